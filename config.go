@@ -0,0 +1,121 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package main
+
+import (
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/konstructio/kubefirst-api/internal/catalogMirror"
+	"github.com/konstructio/kubefirst-api/internal/env"
+	"github.com/konstructio/kubefirst-api/internal/metrics"
+	"github.com/konstructio/kubefirst-api/internal/secrets"
+	apitelemetry "github.com/konstructio/kubefirst-api/internal/telemetry"
+	"github.com/konstructio/kubefirst-api/internal/utils"
+	"github.com/rs/zerolog"
+	log "github.com/rs/zerolog/log"
+)
+
+// flagOverrides maps the command line flags to the settings they override
+var flagOverrides = map[string]string{
+	"port":      "SERVER_PORT",
+	"log-level": "LOG_LEVEL",
+}
+
+// loadConfig loads the configuration from the configuration file, the
+// environment and the command line flags, in increasing order of precedence
+func loadConfig() (*env.Config, error) {
+	configFile := flag.String("config", os.Getenv("CONFIG_FILE"), "YAML configuration file, settings are keyed by environment variable name")
+	flag.String("port", "", "port the API listens on, overrides SERVER_PORT")
+	flag.String("log-level", "", "log level, overrides LOG_LEVEL")
+	flag.Parse()
+
+	overrides := map[string]string{}
+	flag.Visit(func(f *flag.Flag) {
+		if name, ok := flagOverrides[f.Name]; ok {
+			overrides[name] = f.Value.String()
+		}
+	})
+
+	cfg, err := env.Load(env.Options{File: *configFile, Overrides: overrides})
+	if err != nil {
+		return nil, err
+	}
+
+	setLogLevel(cfg.Get().LogLevel)
+	cfg.OnReload(applyReload)
+
+	return cfg, nil
+}
+
+// reloadOnSignal reloads the configuration on every SIGHUP
+func reloadOnSignal(cfg *env.Config) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	for range signals {
+		log.Info().Msg("SIGHUP received, reloading configuration")
+		if _, err := cfg.Reload(); err != nil {
+			log.Error().Msgf("error reloading configuration, keeping the current one: %s", err)
+		}
+	}
+}
+
+// applyReload applies the reloaded settings held by the API outside of the
+// configuration, settings read on use need nothing more
+func applyReload(previous, updated env.Env) {
+	if previous.LogLevel != updated.LogLevel {
+		setLogLevel(updated.LogLevel)
+	}
+
+	if previous.UseTelemetry != updated.UseTelemetry ||
+		previous.TelemetrySink != updated.TelemetrySink ||
+		previous.TelemetryFile != updated.TelemetryFile ||
+		previous.TelemetryWebhookURL != updated.TelemetryWebhookURL {
+		if err := apitelemetry.Configure(updated); err != nil {
+			log.Error().Msgf("error reconfiguring telemetry: %s", err)
+		}
+	}
+
+	if previous.GitopsCatalogMirror != updated.GitopsCatalogMirror ||
+		previous.GitopsCatalogMirrorSHA256 != updated.GitopsCatalogMirrorSHA256 ||
+		previous.GitopsCatalogMirrorSignature != updated.GitopsCatalogMirrorSignature ||
+		previous.GitopsCatalogMirrorPublicKey != updated.GitopsCatalogMirrorPublicKey ||
+		previous.GitopsCatalogMirrorInsecure != updated.GitopsCatalogMirrorInsecure ||
+		previous.GitopsCatalogMirrorUsername != updated.GitopsCatalogMirrorUsername ||
+		previous.GitopsCatalogMirrorPassword != updated.GitopsCatalogMirrorPassword ||
+		previous.GitopsCatalogMirrorPlainHTTP != updated.GitopsCatalogMirrorPlainHTTP {
+		catalogMirror.Configure(updated)
+		go refreshGitopsCatalog()
+	}
+}
+
+// refreshGitopsCatalog refreshes the gitops catalog from its current source
+func refreshGitopsCatalog() {
+	kcfg := utils.GetKubernetesClient("")
+	if kcfg == nil {
+		return
+	}
+
+	err := secrets.UpdateGitopsCatalogApps(kcfg.Clientset)
+	metrics.ObserveCatalogRefresh(err)
+	if err != nil {
+		log.Warn().Msgf("error refreshing gitops catalog after reload: %s", err)
+	}
+}
+
+// setLogLevel sets the level of the global logger
+func setLogLevel(level string) {
+	parsed, err := zerolog.ParseLevel(level)
+	if err != nil {
+		log.Warn().Msgf("invalid log level %q: %s", level, err)
+		return
+	}
+	zerolog.SetGlobalLevel(parsed)
+}
@@ -304,9 +304,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/cluster/:cluster_name/export": {
-            "post": {
-                "description": "Export a Kubefirst cluster database entry",
+        "/cluster/:cluster_name/certificates": {
+            "get": {
+                "description": "List the tls certificates of a cluster with their issuer, names, expiry and renewal status",
                 "consumes": [
                     "application/json"
                 ],
@@ -316,7 +316,7 @@ const docTemplate = `{
                 "tags": [
                     "cluster"
                 ],
-                "summary": "Export a Kubefirst cluster database entry",
+                "summary": "List the tls certificates of a cluster",
                 "parameters": [
                     {
                         "type": "string",
@@ -335,10 +335,13 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "202": {
-                        "description": "Accepted",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/types.JSONSuccessResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/types.ClusterCertificate"
+                            }
                         }
                     },
                     "400": {
@@ -346,13 +349,19 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/types.JSONFailureResponse"
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONFailureResponse"
+                        }
                     }
                 }
             }
         },
-        "/cluster/:cluster_name/reset_progress": {
-            "post": {
-                "description": "Remove a cluster progress marker from a cluster entry",
+        "/cluster/:cluster_name/credentials": {
+            "get": {
+                "description": "Return the Vault, Argo CD and kbot credentials of a cluster. Every access is recorded in the cluster credentials audit log.",
                 "consumes": [
                     "application/json"
                 ],
@@ -362,7 +371,7 @@ const docTemplate = `{
                 "tags": [
                     "cluster"
                 ],
-                "summary": "Remove a cluster progress marker from a cluster entry",
+                "summary": "Return the platform credentials of a cluster",
                 "parameters": [
                     {
                         "type": "string",
@@ -381,10 +390,10 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "202": {
-                        "description": "Accepted",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/types.JSONSuccessResponse"
+                            "$ref": "#/definitions/credentials.PlatformCredentials"
                         }
                     },
                     "400": {
@@ -392,13 +401,25 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/types.JSONFailureResponse"
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONFailureResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONFailureResponse"
+                        }
                     }
                 }
             }
         },
-        "/cluster/:cluster_name/vclusters": {
+        "/cluster/:cluster_name/credentials/rotate": {
             "post": {
-                "description": "Create default virtual clusters",
+                "description": "Rotate the Argo CD admin password and the kbot password stored in Vault, and return the new credentials. Every rotation is recorded in the cluster credentials audit log.",
                 "consumes": [
                     "application/json"
                 ],
@@ -408,7 +429,7 @@ const docTemplate = `{
                 "tags": [
                     "cluster"
                 ],
-                "summary": "Create default virtual clusters",
+                "summary": "Rotate the platform credentials of a cluster",
                 "parameters": [
                     {
                         "type": "string",
@@ -427,10 +448,10 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "202": {
-                        "description": "Accepted",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/types.JSONSuccessResponse"
+                            "$ref": "#/definitions/credentials.PlatformCredentials"
                         }
                     },
                     "400": {
@@ -438,13 +459,25 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/types.JSONFailureResponse"
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONFailureResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONFailureResponse"
+                        }
                     }
                 }
             }
         },
-        "/cluster/import": {
-            "post": {
-                "description": "Import a Kubefirst cluster database entry",
+        "/cluster/:cluster_name/export": {
+            "get": {
+                "description": "Export a Kubefirst cluster database entry",
                 "consumes": [
                     "application/json"
                 ],
@@ -454,16 +487,14 @@ const docTemplate = `{
                 "tags": [
                     "cluster"
                 ],
-                "summary": "Import a Kubefirst cluster database entry",
+                "summary": "Export a Kubefirst cluster database entry",
                 "parameters": [
                     {
-                        "description": "Cluster import request in JSON format",
-                        "name": "request_body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/types.Cluster"
-                        }
+                        "type": "string",
+                        "description": "Cluster name",
+                        "name": "cluster_name",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "string",
@@ -490,9 +521,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/domain/:cloud_provider": {
+        "/cluster/:cluster_name/reset_progress": {
             "post": {
-                "description": "Return a list of registered domains/hosted zones for a cloud provider account",
+                "description": "Remove a cluster progress marker from a cluster entry",
                 "consumes": [
                     "application/json"
                 ],
@@ -500,18 +531,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "domain"
+                    "cluster"
                 ],
-                "summary": "Return a list of registered domains/hosted zones for a cloud provider account",
+                "summary": "Remove a cluster progress marker from a cluster entry",
                 "parameters": [
                     {
-                        "description": "Domain list request in JSON format",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/types.DomainListRequest"
-                        }
+                        "type": "string",
+                        "description": "Cluster name",
+                        "name": "cluster_name",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "string",
@@ -523,10 +552,10 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
-                            "$ref": "#/definitions/types.DomainListResponse"
+                            "$ref": "#/definitions/types.JSONSuccessResponse"
                         }
                     },
                     "400": {
@@ -538,9 +567,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/gitops-catalog/:cluster_name/:cloud_provider/apps": {
-            "get": {
-                "description": "Returns a list of available Kubefirst gitops catalog applications",
+        "/cluster/:cluster_name/state-store/migrate": {
+            "post": {
+                "description": "Copy the terraform state and artifacts of a cluster to another s3 compatible, gcs or azure blob bucket, verify the copies by checksum, point the terraform backends of the gitops repository and the atlantis credentials at it. The cluster is marked in progress while migrating, its state store details are only updated once every step succeeded and the source bucket is left untouched.",
                 "consumes": [
                     "application/json"
                 ],
@@ -548,10 +577,26 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "gitops-catalog"
+                    "cluster"
                 ],
-                "summary": "Returns a list of available Kubefirst gitops catalog applications",
+                "summary": "Migrate the state store of a cluster to another bucket",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cluster name",
+                        "name": "cluster_name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Destination bucket",
+                        "name": "definition",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.StateStoreMigrationRequest"
+                        }
+                    },
                     {
                         "type": "string",
                         "default": "Bearer \u003cAPI key\u003e",
@@ -565,7 +610,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/types.GitopsCatalogApps"
+                            "$ref": "#/definitions/types.StateStoreMigrationResult"
                         }
                     },
                     "400": {
@@ -573,13 +618,31 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/types.JSONFailureResponse"
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONFailureResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONFailureResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONFailureResponse"
+                        }
                     }
                 }
             }
         },
-        "/gitops-catalog/apps/update": {
+        "/cluster/:cluster_name/tls-backups": {
             "get": {
-                "description": "Updates the list of available Kubefirst gitops catalog applications",
+                "description": "List the tls certificate backups stored in the state store of a cluster, newest first",
                 "consumes": [
                     "application/json"
                 ],
@@ -587,10 +650,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "gitops-catalog"
+                    "cluster"
                 ],
-                "summary": "Updates the list of available Kubefirst gitops catalog applications",
+                "summary": "List the tls certificate backups of a cluster",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cluster name",
+                        "name": "cluster_name",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "type": "string",
                         "default": "Bearer \u003cAPI key\u003e",
@@ -604,7 +674,10 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/types.JSONSuccessResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/types.BucketObject"
+                            }
                         }
                     },
                     "400": {
@@ -612,21 +685,50 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/types.JSONFailureResponse"
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONFailureResponse"
+                        }
                     }
                 }
             }
         },
-        "/health": {
+        "/cluster/:cluster_name/vault/secrets/{path}": {
             "get": {
-                "description": "Return health status if the application is running.",
+                "description": "List the secrets below a path ending with a slash, or return a secret with its version history. Only the secrets of catalog apps installed on the cluster are accessible.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "health"
+                    "vault"
                 ],
-                "summary": "Return health status if the application is running.",
+                "summary": "List or read secrets in the vault kv store of a cluster",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cluster name",
+                        "name": "cluster_name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Secret path in the secret kv mount",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Secret version, defaults to the current version",
+                        "name": "version",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "default": "Bearer \u003cAPI key\u003e",
@@ -640,15 +742,37 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/types.JSONHealthResponse"
+                            "$ref": "#/definitions/types.VaultSecretList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONFailureResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONFailureResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONFailureResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONFailureResponse"
                         }
                     }
                 }
-            }
-        },
-        "/region/:cloud_provider": {
-            "post": {
-                "description": "Return a list of regions for a cloud provider account",
+            },
+            "put": {
+                "description": "Write a new version of a secret. Only the secrets of catalog apps installed on the cluster are accessible.",
                 "consumes": [
                     "application/json"
                 ],
@@ -656,17 +780,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "region"
+                    "vault"
                 ],
-                "summary": "Return a list of regions for a cloud provider account",
+                "summary": "Write a secret in the vault kv store of a cluster",
                 "parameters": [
                     {
-                        "description": "Region list request in JSON format",
-                        "name": "request",
+                        "type": "string",
+                        "description": "Cluster name",
+                        "name": "cluster_name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Secret path in the secret kv mount",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Secret data",
+                        "name": "definition",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/types.RegionListRequest"
+                            "$ref": "#/definitions/types.VaultSecretWriteRequest"
                         }
                     },
                     {
@@ -682,7 +820,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/types.RegionListResponse"
+                            "$ref": "#/definitions/types.VaultSecret"
                         }
                     },
                     "400": {
@@ -690,13 +828,29 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/types.JSONFailureResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONFailureResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONFailureResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONFailureResponse"
+                        }
                     }
                 }
-            }
-        },
-        "/services/:cluster_name": {
-            "get": {
-                "description": "Returns a list of services for a cluster",
+            },
+            "delete": {
+                "description": "Delete versions of a secret, the current version by default. Deleted versions can be recovered unless destroy is set; destroying without versions removes the secret with its whole history. Only the secrets of catalog apps installed on the cluster are accessible.",
                 "consumes": [
                     "application/json"
                 ],
@@ -704,9 +858,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "services"
+                    "vault"
                 ],
-                "summary": "Returns a list of services for a cluster",
+                "summary": "Delete a secret in the vault kv store of a cluster",
                 "parameters": [
                     {
                         "type": "string",
@@ -715,6 +869,25 @@ const docTemplate = `{
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "description": "Secret path in the secret kv mount",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated versions to delete",
+                        "name": "versions",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Permanently destroy the versions",
+                        "name": "destroy",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "default": "Bearer \u003cAPI key\u003e",
@@ -728,7 +901,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/types.ClusterServiceList"
+                            "$ref": "#/definitions/types.JSONSuccessResponse"
                         }
                     },
                     "400": {
@@ -736,15 +909,644 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/types.JSONFailureResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/services/:cluster_name/:service_name": {
-            "post": {
-                "description": "Add a gitops catalog application to a cluster as a service",
-                "consumes": [
-                    "application/json"
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONFailureResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONFailureResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONFailureResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/cluster/:cluster_name/vclusters": {
+            "post": {
+                "description": "Create default virtual clusters",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cluster"
+                ],
+                "summary": "Create default virtual clusters",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cluster name",
+                        "name": "cluster_name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAPI key\u003e",
+                        "description": "API key",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONSuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONFailureResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/cluster/import": {
+            "post": {
+                "description": "Import a Kubefirst cluster database entry",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cluster"
+                ],
+                "summary": "Import a Kubefirst cluster database entry",
+                "parameters": [
+                    {
+                        "description": "Cluster import request in JSON format",
+                        "name": "request_body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.Cluster"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAPI key\u003e",
+                        "description": "API key",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONSuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONFailureResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/domain/:cloud_provider": {
+            "post": {
+                "description": "Return a list of registered domains/hosted zones for a cloud provider account",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "domain"
+                ],
+                "summary": "Return a list of registered domains/hosted zones for a cloud provider account",
+                "parameters": [
+                    {
+                        "description": "Domain list request in JSON format",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.DomainListRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAPI key\u003e",
+                        "description": "API key",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.DomainListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONFailureResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/domain/:domain/certificate-usage": {
+            "get": {
+                "description": "Return the LetsEncrypt certificates issued for a domain during the current weekly rate limit window",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "domain"
+                ],
+                "summary": "Return the LetsEncrypt certificate usage of a domain",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Domain name",
+                        "name": "domain",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAPI key\u003e",
+                        "description": "API key",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/certificates.CertificateUsage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONFailureResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONFailureResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/gitops-catalog/:cluster_name/:cloud_provider/apps": {
+            "get": {
+                "description": "Returns a filtered, paginated list of available Kubefirst gitops catalog applications",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "gitops-catalog"
+                ],
+                "summary": "Returns a list of available Kubefirst gitops catalog applications",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cluster name",
+                        "name": "cluster_name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cloud provider",
+                        "name": "cloud_provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return apps in this category",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search the app display name and description",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only return apps that are (true) or are not (false) installed on the cluster",
+                        "name": "installed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number, starting at 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Apps per page, all apps when omitted",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAPI key\u003e",
+                        "description": "API key",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GitopsCatalogAppsPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONFailureResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONFailureResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/gitops-catalog/apps/update": {
+            "get": {
+                "description": "Updates the list of available Kubefirst gitops catalog applications",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "gitops-catalog"
+                ],
+                "summary": "Updates the list of available Kubefirst gitops catalog applications",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAPI key\u003e",
+                        "description": "API key",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONSuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONFailureResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/gitops-catalog/categories": {
+            "get": {
+                "description": "Returns each gitops catalog category with the number of applications it holds",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "gitops-catalog"
+                ],
+                "summary": "Returns the gitops catalog categories",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cloud provider of the cluster, its denylisted apps are not counted",
+                        "name": "cloud_provider",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Git provider of the cluster, its denylisted apps are not counted",
+                        "name": "git_provider",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAPI key\u003e",
+                        "description": "API key",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GitopsCatalogCategories"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONFailureResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Return health status if the application is running.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Return health status if the application is running.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAPI key\u003e",
+                        "description": "API key",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONHealthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/livez": {
+            "get": {
+                "description": "Check the background workers of the API, such as the gitops catalog updater and the telemetry heartbeat, are still running.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Return the liveness of the API",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Check the API is not shutting down, the kubernetes api, the permissions of the API in the kubefirst namespace, the gitops catalog secret, and vault and argo cd of the provisioned clusters. Catalog, vault and argo cd failures degrade the report without failing it, and vault and argo cd are checked at most once a minute.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Return the readiness of the API and its dependencies",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    }
+                }
+            }
+        },
+        "/region/:cloud_provider": {
+            "post": {
+                "description": "Return a list of regions for a cloud provider account",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "region"
+                ],
+                "summary": "Return a list of regions for a cloud provider account",
+                "parameters": [
+                    {
+                        "description": "Region list request in JSON format",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.RegionListRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAPI key\u003e",
+                        "description": "API key",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.RegionListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONFailureResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/services/:cluster_name": {
+            "get": {
+                "description": "Returns a list of services for a cluster",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "services"
+                ],
+                "summary": "Returns a list of services for a cluster",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cluster name",
+                        "name": "cluster_name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAPI key\u003e",
+                        "description": "API key",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.ClusterServiceList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONFailureResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/services/:cluster_name/:service_name": {
+            "put": {
+                "description": "Rewrite the config and secret keys of an installed gitops catalog application",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "services"
+                ],
+                "summary": "Update the configuration of a service installed on a cluster",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cluster name",
+                        "name": "cluster_name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Service name to be updated",
+                        "name": "service_name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Service update request in JSON format",
+                        "name": "definition",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.GitopsCatalogAppUpdateRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAPI key\u003e",
+                        "description": "API key",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONSuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONFailureResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add a gitops catalog application to a cluster as a service",
+                "consumes": [
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
@@ -853,6 +1655,68 @@ const docTemplate = `{
                 }
             }
         },
+        "/services/:cluster_name/:service_name/targets": {
+            "post": {
+                "description": "Install a gitops catalog application on a list of clusters with per-target config overrides in a single commit",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "services"
+                ],
+                "summary": "Add a gitops catalog application to several clusters at once",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Management cluster name",
+                        "name": "cluster_name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Service name to be added",
+                        "name": "service_name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Multi-target service create request in JSON format",
+                        "name": "definition",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.GitopsCatalogAppMultiTargetCreateRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAPI key\u003e",
+                        "description": "API key",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GitopsCatalogAppMultiTargetCreateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONFailureResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/services/:cluster_name/:service_name/validate": {
             "post": {
                 "description": "Validate a gitops catalog application so it can be deleted",
@@ -915,7 +1779,112 @@ const docTemplate = `{
                 }
             }
         },
-        "/stream/file_name": {
+        "/services/:cluster_name/bulk": {
+            "get": {
+                "description": "Return the status of the last bulk install of a cluster with the results of the waves installed so far",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "services"
+                ],
+                "summary": "Return the progress of the last bulk install of a cluster",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cluster name",
+                        "name": "cluster_name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAPI key\u003e",
+                        "description": "API key",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GitopsCatalogAppBulkCreateResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONFailureResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Start installing a set of gitops catalog applications, one commit per dependency wave. The progress is polled with GET /services/:cluster_name/bulk",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "services"
+                ],
+                "summary": "Add several gitops catalog applications to a cluster in dependency order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cluster name",
+                        "name": "cluster_name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Bulk service create request in JSON format",
+                        "name": "definition",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.GitopsCatalogAppBulkCreateRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAPI key\u003e",
+                        "description": "API key",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/types.GitopsCatalogAppBulkCreateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONFailureResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/types.JSONFailureResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stream/:file_name": {
             "get": {
                 "description": "Stream API server logs",
                 "tags": [
@@ -983,6 +1952,140 @@ const docTemplate = `{
         }
     },
     "definitions": {
+        "certificates.CertificateDetail": {
+            "type": "object",
+            "properties": {
+                "dns_names": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "expiration_days": {
+                    "type": "number"
+                },
+                "issued": {
+                    "type": "string"
+                }
+            }
+        },
+        "certificates.CertificateUsage": {
+            "type": "object",
+            "properties": {
+                "domain": {
+                    "type": "string"
+                },
+                "issued": {
+                    "type": "integer"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "names": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/certificates.NameUsage"
+                    }
+                },
+                "remaining": {
+                    "type": "integer"
+                },
+                "reset_time": {
+                    "description": "ResetTime is when the oldest certificate leaves the window, freeing a slot",
+                    "type": "string"
+                }
+            }
+        },
+        "certificates.NameUsage": {
+            "type": "object",
+            "properties": {
+                "details": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/certificates.CertificateDetail"
+                    }
+                },
+                "issued": {
+                    "type": "integer"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "remaining": {
+                    "type": "integer"
+                },
+                "reset_time": {
+                    "type": "string"
+                }
+            }
+        },
+        "credentials.ComponentCredentials": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "credentials.PlatformCredentials": {
+            "type": "object",
+            "properties": {
+                "argocd": {
+                    "$ref": "#/definitions/credentials.ComponentCredentials"
+                },
+                "kbot": {
+                    "$ref": "#/definitions/credentials.ComponentCredentials"
+                },
+                "vault": {
+                    "$ref": "#/definitions/credentials.ComponentCredentials"
+                }
+            }
+        },
+        "health.ComponentStatus": {
+            "type": "object",
+            "properties": {
+                "critical": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "health.Report": {
+            "type": "object",
+            "properties": {
+                "components": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/health.ComponentStatus"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
         "types.AWSAuth": {
             "type": "object",
             "properties": {
@@ -1016,17 +2119,31 @@ const docTemplate = `{
         "types.AzureAuth": {
             "type": "object",
             "properties": {
-                "client_id": {
-                    "type": "string"
-                },
-                "client_secret": {
+                "client_id": {
+                    "type": "string"
+                },
+                "client_secret": {
+                    "type": "string"
+                },
+                "subscription_id": {
+                    "type": "string"
+                },
+                "tenant_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "types.BucketObject": {
+            "type": "object",
+            "properties": {
+                "key": {
                     "type": "string"
                 },
-                "subscription_id": {
+                "last_modified": {
                     "type": "string"
                 },
-                "tenant_id": {
-                    "type": "string"
+                "size": {
+                    "type": "integer"
                 }
             }
         },
@@ -1141,6 +2258,9 @@ const docTemplate = `{
                     "description": "Azure",
                     "type": "string"
                 },
+                "certificates_last_checked_at": {
+                    "type": "string"
+                },
                 "civo_auth": {
                     "$ref": "#/definitions/types.CivoAuth"
                 },
@@ -1190,6 +2310,13 @@ const docTemplate = `{
                     "description": "Container Registry and Secrets",
                     "type": "boolean"
                 },
+                "expiring_certificates": {
+                    "description": "Certificates expiring within the monitoring window, as of the last check",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.ClusterCertificate"
+                    }
+                },
                 "final_check": {
                     "type": "boolean"
                 },
@@ -1208,6 +2335,9 @@ const docTemplate = `{
                 "git_provider": {
                     "type": "string"
                 },
+                "git_server": {
+                    "$ref": "#/definitions/types.GitServer"
+                },
                 "git_terraform_apply_check": {
                     "type": "boolean"
                 },
@@ -1239,6 +2369,10 @@ const docTemplate = `{
                 "install_tools_check": {
                     "type": "boolean"
                 },
+                "interrupted_operation": {
+                    "description": "InterruptedOperation is the operation an API shutdown stopped, create or\ndelete, only that operation may be retried",
+                    "type": "string"
+                },
                 "k3s_auth": {
                     "$ref": "#/definitions/types.K3sAuth"
                 },
@@ -1251,6 +2385,10 @@ const docTemplate = `{
                 "last_condition": {
                     "type": "string"
                 },
+                "last_error_code": {
+                    "description": "LastErrorCode is the error code of the last failed operation",
+                    "type": "string"
+                },
                 "log_file": {
                     "type": "string"
                 },
@@ -1285,8 +2423,12 @@ const docTemplate = `{
                 "subdomain_name": {
                     "type": "string"
                 },
+                "trace_id": {
+                    "description": "TraceID is the trace of the last create or delete operation",
+                    "type": "string"
+                },
                 "useTelemetry": {
-                    "description": "Telemetry",
+                    "description": "Telemetry, no event about the cluster or its workload clusters is sent when\nfalse. Records written before the setting existed leave it unset, see\nTelemetryEnabled.",
                     "type": "boolean"
                 },
                 "users_terraform_apply_check": {
@@ -1312,6 +2454,49 @@ const docTemplate = `{
                 }
             }
         },
+        "types.ClusterCertificate": {
+            "type": "object",
+            "properties": {
+                "dns_names": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "issuer": {
+                    "type": "string"
+                },
+                "managed": {
+                    "description": "Ready and RenewalTime are reported by cert-manager for managed certificates",
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "not_after": {
+                    "type": "string"
+                },
+                "not_before": {
+                    "type": "string"
+                },
+                "ready": {
+                    "type": "boolean"
+                },
+                "renewal_time": {
+                    "type": "string"
+                },
+                "secret_name": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status is one of valid, expiring, expired or pending",
+                    "type": "string"
+                }
+            }
+        },
         "types.ClusterDefinition": {
             "type": "object",
             "required": [
@@ -1407,9 +2592,13 @@ const docTemplate = `{
                     "type": "string",
                     "enum": [
                         "github",
-                        "gitlab"
+                        "gitlab",
+                        "gitea"
                     ]
                 },
+                "git_server": {
+                    "$ref": "#/definitions/types.GitServer"
+                },
                 "gitops_template_branch": {
                     "type": "string"
                 },
@@ -1451,6 +2640,10 @@ const docTemplate = `{
                         "workload"
                     ]
                 },
+                "use_telemetry": {
+                    "description": "Telemetry, enabled when omitted. No event about the cluster is sent when disabled.",
+                    "type": "boolean"
+                },
                 "vultr_auth": {
                     "$ref": "#/definitions/types.VultrAuth"
                 }
@@ -1550,77 +2743,302 @@ const docTemplate = `{
                 }
             }
         },
-        "types.GitAuth": {
+        "types.GitAuth": {
+            "type": "object",
+            "properties": {
+                "git_owner": {
+                    "type": "string"
+                },
+                "git_token": {
+                    "type": "string"
+                },
+                "git_username": {
+                    "type": "string"
+                },
+                "private_key": {
+                    "type": "string"
+                },
+                "public_key": {
+                    "type": "string"
+                },
+                "public_keys": {
+                    "type": "string"
+                }
+            }
+        },
+        "types.GitServer": {
+            "type": "object",
+            "properties": {
+                "api_url": {
+                    "type": "string"
+                },
+                "base_url": {
+                    "type": "string"
+                },
+                "ca_bundle": {
+                    "type": "string"
+                }
+            }
+        },
+        "types.GitopsCatalogApp": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string"
+                },
+                "cloudDenylist": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "config_keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.GitopsCatalogAppKeys"
+                    }
+                },
+                "dependsOn": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "description": {
+                    "type": "string"
+                },
+                "display_name": {
+                    "type": "string"
+                },
+                "gitDenylist": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "image_url": {
+                    "type": "string"
+                },
+                "is_template": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "secret_keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.GitopsCatalogAppKeys"
+                    }
+                }
+            }
+        },
+        "types.GitopsCatalogAppBulkCreateEntry": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "config_keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.GitopsCatalogAppKeys"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "secret_keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.GitopsCatalogAppKeys"
+                    }
+                }
+            }
+        },
+        "types.GitopsCatalogAppBulkCreateRequest": {
+            "type": "object",
+            "required": [
+                "apps"
+            ],
+            "properties": {
+                "apps": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/types.GitopsCatalogAppBulkCreateEntry"
+                    }
+                },
+                "environment": {
+                    "type": "string"
+                },
+                "user": {
+                    "type": "string"
+                },
+                "workload_cluster_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "types.GitopsCatalogAppBulkCreateResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.GitopsCatalogAppBulkCreateResult"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "types.GitopsCatalogAppBulkCreateResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "wave": {
+                    "type": "integer"
+                }
+            }
+        },
+        "types.GitopsCatalogAppCreateRequest": {
+            "type": "object",
+            "properties": {
+                "config_keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.GitopsCatalogAppKeys"
+                    }
+                },
+                "environment": {
+                    "type": "string"
+                },
+                "is_template": {
+                    "type": "boolean"
+                },
+                "secret_keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.GitopsCatalogAppKeys"
+                    }
+                },
+                "user": {
+                    "type": "string"
+                },
+                "workload_cluster_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "types.GitopsCatalogAppKeys": {
+            "type": "object",
+            "properties": {
+                "env": {
+                    "type": "string"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "types.GitopsCatalogAppMultiTargetCreateRequest": {
             "type": "object",
+            "required": [
+                "targets"
+            ],
             "properties": {
-                "git_owner": {
-                    "type": "string"
-                },
-                "git_token": {
-                    "type": "string"
-                },
-                "git_username": {
-                    "type": "string"
+                "config_keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.GitopsCatalogAppKeys"
+                    }
                 },
-                "private_key": {
-                    "type": "string"
+                "secret_keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.GitopsCatalogAppKeys"
+                    }
                 },
-                "public_key": {
-                    "type": "string"
+                "targets": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/types.GitopsCatalogAppTarget"
+                    }
                 },
-                "public_keys": {
+                "user": {
                     "type": "string"
                 }
             }
         },
-        "types.GitopsCatalogApp": {
+        "types.GitopsCatalogAppMultiTargetCreateResponse": {
             "type": "object",
             "properties": {
-                "category": {
-                    "type": "string"
-                },
-                "cloudDenylist": {
+                "results": {
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/types.GitopsCatalogAppTargetResult"
                     }
-                },
+                }
+            }
+        },
+        "types.GitopsCatalogAppTarget": {
+            "type": "object",
+            "properties": {
                 "config_keys": {
                     "type": "array",
                     "items": {
                         "$ref": "#/definitions/types.GitopsCatalogAppKeys"
                     }
                 },
-                "description": {
+                "environment": {
                     "type": "string"
                 },
-                "display_name": {
+                "workload_cluster_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "types.GitopsCatalogAppTargetResult": {
+            "type": "object",
+            "properties": {
+                "cluster_name": {
                     "type": "string"
                 },
-                "gitDenylist": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
-                },
-                "image_url": {
+                "environment": {
                     "type": "string"
                 },
-                "is_template": {
-                    "type": "boolean"
+                "error": {
+                    "type": "string"
                 },
-                "name": {
+                "registry_path": {
                     "type": "string"
                 },
-                "secret_keys": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/types.GitopsCatalogAppKeys"
-                    }
+                "status": {
+                    "type": "string"
                 }
             }
         },
-        "types.GitopsCatalogAppCreateRequest": {
+        "types.GitopsCatalogAppUpdateRequest": {
             "type": "object",
             "properties": {
                 "config_keys": {
@@ -1649,39 +3067,53 @@ const docTemplate = `{
                 }
             }
         },
-        "types.GitopsCatalogAppKeys": {
+        "types.GitopsCatalogAppValidateRequest": {
             "type": "object",
             "properties": {
-                "env": {
-                    "type": "string"
-                },
-                "label": {
-                    "type": "string"
+                "can_delete_service": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "types.GitopsCatalogAppsPage": {
+            "type": "object",
+            "properties": {
+                "apps": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.GitopsCatalogApp"
+                    }
                 },
                 "name": {
                     "type": "string"
                 },
-                "value": {
-                    "type": "string"
+                "page": {
+                    "type": "integer"
+                },
+                "page_size": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "types.GitopsCatalogAppValidateRequest": {
+        "types.GitopsCatalogCategories": {
             "type": "object",
             "properties": {
-                "can_delete_service": {
-                    "type": "boolean"
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.GitopsCatalogCategory"
+                    }
                 }
             }
         },
-        "types.GitopsCatalogApps": {
+        "types.GitopsCatalogCategory": {
             "type": "object",
             "properties": {
-                "apps": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/types.GitopsCatalogApp"
-                    }
+                "count": {
+                    "type": "integer"
                 },
                 "name": {
                     "type": "string"
@@ -1702,9 +3134,26 @@ const docTemplate = `{
         "types.JSONFailureResponse": {
             "type": "object",
             "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "cluster_not_found"
+                },
+                "details": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
                 "error": {
                     "type": "string",
-                    "example": "err"
+                    "example": "cluster \"dev\" not found"
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "aws"
+                },
+                "retryable": {
+                    "type": "boolean"
                 }
             }
         },
@@ -1755,6 +3204,23 @@ const docTemplate = `{
                 }
             }
         },
+        "types.MigratedObject": {
+            "type": "object",
+            "properties": {
+                "bucket": {
+                    "type": "string"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "sha256": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                }
+            }
+        },
         "types.RegionListRequest": {
             "type": "object",
             "properties": {
@@ -1798,6 +3264,17 @@ const docTemplate = `{
         "types.Service": {
             "type": "object",
             "properties": {
+                "catalog_version": {
+                    "description": "CatalogVersion is the gitops catalog revision the service was rendered from",
+                    "type": "string"
+                },
+                "config_keys": {
+                    "description": "ConfigKeys are the config values the service was rendered with, updates\nonly need to provide the values that change",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.GitopsCatalogAppKeys"
+                    }
+                },
                 "created_by": {
                     "type": "string"
                 },
@@ -1821,6 +3298,23 @@ const docTemplate = `{
                 },
                 "status": {
                     "type": "string"
+                },
+                "updated_by": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.ServiceUpdate"
+                    }
+                }
+            }
+        },
+        "types.ServiceUpdate": {
+            "type": "object",
+            "properties": {
+                "timestamp": {
+                    "type": "string"
+                },
+                "user": {
+                    "type": "string"
                 }
             }
         },
@@ -1833,6 +3327,10 @@ const docTemplate = `{
                 "id": {
                     "type": "string"
                 },
+                "key_file": {
+                    "description": "KeyFile is the service account key of gcs state stores",
+                    "type": "string"
+                },
                 "name": {
                     "type": "string"
                 },
@@ -1853,6 +3351,10 @@ const docTemplate = `{
                 "aws_state_store_bucket": {
                     "type": "string"
                 },
+                "backend": {
+                    "description": "Backend is the terraform backend of a migrated state store, empty when\nthe state store is the one created with the cluster",
+                    "type": "string"
+                },
                 "hostname": {
                     "type": "string"
                 },
@@ -1864,6 +3366,62 @@ const docTemplate = `{
                 }
             }
         },
+        "types.StateStoreMigrationRequest": {
+            "type": "object",
+            "required": [
+                "bucket_name",
+                "credentials"
+            ],
+            "properties": {
+                "artifacts_bucket_name": {
+                    "description": "Required for clusters with a separate artifacts bucket",
+                    "type": "string"
+                },
+                "backend": {
+                    "description": "Backend is s3 for s3 compatible stores, gcs or azurerm, defaults to s3",
+                    "type": "string",
+                    "enum": [
+                        "s3",
+                        "gcs",
+                        "azurerm"
+                    ]
+                },
+                "bucket_name": {
+                    "description": "The blob container for azurerm",
+                    "type": "string"
+                },
+                "credentials": {
+                    "$ref": "#/definitions/types.StateStoreCredentials"
+                },
+                "hostname": {
+                    "description": "Required for s3 compatible stores",
+                    "type": "string"
+                },
+                "region": {
+                    "type": "string"
+                }
+            }
+        },
+        "types.StateStoreMigrationResult": {
+            "type": "object",
+            "properties": {
+                "gitops_files": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "objects": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.MigratedObject"
+                    }
+                },
+                "state_store_details": {
+                    "$ref": "#/definitions/types.StateStoreDetails"
+                }
+            }
+        },
         "types.TelemetryRequest": {
             "type": "object",
             "properties": {
@@ -1883,6 +3441,97 @@ const docTemplate = `{
                 }
             }
         },
+        "types.VaultSecret": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "metadata": {
+                    "$ref": "#/definitions/types.VaultSecretMetadata"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "types.VaultSecretList": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "path": {
+                    "type": "string"
+                }
+            }
+        },
+        "types.VaultSecretMetadata": {
+            "type": "object",
+            "properties": {
+                "created_time": {
+                    "type": "string"
+                },
+                "current_version": {
+                    "type": "integer"
+                },
+                "max_versions": {
+                    "type": "integer"
+                },
+                "oldest_version": {
+                    "type": "integer"
+                },
+                "updated_time": {
+                    "type": "string"
+                },
+                "versions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.VaultSecretVersion"
+                    }
+                }
+            }
+        },
+        "types.VaultSecretVersion": {
+            "type": "object",
+            "properties": {
+                "created_time": {
+                    "type": "string"
+                },
+                "deletion_time": {
+                    "type": "string"
+                },
+                "destroyed": {
+                    "type": "boolean"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "types.VaultSecretWriteRequest": {
+            "type": "object",
+            "required": [
+                "data"
+            ],
+            "properties": {
+                "cas": {
+                    "description": "CheckAndSet only writes when the current version matches, 0 only writes new secrets",
+                    "type": "integer"
+                },
+                "data": {
+                    "type": "object",
+                    "additionalProperties": true
+                }
+            }
+        },
         "types.VultrAuth": {
             "type": "object",
             "properties": {
@@ -11,7 +11,9 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/konstructio/kubefirst-api/internal/gitHost"
 	"github.com/konstructio/kubefirst-api/internal/k8s"
+	"github.com/konstructio/kubefirst-api/internal/objectStorage"
 	"github.com/konstructio/kubefirst-api/internal/vault"
 	"github.com/konstructio/kubefirst-api/pkg/providerConfigs"
 	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
@@ -31,49 +33,36 @@ func readVaultTokenFromSecret(clientset kubernetes.Interface) string {
 
 func GetAwsTerraformEnvs(envs map[string]string, cl *pkgtypes.Cluster) map[string]string {
 	// needed for s3 api connectivity to object storage
-	envs["AWS_ACCESS_KEY_ID"] = cl.StateStoreCredentials.AccessKeyID
-	envs["AWS_SECRET_ACCESS_KEY"] = cl.StateStoreCredentials.SecretAccessKey
+	envs["AWS_ACCESS_KEY_ID"] = cl.AWSAuth.AccessKeyID
+	envs["AWS_SECRET_ACCESS_KEY"] = cl.AWSAuth.SecretAccessKey
 	envs["AWS_SESSION_TOKEN"] = cl.AWSAuth.SessionToken
-	envs["TF_VAR_aws_access_key_id"] = cl.StateStoreCredentials.AccessKeyID
-	envs["TF_VAR_aws_secret_access_key"] = cl.StateStoreCredentials.SecretAccessKey
+	envs["TF_VAR_aws_access_key_id"] = cl.AWSAuth.AccessKeyID
+	envs["TF_VAR_aws_secret_access_key"] = cl.AWSAuth.SecretAccessKey
 	envs["TF_VAR_aws_session_token"] = cl.AWSAuth.SessionToken
 	envs["TF_VAR_aws_region"] = cl.CloudRegion
 	envs["TF_VAR_hosted_zone_name"] = cl.DomainName
 	// envs["TF_LOG"] = "debug"
 
+	envs = objectStorage.TerraformEnvs(envs, cl)
+
 	return envs
 }
 
-func GetGithubTerraformEnvs(envs map[string]string, cl *pkgtypes.Cluster) map[string]string {
-	envs["GITHUB_TOKEN"] = cl.GitAuth.Token
-	envs["GITHUB_OWNER"] = cl.GitAuth.Owner
+// GetGitTerraformEnvs returns the environment the git terraform needs from the cloud
+func GetGitTerraformEnvs(envs map[string]string, cl *pkgtypes.Cluster) map[string]string {
 	envs["TF_VAR_atlantis_repo_webhook_secret"] = cl.AtlantisWebhookSecret
 	envs["TF_VAR_atlantis_repo_webhook_url"] = cl.AtlantisWebhookURL
 	envs["TF_VAR_kbot_ssh_public_key"] = cl.GitAuth.PublicKey
-	envs["AWS_ACCESS_KEY_ID"] = cl.StateStoreCredentials.AccessKeyID
-	envs["AWS_SECRET_ACCESS_KEY"] = cl.StateStoreCredentials.SecretAccessKey
+	envs["AWS_ACCESS_KEY_ID"] = cl.AWSAuth.AccessKeyID
+	envs["AWS_SECRET_ACCESS_KEY"] = cl.AWSAuth.SecretAccessKey
 	envs["AWS_SESSION_TOKEN"] = cl.AWSAuth.SessionToken
-	envs["TF_VAR_aws_access_key_id"] = cl.StateStoreCredentials.AccessKeyID
-	envs["TF_VAR_aws_secret_access_key"] = cl.StateStoreCredentials.SecretAccessKey
+	envs["TF_VAR_aws_access_key_id"] = cl.AWSAuth.AccessKeyID
+	envs["TF_VAR_aws_secret_access_key"] = cl.AWSAuth.SecretAccessKey
 	envs["TF_VAR_aws_session_token"] = cl.AWSAuth.SessionToken
 
-	return envs
-}
+	envs = gitHost.TerraformEnvs(envs, cl)
 
-func GetGitlabTerraformEnvs(envs map[string]string, gid int, cl *pkgtypes.Cluster) map[string]string {
-	envs["GITLAB_TOKEN"] = cl.GitAuth.Token
-	envs["GITLAB_OWNER"] = cl.GitAuth.Owner
-	envs["TF_VAR_atlantis_repo_webhook_secret"] = cl.AtlantisWebhookSecret
-	envs["TF_VAR_atlantis_repo_webhook_url"] = cl.AtlantisWebhookURL
-	envs["TF_VAR_kbot_ssh_public_key"] = cl.GitAuth.PublicKey
-	envs["AWS_ACCESS_KEY_ID"] = cl.StateStoreCredentials.AccessKeyID
-	envs["AWS_SECRET_ACCESS_KEY"] = cl.StateStoreCredentials.SecretAccessKey
-	envs["AWS_SESSION_TOKEN"] = cl.AWSAuth.SessionToken
-	envs["TF_VAR_aws_access_key_id"] = cl.StateStoreCredentials.AccessKeyID
-	envs["TF_VAR_aws_secret_access_key"] = cl.StateStoreCredentials.SecretAccessKey
-	envs["TF_VAR_aws_session_token"] = cl.AWSAuth.SessionToken
-	envs["TF_VAR_owner_group_id"] = strconv.Itoa(gid)
-	envs["TF_VAR_gitlab_owner"] = cl.GitAuth.Owner
+	envs = objectStorage.TerraformEnvs(envs, cl)
 
 	return envs
 }
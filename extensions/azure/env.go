@@ -3,9 +3,10 @@ package azure
 import (
 	"fmt"
 	"log"
-	"strconv"
 	"strings"
 
+	"github.com/konstructio/kubefirst-api/internal/gitHost"
+	"github.com/konstructio/kubefirst-api/internal/objectStorage"
 	"github.com/konstructio/kubefirst-api/internal/vault"
 	"github.com/konstructio/kubefirst-api/pkg/k8s"
 	"github.com/konstructio/kubefirst-api/pkg/providerConfigs"
@@ -35,25 +36,13 @@ func GetAzureTerraformEnvs(envs map[string]string, cl *pkgtypes.Cluster) map[str
 	envs["TF_VAR_azure_storage_account"] = cl.StateStoreCredentials.Name
 	envs["TF_VAR_azure_storage_access_key"] = cl.StateStoreCredentials.SecretAccessKey
 
-	return envs
-}
-
-func GetGithubTerraformEnvs(envs map[string]string, cl *pkgtypes.Cluster) map[string]string {
-	envs["GITHUB_TOKEN"] = cl.GitAuth.Token
-	envs["GITHUB_OWNER"] = cl.GitAuth.Owner
-	envs["TF_VAR_atlantis_repo_webhook_secret"] = cl.AtlantisWebhookSecret
-	envs["TF_VAR_kbot_ssh_public_key"] = cl.GitAuth.PublicKey
-	envs["ARM_CLIENT_ID"] = cl.AzureAuth.ClientID
-	envs["ARM_CLIENT_SECRET"] = cl.AzureAuth.ClientSecret
-	envs["ARM_TENANT_ID"] = cl.AzureAuth.TenantID
-	envs["ARM_SUBSCRIPTION_ID"] = cl.AzureAuth.SubscriptionID
+	envs = objectStorage.TerraformEnvs(envs, cl)
 
 	return envs
 }
 
-func GetGitlabTerraformEnvs(envs map[string]string, gid int, cl *pkgtypes.Cluster) map[string]string {
-	envs["GITLAB_TOKEN"] = cl.GitAuth.Token
-	envs["GITLAB_OWNER"] = cl.GitAuth.Owner
+// GetGitTerraformEnvs returns the environment the git terraform needs from the cloud
+func GetGitTerraformEnvs(envs map[string]string, cl *pkgtypes.Cluster) map[string]string {
 	envs["TF_VAR_atlantis_repo_webhook_secret"] = cl.AtlantisWebhookSecret
 	envs["TF_VAR_atlantis_repo_webhook_url"] = cl.AtlantisWebhookURL
 	envs["TF_VAR_kbot_ssh_public_key"] = cl.GitAuth.PublicKey
@@ -61,8 +50,10 @@ func GetGitlabTerraformEnvs(envs map[string]string, gid int, cl *pkgtypes.Cluste
 	envs["ARM_CLIENT_SECRET"] = cl.AzureAuth.ClientSecret
 	envs["ARM_TENANT_ID"] = cl.AzureAuth.TenantID
 	envs["ARM_SUBSCRIPTION_ID"] = cl.AzureAuth.SubscriptionID
-	envs["TF_VAR_owner_group_id"] = strconv.Itoa(gid)
-	envs["TF_VAR_gitlab_owner"] = cl.GitAuth.Owner
+
+	envs = gitHost.TerraformEnvs(envs, cl)
+
+	envs = objectStorage.TerraformEnvs(envs, cl)
 
 	return envs
 }
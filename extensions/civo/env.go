@@ -11,7 +11,9 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/konstructio/kubefirst-api/internal/gitHost"
 	"github.com/konstructio/kubefirst-api/internal/k8s"
+	"github.com/konstructio/kubefirst-api/internal/objectStorage"
 	"github.com/konstructio/kubefirst-api/internal/vault"
 	"github.com/konstructio/kubefirst-api/pkg/providerConfigs"
 	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
@@ -40,27 +42,13 @@ func GetCivoTerraformEnvs(envs map[string]string, cl *pkgtypes.Cluster) map[stri
 	envs["TF_VAR_aws_session_token"] = "" // allows for debugging
 	// envs["TF_LOG"] = "debug"
 
-	return envs
-}
-
-func GetGithubTerraformEnvs(envs map[string]string, cl *pkgtypes.Cluster) map[string]string {
-	envs["GITHUB_TOKEN"] = cl.GitAuth.Token
-	envs["GITHUB_OWNER"] = cl.GitAuth.Owner
-	envs["TF_VAR_atlantis_repo_webhook_secret"] = cl.AtlantisWebhookSecret
-	envs["TF_VAR_kbot_ssh_public_key"] = cl.GitAuth.PublicKey
-	envs["AWS_ACCESS_KEY_ID"] = cl.StateStoreCredentials.AccessKeyID
-	envs["AWS_SECRET_ACCESS_KEY"] = cl.StateStoreCredentials.SecretAccessKey
-	envs["TF_VAR_aws_access_key_id"] = cl.StateStoreCredentials.AccessKeyID
-	envs["TF_VAR_aws_secret_access_key"] = cl.StateStoreCredentials.SecretAccessKey
-	envs["AWS_SESSION_TOKEN"] = ""        // allows for debugging
-	envs["TF_VAR_aws_session_token"] = "" // allows for debugging
+	envs = objectStorage.TerraformEnvs(envs, cl)
 
 	return envs
 }
 
-func GetGitlabTerraformEnvs(envs map[string]string, gid int, cl *pkgtypes.Cluster) map[string]string {
-	envs["GITLAB_TOKEN"] = cl.GitAuth.Token
-	envs["GITLAB_OWNER"] = cl.GitAuth.Owner
+// GetGitTerraformEnvs returns the environment the git terraform needs from the cloud
+func GetGitTerraformEnvs(envs map[string]string, cl *pkgtypes.Cluster) map[string]string {
 	envs["TF_VAR_atlantis_repo_webhook_secret"] = cl.AtlantisWebhookSecret
 	envs["TF_VAR_atlantis_repo_webhook_url"] = cl.AtlantisWebhookURL
 	envs["TF_VAR_kbot_ssh_public_key"] = cl.GitAuth.PublicKey
@@ -68,11 +56,13 @@ func GetGitlabTerraformEnvs(envs map[string]string, gid int, cl *pkgtypes.Cluste
 	envs["AWS_SECRET_ACCESS_KEY"] = cl.StateStoreCredentials.SecretAccessKey
 	envs["TF_VAR_aws_access_key_id"] = cl.StateStoreCredentials.AccessKeyID
 	envs["TF_VAR_aws_secret_access_key"] = cl.StateStoreCredentials.SecretAccessKey
-	envs["TF_VAR_owner_group_id"] = strconv.Itoa(gid)
-	envs["TF_VAR_gitlab_owner"] = cl.GitAuth.Owner
 	envs["AWS_SESSION_TOKEN"] = ""        // allows for debugging
 	envs["TF_VAR_aws_session_token"] = "" // allows for debugging
 
+	envs = gitHost.TerraformEnvs(envs, cl)
+
+	envs = objectStorage.TerraformEnvs(envs, cl)
+
 	return envs
 }
 
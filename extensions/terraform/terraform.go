@@ -7,14 +7,25 @@ See the LICENSE file for more details.
 package terraform
 
 import (
+	"context"
 	"fmt"
 	"os"
 
 	"github.com/konstructio/kubefirst-api/internal"
+	"github.com/konstructio/kubefirst-api/internal/tracing"
 	log "github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
 )
 
-func initActionAutoApprove(terraformClientPath string, tfAction, tfEntrypoint string, tfEnvs map[string]string) error {
+// initActionAutoApprove runs terraform init and the action in a span of the
+// operation in ctx
+func initActionAutoApprove(ctx context.Context, terraformClientPath string, tfAction, tfEntrypoint string, tfEnvs map[string]string) error {
+	return tracing.Run(ctx, "terraform."+tfAction, func(context.Context) error {
+		return runActionAutoApprove(terraformClientPath, tfAction, tfEntrypoint, tfEnvs)
+	}, attribute.String("terraform.entrypoint", tfEntrypoint))
+}
+
+func runActionAutoApprove(terraformClientPath string, tfAction, tfEntrypoint string, tfEnvs map[string]string) error {
 	log.Printf("initActionAutoApprove - action: %s entrypoint: %s", tfAction, tfEntrypoint)
 
 	err := os.Chdir(tfEntrypoint)
@@ -40,18 +51,18 @@ func initActionAutoApprove(terraformClientPath string, tfAction, tfEntrypoint st
 	return nil
 }
 
-func InitApplyAutoApprove(terraformClientPath string, tfEntrypoint string, tfEnvs map[string]string) error {
+func InitApplyAutoApprove(ctx context.Context, terraformClientPath string, tfEntrypoint string, tfEnvs map[string]string) error {
 	tfAction := "apply"
-	err := initActionAutoApprove(terraformClientPath, tfAction, tfEntrypoint, tfEnvs)
+	err := initActionAutoApprove(ctx, terraformClientPath, tfAction, tfEntrypoint, tfEnvs)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func InitDestroyAutoApprove(terraformClientPath string, tfEntrypoint string, tfEnvs map[string]string) error {
+func InitDestroyAutoApprove(ctx context.Context, terraformClientPath string, tfEntrypoint string, tfEnvs map[string]string) error {
 	tfAction := "destroy"
-	err := initActionAutoApprove(terraformClientPath, tfAction, tfEntrypoint, tfEnvs)
+	err := initActionAutoApprove(ctx, terraformClientPath, tfAction, tfEntrypoint, tfEnvs)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,303 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package apierror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/konstructio/kubefirst-api/internal/shutdown"
+	"github.com/konstructio/kubefirst-api/internal/types"
+)
+
+// Code is the machine-readable kind of an error returned by the API and
+// stored on failed cluster records
+type Code string
+
+// Error codes
+const (
+	CodeInvalidRequest      Code = "invalid_request"
+	CodeUnauthorized        Code = "unauthorized"
+	CodeForbidden           Code = "forbidden"
+	CodeNotFound            Code = "not_found"
+	CodeClusterNotFound     Code = "cluster_not_found"
+	CodeConflict            Code = "conflict"
+	CodeOperationInProgress Code = "operation_in_progress"
+	CodeInvalidCredentials  Code = "invalid_credentials"
+	CodeQuotaExceeded       Code = "quota_exceeded"
+	CodeDNSNotDelegated     Code = "dns_not_delegated"
+	CodeInterrupted         Code = "interrupted"
+	CodeUpstream            Code = "upstream_error"
+	CodeUnavailable         Code = "unavailable"
+	CodeInternal            Code = "internal_error"
+)
+
+// statuses maps every code to the HTTP status it is returned with
+var statuses = map[Code]int{
+	CodeInvalidRequest:      http.StatusBadRequest,
+	CodeUnauthorized:        http.StatusUnauthorized,
+	CodeForbidden:           http.StatusForbidden,
+	CodeNotFound:            http.StatusNotFound,
+	CodeClusterNotFound:     http.StatusNotFound,
+	CodeConflict:            http.StatusConflict,
+	CodeOperationInProgress: http.StatusConflict,
+	CodeInvalidCredentials:  http.StatusUnprocessableEntity,
+	CodeQuotaExceeded:       http.StatusUnprocessableEntity,
+	CodeDNSNotDelegated:     http.StatusUnprocessableEntity,
+	CodeInterrupted:         http.StatusServiceUnavailable,
+	CodeUpstream:            http.StatusBadGateway,
+	CodeUnavailable:         http.StatusServiceUnavailable,
+	CodeInternal:            http.StatusInternalServerError,
+}
+
+// Status returns the HTTP status of the code
+func (c Code) Status() int {
+	if status, ok := statuses[c]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// Retryable reports whether a request failing with the code may succeed as is
+// later on
+func (c Code) Retryable() bool {
+	switch c {
+	case CodeOperationInProgress, CodeInterrupted, CodeUnavailable:
+		return true
+	}
+	return false
+}
+
+// Error is an error with a code, returned by the API as its envelope
+type Error struct {
+	Code    Code
+	Message string
+	// Details are the values the message is about, such as a cluster name
+	Details map[string]string
+	// Retryable reports whether the request may succeed as is later on
+	Retryable bool
+	// Provider is the cloud or git provider the error comes from
+	Provider string
+	// Err is the error the message was built from
+	Err error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Status returns the HTTP status of the error
+func (e *Error) Status() int {
+	return e.Code.Status()
+}
+
+// WithDetail adds a detail to the error
+func (e *Error) WithDetail(key, value string) *Error {
+	if e.Details == nil {
+		e.Details = map[string]string{}
+	}
+	e.Details[key] = value
+	return e
+}
+
+// Response returns the envelope of the error
+func (e *Error) Response() types.JSONFailureResponse {
+	return types.JSONFailureResponse{
+		Message:   e.Message,
+		Code:      string(e.Code),
+		Details:   e.Details,
+		Retryable: e.Retryable,
+		Provider:  e.Provider,
+	}
+}
+
+// New returns an error with a code
+func New(code Code, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...), Retryable: code.Retryable()}
+}
+
+// Wrap returns err with a code
+func Wrap(code Code, err error) *Error {
+	return &Error{Code: code, Message: err.Error(), Retryable: code.Retryable(), Err: err}
+}
+
+// ClusterNotFound returns the error of a missing cluster record
+func ClusterNotFound(clusterName string) *Error {
+	return New(CodeClusterNotFound, "cluster %q not found", clusterName).WithDetail("cluster_name", clusterName)
+}
+
+// OperationInProgress returns the error of a cluster already running an operation
+func OperationInProgress(clusterName, format string, args ...interface{}) *Error {
+	return New(CodeOperationInProgress, format, args...).WithDetail("cluster_name", clusterName)
+}
+
+// InvalidCredentials returns the error of missing or rejected provider credentials
+func InvalidCredentials(provider string, err error) *Error {
+	e := Wrap(CodeInvalidCredentials, err)
+	e.Provider = provider
+	return e
+}
+
+// DNSNotDelegated returns the error of a domain whose name servers do not
+// point to the dns provider
+func DNSNotDelegated(domainName, message string) *Error {
+	return New(CodeDNSNotDelegated, "%s", message).WithDetail("domain_name", domainName)
+}
+
+// Upstream returns the error of a provider call, classified as invalid
+// credentials or quota exceeded when its message tells so
+func Upstream(provider string, err error) *Error {
+	e := classify(err)
+	if e == nil {
+		e = classifyMessage(err)
+	}
+	if e == nil {
+		e = Wrap(CodeUpstream, err)
+		e.Retryable = isTimeout(err)
+	}
+	if e.Provider == "" {
+		e.Provider = provider
+	}
+	return e
+}
+
+// From returns err with a code, errors without one are internal errors
+func From(err error) *Error {
+	return FromStatus(http.StatusInternalServerError, err)
+}
+
+// FromStatus returns err with a code, errors without one get the code of status.
+// The messages of provider errors are only classified when they are wrapped
+// with Upstream, so a handler status is never overridden by a message.
+func FromStatus(status int, err error) *Error {
+	if e := classify(err); e != nil {
+		return e
+	}
+	return Wrap(codeOf(status), err)
+}
+
+// coder is implemented by the errors of other packages that have a code
+type coder interface {
+	APIError() *Error
+}
+
+// classify returns the code of the errors created by this package, by other
+// packages implementing coder and of interrupted operations
+func classify(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		if e.Message != err.Error() {
+			// keep the context added by the callers
+			copied := *e
+			copied.Message = err.Error()
+			return &copied
+		}
+		return e
+	}
+
+	var c coder
+	if errors.As(err, &c) {
+		e := c.APIError()
+		e.Message = err.Error()
+		e.Err = err
+		return e
+	}
+
+	if errors.Is(err, shutdown.ErrInterrupted) {
+		return Wrap(CodeInterrupted, err)
+	}
+
+	return nil
+}
+
+// classifyMessage returns the code of a provider error when its message tells
+// of a quota or credentials failure
+func classifyMessage(err error) *Error {
+	message := strings.ToLower(err.Error())
+	switch {
+	case containsAny(message, quotaMessages):
+		return Wrap(CodeQuotaExceeded, err)
+	case containsAny(message, credentialsMessages):
+		return Wrap(CodeInvalidCredentials, err)
+	}
+
+	return nil
+}
+
+// quotaMessages are found in the errors of the providers when a quota or a
+// limit of the account is reached
+var quotaMessages = []string{
+	"quota",
+	"limitexceeded",
+	"limit exceeded",
+	"exceeds your",
+	"insufficient_quota",
+}
+
+// credentialsMessages are found in the errors of the providers when the
+// credentials are missing, invalid or expired
+var credentialsMessages = []string{
+	"invalidclienttokenid",
+	"signaturedoesnotmatch",
+	"expiredtoken",
+	"authfailure",
+	"unrecognizedclientexception",
+	"invalid_grant",
+	"unable to authenticate you",
+	"authentication failed",
+	"invalid api key",
+	"invalid token",
+	"401 unauthorized",
+}
+
+func containsAny(message string, substrings []string) bool {
+	for _, s := range substrings {
+		if strings.Contains(message, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTimeout reports whether err is a timeout, which is worth retrying
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+}
+
+// codeOf returns the code of an HTTP status
+func codeOf(status int) Code {
+	switch status {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return CodeInvalidRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusBadGateway:
+		return CodeUpstream
+	case http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return CodeUnavailable
+	}
+
+	if status < http.StatusInternalServerError {
+		return CodeInvalidRequest
+	}
+	return CodeInternal
+}
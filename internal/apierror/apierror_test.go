@@ -0,0 +1,79 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package apierror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/konstructio/kubefirst-api/internal/shutdown"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type notFound struct{ name string }
+
+func (e *notFound) Error() string { return e.name + " not found" }
+
+func (e *notFound) APIError() *Error { return ClusterNotFound(e.name) }
+
+func TestFromStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		err        error
+		wantCode   Code
+		wantStatus int
+	}{
+		{name: "untyped keeps the status", status: http.StatusBadRequest, err: errors.New("bad input"), wantCode: CodeInvalidRequest, wantStatus: http.StatusBadRequest},
+		{name: "untyped internal", status: http.StatusInternalServerError, err: errors.New("boom"), wantCode: CodeInternal, wantStatus: http.StatusInternalServerError},
+		{name: "typed wins over the status", status: http.StatusBadRequest, err: fmt.Errorf("create: %w", OperationInProgress("dev", "busy")), wantCode: CodeOperationInProgress, wantStatus: http.StatusConflict},
+		{name: "coder", status: http.StatusBadRequest, err: fmt.Errorf("lookup: %w", &notFound{name: "dev"}), wantCode: CodeClusterNotFound, wantStatus: http.StatusNotFound},
+		{name: "interrupted", status: http.StatusInternalServerError, err: fmt.Errorf("step GitInit: %w", shutdown.ErrInterrupted), wantCode: CodeInterrupted, wantStatus: http.StatusServiceUnavailable},
+		{name: "kubernetes not found keeps the status", status: http.StatusBadRequest, err: k8serrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, "dev"), wantCode: CodeInvalidRequest, wantStatus: http.StatusBadRequest},
+		{name: "quota message keeps the status", status: http.StatusBadRequest, err: errors.New("the request exceeds your quota of 10 services"), wantCode: CodeInvalidRequest, wantStatus: http.StatusBadRequest},
+		{name: "credentials message keeps the status", status: http.StatusInternalServerError, err: errors.New("invalid token in the vault response"), wantCode: CodeInternal, wantStatus: http.StatusInternalServerError},
+		{name: "upstream quota", status: http.StatusBadRequest, err: fmt.Errorf("create: %w", Upstream("aws", errors.New("VcpuLimitExceeded: you have requested more vCPU capacity"))), wantCode: CodeQuotaExceeded, wantStatus: http.StatusUnprocessableEntity},
+		{name: "upstream credentials", status: http.StatusBadRequest, err: Upstream("aws", errors.New("InvalidClientTokenId: the security token included in the request is invalid")), wantCode: CodeInvalidCredentials, wantStatus: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := FromStatus(tt.status, tt.err)
+			if e.Code != tt.wantCode {
+				t.Errorf("expected code %q, got %q", tt.wantCode, e.Code)
+			}
+			if e.Status() != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, e.Status())
+			}
+			if e.Message != tt.err.Error() {
+				t.Errorf("expected message %q, got %q", tt.err.Error(), e.Message)
+			}
+		})
+	}
+}
+
+func TestUpstream(t *testing.T) {
+	e := Upstream("civo", errors.New("connection reset by peer"))
+	if e.Code != CodeUpstream || e.Provider != "civo" || e.Status() != http.StatusBadGateway {
+		t.Errorf("expected an upstream error of civo, got %+v", e)
+	}
+
+	e = Upstream("aws", errors.New("ExpiredToken: the security token included in the request is expired"))
+	if e.Code != CodeInvalidCredentials || e.Provider != "aws" {
+		t.Errorf("expected invalid aws credentials, got %+v", e)
+	}
+}
+
+func TestResponse(t *testing.T) {
+	r := OperationInProgress("dev", "dev is busy").Response()
+	if r.Code != string(CodeOperationInProgress) || !r.Retryable || r.Details["cluster_name"] != "dev" || r.Message != "dev is busy" {
+		t.Errorf("unexpected envelope %+v", r)
+	}
+}
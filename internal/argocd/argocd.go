@@ -16,9 +16,9 @@ import (
 	v1alpha1ArgocdApplication "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
 	pkg "github.com/konstructio/kubefirst-api/internal"
 	"github.com/konstructio/kubefirst-api/internal/argocdModel"
+	"github.com/konstructio/kubefirst-api/internal/env"
 	"github.com/konstructio/kubefirst-api/internal/httpCommon"
 	"github.com/rs/zerolog/log"
-	"github.com/spf13/viper"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	coreV1Types "k8s.io/client-go/kubernetes/typed/core/v1"
 )
@@ -69,12 +69,12 @@ type TLSConfig struct {
 }
 
 // Sync request ArgoCD to manual sync an application.
-func DeleteApplication(httpClient pkg.HTTPDoer, applicationName, argoCDToken, cascade string) (int, string, error) {
+func DeleteApplication(httpClient pkg.HTTPDoer, endpoint, applicationName, argoCDToken, cascade string) (int, string, error) {
 	params := url.Values{}
 	params.Add("cascade", cascade)
 	paramBody := strings.NewReader(params.Encode())
 
-	url := fmt.Sprintf("%s/api/v1/applications/%s", GetArgoEndpoint(), applicationName)
+	url := fmt.Sprintf("%s/api/v1/applications/%s", endpoint, applicationName)
 	log.Info().Msgf("deleting application %s using endpoint %q", applicationName, url)
 
 	req, err := http.NewRequest(http.MethodDelete, url, paramBody)
@@ -138,14 +138,11 @@ func GetArgoCDApplication(token string, applicationName string) (*argocdModel.V1
 
 // GetArgoEndpoint provides a solution in the interim for returning the correct
 // endpoint address
-func GetArgoEndpoint() string {
-	var argoCDLocalEndpoint string
-	if viper.GetString("argocd.local.service") != "" {
-		argoCDLocalEndpoint = viper.GetString("argocd.local.service")
-	} else {
-		argoCDLocalEndpoint = pkg.ArgocdPortForwardURL
+func GetArgoEndpoint(e env.Env) string {
+	if e.ArgoCDLocalService != "" {
+		return e.ArgoCDLocalService
 	}
-	return argoCDLocalEndpoint
+	return pkg.ArgocdPortForwardURL
 }
 
 func GetArgoCDApplicationObject(gitopsRepoURL, registryPath string) *v1alpha1ArgocdApplication.Application {
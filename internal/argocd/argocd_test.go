@@ -11,9 +11,8 @@ import (
 	"net/http"
 	"testing"
 
-	"github.com/konstructio/kubefirst-api/configs"
 	pkg "github.com/konstructio/kubefirst-api/internal"
-	"github.com/spf13/viper"
+	"github.com/konstructio/kubefirst-api/internal/env"
 )
 
 // this is called when ArgoCD is up and running
@@ -22,21 +21,17 @@ func TestArgoCDLivenessIntegration(t *testing.T) {
 		t.Skip("skipping integration test")
 	}
 
-	config, err := configs.ReadConfig()
-	if err != nil {
-		t.Fatal(err)
-	}
-
-	err = pkg.SetupViper(config, false)
+	cfg, err := env.Load(env.Options{})
 	if err != nil {
 		t.Fatal(err)
 	}
+	e := cfg.Get()
 
 	var argoURL string
-	if viper.GetString("cloud") == pkg.CloudK3d {
+	if e.CloudProvider == pkg.CloudK3d {
 		argoURL = "http://localhost:8080"
 	} else {
-		argoURL = fmt.Sprintf("https://argocd.%s", viper.GetString("aws.hostedzonename"))
+		argoURL = fmt.Sprintf("https://argocd.%s", e.DomainName)
 	}
 
 	req, err := http.NewRequest(http.MethodGet, argoURL, nil)
@@ -60,21 +55,17 @@ func TestArgoWorkflowLivenessIntegration(t *testing.T) {
 		t.Skip("skipping integration test")
 	}
 
-	config, err := configs.ReadConfig()
-	if err != nil {
-		t.Fatal(err)
-	}
-
-	err = pkg.SetupViper(config, false)
+	cfg, err := env.Load(env.Options{})
 	if err != nil {
 		t.Fatal(err)
 	}
+	e := cfg.Get()
 
 	var argoURL string
-	if viper.GetString("cloud") == pkg.CloudK3d {
+	if e.CloudProvider == pkg.CloudK3d {
 		argoURL = "http://localhost:2746"
 	} else {
-		argoURL = fmt.Sprintf("https://argo.%s", viper.GetString("aws.hostedzonename"))
+		argoURL = fmt.Sprintf("https://argo.%s", e.DomainName)
 	}
 
 	req, err := http.NewRequest(http.MethodGet, argoURL, nil)
@@ -50,7 +50,7 @@ func getToken(endpoint, username, password string) (string, error) {
 }
 
 // GetArgoCDToken expects ArgoCD username and password, and returns a ArgoCD Bearer Token. ArgoCD username and password
-// are stored in the viper file.
+// are provided by the caller.
 func GetArgoCDToken(username string, password string) (string, error) {
 	url := pkg.ArgoCDLocalBaseURL + "/session"
 	return getToken(url, username, password)
@@ -11,6 +11,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
 	"slices"
 	"strconv"
@@ -19,14 +20,14 @@ import (
 	ec2Types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/route53"
 	route53Types "github.com/aws/aws-sdk-go-v2/service/route53/types"
 	"github.com/aws/aws-sdk-go-v2/service/ssm"
-	"github.com/konstructio/kubefirst-api/internal/constants"
-	"github.com/konstructio/kubefirst-api/internal/env"
+	"github.com/konstructio/kubefirst-api/internal/httpCommon"
 	"github.com/konstructio/kubefirst-api/internal/utils"
 	log "github.com/rs/zerolog/log"
 )
@@ -52,17 +53,14 @@ const (
 	validationRecordValue     string = "domain record propagated"
 )
 
-// New instantiates a new AWS configuration
-func New() (*Configuration, error) {
-	env, err := env.GetEnv(constants.SilenceGetEnv)
-	if err != nil {
-		return nil, fmt.Errorf("unable to get environment variables: %w", err)
-	}
-
+// New instantiates a new AWS configuration for a region and a shared
+// configuration profile
+func New(region, profile string) (*Configuration, error) {
 	awsClient, err := config.LoadDefaultConfig(
 		context.Background(),
-		config.WithRegion(env.AWSRegion),
-		config.WithSharedConfigProfile(env.AWSProfile),
+		config.WithRegion(region),
+		config.WithSharedConfigProfile(profile),
+		config.WithHTTPClient(HTTPClient()),
 	)
 	if err != nil {
 		log.Error().Msgf("Could not create AWS config: %s", err.Error())
@@ -286,6 +284,7 @@ func NewAwsV2(region string) (aws.Config, error) {
 		context.Background(),
 		config.WithRegion(region),
 		config.WithSharedConfigProfile(profile),
+		config.WithHTTPClient(HTTPClient()),
 	)
 	if err != nil {
 		return aws.Config{}, fmt.Errorf("unable to create aws client for region %q: %w", region, err)
@@ -303,6 +302,7 @@ func NewAwsV3(region, accessKeyID, secretAccessKey, sessionToken string) (aws.Co
 			secretAccessKey,
 			sessionToken,
 		)),
+		config.WithHTTPClient(HTTPClient()),
 	)
 	if err != nil {
 		return aws.Config{}, fmt.Errorf("unable to create aws client for region %q with provided credentials: %w", region, err)
@@ -311,6 +311,14 @@ func NewAwsV3(region, accessKeyID, secretAccessKey, sessionToken string) (aws.Co
 	return awsClient, nil
 }
 
+// HTTPClient returns an aws sdk http client with the outbound configuration
+func HTTPClient() *awshttp.BuildableClient {
+	return awshttp.NewBuildableClient().WithTransportOptions(func(tr *http.Transport) {
+		tr.Proxy = httpCommon.Proxy
+		tr.TLSClientConfig = httpCommon.TLSConfig(false)
+	})
+}
+
 // GetRegions lists all available regions
 func (conf *Configuration) GetRegions() ([]string, error) {
 	ec2Client := ec2.NewFromConfig(conf.Config)
@@ -0,0 +1,372 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package catalogMirror //nolint:revive,stylecheck // matches existing package naming
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/konstructio/kubefirst-api/internal/downloadManager"
+	"github.com/konstructio/kubefirst-api/internal/env"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	log "github.com/rs/zerolog/log"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/retry"
+)
+
+const (
+	ociPrefix = "oci://"
+	indexFile = "index.yaml"
+)
+
+var (
+	mu        sync.Mutex
+	settings  env.Env
+	mirrorDir string
+)
+
+// Configure sets the mirror settings from the configuration and forgets the
+// loaded mirror, so the next use resolves the configured one
+func Configure(e env.Env) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	settings = e
+	mirrorDir = ""
+}
+
+// Enabled reports whether the gitops catalog should be served from a local mirror
+// instead of github.com
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+
+	return settings.GitopsCatalogMirror != ""
+}
+
+// Dir returns the local directory holding the verified gitops catalog mirror. The
+// mirror is resolved, verified and unpacked on first use and reused afterwards.
+func Dir() (string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if mirrorDir != "" {
+		return mirrorDir, nil
+	}
+
+	if settings.GitopsCatalogMirror == "" {
+		return "", errors.New("no gitops catalog mirror configured")
+	}
+
+	dir, err := load(settings)
+	if err != nil {
+		return "", fmt.Errorf("error loading gitops catalog mirror %q: %w", settings.GitopsCatalogMirror, err)
+	}
+
+	mirrorDir = dir
+	return mirrorDir, nil
+}
+
+// ReadIndex returns the contents of the mirrored catalog index
+func ReadIndex() ([]byte, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, indexFile))
+	if err != nil {
+		return nil, fmt.Errorf("error reading gitops catalog mirror index: %w", err)
+	}
+
+	return b, nil
+}
+
+// ReadApplicationDirectory returns the contents of every file in a mirrored
+// catalog application's directory
+func ReadApplicationDirectory(applicationName string) ([][]byte, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	appDir := filepath.Join(dir, filepath.Clean(applicationName))
+	entries, err := os.ReadDir(appDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return [][]byte{}, nil
+		}
+		return nil, fmt.Errorf("error reading gitops catalog mirror app directory %q: %w", applicationName, err)
+	}
+
+	var res [][]byte
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		b, err := os.ReadFile(filepath.Join(appDir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("error reading gitops catalog mirror file %q: %w", entry.Name(), err)
+		}
+		res = append(res, b)
+	}
+
+	return res, nil
+}
+
+// Clone copies the mirror into gitopsCatalogDir as a git repository with a single
+// commit. The commit is created with a fixed author and timestamp so that its hash
+// only depends on the mirror contents and can be recorded as the catalog revision.
+func Clone(gitopsCatalogDir string) error {
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+
+	if err := os.CopyFS(gitopsCatalogDir, os.DirFS(dir)); err != nil {
+		return fmt.Errorf("error copying gitops catalog mirror: %w", err)
+	}
+
+	repo, err := git.PlainInit(gitopsCatalogDir, false)
+	if err != nil {
+		return fmt.Errorf("error initializing gitops catalog mirror repository: %w", err)
+	}
+
+	w, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("error getting gitops catalog mirror worktree: %w", err)
+	}
+
+	if err := w.AddGlob("."); err != nil {
+		return fmt.Errorf("error staging gitops catalog mirror: %w", err)
+	}
+
+	signature := &object.Signature{
+		Name:  "kbot",
+		Email: "kbot@kubefirst.com",
+		When:  time.Unix(0, 0).UTC(),
+	}
+	_, err = w.Commit("gitops catalog mirror", &git.CommitOptions{
+		Author:    signature,
+		Committer: signature,
+	})
+	if err != nil {
+		return fmt.Errorf("error committing gitops catalog mirror: %w", err)
+	}
+
+	return nil
+}
+
+// load resolves the configured mirror source to a verified local directory
+func load(e env.Env) (string, error) {
+	source := e.GitopsCatalogMirror
+
+	if !strings.HasPrefix(source, ociPrefix) {
+		info, err := os.Stat(source)
+		if err != nil {
+			return "", fmt.Errorf("unable to stat mirror: %w", err)
+		}
+
+		// A mounted directory is trusted as is: there is no archive to check the
+		// checksum or the signature against, so its contents are not verified
+		if info.IsDir() {
+			if e.GitopsCatalogMirrorSHA256 != "" || e.GitopsCatalogMirrorSignature != "" {
+				return "", errors.New("checksum and signature verification require a tarball or OCI mirror")
+			}
+			if _, err := os.Stat(filepath.Join(source, indexFile)); err != nil {
+				return "", fmt.Errorf("mirror directory does not contain %s: %w", indexFile, err)
+			}
+			log.Info().Msgf("serving gitops catalog from unverified mirror directory %s", source)
+			return source, nil
+		}
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("error getting home path: %w", err)
+	}
+
+	workDir := filepath.Join(homeDir, ".k1", "gitops-catalog-mirror")
+	if err := os.RemoveAll(workDir); err != nil {
+		return "", fmt.Errorf("error cleaning mirror directory: %w", err)
+	}
+	if err := os.MkdirAll(workDir, 0o755); err != nil {
+		return "", fmt.Errorf("error creating mirror directory: %w", err)
+	}
+
+	archive := source
+	if strings.HasPrefix(source, ociPrefix) {
+		archive = filepath.Join(workDir, "gitops-catalog.tar.gz")
+		if err := pullArtifact(e, strings.TrimPrefix(source, ociPrefix), archive); err != nil {
+			return "", err
+		}
+	}
+
+	if err := verify(e, archive); err != nil {
+		return "", err
+	}
+
+	f, err := os.Open(archive)
+	if err != nil {
+		return "", fmt.Errorf("unable to open mirror archive: %w", err)
+	}
+	defer f.Close()
+
+	extractDir := filepath.Join(workDir, "catalog")
+	if err := downloadManager.ExtractTarGz(f, extractDir); err != nil {
+		return "", fmt.Errorf("unable to extract mirror archive: %w", err)
+	}
+
+	root, err := findCatalogRoot(extractDir)
+	if err != nil {
+		return "", err
+	}
+
+	log.Info().Msgf("serving gitops catalog from verified mirror %s", source)
+	return root, nil
+}
+
+// verify checks the mirror archive against the configured checksum and signature.
+// An archive with neither is refused unless GITOPS_CATALOG_MIRROR_INSECURE is set.
+func verify(e env.Env, archive string) error {
+	if e.GitopsCatalogMirrorSHA256 == "" && e.GitopsCatalogMirrorSignature == "" {
+		if !e.GitopsCatalogMirrorInsecure {
+			return errors.New("mirror has no checksum or signature to verify, set GITOPS_CATALOG_MIRROR_SHA256 or GITOPS_CATALOG_MIRROR_SIGNATURE, or GITOPS_CATALOG_MIRROR_INSECURE to serve it unverified")
+		}
+		log.Warn().Msg("GITOPS_CATALOG_MIRROR_INSECURE is set, serving the gitops catalog mirror without verification")
+		return nil
+	}
+
+	if e.GitopsCatalogMirrorSHA256 != "" {
+		if err := downloadManager.VerifySHA256(archive, e.GitopsCatalogMirrorSHA256); err != nil {
+			return fmt.Errorf("mirror checksum verification failed: %w", err)
+		}
+	}
+
+	if e.GitopsCatalogMirrorSignature == "" {
+		return nil
+	}
+
+	if e.GitopsCatalogMirrorPublicKey == "" {
+		return errors.New("GITOPS_CATALOG_MIRROR_PUBLIC_KEY is required to verify the mirror signature")
+	}
+
+	sig, err := os.Open(e.GitopsCatalogMirrorSignature)
+	if err != nil {
+		return fmt.Errorf("unable to open mirror signature: %w", err)
+	}
+	defer sig.Close()
+
+	key, err := os.Open(e.GitopsCatalogMirrorPublicKey)
+	if err != nil {
+		return fmt.Errorf("unable to open mirror public key: %w", err)
+	}
+	defer key.Close()
+
+	if err := downloadManager.VerifyDetachedSignature(archive, sig, key); err != nil {
+		return fmt.Errorf("mirror signature verification failed: %w", err)
+	}
+
+	return nil
+}
+
+// pullArtifact downloads the first layer of an OCI artifact to a local file. The
+// registry client verifies the layer digest while it is read.
+func pullArtifact(e env.Env, reference, target string) error {
+	ctx := context.Background()
+
+	repo, err := remote.NewRepository(reference)
+	if err != nil {
+		return fmt.Errorf("invalid OCI reference %q: %w", reference, err)
+	}
+	repo.PlainHTTP = e.GitopsCatalogMirrorPlainHTTP
+
+	client := &auth.Client{
+		Client: retry.DefaultClient,
+		Cache:  auth.NewCache(),
+	}
+	if e.GitopsCatalogMirrorUsername != "" {
+		client.Credential = auth.StaticCredential(repo.Reference.Registry, auth.Credential{
+			Username: e.GitopsCatalogMirrorUsername,
+			Password: e.GitopsCatalogMirrorPassword,
+		})
+	}
+	repo.Client = client
+
+	_, manifestBytes, err := oras.FetchBytes(ctx, repo, repo.Reference.Reference, oras.DefaultFetchBytesOptions)
+	if err != nil {
+		return fmt.Errorf("unable to fetch OCI manifest for %q: %w", reference, err)
+	}
+
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+		return fmt.Errorf("unable to parse OCI manifest for %q: %w", reference, err)
+	}
+
+	if len(manifest.Layers) == 0 {
+		return fmt.Errorf("OCI artifact %q has no layers", reference)
+	}
+	layer := manifest.Layers[0]
+
+	rc, err := repo.Fetch(ctx, layer)
+	if err != nil {
+		return fmt.Errorf("unable to fetch OCI layer %s: %w", layer.Digest, err)
+	}
+	defer rc.Close()
+
+	out, err := os.Create(target)
+	if err != nil {
+		return fmt.Errorf("unable to create file %q: %w", target, err)
+	}
+	defer out.Close()
+
+	vr := content.NewVerifyReader(rc, layer)
+	if _, err := io.Copy(out, vr); err != nil {
+		return fmt.Errorf("unable to download OCI layer %s: %w", layer.Digest, err)
+	}
+	if err := vr.Verify(); err != nil {
+		return fmt.Errorf("OCI layer %s failed verification: %w", layer.Digest, err)
+	}
+
+	return nil
+}
+
+// findCatalogRoot returns the directory holding the catalog index, allowing
+// tarballs that wrap the catalog in a single top level directory
+func findCatalogRoot(dir string) (string, error) {
+	if _, err := os.Stat(filepath.Join(dir, indexFile)); err == nil {
+		return dir, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("unable to read extracted mirror: %w", err)
+	}
+
+	if len(entries) == 1 && entries[0].IsDir() {
+		nested := filepath.Join(dir, entries[0].Name())
+		if _, err := os.Stat(filepath.Join(nested, indexFile)); err == nil {
+			return nested, nil
+		}
+	}
+
+	return "", fmt.Errorf("mirror archive does not contain %s", indexFile)
+}
@@ -0,0 +1,128 @@
+package catalogMirror //nolint:revive,stylecheck // matches existing package naming
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/konstructio/kubefirst-api/internal/downloadManager"
+	"github.com/konstructio/kubefirst-api/internal/env"
+)
+
+func writeCatalogTarball(t *testing.T, path string) {
+	t.Helper()
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	files := map[string]string{
+		"gitops-catalog/index.yaml":           "name: test\napps:\n- name: example\n",
+		"gitops-catalog/example/example.yaml": "kind: Application\n",
+	}
+	for name, body := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(body)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if _, err := tw.Write([]byte(body)); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestMirrorTarball(t *testing.T) {
+	tmp := t.TempDir()
+	archive := filepath.Join(tmp, "catalog.tar.gz")
+	writeCatalogTarball(t, archive)
+
+	sum, err := downloadManager.FileSHA256(archive)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	t.Setenv("HOME", tmp)
+	defer Configure(env.Env{})
+
+	t.Run("checksum mismatch", func(t *testing.T) {
+		Configure(env.Env{GitopsCatalogMirror: archive, GitopsCatalogMirrorSHA256: "0000"})
+
+		if _, err := Dir(); err == nil {
+			t.Fatal("expected checksum verification error")
+		}
+	})
+
+	t.Run("unverified mirror", func(t *testing.T) {
+		Configure(env.Env{GitopsCatalogMirror: archive})
+
+		if _, err := Dir(); err == nil {
+			t.Fatal("expected an archive without checksum or signature to be refused")
+		}
+	})
+
+	t.Run("insecure mirror", func(t *testing.T) {
+		Configure(env.Env{GitopsCatalogMirror: archive, GitopsCatalogMirrorInsecure: true})
+
+		if _, err := Dir(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	})
+
+	t.Run("verified mirror", func(t *testing.T) {
+		Configure(env.Env{GitopsCatalogMirror: archive, GitopsCatalogMirrorSHA256: sum})
+
+		index, err := ReadIndex()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if len(index) == 0 {
+			t.Error("expected index contents")
+		}
+
+		files, err := ReadApplicationDirectory("example")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if len(files) != 1 {
+			t.Errorf("expected 1 file in app directory, got %d", len(files))
+		}
+	})
+
+	t.Run("clone revision is stable", func(t *testing.T) {
+		revisions := []string{}
+		for _, dir := range []string{filepath.Join(tmp, "a"), filepath.Join(tmp, "b")} {
+			if err := Clone(dir); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			repo, err := git.PlainOpen(dir)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			head, err := repo.Head()
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			revisions = append(revisions, head.Hash().String())
+		}
+
+		if revisions[0] != revisions[1] {
+			t.Errorf("expected identical revisions, got %v", revisions)
+		}
+	})
+
+	mirrorDir = ""
+}
@@ -10,6 +10,9 @@ import (
 	"github.com/civo/civogo"
 )
 
+// NewCivo returns a civo client. civogo replaces its transport on every request,
+// so it only honours the outbound certificate authorities exported through
+// SSL_CERT_FILE and not the outbound proxy.
 func NewCivo(civoToken string, region string) *civogo.Client {
 	civoClient, _ := civogo.NewClient(civoToken, region)
 
@@ -16,20 +16,29 @@ type HTTPDoer interface {
 
 var SupportedPlatforms = []string{
 	"akamai-github",
+	"akamai-gitea",
 	"aws-github",
 	"aws-gitlab",
+	"aws-gitea",
 	"azure-github",
 	"azure-gitlab",
+	"azure-gitea",
 	"civo-github",
 	"civo-gitlab",
+	"civo-gitea",
 	"digitalocean-github",
 	"digitalocean-gitlab",
+	"digitalocean-gitea",
 	"google-github",
 	"google-gitlab",
+	"google-gitea",
 	"k3d-github",
 	"k3d-gitlab",
+	"k3d-gitea",
 	"k3s-gitlab",
 	"k3s-github",
+	"k3s-gitea",
 	"vultr-github",
 	"vultr-gitlab",
+	"vultr-gitea",
 }
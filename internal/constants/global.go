@@ -17,8 +17,7 @@ const (
 	ClusterStatusDeleted      = "deleted"
 	ClusterStatusDeleting     = "deleting"
 	ClusterStatusError        = "error"
+	ClusterStatusInterrupted  = "interrupted"
 	ClusterStatusProvisioned  = "provisioned"
 	ClusterStatusProvisioning = "provisioning"
-
-	SilenceGetEnv = true
 )
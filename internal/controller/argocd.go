@@ -55,14 +55,14 @@ func (clctrl *ClusterController) InstallArgoCD() error {
 
 		log.Info().Msg("installing argocd")
 
-		telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.ArgoCDInstallStarted, "")
+		clctrl.sendEvent(telemetry.ArgoCDInstallStarted, "")
 		err = argocd.ApplyArgoCDKustomize(kcfg.Clientset, argoCDInstallPath)
 		if err != nil {
-			telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.ArgoCDInstallFailed, err.Error())
+			clctrl.sendEvent(telemetry.ArgoCDInstallFailed, err.Error())
 			return fmt.Errorf("failed to apply ArgoCD kustomize: %w", err)
 		}
 
-		telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.ArgoCDInstallCompleted, "")
+		clctrl.sendEvent(telemetry.ArgoCDInstallCompleted, "")
 
 		// Wait for ArgoCD to be ready
 		_, err = k8s.VerifyArgoCDReadiness(kcfg.Clientset, true, 300)
@@ -212,7 +212,7 @@ func (clctrl *ClusterController) DeployRegistryApplication() error {
 			}
 		}
 
-		telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.CreateRegistryStarted, "")
+		clctrl.sendEvent(telemetry.CreateRegistryStarted, "")
 		argocdClient, err := argocdapi.NewForConfig(kcfg.RestConfig)
 		if err != nil {
 			return fmt.Errorf("failed to create ArgoCD client: %w", err)
@@ -267,7 +267,7 @@ func (clctrl *ClusterController) DeployRegistryApplication() error {
 			break
 		}
 
-		telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.CreateRegistryCompleted, "")
+		clctrl.sendEvent(telemetry.CreateRegistryCompleted, "")
 
 		clctrl.Cluster.ArgoCDCreateRegistryCheck = true
 		err = secrets.UpdateCluster(clctrl.KubernetesClient, clctrl.Cluster)
@@ -21,10 +21,9 @@ import (
 	k3sext "github.com/konstructio/kubefirst-api/extensions/k3s"
 	terraformext "github.com/konstructio/kubefirst-api/extensions/terraform"
 	vultrext "github.com/konstructio/kubefirst-api/extensions/vultr"
-	"github.com/konstructio/kubefirst-api/internal/constants"
-	"github.com/konstructio/kubefirst-api/internal/env"
 	gitShim "github.com/konstructio/kubefirst-api/internal/gitShim"
 	"github.com/konstructio/kubefirst-api/internal/k8s"
+	"github.com/konstructio/kubefirst-api/internal/objectStorage"
 	"github.com/konstructio/kubefirst-api/internal/secrets"
 	"github.com/konstructio/kubefirst-api/pkg/providerConfigs"
 	"github.com/kubefirst/metrics-client/pkg/telemetry"
@@ -45,7 +44,7 @@ func (clctrl *ClusterController) CreateCluster() error {
 		tfEntrypoint := clctrl.ProviderConfig.GitopsDir + fmt.Sprintf("/terraform/%s", clctrl.CloudProvider)
 		tfEnvs := map[string]string{}
 
-		telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.CloudTerraformApplyStarted, "")
+		clctrl.sendEvent(telemetry.CloudTerraformApplyStarted, "")
 
 		log.Info().Msgf("creating %s cluster", clctrl.CloudProvider)
 
@@ -80,20 +79,20 @@ func (clctrl *ClusterController) CreateCluster() error {
 			tfEnvs = k3sext.GetK3sTerraformEnvs(tfEnvs, cl)
 		}
 
-		err := terraformext.InitApplyAutoApprove(clctrl.ProviderConfig.TerraformClient, tfEntrypoint, tfEnvs)
+		err := terraformext.InitApplyAutoApprove(clctrl.traceContext(), clctrl.ProviderConfig.TerraformClient, tfEntrypoint, tfEnvs)
 		if err != nil {
 			log.Error().Msgf("error applying cloud terraform: %s", err)
 
 			log.Info().Msg("sleeping 10 seconds before retrying terraform execution once more")
 			time.Sleep(10 * time.Second)
 
-			err = terraformext.InitApplyAutoApprove(clctrl.ProviderConfig.TerraformClient, tfEntrypoint, tfEnvs)
+			err = terraformext.InitApplyAutoApprove(clctrl.traceContext(), clctrl.ProviderConfig.TerraformClient, tfEntrypoint, tfEnvs)
 			if err != nil {
-				telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.CloudTerraformApplyFailed, err.Error())
+				clctrl.sendEvent(telemetry.CloudTerraformApplyFailed, err.Error())
 				clctrl.Cluster.CloudTerraformApplyFailedCheck = true
 
 				if err := secrets.UpdateCluster(clctrl.KubernetesClient, clctrl.Cluster); err != nil {
-					telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.CloudTerraformApplyFailed, err.Error())
+					clctrl.sendEvent(telemetry.CloudTerraformApplyFailed, err.Error())
 					return fmt.Errorf("failed to update cluster after terraform apply failed: %w", err)
 				}
 
@@ -103,7 +102,7 @@ func (clctrl *ClusterController) CreateCluster() error {
 		}
 
 		log.Info().Msgf("created %s cloud resources", clctrl.CloudProvider)
-		telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.CloudTerraformApplyCompleted, "")
+		clctrl.sendEvent(telemetry.CloudTerraformApplyCompleted, "")
 
 		clctrl.Cluster.CloudTerraformApplyCheck = true
 		clctrl.Cluster.CloudTerraformApplyFailedCheck = false
@@ -167,8 +166,6 @@ func (clctrl *ClusterController) CreateTokens(kind string) interface{} {
 			return fmt.Errorf("failed to get repo URL for gitops tokens: %w", err)
 		}
 
-		env, _ := env.GetEnv(constants.SilenceGetEnv)
-
 		// Default gitopsTemplateTokens
 		gitopsTemplateTokens := &providerConfigs.GitopsDirectoryValues{
 			AlertsEmail:               clctrl.AlertsEmail,
@@ -183,7 +180,8 @@ func (clctrl *ClusterController) CreateTokens(kind string) interface{} {
 			KubefirstTeam:             clctrl.KubefirstTeam,
 			NodeType:                  clctrl.NodeType,
 			NodeCount:                 clctrl.NodeCount,
-			KubefirstVersion:          env.KubefirstVersion,
+			KubefirstVersion:          clctrl.Env.KubefirstVersion,
+			UseTelemetry:              strconv.FormatBool(cl.TelemetryEnabled()),
 			Kubeconfig:                clctrl.ProviderConfig.Kubeconfig, // AWS
 			KubeconfigPath:            clctrl.ProviderConfig.Kubeconfig, // Not AWS
 
@@ -207,7 +205,7 @@ func (clctrl *ClusterController) CreateTokens(kind string) interface{} {
 			GitURL:               clctrl.GitopsTemplateURL,
 			GitopsRepoURL:        destinationGitopsRepoURL,
 
-			GitHubHost:  fmt.Sprintf("https://github.com/%s/gitops.git", clctrl.GitAuth.Owner),
+			GitHubHost:  fmt.Sprintf("https://%s/%s/gitops.git", clctrl.GitHost, clctrl.GitAuth.Owner),
 			GitHubOwner: clctrl.GitAuth.Owner,
 			GitHubUser:  clctrl.GitAuth.User,
 
@@ -267,7 +265,7 @@ func (clctrl *ClusterController) CreateTokens(kind string) interface{} {
 			}
 		case "azure":
 			gitopsTemplateTokens.AzureStorageResourceGroup = fmt.Sprintf("%s-state", clctrl.ClusterName)
-			gitopsTemplateTokens.AzureStorageContainerName = azureTerraformContainer
+			gitopsTemplateTokens.AzureStorageContainerName = objectStorage.AzureTerraformContainer
 			gitopsTemplateTokens.AzureDNSZoneResourceGroup = clctrl.AzureDNSZoneResourceGroup
 		case "k3s":
 			gitopsTemplateTokens.K3sServersPrivateIps = clctrl.K3sAuth.K3sServersPrivateIps
@@ -427,6 +425,7 @@ func (clctrl *ClusterController) ContainerRegistryAuth() (string, error) {
 			GitlabGroupFlag:       clctrl.GitAuth.Owner,
 			GithubOwner:           clctrl.GitAuth.Owner,
 			ContainerRegistryHost: clctrl.ContainerRegistryHost,
+			GitServer:             clctrl.GitServer,
 			Clientset:             kcfg.Clientset,
 		}
 		containerRegistryAuthToken, err := gitShim.CreateContainerRegistrySecret(&containerRegistryAuth)
@@ -458,6 +457,7 @@ func (clctrl *ClusterController) ContainerRegistryAuth() (string, error) {
 		GitlabGroupFlag:       clctrl.GitAuth.Owner,
 		GithubOwner:           clctrl.GitAuth.Owner,
 		ContainerRegistryHost: clctrl.ContainerRegistryHost,
+		GitServer:             clctrl.GitServer,
 		Clientset:             kcfg.Clientset,
 	}
 	containerRegistryAuthToken, err := gitShim.CreateContainerRegistrySecret(&containerRegistryAuth)
@@ -1,3 +0,0 @@
-package controller
-
-const azureTerraformContainer = "terraform"
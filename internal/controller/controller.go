@@ -16,18 +16,20 @@ import (
 	"time"
 
 	runtime "github.com/konstructio/kubefirst-api/internal"
+	"github.com/konstructio/kubefirst-api/internal/apierror"
 	awsinternal "github.com/konstructio/kubefirst-api/internal/aws"
 	azureinternal "github.com/konstructio/kubefirst-api/internal/azure"
 	"github.com/konstructio/kubefirst-api/internal/constants"
 	"github.com/konstructio/kubefirst-api/internal/env"
-	"github.com/konstructio/kubefirst-api/internal/github"
-	"github.com/konstructio/kubefirst-api/internal/gitlab"
+	"github.com/konstructio/kubefirst-api/internal/gitHost"
+	"github.com/konstructio/kubefirst-api/internal/gitProvider"
 	"github.com/konstructio/kubefirst-api/internal/k8s"
+	"github.com/konstructio/kubefirst-api/internal/metrics"
+	"github.com/konstructio/kubefirst-api/internal/objectStorage"
 	"github.com/konstructio/kubefirst-api/internal/secrets"
-	"github.com/konstructio/kubefirst-api/internal/services"
+	apitelemetry "github.com/konstructio/kubefirst-api/internal/telemetry"
 	"github.com/konstructio/kubefirst-api/internal/utils"
 	google "github.com/konstructio/kubefirst-api/pkg/google"
-	"github.com/konstructio/kubefirst-api/pkg/handlers"
 	"github.com/konstructio/kubefirst-api/pkg/providerConfigs"
 	"github.com/konstructio/kubefirst-api/pkg/types"
 	"github.com/kubefirst/metrics-client/pkg/telemetry"
@@ -77,6 +79,7 @@ type ClusterController struct {
 	GitProvider          string
 	GitProtocol          string
 	GitHost              string
+	GitServer            types.GitServer
 	GitOwner             string
 	GitUser              string
 	GitToken             string
@@ -111,6 +114,12 @@ type ClusterController struct {
 	// Telemetry
 	TelemetryEvent telemetry.TelemetryEvent
 
+	// Context carries the span of the running operation or step
+	Context context.Context
+
+	// Env is the API configuration when the operation started
+	Env env.Env
+
 	// Azure
 	AzureDNSZoneResourceGroup string
 
@@ -118,6 +127,7 @@ type ClusterController struct {
 	AwsClient    *awsinternal.Configuration
 	AzureClient  *azureinternal.Client
 	GoogleClient google.Configuration
+	GitClient    gitProvider.GitProvider
 	Kcfg         *k8s.KubernetesClient
 	Cluster      types.Cluster
 }
@@ -127,15 +137,12 @@ func (clctrl *ClusterController) InitController(def *types.ClusterDefinition) er
 	// Create k1 dir if it doesn't exist
 	utils.CreateK1Directory(def.ClusterName)
 
-	// Get Environment variables
-	env, _ := env.GetEnv(constants.SilenceGetEnv)
-
 	kcfg := utils.GetKubernetesClient(def.ClusterName)
 	clctrl.KubernetesClient = kcfg.Clientset
 
 	// If on local environment, automatically create the namespace
 	// if it doesn't exist
-	if env.K1LocalDebug {
+	if clctrl.Env.K1LocalDebug {
 		if err := utils.CreateKubefirstNamespaceIfNotExists(clctrl.KubernetesClient); err != nil {
 			return fmt.Errorf("error creating Kubefirst namespace: %w", err)
 		}
@@ -179,21 +186,21 @@ func (clctrl *ClusterController) InitController(def *types.ClusterDefinition) er
 	}
 
 	telemetryEvent := telemetry.TelemetryEvent{
-		CliVersion:        env.KubefirstVersion,
-		CloudProvider:     env.CloudProvider,
-		ClusterID:         env.ClusterID,
-		ClusterType:       env.ClusterType,
-		DomainName:        env.DomainName,
+		CliVersion:        clctrl.Env.KubefirstVersion,
+		CloudProvider:     clctrl.Env.CloudProvider,
+		ClusterID:         clctrl.Env.ClusterID,
+		ClusterType:       clctrl.Env.ClusterType,
+		DomainName:        clctrl.Env.DomainName,
 		ErrorMessage:      "",
-		GitProvider:       env.GitProvider,
-		InstallMethod:     env.InstallMethod,
+		GitProvider:       clctrl.Env.GitProvider,
+		InstallMethod:     clctrl.Env.InstallMethod,
 		KubefirstClient:   "api",
-		KubefirstTeam:     env.KubefirstTeam,
-		KubefirstTeamInfo: env.KubefirstTeamInfo,
-		MachineID:         env.ClusterID,
-		ParentClusterId:   env.ParentClusterID,
+		KubefirstTeam:     clctrl.Env.KubefirstTeam,
+		KubefirstTeamInfo: clctrl.Env.KubefirstTeamInfo,
+		MachineID:         clctrl.Env.ClusterID,
+		ParentClusterId:   clctrl.Env.ParentClusterID,
 		MetricName:        telemetry.ClusterInstallCompleted,
-		UserId:            env.ClusterID,
+		UserId:            clctrl.Env.ClusterID,
 	}
 	clctrl.TelemetryEvent = telemetryEvent
 
@@ -224,8 +231,8 @@ func (clctrl *ClusterController) InitController(def *types.ClusterDefinition) er
 	clctrl.K3sAuth = def.K3sAuth
 	clctrl.CloudflareAuth = def.CloudflareAuth
 
-	clctrl.Repositories = []string{"gitops", "metaphor"}
-	clctrl.Teams = []string{"admins", "developers"}
+	clctrl.Repositories = gitProvider.ManagedRepositories()
+	clctrl.Teams = gitProvider.ManagedTeams()
 
 	clctrl.ECR = def.ECR
 
@@ -235,7 +242,7 @@ func (clctrl *ClusterController) InitController(def *types.ClusterDefinition) er
 	if def.GitopsTemplateBranch != "" {
 		clctrl.GitopsTemplateBranch = def.GitopsTemplateBranch
 	} else {
-		clctrl.GitopsTemplateBranch = env.KubefirstVersion
+		clctrl.GitopsTemplateBranch = clctrl.Env.KubefirstVersion
 	}
 
 	if def.GitopsTemplateURL != "" {
@@ -263,14 +270,14 @@ func (clctrl *ClusterController) InitController(def *types.ClusterDefinition) er
 			clctrl.KubefirstStateStoreBucketName = clctrl.KubefirstStateStoreBucketName[:maxLen]
 		}
 	default:
-		clctrl.KubefirstStateStoreBucketName = fmt.Sprintf("k1-state-store-%s-%s", clctrl.ClusterName, clusterID)
+		clctrl.KubefirstStateStoreBucketName = objectStorage.StateStoreBucketPrefix(clctrl.ClusterName) + clusterID
 	}
 
 	clctrl.KubefirstArtifactsBucketName = fmt.Sprintf("k1-artifacts-%s-%s", clctrl.ClusterName, clusterID)
 	clctrl.NodeType = def.NodeType
 	clctrl.NodeCount = def.NodeCount
 
-	clctrl.KubefirstTeam = env.KubefirstTeam
+	clctrl.KubefirstTeam = clctrl.Env.KubefirstTeam
 
 	clctrl.AtlantisWebhookSecret = runtime.Random(20)
 
@@ -283,9 +290,13 @@ func (clctrl *ClusterController) InitController(def *types.ClusterDefinition) er
 	clctrl.AtlantisWebhookURL = fmt.Sprintf("https://atlantis.%s/events", fullDomainName)
 
 	// Initialize git parameters
+	if err := gitHost.Validate(def.GitProvider, def.GitServer); err != nil {
+		return fmt.Errorf("invalid git server configuration: %w", err)
+	}
 	clctrl.GitProvider = def.GitProvider
 	clctrl.GitProtocol = def.GitProtocol
 	clctrl.GitAuth = def.GitAuth
+	clctrl.GitServer = def.GitServer
 
 	err = clctrl.SetGitTokens(*def)
 	if err != nil {
@@ -295,7 +306,7 @@ func (clctrl *ClusterController) InitController(def *types.ClusterDefinition) er
 	// Instantiate provider configuration
 	switch clctrl.CloudProvider {
 	case "akamai":
-		conf, err := providerConfigs.GetConfig(clctrl.ClusterName, clctrl.DomainName, clctrl.GitProvider, clctrl.GitAuth.Owner, clctrl.GitProtocol, clctrl.CloudflareAuth.APIToken, clctrl.CloudflareAuth.OriginCaIssuerKey)
+		conf, err := providerConfigs.GetConfig(clctrl.ClusterName, clctrl.DomainName, clctrl.GitProvider, clctrl.GitHost, clctrl.GitAuth.Owner, clctrl.GitProtocol, clctrl.CloudflareAuth.APIToken, clctrl.CloudflareAuth.OriginCaIssuerKey)
 		if err != nil {
 			return fmt.Errorf("unable to get provider configuration for Akamai: %w", err)
 		}
@@ -303,14 +314,14 @@ func (clctrl *ClusterController) InitController(def *types.ClusterDefinition) er
 		clctrl.ProviderConfig = *conf
 		clctrl.ProviderConfig.AkamaiToken = clctrl.AkamaiAuth.Token
 	case "aws":
-		conf, err := providerConfigs.GetConfig(clctrl.ClusterName, clctrl.DomainName, clctrl.GitProvider, clctrl.GitAuth.Owner, clctrl.GitProtocol, clctrl.CloudflareAuth.Token, "")
+		conf, err := providerConfigs.GetConfig(clctrl.ClusterName, clctrl.DomainName, clctrl.GitProvider, clctrl.GitHost, clctrl.GitAuth.Owner, clctrl.GitProtocol, clctrl.CloudflareAuth.Token, "")
 		if err != nil {
 			return fmt.Errorf("unable to get provider configuration for AWS: %w", err)
 		}
 
 		clctrl.ProviderConfig = *conf
 	case "azure":
-		conf, err := providerConfigs.GetConfig(clctrl.ClusterName, clctrl.DomainName, clctrl.GitProvider, clctrl.GitAuth.Owner, clctrl.GitProtocol, clctrl.CloudflareAuth.APIToken, clctrl.CloudflareAuth.OriginCaIssuerKey)
+		conf, err := providerConfigs.GetConfig(clctrl.ClusterName, clctrl.DomainName, clctrl.GitProvider, clctrl.GitHost, clctrl.GitAuth.Owner, clctrl.GitProtocol, clctrl.CloudflareAuth.APIToken, clctrl.CloudflareAuth.OriginCaIssuerKey)
 		if err != nil {
 			return fmt.Errorf("unable to get provider configuration for Azure: %w", err)
 		}
@@ -318,7 +329,7 @@ func (clctrl *ClusterController) InitController(def *types.ClusterDefinition) er
 		clctrl.ProviderConfig = *conf
 		clctrl.AzureDNSZoneResourceGroup = def.AzureDNSZoneResourceGroup
 	case "civo":
-		conf, err := providerConfigs.GetConfig(clctrl.ClusterName, clctrl.DomainName, clctrl.GitProvider, clctrl.GitAuth.Owner, clctrl.GitProtocol, clctrl.CloudflareAuth.APIToken, clctrl.CloudflareAuth.OriginCaIssuerKey)
+		conf, err := providerConfigs.GetConfig(clctrl.ClusterName, clctrl.DomainName, clctrl.GitProvider, clctrl.GitHost, clctrl.GitAuth.Owner, clctrl.GitProtocol, clctrl.CloudflareAuth.APIToken, clctrl.CloudflareAuth.OriginCaIssuerKey)
 		if err != nil {
 			return fmt.Errorf("unable to get provider configuration for Civo: %w", err)
 		}
@@ -326,7 +337,7 @@ func (clctrl *ClusterController) InitController(def *types.ClusterDefinition) er
 		clctrl.ProviderConfig = *conf
 		clctrl.ProviderConfig.CivoToken = clctrl.CivoAuth.Token
 	case "google":
-		conf, err := providerConfigs.GetConfig(clctrl.ClusterName, clctrl.DomainName, clctrl.GitProvider, clctrl.GitAuth.Owner, clctrl.GitProtocol, clctrl.CloudflareAuth.Token, "")
+		conf, err := providerConfigs.GetConfig(clctrl.ClusterName, clctrl.DomainName, clctrl.GitProvider, clctrl.GitHost, clctrl.GitAuth.Owner, clctrl.GitProtocol, clctrl.CloudflareAuth.Token, "")
 		if err != nil {
 			return fmt.Errorf("unable to get provider configuration for Google: %w", err)
 		}
@@ -335,7 +346,7 @@ func (clctrl *ClusterController) InitController(def *types.ClusterDefinition) er
 		clctrl.ProviderConfig.GoogleAuth = clctrl.GoogleAuth.KeyFile
 		clctrl.ProviderConfig.GoogleProject = clctrl.GoogleAuth.ProjectID
 	case "digitalocean":
-		conf, err := providerConfigs.GetConfig(clctrl.ClusterName, clctrl.DomainName, clctrl.GitProvider, clctrl.GitAuth.Owner, clctrl.GitProtocol, clctrl.CloudflareAuth.Token, "")
+		conf, err := providerConfigs.GetConfig(clctrl.ClusterName, clctrl.DomainName, clctrl.GitProvider, clctrl.GitHost, clctrl.GitAuth.Owner, clctrl.GitProtocol, clctrl.CloudflareAuth.Token, "")
 		if err != nil {
 			return fmt.Errorf("unable to get provider configuration for DigitalOcean: %w", err)
 		}
@@ -343,7 +354,7 @@ func (clctrl *ClusterController) InitController(def *types.ClusterDefinition) er
 		clctrl.ProviderConfig = *conf
 		clctrl.ProviderConfig.DigitaloceanToken = clctrl.DigitaloceanAuth.Token
 	case "vultr":
-		conf, err := providerConfigs.GetConfig(clctrl.ClusterName, clctrl.DomainName, clctrl.GitProvider, clctrl.GitAuth.Owner, clctrl.GitProtocol, clctrl.CloudflareAuth.Token, "")
+		conf, err := providerConfigs.GetConfig(clctrl.ClusterName, clctrl.DomainName, clctrl.GitProvider, clctrl.GitHost, clctrl.GitAuth.Owner, clctrl.GitProtocol, clctrl.CloudflareAuth.Token, "")
 		if err != nil {
 			return fmt.Errorf("unable to get provider configuration for Vultr: %w", err)
 		}
@@ -351,7 +362,7 @@ func (clctrl *ClusterController) InitController(def *types.ClusterDefinition) er
 		clctrl.ProviderConfig = *conf
 		clctrl.ProviderConfig.VultrToken = clctrl.VultrAuth.Token
 	case "k3s":
-		conf, err := providerConfigs.GetConfig(clctrl.ClusterName, clctrl.DomainName, clctrl.GitProvider, clctrl.GitAuth.Owner, clctrl.GitProtocol, clctrl.CloudflareAuth.Token, "")
+		conf, err := providerConfigs.GetConfig(clctrl.ClusterName, clctrl.DomainName, clctrl.GitProvider, clctrl.GitHost, clctrl.GitAuth.Owner, clctrl.GitProtocol, clctrl.CloudflareAuth.Token, "")
 		if err != nil {
 			return fmt.Errorf("unable to get provider configuration for K3s: %w", err)
 		}
@@ -395,13 +406,14 @@ func (clctrl *ClusterController) InitController(def *types.ClusterDefinition) er
 		clctrl.AzureClient = azureClient
 	case "google":
 		clctrl.GoogleClient = google.Configuration{
-			Context: context.Background(),
+			Context: clctrl.traceContext(),
 			Project: def.GoogleAuth.ProjectID,
 			Region:  clctrl.CloudRegion,
 		}
 	}
 
 	// Write cluster record if it doesn't exist
+	useTelemetry := def.UseTelemetry == nil || *def.UseTelemetry
 	clctrl.Cluster = types.Cluster{
 		ID:                     primitive.NewObjectID(),
 		CreationTimestamp:      fmt.Sprintf("%v", primitive.NewDateTimeFromTime(time.Now().UTC())),
@@ -421,6 +433,7 @@ func (clctrl *ClusterController) InitController(def *types.ClusterDefinition) er
 		GitProvider:            clctrl.GitProvider,
 		GitProtocol:            clctrl.GitProtocol,
 		GitHost:                clctrl.GitHost,
+		GitServer:              clctrl.GitServer,
 		GitAuth:                clctrl.GitAuth,
 		GitlabOwnerGroupID:     clctrl.GitlabOwnerGroupID,
 		AtlantisWebhookSecret:  clctrl.AtlantisWebhookSecret,
@@ -441,6 +454,7 @@ func (clctrl *ClusterController) InitController(def *types.ClusterDefinition) er
 		LogFileName:            def.LogFileName,
 		PostInstallCatalogApps: clctrl.PostInstallCatalogApps,
 		InstallKubefirstPro:    clctrl.InstallKubefirstPro,
+		UseTelemetry:           &useTelemetry,
 	}
 
 	if !recordExists {
@@ -459,47 +473,42 @@ func (clctrl *ClusterController) InitController(def *types.ClusterDefinition) er
 // GetCurrentClusterRecord will return an active cluster's record if it exists
 func (clctrl *ClusterController) SetGitTokens(def types.ClusterDefinition) error {
 	switch def.GitProvider {
-	case "github":
-		gitHubService := services.NewGitHubService(clctrl.Client)
-		gitHubHandler := handlers.NewGitHubHandler(gitHubService)
-
-		clctrl.GitHost = "github.com"
-		clctrl.ContainerRegistryHost = "ghcr.io"
-		// Verify token scopes
-		err := github.VerifyTokenPermissions(def.GitAuth.Token)
-		if err != nil {
-			return fmt.Errorf("GitHub token verification failed: %w", err)
-		}
-		// Get authenticated user's name
-		githubUser, err := gitHubHandler.GetGitHubUser(def.GitAuth.Token)
-		if err != nil {
-			return fmt.Errorf("error retrieving GitHub user: %w", err)
-		}
-		clctrl.GitAuth.User = githubUser
-	case "gitlab":
-		clctrl.GitHost = "gitlab.com"
-		clctrl.ContainerRegistryHost = "registry.gitlab.com"
-		// Verify token scopes
-		err := gitlab.VerifyTokenPermissions(def.GitAuth.Token)
-		if err != nil {
-			return fmt.Errorf("GitLab token verification failed: %w", err)
-		}
-		gitlabClient, err := gitlab.NewGitLabClient(def.GitAuth.Token, def.GitAuth.Owner)
-		if err != nil {
-			return fmt.Errorf("error creating GitLab client: %w", err)
-		}
-		clctrl.GitAuth.Owner = gitlabClient.ParentGroupPath
-		clctrl.GitlabOwnerGroupID = gitlabClient.ParentGroupID
-		// Get authenticated user's name
-		user, _, err := gitlabClient.Client.Users.CurrentUser()
-		if err != nil {
-			return fmt.Errorf("unable to get authenticated user info from GitLab: %w", err)
-		}
-		clctrl.GitAuth.User = user.Username
+	case "github", "gitlab", "gitea":
 	default:
 		return fmt.Errorf("invalid git provider option: %q", def.GitProvider)
 	}
 
+	clctrl.GitHost = gitHost.Host(def.GitProvider, def.GitServer)
+	clctrl.ContainerRegistryHost = gitHost.ContainerRegistryHost(def.GitProvider, def.GitServer)
+
+	provider := clctrl.GitClient
+	if provider == nil {
+		var err error
+		provider, err = gitProvider.New(def.GitProvider, def.GitAuth.Token, def.GitAuth.Owner, def.GitServer)
+		if err != nil {
+			return fmt.Errorf("error creating %s client: %w", def.GitProvider, err)
+		}
+		clctrl.GitClient = provider
+	}
+
+	// Verify token scopes
+	err := provider.VerifyTokenPermissions()
+	if err != nil {
+		return fmt.Errorf("%s token verification failed: %w", def.GitProvider, err)
+	}
+
+	// Owners are addressed by the path the provider resolved, GitLab groups
+	// by their full path and id
+	clctrl.GitAuth.Owner = provider.Owner()
+	clctrl.GitlabOwnerGroupID = provider.OwnerID()
+
+	// Get authenticated user's name
+	user, err := provider.GetAuthenticatedUser()
+	if err != nil {
+		return fmt.Errorf("error retrieving %s user: %w", def.GitProvider, err)
+	}
+	clctrl.GitAuth.User = user
+
 	return nil
 }
 
@@ -513,13 +522,25 @@ func (clctrl *ClusterController) GetCurrentClusterRecord() (*types.Cluster, erro
 	return cl, nil
 }
 
+// sendEvent sends a telemetry event and records the provisioning step it marks
+func (clctrl *ClusterController) sendEvent(metricName, message string) {
+	apitelemetry.SendEvent(clctrl.Cluster.TelemetryEnabled(), clctrl.TelemetryEvent, metricName, message)
+	metrics.ObserveStepEvent(clctrl.CloudProvider, clctrl.ClusterName, metricName)
+}
+
 // UpdateClusterOnError implements an error handler for cluster controller objects
-func (clctrl *ClusterController) UpdateClusterOnError(condition string) error {
+func (clctrl *ClusterController) UpdateClusterOnError(err error) error {
+	// the checkpoint of an interrupted operation is kept for it to be resumed
+	if clctrl.Cluster.Status == constants.ClusterStatusInterrupted {
+		return nil
+	}
+
 	clctrl.Cluster.InProgress = false
 	clctrl.Cluster.Status = constants.ClusterStatusError
-	clctrl.Cluster.LastCondition = condition
+	clctrl.Cluster.LastCondition = err.Error()
+	clctrl.Cluster.LastErrorCode = string(apierror.From(err).Code)
 
-	log.Error().Msgf("unexpected error: %s", condition)
+	log.Error().Msgf("unexpected error: %s", err)
 	if err := secrets.UpdateCluster(clctrl.KubernetesClient, clctrl.Cluster); err != nil {
 		return fmt.Errorf("error updating cluster after condition failure: %w", err)
 	}
@@ -7,11 +7,10 @@ See the LICENSE file for more details.
 package controller
 
 import (
-	"context"
-	"errors"
 	"fmt"
 
 	cloudflare_api "github.com/cloudflare/cloudflare-go"
+	"github.com/konstructio/kubefirst-api/internal/apierror"
 	"github.com/konstructio/kubefirst-api/internal/civo"
 	"github.com/konstructio/kubefirst-api/internal/cloudflare"
 	"github.com/konstructio/kubefirst-api/internal/digitalocean"
@@ -30,7 +29,7 @@ func (clctrl *ClusterController) DomainLivenessTest() error {
 	}
 
 	if !cl.DomainLivenessCheck {
-		telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.DomainLivenessStarted, "")
+		clctrl.sendEvent(telemetry.DomainLivenessStarted, "")
 
 		switch clctrl.DNSProvider {
 		case "aws":
@@ -42,7 +41,7 @@ func (clctrl *ClusterController) DomainLivenessTest() error {
 			}
 		case "azure":
 			var domainLiveness bool
-			ctx := context.Background()
+			ctx := clctrl.traceContext()
 
 			if clctrl.AzureDNSZoneResourceGroup == "" {
 				domainLiveness, _, err = clctrl.AzureClient.TestHostedZoneLivenessWildcard(ctx, clctrl.DomainName)
@@ -61,13 +60,13 @@ func (clctrl *ClusterController) DomainLivenessTest() error {
 		case "civo":
 			civoConf := civo.Configuration{
 				Client:  civo.NewCivo(cl.CivoAuth.Token, cl.CloudRegion),
-				Context: context.Background(),
+				Context: clctrl.traceContext(),
 			}
 
 			// domain id
 			domainID, err := civoConf.GetDNSInfo(clctrl.DomainName)
 			if err != nil {
-				telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.DomainLivenessFailed, err.Error())
+				clctrl.sendEvent(telemetry.DomainLivenessFailed, err.Error())
 				log.Info().Msg(err.Error())
 			}
 
@@ -87,7 +86,7 @@ func (clctrl *ClusterController) DomainLivenessTest() error {
 
 			cloudflareConf := cloudflare.Configuration{
 				Client:  client,
-				Context: context.Background(),
+				Context: clctrl.traceContext(),
 			}
 
 			domainLiveness := cloudflareConf.TestDomainLiveness(clctrl.DomainName)
@@ -99,7 +98,7 @@ func (clctrl *ClusterController) DomainLivenessTest() error {
 		case "digitalocean":
 			digitaloceanConf := digitalocean.Configuration{
 				Client:  digitalocean.NewDigitalocean(cl.DigitaloceanAuth.Token),
-				Context: context.Background(),
+				Context: clctrl.traceContext(),
 			}
 
 			// domain id
@@ -118,7 +117,7 @@ func (clctrl *ClusterController) DomainLivenessTest() error {
 		case "vultr":
 			vultrConf := vultr.Configuration{
 				Client:  vultr.NewVultr(cl.VultrAuth.Token),
-				Context: context.Background(),
+				Context: clctrl.traceContext(),
 			}
 
 			// domain id
@@ -127,7 +126,6 @@ func (clctrl *ClusterController) DomainLivenessTest() error {
 				log.Info().Msg(err.Error())
 			}
 
-			// viper values set in above function
 			log.Info().Msgf("domainId: %s", domainID)
 			domainLiveness := vultrConf.TestDomainLiveness(clctrl.DomainName)
 
@@ -143,7 +141,7 @@ func (clctrl *ClusterController) DomainLivenessTest() error {
 			return fmt.Errorf("failed to update cluster after domain liveness test: %w", err)
 		}
 
-		telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.DomainLivenessCompleted, "")
+		clctrl.sendEvent(telemetry.DomainLivenessCompleted, "")
 
 		log.Info().Msgf("domain %s verified", clctrl.DomainName)
 	}
@@ -162,7 +160,7 @@ func (clctrl *ClusterController) HandleDomainLiveness(domainLiveness bool) error
 		if len(foundRecords) != 0 {
 			msg += fmt.Sprintf(" - last result: %s - it may be necessary to wait for propagation", foundRecords)
 		}
-		return errors.New(msg)
+		return apierror.DNSNotDelegated(clctrl.DomainName, msg)
 	}
 
 	return nil
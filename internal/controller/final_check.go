@@ -38,14 +38,14 @@ func (clctrl *ClusterController) FinalCheck() error {
 			3600,
 		)
 		if err != nil {
-			clctrl.UpdateClusterOnError(err.Error())
+			clctrl.UpdateClusterOnError(err)
 			return fmt.Errorf("error finding crossplane Deployment: %w", err)
 		}
 
 		log.Info().Msg("waiting on dns, tls certificates from letsencrypt and remaining sync waves.\n this may take up to 60 minutes but regularly completes in under 20 minutes")
 		_, err = k8s.WaitForDeploymentReady(clctrl.Kcfg.Clientset, crossplaneDeployment, 3600)
 		if err != nil {
-			clctrl.UpdateClusterOnError(err.Error())
+			clctrl.UpdateClusterOnError(err)
 			return fmt.Errorf("error waiting for crossplane deployment to enter Ready state: %w", err)
 		}
 
@@ -65,13 +65,13 @@ func (clctrl *ClusterController) FinalCheck() error {
 		cl, err := secrets.GetCluster(clctrl.KubernetesClient, clctrl.ClusterName)
 		if err != nil {
 			log.Error().Msgf("error getting cluster %s: %s", clctrl.ClusterName, err)
-			clctrl.UpdateClusterOnError(err.Error())
+			clctrl.UpdateClusterOnError(err)
 			return fmt.Errorf("error getting cluster %s: %w", clctrl.ClusterName, err)
 		}
 
 		if err := services.AddDefaultServices(cl); err != nil {
 			log.Error().Msgf("error adding default service entries for cluster %s: %s", cl.ClusterName, err)
-			clctrl.UpdateClusterOnError(err.Error())
+			clctrl.UpdateClusterOnError(err)
 			return fmt.Errorf("error adding default service entries for cluster %s: %w", cl.ClusterName, err)
 		}
 
@@ -85,13 +85,13 @@ func (clctrl *ClusterController) FinalCheck() error {
 				1200,
 			)
 			if err != nil {
-				clctrl.UpdateClusterOnError(err.Error())
+				clctrl.UpdateClusterOnError(err)
 				return fmt.Errorf("error finding kubefirst-pro-api Deployment: %w", err)
 			}
 
 			_, err = k8s.WaitForDeploymentReady(clctrl.Kcfg.Clientset, kubefirstProAPI, 300)
 			if err != nil {
-				clctrl.UpdateClusterOnError(err.Error())
+				clctrl.UpdateClusterOnError(err)
 				return fmt.Errorf("error waiting for kubefirst-pro-api deployment to enter Ready state: %w", err)
 			}
 		}
@@ -106,12 +106,12 @@ func (clctrl *ClusterController) FinalCheck() error {
 			3600,
 		)
 		if err != nil {
-			clctrl.UpdateClusterOnError(err.Error())
+			clctrl.UpdateClusterOnError(err)
 			return fmt.Errorf("error finding argocd Deployment: %w", err)
 		}
 		_, err = k8s.WaitForDeploymentReady(clctrl.Kcfg.Clientset, argocdDeployment, 3600)
 		if err != nil {
-			clctrl.UpdateClusterOnError(err.Error())
+			clctrl.UpdateClusterOnError(err)
 			return fmt.Errorf("error waiting for argocd deployment to enter Ready state: %w", err)
 		}
 
@@ -19,8 +19,7 @@ import (
 	k3sext "github.com/konstructio/kubefirst-api/extensions/k3s"
 	terraformext "github.com/konstructio/kubefirst-api/extensions/terraform"
 	vultrext "github.com/konstructio/kubefirst-api/extensions/vultr"
-	gitShim "github.com/konstructio/kubefirst-api/internal/gitShim"
-	"github.com/konstructio/kubefirst-api/internal/gitlab"
+	"github.com/konstructio/kubefirst-api/internal/gitProvider"
 	"github.com/konstructio/kubefirst-api/internal/secrets"
 	"github.com/kubefirst/metrics-client/pkg/telemetry"
 	log "github.com/rs/zerolog/log"
@@ -34,22 +33,18 @@ func (clctrl *ClusterController) GitInit() error {
 	}
 
 	if !cl.GitInitCheck {
-		// Check for git resources in provider
-		initGitParameters := gitShim.GitInitParameters{
-			GitProvider:  clctrl.GitProvider,
-			GitToken:     clctrl.GitAuth.Token,
-			GitOwner:     clctrl.GitAuth.Owner,
-			GitProtocol:  clctrl.GitProtocol,
-			Repositories: clctrl.Repositories,
-			Teams:        clctrl.Teams,
-			GithubOrg:    clctrl.GitAuth.Owner,
-			GitlabGroup:  clctrl.GitAuth.Owner,
-		}
-		err := gitShim.InitializeGitProvider(&initGitParameters)
+		provider, err := clctrl.gitClient()
 		if err != nil {
 			return fmt.Errorf("failed to initialize git provider: %w", err)
 		}
 
+		// Check for git resources in provider
+		err = gitProvider.CheckResourcesAvailable(provider, clctrl.Repositories, clctrl.Teams)
+		if err != nil {
+			log.Error().Msgf("%s owner %s is not ready for installation: %s", provider.Name(), provider.Owner(), err)
+			return fmt.Errorf("%s owner %s is not ready for installation: %w", provider.Name(), provider.Owner(), err)
+		}
+
 		clctrl.Cluster.GitInitCheck = true
 		err = secrets.UpdateCluster(clctrl.KubernetesClient, clctrl.Cluster)
 		if err != nil {
@@ -67,73 +62,63 @@ func (clctrl *ClusterController) RunGitTerraform() error {
 		return fmt.Errorf("failed to get cluster for terraform execution: %w", err)
 	}
 
+	provider, err := clctrl.gitClient()
+	if err != nil {
+		return err
+	}
+
 	// // * create teams and repositories in github
 
-	telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.GitTerraformApplyStarted, "")
+	clctrl.sendEvent(telemetry.GitTerraformApplyStarted, "")
 
 	log.Info().Msgf("Creating %s resources with terraform", clctrl.GitProvider)
 
-	tfEntrypoint := clctrl.ProviderConfig.GitopsDir + fmt.Sprintf("/terraform/%s", clctrl.GitProvider)
+	// Providers without a git terraform entrypoint in the gitops template
+	// manage their resources through their api instead
+	if provider.TerraformEntrypoint() == "" {
+		return clctrl.createGitResources(cl, provider)
+	}
+
+	tfEntrypoint := clctrl.ProviderConfig.GitopsDir + "/" + provider.TerraformEntrypoint()
 	tfEnvs := map[string]string{}
 
 	if !cl.GitTerraformApplyCheck {
-		switch clctrl.GitProvider {
-		case "github":
-			switch clctrl.CloudProvider {
-			case "akamai":
-				tfEnvs = akamaiext.GetGithubTerraformEnvs(tfEnvs, cl)
-			case "aws":
-				tfEnvs = awsext.GetGithubTerraformEnvs(tfEnvs, cl)
-			case "azure":
-				tfEnvs = azureext.GetGithubTerraformEnvs(tfEnvs, cl)
-			case "civo":
-				tfEnvs = civoext.GetGithubTerraformEnvs(tfEnvs, cl)
-			case "google":
-				tfEnvs = googleext.GetGithubTerraformEnvs(tfEnvs, cl)
-			case "digitalocean":
-				tfEnvs = digitaloceanext.GetGithubTerraformEnvs(tfEnvs, cl)
-			case "vultr":
-				tfEnvs = vultrext.GetGithubTerraformEnvs(tfEnvs, cl)
-			case "k3s":
-				tfEnvs = k3sext.GetGithubTerraformEnvs(tfEnvs, cl)
-			}
-		case "gitlab":
-			switch clctrl.CloudProvider {
-			case "akamai":
-				tfEnvs = akamaiext.GetGitlabTerraformEnvs(tfEnvs, clctrl.GitlabOwnerGroupID, cl)
-			case "aws":
-				tfEnvs = awsext.GetGitlabTerraformEnvs(tfEnvs, clctrl.GitlabOwnerGroupID, cl)
-			case "azure":
-				tfEnvs = azureext.GetGitlabTerraformEnvs(tfEnvs, clctrl.GitlabOwnerGroupID, cl)
-			case "civo":
-				tfEnvs = civoext.GetGitlabTerraformEnvs(tfEnvs, clctrl.GitlabOwnerGroupID, cl)
-			case "google":
-				tfEnvs = googleext.GetGitlabTerraformEnvs(tfEnvs, clctrl.GitlabOwnerGroupID, cl)
-			case "digitalocean":
-				tfEnvs = digitaloceanext.GetGitlabTerraformEnvs(tfEnvs, clctrl.GitlabOwnerGroupID, cl)
-			case "vultr":
-				tfEnvs = vultrext.GetGitlabTerraformEnvs(tfEnvs, clctrl.GitlabOwnerGroupID, cl)
-			case "k3s":
-				tfEnvs = k3sext.GetGitlabTerraformEnvs(tfEnvs, clctrl.GitlabOwnerGroupID, cl)
-			}
+		switch clctrl.CloudProvider {
+		case "akamai":
+			tfEnvs = akamaiext.GetGitTerraformEnvs(tfEnvs, cl)
+		case "aws":
+			tfEnvs = awsext.GetGitTerraformEnvs(tfEnvs, cl)
+		case "azure":
+			tfEnvs = azureext.GetGitTerraformEnvs(tfEnvs, cl)
+		case "civo":
+			tfEnvs = civoext.GetGitTerraformEnvs(tfEnvs, cl)
+		case "google":
+			tfEnvs = googleext.GetGitTerraformEnvs(tfEnvs, cl)
+		case "digitalocean":
+			tfEnvs = digitaloceanext.GetGitTerraformEnvs(tfEnvs, cl)
+		case "vultr":
+			tfEnvs = vultrext.GetGitTerraformEnvs(tfEnvs, cl)
+		case "k3s":
+			tfEnvs = k3sext.GetGitTerraformEnvs(tfEnvs, cl)
 		}
+		tfEnvs = provider.TerraformEnvs(tfEnvs)
 
-		err := terraformext.InitApplyAutoApprove(clctrl.ProviderConfig.TerraformClient, tfEntrypoint, tfEnvs)
+		err := terraformext.InitApplyAutoApprove(clctrl.traceContext(), clctrl.ProviderConfig.TerraformClient, tfEntrypoint, tfEnvs)
 		if err != nil {
 			log.Error().Msgf("error applying git terraform: %s", err)
 			log.Info().Msg("sleeping 10 seconds before retrying terraform execution once more")
 			time.Sleep(10 * time.Second)
-			err = terraformext.InitApplyAutoApprove(clctrl.ProviderConfig.TerraformClient, tfEntrypoint, tfEnvs)
+			err = terraformext.InitApplyAutoApprove(clctrl.traceContext(), clctrl.ProviderConfig.TerraformClient, tfEntrypoint, tfEnvs)
 			if err != nil {
 				msg := fmt.Sprintf("error creating %s resources with terraform %s: %s", clctrl.GitProvider, tfEntrypoint, err)
 				log.Error().Msg(msg)
-				telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.GitTerraformApplyFailed, err.Error())
+				clctrl.sendEvent(telemetry.GitTerraformApplyFailed, err.Error())
 				return fmt.Errorf("failed to apply terraform for git resources: %q", msg)
 			}
 		}
 
-		log.Info().Msgf("created git projects and groups for %s.com/%s", clctrl.GitProvider, clctrl.GitAuth.Owner)
-		telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.GitTerraformApplyCompleted, "")
+		log.Info().Msgf("created git projects and groups for %s/%s", clctrl.GitHost, clctrl.GitAuth.Owner)
+		clctrl.sendEvent(telemetry.GitTerraformApplyCompleted, "")
 
 		clctrl.Cluster.GitTerraformApplyCheck = true
 		err = secrets.UpdateCluster(clctrl.KubernetesClient, clctrl.Cluster)
@@ -145,36 +130,32 @@ func (clctrl *ClusterController) RunGitTerraform() error {
 	return nil
 }
 
+// GetRepoURL returns the gitops repository url for the configured git protocol
+// and sets the https urls of the repositories. The owner is the full path the
+// git provider resolved in SetGitTokens, which for GitLab includes parent groups.
 func (clctrl *ClusterController) GetRepoURL() (string, error) {
-	// default case is https
-	destinationGitopsRepoURL := clctrl.ProviderConfig.DestinationGitopsRepoURL
+	clctrl.ProviderConfig.DestinationGitopsRepoHTTPSURL = fmt.Sprintf("https://%s/%s/gitops.git", clctrl.GitHost, clctrl.GitAuth.Owner)
+	clctrl.ProviderConfig.DestinationMetaphorRepoHTTPSURL = fmt.Sprintf("https://%s/%s/metaphor.git", clctrl.GitHost, clctrl.GitAuth.Owner)
+
+	// Define constant url based on flag input, only expecting 2 protocols
+	if clctrl.ProviderConfig.GitProtocol == "ssh" {
+		return clctrl.ProviderConfig.DestinationGitopsRepoGitURL, nil
+	}
 
-	switch clctrl.GitProvider {
-	case "github":
+	// default case is https
+	return clctrl.ProviderConfig.DestinationGitopsRepoURL, nil
+}
 
-		// Define constant url based on flag input, only expecting 2 protocols
-		if clctrl.ProviderConfig.GitProtocol == "ssh" {
-			destinationGitopsRepoURL = clctrl.ProviderConfig.DestinationGitopsRepoGitURL
-		}
-	case "gitlab":
-		gitlabClient, err := gitlab.NewGitLabClient(clctrl.GitAuth.Token, clctrl.GitAuth.Owner)
+// gitClient returns the client of the cluster git provider, creating it on
+// first use unless one was set on the controller
+func (clctrl *ClusterController) gitClient() (gitProvider.GitProvider, error) {
+	if clctrl.GitClient == nil {
+		provider, err := gitProvider.New(clctrl.GitProvider, clctrl.GitAuth.Token, clctrl.GitAuth.Owner, clctrl.GitServer)
 		if err != nil {
-			return "", fmt.Errorf("failed to create GitLab client: %w", err)
-		}
-		// Format git url based on full path to group
-		switch clctrl.ProviderConfig.GitProtocol {
-		case "https":
-			// Update the urls in the cluster for gitlab parent groups
-			clctrl.ProviderConfig.DestinationGitopsRepoHTTPSURL = fmt.Sprintf("https://gitlab.com/%s/gitops.git", gitlabClient.ParentGroupPath)
-			clctrl.ProviderConfig.DestinationMetaphorRepoHTTPSURL = fmt.Sprintf("https://gitlab.com/%s/metaphor.git", gitlabClient.ParentGroupPath)
-		default:
-			// Update the urls in the cluster for gitlab parent group
-			clctrl.ProviderConfig.DestinationGitopsRepoGitURL = fmt.Sprintf("git@gitlab.com:%s/gitops.git", gitlabClient.ParentGroupPath)
-			clctrl.ProviderConfig.DestinationMetaphorRepoGitURL = fmt.Sprintf("git@gitlab.com:%s/metaphor.git", gitlabClient.ParentGroupPath)
-			// Return the url used for detokenization
-			destinationGitopsRepoURL = clctrl.ProviderConfig.DestinationGitopsRepoGitURL
+			return nil, fmt.Errorf("error creating %s client: %w", clctrl.GitProvider, err)
 		}
+		clctrl.GitClient = provider
 	}
 
-	return destinationGitopsRepoURL, nil
+	return clctrl.GitClient, nil
 }
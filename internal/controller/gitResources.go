@@ -0,0 +1,107 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	terraformext "github.com/konstructio/kubefirst-api/extensions/terraform"
+	"github.com/konstructio/kubefirst-api/internal/gitProvider"
+	"github.com/konstructio/kubefirst-api/internal/secrets"
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
+	"github.com/kubefirst/metrics-client/pkg/telemetry"
+	"github.com/rs/zerolog/log"
+	"k8s.io/client-go/kubernetes"
+)
+
+// createGitResources creates the repositories, teams, deploy key and atlantis
+// webhook through the provider api, standing in for the git terraform apply
+func (clctrl *ClusterController) createGitResources(cl *pkgtypes.Cluster, provider gitProvider.GitProvider) error {
+	if cl.GitTerraformApplyCheck {
+		return nil
+	}
+
+	err := provider.CreateResources(gitProvider.ResourceParameters{
+		Repositories:          clctrl.Repositories,
+		Teams:                 clctrl.Teams,
+		AtlantisWebhookURL:    clctrl.AtlantisWebhookURL,
+		AtlantisWebhookSecret: clctrl.AtlantisWebhookSecret,
+		KbotSSHPublicKey:      cl.GitAuth.PublicKey,
+	})
+	if err != nil {
+		log.Error().Msgf("error creating %s resources: %s", provider.Name(), err)
+		clctrl.sendEvent(telemetry.GitTerraformApplyFailed, err.Error())
+		return fmt.Errorf("failed to create %s resources: %w", provider.Name(), err)
+	}
+
+	log.Info().Msgf("created git repositories and teams for %s/%s", clctrl.GitHost, clctrl.GitAuth.Owner)
+	clctrl.sendEvent(telemetry.GitTerraformApplyCompleted, "")
+
+	clctrl.Cluster.GitTerraformApplyCheck = true
+	err = secrets.UpdateCluster(clctrl.KubernetesClient, clctrl.Cluster)
+	if err != nil {
+		return fmt.Errorf("failed to update cluster after creating %s resources: %w", provider.Name(), err)
+	}
+
+	return nil
+}
+
+// DestroyGitResources removes the repositories and teams of a cluster with the
+// git terraform of the provider, or through its api for providers without one.
+// envs holds the cloud environment the git terraform reads.
+func DestroyGitResources(ctx context.Context, clientset kubernetes.Interface, cl *pkgtypes.Cluster, provider gitProvider.GitProvider, terraformClient, gitopsDir string, envs map[string]string) error {
+	if !cl.GitTerraformApplyCheck {
+		return nil
+	}
+
+	log.Info().Msgf("destroying %s resources", provider.Name())
+
+	// Before removing the repositories, remove any container registry repositories
+	// since failing to remove them beforehand will result in a deletion failure
+	for _, repo := range gitProvider.ManagedRepositories() {
+		if err := provider.DeleteContainerRegistryRepositories(repo); err != nil {
+			log.Error().Msgf("error deleting container registry repositories of project %s: %s", repo, err)
+		}
+	}
+
+	if entrypoint := provider.TerraformEntrypoint(); entrypoint != "" {
+		tfEntrypoint := gitopsDir + "/" + entrypoint
+		err := terraformext.InitDestroyAutoApprove(ctx, terraformClient, tfEntrypoint, provider.TerraformEnvs(envs))
+		if err != nil {
+			return fmt.Errorf("error executing terraform destroy for %s: %w", tfEntrypoint, err)
+		}
+	} else {
+		err := gitProvider.DeleteResources(provider, gitProvider.ManagedRepositories(), gitProvider.ManagedTeams())
+		if err != nil {
+			return fmt.Errorf("error destroying %s resources for cluster %s: %w", provider.Name(), cl.ClusterName, err)
+		}
+	}
+	log.Info().Msgf("%s resources destroyed", provider.Name())
+
+	cl.GitTerraformApplyCheck = false
+	err := secrets.UpdateCluster(clientset, *cl)
+	if err != nil {
+		return fmt.Errorf("error updating cluster after destroying %s resources for cluster %s: %w", provider.Name(), cl.ClusterName, err)
+	}
+
+	return nil
+}
+
+// DeleteKbotSSHKey removes the kbot ssh key from the token user of providers
+// where kubefirst registered it. Failures are logged since the key does not
+// block deleting the cluster.
+func DeleteKbotSSHKey(provider gitProvider.GitProvider) {
+	if !provider.ManagesUserSSHKey() {
+		return
+	}
+
+	log.Info().Msg("attempting to delete managed ssh key...")
+	if err := provider.DeleteSSHKey(gitProvider.KbotSSHKeyTitle); err != nil {
+		log.Warn().Msgf("error deleting %s ssh key %s: %s", provider.Name(), gitProvider.KbotSSHKeyTitle, err)
+	}
+}
@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/konstructio/kubefirst-api/internal/gitProvider"
+	"github.com/konstructio/kubefirst-api/internal/secrets"
+	"github.com/konstructio/kubefirst-api/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDestroyGitResources(t *testing.T) {
+	provider := gitProvider.NewFake("gitea", "kubefirst")
+	for _, repo := range gitProvider.ManagedRepositories() {
+		provider.Repositories[repo] = true
+		provider.Registries[repo] = 1
+	}
+	provider.Teams["admins"] = true
+
+	clientset := fake.NewSimpleClientset()
+	cl := &types.Cluster{ClusterName: "test", GitProvider: "gitea", GitTerraformApplyCheck: true}
+	if err := secrets.InsertCluster(clientset, *cl); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	err := DestroyGitResources(context.Background(), clientset, cl, provider, "terraform", t.TempDir(), map[string]string{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(provider.Repositories) != 0 || len(provider.Teams) != 0 {
+		t.Errorf("expected every resource to be removed, got repositories %v and teams %v", provider.Repositories, provider.Teams)
+	}
+	if len(provider.Registries) != 0 {
+		t.Errorf("expected container registries to be removed first, got %v", provider.Registries)
+	}
+	if cl.GitTerraformApplyCheck {
+		t.Error("expected the git terraform apply check to be cleared")
+	}
+
+	stored, err := secrets.GetCluster(clientset, "test")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if stored.GitTerraformApplyCheck {
+		t.Error("expected the stored cluster to have the git terraform apply check cleared")
+	}
+}
+
+func TestDestroyGitResourcesNotCreated(t *testing.T) {
+	provider := gitProvider.NewFake("github", "kubefirst")
+	provider.Entrypoint = "terraform/github"
+	provider.Repositories["gitops"] = true
+
+	cl := &types.Cluster{ClusterName: "test", GitProvider: "github"}
+	err := DestroyGitResources(context.Background(), fake.NewSimpleClientset(), cl, provider, "terraform", t.TempDir(), map[string]string{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !provider.Repositories["gitops"] {
+		t.Error("expected resources kubefirst never created to be left alone")
+	}
+}
+
+func TestDestroyGitResourcesFailure(t *testing.T) {
+	provider := gitProvider.NewFake("gitea", "kubefirst")
+	provider.Err = errors.New("unauthorized")
+
+	cl := &types.Cluster{ClusterName: "test", GitProvider: "gitea", GitTerraformApplyCheck: true}
+	err := DestroyGitResources(context.Background(), fake.NewSimpleClientset(), cl, provider, "terraform", t.TempDir(), map[string]string{})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if !cl.GitTerraformApplyCheck {
+		t.Error("expected the git terraform apply check to be kept for a retry")
+	}
+}
+
+func TestDeleteKbotSSHKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		userSSHKey bool
+		wantKeys   int
+	}{
+		{name: "user key", userSSHKey: true, wantKeys: 0},
+		{name: "deploy key", userSSHKey: false, wantKeys: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := gitProvider.NewFake("gitlab", "kubefirst")
+			provider.UserSSHKey = tt.userSSHKey
+			if err := provider.AddSSHKey(gitProvider.KbotSSHKeyTitle, "ssh-ed25519 AAAA"); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			DeleteKbotSSHKey(provider)
+
+			if len(provider.SSHKeys) != tt.wantKeys {
+				t.Errorf("expected %d ssh keys, got %d", tt.wantKeys, len(provider.SSHKeys))
+			}
+		})
+	}
+}
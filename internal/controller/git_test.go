@@ -0,0 +1,162 @@
+package controller
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/konstructio/kubefirst-api/internal/gitProvider"
+	"github.com/konstructio/kubefirst-api/internal/secrets"
+	"github.com/konstructio/kubefirst-api/pkg/providerConfigs"
+	"github.com/konstructio/kubefirst-api/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// newGitTestController returns a controller for a cluster record stored in a
+// fake clientset and using provider as its git client
+func newGitTestController(t *testing.T, provider gitProvider.GitProvider) *ClusterController {
+	t.Helper()
+
+	useTelemetry := false
+	cl := types.Cluster{
+		ClusterName:  "test",
+		GitProvider:  provider.Name(),
+		UseTelemetry: &useTelemetry,
+	}
+
+	clientset := fake.NewSimpleClientset()
+	if err := secrets.InsertCluster(clientset, cl); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	return &ClusterController{
+		ClusterName:      cl.ClusterName,
+		GitProvider:      provider.Name(),
+		GitClient:        provider,
+		Repositories:     gitProvider.ManagedRepositories(),
+		Teams:            gitProvider.ManagedTeams(),
+		KubernetesClient: clientset,
+		Cluster:          cl,
+	}
+}
+
+func TestSetGitTokens(t *testing.T) {
+	provider := gitProvider.NewFake("gitlab", "kubefirst/platform")
+	provider.GroupID = 42
+
+	clctrl := &ClusterController{GitClient: provider}
+	err := clctrl.SetGitTokens(types.ClusterDefinition{
+		GitProvider: "gitlab",
+		GitAuth:     types.GitAuth{Owner: "platform"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if clctrl.GitAuth.Owner != "kubefirst/platform" {
+		t.Errorf("expected the owner path resolved by the provider, got %q", clctrl.GitAuth.Owner)
+	}
+	if clctrl.GitlabOwnerGroupID != 42 {
+		t.Errorf("expected group id 42, got %d", clctrl.GitlabOwnerGroupID)
+	}
+	if clctrl.GitAuth.User != "kbot" {
+		t.Errorf("expected user kbot, got %q", clctrl.GitAuth.User)
+	}
+}
+
+func TestSetGitTokensVerificationFailure(t *testing.T) {
+	provider := gitProvider.NewFake("github", "kubefirst")
+	provider.Err = errors.New("missing scopes")
+
+	clctrl := &ClusterController{GitClient: provider}
+	err := clctrl.SetGitTokens(types.ClusterDefinition{GitProvider: "github"})
+	if err == nil {
+		t.Fatal("expected an error for a token failing verification")
+	}
+}
+
+func TestGitInit(t *testing.T) {
+	provider := gitProvider.NewFake("github", "kubefirst")
+	clctrl := newGitTestController(t, provider)
+
+	if err := clctrl.GitInit(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !clctrl.Cluster.GitInitCheck {
+		t.Error("expected the git init check to be set")
+	}
+}
+
+func TestGitInitExistingRepository(t *testing.T) {
+	provider := gitProvider.NewFake("github", "kubefirst")
+	provider.Repositories["gitops"] = true
+	clctrl := newGitTestController(t, provider)
+
+	if err := clctrl.GitInit(); err == nil {
+		t.Fatal("expected an error for an owner with an existing gitops repository")
+	}
+	if clctrl.Cluster.GitInitCheck {
+		t.Error("expected the git init check to be left unset")
+	}
+}
+
+func TestRunGitTerraformWithoutEntrypoint(t *testing.T) {
+	provider := gitProvider.NewFake("gitea", "kubefirst")
+	clctrl := newGitTestController(t, provider)
+	clctrl.AtlantisWebhookURL = "https://atlantis.example.com/events"
+
+	if err := clctrl.RunGitTerraform(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, repo := range gitProvider.ManagedRepositories() {
+		if !provider.Repositories[repo] {
+			t.Errorf("expected repository %s to be created", repo)
+		}
+	}
+	for _, team := range gitProvider.ManagedTeams() {
+		if !provider.Teams[team] {
+			t.Errorf("expected team %s to be created", team)
+		}
+	}
+	if len(provider.Webhooks["gitops"]) != 1 {
+		t.Errorf("expected an atlantis webhook on gitops, got %v", provider.Webhooks["gitops"])
+	}
+	if !clctrl.Cluster.GitTerraformApplyCheck {
+		t.Error("expected the git terraform apply check to be set")
+	}
+}
+
+func TestGetRepoURL(t *testing.T) {
+	tests := []struct {
+		protocol string
+		want     string
+	}{
+		{protocol: "https", want: "https://gitlab.com/kubefirst/platform/gitops.git"},
+		{protocol: "ssh", want: "git@gitlab.com:kubefirst/platform/gitops.git"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.protocol, func(t *testing.T) {
+			clctrl := &ClusterController{
+				GitHost: "gitlab.com",
+				GitAuth: types.GitAuth{Owner: "kubefirst/platform"},
+				ProviderConfig: providerConfigs.ProviderConfig{
+					GitProtocol:                 tt.protocol,
+					DestinationGitopsRepoURL:    "https://gitlab.com/kubefirst/platform/gitops.git",
+					DestinationGitopsRepoGitURL: "git@gitlab.com:kubefirst/platform/gitops.git",
+				},
+			}
+
+			got, err := clctrl.GetRepoURL()
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+			if clctrl.ProviderConfig.DestinationMetaphorRepoHTTPSURL != "https://gitlab.com/kubefirst/platform/metaphor.git" {
+				t.Errorf("unexpected metaphor url %q", clctrl.ProviderConfig.DestinationMetaphorRepoHTTPSURL)
+			}
+		})
+	}
+}
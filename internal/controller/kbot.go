@@ -26,7 +26,7 @@ func (clctrl *ClusterController) InitializeBot() error {
 		clctrl.GitAuth.PrivateKey, clctrl.GitAuth.PublicKey, err = pkg.CreateSSHKeyPair()
 		if err != nil {
 			log.Error().Msgf("error generating ssh keys: %s", err)
-			telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.KbotSetupFailed, err.Error())
+			clctrl.sendEvent(telemetry.KbotSetupFailed, err.Error())
 			return fmt.Errorf("failed to generate SSH key pair: %w", err)
 		}
 
@@ -13,6 +13,7 @@ import (
 	githttps "github.com/go-git/go-git/v5/plumbing/transport/http"
 	pkg "github.com/konstructio/kubefirst-api/internal"
 	"github.com/konstructio/kubefirst-api/internal/gitClient"
+	"github.com/konstructio/kubefirst-api/internal/gitHost"
 	"github.com/konstructio/kubefirst-api/internal/secrets"
 )
 
@@ -55,7 +56,12 @@ func (clctrl *ClusterController) DetokenizeKMSKeyID() error {
 			clctrl.CloudProvider == "digitalocean" && clctrl.GitProvider == "github",
 			clctrl.CloudProvider == "digitalocean" && clctrl.GitProvider == "gitlab",
 			clctrl.CloudProvider == "vultr" && clctrl.GitProvider == "github",
-			clctrl.CloudProvider == "vultr" && clctrl.GitProvider == "gitlab":
+			clctrl.CloudProvider == "vultr" && clctrl.GitProvider == "gitlab",
+			clctrl.CloudProvider == "civo" && clctrl.GitProvider == "gitea",
+			clctrl.CloudProvider == "aws" && clctrl.GitProvider == "gitea",
+			clctrl.CloudProvider == "google" && clctrl.GitProvider == "gitea",
+			clctrl.CloudProvider == "digitalocean" && clctrl.GitProvider == "gitea",
+			clctrl.CloudProvider == "vultr" && clctrl.GitProvider == "gitea":
 			registryPath = fmt.Sprintf("registry/clusters/%s", clctrl.ClusterName)
 		default:
 			registryPath = fmt.Sprintf("registry/%s", clctrl.ClusterName)
@@ -74,12 +80,13 @@ func (clctrl *ClusterController) DetokenizeKMSKeyID() error {
 			return fmt.Errorf("failed to commit detokenized KMS key: %w", err)
 		}
 
-		err = gitopsRepo.Push(&git.PushOptions{
+		err = clctrl.gitPush(gitopsRepo, &git.PushOptions{
 			RemoteName: clctrl.GitProvider,
 			Auth: &githttps.BasicAuth{
 				Username: clctrl.GitAuth.User,
 				Password: clctrl.GitAuth.Token,
 			},
+			CABundle: gitHost.CABundle(clctrl.GitServer),
 		})
 		if err != nil {
 			return fmt.Errorf("failed to push changes to repository: %w", err)
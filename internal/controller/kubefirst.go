@@ -13,8 +13,6 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"os"
-	"strings"
 	"time"
 
 	awsext "github.com/konstructio/kubefirst-api/extensions/aws"
@@ -29,8 +27,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 )
 
-func ReadKubefirstAPITokenFromSecret(clientset kubernetes.Interface) (string, error) {
-	namespace := os.Getenv("KUBE_NAMESPACE")
+func ReadKubefirstAPITokenFromSecret(clientset kubernetes.Interface, namespace string) (string, error) {
 	if namespace == "" {
 		return "", errors.New("error namespace can not be empty")
 	}
@@ -55,7 +52,7 @@ func (clctrl *ClusterController) ExportClusterRecord() error {
 	cluster, err := secrets.GetCluster(clctrl.KubernetesClient, clctrl.ClusterName)
 	if err != nil {
 		log.Error().Msgf("Error exporting cluster record: %s", err)
-		clctrl.UpdateClusterOnError(err.Error())
+		clctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error exporting cluster record: %w", err)
 	}
 
@@ -87,7 +84,7 @@ func (clctrl *ClusterController) ExportClusterRecord() error {
 
 	bytes, err := json.Marshal(cluster)
 	if err != nil {
-		clctrl.UpdateClusterOnError(err.Error())
+		clctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("unable to marshal cluster data: %w", err)
 	}
 
@@ -99,7 +96,7 @@ func (clctrl *ClusterController) ExportClusterRecord() error {
 	}
 
 	if err := k8s.CreateSecretV2(kcfg.Clientset, secret); err != nil {
-		clctrl.UpdateClusterOnError(err.Error())
+		clctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("unable to save secret to management cluster. %w", err)
 	}
 
@@ -119,14 +116,14 @@ func (clctrl *ClusterController) CreateVirtualClusters() error {
 
 	consoleCloudURL := fmt.Sprintf("https://kubefirst.%s", fullDomainName)
 
-	if strings.ToLower(os.Getenv("K1_LOCAL_DEBUG")) == "true" { // allow using local console running on port 3000
+	if clctrl.Env.K1LocalDebug { // allow using local console running on port 3000
 		consoleCloudURL = "http://localhost:3000"
 	}
 
 	err := pkg.IsAppAvailable(fmt.Sprintf("%s/api/proxyHealth", consoleCloudURL), "kubefirst api")
 	if err != nil {
 		log.Error().Msgf("unable to wait for kubefirst console: %s", err)
-		clctrl.UpdateClusterOnError(err.Error())
+		clctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("unable to wait for kubefirst console: %w", err)
 	}
 
@@ -142,7 +139,7 @@ func (clctrl *ClusterController) CreateVirtualClusters() error {
 	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/api/proxy", consoleCloudURL), bytes.NewReader(payload))
 	if err != nil {
 		log.Error().Msgf("unable to create default clusters: %s", err)
-		clctrl.UpdateClusterOnError(err.Error())
+		clctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("unable to create default clusters: %w", err)
 	}
 
@@ -152,7 +149,7 @@ func (clctrl *ClusterController) CreateVirtualClusters() error {
 	res, err := httpCommon.CustomHTTPClient(true).Do(req)
 	if err != nil {
 		log.Error().Msgf("unable to create default clusters: %s", err)
-		clctrl.UpdateClusterOnError(err.Error())
+		clctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("unable to create default clusters: %w", err)
 	}
 	defer res.Body.Close()
@@ -165,7 +162,7 @@ func (clctrl *ClusterController) CreateVirtualClusters() error {
 	if res.StatusCode != http.StatusOK {
 		e := fmt.Errorf("unable to create default clusters, API responded non-200 status: %s: %s", res.Status, string(body))
 		log.Error().Msg(e.Error())
-		clctrl.UpdateClusterOnError(e.Error())
+		clctrl.UpdateClusterOnError(e)
 		return e
 	}
 
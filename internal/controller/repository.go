@@ -9,13 +9,13 @@ package controller
 import (
 	"fmt"
 	"os"
-	"strings"
 
 	"github.com/go-git/go-git/v5"
 	githttps "github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/konstructio/kubefirst-api/internal/civo"
 	"github.com/konstructio/kubefirst-api/internal/digitalocean"
-	"github.com/konstructio/kubefirst-api/internal/gitlab"
+	"github.com/konstructio/kubefirst-api/internal/gitHost"
+	"github.com/konstructio/kubefirst-api/internal/gitProvider"
 	"github.com/konstructio/kubefirst-api/internal/secrets"
 	"github.com/konstructio/kubefirst-api/internal/vultr"
 	google "github.com/konstructio/kubefirst-api/pkg/google"
@@ -252,7 +252,7 @@ func (clctrl *ClusterController) RepositoryPush() error {
 		gitopsDir := clctrl.ProviderConfig.GitopsDir
 		metaphorDir := clctrl.ProviderConfig.MetaphorDir
 
-		telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.GitopsRepoPushStarted, "")
+		clctrl.sendEvent(telemetry.GitopsRepoPushStarted, "")
 		gitopsRepo, err := git.PlainOpen(gitopsDir)
 		if err != nil {
 			return fmt.Errorf("error opening gitops repo at %q: %w", gitopsDir, err)
@@ -263,73 +263,55 @@ func (clctrl *ClusterController) RepositoryPush() error {
 			return fmt.Errorf("error opening metaphor repo at %q: %w", metaphorDir, err)
 		}
 
-		// For GitLab, we currently need to add an ssh key to the authenticating user
-		if clctrl.GitProvider == "gitlab" {
-			gitlabClient, err := gitlab.NewGitLabClient(clctrl.GitAuth.Token, clctrl.GitAuth.Owner)
-			if err != nil {
-				return fmt.Errorf("error creating gitlab client for %q: %w", clctrl.GitAuth.Owner, err)
-			}
+		provider, err := clctrl.gitClient()
+		if err != nil {
+			return err
+		}
 
-			keys, err := gitlabClient.GetUserSSHKeys()
+		// Some providers, like GitLab, need the ssh key added to the authenticating user
+		if provider.ManagesUserSSHKey() {
+			err = gitProvider.EnsureSSHKey(provider, gitProvider.KbotSSHKeyTitle, clctrl.GitAuth.PublicKey, false)
 			if err != nil {
-				log.Error().Msgf("unable to check for ssh keys in gitlab: %s", err.Error())
-			}
-
-			keyName := "kbot-ssh-key"
-			keyFound := false
-			for _, key := range keys {
-				if key.Title == keyName {
-					if strings.Contains(key.Key, strings.TrimSuffix(clctrl.GitAuth.PublicKey, "\n")) {
-						log.Info().Msgf("ssh key %s already exists and key is up to date, continuing", keyName)
-						keyFound = true
-					} else {
-						log.Error().Msgf("ssh key %s already exists and key data has drifted - please remove before continuing", keyName)
-					}
-				}
-			}
-			if !keyFound {
-				log.Info().Msgf("creating ssh key %s...", keyName)
-				err := gitlabClient.AddUserSSHKey(keyName, clctrl.GitAuth.PublicKey)
-				if err != nil {
-					log.Error().Msgf("error adding ssh key %q: %s", keyName, err.Error())
-				}
+				log.Error().Msgf("unable to set up ssh key %q: %s", gitProvider.KbotSSHKeyTitle, err.Error())
 			}
 		}
 
 		// push metaphor repo to remote
-		err = gitopsRepo.Push(
+		err = clctrl.gitPush(gitopsRepo,
 			&git.PushOptions{
 				RemoteName: clctrl.GitProvider,
 				Auth: &githttps.BasicAuth{
 					Username: clctrl.GitAuth.User,
 					Password: clctrl.GitAuth.Token,
 				},
+				CABundle: gitHost.CABundle(clctrl.GitServer),
 			},
 		)
 		if err != nil {
-			telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.GitopsRepoPushFailed, err.Error())
+			clctrl.sendEvent(telemetry.GitopsRepoPushFailed, err.Error())
 			return fmt.Errorf("error pushing detokenized gitops repository to remote %s: %w", clctrl.ProviderConfig.DestinationGitopsRepoURL, err)
 		}
 
 		// push metaphor repo to remote
-		err = metaphorRepo.Push(
+		err = clctrl.gitPush(metaphorRepo,
 			&git.PushOptions{
 				RemoteName: "origin",
 				Auth: &githttps.BasicAuth{
 					Username: clctrl.GitAuth.User,
 					Password: clctrl.GitAuth.Token,
 				},
+				CABundle: gitHost.CABundle(clctrl.GitServer),
 			},
 		)
 		if err != nil {
-			telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.GitopsRepoPushFailed, err.Error())
+			clctrl.sendEvent(telemetry.GitopsRepoPushFailed, err.Error())
 			return fmt.Errorf("error pushing detokenized metaphor repository to remote %s: %w", clctrl.ProviderConfig.DestinationMetaphorRepoURL, err)
 		}
 
 		log.Info().Msgf("successfully pushed gitops and metaphor repositories to git@%s/%s", clctrl.GitHost, clctrl.GitAuth.Owner)
 		// todo delete the local gitops repo and re-clone it
 		// todo that way we can stop worrying about which origin we're going to push to
-		telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.GitopsRepoPushCompleted, "")
+		clctrl.sendEvent(telemetry.GitopsRepoPushCompleted, "")
 
 		clctrl.Cluster.GitopsPushedCheck = true
 		err = secrets.UpdateCluster(clctrl.KubernetesClient, clctrl.Cluster)
@@ -7,13 +7,14 @@ See the LICENSE file for more details.
 package controller
 
 import (
-	"context"
 	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/konstructio/kubefirst-api/internal/civo"
 	"github.com/konstructio/kubefirst-api/internal/digitalocean"
+	"github.com/konstructio/kubefirst-api/internal/httpCommon"
+	"github.com/konstructio/kubefirst-api/internal/objectStorage"
 	"github.com/konstructio/kubefirst-api/internal/secrets"
 	"github.com/konstructio/kubefirst-api/internal/vultr"
 	"github.com/konstructio/kubefirst-api/pkg/akamai"
@@ -33,7 +34,7 @@ func (clctrl *ClusterController) StateStoreCredentials() error {
 
 	var stateStoreData pkgtypes.StateStoreCredentials
 
-	telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.StateStoreCredentialsCreateStarted, "")
+	clctrl.sendEvent(telemetry.StateStoreCredentialsCreateStarted, "")
 
 	if !cl.StateStoreCredsCheck {
 		switch clctrl.CloudProvider {
@@ -65,19 +66,19 @@ func (clctrl *ClusterController) StateStoreCredentials() error {
 			}
 			err = secrets.UpdateCluster(clctrl.KubernetesClient, clctrl.Cluster)
 			if err != nil {
-				telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.StateStoreCredentialsCreateFailed, err.Error())
+				clctrl.sendEvent(telemetry.StateStoreCredentialsCreateFailed, err.Error())
 				return fmt.Errorf("failed to update cluster after creating AWS state store: %w", err)
 			}
 		case "azure":
 			// Azure storage is non-S3 compliant
 			location := clctrl.CloudRegion
 			resourceGroup := fmt.Sprintf("%s-state", clctrl.ClusterName)
-			containerName := azureTerraformContainer
+			containerName := objectStorage.AzureTerraformContainer
 
-			ctx := context.Background()
+			ctx := clctrl.traceContext()
 
 			if _, err := clctrl.AzureClient.CreateResourceGroup(ctx, resourceGroup, location); err != nil {
-				telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.StateStoreCreateFailed, err.Error())
+				clctrl.sendEvent(telemetry.StateStoreCreateFailed, err.Error())
 				return fmt.Errorf("error creating azure storage resource group %s: %w", resourceGroup, err)
 			}
 
@@ -87,13 +88,13 @@ func (clctrl *ClusterController) StateStoreCredentials() error {
 				resourceGroup,
 				clctrl.KubefirstStateStoreBucketName,
 			); err != nil {
-				telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.StateStoreCreateFailed, err.Error())
+				clctrl.sendEvent(telemetry.StateStoreCreateFailed, err.Error())
 				return fmt.Errorf("error creating azure storage account %s: %w", clctrl.KubefirstStateStoreBucketName, err)
 			}
 
 			keys, err := clctrl.AzureClient.GetStorageAccessKeys(ctx, resourceGroup, clctrl.KubefirstStateStoreBucketName)
 			if err != nil {
-				telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.StateStoreCreateFailed, err.Error())
+				clctrl.sendEvent(telemetry.StateStoreCreateFailed, err.Error())
 				return fmt.Errorf("error retrieving azure storage account keys %s: %w", clctrl.KubefirstStateStoreBucketName, err)
 			}
 
@@ -104,18 +105,18 @@ func (clctrl *ClusterController) StateStoreCredentials() error {
 			}
 
 			if _, err := clctrl.AzureClient.CreateBlobContainer(ctx, clctrl.KubefirstStateStoreBucketName, containerName); err != nil {
-				telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.StateStoreCreateFailed, err.Error())
+				clctrl.sendEvent(telemetry.StateStoreCreateFailed, err.Error())
 				return fmt.Errorf("error creating blob storage container %s: %w", clctrl.KubefirstStateStoreBucketName, err)
 			}
 		case "civo":
 			civoConf := civo.Configuration{
 				Client:  civo.NewCivo(cl.CivoAuth.Token, cl.CloudRegion),
-				Context: context.Background(),
+				Context: clctrl.traceContext(),
 			}
 
 			creds, err := civoConf.GetAccessCredentials(clctrl.KubefirstStateStoreBucketName, clctrl.CloudRegion)
 			if err != nil {
-				telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.StateStoreCredentialsCreateFailed, err.Error())
+				clctrl.sendEvent(telemetry.StateStoreCredentialsCreateFailed, err.Error())
 				log.Error().Msg(err.Error())
 				return fmt.Errorf("failed to get access credentials from Civo: %w", err)
 			}
@@ -129,7 +130,7 @@ func (clctrl *ClusterController) StateStoreCredentials() error {
 		case "digitalocean":
 			digitaloceanConf := digitalocean.Configuration{
 				Client:  digitalocean.NewDigitalocean(cl.DigitaloceanAuth.Token),
-				Context: context.Background(),
+				Context: clctrl.traceContext(),
 			}
 
 			creds := digitalocean.SpacesCredentials{
@@ -141,7 +142,7 @@ func (clctrl *ClusterController) StateStoreCredentials() error {
 			if err != nil {
 				msg := fmt.Sprintf("error creating spaces bucket %s: %s", clctrl.KubefirstStateStoreBucketName, err)
 				log.Error().Msg(msg)
-				telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.StateStoreCredentialsCreateFailed, err.Error())
+				clctrl.sendEvent(telemetry.StateStoreCredentialsCreateFailed, err.Error())
 				return fmt.Errorf("failed to create DigitalOcean spaces bucket: %w", err)
 			}
 
@@ -167,14 +168,14 @@ func (clctrl *ClusterController) StateStoreCredentials() error {
 			_, err := clctrl.GoogleClient.CreateBucket(clctrl.KubefirstStateStoreBucketName, []byte(clctrl.GoogleAuth.KeyFile))
 			if err != nil {
 				msg := fmt.Sprintf("error creating google bucket %s: %s", clctrl.KubefirstStateStoreBucketName, err)
-				telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.StateStoreCreateFailed, msg)
+				clctrl.sendEvent(telemetry.StateStoreCreateFailed, msg)
 				return fmt.Errorf("failed to create Google Cloud Storage bucket: %w", err)
 			}
 
 		case "vultr":
 			vultrConf := vultr.Configuration{
 				Client:  vultr.NewVultr(cl.VultrAuth.Token),
-				Context: context.Background(),
+				Context: clctrl.traceContext(),
 				Region:  cl.CloudRegion,
 				// https://www.vultr.com/docs/vultr-object-storage/
 				ObjectStorageRegion: "ewr",
@@ -182,7 +183,7 @@ func (clctrl *ClusterController) StateStoreCredentials() error {
 
 			objst, err := vultrConf.CreateObjectStorage(clctrl.KubefirstStateStoreBucketName)
 			if err != nil {
-				telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.StateStoreCreateFailed, err.Error())
+				clctrl.sendEvent(telemetry.StateStoreCreateFailed, err.Error())
 				log.Error().Msg(err.Error())
 				return fmt.Errorf("failed to create Vultr object storage: %w", err)
 			}
@@ -192,7 +193,7 @@ func (clctrl *ClusterController) StateStoreCredentials() error {
 				Endpoint:        objst.S3Hostname,
 			}, clctrl.KubefirstStateStoreBucketName)
 			if err != nil {
-				telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.StateStoreCredentialsCreateFailed, err.Error())
+				clctrl.sendEvent(telemetry.StateStoreCredentialsCreateFailed, err.Error())
 				return fmt.Errorf("failed to create Vultr state storage bucket: %w", err)
 			}
 
@@ -222,7 +223,7 @@ func (clctrl *ClusterController) StateStoreCredentials() error {
 			return fmt.Errorf("failed to update cluster state store credentials: %w", err)
 		}
 
-		telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.CloudCredentialsCheckCompleted, "")
+		clctrl.sendEvent(telemetry.CloudCredentialsCheckCompleted, "")
 		log.Info().Msgf("%s object storage credentials created and set", clctrl.CloudProvider)
 	}
 
@@ -245,6 +246,7 @@ func (clctrl *ClusterController) StateStoreCreate() error {
 			oauth2Client := &http.Client{
 				Transport: &oauth2.Transport{
 					Source: tokenSource,
+					Base:   httpCommon.TracedTransport(),
 				},
 			}
 
@@ -252,14 +254,14 @@ func (clctrl *ClusterController) StateStoreCreate() error {
 
 			akamaiConf := akamai.Configuration{
 				Client:  linodego.NewClient(oauth2Client),
-				Context: context.Background(),
+				Context: clctrl.traceContext(),
 			}
 
-			telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.StateStoreCreateStarted, "")
+			clctrl.sendEvent(telemetry.StateStoreCreateStarted, "")
 
 			bucketAndCreds, err := akamaiConf.CreateObjectStorageBucketAndKeys(cl.ClusterName)
 			if err != nil {
-				telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.StateStoreCreateFailed, err.Error())
+				clctrl.sendEvent(telemetry.StateStoreCreateFailed, err.Error())
 				log.Error().Msg(err.Error())
 				return fmt.Errorf("failed to create Akamai object storage bucket and keys: %w", err)
 			}
@@ -277,23 +279,23 @@ func (clctrl *ClusterController) StateStoreCreate() error {
 				return fmt.Errorf("failed to update cluster after creating Akamai state store: %w", err)
 			}
 
-			telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.StateStoreCreateCompleted, "")
+			clctrl.sendEvent(telemetry.StateStoreCreateCompleted, "")
 			log.Info().Msgf("%s state store bucket created", clctrl.CloudProvider)
 		case "civo":
 
 			civoConf := civo.Configuration{
 				Client:  civo.NewCivo(cl.CivoAuth.Token, cl.CloudRegion),
-				Context: context.Background(),
+				Context: clctrl.traceContext(),
 			}
 
-			telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.StateStoreCreateStarted, "")
+			clctrl.sendEvent(telemetry.StateStoreCreateStarted, "")
 
 			accessKeyID := cl.StateStoreCredentials.AccessKeyID
 			log.Info().Msgf("access key id %s", accessKeyID)
 
 			bucket, err := civoConf.CreateStorageBucket(accessKeyID, clctrl.KubefirstStateStoreBucketName, clctrl.CloudRegion)
 			if err != nil {
-				telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.StateStoreCreateFailed, err.Error())
+				clctrl.sendEvent(telemetry.StateStoreCreateFailed, err.Error())
 				log.Error().Msg(err.Error())
 				return fmt.Errorf("failed to create Civo storage bucket: %w", err)
 			}
@@ -312,7 +314,7 @@ func (clctrl *ClusterController) StateStoreCreate() error {
 				return fmt.Errorf("failed to update cluster after creating Civo state store: %w", err)
 			}
 
-			telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.StateStoreCreateCompleted, "")
+			clctrl.sendEvent(telemetry.StateStoreCreateCompleted, "")
 			log.Info().Msgf("%s state store bucket created", clctrl.CloudProvider)
 		}
 	}
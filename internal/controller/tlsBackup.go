@@ -0,0 +1,63 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package controller
+
+import (
+	"os"
+
+	"github.com/konstructio/kubefirst-api/internal/ssl"
+	log "github.com/rs/zerolog/log"
+)
+
+// RestoreTLSBackup downloads the latest tls backup of a recreated cluster from
+// the state store and restores its tls secrets before argocd issues new ones
+func (clctrl *ClusterController) RestoreTLSBackup() {
+	cl, err := clctrl.GetCurrentClusterRecord()
+	if err != nil {
+		log.Warn().Msgf("unable to check for a tls backup: %s", err)
+		return
+	}
+
+	found, err := ssl.FetchLatestBackup(cl, clctrl.ProviderConfig.SSLBackupDir)
+	if err != nil {
+		log.Warn().Msgf("unable to fetch tls backup from the state store: %s", err)
+	}
+	if !found {
+		log.Info().Msg("no tls backup found in the state store, continuing")
+	}
+
+	// * check for ssl restore
+	log.Info().Msg("checking for tls secrets to restore")
+	secretsFilesToRestore, err := os.ReadDir(clctrl.ProviderConfig.SSLBackupDir + "/secrets")
+	if err != nil {
+		if os.IsNotExist(err) {
+			log.Info().Msg("no files found in secrets directory, continuing")
+		} else {
+			log.Info().Msgf("unable to check for TLS secrets to restore: %s", err.Error())
+		}
+	}
+
+	if len(secretsFilesToRestore) == 0 {
+		log.Info().Msg("no files found in secrets directory, continuing")
+		return
+	}
+
+	// todo would like these but requires CRD's and is not currently supported
+	// add crds ( use execShellReturnErrors? )
+	// https://raw.githubusercontent.com/cert-manager/cert-manager/v1.11.0/deploy/crds/crd-clusterissuers.yaml
+	// https://raw.githubusercontent.com/cert-manager/cert-manager/v1.11.0/deploy/crds/crd-certificates.yaml
+	// add certificates, and clusterissuers
+	log.Info().Msgf("found %d tls secrets to restore", len(secretsFilesToRestore))
+	kcfg, err := clctrl.clusterKubernetesClient()
+	if err != nil {
+		log.Warn().Msgf("unable to restore tls secrets: %s", err)
+		return
+	}
+	if err := ssl.Restore(clctrl.ProviderConfig.SSLBackupDir, kcfg.Clientset); err != nil {
+		log.Warn().Msgf("unable to restore tls secrets: %s", err)
+	}
+}
@@ -9,6 +9,7 @@ package controller
 import (
 	"fmt"
 
+	"github.com/konstructio/kubefirst-api/internal/downloadManager"
 	"github.com/konstructio/kubefirst-api/internal/secrets"
 	"github.com/konstructio/kubefirst-api/internal/utils"
 	awsinternal "github.com/konstructio/kubefirst-api/pkg/aws"
@@ -28,9 +29,15 @@ func (clctrl *ClusterController) DownloadTools(toolsDir string) error {
 	if !cl.InstallToolsCheck {
 		log.Info().Msg("installing kubefirst dependencies")
 
+		cache, err := downloadManager.NewToolCache(clctrl.Env.ToolsCacheDir, clctrl.Env.ToolsPreseedDir, clctrl.Env.ToolsTerraformPublicKey)
+		if err != nil {
+			return fmt.Errorf("error creating tools cache: %w", err)
+		}
+
 		switch cl.CloudProvider {
 		case "akamai":
 			err := utils.DownloadTools(
+				cache,
 				clctrl.ProviderConfig.KubectlClient,
 				providerConfigs.KubectlClientVersion,
 				providerConfigs.LocalhostOS,
@@ -44,6 +51,7 @@ func (clctrl *ClusterController) DownloadTools(toolsDir string) error {
 			}
 		case "aws":
 			err := awsinternal.DownloadTools(
+				cache,
 				&clctrl.ProviderConfig,
 				providerConfigs.KubectlClientVersion,
 				providerConfigs.TerraformClientVersion,
@@ -54,6 +62,7 @@ func (clctrl *ClusterController) DownloadTools(toolsDir string) error {
 			}
 		case "azure":
 			err := utils.DownloadTools(
+				cache,
 				clctrl.ProviderConfig.KubectlClient,
 				providerConfigs.KubectlClientVersion,
 				providerConfigs.LocalhostOS,
@@ -67,6 +76,7 @@ func (clctrl *ClusterController) DownloadTools(toolsDir string) error {
 			}
 		case "civo":
 			err := utils.DownloadTools(
+				cache,
 				clctrl.ProviderConfig.KubectlClient,
 				providerConfigs.KubectlClientVersion,
 				providerConfigs.LocalhostOS,
@@ -80,6 +90,7 @@ func (clctrl *ClusterController) DownloadTools(toolsDir string) error {
 			}
 		case "google":
 			err := utils.DownloadTools(
+				cache,
 				clctrl.ProviderConfig.KubectlClient,
 				providerConfigs.KubectlClientVersion,
 				providerConfigs.LocalhostOS,
@@ -93,6 +104,7 @@ func (clctrl *ClusterController) DownloadTools(toolsDir string) error {
 			}
 		case "digitalocean":
 			err := utils.DownloadTools(
+				cache,
 				clctrl.ProviderConfig.KubectlClient,
 				providerConfigs.KubectlClientVersion,
 				providerConfigs.LocalhostOS,
@@ -106,6 +118,7 @@ func (clctrl *ClusterController) DownloadTools(toolsDir string) error {
 			}
 		case "vultr":
 			err := utils.DownloadTools(
+				cache,
 				clctrl.ProviderConfig.KubectlClient,
 				providerConfigs.KubectlClientVersion,
 				providerConfigs.LocalhostOS,
@@ -122,6 +135,7 @@ func (clctrl *ClusterController) DownloadTools(toolsDir string) error {
 			// use vultr DownloadTools meanwhile
 		case "k3s":
 			err := utils.DownloadTools(
+				cache,
 				clctrl.ProviderConfig.KubectlClient,
 				providerConfigs.KubectlClientVersion,
 				providerConfigs.LocalhostOS,
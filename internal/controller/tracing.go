@@ -0,0 +1,76 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/konstructio/kubefirst-api/internal/apierror"
+	"github.com/konstructio/kubefirst-api/internal/constants"
+	"github.com/konstructio/kubefirst-api/internal/metrics"
+	"github.com/konstructio/kubefirst-api/internal/secrets"
+	"github.com/konstructio/kubefirst-api/internal/shutdown"
+	"github.com/konstructio/kubefirst-api/internal/tracing"
+	log "github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// traceContext returns the context of the running step, steps and cloud calls
+// started from it are traced as its children
+func (clctrl *ClusterController) traceContext() context.Context {
+	if clctrl.Context == nil {
+		return context.Background()
+	}
+	return clctrl.Context
+}
+
+// RunStep runs a provisioning step in a span named after it. Once the API is
+// shutting down the step is not started and the cluster record is checkpointed
+// as interrupted, retrying the create resumes from this step.
+func (clctrl *ClusterController) RunStep(name string, step func() error) error {
+	if shutdown.Draining() {
+		return clctrl.interrupt(name)
+	}
+
+	parent := clctrl.traceContext()
+	ctx, span := tracing.Start(parent, name,
+		attribute.String("cluster.name", clctrl.ClusterName),
+		attribute.String("cloud.provider", clctrl.CloudProvider),
+	)
+
+	clctrl.Context = ctx
+	err := step()
+	clctrl.Context = parent
+
+	tracing.Finish(span, err)
+	return err
+}
+
+// gitPush pushes a repository in a span of the running step
+func (clctrl *ClusterController) gitPush(repo *git.Repository, opts *git.PushOptions) error {
+	return tracing.Run(clctrl.traceContext(), "git.push", func(ctx context.Context) error {
+		return repo.PushContext(ctx, opts)
+	}, attribute.String("git.remote", opts.RemoteName))
+}
+
+// interrupt marks the cluster record interrupted before step
+func (clctrl *ClusterController) interrupt(step string) error {
+	clctrl.Cluster.InProgress = false
+	clctrl.Cluster.Status = constants.ClusterStatusInterrupted
+	clctrl.Cluster.LastCondition = fmt.Sprintf("%s before step %s, retry the operation to resume", shutdown.ErrInterrupted, step)
+	clctrl.Cluster.LastErrorCode = string(apierror.CodeInterrupted)
+	clctrl.Cluster.InterruptedOperation = metrics.OperationCreate
+
+	log.Warn().Msgf("cluster %s: %s", clctrl.ClusterName, clctrl.Cluster.LastCondition)
+	if err := secrets.UpdateCluster(clctrl.KubernetesClient, clctrl.Cluster); err != nil {
+		return fmt.Errorf("error checkpointing interrupted cluster: %w", err)
+	}
+
+	return fmt.Errorf("step %s: %w", step, shutdown.ErrInterrupted)
+}
@@ -54,7 +54,7 @@ func (clctrl *ClusterController) RunUsersTerraform() error {
 			}
 		}
 
-		telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.UsersTerraformApplyStarted, "")
+		clctrl.sendEvent(telemetry.UsersTerraformApplyStarted, "")
 		log.Info().Msg("applying users terraform")
 
 		tfEnvs := map[string]string{}
@@ -88,29 +88,24 @@ func (clctrl *ClusterController) RunUsersTerraform() error {
 		}
 		tfEntrypoint = clctrl.ProviderConfig.GitopsDir + "/terraform/users"
 		terraformClient = clctrl.ProviderConfig.TerraformClient
-		err = terraformext.InitApplyAutoApprove(terraformClient, tfEntrypoint, tfEnvs)
+		err = terraformext.InitApplyAutoApprove(clctrl.traceContext(), terraformClient, tfEntrypoint, tfEnvs)
 		if err != nil {
 			log.Error().Msgf("error applying users terraform: %s", err)
 			log.Info().Msg("sleeping 10 seconds before retrying terraform execution once more")
 			time.Sleep(10 * time.Second)
-			err = terraformext.InitApplyAutoApprove(terraformClient, tfEntrypoint, tfEnvs)
+			err = terraformext.InitApplyAutoApprove(clctrl.traceContext(), terraformClient, tfEntrypoint, tfEnvs)
 			if err != nil {
 				log.Error().Msgf("error applying users terraform: %s", err)
-				telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.UsersTerraformApplyFailed, err.Error())
+				clctrl.sendEvent(telemetry.UsersTerraformApplyFailed, err.Error())
 				return fmt.Errorf("failed to apply users terraform on retry: %w", err)
 			}
 		}
 		log.Info().Msg("executed users terraform successfully")
-		telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.UsersTerraformApplyCompleted, "")
+		clctrl.sendEvent(telemetry.UsersTerraformApplyCompleted, "")
 
+		// the root token is only held in memory, it is revoked after bootstrap
 		clctrl.VaultAuth.RootToken = tfEnvs["VAULT_TOKEN"]
 
-		clctrl.Cluster.VaultAuth.RootToken = clctrl.VaultAuth.RootToken
-		err = secrets.UpdateCluster(clctrl.KubernetesClient, clctrl.Cluster)
-		if err != nil {
-			return fmt.Errorf("failed to update cluster after applying terraform: %w", err)
-		}
-
 		// Set kbot password in object
 		err = clctrl.GetUserPassword("kbot")
 		if err != nil {
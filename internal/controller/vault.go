@@ -35,17 +35,15 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-// InitializeVault
+// GetUserPassword retrieves the password of a vault user with the root token
+// held by the controller during bootstrap
 func (clctrl *ClusterController) GetUserPassword(user string) error {
-	cl, err := secrets.GetCluster(clctrl.KubernetesClient, clctrl.ClusterName)
-	if err != nil {
-		return fmt.Errorf("failed to get cluster for user password retrieval: %w", err)
-	}
+	var err error
 
 	// empty conf
 	vaultConf := &vault.Conf
 	// sets up vault client within function
-	clctrl.VaultAuth.KbotPassword, err = vaultConf.GetUserPassword(vault.VaultDefaultAddress, cl.VaultAuth.RootToken, user, "initial-password")
+	clctrl.VaultAuth.KbotPassword, err = vaultConf.GetUserPassword(vault.VaultDefaultAddress, clctrl.VaultAuth.RootToken, user, "initial-password")
 	if err != nil {
 		return fmt.Errorf("failed to get user password from vault: %w", err)
 	}
@@ -90,10 +88,11 @@ func (clctrl *ClusterController) InitializeVault() error {
 			}
 		}
 
-		telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.VaultInitializationStarted, "")
+		clctrl.sendEvent(telemetry.VaultInitializationStarted, "")
 
-		switch clctrl.CloudProvider {
-		case "aws", "google":
+		switch {
+		case vault.UsesAutoUnseal(cl):
+			// the keys returned with a kms seal are recovery keys, they cannot unseal vault
 			vaultClient := &vault.Conf
 
 			initResponse, err := vaultClient.AutoUnseal()
@@ -120,7 +119,7 @@ func (clctrl *ClusterController) InitializeVault() error {
 			if err != nil {
 				return fmt.Errorf("failed to create vault secret in Kubernetes: %w", err)
 			}
-		case "akamai", "azure", "civo", "digitalocean", "k3s", "vultr":
+		default:
 			// Initialize and unseal Vault
 			// Build and apply manifests
 			yamlData, err := kcfg.KustomizeBuild(vaultHandlerPath)
@@ -144,11 +143,11 @@ func (clctrl *ClusterController) InitializeVault() error {
 			_, err = k8s.WaitForJobComplete(kcfg.Clientset, job.Name, job.Namespace, 240)
 			if err != nil {
 				msg := fmt.Sprintf("could not run vault unseal job: %s", err)
-				telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.VaultInitializationFailed, err.Error())
+				clctrl.sendEvent(telemetry.VaultInitializationFailed, err.Error())
 				log.Error().Msg(msg)
 			}
 		}
-		telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.VaultInitializationCompleted, "")
+		clctrl.sendEvent(telemetry.VaultInitializationCompleted, "")
 
 		clctrl.Cluster.VaultInitializedCheck = true
 		err = secrets.UpdateCluster(clctrl.KubernetesClient, clctrl.Cluster)
@@ -189,7 +188,7 @@ func (clctrl *ClusterController) RunVaultTerraform() error {
 			}
 		}
 
-		telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.VaultTerraformApplyStarted, "")
+		clctrl.sendEvent(telemetry.VaultTerraformApplyStarted, "")
 
 		tfEnvs := map[string]string{}
 
@@ -248,21 +247,21 @@ func (clctrl *ClusterController) RunVaultTerraform() error {
 		tfClient := clctrl.ProviderConfig.TerraformClient
 
 		log.Info().Msg("configuring vault with terraform")
-		err = terraformext.InitApplyAutoApprove(tfClient, tfEntrypoint, tfEnvs)
+		err = terraformext.InitApplyAutoApprove(clctrl.traceContext(), tfClient, tfEntrypoint, tfEnvs)
 		if err != nil {
 			log.Error().Msgf("error applying vault terraform: %s", err)
 			log.Info().Msg("sleeping 10 seconds before retrying terraform execution once more")
 			time.Sleep(10 * time.Second)
-			err = terraformext.InitApplyAutoApprove(tfClient, tfEntrypoint, tfEnvs)
+			err = terraformext.InitApplyAutoApprove(clctrl.traceContext(), tfClient, tfEntrypoint, tfEnvs)
 			if err != nil {
 				log.Error().Msgf("error applying vault terraform on retry: %s", err)
-				telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.VaultTerraformApplyFailed, err.Error())
+				clctrl.sendEvent(telemetry.VaultTerraformApplyFailed, err.Error())
 				return fmt.Errorf("failed to apply vault terraform configuration after retry: %w", err)
 			}
 		}
 
 		log.Info().Msg("vault terraform executed successfully")
-		telemetry.SendEvent(clctrl.TelemetryEvent, telemetry.VaultTerraformApplyCompleted, "")
+		clctrl.sendEvent(telemetry.VaultTerraformApplyCompleted, "")
 
 		clctrl.Cluster.VaultTerraformApplyCheck = true
 		err = secrets.UpdateCluster(clctrl.KubernetesClient, clctrl.Cluster)
@@ -336,7 +335,17 @@ func (clctrl *ClusterController) WriteVaultSecrets() error {
 	if len(vaultUnsealSecretData) != 0 {
 		vaultRootToken = vaultUnsealSecretData["root-token"]
 	}
-	vaultClient.SetToken(vaultRootToken)
+
+	// platform secrets are written with the scoped token kubefirst-api keeps
+	// using once the root token is revoked
+	if err := vault.Conf.ConfigureAPIAuth(vaultAddr, vaultRootToken, clientset); err != nil {
+		return fmt.Errorf("failed to configure vault auth for kubefirst-api: %w", err)
+	}
+	vaultToken, err := vault.Conf.APILogin(vaultAddr, clientset)
+	if err != nil {
+		return fmt.Errorf("failed to log in to vault as kubefirst-api: %w", err)
+	}
+	vaultClient.SetToken(vaultToken)
 
 	if clctrl.CloudProvider == "akamai" {
 		secretToCreate := &v1.Secret{
@@ -457,3 +466,61 @@ func writeGoogleSecrets(homeDir string, vaultClient *vaultapi.Client) error {
 	}
 	return nil
 }
+
+// RevokeVaultRootToken revokes the vault root token once bootstrap is complete
+// when VAULT_REVOKE_ROOT_TOKEN is set, kubefirst-api writes to vault with its
+// scoped token afterwards. The vault and users terraform of each provider still
+// read the root token, so retried steps and later terraform runs need it.
+func (clctrl *ClusterController) RevokeVaultRootToken() error {
+	if !clctrl.Env.VaultRevokeRootToken {
+		log.Info().Msg("vault root token revocation is disabled")
+		return nil
+	}
+
+	// external secrets operator authenticates with the root token on akamai
+	if clctrl.CloudProvider == "akamai" {
+		log.Warn().Msg("the vault root token is used by external secrets operator on akamai and is not revoked")
+		return nil
+	}
+
+	kcfg, err := clctrl.clusterKubernetesClient()
+	if err != nil {
+		return err
+	}
+
+	if err := vault.Conf.RevokeRootToken(vault.VaultDefaultAddress, kcfg.Clientset); err != nil {
+		return fmt.Errorf("failed to revoke vault root token: %w", err)
+	}
+
+	clctrl.VaultAuth.RootToken = ""
+	clctrl.Cluster.VaultAuth.RootToken = ""
+	if err := secrets.UpdateCluster(clctrl.KubernetesClient, clctrl.Cluster); err != nil {
+		return fmt.Errorf("failed to update cluster after revoking vault root token: %w", err)
+	}
+
+	return nil
+}
+
+// clusterKubernetesClient returns a client for the cluster being provisioned
+func (clctrl *ClusterController) clusterKubernetesClient() (*k8s.KubernetesClient, error) {
+	switch clctrl.CloudProvider {
+	case "aws":
+		kcfg, err := awsext.CreateEKSKubeconfig(&clctrl.AwsClient.Config, clctrl.ClusterName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create eks config: %w", err)
+		}
+		return kcfg, nil
+	case "google":
+		kcfg, err := clctrl.GoogleClient.GetContainerClusterAuth(clctrl.ClusterName, []byte(clctrl.GoogleAuth.KeyFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get google client auth: %w", err)
+		}
+		return kcfg, nil
+	default:
+		kcfg, err := k8s.CreateKubeConfig(false, clctrl.ProviderConfig.Kubeconfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Kubernetes config: %w", err)
+		}
+		return kcfg, nil
+	}
+}
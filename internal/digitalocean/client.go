@@ -8,13 +8,21 @@ package digitalocean
 
 import (
 	"fmt"
+	"net/http"
 	"sort"
 
 	"github.com/digitalocean/godo"
+	"github.com/konstructio/kubefirst-api/internal/httpCommon"
+	"golang.org/x/oauth2"
 )
 
 func NewDigitalocean(digitalOceanToken string) *godo.Client {
-	digitaloceanClient := godo.NewFromToken(digitalOceanToken)
+	digitaloceanClient := godo.NewClient(&http.Client{
+		Transport: &oauth2.Transport{
+			Source: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: digitalOceanToken}),
+			Base:   httpCommon.TracedTransport(),
+		},
+	})
 
 	return digitaloceanClient
 }
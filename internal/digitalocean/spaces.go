@@ -10,6 +10,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/konstructio/kubefirst-api/internal/httpCommon"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
 )
@@ -21,8 +22,9 @@ func (c *Configuration) CreateSpaceBucket(cr SpacesCredentials, bucketName strin
 
 	// Initialize minio client object.
 	minioClient, err := minio.New(cr.Endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(cr.AccessKey, cr.SecretAccessKey, ""),
-		Secure: useSSL,
+		Creds:     credentials.NewStaticV4(cr.AccessKey, cr.SecretAccessKey, ""),
+		Secure:    useSSL,
+		Transport: httpCommon.TracedTransport(),
 	})
 	if err != nil {
 		return fmt.Errorf("error initializing minio client for digitalocean: %w", err)
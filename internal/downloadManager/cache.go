@@ -0,0 +1,358 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package downloadManager //nolint:revive,stylecheck // allowing temporarily for better code organization
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	kubectlReleaseURL   = "https://dl.k8s.io/release"
+	terraformReleaseURL = "https://releases.hashicorp.com/terraform"
+	hashicorpKeyURL     = "https://www.hashicorp.com/.well-known/pgp-key.txt"
+
+	// HashicorpKeyFingerprint is the fingerprint of the key signing HashiCorp releases
+	HashicorpKeyFingerprint = "C874011F0AB405110D02105534365D9472D7468F"
+
+	// hashicorpKeyFile is the name of the HashiCorp release key in a preseed directory
+	hashicorpKeyFile = "hashicorp.asc"
+)
+
+// ToolCache holds verified tool binaries shared by every cluster, keyed by
+// tool, version, os and architecture. Release artifacts are read from the
+// preseed directory when it holds them and downloaded otherwise, and are
+// verified either way before a tool enters the cache.
+//
+// The preseed directory mirrors the cache layout with the published release
+// artifacts, for example
+//
+//	kubectl/v1.28.1/linux_amd64/{kubectl,kubectl.sha256}
+//	terraform/1.5.7/linux_amd64/{terraform_1.5.7_linux_amd64.zip,terraform_1.5.7_SHA256SUMS,terraform_1.5.7_SHA256SUMS.sig}
+//	terraform/hashicorp.asc
+type ToolCache struct {
+	Dir                string
+	PreseedDir         string
+	TerraformPublicKey string
+}
+
+// NewToolCache returns a tool cache in dir, ~/.k1/tools-cache when empty
+func NewToolCache(dir, preseedDir, terraformPublicKey string) (*ToolCache, error) {
+	if dir == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("unable to get user home directory: %w", err)
+		}
+		dir = filepath.Join(homeDir, ".k1", "tools-cache")
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("unable to create tools cache %q: %w", dir, err)
+	}
+
+	return &ToolCache{
+		Dir:                dir,
+		PreseedDir:         preseedDir,
+		TerraformPublicKey: terraformPublicKey,
+	}, nil
+}
+
+// Kubectl installs a verified kubectl binary at target
+func (c *ToolCache) Kubectl(version, goos, arch, target string) error {
+	cached, err := c.ensure("kubectl", version, goos, arch, func(stagingDir string) error {
+		url := fmt.Sprintf("%s/%s/bin/%s/%s/kubectl", kubectlReleaseURL, version, goos, arch)
+		binary := filepath.Join(stagingDir, "kubectl")
+		sumFile := binary + ".sha256"
+
+		if err := c.fetch(binary, "kubectl", version, goos, arch, url); err != nil {
+			return err
+		}
+		if err := c.fetch(sumFile, "kubectl", version, goos, arch, url+".sha256"); err != nil {
+			return err
+		}
+
+		sum, err := os.ReadFile(sumFile)
+		if err != nil {
+			return fmt.Errorf("unable to read kubectl checksum: %w", err)
+		}
+		fields := strings.Fields(string(sum))
+		if len(fields) == 0 {
+			return errors.New("kubectl checksum file is empty")
+		}
+
+		return VerifySHA256(binary, fields[0])
+	})
+	if err != nil {
+		return err
+	}
+
+	return installTool(cached, target)
+}
+
+// Terraform installs a verified terraform binary at target. The SHA256SUMS of
+// the release are checked against the HashiCorp signature before the archive
+// checksum is trusted.
+func (c *ToolCache) Terraform(version, goos, arch, target string) error {
+	cached, err := c.ensure("terraform", version, goos, arch, func(stagingDir string) error {
+		zipName := fmt.Sprintf("terraform_%s_%s_%s.zip", version, goos, arch)
+		sumsName := fmt.Sprintf("terraform_%s_SHA256SUMS", version)
+		baseURL := fmt.Sprintf("%s/%s", terraformReleaseURL, version)
+
+		for _, name := range []string{zipName, sumsName, sumsName + ".sig"} {
+			if err := c.fetch(filepath.Join(stagingDir, name), "terraform", version, goos, arch, baseURL+"/"+name); err != nil {
+				return err
+			}
+		}
+
+		keyRing, err := c.terraformKeyRing()
+		if err != nil {
+			return err
+		}
+
+		sig, err := os.Open(filepath.Join(stagingDir, sumsName+".sig"))
+		if err != nil {
+			return fmt.Errorf("unable to open terraform checksum signature: %w", err)
+		}
+		defer sig.Close()
+
+		sumsFile := filepath.Join(stagingDir, sumsName)
+		if err := VerifyDetachedSignature(sumsFile, sig, bytes.NewReader(keyRing)); err != nil {
+			return err
+		}
+
+		expected, err := checksumFor(sumsFile, zipName)
+		if err != nil {
+			return err
+		}
+
+		zipFile := filepath.Join(stagingDir, zipName)
+		if err := VerifySHA256(zipFile, expected); err != nil {
+			return err
+		}
+
+		unzipDir := filepath.Join(stagingDir, "unzip")
+		if err := Unzip(zipFile, unzipDir); err != nil {
+			return err
+		}
+
+		return os.Rename(filepath.Join(unzipDir, "terraform"), filepath.Join(stagingDir, "terraform"))
+	})
+	if err != nil {
+		return err
+	}
+
+	return installTool(cached, target)
+}
+
+// ensure returns the path of a cached tool, building it with build when the
+// cache does not hold a binary matching its recorded checksum. build leaves
+// the verified binary named after the tool in the staging directory.
+func (c *ToolCache) ensure(tool, version, goos, arch string, build func(stagingDir string) error) (string, error) {
+	dir := filepath.Join(c.Dir, tool, version, goos+"_"+arch)
+	binary := filepath.Join(dir, tool)
+	sumFile := binary + ".sha256"
+
+	if sum, err := os.ReadFile(sumFile); err == nil {
+		if err := VerifySHA256(binary, string(sum)); err == nil {
+			log.Info().Msgf("using cached %s %s from %s", tool, version, dir)
+			return binary, nil
+		}
+		log.Warn().Msgf("cached %s %s failed verification, fetching it again", tool, version)
+		if err := os.RemoveAll(dir); err != nil {
+			return "", fmt.Errorf("unable to remove cached %s %s: %w", tool, version, err)
+		}
+	}
+
+	stagingDir, err := os.MkdirTemp(c.Dir, ".staging-")
+	if err != nil {
+		return "", fmt.Errorf("unable to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(stagingDir)
+
+	if err := build(stagingDir); err != nil {
+		return "", fmt.Errorf("unable to fetch %s %s for %s/%s: %w", tool, version, goos, arch, err)
+	}
+
+	sum, err := FileSHA256(filepath.Join(stagingDir, tool))
+	if err != nil {
+		return "", err
+	}
+
+	// populate a sibling directory and rename it into place so concurrent
+	// provisioning never sees a partial entry
+	if err := os.MkdirAll(filepath.Dir(dir), 0o755); err != nil {
+		return "", fmt.Errorf("unable to create tools cache directory: %w", err)
+	}
+	entryDir, err := os.MkdirTemp(filepath.Dir(dir), ".entry-")
+	if err != nil {
+		return "", fmt.Errorf("unable to create tools cache entry: %w", err)
+	}
+	defer os.RemoveAll(entryDir)
+
+	if err := os.Rename(filepath.Join(stagingDir, tool), filepath.Join(entryDir, tool)); err != nil {
+		return "", fmt.Errorf("unable to move %s into the tools cache: %w", tool, err)
+	}
+	if err := os.WriteFile(filepath.Join(entryDir, tool+".sha256"), []byte(sum), 0o644); err != nil {
+		return "", fmt.Errorf("unable to record %s checksum: %w", tool, err)
+	}
+
+	if err := os.Rename(entryDir, dir); err != nil {
+		if _, statErr := os.Stat(sumFile); statErr != nil {
+			return "", fmt.Errorf("unable to add %s to the tools cache: %w", tool, err)
+		}
+		// another provisioning run cached the same tool first
+	}
+
+	log.Info().Msgf("cached verified %s %s in %s", tool, version, dir)
+	return binary, nil
+}
+
+// fetch places a release artifact at target, preferring the preseed directory
+// over url
+func (c *ToolCache) fetch(target, tool, version, goos, arch, url string) error {
+	if c.PreseedDir != "" {
+		preseeded := filepath.Join(c.PreseedDir, tool, version, goos+"_"+arch, filepath.Base(target))
+		if _, err := os.Stat(preseeded); err == nil {
+			log.Info().Msgf("using preseeded %s", preseeded)
+			return copyFile(preseeded, target, 0o644)
+		}
+	}
+
+	log.Info().Msgf("downloading %s", url)
+	return DownloadFile(target, url)
+}
+
+// terraformKeyRing returns the armored key verifying terraform releases. A
+// configured key is trusted as is, a preseeded or downloaded key must match
+// the pinned HashiCorp fingerprint.
+func (c *ToolCache) terraformKeyRing() ([]byte, error) {
+	if c.TerraformPublicKey != "" {
+		key, err := os.ReadFile(c.TerraformPublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read terraform public key: %w", err)
+		}
+		return key, nil
+	}
+
+	var key []byte
+	preseeded := filepath.Join(c.PreseedDir, "terraform", hashicorpKeyFile)
+	if c.PreseedDir != "" {
+		if k, err := os.ReadFile(preseeded); err == nil {
+			key = k
+		}
+	}
+
+	if key == nil {
+		f, err := os.CreateTemp(c.Dir, ".hashicorp-key-")
+		if err != nil {
+			return nil, fmt.Errorf("unable to create temporary file: %w", err)
+		}
+		f.Close()
+		defer os.Remove(f.Name())
+
+		if err := DownloadFile(f.Name(), hashicorpKeyURL); err != nil {
+			return nil, err
+		}
+		if key, err = os.ReadFile(f.Name()); err != nil {
+			return nil, fmt.Errorf("unable to read hashicorp public key: %w", err)
+		}
+	}
+
+	if err := checkFingerprint(key, HashicorpKeyFingerprint); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// checkFingerprint checks that an armored key ring holds the key with the
+// given fingerprint
+func checkFingerprint(armoredKeyRing []byte, fingerprint string) error {
+	keyRing, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(armoredKeyRing))
+	if err != nil {
+		return fmt.Errorf("unable to read public key: %w", err)
+	}
+
+	for _, entity := range keyRing {
+		if strings.EqualFold(hex.EncodeToString(entity.PrimaryKey.Fingerprint), fingerprint) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("public key does not match fingerprint %s", fingerprint)
+}
+
+// checksumFor returns the checksum of name in a SHA256SUMS file
+func checksumFor(sumsFile, name string) (string, error) {
+	f, err := os.Open(sumsFile)
+	if err != nil {
+		return "", fmt.Errorf("unable to open checksums %q: %w", sumsFile, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return fields[0], nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("unable to read checksums %q: %w", sumsFile, err)
+	}
+
+	return "", fmt.Errorf("no checksum for %q in %q", name, sumsFile)
+}
+
+// installTool copies a cached tool to target
+func installTool(cached, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return fmt.Errorf("unable to create directory %q: %w", filepath.Dir(target), err)
+	}
+
+	tmp := target + ".tmp"
+	if err := copyFile(cached, tmp, 0o755); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp, target); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("unable to install %q: %w", target, err)
+	}
+
+	return nil
+}
+
+func copyFile(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("unable to open file %q: %w", src, err)
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return fmt.Errorf("unable to create file %q: %w", dst, err)
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, in); err != nil {
+		return fmt.Errorf("unable to copy %q to %q: %w", src, dst, err)
+	}
+
+	return out.Chmod(perm)
+}
@@ -0,0 +1,93 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package downloadManager //nolint:revive,stylecheck // allowing temporarily for better code organization
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestToolCacheKubectlPreseed(t *testing.T) {
+	binary := []byte("#!/bin/sh\necho kubectl\n")
+	digest := sha256.Sum256(binary)
+
+	tests := []struct {
+		name    string
+		sum     string
+		wantErr bool
+	}{
+		{name: "matching checksum", sum: hex.EncodeToString(digest[:])},
+		{name: "mismatched checksum", sum: hex.EncodeToString(make([]byte, sha256.Size)), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preseedDir := t.TempDir()
+			platformDir := filepath.Join(preseedDir, "kubectl", "v1.28.1", "linux_amd64")
+			if err := os.MkdirAll(platformDir, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(platformDir, "kubectl"), binary, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(platformDir, "kubectl.sha256"), []byte(tt.sum), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			cache, err := NewToolCache(t.TempDir(), preseedDir, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			target := filepath.Join(t.TempDir(), "tools", "kubectl")
+			err = cache.Kubectl("v1.28.1", "linux", "amd64", target)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Kubectl() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if _, err := os.Stat(filepath.Join(cache.Dir, "kubectl", "v1.28.1")); !os.IsNotExist(err) {
+					t.Errorf("unverified kubectl was added to the cache")
+				}
+				return
+			}
+
+			info, err := os.Stat(target)
+			if err != nil {
+				t.Fatalf("kubectl not installed: %v", err)
+			}
+			if info.Mode().Perm() != 0o755 {
+				t.Errorf("kubectl mode = %v, want 0755", info.Mode().Perm())
+			}
+
+			// a second cluster reuses the cache without the preseed directory
+			cache.PreseedDir = ""
+			if err := cache.Kubectl("v1.28.1", "linux", "amd64", filepath.Join(t.TempDir(), "kubectl")); err != nil {
+				t.Errorf("cached kubectl was not reused: %v", err)
+			}
+		})
+	}
+}
+
+func TestChecksumFor(t *testing.T) {
+	sums := filepath.Join(t.TempDir(), "terraform_1.5.7_SHA256SUMS")
+	content := "aaaa  terraform_1.5.7_darwin_arm64.zip\nbbbb  terraform_1.5.7_linux_amd64.zip\n"
+	if err := os.WriteFile(sums, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := checksumFor(sums, "terraform_1.5.7_linux_amd64.zip")
+	if err != nil || got != "bbbb" {
+		t.Errorf("checksumFor() = %q, %v, want %q", got, err, "bbbb")
+	}
+
+	if _, err := checksumFor(sums, "terraform_1.5.7_windows_amd64.zip"); err == nil {
+		t.Error("checksumFor() expected an error for a missing entry")
+	}
+}
@@ -89,6 +89,63 @@ func ExtractFileFromTarGz(gzipStream io.Reader, tarAddress string, targetFilePat
 	return nil
 }
 
+// ExtractTarGz extracts every file in a gzipped tarball into targetDirectory
+func ExtractTarGz(gzipStream io.Reader, targetDirectory string) error {
+	uncompressedStream, err := gzip.NewReader(gzipStream)
+	if err != nil {
+		return fmt.Errorf("unable to create gzip reader: %w", err)
+	}
+
+	tarReader := tar.NewReader(uncompressedStream)
+	targetDirectory = filepath.Clean(targetDirectory)
+
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return fmt.Errorf("unable to read tar contents: %w", err)
+		}
+
+		filePath := filepath.Join(targetDirectory, filepath.Clean(header.Name))
+		// archives created with tar -C dir . start with the ./ root entry
+		if filePath == targetDirectory {
+			continue
+		}
+		if !strings.HasPrefix(filePath, targetDirectory+string(os.PathSeparator)) {
+			return fmt.Errorf("invalid file path: %q", filePath)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(filePath, os.ModePerm); err != nil {
+				return fmt.Errorf("unable to create directory %q: %w", filePath, err)
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
+				return fmt.Errorf("unable to create directory %q: %w", filepath.Dir(filePath), err)
+			}
+
+			outFile, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.FileMode(header.Mode).Perm())
+			if err != nil {
+				return fmt.Errorf("unable to create file %q: %w", filePath, err)
+			}
+
+			_, err = io.Copy(outFile, tarReader)
+			outFile.Close()
+			if err != nil {
+				return fmt.Errorf("unable to copy contents to file %q: %w", filePath, err)
+			}
+		default:
+			log.Info().Msgf("skipping unsupported type: %s in %s", string(header.Typeflag), header.Name)
+		}
+	}
+
+	return nil
+}
+
 func Unzip(zipFilepath string, unzipDirectory string) error {
 	archive, err := zip.OpenReader(zipFilepath)
 	if err != nil {
@@ -0,0 +1,99 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package downloadManager //nolint:revive,stylecheck // allowing temporarily for better code organization
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// tarGz builds an archive of entries, directories end with a slash
+func tarGz(t *testing.T, entries map[string]string, order []string) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+
+	for _, name := range order {
+		content := entries[name]
+		header := &tar.Header{Name: name, Mode: 0o644, Typeflag: tar.TypeReg, Size: int64(len(content))}
+		if name[len(name)-1] == '/' {
+			header = &tar.Header{Name: name, Mode: 0o755, Typeflag: tar.TypeDir}
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if header.Typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte(content)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestExtractTarGz(t *testing.T) {
+	tests := []struct {
+		name    string
+		order   []string
+		entries map[string]string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:    "rooted at ./",
+			order:   []string{"./", "./catalog/", "./catalog/index.yaml"},
+			entries: map[string]string{"./catalog/index.yaml": "apps: []\n"},
+			want:    map[string]string{"catalog/index.yaml": "apps: []\n"},
+		},
+		{
+			name:    "relative paths",
+			order:   []string{"secrets/", "secrets/tls.yaml"},
+			entries: map[string]string{"secrets/tls.yaml": "kind: Secret\n"},
+			want:    map[string]string{"secrets/tls.yaml": "kind: Secret\n"},
+		},
+		{
+			name:    "outside of the target directory",
+			order:   []string{"../escape.yaml"},
+			entries: map[string]string{"../escape.yaml": "kind: Secret\n"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			err := ExtractTarGz(tarGz(t, tt.entries, tt.order), dir+string(os.PathSeparator))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExtractTarGz() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			for name, content := range tt.want {
+				got, err := os.ReadFile(filepath.Join(dir, name))
+				if err != nil {
+					t.Fatalf("unable to read extracted file %s: %s", name, err)
+				}
+				if string(got) != content {
+					t.Errorf("extracted %s = %q, want %q", name, got, content)
+				}
+			}
+		})
+	}
+}
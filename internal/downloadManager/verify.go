@@ -0,0 +1,80 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package downloadManager //nolint:revive,stylecheck // allowing temporarily for better code organization
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
+
+// FileSHA256 returns the hex encoded SHA256 digest of a local file
+func FileSHA256(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("unable to open file %q: %w", filePath, err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("unable to read file %q: %w", filePath, err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// VerifySHA256 checks a local file against an expected hex encoded SHA256 digest
+func VerifySHA256(filePath, expected string) error {
+	actual, err := FileSHA256(filePath)
+	if err != nil {
+		return err
+	}
+
+	expected = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(expected)), "sha256:")
+	if actual != expected {
+		return fmt.Errorf("checksum mismatch for %q: expected %s, got %s", filePath, expected, actual)
+	}
+
+	return nil
+}
+
+// VerifyDetachedSignature checks a detached OpenPGP signature for a local file against
+// an armored public key ring. Both armored and binary signatures are accepted.
+func VerifyDetachedSignature(filePath string, signature io.Reader, armoredKeyRing io.Reader) error {
+	keyRing, err := openpgp.ReadArmoredKeyRing(armoredKeyRing)
+	if err != nil {
+		return fmt.Errorf("unable to read public key: %w", err)
+	}
+
+	sig, err := io.ReadAll(signature)
+	if err != nil {
+		return fmt.Errorf("unable to read signature: %w", err)
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("unable to open file %q: %w", filePath, err)
+	}
+	defer f.Close()
+
+	if strings.HasPrefix(strings.TrimSpace(string(sig)), "-----BEGIN PGP SIGNATURE-----") {
+		_, err = openpgp.CheckArmoredDetachedSignature(keyRing, f, strings.NewReader(string(sig)), nil)
+	} else {
+		_, err = openpgp.CheckDetachedSignature(keyRing, f, strings.NewReader(string(sig)), nil)
+	}
+	if err != nil {
+		return fmt.Errorf("signature verification failed for %q: %w", filePath, err)
+	}
+
+	return nil
+}
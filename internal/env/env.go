@@ -1,16 +1,14 @@
 package env
 
 import (
-	"fmt"
-
-	env "github.com/caarlos0/env/v10"
-	"github.com/joho/godotenv"
-	"github.com/rs/zerolog/log"
+	"time"
 )
 
+// Env is the configuration of the API. Fields tagged reload are applied on
+// SIGHUP, the others require a restart.
 type Env struct {
 	ServerPort            int    `env:"SERVER_PORT" envDefault:"8081"`
-	K1AccessToken         string `env:"K1_ACCESS_TOKEN"`
+	K1AccessToken         string `env:"K1_ACCESS_TOKEN" reload:"true"`
 	KubefirstVersion      string `env:"KUBEFIRST_VERSION" envDefault:"main"`
 	CloudProvider         string `env:"CLOUD_PROVIDER"`
 	ClusterID             string `env:"CLUSTER_ID"`
@@ -28,19 +26,72 @@ type Env struct {
 	EnterpriseAPIURL      string `env:"ENTERPRISE_API_URL"`
 	K1LocalDebug          bool   `env:"K1_LOCAL_DEBUG"`
 	K1LocalKubeconfigPath string `env:"K1_LOCAL_KUBECONFIG_PATH"`
-}
+	KubeNamespace         string `env:"KUBE_NAMESPACE"`
+	LogLevel              string `env:"LOG_LEVEL" envDefault:"debug" reload:"true"`
+
+	// ArgoCD endpoint reached instead of the local port forward when set
+	ArgoCDLocalService string `env:"ARGOCD_LOCAL_SERVICE"`
+
+	// Time given to running operations to reach a step boundary on SIGTERM,
+	// operations still running are then marked interrupted
+	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" envDefault:"5m"`
+
+	// Offline gitops catalog mirror. Tarball and OCI mirrors are verified with the
+	// checksum or the signature, and refused without one unless insecure is set.
+	// A mounted directory is trusted as is and cannot be verified.
+	GitopsCatalogMirror          string `env:"GITOPS_CATALOG_MIRROR" reload:"true"`
+	GitopsCatalogMirrorSHA256    string `env:"GITOPS_CATALOG_MIRROR_SHA256" reload:"true"`
+	GitopsCatalogMirrorSignature string `env:"GITOPS_CATALOG_MIRROR_SIGNATURE" reload:"true"`
+	GitopsCatalogMirrorPublicKey string `env:"GITOPS_CATALOG_MIRROR_PUBLIC_KEY" reload:"true"`
+	GitopsCatalogMirrorInsecure  bool   `env:"GITOPS_CATALOG_MIRROR_INSECURE" envDefault:"false" reload:"true"`
+	GitopsCatalogMirrorUsername  string `env:"GITOPS_CATALOG_MIRROR_USERNAME" reload:"true"`
+	GitopsCatalogMirrorPassword  string `env:"GITOPS_CATALOG_MIRROR_PASSWORD" reload:"true"`
+	GitopsCatalogMirrorPlainHTTP bool   `env:"GITOPS_CATALOG_MIRROR_PLAIN_HTTP" envDefault:"false" reload:"true"`
+
+	// Interval between tls certificate backups to the cluster state store, 0 disables them
+	TLSBackupInterval time.Duration `env:"TLS_BACKUP_INTERVAL" envDefault:"24h"`
+	// LetsEncrypt rate limit check before cluster create, one of warn, block or off
+	CertificateUsageCheck string `env:"CERTIFICATE_USAGE_CHECK" envDefault:"warn" reload:"true"`
+	// Interval between certificate expiry checks, 0 disables them
+	CertificateExpiryCheckInterval time.Duration `env:"CERTIFICATE_EXPIRY_CHECK_INTERVAL" envDefault:"12h"`
+	// Certificates expiring within this window are flagged on the cluster record
+	CertificateExpiryWindow time.Duration `env:"CERTIFICATE_EXPIRY_WINDOW" envDefault:"336h"`
+
+	// Revoke the vault root token once cluster bootstrap completes. Off by default
+	// while the vault and users terraform still read the root token from
+	// vault-unseal-secret, later terraform runs fail once it is revoked.
+	VaultRevokeRootToken bool `env:"VAULT_REVOKE_ROOT_TOKEN" envDefault:"false"`
 
-func GetEnv(silent bool) (Env, error) {
-	err := godotenv.Load(".env")
+	// Verified tool downloads shared by every cluster, defaults to ~/.k1/tools-cache
+	ToolsCacheDir string `env:"TOOLS_CACHE_DIR"`
+	// Local directory holding tool release artifacts for provisioning without internet access
+	ToolsPreseedDir string `env:"TOOLS_PRESEED_DIR"`
+	// Armored public key used instead of the pinned HashiCorp release key
+	ToolsTerraformPublicKey string `env:"TOOLS_TERRAFORM_PUBLIC_KEY"`
 
-	if err != nil && !silent {
-		log.Info().Msg("error loading .env file, using local environment variables")
-	}
+	// Outbound HTTP proxy, the standard proxy environment variables are used when empty
+	OutboundProxyURL string `env:"OUTBOUND_PROXY_URL"`
+	// Comma separated hosts, domains and CIDRs reached without the proxy
+	OutboundNoProxy string `env:"OUTBOUND_NO_PROXY"`
+	// PEM bundle of certificate authorities trusted in addition to the system ones
+	OutboundCABundle string `env:"OUTBOUND_CA_BUNDLE"`
+	// Comma separated hosts whose certificates are not verified
+	OutboundInsecureHosts []string `env:"OUTBOUND_INSECURE_HOSTS"`
+	// PEM bundles trusted for a single host, as host:path pairs
+	OutboundHostCABundles map[string]string `env:"OUTBOUND_HOST_CA_BUNDLES"`
 
-	environment := Env{}
-	if err := env.Parse(&environment); err != nil {
-		return Env{}, fmt.Errorf("error parsing environment variables: %w", err)
-	}
+	// Disables every telemetry event when false, whatever the sink
+	UseTelemetry bool `env:"USE_TELEMETRY" envDefault:"true" reload:"true"`
+	// Telemetry destination, one of upstream, file, webhook or none
+	TelemetrySink string `env:"TELEMETRY_SINK" envDefault:"upstream" reload:"true"`
+	// JSONL file of the file sink, defaults to ~/.k1/telemetry.jsonl
+	TelemetryFile string `env:"TELEMETRY_FILE" reload:"true"`
+	// URL receiving the events of the webhook sink as JSON posts
+	TelemetryWebhookURL string `env:"TELEMETRY_WEBHOOK_URL" reload:"true"`
 
-	return environment, nil
+	// Trace exporter, one of none, otlp or stdout. The otlp exporter is configured
+	// with the standard OTEL_EXPORTER_OTLP_* variables
+	TracingExporter string `env:"TRACING_EXPORTER" envDefault:"none"`
+	// Fraction of requests and operations traced
+	TracingSampleRatio float64 `env:"TRACING_SAMPLE_RATIO" envDefault:"1"`
 }
@@ -3,8 +3,6 @@ package env
 import (
 	"os"
 	"testing"
-
-	"github.com/konstructio/kubefirst-api/internal/constants"
 )
 
 func TestEnv(t *testing.T) {
@@ -44,11 +42,11 @@ func TestEnv(t *testing.T) {
 		os.Unsetenv("ENTERPRISE_API_URL")
 	}()
 
-	env := Env{}
-	env, err := GetEnv(constants.SilenceGetEnv)
+	cfg, err := Load(Options{})
 	if err != nil {
-		t.Errorf("unexpected error: %v", err)
+		t.Fatalf("unexpected error: %v", err)
 	}
+	env := cfg.Get()
 
 	if env.ServerPort != 8081 {
 		t.Errorf("expected ServerPort to be 8081, but got %v", env.ServerPort)
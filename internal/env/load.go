@@ -0,0 +1,207 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	env "github.com/caarlos0/env/v10"
+	"github.com/joho/godotenv"
+	"github.com/rs/zerolog/log"
+	"sigs.k8s.io/yaml"
+)
+
+// Options are the sources of the configuration besides the environment
+type Options struct {
+	// File is a YAML file of settings keyed by their environment variable
+	// name, in any case. Lists are YAML sequences and maps YAML mappings.
+	File string
+	// Overrides take precedence over the file and the environment, they are
+	// keyed by environment variable name and typically set from flags
+	Overrides map[string]string
+}
+
+// Config is the loaded configuration. It is handed to the router, the
+// controllers and the background jobs, which read it with Get so reloaded
+// settings apply to their next use.
+type Config struct {
+	mu          sync.RWMutex
+	current     Env
+	sources     Options
+	reloadHooks []func(previous, updated Env)
+}
+
+// Load reads the configuration from the file, the environment and the
+// overrides, in increasing order of precedence, and validates it
+func Load(opts Options) (*Config, error) {
+	e, err := parse(opts, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Config{current: e, sources: opts}, nil
+}
+
+// Static returns a configuration holding e that is never reloaded, for tests
+// and library use
+func Static(e Env) *Config {
+	return &Config{current: e}
+}
+
+// Get returns the current configuration
+func (c *Config) Get() Env {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.current
+}
+
+// Reload reads the sources given to Load again and applies the settings
+// tagged reload. Changes to the other settings are logged and ignored until
+// the next restart. The configuration is left untouched when it is invalid.
+func (c *Config) Reload() (Env, error) {
+	c.mu.RLock()
+	opts := c.sources
+	c.mu.RUnlock()
+
+	updated, err := parse(opts, true)
+	if err != nil {
+		return Env{}, err
+	}
+
+	c.mu.Lock()
+	previous := c.current
+	applied, restart := applyReloadable(previous, updated)
+	c.current = applied
+	hooks := c.reloadHooks
+	c.mu.Unlock()
+
+	for _, name := range restart {
+		log.Warn().Msgf("%s changed, restart the API to apply it", name)
+	}
+	for _, hook := range hooks {
+		hook(previous, applied)
+	}
+
+	log.Info().Msg("configuration reloaded")
+	return applied, nil
+}
+
+// OnReload registers a function called after every successful reload with the
+// previous and the reloaded configuration
+func (c *Config) OnReload(hook func(previous, updated Env)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.reloadHooks = append(c.reloadHooks, hook)
+}
+
+// parse reads and validates the configuration from its sources
+func parse(opts Options, silent bool) (Env, error) {
+	err := godotenv.Load(".env")
+	if err != nil && !silent {
+		log.Info().Msg("error loading .env file, using local environment variables")
+	}
+
+	vars := map[string]string{}
+	if opts.File != "" {
+		if vars, err = readFile(opts.File); err != nil {
+			return Env{}, err
+		}
+	}
+	for _, kv := range os.Environ() {
+		if key, value, ok := strings.Cut(kv, "="); ok {
+			vars[key] = value
+		}
+	}
+	for key, value := range opts.Overrides {
+		vars[key] = value
+	}
+
+	environment := Env{}
+	if err := env.ParseWithOptions(&environment, env.Options{Environment: vars}); err != nil {
+		return Env{}, fmt.Errorf("error parsing environment variables: %w", err)
+	}
+
+	if err := environment.Validate(); err != nil {
+		return Env{}, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	return environment, nil
+}
+
+// readFile reads a configuration file into environment variables
+func readFile(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read configuration file %q: %w", path, err)
+	}
+
+	settings := map[string]interface{}{}
+	if err := yaml.Unmarshal(content, &settings); err != nil {
+		return nil, fmt.Errorf("unable to parse configuration file %q: %w", path, err)
+	}
+
+	vars := make(map[string]string, len(settings))
+	for key, value := range settings {
+		vars[strings.ToUpper(key)] = fileValue(value)
+	}
+
+	return vars, nil
+}
+
+// fileValue formats a file setting the way the environment variable is parsed
+func fileValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, fileValue(item))
+		}
+		return strings.Join(items, ",")
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		pairs := make([]string, 0, len(v))
+		for _, key := range keys {
+			pairs = append(pairs, key+":"+fileValue(v[key]))
+		}
+		return strings.Join(pairs, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// applyReloadable returns previous with the reloadable settings of updated,
+// along with the changed settings that require a restart
+func applyReloadable(previous, updated Env) (Env, []string) {
+	applied := previous
+	av := reflect.ValueOf(&applied).Elem()
+	uv := reflect.ValueOf(updated)
+	t := av.Type()
+
+	var restart []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if reflect.DeepEqual(av.Field(i).Interface(), uv.Field(i).Interface()) {
+			continue
+		}
+
+		if field.Tag.Get("reload") == "true" {
+			av.Field(i).Set(uv.Field(i))
+			continue
+		}
+		restart = append(restart, field.Tag.Get("env"))
+	}
+
+	return applied, restart
+}
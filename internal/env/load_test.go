@@ -0,0 +1,78 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParsePrecedence(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	content := "server_port: 9000\nlog_level: warn\ncluster_id: from-file\n"
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatalf("unable to write configuration file: %v", err)
+	}
+
+	t.Setenv("LOG_LEVEL", "error")
+	t.Setenv("CLUSTER_ID", "from-env")
+
+	e, err := parse(Options{File: file, Overrides: map[string]string{"CLUSTER_ID": "from-flag"}}, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if e.ServerPort != 9000 {
+		t.Errorf("expected ServerPort from the file to be 9000, but got %v", e.ServerPort)
+	}
+	if e.LogLevel != "error" {
+		t.Errorf("expected LogLevel from the environment to be 'error', but got '%s'", e.LogLevel)
+	}
+	if e.ClusterID != "from-flag" {
+		t.Errorf("expected ClusterID from the overrides to be 'from-flag', but got '%s'", e.ClusterID)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(e *Env)
+		wantErr bool
+	}{
+		{name: "defaults", mutate: func(_ *Env) {}},
+		{name: "port out of range", mutate: func(e *Env) { e.ServerPort = 70000 }, wantErr: true},
+		{name: "unknown log level", mutate: func(e *Env) { e.LogLevel = "loud" }, wantErr: true},
+		{name: "unknown certificate check", mutate: func(e *Env) { e.CertificateUsageCheck = "maybe" }, wantErr: true},
+		{name: "webhook sink without url", mutate: func(e *Env) { e.TelemetrySink = "webhook" }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := parse(Options{}, true)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			tt.mutate(&e)
+			if err := e.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApplyReloadable(t *testing.T) {
+	previous := Env{ServerPort: 8081, LogLevel: "debug"}
+	updated := Env{ServerPort: 9000, LogLevel: "info"}
+
+	applied, restart := applyReloadable(previous, updated)
+
+	if applied.LogLevel != "info" {
+		t.Errorf("expected LogLevel to be reloaded to 'info', but got '%s'", applied.LogLevel)
+	}
+	if applied.ServerPort != 8081 {
+		t.Errorf("expected ServerPort to be kept at 8081, but got %v", applied.ServerPort)
+	}
+	if len(restart) != 1 || restart[0] != "SERVER_PORT" {
+		t.Errorf("expected only SERVER_PORT to require a restart, but got %v", restart)
+	}
+}
@@ -0,0 +1,82 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/rs/zerolog"
+)
+
+// Validate reports every invalid setting of the configuration
+func (e Env) Validate() error {
+	var errs []error
+	invalid := func(name, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s: %s", name, fmt.Sprintf(format, args...)))
+	}
+
+	if e.ServerPort < 1 || e.ServerPort > 65535 {
+		invalid("SERVER_PORT", "must be between 1 and 65535, got %d", e.ServerPort)
+	}
+
+	if _, err := zerolog.ParseLevel(e.LogLevel); err != nil || e.LogLevel == "" {
+		invalid("LOG_LEVEL", "must be one of trace, debug, info, warn, error, fatal or panic, got %q", e.LogLevel)
+	}
+
+	if !oneOf(e.CertificateUsageCheck, "warn", "block", "off") {
+		invalid("CERTIFICATE_USAGE_CHECK", "must be one of warn, block or off, got %q", e.CertificateUsageCheck)
+	}
+
+	if e.ShutdownTimeout <= 0 {
+		invalid("SHUTDOWN_TIMEOUT", "must be positive")
+	}
+	if e.TLSBackupInterval < 0 {
+		invalid("TLS_BACKUP_INTERVAL", "must not be negative")
+	}
+	if e.CertificateExpiryCheckInterval < 0 {
+		invalid("CERTIFICATE_EXPIRY_CHECK_INTERVAL", "must not be negative")
+	}
+	if e.CertificateExpiryWindow < 0 {
+		invalid("CERTIFICATE_EXPIRY_WINDOW", "must not be negative")
+	}
+
+	if e.GitopsCatalogMirrorUsername != "" && e.GitopsCatalogMirrorPassword == "" {
+		invalid("GITOPS_CATALOG_MIRROR_PASSWORD", "is required with GITOPS_CATALOG_MIRROR_USERNAME")
+	}
+	if e.GitopsCatalogMirrorSignature != "" && e.GitopsCatalogMirrorPublicKey == "" {
+		invalid("GITOPS_CATALOG_MIRROR_PUBLIC_KEY", "is required with GITOPS_CATALOG_MIRROR_SIGNATURE")
+	}
+
+	if e.OutboundProxyURL != "" {
+		if u, err := url.Parse(e.OutboundProxyURL); err != nil || u.Host == "" {
+			invalid("OUTBOUND_PROXY_URL", "must be an absolute url, got %q", e.OutboundProxyURL)
+		}
+	}
+
+	if !oneOf(e.TelemetrySink, "upstream", "file", "webhook", "none") {
+		invalid("TELEMETRY_SINK", "must be one of upstream, file, webhook or none, got %q", e.TelemetrySink)
+	}
+	if e.TelemetrySink == "webhook" {
+		if u, err := url.Parse(e.TelemetryWebhookURL); err != nil || u.Host == "" {
+			invalid("TELEMETRY_WEBHOOK_URL", "must be an absolute url with the webhook sink, got %q", e.TelemetryWebhookURL)
+		}
+	}
+
+	if !oneOf(e.TracingExporter, "none", "otlp", "stdout") {
+		invalid("TRACING_EXPORTER", "must be one of none, otlp or stdout, got %q", e.TracingExporter)
+	}
+	if e.TracingSampleRatio < 0 || e.TracingSampleRatio > 1 {
+		invalid("TRACING_SAMPLE_RATIO", "must be between 0 and 1, got %v", e.TracingSampleRatio)
+	}
+
+	return errors.Join(errs...)
+}
+
+func oneOf(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+	return false
+}
@@ -15,7 +15,6 @@ import (
 	"strconv"
 	"time"
 
-	"github.com/konstructio/kubefirst-api/internal/constants"
 	"github.com/konstructio/kubefirst-api/internal/env"
 	"github.com/konstructio/kubefirst-api/internal/httpCommon"
 	"github.com/konstructio/kubefirst-api/internal/secrets"
@@ -38,7 +37,7 @@ func NewEnvironment(envDef types.Environment) (*types.Environment, error) {
 	return &newEnv, nil
 }
 
-func CreateDefaultClusters(mgmtCluster types.Cluster) error {
+func CreateDefaultClusters(e env.Env, mgmtCluster types.Cluster) error {
 	defaultClusterNames := []string{"development", "staging", "production"}
 
 	defaultVclusterTemplate := types.WorkloadCluster{
@@ -124,12 +123,11 @@ func CreateDefaultClusters(mgmtCluster types.Cluster) error {
 	}
 
 	// call api-ee to create clusters
-	return callAPIEE(defaultEnvironmentSet)
+	return callAPIEE(e, defaultEnvironmentSet)
 }
 
-func callAPIEE(payload types.WorkloadClusterSet) error {
+func callAPIEE(e env.Env, payload types.WorkloadClusterSet) error {
 	httpClient := httpCommon.CustomHTTPClient(false)
-	env, _ := env.GetEnv(constants.SilenceGetEnv)
 
 	for i, cluster := range payload.Clusters {
 		log.Info().Msgf("creating cluster %s for %s", strconv.Itoa(i), cluster.ClusterName)
@@ -139,7 +137,7 @@ func callAPIEE(payload types.WorkloadClusterSet) error {
 			return fmt.Errorf("error marshalling cluster %q: %w", cluster.ClusterName, err)
 		}
 
-		endpoint := fmt.Sprintf("%s/api/v1/cluster/%s", env.EnterpriseAPIURL, env.ClusterID)
+		endpoint := fmt.Sprintf("%s/api/v1/cluster/%s", e.EnterpriseAPIURL, e.ClusterID)
 		req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(payload))
 		if err != nil {
 			log.Error().Msgf("error creating http request %s", err)
@@ -9,24 +9,104 @@ package errors
 import (
 	"fmt"
 
+	"github.com/konstructio/kubefirst-api/internal/apierror"
 	"github.com/konstructio/kubefirst-api/internal/constants"
+	"github.com/konstructio/kubefirst-api/internal/metrics"
 	"github.com/konstructio/kubefirst-api/internal/secrets"
+	"github.com/konstructio/kubefirst-api/internal/shutdown"
 	"github.com/konstructio/kubefirst-api/internal/utils"
 	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
+	log "github.com/rs/zerolog/log"
 )
 
 // HandleClusterError implements an error handler for standalone cluster objects
-func HandleClusterError(cl *pkgtypes.Cluster, condition string) error {
+func HandleClusterError(cl *pkgtypes.Cluster, err error) error {
 	kcfg := utils.GetKubernetesClient(cl.ClusterName)
 
 	cl.InProgress = false
 	cl.Status = constants.ClusterStatusError
-	cl.LastCondition = condition
+	cl.LastCondition = err.Error()
+	cl.LastErrorCode = string(apierror.From(err).Code)
 
-	err := secrets.UpdateCluster(kcfg.Clientset, *cl)
-	if err != nil {
+	if err := secrets.UpdateCluster(kcfg.Clientset, *cl); err != nil {
 		return fmt.Errorf("failed to update cluster %q: %w", cl.ClusterName, err)
 	}
 
 	return nil
 }
+
+// HandleClusterInterrupted marks the record of a cluster whose operation was
+// stopped by an API shutdown, retrying the operation resumes it
+func HandleClusterInterrupted(clusterName, operation, condition string) error {
+	kcfg := utils.GetKubernetesClient(clusterName)
+	if kcfg == nil {
+		return fmt.Errorf("failed to create kubernetes client for cluster %q", clusterName)
+	}
+
+	cl, err := secrets.GetCluster(kcfg.Clientset, clusterName)
+	if err != nil {
+		return fmt.Errorf("failed to get cluster %q: %w", clusterName, err)
+	}
+
+	markInterrupted(cl, operation, condition)
+
+	if err := secrets.UpdateCluster(kcfg.Clientset, *cl); err != nil {
+		return fmt.Errorf("failed to update cluster %q: %w", clusterName, err)
+	}
+
+	return nil
+}
+
+// HandleOperationAbandoned records on a cluster that an operation which cannot
+// be resumed was stopped by an API shutdown, the cluster status is kept
+func HandleOperationAbandoned(clusterName, condition string) error {
+	kcfg := utils.GetKubernetesClient(clusterName)
+	if kcfg == nil {
+		return fmt.Errorf("failed to create kubernetes client for cluster %q", clusterName)
+	}
+
+	cl, err := secrets.GetCluster(kcfg.Clientset, clusterName)
+	if err != nil {
+		return fmt.Errorf("failed to get cluster %q: %w", clusterName, err)
+	}
+
+	cl.InProgress = false
+	cl.LastCondition = condition
+	cl.LastErrorCode = string(apierror.CodeInterrupted)
+
+	if err := secrets.UpdateCluster(kcfg.Clientset, *cl); err != nil {
+		return fmt.Errorf("failed to update cluster %q: %w", clusterName, err)
+	}
+
+	return nil
+}
+
+// CheckpointDelete is the step boundary of a cluster delete. Once the API is
+// shutting down the cluster record is checkpointed as interrupted before step
+// and an error is returned, retrying the delete resumes from this step.
+func CheckpointDelete(cl *pkgtypes.Cluster, step string) error {
+	if !shutdown.Draining() {
+		return nil
+	}
+
+	condition := fmt.Sprintf("%s before step %s, retry the operation to resume", shutdown.ErrInterrupted, step)
+	log.Warn().Msgf("cluster %s: %s", cl.ClusterName, condition)
+
+	markInterrupted(cl, metrics.OperationDelete, condition)
+
+	kcfg := utils.GetKubernetesClient(cl.ClusterName)
+	if err := secrets.UpdateCluster(kcfg.Clientset, *cl); err != nil {
+		return fmt.Errorf("error checkpointing interrupted cluster: %w", err)
+	}
+
+	return fmt.Errorf("step %s: %w", step, shutdown.ErrInterrupted)
+}
+
+// markInterrupted sets the interrupted status of a cluster record
+func markInterrupted(cl *pkgtypes.Cluster, operation, condition string) {
+	cl.InProgress = false
+	cl.Status = constants.ClusterStatusInterrupted
+	cl.LastCondition = condition
+	cl.LastErrorCode = string(apierror.CodeInterrupted)
+	cl.InterruptedOperation = operation
+}
@@ -13,7 +13,6 @@ import (
 	"github.com/go-git/go-git/v5"
 	pkg "github.com/konstructio/kubefirst-api/internal"
 	"github.com/rs/zerolog/log"
-	"github.com/spf13/viper"
 )
 
 // AppendFile verify if a file must be appended to committed gitops
@@ -47,7 +46,7 @@ func AppendFile(cloudType string, reponame string, filename string) bool {
 
 // GitAddWithFilter Check workdir for files to commit
 // filter out the undersired ones based on context
-func GitAddWithFilter(w *git.Worktree) error {
+func GitAddWithFilter(cloudProvider string, w *git.Worktree) error {
 	status, err := w.Status()
 	if err != nil {
 		log.Debug().Msgf("error getting worktree status: %s", err)
@@ -56,7 +55,7 @@ func GitAddWithFilter(w *git.Worktree) error {
 
 	for file, s := range status {
 		log.Printf("the file is %s the status is %v", file, s.Worktree)
-		if AppendFile(viper.GetString("cloud"), "gitops", file) {
+		if AppendFile(cloudProvider, "gitops", file) {
 			_, err = w.Add(file)
 			if err != nil {
 				log.Error().Err(err).Msgf("error getting worktree status: %s", err)
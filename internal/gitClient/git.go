@@ -44,7 +44,9 @@ func Clone(gitRef, repoLocalPath, repoURL string) (*git.Repository, error) {
 	return repo, nil
 }
 
-func ClonePrivateRepo(gitRef, repoLocalPath, repoURL, userName, token string) (*git.Repository, error) {
+// ClonePrivateRepo clones a repository with basic auth. caBundle may hold
+// additional PEM encoded certificates to trust for self-hosted git servers.
+func ClonePrivateRepo(gitRef, repoLocalPath, repoURL, userName, token string, caBundle []byte) (*git.Repository, error) {
 	// kubefirst tags do not contain a `v` prefix, to use the library requires the v to be valid
 	isSemVer := semver.IsValid(gitRef)
 
@@ -64,6 +66,7 @@ func ClonePrivateRepo(gitRef, repoLocalPath, repoURL, userName, token string) (*
 			Username: userName,
 			Password: token,
 		},
+		CABundle: caBundle,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("error cloning private repository from URL %q: %w", repoURL, err)
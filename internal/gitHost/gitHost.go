@@ -0,0 +1,265 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package gitHost //nolint:revive,stylecheck // matches existing package naming
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
+	log "github.com/rs/zerolog/log"
+)
+
+const (
+	GitHubHost   = "github.com"
+	GitLabHost   = "gitlab.com"
+	GitHubAPIURL = "https://api.github.com"
+	GitLabAPIURL = "https://gitlab.com/api/v4"
+
+	GitHubContainerRegistryHost = "ghcr.io"
+	GitLabContainerRegistryHost = "registry.gitlab.com"
+
+	caBundleFileName = "git-ca-bundle.pem"
+)
+
+// Validate checks that a self-hosted git server definition is usable for the
+// git provider. Gitea and Forgejo have no public default and need a base url.
+func Validate(gitProvider string, server pkgtypes.GitServer) error {
+	if gitProvider == "gitea" && server.BaseURL == "" {
+		return errors.New("git server base_url is required for the gitea git provider")
+	}
+
+	for name, value := range map[string]string{"base_url": server.BaseURL, "api_url": server.APIURL} {
+		if value == "" {
+			continue
+		}
+		u, err := url.Parse(value)
+		if err != nil {
+			return fmt.Errorf("invalid git server %s %q: %w", name, value, err)
+		}
+		if u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("invalid git server %s %q: must be an https url", name, value)
+		}
+	}
+
+	if server.APIURL != "" && server.BaseURL == "" {
+		return errors.New("git server api_url requires base_url to be set")
+	}
+
+	if server.CABundle != "" {
+		if ok := x509.NewCertPool().AppendCertsFromPEM([]byte(server.CABundle)); !ok {
+			return errors.New("git server ca_bundle does not contain any valid PEM certificates")
+		}
+	}
+
+	return nil
+}
+
+// IsSelfHosted reports whether the cluster uses a git server other than
+// github.com or gitlab.com
+func IsSelfHosted(server pkgtypes.GitServer) bool {
+	return server.BaseURL != ""
+}
+
+// Host returns the host (and optional path prefix) used to build repository
+// urls such as https://<host>/<owner>/gitops.git
+func Host(gitProvider string, server pkgtypes.GitServer) string {
+	if server.BaseURL != "" {
+		u, err := url.Parse(server.BaseURL)
+		if err == nil && u.Host != "" {
+			return u.Host + strings.TrimSuffix(u.Path, "/")
+		}
+	}
+
+	switch gitProvider {
+	case "gitlab":
+		return GitLabHost
+	default:
+		return GitHubHost
+	}
+}
+
+// Hostname returns the host without any path prefix or port, as needed for ssh
+// known hosts and container registry names
+func Hostname(gitProvider string, server pkgtypes.GitServer) string {
+	host := strings.SplitN(Host(gitProvider, server), "/", 2)[0]
+	if h, _, found := strings.Cut(host, ":"); found {
+		return h
+	}
+	return host
+}
+
+// BaseURL returns the web url of the git server without a trailing slash
+func BaseURL(gitProvider string, server pkgtypes.GitServer) string {
+	return "https://" + Host(gitProvider, server)
+}
+
+// APIURL returns the REST api url of the git server. When only a base url is
+// configured the api location is derived from the provider defaults:
+// <base>/api/v3 for GitHub Enterprise, <base>/api/v4 for GitLab and
+// <base>/api/v1 for Gitea.
+func APIURL(gitProvider string, server pkgtypes.GitServer) string {
+	if server.APIURL != "" {
+		return strings.TrimSuffix(server.APIURL, "/")
+	}
+
+	switch gitProvider {
+	case "gitea":
+		return BaseURL(gitProvider, server) + "/api/v1"
+	case "gitlab":
+		if IsSelfHosted(server) {
+			return BaseURL(gitProvider, server) + "/api/v4"
+		}
+		return GitLabAPIURL
+	default:
+		if IsSelfHosted(server) {
+			return BaseURL(gitProvider, server) + "/api/v3"
+		}
+		return GitHubAPIURL
+	}
+}
+
+// ContainerRegistryHost returns the registry that hosts images built from the
+// gitops and metaphor repositories
+func ContainerRegistryHost(gitProvider string, server pkgtypes.GitServer) string {
+	switch gitProvider {
+	case "gitea":
+		// Gitea serves its container registry from the main host
+		return Hostname(gitProvider, server)
+	case "gitlab":
+		if IsSelfHosted(server) {
+			return "registry." + Hostname(gitProvider, server)
+		}
+		return GitLabContainerRegistryHost
+	default:
+		if IsSelfHosted(server) {
+			return "containers." + Hostname(gitProvider, server)
+		}
+		return GitHubContainerRegistryHost
+	}
+}
+
+// CABundle returns the configured CA bundle, or nil to use the system roots
+func CABundle(server pkgtypes.GitServer) []byte {
+	if server.CABundle == "" {
+		return nil
+	}
+	return []byte(server.CABundle)
+}
+
+// TLSConfig returns a tls configuration trusting the system roots plus the
+// configured CA bundle
+func TLSConfig(server pkgtypes.GitServer) (*tls.Config, error) {
+	if server.CABundle == "" {
+		return &tls.Config{MinVersion: tls.VersionTLS12}, nil
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if ok := pool.AppendCertsFromPEM([]byte(server.CABundle)); !ok {
+		return nil, errors.New("git server ca_bundle does not contain any valid PEM certificates")
+	}
+
+	return &tls.Config{MinVersion: tls.VersionTLS12, RootCAs: pool}, nil
+}
+
+// HTTPClient returns an http client for calling the git server api
+func HTTPClient(server pkgtypes.GitServer) (*http.Client, error) {
+	tlsConfig, err := TLSConfig(server)
+	if err != nil {
+		return nil, err
+	}
+
+	//nolint:forcetypeassert // we are cloning the default transport
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   90 * time.Second,
+	}, nil
+}
+
+// WriteCABundle writes the configured CA bundle to dir so it can be handed to
+// external tools. The bundle exported in SSL_CERT_FILE for outbound
+// connections is kept ahead of it, as external tools only read one file. It
+// returns an empty path when no bundle is configured.
+func WriteCABundle(server pkgtypes.GitServer, dir string) (string, error) {
+	if server.CABundle == "" {
+		return "", nil
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("unable to create directory %q: %w", dir, err)
+	}
+
+	var content []byte
+	if outbound := os.Getenv("SSL_CERT_FILE"); outbound != "" {
+		existing, err := os.ReadFile(outbound)
+		if err != nil {
+			return "", fmt.Errorf("unable to read outbound ca bundle %q: %w", outbound, err)
+		}
+		content = append(existing, '\n')
+	}
+	content = append(content, server.CABundle...)
+
+	path := filepath.Join(dir, caBundleFileName)
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		return "", fmt.Errorf("unable to write git server ca bundle to %q: %w", path, err)
+	}
+
+	return path, nil
+}
+
+// TerraformEnvs adds the environment needed by the github and gitlab terraform
+// providers to reach a self-hosted git server. The CA bundle is written to the
+// cluster's k1 directory together with the outbound one and exposed through
+// SSL_CERT_FILE; the system certificate directories are still trusted
+// alongside it.
+func TerraformEnvs(envs map[string]string, cl *pkgtypes.Cluster) map[string]string {
+	if envs == nil {
+		envs = make(map[string]string)
+	}
+
+	if !IsSelfHosted(cl.GitServer) {
+		return envs
+	}
+
+	switch cl.GitProvider {
+	case "github":
+		envs["GITHUB_BASE_URL"] = APIURL(cl.GitProvider, cl.GitServer) + "/"
+	case "gitlab":
+		envs["GITLAB_BASE_URL"] = APIURL(cl.GitProvider, cl.GitServer) + "/"
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		log.Error().Msgf("unable to get home directory to write git server ca bundle: %s", err)
+		return envs
+	}
+
+	caBundlePath, err := WriteCABundle(cl.GitServer, filepath.Join(homeDir, ".k1", cl.ClusterName))
+	if err != nil {
+		log.Error().Msgf("%s", err)
+		return envs
+	}
+	if caBundlePath != "" {
+		envs["SSL_CERT_FILE"] = caBundlePath
+	}
+
+	return envs
+}
@@ -0,0 +1,123 @@
+package gitHost //nolint:revive,stylecheck // matches existing package naming
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
+)
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name         string
+		provider     string
+		server       pkgtypes.GitServer
+		wantHost     string
+		wantAPIURL   string
+		wantRegistry string
+	}{
+		{
+			name:         "github.com",
+			provider:     "github",
+			wantHost:     "github.com",
+			wantAPIURL:   "https://api.github.com",
+			wantRegistry: "ghcr.io",
+		},
+		{
+			name:         "gitlab.com",
+			provider:     "gitlab",
+			wantHost:     "gitlab.com",
+			wantAPIURL:   "https://gitlab.com/api/v4",
+			wantRegistry: "registry.gitlab.com",
+		},
+		{
+			name:         "github enterprise",
+			provider:     "github",
+			server:       pkgtypes.GitServer{BaseURL: "https://github.example.com/"},
+			wantHost:     "github.example.com",
+			wantAPIURL:   "https://github.example.com/api/v3",
+			wantRegistry: "containers.github.example.com",
+		},
+		{
+			name:         "gitlab self-managed under a path with explicit api url",
+			provider:     "gitlab",
+			server:       pkgtypes.GitServer{BaseURL: "https://git.example.com:8443/gitlab", APIURL: "https://gitlab-api.example.com/api/v4/"},
+			wantHost:     "git.example.com:8443/gitlab",
+			wantAPIURL:   "https://gitlab-api.example.com/api/v4",
+			wantRegistry: "registry.git.example.com",
+		},
+		{
+			name:         "gitea",
+			provider:     "gitea",
+			server:       pkgtypes.GitServer{BaseURL: "https://gitea.example.com"},
+			wantHost:     "gitea.example.com",
+			wantAPIURL:   "https://gitea.example.com/api/v1",
+			wantRegistry: "gitea.example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Host(tt.provider, tt.server); got != tt.wantHost {
+				t.Errorf("expected host %q, got %q", tt.wantHost, got)
+			}
+			if got := APIURL(tt.provider, tt.server); got != tt.wantAPIURL {
+				t.Errorf("expected api url %q, got %q", tt.wantAPIURL, got)
+			}
+			if got := ContainerRegistryHost(tt.provider, tt.server); got != tt.wantRegistry {
+				t.Errorf("expected registry %q, got %q", tt.wantRegistry, got)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		server   pkgtypes.GitServer
+		wantErr  bool
+	}{
+		{name: "empty", provider: "github", server: pkgtypes.GitServer{}},
+		{name: "https base url", provider: "gitlab", server: pkgtypes.GitServer{BaseURL: "https://gitlab.example.com"}},
+		{name: "http base url", provider: "gitlab", server: pkgtypes.GitServer{BaseURL: "http://gitlab.example.com"}, wantErr: true},
+		{name: "api url without base url", provider: "gitlab", server: pkgtypes.GitServer{APIURL: "https://gitlab.example.com/api/v4"}, wantErr: true},
+		{name: "invalid ca bundle", provider: "gitlab", server: pkgtypes.GitServer{BaseURL: "https://gitlab.example.com", CABundle: "not a certificate"}, wantErr: true},
+		{name: "gitea without base url", provider: "gitea", server: pkgtypes.GitServer{}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.provider, tt.server)
+			if tt.wantErr && err == nil {
+				t.Fatal("expected error")
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestWriteCABundle(t *testing.T) {
+	dir := t.TempDir()
+	outbound := filepath.Join(dir, "outbound.pem")
+	if err := os.WriteFile(outbound, []byte("outbound"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SSL_CERT_FILE", outbound)
+
+	path, err := WriteCABundle(pkgtypes.GitServer{CABundle: "git"}, filepath.Join(dir, "cluster"))
+	if err != nil {
+		t.Fatalf("WriteCABundle() error = %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(content), "outbound\ngit"; got != want {
+		t.Errorf("WriteCABundle() wrote %q, want %q", got, want)
+	}
+}
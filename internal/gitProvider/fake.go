@@ -0,0 +1,245 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package gitProvider //nolint:revive,stylecheck // matches existing package naming
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Fake is an in-memory GitProvider for tests
+type Fake struct {
+	mu sync.Mutex
+
+	ProviderName string
+	OwnerName    string
+	GroupID      int
+	User         string
+	Token        string
+
+	// Entrypoint is returned by TerraformEntrypoint, leave it empty to have
+	// resources created through CreateResources
+	Entrypoint string
+	// UserSSHKey is returned by ManagesUserSSHKey
+	UserSSHKey bool
+
+	Repositories map[string]bool
+	Teams        map[string]bool
+	SSHKeys      []SSHKey
+	// Webhooks maps a repository to its webhook urls
+	Webhooks map[string][]string
+	// PullRequests records every pull request as repo:head->base
+	PullRequests []string
+	// Registries maps a repository to its number of container registry repositories
+	Registries map[string]int
+
+	// Err, when set, is returned by every call
+	Err error
+
+	nextKeyID int64
+}
+
+// NewFake returns an empty Fake for the named provider
+func NewFake(name, owner string) *Fake {
+	return &Fake{
+		ProviderName: name,
+		OwnerName:    owner,
+		User:         "kbot",
+		Token:        "fake-token",
+		Repositories: map[string]bool{},
+		Teams:        map[string]bool{},
+		Webhooks:     map[string][]string{},
+		Registries:   map[string]int{},
+	}
+}
+
+func (f *Fake) Name() string {
+	return f.ProviderName
+}
+
+func (f *Fake) Owner() string {
+	return f.OwnerName
+}
+
+func (f *Fake) OwnerID() int {
+	return f.GroupID
+}
+
+func (f *Fake) VerifyTokenPermissions() error {
+	return f.Err
+}
+
+func (f *Fake) GetAuthenticatedUser() (string, error) {
+	if f.Err != nil {
+		return "", f.Err
+	}
+	return f.User, nil
+}
+
+func (f *Fake) CheckRepoExists(name string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.Repositories[name], f.Err
+}
+
+func (f *Fake) DeleteRepo(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return f.Err
+	}
+	if !f.Repositories[name] {
+		return fmt.Errorf("repository %s/%s not found", f.OwnerName, name)
+	}
+	if f.Registries[name] > 0 {
+		return fmt.Errorf("repository %s/%s has container registry repositories", f.OwnerName, name)
+	}
+	delete(f.Repositories, name)
+	delete(f.Webhooks, name)
+	return nil
+}
+
+func (f *Fake) CheckTeamExists(name string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.Teams[name], f.Err
+}
+
+func (f *Fake) DeleteTeam(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return f.Err
+	}
+	if !f.Teams[name] {
+		return fmt.Errorf("team %s/%s not found", f.OwnerName, name)
+	}
+	delete(f.Teams, name)
+	return nil
+}
+
+func (f *Fake) ListSSHKeys() ([]SSHKey, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	return append([]SSHKey(nil), f.SSHKeys...), nil
+}
+
+func (f *Fake) AddSSHKey(title, publicKey string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return f.Err
+	}
+	f.nextKeyID++
+	f.SSHKeys = append(f.SSHKeys, SSHKey{ID: f.nextKeyID, Title: title, Key: publicKey})
+	return nil
+}
+
+func (f *Fake) DeleteSSHKey(title string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return f.Err
+	}
+	keys := f.SSHKeys[:0]
+	for _, key := range f.SSHKeys {
+		if key.Title != title {
+			keys = append(keys, key)
+		}
+	}
+	f.SSHKeys = keys
+	return nil
+}
+
+func (f *Fake) CreateWebhook(repo, url, _ string, _ []string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return f.Err
+	}
+	f.Webhooks[repo] = append(f.Webhooks[repo], url)
+	return nil
+}
+
+func (f *Fake) DeleteWebhook(repo, url string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return f.Err
+	}
+	for i, hook := range f.Webhooks[repo] {
+		if hook == url {
+			f.Webhooks[repo] = append(f.Webhooks[repo][:i], f.Webhooks[repo][i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("hook %s/%s/%s not found", f.OwnerName, repo, url)
+}
+
+func (f *Fake) CreatePullRequest(repo, head, base, _, _ string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return "", f.Err
+	}
+	f.PullRequests = append(f.PullRequests, fmt.Sprintf("%s:%s->%s", repo, head, base))
+	return fmt.Sprintf("https://git.example.com/%s/%s/pulls/%d", f.OwnerName, repo, len(f.PullRequests)), nil
+}
+
+func (f *Fake) ContainerRegistryToken(string) (string, error) {
+	if f.Err != nil {
+		return "", f.Err
+	}
+	return f.Token, nil
+}
+
+func (f *Fake) DeleteContainerRegistryRepositories(repo string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return f.Err
+	}
+	delete(f.Registries, repo)
+	return nil
+}
+
+func (f *Fake) TerraformEntrypoint() string {
+	return f.Entrypoint
+}
+
+func (f *Fake) TerraformEnvs(envs map[string]string) map[string]string {
+	prefix := strings.ToUpper(f.ProviderName)
+	envs[prefix+"_TOKEN"] = f.Token
+	envs[prefix+"_OWNER"] = f.OwnerName
+	return envs
+}
+
+func (f *Fake) CreateResources(p ResourceParameters) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return f.Err
+	}
+	for _, repo := range p.Repositories {
+		f.Repositories[repo] = true
+		if p.AtlantisWebhookURL != "" {
+			f.Webhooks[repo] = append(f.Webhooks[repo], p.AtlantisWebhookURL)
+		}
+	}
+	for _, team := range p.Teams {
+		f.Teams[team] = true
+	}
+	return nil
+}
+
+func (f *Fake) ManagesUserSSHKey() bool {
+	return f.UserSSHKey
+}
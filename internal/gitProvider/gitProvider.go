@@ -0,0 +1,235 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package gitProvider //nolint:revive,stylecheck // matches existing package naming
+
+import (
+	"fmt"
+	"strings"
+
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
+	log "github.com/rs/zerolog/log"
+)
+
+// KbotSSHKeyTitle is the title of the ssh key kubefirst manages for kbot
+const KbotSSHKeyTitle = "kbot-ssh-key"
+
+// Webhook events understood by every provider. Providers translate them to
+// their own event names.
+const (
+	EventPush         = "push"
+	EventPullRequest  = "pull_request"
+	EventIssueComment = "issue_comment"
+)
+
+// SSHKey is a user ssh key registered with a git provider
+type SSHKey struct {
+	ID    int64
+	Title string
+	Key   string
+}
+
+// GitProvider is the set of git hosting operations kubefirst performs against
+// the organization or group owning a cluster's repositories. Teams map to
+// GitLab subgroups.
+type GitProvider interface {
+	// Name returns the provider name as stored on the cluster record
+	Name() string
+	// Owner returns the full path of the organization or group
+	Owner() string
+	// OwnerID returns the numeric id of the group, or 0 for providers that
+	// address owners by name only
+	OwnerID() int
+
+	VerifyTokenPermissions() error
+	GetAuthenticatedUser() (string, error)
+
+	CheckRepoExists(name string) (bool, error)
+	DeleteRepo(name string) error
+
+	CheckTeamExists(name string) (bool, error)
+	DeleteTeam(name string) error
+
+	ListSSHKeys() ([]SSHKey, error)
+	AddSSHKey(title, publicKey string) error
+	DeleteSSHKey(title string) error
+
+	CreateWebhook(repo, url, secret string, events []string) error
+	DeleteWebhook(repo, url string) error
+
+	// CreatePullRequest opens a pull or merge request and returns its web url
+	CreatePullRequest(repo, head, base, title, body string) (string, error)
+
+	// ContainerRegistryToken returns a credential able to push to and pull
+	// from the provider container registry
+	ContainerRegistryToken(name string) (string, error)
+	// DeleteContainerRegistryRepositories removes images stored alongside a
+	// repository that would otherwise block deleting it
+	DeleteContainerRegistryRepositories(repo string) error
+
+	// TerraformEntrypoint returns the gitops repository directory of the
+	// terraform creating repositories and teams, or "" when they are created
+	// through the provider api with CreateResources
+	TerraformEntrypoint() string
+	// TerraformEnvs adds the provider credentials the git terraform reads to envs
+	TerraformEnvs(envs map[string]string) map[string]string
+	// CreateResources creates the repositories, teams, atlantis webhooks and
+	// kbot deploy key of providers without a terraform entrypoint
+	CreateResources(p ResourceParameters) error
+	// ManagesUserSSHKey reports whether the kbot ssh key is registered on the
+	// token user by kubefirst rather than created alongside the repositories
+	ManagesUserSSHKey() bool
+}
+
+// ResourceParameters are the resources created for a new cluster
+type ResourceParameters struct {
+	Repositories          []string
+	Teams                 []string
+	AtlantisWebhookURL    string
+	AtlantisWebhookSecret string
+	KbotSSHPublicKey      string
+}
+
+// New returns the GitProvider implementation for gitProvider. owner is the
+// organization, or for GitLab the group path, under which resources are managed.
+func New(gitProvider, token, owner string, server pkgtypes.GitServer) (GitProvider, error) {
+	var (
+		p   GitProvider
+		err error
+	)
+
+	switch gitProvider {
+	case "github":
+		p, err = NewGitHub(token, owner, server)
+	case "gitlab":
+		p, err = NewGitLab(token, owner, server)
+	case "gitea":
+		p, err = NewGitea(token, owner, server)
+	default:
+		return nil, fmt.Errorf("invalid git provider option: %q", gitProvider)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// ForCluster returns the GitProvider of an existing cluster
+func ForCluster(cl *pkgtypes.Cluster) (GitProvider, error) {
+	return New(cl.GitProvider, cl.GitAuth.Token, cl.GitAuth.Owner, cl.GitServer)
+}
+
+// ManagedRepositories returns the repositories kubefirst creates for a cluster
+func ManagedRepositories() []string {
+	return []string{"gitops", "metaphor"}
+}
+
+// ManagedTeams returns the teams kubefirst creates for a cluster
+func ManagedTeams() []string {
+	return []string{"admins", "developers"}
+}
+
+// CheckResourcesAvailable returns an error listing any repositories or teams
+// that already exist and would be overwritten by a new installation
+func CheckResourcesAvailable(p GitProvider, repositories, teams []string) error {
+	existing := []string{}
+
+	for _, repo := range repositories {
+		exists, err := p.CheckRepoExists(repo)
+		if err != nil {
+			return fmt.Errorf("error checking for repository %s/%s: %w", p.Owner(), repo, err)
+		}
+		if exists {
+			log.Info().Msgf("repository %s/%s exists", p.Owner(), repo)
+			existing = append(existing, fmt.Sprintf("repository %s/%s", p.Owner(), repo))
+		}
+	}
+
+	for _, team := range teams {
+		exists, err := p.CheckTeamExists(team)
+		if err != nil {
+			return fmt.Errorf("error checking for team %s/%s: %w", p.Owner(), team, err)
+		}
+		if exists {
+			log.Info().Msgf("team %s/%s exists", p.Owner(), team)
+			existing = append(existing, fmt.Sprintf("team %s/%s", p.Owner(), team))
+		}
+	}
+
+	if len(existing) > 0 {
+		return fmt.Errorf("the following must be removed before continuing with your kubefirst installation: %s", strings.Join(existing, ", "))
+	}
+
+	return nil
+}
+
+// DeleteResources removes repositories and teams, skipping any that do not exist
+func DeleteResources(p GitProvider, repositories, teams []string) error {
+	for _, repo := range repositories {
+		exists, err := p.CheckRepoExists(repo)
+		if err != nil {
+			return fmt.Errorf("error checking for repository %s/%s: %w", p.Owner(), repo, err)
+		}
+		if !exists {
+			continue
+		}
+		if err := p.DeleteRepo(repo); err != nil {
+			return fmt.Errorf("error deleting repository %s/%s: %w", p.Owner(), repo, err)
+		}
+	}
+
+	for _, team := range teams {
+		exists, err := p.CheckTeamExists(team)
+		if err != nil {
+			return fmt.Errorf("error checking for team %s/%s: %w", p.Owner(), team, err)
+		}
+		if !exists {
+			continue
+		}
+		if err := p.DeleteTeam(team); err != nil {
+			return fmt.Errorf("error deleting team %s/%s: %w", p.Owner(), team, err)
+		}
+	}
+
+	return nil
+}
+
+// EnsureSSHKey adds publicKey under title unless an identical key is already
+// registered. A key with the same title and different data is replaced when
+// replaceDrifted is set and reported as an error otherwise.
+func EnsureSSHKey(p GitProvider, title, publicKey string, replaceDrifted bool) error {
+	keys, err := p.ListSSHKeys()
+	if err != nil {
+		return fmt.Errorf("error checking for ssh keys in %s: %w", p.Name(), err)
+	}
+
+	publicKey = strings.TrimSuffix(publicKey, "\n")
+	for _, key := range keys {
+		if key.Title != title {
+			continue
+		}
+		if strings.Contains(key.Key, publicKey) {
+			log.Info().Msgf("ssh key %s already exists and key is up to date, continuing", title)
+			return nil
+		}
+		if !replaceDrifted {
+			return fmt.Errorf("ssh key %s already exists and key data has drifted - please remove before continuing", title)
+		}
+		log.Warn().Msgf("ssh key %s already exists and key data has drifted - it will be recreated", title)
+		if err := p.DeleteSSHKey(title); err != nil {
+			return fmt.Errorf("error deleting %s user ssh key %q: %w", p.Name(), title, err)
+		}
+		break
+	}
+
+	log.Info().Msgf("creating ssh key %s...", title)
+	if err := p.AddSSHKey(title, publicKey); err != nil {
+		return fmt.Errorf("error adding ssh key %s: %w", title, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package gitProvider //nolint:revive,stylecheck // matches existing package naming
+
+import (
+	"testing"
+)
+
+var (
+	_ GitProvider = (*GitHub)(nil)
+	_ GitProvider = (*GitLab)(nil)
+	_ GitProvider = (*Gitea)(nil)
+	_ GitProvider = (*Fake)(nil)
+)
+
+func TestCheckResourcesAvailable(t *testing.T) {
+	tests := []struct {
+		name    string
+		repos   []string
+		teams   []string
+		wantErr bool
+	}{
+		{name: "empty owner"},
+		{name: "existing repository", repos: []string{"gitops"}, wantErr: true},
+		{name: "existing team", teams: []string{"admins"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewFake("github", "kubefirst")
+			for _, repo := range tt.repos {
+				f.Repositories[repo] = true
+			}
+			for _, team := range tt.teams {
+				f.Teams[team] = true
+			}
+
+			err := CheckResourcesAvailable(f, []string{"gitops", "metaphor"}, []string{"admins", "developers"})
+			if tt.wantErr && err == nil {
+				t.Fatal("expected error")
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestDeleteResources(t *testing.T) {
+	f := NewFake("gitea", "kubefirst")
+	f.Repositories["gitops"] = true
+	f.Teams["admins"] = true
+
+	// missing resources are skipped
+	err := DeleteResources(f, []string{"gitops", "metaphor"}, []string{"admins", "developers"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(f.Repositories) != 0 || len(f.Teams) != 0 {
+		t.Errorf("expected all resources to be deleted, got repositories %v and teams %v", f.Repositories, f.Teams)
+	}
+}
+
+func TestEnsureSSHKey(t *testing.T) {
+	const publicKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIKbot\n"
+
+	tests := []struct {
+		name           string
+		existing       []SSHKey
+		replaceDrifted bool
+		wantErr        bool
+		wantKeys       int
+	}{
+		{name: "no key", wantKeys: 1},
+		{name: "up to date key", existing: []SSHKey{{ID: 1, Title: KbotSSHKeyTitle, Key: "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIKbot"}}, wantKeys: 1},
+		{name: "drifted key", existing: []SSHKey{{ID: 1, Title: KbotSSHKeyTitle, Key: "ssh-ed25519 other"}}, wantErr: true, wantKeys: 1},
+		{name: "drifted key replaced", existing: []SSHKey{{ID: 1, Title: KbotSSHKeyTitle, Key: "ssh-ed25519 other"}}, replaceDrifted: true, wantKeys: 1},
+		{name: "unrelated key", existing: []SSHKey{{ID: 1, Title: "laptop", Key: "ssh-ed25519 other"}}, wantKeys: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewFake("gitlab", "kubefirst")
+			f.SSHKeys = tt.existing
+
+			err := EnsureSSHKey(f, KbotSSHKeyTitle, publicKey, tt.replaceDrifted)
+			if tt.wantErr && err == nil {
+				t.Fatal("expected error")
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if len(f.SSHKeys) != tt.wantKeys {
+				t.Errorf("expected %d keys, got %d", tt.wantKeys, len(f.SSHKeys))
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package gitProvider //nolint:revive,stylecheck // matches existing package naming
+
+import (
+	"fmt"
+
+	"github.com/konstructio/kubefirst-api/internal/gitea"
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
+)
+
+// Gitea implements GitProvider for Gitea and Forgejo
+type Gitea struct {
+	Client *gitea.Wrapper
+}
+
+// NewGitea returns a Gitea provider managing resources in the owner organization
+func NewGitea(token, owner string, server pkgtypes.GitServer) (*Gitea, error) {
+	client, err := gitea.NewGiteaClient(token, owner, server)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create gitea client: %w", err)
+	}
+
+	return &Gitea{Client: client}, nil
+}
+
+func (g *Gitea) Name() string {
+	return "gitea"
+}
+
+func (g *Gitea) Owner() string {
+	return g.Client.Owner
+}
+
+func (g *Gitea) OwnerID() int {
+	return 0
+}
+
+func (g *Gitea) VerifyTokenPermissions() error {
+	return g.Client.VerifyTokenPermissions()
+}
+
+func (g *Gitea) GetAuthenticatedUser() (string, error) {
+	return g.Client.GetAuthenticatedUser()
+}
+
+func (g *Gitea) CheckRepoExists(name string) (bool, error) {
+	return g.Client.CheckRepoExists(name)
+}
+
+func (g *Gitea) DeleteRepo(name string) error {
+	return g.Client.DeleteRepo(name)
+}
+
+func (g *Gitea) CheckTeamExists(name string) (bool, error) {
+	team, err := g.Client.GetTeam(name)
+	if err != nil {
+		return false, err
+	}
+	return team != nil, nil
+}
+
+func (g *Gitea) DeleteTeam(name string) error {
+	return g.Client.DeleteTeam(name)
+}
+
+func (g *Gitea) ListSSHKeys() ([]SSHKey, error) {
+	keys, err := g.Client.ListUserSSHKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]SSHKey, 0, len(keys))
+	for _, key := range keys {
+		res = append(res, SSHKey{ID: key.ID, Title: key.Title, Key: key.Key})
+	}
+	return res, nil
+}
+
+func (g *Gitea) AddSSHKey(title, publicKey string) error {
+	return g.Client.AddUserSSHKey(title, publicKey)
+}
+
+func (g *Gitea) DeleteSSHKey(title string) error {
+	return g.Client.DeleteUserSSHKey(title)
+}
+
+func (g *Gitea) CreateWebhook(repo, url, secret string, events []string) error {
+	return g.Client.CreateWebhookRepo(repo, url, secret, events)
+}
+
+func (g *Gitea) DeleteWebhook(repo, url string) error {
+	return g.Client.DeleteRepositoryWebhook(repo, url)
+}
+
+func (g *Gitea) CreatePullRequest(repo, head, base, title, body string) (string, error) {
+	return g.Client.CreatePullRequest(repo, head, base, title, body)
+}
+
+// ContainerRegistryToken returns the provided token, which the gitea package
+// registry accepts
+func (g *Gitea) ContainerRegistryToken(string) (string, error) {
+	return g.Client.Token, nil
+}
+
+// DeleteContainerRegistryRepositories is a no-op since gitea packages belong
+// to the organization and do not block deleting a repository
+func (g *Gitea) DeleteContainerRegistryRepositories(string) error {
+	return nil
+}
+
+// TerraformEntrypoint is empty since the gitops template has no gitea
+// terraform, resources are created with CreateResources instead
+func (g *Gitea) TerraformEntrypoint() string {
+	return ""
+}
+
+func (g *Gitea) TerraformEnvs(envs map[string]string) map[string]string {
+	return envs
+}
+
+func (g *Gitea) CreateResources(p ResourceParameters) error {
+	return g.Client.CreateResources(&gitea.ResourceParameters{
+		Repositories:          p.Repositories,
+		Teams:                 p.Teams,
+		AtlantisWebhookURL:    p.AtlantisWebhookURL,
+		AtlantisWebhookSecret: p.AtlantisWebhookSecret,
+		KbotSSHPublicKey:      p.KbotSSHPublicKey,
+	})
+}
+
+func (g *Gitea) ManagesUserSSHKey() bool {
+	return false
+}
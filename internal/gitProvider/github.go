@@ -0,0 +1,166 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package gitProvider //nolint:revive,stylecheck // matches existing package naming
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/konstructio/kubefirst-api/internal/github"
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
+)
+
+// GitHub implements GitProvider for github.com and GitHub Enterprise
+type GitHub struct {
+	Session github.Session
+
+	token  string
+	owner  string
+	server pkgtypes.GitServer
+}
+
+// NewGitHub returns a GitHub provider managing resources in the owner organization
+func NewGitHub(token, owner string, server pkgtypes.GitServer) (*GitHub, error) {
+	session, err := github.New(token, server)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create github client: %w", err)
+	}
+
+	return &GitHub{Session: session, token: token, owner: owner, server: server}, nil
+}
+
+func (g *GitHub) Name() string {
+	return "github"
+}
+
+func (g *GitHub) Owner() string {
+	return g.owner
+}
+
+func (g *GitHub) OwnerID() int {
+	return 0
+}
+
+func (g *GitHub) VerifyTokenPermissions() error {
+	return github.VerifyTokenPermissions(g.token, g.server)
+}
+
+func (g *GitHub) GetAuthenticatedUser() (string, error) {
+	return g.Session.GetAuthenticatedUser()
+}
+
+func (g *GitHub) CheckRepoExists(name string) (bool, error) {
+	return statusExists(g.Session.CheckRepoExists(g.owner, name))
+}
+
+func (g *GitHub) DeleteRepo(name string) error {
+	_, err := g.Session.RemoveRepo(g.owner, name)
+	return err
+}
+
+func (g *GitHub) CheckTeamExists(name string) (bool, error) {
+	return statusExists(g.Session.CheckTeamExists(g.owner, name))
+}
+
+func (g *GitHub) DeleteTeam(name string) error {
+	return g.Session.RemoveTeam(g.owner, name)
+}
+
+func (g *GitHub) ListSSHKeys() ([]SSHKey, error) {
+	keys, err := g.Session.ListSSHKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]SSHKey, 0, len(keys))
+	for _, key := range keys {
+		res = append(res, SSHKey{ID: key.GetID(), Title: key.GetTitle(), Key: key.GetKey()})
+	}
+	return res, nil
+}
+
+func (g *GitHub) AddSSHKey(title, publicKey string) error {
+	_, err := g.Session.AddSSHKey(title, publicKey)
+	return err
+}
+
+func (g *GitHub) DeleteSSHKey(title string) error {
+	keys, err := g.ListSSHKeys()
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if key.Title == title {
+			if err := g.Session.RemoveSSHKey(key.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (g *GitHub) CreateWebhook(repo, url, secret string, events []string) error {
+	return g.Session.CreateWebhookRepo(g.owner, repo, "web", url, secret, events)
+}
+
+func (g *GitHub) DeleteWebhook(repo, url string) error {
+	return g.Session.DeleteRepositoryWebhook(g.owner, repo, url)
+}
+
+func (g *GitHub) CreatePullRequest(repo, head, base, title, body string) (string, error) {
+	pr, err := g.Session.CreatePR(head, repo, g.owner, base, title, body)
+	if err != nil {
+		return "", err
+	}
+	return pr.GetHTMLURL(), nil
+}
+
+// ContainerRegistryToken returns the provided token, which ghcr.io accepts
+func (g *GitHub) ContainerRegistryToken(string) (string, error) {
+	return g.token, nil
+}
+
+// DeleteContainerRegistryRepositories is a no-op since github packages do not
+// block deleting a repository
+func (g *GitHub) DeleteContainerRegistryRepositories(string) error {
+	return nil
+}
+
+func (g *GitHub) TerraformEntrypoint() string {
+	return "terraform/github"
+}
+
+func (g *GitHub) TerraformEnvs(envs map[string]string) map[string]string {
+	envs["GITHUB_TOKEN"] = g.token
+	envs["GITHUB_OWNER"] = g.owner
+	return envs
+}
+
+// CreateResources is not supported since the github terraform creates the
+// repositories and teams
+func (g *GitHub) CreateResources(ResourceParameters) error {
+	return errors.New("github resources are created by the git terraform")
+}
+
+func (g *GitHub) ManagesUserSSHKey() bool {
+	return false
+}
+
+// statusExists converts the status code of a github get request into whether
+// the resource exists
+func statusExists(status int) (bool, error) {
+	switch status {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("unexpected github response status %d", status)
+	}
+}
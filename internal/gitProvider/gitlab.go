@@ -0,0 +1,192 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package gitProvider //nolint:revive,stylecheck // matches existing package naming
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/konstructio/kubefirst-api/internal/gitlab"
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
+	log "github.com/rs/zerolog/log"
+)
+
+// GitLab implements GitProvider for gitlab.com and self-managed GitLab.
+// Teams are subgroups of the parent group.
+type GitLab struct {
+	Client *gitlab.Wrapper
+
+	token  string
+	server pkgtypes.GitServer
+}
+
+// NewGitLab returns a GitLab provider managing resources in the group at path owner
+func NewGitLab(token, owner string, server pkgtypes.GitServer) (*GitLab, error) {
+	client, err := gitlab.NewGitLabClient(token, owner, server)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create gitlab client: %w", err)
+	}
+
+	return &GitLab{Client: client, token: token, server: server}, nil
+}
+
+func (g *GitLab) Name() string {
+	return "gitlab"
+}
+
+func (g *GitLab) Owner() string {
+	return g.Client.ParentGroupPath
+}
+
+func (g *GitLab) OwnerID() int {
+	return g.Client.ParentGroupID
+}
+
+func (g *GitLab) VerifyTokenPermissions() error {
+	return gitlab.VerifyTokenPermissions(g.token, g.server)
+}
+
+func (g *GitLab) GetAuthenticatedUser() (string, error) {
+	user, _, err := g.Client.Client.Users.CurrentUser()
+	if err != nil {
+		return "", fmt.Errorf("unable to get authenticated user info from gitlab: %w", err)
+	}
+	return user.Username, nil
+}
+
+func (g *GitLab) CheckRepoExists(name string) (bool, error) {
+	return g.Client.CheckProjectExists(name)
+}
+
+func (g *GitLab) DeleteRepo(name string) error {
+	return g.Client.DeleteProject(name)
+}
+
+func (g *GitLab) CheckTeamExists(name string) (bool, error) {
+	subgroups, err := g.Client.GetSubGroups()
+	if err != nil {
+		return false, err
+	}
+
+	for _, sg := range subgroups {
+		if sg.Name == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (g *GitLab) DeleteTeam(name string) error {
+	return g.Client.DeleteSubGroup(name)
+}
+
+func (g *GitLab) ListSSHKeys() ([]SSHKey, error) {
+	keys, err := g.Client.GetUserSSHKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]SSHKey, 0, len(keys))
+	for _, key := range keys {
+		res = append(res, SSHKey{ID: int64(key.ID), Title: key.Title, Key: key.Key})
+	}
+	return res, nil
+}
+
+func (g *GitLab) AddSSHKey(title, publicKey string) error {
+	return g.Client.AddUserSSHKey(title, publicKey)
+}
+
+func (g *GitLab) DeleteSSHKey(title string) error {
+	return g.Client.DeleteUserSSHKey(title)
+}
+
+func (g *GitLab) CreateWebhook(repo, url, secret string, events []string) error {
+	return g.Client.CreateProjectWebhook(
+		repo,
+		url,
+		secret,
+		slices.Contains(events, EventPush),
+		slices.Contains(events, EventPullRequest),
+		slices.Contains(events, EventIssueComment),
+	)
+}
+
+func (g *GitLab) DeleteWebhook(repo, url string) error {
+	return g.Client.DeleteProjectWebhook(repo, url)
+}
+
+func (g *GitLab) CreatePullRequest(repo, head, base, title, body string) (string, error) {
+	return g.Client.CreateMergeRequest(repo, head, base, title, body)
+}
+
+// ContainerRegistryToken creates, or recreates, a group deploy token scoped to
+// the container registry
+func (g *GitLab) ContainerRegistryToken(name string) (string, error) {
+	return g.Client.CreateGroupDeployToken(0, &gitlab.DeployTokenCreateParameters{
+		Name:     name,
+		Username: name,
+		Scopes:   []string{"read_registry", "write_registry"},
+	})
+}
+
+// DeleteContainerRegistryRepositories removes the container registry
+// repositories of a project, which otherwise make the project undeletable
+func (g *GitLab) DeleteContainerRegistryRepositories(repo string) error {
+	exists, err := g.Client.CheckProjectExists(repo)
+	if err != nil {
+		return fmt.Errorf("could not check for existence of project %s: %w", repo, err)
+	}
+	if !exists {
+		log.Info().Msgf("project %s does not exist, skipping", repo)
+		return nil
+	}
+
+	log.Info().Msgf("checking project %s for container registries...", repo)
+	crr, err := g.Client.GetProjectContainerRegistryRepositories(repo)
+	if err != nil {
+		return fmt.Errorf("could not retrieve container registry repositories for project %s: %w", repo, err)
+	}
+	if len(crr) == 0 {
+		log.Info().Msgf("project %s does not have any container registries, skipping", repo)
+		return nil
+	}
+
+	var errs []error
+	for _, cr := range crr {
+		if err := g.Client.DeleteContainerRegistryRepository(repo, cr.ID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (g *GitLab) TerraformEntrypoint() string {
+	return "terraform/gitlab"
+}
+
+func (g *GitLab) TerraformEnvs(envs map[string]string) map[string]string {
+	envs["GITLAB_TOKEN"] = g.token
+	envs["GITLAB_OWNER"] = g.Owner()
+	envs["TF_VAR_owner_group_id"] = strconv.Itoa(g.OwnerID())
+	envs["TF_VAR_gitlab_owner"] = g.Owner()
+	return envs
+}
+
+// CreateResources is not supported since the gitlab terraform creates the
+// projects and subgroups
+func (g *GitLab) CreateResources(ResourceParameters) error {
+	return errors.New("gitlab resources are created by the git terraform")
+}
+
+// ManagesUserSSHKey is true since the gitlab terraform does not manage ssh
+// keys, kubefirst adds the kbot key to the token user instead
+func (g *GitLab) ManagesUserSSHKey() bool {
+	return true
+}
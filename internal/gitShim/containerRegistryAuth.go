@@ -10,8 +10,9 @@ import (
 	"encoding/base64"
 	"fmt"
 
-	"github.com/konstructio/kubefirst-api/internal/gitlab"
+	"github.com/konstructio/kubefirst-api/internal/gitProvider"
 	"github.com/konstructio/kubefirst-api/internal/k8s"
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
 	log "github.com/rs/zerolog/log"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -27,6 +28,7 @@ type ContainerRegistryAuth struct {
 	GitlabGroupFlag       string
 	GithubOwner           string
 	ContainerRegistryHost string
+	GitServer             pkgtypes.GitServer
 
 	Clientset kubernetes.Interface
 }
@@ -37,8 +39,8 @@ func CreateContainerRegistrySecret(obj *ContainerRegistryAuth) (string, error) {
 	switch obj.GitProvider {
 	// GitHub docker auth secret
 	// kaniko requires a specific format for Docker auth created as a secret
-	// For GitHub, this becomes the provided token (pat)
-	case "github":
+	// For GitHub and Gitea, this becomes the provided token (pat)
+	case "github", "gitea":
 		usernamePasswordString := fmt.Sprintf("%s:%s", obj.GitUser, obj.GitToken)
 		usernamePasswordStringB64 := base64.StdEncoding.EncodeToString([]byte(usernamePasswordString))
 		dockerConfigString := fmt.Sprintf(`{"auths": {"%s": {"username": "%s", "password": "%s", "email": "%s", "auth": "%s"}}}`,
@@ -64,18 +66,13 @@ func CreateContainerRegistrySecret(obj *ContainerRegistryAuth) (string, error) {
 	// Project deploy tokens are generated for each member of createTokensForProjects
 	// These deploy tokens are used to authorize against the GitLab container registry
 	case "gitlab":
-		gitlabClient, err := gitlab.NewGitLabClient(obj.GitToken, obj.GitlabGroupFlag)
+		provider, err := gitProvider.New(obj.GitProvider, obj.GitToken, obj.GitlabGroupFlag, obj.GitServer)
 		if err != nil {
 			return "", fmt.Errorf("error while creating gitlab client: %w", err)
 		}
 
 		// Create argo workflows pull secret
-		p := gitlab.DeployTokenCreateParameters{
-			Name:     secretName,
-			Username: secretName,
-			Scopes:   []string{"read_registry", "write_registry"},
-		}
-		token, err := gitlabClient.CreateGroupDeployToken(0, &p)
+		token, err := provider.ContainerRegistryToken(secretName)
 		if err != nil {
 			log.Error().Msgf("error while creating secret for container registry auth: %s", err)
 		}
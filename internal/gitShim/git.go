@@ -13,7 +13,9 @@ import (
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/konstructio/kubefirst-api/internal/catalogMirror"
 	"github.com/konstructio/kubefirst-api/internal/gitClient"
+	"github.com/konstructio/kubefirst-api/internal/gitHost"
 	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
 	"github.com/rs/zerolog/log"
 )
@@ -73,7 +75,7 @@ func PrepareMgmtCluster(cluster pkgtypes.Cluster) error {
 
 func PrepareGitEnvironment(cluster *pkgtypes.Cluster, gitopsDir string) error {
 	repoURL := fmt.Sprintf("https://%s/%s/gitops", cluster.GitHost, cluster.GitAuth.Owner)
-	_, err := gitClient.ClonePrivateRepo("main", gitopsDir, repoURL, cluster.GitAuth.User, cluster.GitAuth.Token)
+	_, err := gitClient.ClonePrivateRepo("main", gitopsDir, repoURL, cluster.GitAuth.User, cluster.GitAuth.Token, gitHost.CABundle(cluster.GitServer))
 	if err != nil {
 		log.Error().Msgf("error cloning private repository: %s", err)
 		return fmt.Errorf("error cloning private repository: %w", err)
@@ -83,6 +85,14 @@ func PrepareGitEnvironment(cluster *pkgtypes.Cluster, gitopsDir string) error {
 }
 
 func PrepareGitOpsCatalog(gitopsCatalogDir string) error {
+	if catalogMirror.Enabled() {
+		if err := catalogMirror.Clone(gitopsCatalogDir); err != nil {
+			log.Error().Msgf("error preparing gitops catalog from mirror: %s", err)
+			return fmt.Errorf("error preparing gitops catalog from mirror: %w", err)
+		}
+		return nil
+	}
+
 	repoURL := fmt.Sprintf("https://github.com/%s/%s", KubefirstGitHubOrganization, KubefirstGitopsCatalogRepository)
 	_, err := gitClient.Clone("main", gitopsCatalogDir, repoURL)
 	if err != nil {
@@ -92,3 +102,50 @@ func PrepareGitOpsCatalog(gitopsCatalogDir string) error {
 
 	return nil
 }
+
+// PrepareGitOpsCatalogAtRevision clones the gitops catalog and checks out the
+// provided commit so a service can be re-rendered from the catalog version it
+// was originally installed from. An empty revision leaves the catalog at main.
+func PrepareGitOpsCatalogAtRevision(gitopsCatalogDir, revision string) error {
+	if err := PrepareGitOpsCatalog(gitopsCatalogDir); err != nil {
+		return err
+	}
+
+	if revision == "" {
+		return nil
+	}
+
+	repo, err := git.PlainOpen(gitopsCatalogDir)
+	if err != nil {
+		return fmt.Errorf("error opening gitops catalog repository: %w", err)
+	}
+
+	w, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("error getting gitops catalog worktree: %w", err)
+	}
+
+	err = w.Checkout(&git.CheckoutOptions{Hash: plumbing.NewHash(revision)})
+	if err != nil {
+		log.Error().Msgf("error checking out gitops catalog revision %q: %s", revision, err)
+		return fmt.Errorf("error checking out gitops catalog revision %q: %w", revision, err)
+	}
+
+	return nil
+}
+
+// GitOpsCatalogRevision returns the commit currently checked out in a local
+// gitops catalog clone
+func GitOpsCatalogRevision(gitopsCatalogDir string) (string, error) {
+	repo, err := git.PlainOpen(gitopsCatalogDir)
+	if err != nil {
+		return "", fmt.Errorf("error opening gitops catalog repository: %w", err)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("error getting gitops catalog head reference: %w", err)
+	}
+
+	return head.Hash().String(), nil
+}
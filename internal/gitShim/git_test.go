@@ -0,0 +1,84 @@
+package gitShim //nolint:revive,stylecheck // allowed during code reorg
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/konstructio/kubefirst-api/internal/catalogMirror"
+	"github.com/konstructio/kubefirst-api/internal/downloadManager"
+	"github.com/konstructio/kubefirst-api/internal/env"
+)
+
+// useCatalogMirror serves the gitops catalog from a checksummed tarball so the
+// catalog can be prepared without github.com
+func useCatalogMirror(t *testing.T) {
+	t.Helper()
+
+	tmp := t.TempDir()
+	archive := filepath.Join(tmp, "catalog.tar.gz")
+
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	files := map[string]string{
+		"index.yaml":           "name: test\napps:\n- name: example\n",
+		"example/example.yaml": "kind: Application\n",
+	}
+	for name, body := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(body)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if _, err := tw.Write([]byte(body)); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	f.Close()
+
+	sum, err := downloadManager.FileSHA256(archive)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	t.Setenv("HOME", tmp)
+	catalogMirror.Configure(env.Env{GitopsCatalogMirror: archive, GitopsCatalogMirrorSHA256: sum})
+	t.Cleanup(func() { catalogMirror.Configure(env.Env{}) })
+}
+
+func TestPrepareGitOpsCatalogAtRevision(t *testing.T) {
+	useCatalogMirror(t)
+	root := t.TempDir()
+
+	head := filepath.Join(root, "head")
+	if err := PrepareGitOpsCatalogAtRevision(head, ""); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	revision, err := GitOpsCatalogRevision(head)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	pinned := filepath.Join(root, "pinned")
+	if err := PrepareGitOpsCatalogAtRevision(pinned, revision); err != nil {
+		t.Fatalf("unexpected error checking out %s: %s", revision, err)
+	}
+	if _, err := os.Stat(filepath.Join(pinned, "example", "example.yaml")); err != nil {
+		t.Errorf("expected the catalog app at revision %s: %s", revision, err)
+	}
+
+	unknown := filepath.Join(root, "unknown")
+	if err := PrepareGitOpsCatalogAtRevision(unknown, "0123456789abcdef0123456789abcdef01234567"); err == nil {
+		t.Error("expected an error for a revision that is not in the catalog")
+	}
+}
@@ -0,0 +1,42 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package gitea
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// VerifyTokenPermissions checks that the token can manage repositories and teams
+// in the owner organization. Gitea does not expose token scopes, so the
+// effective organization permissions of the token's user are checked instead.
+func (g *Wrapper) VerifyTokenPermissions() error {
+	user, err := g.GetAuthenticatedUser()
+	if err != nil {
+		return err
+	}
+
+	var perms OrgPermissions
+	path := fmt.Sprintf("/users/%s/orgs/%s/permissions", user, g.Owner)
+	status, err := g.do(http.MethodGet, path, nil, &perms)
+	if err != nil {
+		if status == http.StatusNotFound {
+			return fmt.Errorf("gitea organization %q does not exist or is not visible to user %q", g.Owner, user)
+		}
+		return fmt.Errorf("error reading permissions of user %q in gitea organization %q: %w", user, g.Owner, err)
+	}
+
+	if !perms.IsOwner && !perms.IsAdmin {
+		return errors.New("the supplied gitea token must belong to an owner or admin of the organization")
+	}
+	if !perms.CanCreateRepository {
+		return fmt.Errorf("the supplied gitea token cannot create repositories in organization %q", g.Owner)
+	}
+
+	return nil
+}
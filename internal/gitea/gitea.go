@@ -0,0 +1,533 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package gitea
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/konstructio/kubefirst-api/internal/gitHost"
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	pageSize = 50
+
+	// KbotSSHKeyTitle is the title of the deploy key kubefirst adds to every repository
+	KbotSSHKeyTitle = "kbot-ssh-key"
+)
+
+// teamUnits grants a team access to every repository feature kubefirst relies on
+var teamUnits = []string{
+	"repo.code",
+	"repo.issues",
+	"repo.pulls",
+	"repo.releases",
+	"repo.wiki",
+	"repo.packages",
+	"repo.actions",
+}
+
+// teamPermissions maps the kubefirst teams to their repository permission
+var teamPermissions = map[string]string{
+	"admins":     "admin",
+	"developers": "write",
+}
+
+// NewGiteaClient instantiates a wrapper to communicate with a Gitea or Forgejo
+// server. owner is the organization under which resources will be managed.
+func NewGiteaClient(token string, owner string, server pkgtypes.GitServer) (*Wrapper, error) {
+	if !gitHost.IsSelfHosted(server) {
+		return nil, errors.New("a gitea server base url is required")
+	}
+
+	httpClient, err := gitHost.HTTPClient(server)
+	if err != nil {
+		return nil, fmt.Errorf("error creating gitea http client: %w", err)
+	}
+
+	return &Wrapper{
+		Client: httpClient,
+		APIURL: gitHost.APIURL("gitea", server),
+		Token:  token,
+		Owner:  owner,
+	}, nil
+}
+
+// GetAuthenticatedUser returns the login of the user owning the token
+func (g *Wrapper) GetAuthenticatedUser() (string, error) {
+	var user User
+	if _, err := g.do(http.MethodGet, "/user", nil, &user); err != nil {
+		return "", fmt.Errorf("error retrieving authenticated gitea user: %w", err)
+	}
+
+	return user.Login, nil
+}
+
+// Repositories
+
+// CheckRepoExists reports whether a repository exists in the owner organization
+func (g *Wrapper) CheckRepoExists(name string) (bool, error) {
+	status, err := g.do(http.MethodGet, g.repoPath(name), nil, nil)
+	if status == http.StatusNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error checking for gitea repository %s/%s: %w", g.Owner, name, err)
+	}
+
+	return true, nil
+}
+
+// CreatePrivateRepo creates an empty private repository in the owner organization
+func (g *Wrapper) CreatePrivateRepo(name, description string) error {
+	body := map[string]interface{}{
+		"name":        name,
+		"description": description,
+		"private":     true,
+		"auto_init":   false,
+	}
+
+	if _, err := g.do(http.MethodPost, fmt.Sprintf("/orgs/%s/repos", url.PathEscape(g.Owner)), body, nil); err != nil {
+		return fmt.Errorf("error creating gitea repository %s/%s: %w", g.Owner, name, err)
+	}
+
+	log.Info().Msgf("created gitea repository %s/%s", g.Owner, name)
+	return nil
+}
+
+// DeleteRepo removes a repository from the owner organization
+func (g *Wrapper) DeleteRepo(name string) error {
+	status, err := g.do(http.MethodDelete, g.repoPath(name), nil, nil)
+	if status == http.StatusNotFound {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error deleting gitea repository %s/%s: %w", g.Owner, name, err)
+	}
+
+	log.Info().Msgf("deleted gitea repository %s/%s", g.Owner, name)
+	return nil
+}
+
+// Teams
+
+// ListTeams returns every team in the owner organization
+func (g *Wrapper) ListTeams() ([]Team, error) {
+	teams := []Team{}
+	for page := 1; ; page++ {
+		var res []Team
+		path := fmt.Sprintf("/orgs/%s/teams?page=%d&limit=%d", url.PathEscape(g.Owner), page, pageSize)
+		if _, err := g.do(http.MethodGet, path, nil, &res); err != nil {
+			return nil, fmt.Errorf("error listing gitea teams in %q: %w", g.Owner, err)
+		}
+		teams = append(teams, res...)
+		if len(res) < pageSize {
+			return teams, nil
+		}
+	}
+}
+
+// GetTeam returns the team with the given name, or nil when it does not exist
+func (g *Wrapper) GetTeam(name string) (*Team, error) {
+	teams, err := g.ListTeams()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, team := range teams {
+		if strings.EqualFold(team.Name, name) {
+			return &team, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// CreateTeam creates a team with the given repository permission
+func (g *Wrapper) CreateTeam(name, permission string) (*Team, error) {
+	body := map[string]interface{}{
+		"name":                      name,
+		"permission":                permission,
+		"units":                     teamUnits,
+		"includes_all_repositories": false,
+	}
+
+	var team Team
+	if _, err := g.do(http.MethodPost, fmt.Sprintf("/orgs/%s/teams", url.PathEscape(g.Owner)), body, &team); err != nil {
+		return nil, fmt.Errorf("error creating gitea team %q: %w", name, err)
+	}
+
+	log.Info().Msgf("created gitea team %s/%s", g.Owner, name)
+	return &team, nil
+}
+
+// AddTeamRepo grants a team access to a repository of the owner organization
+func (g *Wrapper) AddTeamRepo(teamID int64, repo string) error {
+	path := fmt.Sprintf("/teams/%d/repos/%s/%s", teamID, url.PathEscape(g.Owner), url.PathEscape(repo))
+	if _, err := g.do(http.MethodPut, path, nil, nil); err != nil {
+		return fmt.Errorf("error adding repository %q to gitea team %d: %w", repo, teamID, err)
+	}
+
+	return nil
+}
+
+// DeleteTeam removes a team by name if it exists
+func (g *Wrapper) DeleteTeam(name string) error {
+	team, err := g.GetTeam(name)
+	if err != nil {
+		return err
+	}
+	if team == nil {
+		return nil
+	}
+
+	if _, err := g.do(http.MethodDelete, fmt.Sprintf("/teams/%d", team.ID), nil, nil); err != nil {
+		return fmt.Errorf("error deleting gitea team %q: %w", name, err)
+	}
+
+	log.Info().Msgf("deleted gitea team %s/%s", g.Owner, name)
+	return nil
+}
+
+// Deploy keys
+
+// ListDeployKeys returns the deploy keys of a repository
+func (g *Wrapper) ListDeployKeys(repo string) ([]DeployKey, error) {
+	var keys []DeployKey
+	if _, err := g.do(http.MethodGet, g.repoPath(repo)+"/keys", nil, &keys); err != nil {
+		return nil, fmt.Errorf("error listing deploy keys of gitea repository %q: %w", repo, err)
+	}
+
+	return keys, nil
+}
+
+// AddDeployKey adds an ssh key to a repository. Existing keys with the same
+// title and key are kept, so the call can be retried.
+func (g *Wrapper) AddDeployKey(repo, title, key string, readOnly bool) error {
+	keys, err := g.ListDeployKeys(repo)
+	if err != nil {
+		return err
+	}
+
+	for _, k := range keys {
+		if k.Title == title && strings.Contains(k.Key, strings.TrimSpace(key)) {
+			log.Info().Msgf("deploy key %s already exists on %s/%s, continuing", title, g.Owner, repo)
+			return nil
+		}
+	}
+
+	body := map[string]interface{}{
+		"title":     title,
+		"key":       key,
+		"read_only": readOnly,
+	}
+	if _, err := g.do(http.MethodPost, g.repoPath(repo)+"/keys", body, nil); err != nil {
+		return fmt.Errorf("error adding deploy key %q to gitea repository %q: %w", title, repo, err)
+	}
+
+	return nil
+}
+
+// Webhooks
+
+// ListRepoWebhooks returns the webhooks of a repository
+func (g *Wrapper) ListRepoWebhooks(repo string) ([]Hook, error) {
+	var hooks []Hook
+	if _, err := g.do(http.MethodGet, g.repoPath(repo)+"/hooks", nil, &hooks); err != nil {
+		return nil, fmt.Errorf("error listing webhooks of gitea repository %q: %w", repo, err)
+	}
+
+	return hooks, nil
+}
+
+// CreateWebhookRepo adds a json webhook to a repository unless one already
+// targets the same url
+func (g *Wrapper) CreateWebhookRepo(repo, hookURL, hookSecret string, hookEvents []string) error {
+	hooks, err := g.ListRepoWebhooks(repo)
+	if err != nil {
+		return err
+	}
+
+	for _, hook := range hooks {
+		if hook.Config["url"] == hookURL {
+			log.Info().Msgf("webhook %s already exists on %s/%s, continuing", hookURL, g.Owner, repo)
+			return nil
+		}
+	}
+
+	body := map[string]interface{}{
+		"type":   "gitea",
+		"active": true,
+		"events": hookEvents,
+		"config": map[string]string{
+			"url":          hookURL,
+			"content_type": "json",
+			"secret":       hookSecret,
+		},
+	}
+	if _, err := g.do(http.MethodPost, g.repoPath(repo)+"/hooks", body, nil); err != nil {
+		return fmt.Errorf("error creating webhook for gitea repository %q: %w", repo, err)
+	}
+
+	log.Info().Msgf("created webhook %s on %s/%s", hookURL, g.Owner, repo)
+	return nil
+}
+
+// DeleteRepositoryWebhook removes every webhook of a repository targeting url
+func (g *Wrapper) DeleteRepositoryWebhook(repo, hookURL string) error {
+	hooks, err := g.ListRepoWebhooks(repo)
+	if err != nil {
+		return err
+	}
+
+	for _, hook := range hooks {
+		if hook.Config["url"] != hookURL {
+			continue
+		}
+		if _, err := g.do(http.MethodDelete, fmt.Sprintf("%s/hooks/%d", g.repoPath(repo), hook.ID), nil, nil); err != nil {
+			return fmt.Errorf("error deleting webhook %d of gitea repository %q: %w", hook.ID, repo, err)
+		}
+	}
+
+	return nil
+}
+
+// User ssh keys
+
+// ListUserSSHKeys returns the ssh keys of the authenticated user
+func (g *Wrapper) ListUserSSHKeys() ([]DeployKey, error) {
+	keys := []DeployKey{}
+	for page := 1; ; page++ {
+		var res []DeployKey
+		if _, err := g.do(http.MethodGet, fmt.Sprintf("/user/keys?page=%d&limit=%d", page, pageSize), nil, &res); err != nil {
+			return nil, fmt.Errorf("error listing gitea user ssh keys: %w", err)
+		}
+		keys = append(keys, res...)
+		if len(res) < pageSize {
+			return keys, nil
+		}
+	}
+}
+
+// AddUserSSHKey adds an ssh key to the authenticated user
+func (g *Wrapper) AddUserSSHKey(title, key string) error {
+	body := map[string]interface{}{
+		"title": title,
+		"key":   key,
+	}
+	if _, err := g.do(http.MethodPost, "/user/keys", body, nil); err != nil {
+		return fmt.Errorf("error adding gitea user ssh key %q: %w", title, err)
+	}
+
+	return nil
+}
+
+// DeleteUserSSHKey removes every ssh key of the authenticated user with the given title
+func (g *Wrapper) DeleteUserSSHKey(title string) error {
+	keys, err := g.ListUserSSHKeys()
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if key.Title != title {
+			continue
+		}
+		if _, err := g.do(http.MethodDelete, fmt.Sprintf("/user/keys/%d", key.ID), nil, nil); err != nil {
+			return fmt.Errorf("error deleting gitea user ssh key %q: %w", title, err)
+		}
+		log.Info().Msgf("deleted gitea ssh key %s", title)
+	}
+
+	return nil
+}
+
+// Pull requests
+
+// CreatePullRequest opens a pull request in a repository and returns its web url
+func (g *Wrapper) CreatePullRequest(repo, head, base, title, body string) (string, error) {
+	in := map[string]interface{}{
+		"head":  head,
+		"base":  base,
+		"title": title,
+		"body":  body,
+	}
+
+	var pr PullRequest
+	if _, err := g.do(http.MethodPost, g.repoPath(repo)+"/pulls", in, &pr); err != nil {
+		return "", fmt.Errorf("error creating pull request for gitea repository %q: %w", repo, err)
+	}
+
+	log.Info().Msgf("created pull request %s", pr.HTMLURL)
+	return pr.HTMLURL, nil
+}
+
+// Resources
+
+// CheckResourcesAvailable returns an error listing any repositories or teams
+// that already exist and would be overwritten by a new installation
+func (g *Wrapper) CheckResourcesAvailable(repositories, teams []string) error {
+	existing := []string{}
+
+	for _, repo := range repositories {
+		exists, err := g.CheckRepoExists(repo)
+		if err != nil {
+			return err
+		}
+		if exists {
+			existing = append(existing, fmt.Sprintf("repository %s/%s", g.Owner, repo))
+		}
+	}
+
+	for _, name := range teams {
+		team, err := g.GetTeam(name)
+		if err != nil {
+			return err
+		}
+		if team != nil {
+			existing = append(existing, fmt.Sprintf("team %s/%s", g.Owner, name))
+		}
+	}
+
+	if len(existing) > 0 {
+		return fmt.Errorf("the following must be removed before continuing with your kubefirst installation: %s", strings.Join(existing, ", "))
+	}
+
+	return nil
+}
+
+// CreateResources creates the repositories, teams, deploy keys and atlantis
+// webhook that the github and gitlab terraform entrypoints manage for the
+// other providers. Resources that already exist are reused so a failed run
+// can be retried.
+func (g *Wrapper) CreateResources(p *ResourceParameters) error {
+	for _, repo := range p.Repositories {
+		exists, err := g.CheckRepoExists(repo)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			if err := g.CreatePrivateRepo(repo, fmt.Sprintf("kubefirst %s repository", repo)); err != nil {
+				return err
+			}
+		}
+
+		if p.KbotSSHPublicKey != "" {
+			if err := g.AddDeployKey(repo, KbotSSHKeyTitle, p.KbotSSHPublicKey, false); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, name := range p.Teams {
+		team, err := g.GetTeam(name)
+		if err != nil {
+			return err
+		}
+		if team == nil {
+			permission, ok := teamPermissions[name]
+			if !ok {
+				permission = "read"
+			}
+			team, err = g.CreateTeam(name, permission)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, repo := range p.Repositories {
+			if err := g.AddTeamRepo(team.ID, repo); err != nil {
+				return err
+			}
+		}
+	}
+
+	if p.AtlantisWebhookURL != "" {
+		events := []string{"push", "pull_request", "issue_comment"}
+		if err := g.CreateWebhookRepo("gitops", p.AtlantisWebhookURL, p.AtlantisWebhookSecret, events); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteResources removes the repositories and teams created by CreateResources
+func (g *Wrapper) DeleteResources(p *ResourceParameters) error {
+	for _, repo := range p.Repositories {
+		if err := g.DeleteRepo(repo); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range p.Teams {
+		if err := g.DeleteTeam(name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (g *Wrapper) repoPath(repo string) string {
+	return fmt.Sprintf("/repos/%s/%s", url.PathEscape(g.Owner), url.PathEscape(repo))
+}
+
+// do sends a request to the gitea api and decodes a json response into out.
+// The response status is returned alongside any error so callers can
+// distinguish missing resources.
+func (g *Wrapper) do(method, path string, in interface{}, out interface{}) (int, error) {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return 0, fmt.Errorf("error marshalling request body: %w", err)
+		}
+		body = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequest(method, g.APIURL+path, body)
+	if err != nil {
+		return 0, fmt.Errorf("error creating request to %q: %w", path, err)
+	}
+	req.Header.Set("Authorization", "token "+g.Token)
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := g.Client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("error calling gitea api %s %q: %w", method, req.URL.String(), err)
+	}
+	defer res.Body.Close()
+
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return res.StatusCode, fmt.Errorf("error reading gitea response body: %w", err)
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("gitea api %s %q returned status %d: %q", method, req.URL.String(), res.StatusCode, string(resBody))
+	}
+
+	if out != nil && len(resBody) > 0 {
+		if err := json.Unmarshal(resBody, out); err != nil {
+			return res.StatusCode, fmt.Errorf("error decoding gitea response: %w", err)
+		}
+	}
+
+	return res.StatusCode, nil
+}
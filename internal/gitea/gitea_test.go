@@ -0,0 +1,210 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package gitea
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// fakeGitea is an in-memory stand-in for the subset of the gitea api used by
+// kubefirst
+type fakeGitea struct {
+	mu     sync.Mutex
+	nextID int64
+	repos  map[string]bool
+	teams  map[int64]*Team
+	access map[int64][]string
+	keys   map[string][]DeployKey
+	hooks  map[string][]Hook
+}
+
+func newFakeGitea(t *testing.T) (*fakeGitea, *Wrapper) {
+	t.Helper()
+
+	f := &fakeGitea{
+		repos:  map[string]bool{},
+		teams:  map[int64]*Team{},
+		access: map[int64][]string{},
+		keys:   map[string][]DeployKey{},
+		hooks:  map[string][]Hook{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/repos/{owner}/{repo}", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if !f.repos[r.PathValue("repo")] {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, Repository{Name: r.PathValue("repo")})
+	})
+	mux.HandleFunc("DELETE /api/v1/repos/{owner}/{repo}", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if !f.repos[r.PathValue("repo")] {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		delete(f.repos, r.PathValue("repo"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("POST /api/v1/orgs/{owner}/repos", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Name string `json:"name"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if f.repos[body.Name] {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		f.repos[body.Name] = true
+		writeJSON(w, http.StatusCreated, Repository{Name: body.Name})
+	})
+	mux.HandleFunc("GET /api/v1/orgs/{owner}/teams", func(w http.ResponseWriter, _ *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		teams := []Team{}
+		for _, team := range f.teams {
+			teams = append(teams, *team)
+		}
+		writeJSON(w, http.StatusOK, teams)
+	})
+	mux.HandleFunc("POST /api/v1/orgs/{owner}/teams", func(w http.ResponseWriter, r *http.Request) {
+		var team Team
+		json.NewDecoder(r.Body).Decode(&team)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.nextID++
+		team.ID = f.nextID
+		f.teams[team.ID] = &team
+		writeJSON(w, http.StatusCreated, team)
+	})
+	mux.HandleFunc("DELETE /api/v1/teams/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		delete(f.teams, id)
+		delete(f.access, id)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("PUT /api/v1/teams/{id}/repos/{owner}/{repo}", func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.access[id] = append(f.access[id], r.PathValue("repo"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /api/v1/repos/{owner}/{repo}/keys", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		writeJSON(w, http.StatusOK, f.keys[r.PathValue("repo")])
+	})
+	mux.HandleFunc("POST /api/v1/repos/{owner}/{repo}/keys", func(w http.ResponseWriter, r *http.Request) {
+		var key DeployKey
+		json.NewDecoder(r.Body).Decode(&key)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.keys[r.PathValue("repo")] = append(f.keys[r.PathValue("repo")], key)
+		writeJSON(w, http.StatusCreated, key)
+	})
+	mux.HandleFunc("GET /api/v1/repos/{owner}/{repo}/hooks", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		writeJSON(w, http.StatusOK, f.hooks[r.PathValue("repo")])
+	})
+	mux.HandleFunc("POST /api/v1/repos/{owner}/{repo}/hooks", func(w http.ResponseWriter, r *http.Request) {
+		var hook Hook
+		json.NewDecoder(r.Body).Decode(&hook)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.hooks[r.PathValue("repo")] = append(f.hooks[r.PathValue("repo")], hook)
+		writeJSON(w, http.StatusCreated, hook)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return f, &Wrapper{
+		Client: server.Client(),
+		APIURL: server.URL + "/api/v1",
+		Token:  "token",
+		Owner:  "kubefirst",
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func TestResources(t *testing.T) {
+	f, g := newFakeGitea(t)
+
+	p := &ResourceParameters{
+		Repositories:          []string{"gitops", "metaphor"},
+		Teams:                 []string{"admins", "developers"},
+		AtlantisWebhookURL:    "https://atlantis.example.com/events",
+		AtlantisWebhookSecret: "secret",
+		KbotSSHPublicKey:      "ssh-ed25519 AAAA kbot",
+	}
+
+	if err := g.CheckResourcesAvailable(p.Repositories, p.Teams); err != nil {
+		t.Fatalf("expected empty organization to be available: %s", err)
+	}
+
+	// a second run must reuse what the first one created
+	for i := 0; i < 2; i++ {
+		if err := g.CreateResources(p); err != nil {
+			t.Fatalf("run %d: unexpected error: %s", i, err)
+		}
+	}
+
+	if len(f.repos) != 2 {
+		t.Errorf("expected 2 repositories, got %d", len(f.repos))
+	}
+	if len(f.teams) != 2 {
+		t.Errorf("expected 2 teams, got %d", len(f.teams))
+	}
+	for _, team := range f.teams {
+		if want := teamPermissions[team.Name]; team.Permission != want {
+			t.Errorf("expected team %s permission %q, got %q", team.Name, want, team.Permission)
+		}
+	}
+	for _, repo := range p.Repositories {
+		if len(f.keys[repo]) != 1 {
+			t.Errorf("expected 1 deploy key on %s, got %d", repo, len(f.keys[repo]))
+		}
+	}
+	if len(f.hooks["gitops"]) != 1 {
+		t.Errorf("expected 1 webhook on gitops, got %d", len(f.hooks["gitops"]))
+	}
+
+	if err := g.CheckResourcesAvailable(p.Repositories, p.Teams); err == nil {
+		t.Error("expected existing resources to be reported")
+	}
+
+	if err := g.DeleteResources(p); err != nil {
+		t.Fatalf("unexpected error deleting resources: %s", err)
+	}
+	if err := g.CheckResourcesAvailable(p.Repositories, p.Teams); err != nil {
+		t.Errorf("expected resources to be removed: %s", err)
+	}
+
+	// deleting missing resources is not an error
+	if err := g.DeleteResources(p); err != nil {
+		t.Errorf("unexpected error deleting missing resources: %s", err)
+	}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package gitea
+
+import "net/http"
+
+// Wrapper holds gitea client info and provides an interface to its functions.
+// The same client works against Forgejo, which keeps the Gitea api.
+type Wrapper struct {
+	Client *http.Client
+	APIURL string
+	Token  string
+	Owner  string
+}
+
+// User is the subset of a gitea user used by kubefirst
+type User struct {
+	ID    int64  `json:"id"`
+	Login string `json:"login"`
+}
+
+// Repository is the subset of a gitea repository used by kubefirst
+type Repository struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	FullName string `json:"full_name"`
+	CloneURL string `json:"clone_url"`
+	SSHURL   string `json:"ssh_url"`
+	Private  bool   `json:"private"`
+}
+
+// Team is the subset of a gitea organization team used by kubefirst
+type Team struct {
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	Permission string `json:"permission"`
+}
+
+// DeployKey is a repository scoped ssh key
+type DeployKey struct {
+	ID       int64  `json:"id"`
+	Title    string `json:"title"`
+	Key      string `json:"key"`
+	ReadOnly bool   `json:"read_only"`
+}
+
+// Hook is a repository webhook
+type Hook struct {
+	ID     int64             `json:"id"`
+	Type   string            `json:"type"`
+	Config map[string]string `json:"config"`
+	Events []string          `json:"events"`
+	Active bool              `json:"active"`
+}
+
+// PullRequest is the subset of a gitea pull request used by kubefirst
+type PullRequest struct {
+	ID      int64  `json:"id"`
+	Number  int64  `json:"number"`
+	HTMLURL string `json:"html_url"`
+}
+
+// OrgPermissions describes what the authenticated user may do in an organization
+type OrgPermissions struct {
+	IsOwner             bool `json:"is_owner"`
+	IsAdmin             bool `json:"is_admin"`
+	CanWrite            bool `json:"can_write"`
+	CanRead             bool `json:"can_read"`
+	CanCreateRepository bool `json:"can_create_repository"`
+}
+
+// ResourceParameters describes the git resources kubefirst manages in a gitea
+// organization in place of the github and gitlab terraform entrypoints
+type ResourceParameters struct {
+	Repositories          []string
+	Teams                 []string
+	AtlantisWebhookURL    string
+	AtlantisWebhookSecret string
+	KbotSSHPublicKey      string
+}
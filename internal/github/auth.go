@@ -13,14 +13,11 @@ import (
 	"strings"
 
 	pkg "github.com/konstructio/kubefirst-api/internal"
-	"github.com/konstructio/kubefirst-api/internal/httpCommon"
+	"github.com/konstructio/kubefirst-api/internal/gitHost"
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
 	"github.com/rs/zerolog/log"
 )
 
-const (
-	githubAPIURL = "https://api.github.com"
-)
-
 var requiredScopes = [...]string{
 	"admin:org",
 	"admin:public_key",
@@ -34,8 +31,8 @@ var requiredScopes = [...]string{
 
 // VerifyTokenPermissions compares scope of the provided token to the required
 // scopes for kubefirst functionality
-func VerifyTokenPermissions(githubToken string) error {
-	req, err := http.NewRequest(http.MethodGet, githubAPIURL, nil)
+func VerifyTokenPermissions(githubToken string, server pkgtypes.GitServer) error {
+	req, err := http.NewRequest(http.MethodGet, gitHost.APIURL("github", server), nil)
 	if err != nil {
 		log.Info().Msg("error setting github owner permissions request")
 		return fmt.Errorf("unable to create request to verify token permissions: %w", err)
@@ -43,7 +40,12 @@ func VerifyTokenPermissions(githubToken string) error {
 
 	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", githubToken))
 
-	res, err := httpCommon.CustomHTTPClient(false).Do(req)
+	client, err := gitHost.HTTPClient(server)
+	if err != nil {
+		return fmt.Errorf("unable to create GitHub http client: %w", err)
+	}
+
+	res, err := client.Do(req)
 	if err != nil {
 		return fmt.Errorf("error calling GitHub API %q: %w", req.URL.String(), err)
 	}
@@ -15,6 +15,8 @@ import (
 	"time"
 
 	"github.com/google/go-github/v45/github"
+	"github.com/konstructio/kubefirst-api/internal/gitHost"
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
 	"github.com/rs/zerolog/log"
 	"golang.org/x/oauth2"
 )
@@ -26,14 +28,32 @@ type Session struct {
 	gitClient   *github.Client
 }
 
-// New - Create a new client for github wrapper
-func New(token string) Session {
+// New - Create a new client for github wrapper. A GitHub Enterprise server is
+// used when the provided git server has a base url.
+func New(token string, server pkgtypes.GitServer) (Session, error) {
 	var gSession Session
-	gSession.context = context.Background()
+
+	httpClient, err := gitHost.HTTPClient(server)
+	if err != nil {
+		return gSession, fmt.Errorf("error creating github http client: %w", err)
+	}
+
+	gSession.context = context.WithValue(context.Background(), oauth2.HTTPClient, httpClient)
 	gSession.staticToken = oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
 	gSession.oauthClient = oauth2.NewClient(gSession.context, gSession.staticToken)
-	gSession.gitClient = github.NewClient(gSession.oauthClient)
-	return gSession
+
+	if !gitHost.IsSelfHosted(server) {
+		gSession.gitClient = github.NewClient(gSession.oauthClient)
+		return gSession, nil
+	}
+
+	apiURL := gitHost.APIURL("github", server)
+	gSession.gitClient, err = github.NewEnterpriseClient(apiURL, apiURL, gSession.oauthClient)
+	if err != nil {
+		return gSession, fmt.Errorf("error creating github enterprise client for %q: %w", apiURL, err)
+	}
+
+	return gSession, nil
 }
 
 func (g Session) CreateWebhookRepo(org, repo, hookName, hookURL, hookSecret string, hookEvents []string) error {
@@ -280,18 +300,53 @@ func (g Session) RetrySearchPullRequestComment(
 	return false, fmt.Errorf("failed to find the search term %q in comments for pull request %d after retries", searchFor, *pullRequest.Number)
 }
 
-// GetRepo - Always returns a status code for whether a repository exists or not
+// GetRepo - Always returns a status code for whether a repository exists or not.
+// A status code of 0 means the request could not be completed.
 func (g Session) CheckRepoExists(owner, name string) int {
 	_, response, _ := g.gitClient.Repositories.Get(g.context, owner, name)
+	if response == nil {
+		return 0
+	}
 	return response.StatusCode
 }
 
-// GetRepo - Always returns a status code for whether a team exists or not
+// GetRepo - Always returns a status code for whether a team exists or not.
+// A status code of 0 means the request could not be completed.
 func (g Session) CheckTeamExists(owner, name string) int {
 	_, response, _ := g.gitClient.Teams.GetTeamBySlug(g.context, owner, name)
+	if response == nil {
+		return 0
+	}
 	return response.StatusCode
 }
 
+// ListSSHKeys returns the ssh keys of the user owning the session token
+func (g Session) ListSSHKeys() ([]*github.Key, error) {
+	container := make([]*github.Key, 0)
+	for nextPage := 1; nextPage > 0; {
+		keys, resp, err := g.gitClient.Users.ListKeys(g.context, "", &github.ListOptions{
+			Page:    nextPage,
+			PerPage: 100,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error listing ssh keys of authenticated user: %w", err)
+		}
+		container = append(container, keys...)
+		nextPage = resp.NextPage
+	}
+	return container, nil
+}
+
+// GetAuthenticatedUser returns the login of the user owning the session token
+func (g Session) GetAuthenticatedUser() (string, error) {
+	user, _, err := g.gitClient.Users.Get(g.context, "")
+	if err != nil {
+		return "", fmt.Errorf("error retrieving authenticated github user: %w", err)
+	}
+
+	return user.GetLogin(), nil
+}
+
 // DeleteRepositoryWebhook
 func (g Session) DeleteRepositoryWebhook(owner, repository, url string) error {
 	webhooks, err := g.ListRepoWebhooks(owner, repository)
@@ -13,18 +13,15 @@ import (
 	"net/http"
 
 	"github.com/konstructio/kubefirst-api/internal"
-	"github.com/konstructio/kubefirst-api/internal/httpCommon"
+	"github.com/konstructio/kubefirst-api/internal/gitHost"
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
 	"github.com/rs/zerolog/log"
 )
 
-const (
-	gitlabAPIURL = "https://gitlab.com/api/v4"
-)
-
 // VerifyTokenPermissions compares scope of the provided token to the required
 // scopes for kubefirst functionality
-func VerifyTokenPermissions(gitlabToken string) error {
-	destination := gitlabAPIURL + "/personal_access_tokens/self"
+func VerifyTokenPermissions(gitlabToken string, server pkgtypes.GitServer) error {
+	destination := gitHost.APIURL("gitlab", server) + "/personal_access_tokens/self"
 	req, err := http.NewRequest(http.MethodGet, destination, nil)
 	if err != nil {
 		log.Error().Msgf("unable to create HTTP request to %q", destination)
@@ -33,7 +30,12 @@ func VerifyTokenPermissions(gitlabToken string) error {
 
 	req.Header.Add("Authorization", "Bearer "+gitlabToken)
 
-	res, err := httpCommon.CustomHTTPClient(false).Do(req)
+	client, err := gitHost.HTTPClient(server)
+	if err != nil {
+		return fmt.Errorf("unable to create GitLab http client: %w", err)
+	}
+
+	res, err := client.Do(req)
 	if err != nil {
 		return fmt.Errorf("unable to make GET request to GitLab API %q: %w", req.URL.String(), err)
 	}
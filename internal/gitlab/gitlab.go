@@ -10,14 +10,22 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/konstructio/kubefirst-api/internal/gitHost"
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
 	"github.com/rs/zerolog/log"
 	"github.com/xanzy/go-gitlab"
 )
 
 // NewGitLabClient instantiates a wrapper to communicate with GitLab
-// It sets the path and ID of the group under which resources will be managed
-func NewGitLabClient(token string, parentGroupName string) (*Wrapper, error) {
-	git, err := gitlab.NewClient(token)
+// It sets the path and ID of the group under which resources will be managed.
+// A self-managed instance is used when the provided git server has a base url.
+func NewGitLabClient(token string, parentGroupName string, server pkgtypes.GitServer) (*Wrapper, error) {
+	httpClient, err := gitHost.HTTPClient(server)
+	if err != nil {
+		return nil, fmt.Errorf("error creating gitlab http client: %w", err)
+	}
+
+	git, err := gitlab.NewClient(token, gitlab.WithBaseURL(gitHost.APIURL("gitlab", server)), gitlab.WithHTTPClient(httpClient))
 	if err != nil {
 		return nil, fmt.Errorf("error instantiating gitlab client: %w", err)
 	}
@@ -161,6 +169,67 @@ func (gl *Wrapper) GetSubGroups() ([]gitlab.Group, error) {
 	return container, nil
 }
 
+// DeleteProject removes a project from the parent group
+func (gl *Wrapper) DeleteProject(projectName string) error {
+	projectID, err := gl.GetProjectID(projectName)
+	if err != nil {
+		return fmt.Errorf("could not get project ID for project %s: %w", projectName, err)
+	}
+
+	_, err = gl.Client.Projects.DeleteProject(projectID)
+	if err != nil {
+		return fmt.Errorf("could not delete project %s: %w", projectName, err)
+	}
+
+	log.Info().Msgf("deleted gitlab project %s/%s", gl.ParentGroupPath, projectName)
+	return nil
+}
+
+// DeleteSubGroup removes a subgroup of the parent group by name
+func (gl *Wrapper) DeleteSubGroup(subgroupName string) error {
+	subgroups, err := gl.GetSubGroups()
+	if err != nil {
+		return err
+	}
+
+	for _, sg := range subgroups {
+		if sg.Name != subgroupName {
+			continue
+		}
+		_, err := gl.Client.Groups.DeleteGroup(sg.ID)
+		if err != nil {
+			return fmt.Errorf("could not delete subgroup %s: %w", subgroupName, err)
+		}
+		log.Info().Msgf("deleted gitlab subgroup %s/%s", gl.ParentGroupPath, subgroupName)
+		return nil
+	}
+
+	return nil
+}
+
+// Merge Requests
+
+// CreateMergeRequest opens a merge request in a project and returns its web url
+func (gl *Wrapper) CreateMergeRequest(projectName, sourceBranch, targetBranch, title, description string) (string, error) {
+	projectID, err := gl.GetProjectID(projectName)
+	if err != nil {
+		return "", fmt.Errorf("could not get project ID for project %s: %w", projectName, err)
+	}
+
+	mr, _, err := gl.Client.MergeRequests.CreateMergeRequest(projectID, &gitlab.CreateMergeRequestOptions{
+		Title:        &title,
+		Description:  &description,
+		SourceBranch: &sourceBranch,
+		TargetBranch: &targetBranch,
+	})
+	if err != nil {
+		return "", fmt.Errorf("could not create merge request for project %s: %w", projectName, err)
+	}
+
+	log.Info().Msgf("created merge request %s", mr.WebURL)
+	return mr.WebURL, nil
+}
+
 // User Management
 
 // AddUserSSHKey
@@ -407,6 +476,31 @@ func (gl *Wrapper) ListProjectDeployTokens(projectName string) ([]gitlab.DeployT
 	return container, nil
 }
 
+// CreateProjectWebhook adds a webhook to a project. The token is sent by
+// gitlab in the X-Gitlab-Token header of every delivery.
+func (gl *Wrapper) CreateProjectWebhook(projectName, url, token string, pushEvents, mergeRequestsEvents, noteEvents bool) error {
+	projectID, err := gl.GetProjectID(projectName)
+	if err != nil {
+		return fmt.Errorf("could not get project ID for project %s: %w", projectName, err)
+	}
+
+	enableSSLVerification := true
+	_, _, err = gl.Client.Projects.AddProjectHook(projectID, &gitlab.AddProjectHookOptions{
+		URL:                   &url,
+		Token:                 &token,
+		PushEvents:            &pushEvents,
+		MergeRequestsEvents:   &mergeRequestsEvents,
+		NoteEvents:            &noteEvents,
+		EnableSSLVerification: &enableSSLVerification,
+	})
+	if err != nil {
+		return fmt.Errorf("could not create project webhook %s: %w", url, err)
+	}
+
+	log.Info().Msgf("created hook %s/%s", projectName, url)
+	return nil
+}
+
 // DeleteProjectWebhook
 func (gl *Wrapper) DeleteProjectWebhook(projectName string, url string) error {
 	projectID, err := gl.GetProjectID(projectName)
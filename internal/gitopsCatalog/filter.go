@@ -0,0 +1,104 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package gitopsCatalog //nolint:revive,stylecheck // temporary allowing during code organization
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/konstructio/kubefirst-api/pkg/types"
+	"golang.org/x/exp/slices"
+)
+
+// AppFilter describes the server side filters applied to the gitops catalog
+type AppFilter struct {
+	// Category matches the app category, case insensitive
+	Category string
+	// Search matches a substring of the app display name or description, case insensitive
+	Search string
+	// Installed, when set, keeps only apps whose installed state matches
+	Installed *bool
+	// InstalledApps lists the names of the apps installed on the cluster
+	InstalledApps []string
+}
+
+// FilterApps returns the apps matching every filter that is set, preserving catalog order
+func FilterApps(apps []types.GitopsCatalogApp, filter AppFilter) []types.GitopsCatalogApp {
+	search := strings.ToLower(strings.TrimSpace(filter.Search))
+	filtered := []types.GitopsCatalogApp{}
+
+	for _, app := range apps {
+		if filter.Category != "" && !strings.EqualFold(app.Category, filter.Category) {
+			continue
+		}
+
+		if search != "" &&
+			!strings.Contains(strings.ToLower(app.DisplayName), search) &&
+			!strings.Contains(strings.ToLower(app.Description), search) {
+			continue
+		}
+
+		if filter.Installed != nil && slices.Contains(filter.InstalledApps, app.Name) != *filter.Installed {
+			continue
+		}
+
+		filtered = append(filtered, app)
+	}
+
+	return filtered
+}
+
+// Paginate returns a single page of apps. Pages start at 1, and a page size of 0
+// returns every app on the first page.
+func Paginate(apps []types.GitopsCatalogApp, page, pageSize int) []types.GitopsCatalogApp {
+	if pageSize <= 0 {
+		if page <= 1 {
+			return apps
+		}
+		return []types.GitopsCatalogApp{}
+	}
+
+	if page < 1 {
+		page = 1
+	}
+
+	start := (page - 1) * pageSize
+	if start >= len(apps) {
+		return []types.GitopsCatalogApp{}
+	}
+
+	end := start + pageSize
+	if end > len(apps) {
+		end = len(apps)
+	}
+
+	return apps[start:end]
+}
+
+// Categories counts the apps in each category, sorted by category name. Categories
+// are grouped case insensitively to match the category filter, keeping the first
+// spelling found in the catalog.
+func Categories(apps []types.GitopsCatalogApp) []types.GitopsCatalogCategory {
+	index := map[string]int{}
+	categories := []types.GitopsCatalogCategory{}
+
+	for _, app := range apps {
+		key := strings.ToLower(app.Category)
+		if i, ok := index[key]; ok {
+			categories[i].Count++
+			continue
+		}
+		index[key] = len(categories)
+		categories = append(categories, types.GitopsCatalogCategory{Name: app.Category, Count: 1})
+	}
+
+	sort.Slice(categories, func(i, j int) bool {
+		return strings.ToLower(categories[i].Name) < strings.ToLower(categories[j].Name)
+	})
+
+	return categories
+}
@@ -0,0 +1,84 @@
+package gitopsCatalog //nolint:revive,stylecheck // temporary allowing during code organization
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/konstructio/kubefirst-api/pkg/types"
+)
+
+var testApps = []types.GitopsCatalogApp{
+	{Name: "cert-manager", DisplayName: "Cert Manager", Category: "Security", Description: "Certificate management"},
+	{Name: "postgres", DisplayName: "Postgres Operator", Category: "Database", Description: "Manage PostgreSQL clusters"},
+	{Name: "vault-secrets", DisplayName: "Vault Secrets", Category: "security", Description: "Sync secrets"},
+	{Name: "redis", DisplayName: "Redis", Category: "Database", Description: "In-memory store"},
+}
+
+func appNames(apps []types.GitopsCatalogApp) []string {
+	names := []string{}
+	for _, app := range apps {
+		names = append(names, app.Name)
+	}
+	return names
+}
+
+func TestFilterApps(t *testing.T) {
+	installed, notInstalled := true, false
+
+	tests := []struct {
+		name   string
+		filter AppFilter
+		want   []string
+	}{
+		{name: "no filter", filter: AppFilter{}, want: []string{"cert-manager", "postgres", "vault-secrets", "redis"}},
+		{name: "category", filter: AppFilter{Category: "security"}, want: []string{"cert-manager", "vault-secrets"}},
+		{name: "search description", filter: AppFilter{Search: "postgresql"}, want: []string{"postgres"}},
+		{name: "search display name", filter: AppFilter{Search: "REDIS"}, want: []string{"redis"}},
+		{name: "installed", filter: AppFilter{Installed: &installed, InstalledApps: []string{"redis"}}, want: []string{"redis"}},
+		{name: "not installed", filter: AppFilter{Category: "Database", Installed: &notInstalled, InstalledApps: []string{"redis"}}, want: []string{"postgres"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := appNames(FilterApps(testApps, tt.filter))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	tests := []struct {
+		name     string
+		page     int
+		pageSize int
+		want     []string
+	}{
+		{name: "all apps", page: 1, pageSize: 0, want: []string{"cert-manager", "postgres", "vault-secrets", "redis"}},
+		{name: "first page", page: 1, pageSize: 3, want: []string{"cert-manager", "postgres", "vault-secrets"}},
+		{name: "last page", page: 2, pageSize: 3, want: []string{"redis"}},
+		{name: "past the end", page: 3, pageSize: 3, want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := appNames(Paginate(testApps, tt.page, tt.pageSize))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCategories(t *testing.T) {
+	want := []types.GitopsCatalogCategory{
+		{Name: "Database", Count: 2},
+		{Name: "Security", Count: 2},
+	}
+
+	got := Categories(testApps)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
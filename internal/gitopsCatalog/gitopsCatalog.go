@@ -9,6 +9,7 @@ package gitopsCatalog //nolint:revive,stylecheck // temporary allowing during co
 import (
 	"fmt"
 
+	"github.com/konstructio/kubefirst-api/internal/catalogMirror"
 	"github.com/konstructio/kubefirst-api/internal/gitShim"
 	"github.com/konstructio/kubefirst-api/pkg/types"
 	"gopkg.in/yaml.v2"
@@ -16,18 +17,9 @@ import (
 
 // ReadActiveApplications reads the active upstream application manifest
 func ReadActiveApplications() (types.GitopsCatalogApps, error) {
-	gh := gitShim.GitHubClient{
-		Client: gitShim.NewGitHub(),
-	}
-
-	activeContent, err := gh.ReadGitopsCatalogRepoContents()
-	if err != nil {
-		return types.GitopsCatalogApps{}, fmt.Errorf("error retrieving gitops catalog repository content: %w", err)
-	}
-
-	index, err := gh.ReadGitopsCatalogIndex(activeContent)
+	index, err := readIndex()
 	if err != nil {
-		return types.GitopsCatalogApps{}, fmt.Errorf("error retrieving gitops catalog index content: %w", err)
+		return types.GitopsCatalogApps{}, err
 	}
 
 	var out types.GitopsCatalogApps
@@ -42,6 +34,14 @@ func ReadActiveApplications() (types.GitopsCatalogApps, error) {
 
 // ReadApplicationDirectory reads a gitops catalog application's directory
 func ReadApplicationDirectory(applicationName string) ([][]byte, error) {
+	if catalogMirror.Enabled() {
+		contents, err := catalogMirror.ReadApplicationDirectory(applicationName)
+		if err != nil {
+			return [][]byte{}, fmt.Errorf("error retrieving gitops catalog app directory content: %w", err)
+		}
+		return contents, nil
+	}
+
 	gh := gitShim.GitHubClient{
 		Client: gitShim.NewGitHub(),
 	}
@@ -58,3 +58,31 @@ func ReadApplicationDirectory(applicationName string) ([][]byte, error) {
 
 	return contents, nil
 }
+
+// readIndex reads the catalog index from the configured mirror, or from the
+// upstream GitHub repository when no mirror is configured
+func readIndex() ([]byte, error) {
+	if catalogMirror.Enabled() {
+		index, err := catalogMirror.ReadIndex()
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving gitops catalog index content: %w", err)
+		}
+		return index, nil
+	}
+
+	gh := gitShim.GitHubClient{
+		Client: gitShim.NewGitHub(),
+	}
+
+	activeContent, err := gh.ReadGitopsCatalogRepoContents()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving gitops catalog repository content: %w", err)
+	}
+
+	index, err := gh.ReadGitopsCatalogIndex(activeContent)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving gitops catalog index content: %w", err)
+	}
+
+	return index, nil
+}
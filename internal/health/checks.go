@@ -0,0 +1,212 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/konstructio/kubefirst-api/internal/constants"
+	"github.com/konstructio/kubefirst-api/internal/httpCommon"
+	"github.com/konstructio/kubefirst-api/internal/secrets"
+	"github.com/konstructio/kubefirst-api/internal/shutdown"
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// kubefirstNamespace holds the cluster records and the gitops catalog
+const kubefirstNamespace = "kubefirst"
+
+// secretVerbs are the secret permissions the API needs in the kubefirst namespace
+var secretVerbs = []string{"get", "list", "create", "update", "delete"}
+
+// KubernetesChecks checks the kubernetes api and the permissions of the API in
+// the kubefirst namespace
+func KubernetesChecks(clientset kubernetes.Interface) []Check {
+	return []Check{
+		{
+			Name:     "kubernetes",
+			Critical: true,
+			Run: func(context.Context) error {
+				if _, err := clientset.Discovery().ServerVersion(); err != nil {
+					return fmt.Errorf("kubernetes api unreachable: %w", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name:     "kubernetes/permissions",
+			Critical: true,
+			Run: func(ctx context.Context) error {
+				return checkSecretPermissions(ctx, clientset)
+			},
+		},
+	}
+}
+
+// CatalogCheck checks the gitops catalog secret can be read. It is not critical,
+// only the catalog routes need the secret and the catalog updater restores it.
+func CatalogCheck(clientset kubernetes.Interface) Check {
+	return Check{
+		Name: "gitops-catalog",
+		Run: func(context.Context) error {
+			if _, err := secrets.GetGitopsCatalogApps(clientset); err != nil {
+				return fmt.Errorf("unable to read the gitops catalog secret: %w", err)
+			}
+			return nil
+		},
+	}
+}
+
+// clusterCheckInterval is how long the outcome of a cluster check is reused, so
+// that probes do not call every provisioned cluster on each request
+const clusterCheckInterval = time.Minute
+
+// ClusterChecks checks vault and argo cd of the provisioned clusters. They are
+// not critical, the API keeps serving the other clusters when one is down. Their
+// outcome is reused for clusterCheckInterval.
+func ClusterChecks(clusters []pkgtypes.Cluster) []Check {
+	client := httpCommon.CustomHTTPClient(false, checkTimeout)
+
+	var checks []Check
+	for _, cluster := range clusters {
+		if cluster.Status != constants.ClusterStatusProvisioned {
+			continue
+		}
+
+		domain := cluster.DomainName
+		if cluster.SubdomainName != "" {
+			domain = cluster.SubdomainName + "." + cluster.DomainName
+		}
+
+		checks = append(checks,
+			cached(Check{
+				Name: fmt.Sprintf("cluster/%s/vault", cluster.ClusterName),
+				Run: func(ctx context.Context) error {
+					// standby and performance standby nodes answer 429 and 473
+					return checkEndpoint(ctx, client, "https://vault."+domain+"/v1/sys/health", http.StatusOK, http.StatusTooManyRequests, 473)
+				},
+			}, clusterCheckInterval),
+			cached(Check{
+				Name: fmt.Sprintf("cluster/%s/argocd", cluster.ClusterName),
+				Run: func(ctx context.Context) error {
+					return checkEndpoint(ctx, client, "https://argocd."+domain+"/healthz", http.StatusOK)
+				},
+			}, clusterCheckInterval),
+		)
+	}
+
+	return checks
+}
+
+type cachedOutcome struct {
+	err error
+	at  time.Time
+}
+
+var (
+	cacheMu sync.Mutex
+	cache   = map[string]cachedOutcome{}
+)
+
+// cached returns check reusing its last outcome for interval. Checks cut short
+// by the request going away are not cached.
+func cached(check Check, interval time.Duration) Check {
+	run := check.Run
+	check.Run = func(ctx context.Context) error {
+		cacheMu.Lock()
+		last, ok := cache[check.Name]
+		cacheMu.Unlock()
+		if ok && time.Since(last.at) < interval {
+			return last.err
+		}
+
+		err := run(ctx)
+		if ctx.Err() == nil || err == nil {
+			cacheMu.Lock()
+			cache[check.Name] = cachedOutcome{err: err, at: time.Now()}
+			cacheMu.Unlock()
+		}
+		return err
+	}
+	return check
+}
+
+// checkSecretPermissions reviews the secret permissions of the API in the
+// kubefirst namespace
+func checkSecretPermissions(ctx context.Context, clientset kubernetes.Interface) error {
+	var denied []string
+	for _, verb := range secretVerbs {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace: kubefirstNamespace,
+					Verb:      verb,
+					Resource:  "secrets",
+				},
+			},
+		}
+
+		res, err := clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("unable to review permissions: %w", err)
+		}
+		if !res.Status.Allowed {
+			denied = append(denied, verb)
+		}
+	}
+
+	if len(denied) > 0 {
+		return fmt.Errorf("not allowed to %s secrets in namespace %s", strings.Join(denied, ", "), kubefirstNamespace)
+	}
+
+	return nil
+}
+
+// checkEndpoint requests url and expects one of the status codes
+func checkEndpoint(ctx context.Context, client *http.Client, url string, codes ...int) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s unreachable: %w", url, err)
+	}
+	defer res.Body.Close()
+
+	for _, code := range codes {
+		if res.StatusCode == code {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%s answered %d", url, res.StatusCode)
+}
+
+// ShutdownCheck fails once the API is shutting down, for it to be taken out of
+// the service endpoints while running operations drain
+func ShutdownCheck() Check {
+	return Check{
+		Name:     "shutdown",
+		Critical: true,
+		Run: func(context.Context) error {
+			if shutdown.Draining() {
+				return errors.New("the API is shutting down")
+			}
+			return nil
+		},
+	}
+}
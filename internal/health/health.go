@@ -0,0 +1,91 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Component and report statuses
+const (
+	StatusOK       = "ok"
+	StatusDegraded = "degraded"
+	StatusFailed   = "failed"
+)
+
+// checkTimeout bounds every single check so a probe answers in time
+const checkTimeout = 5 * time.Second
+
+// Check is the check of a single component. A failed critical check fails the
+// report, a failed non critical check only degrades it.
+type Check struct {
+	Name     string
+	Critical bool
+	Run      func(ctx context.Context) error
+}
+
+// ComponentStatus is the outcome of a check
+type ComponentStatus struct {
+	Status   string `json:"status" example:"ok"`
+	Critical bool   `json:"critical"`
+	Message  string `json:"message,omitempty"`
+}
+
+// Report is the outcome of a set of checks
+type Report struct {
+	Status     string                     `json:"status" example:"ok"`
+	Components map[string]ComponentStatus `json:"components"`
+}
+
+// Run runs the checks concurrently and reports their outcome
+func Run(ctx context.Context, checks []Check) Report {
+	report := Report{Status: StatusOK, Components: make(map[string]ComponentStatus, len(checks))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range checks {
+		wg.Add(1)
+		go func(check Check) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+			defer cancel()
+
+			status := ComponentStatus{Status: StatusOK, Critical: check.Critical}
+			if err := check.Run(ctx); err != nil {
+				status.Status = StatusFailed
+				status.Message = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Components[check.Name] = status
+			switch {
+			case status.Status == StatusOK:
+			case check.Critical:
+				report.Status = StatusFailed
+			case report.Status == StatusOK:
+				report.Status = StatusDegraded
+			}
+		}(check)
+	}
+	wg.Wait()
+
+	return report
+}
+
+// HTTPStatus is the response code of the report, a degraded report is still
+// served with 200 so probes only act on critical failures
+func (r Report) HTTPStatus() int {
+	if r.Status == StatusFailed {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusOK
+}
@@ -0,0 +1,99 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	ok := func(context.Context) error { return nil }
+	fail := func(context.Context) error { return errors.New("unreachable") }
+
+	tests := []struct {
+		name       string
+		checks     []Check
+		wantStatus string
+		wantCode   int
+	}{
+		{name: "all ok", checks: []Check{{Name: "kubernetes", Critical: true, Run: ok}, {Name: "vault", Run: ok}}, wantStatus: StatusOK, wantCode: http.StatusOK},
+		{name: "non critical failure", checks: []Check{{Name: "kubernetes", Critical: true, Run: ok}, {Name: "vault", Run: fail}}, wantStatus: StatusDegraded, wantCode: http.StatusOK},
+		{name: "critical failure", checks: []Check{{Name: "kubernetes", Critical: true, Run: fail}, {Name: "vault", Run: fail}}, wantStatus: StatusFailed, wantCode: http.StatusServiceUnavailable},
+		{name: "no checks", wantStatus: StatusOK, wantCode: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Run(context.Background(), tt.checks)
+			if report.Status != tt.wantStatus {
+				t.Errorf("Run() status = %q, want %q", report.Status, tt.wantStatus)
+			}
+			if code := report.HTTPStatus(); code != tt.wantCode {
+				t.Errorf("HTTPStatus() = %d, want %d", code, tt.wantCode)
+			}
+			if len(report.Components) != len(tt.checks) {
+				t.Errorf("Run() reported %d components, want %d", len(report.Components), len(tt.checks))
+			}
+		})
+	}
+}
+
+func TestWorkerChecks(t *testing.T) {
+	Beat("running", time.Minute)
+	Beat("stuck", time.Minute)
+	workers["stuck"].lastBeat = time.Now().Add(-time.Hour)
+	Beat("stopped", time.Minute)
+	workers["stopped"].lastBeat = time.Now().Add(-time.Hour)
+	Done("stopped")
+
+	report := Run(context.Background(), WorkerChecks())
+	want := map[string]string{
+		"worker/running": StatusOK,
+		"worker/stuck":   StatusFailed,
+		"worker/stopped": StatusOK,
+	}
+	for name, status := range want {
+		if got := report.Components[name].Status; got != status {
+			t.Errorf("%s status = %q, want %q", name, got, status)
+		}
+	}
+	if report.HTTPStatus() != http.StatusServiceUnavailable {
+		t.Errorf("HTTPStatus() = %d, want %d", report.HTTPStatus(), http.StatusServiceUnavailable)
+	}
+}
+
+func TestCached(t *testing.T) {
+	runs := 0
+	check := cached(Check{Name: "cached/vault", Run: func(context.Context) error {
+		runs++
+		return errors.New("unreachable")
+	}}, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		if err := check.Run(context.Background()); err == nil {
+			t.Fatal("expected the cached error")
+		}
+	}
+	if runs != 1 {
+		t.Errorf("expected the check to run once within the interval, ran %d times", runs)
+	}
+
+	cacheMu.Lock()
+	last := cache["cached/vault"]
+	last.at = time.Now().Add(-2 * time.Minute)
+	cache["cached/vault"] = last
+	cacheMu.Unlock()
+
+	_ = check.Run(context.Background())
+	if runs != 2 {
+		t.Errorf("expected the check to run again once the interval passed, ran %d times", runs)
+	}
+}
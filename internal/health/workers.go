@@ -0,0 +1,87 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// workerGrace is the delay allowed past the interval of a worker before it is
+// considered stuck
+const workerGrace = time.Minute
+
+// Background workers reporting their liveness
+const (
+	WorkerGitopsCatalog = "gitops-catalog"
+	WorkerHeartbeat     = "heartbeat"
+)
+
+type worker struct {
+	interval time.Duration
+	lastBeat time.Time
+	done     bool
+}
+
+var (
+	workersMu sync.Mutex
+	workers   = map[string]*worker{}
+)
+
+// Beat records a run of a background worker expected to run every interval
+func Beat(name string, interval time.Duration) {
+	workersMu.Lock()
+	defer workersMu.Unlock()
+
+	workers[name] = &worker{interval: interval, lastBeat: time.Now()}
+}
+
+// Done marks a background worker that stopped on purpose
+func Done(name string) {
+	workersMu.Lock()
+	defer workersMu.Unlock()
+
+	if w, ok := workers[name]; ok {
+		w.done = true
+	}
+}
+
+// WorkerChecks returns a check of every background worker that reported, a
+// worker fails once it misses its interval
+func WorkerChecks() []Check {
+	workersMu.Lock()
+	defer workersMu.Unlock()
+
+	names := make([]string, 0, len(workers))
+	for name := range workers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	checks := make([]Check, 0, len(names))
+	for _, name := range names {
+		w := *workers[name]
+		checks = append(checks, Check{
+			Name:     "worker/" + name,
+			Critical: true,
+			Run: func(context.Context) error {
+				if w.done {
+					return nil
+				}
+				if since := time.Since(w.lastBeat); since > w.interval+workerGrace {
+					return fmt.Errorf("last run %s ago, expected every %s", since.Round(time.Second), w.interval)
+				}
+				return nil
+			},
+		})
+	}
+
+	return checks
+}
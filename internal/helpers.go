@@ -19,10 +19,8 @@ import (
 	"strings"
 	"time"
 
-	"github.com/konstructio/kubefirst-api/configs"
 	"github.com/konstructio/kubefirst-api/internal/progressPrinter"
 	"github.com/rs/zerolog/log"
-	"github.com/spf13/viper"
 )
 
 func CreateDirIfNotExist(dir string) error {
@@ -48,37 +46,6 @@ func RemoveSubdomainV2(domainName string) (string, error) {
 	return domainName, nil
 }
 
-// SetupViper handles Viper config file. If config file doesn't exist, create, in case the file is available, use it.
-func SetupViper(config *configs.Config, silent bool) error {
-	viperConfigFile := config.KubefirstConfigFilePath
-
-	if _, err := os.Stat(viperConfigFile); errors.Is(err, os.ErrNotExist) {
-		if !silent {
-			log.Info().Msgf("Config file not found, creating a blank one: %s", viperConfigFile)
-		}
-
-		if err := os.WriteFile(viperConfigFile, []byte(""), 0o600); err != nil {
-			return fmt.Errorf("unable to create blank config file, error is: %w", err)
-		}
-	}
-
-	viper.SetConfigFile(viperConfigFile)
-	viper.SetConfigType("yaml")
-	viper.AutomaticEnv() // read in environment variables that match
-
-	// if a config file is found, read it in.
-	err := viper.ReadInConfig()
-	if err != nil {
-		return fmt.Errorf("unable to read config file, error is: %w", err)
-	}
-
-	if !silent {
-		log.Info().Msgf("Using config file: %s", viper.ConfigFileUsed())
-	}
-
-	return nil
-}
-
 // CreateFile - Create a file with its contents
 func CreateFile(fileName string, fileContent []byte) error {
 	file, err := os.Create(fileName)
@@ -275,7 +242,7 @@ func ReplaceFileContent(filPath string, oldContent string, newContent string) er
 // UpdateTerraformS3BackendForK8sAddress during the installation process, Terraform must reach port-forwarded resources
 // to be able to communicate with the services. When Kubefirst finish the installation, and Terraform needs to
 // communicate with the services, it must use the internal Kubernetes addresses.
-func UpdateTerraformS3BackendForK8sAddress(k1Dir string) error {
+func UpdateTerraformS3BackendForK8sAddress(k1Dir, gitProvider string) error {
 	// todo: create a function for file content replacement
 	vaultMainFile := fmt.Sprintf("%s/gitops/terraform/vault/main.tf", k1Dir)
 	if err := ReplaceFileContent(
@@ -287,7 +254,7 @@ func UpdateTerraformS3BackendForK8sAddress(k1Dir string) error {
 	}
 
 	// update GitHub Terraform content
-	if viper.GetString("git-provider") == "github" {
+	if gitProvider == "github" {
 		fullPathKubefirstGitHubFile := fmt.Sprintf("%s/gitops/terraform/users/kubefirst-github.tf", k1Dir)
 		if err := ReplaceFileContent(
 			fullPathKubefirstGitHubFile,
@@ -313,14 +280,9 @@ func UpdateTerraformS3BackendForK8sAddress(k1Dir string) error {
 
 // UpdateTerraformS3BackendForLocalhostAddress during the destroy process, Terraform must reach port-forwarded resources
 // to be able to communicate with the services.
-func UpdateTerraformS3BackendForLocalhostAddress() error {
-	config, err := configs.ReadConfig()
-	if err != nil {
-		return fmt.Errorf("unable to read config file: %w", err)
-	}
-
+func UpdateTerraformS3BackendForLocalhostAddress(k1Dir, gitProvider string) error {
 	// todo: create a function for file content replacement
-	vaultMainFile := fmt.Sprintf("%s/gitops/terraform/vault/main.tf", config.K1FolderPath)
+	vaultMainFile := fmt.Sprintf("%s/gitops/terraform/vault/main.tf", k1Dir)
 	if err := ReplaceFileContent(
 		vaultMainFile,
 		"http://minio.minio.svc.cluster.local:9000",
@@ -329,10 +291,9 @@ func UpdateTerraformS3BackendForLocalhostAddress() error {
 		return err
 	}
 
-	gitProvider := viper.GetString("git-provider")
 	// update GitHub Terraform content
 	if gitProvider == "github" {
-		fullPathKubefirstGitHubFile := fmt.Sprintf("%s/gitops/terraform/users/kubefirst-github.tf", config.K1FolderPath)
+		fullPathKubefirstGitHubFile := fmt.Sprintf("%s/gitops/terraform/users/kubefirst-github.tf", k1Dir)
 		if err := ReplaceFileContent(
 			fullPathKubefirstGitHubFile,
 			"http://minio.minio.svc.cluster.local:9000",
@@ -342,7 +303,7 @@ func UpdateTerraformS3BackendForLocalhostAddress() error {
 		}
 
 		// change remote-backend.tf
-		fullPathRemoteBackendFile := fmt.Sprintf("%s/gitops/terraform/github/remote-backend.tf", config.K1FolderPath)
+		fullPathRemoteBackendFile := fmt.Sprintf("%s/gitops/terraform/github/remote-backend.tf", k1Dir)
 		if err := ReplaceFileContent(
 			fullPathRemoteBackendFile,
 			"http://minio.minio.svc.cluster.local:9000",
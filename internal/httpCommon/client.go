@@ -7,22 +7,15 @@ See the LICENSE file for more details.
 package httpCommon //nolint:revive,stylecheck // allowed during code reorg
 
 import (
-	"crypto/tls"
 	"fmt"
 	"io"
 	"net/http"
 	"time"
 )
 
-// CustomHTTPClient - creates a http client based on k1 standards
+// CustomHTTPClient - creates a http client based on k1 standards and the outbound configuration
 // allowInsecure defines: tls.Config{InsecureSkipVerify: allowInsecure}
 func CustomHTTPClient(allowInsecure bool, conntimeout ...time.Duration) *http.Client {
-	//nolint:forcetypeassert // we are cloning the default transport
-	customTransport := http.DefaultTransport.(*http.Transport).Clone()
-
-	//nolint:gosec // allowInsecure is a user input and something we want to allow
-	customTransport.TLSClientConfig = &tls.Config{InsecureSkipVerify: allowInsecure}
-
 	timeout := 90 * time.Minute
 	if len(conntimeout) > 0 {
 		timeout = conntimeout[0]
@@ -33,7 +26,8 @@ func CustomHTTPClient(allowInsecure bool, conntimeout ...time.Duration) *http.Cl
 			TLSHandshakeTimeout:   10 * time.Second,
 			ResponseHeaderTimeout: 10 * time.Second,
 			ExpectContinueTimeout: 1 * time.Second,
-			TLSClientConfig:       customTransport.TLSClientConfig,
+			TLSClientConfig:       TLSConfig(allowInsecure),
+			Proxy:                 Proxy,
 		},
 		Timeout: timeout,
 	}
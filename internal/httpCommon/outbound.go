@@ -0,0 +1,308 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package httpCommon //nolint:revive,stylecheck // allowed during code reorg
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/net/http/httpproxy"
+)
+
+// Outbound is the configuration of every outbound connection the API makes
+type Outbound struct {
+	// ProxyURL is the proxy for http and https requests, the standard proxy
+	// environment variables are used when empty
+	ProxyURL string
+	// NoProxy lists the hosts, domains and CIDRs reached without the proxy
+	NoProxy string
+	// CABundle is a PEM file of certificate authorities trusted in addition
+	// to the system ones
+	CABundle string
+	// InsecureHosts are the hosts whose certificates are not verified. Hosts
+	// are matched on the tls server name, so they must be dns names.
+	InsecureHosts []string
+	// HostCABundles are PEM files of certificate authorities trusted only
+	// for a given host
+	HostCABundles map[string]string
+}
+
+// hostTLS is the tls configuration of a single host
+type hostTLS struct {
+	insecure bool
+	roots    *x509.CertPool
+}
+
+// outboundState is the resolved outbound configuration
+type outboundState struct {
+	proxy func(*url.URL) (*url.URL, error)
+	roots *x509.CertPool
+	hosts map[string]hostTLS
+}
+
+// caBundleCandidates are the system bundles extended with the configured
+// certificate authorities for sdks and subprocesses
+var caBundleCandidates = []string{
+	"/etc/ssl/certs/ca-certificates.crt",
+	"/etc/pki/tls/certs/ca-bundle.crt",
+	"/etc/ssl/ca-bundle.pem",
+	"/etc/pki/ca-trust/extracted/pem/tls-ca-bundle.pem",
+	"/etc/ssl/cert.pem",
+}
+
+var (
+	outboundMu sync.RWMutex
+	outbound   = &outboundState{proxy: httpproxy.FromEnvironment().ProxyFunc()}
+)
+
+// ConfigureOutbound applies the outbound configuration to the clients built by
+// this package and to http.DefaultTransport, which the git and most cloud sdk
+// clients use. The proxy and the certificate authorities are also exported to
+// the environment so sdks building their own transports and subprocesses such
+// as terraform pick them up. It must run before any outbound request is made.
+func ConfigureOutbound(o Outbound) error {
+	state := &outboundState{hosts: map[string]hostTLS{}}
+
+	proxyConfig := httpproxy.FromEnvironment()
+	if o.ProxyURL != "" {
+		if u, err := url.Parse(o.ProxyURL); err != nil || u.Host == "" {
+			return fmt.Errorf("invalid proxy url %q", o.ProxyURL)
+		}
+		proxyConfig.HTTPProxy = o.ProxyURL
+		proxyConfig.HTTPSProxy = o.ProxyURL
+	}
+	if o.NoProxy != "" {
+		proxyConfig.NoProxy = o.NoProxy
+	}
+	state.proxy = proxyConfig.ProxyFunc()
+
+	// exported before the pools are built, as the system pool is loaded once
+	// and must include the configured bundle for clients using it directly
+	if err := exportOutbound(o, proxyConfig); err != nil {
+		return err
+	}
+
+	if o.CABundle != "" {
+		roots, err := certPool(o.CABundle)
+		if err != nil {
+			return err
+		}
+		state.roots = roots
+	}
+
+	for _, host := range o.InsecureHosts {
+		state.hosts[strings.ToLower(host)] = hostTLS{insecure: true}
+	}
+	for host, bundle := range o.HostCABundles {
+		roots, err := certPool(o.CABundle, bundle)
+		if err != nil {
+			return err
+		}
+		state.hosts[strings.ToLower(host)] = hostTLS{roots: roots}
+	}
+
+	outboundMu.Lock()
+	outbound = state
+	outboundMu.Unlock()
+
+	//nolint:forcetypeassert // configuring the default transport in place so existing clients pick it up
+	defaultTransport := http.DefaultTransport.(*http.Transport)
+	defaultTransport.Proxy = Proxy
+	defaultTransport.TLSClientConfig = TLSConfig(false)
+
+	if o.ProxyURL != "" || o.CABundle != "" || len(state.hosts) > 0 {
+		log.Info().Msgf("outbound connections configured with proxy %q, ca bundle %q and %d host tls settings", o.ProxyURL, o.CABundle, len(state.hosts))
+	}
+
+	return nil
+}
+
+// Proxy returns the proxy for a request
+func Proxy(req *http.Request) (*url.URL, error) {
+	outboundMu.RLock()
+	proxy := outbound.proxy
+	outboundMu.RUnlock()
+
+	return proxy(req.URL)
+}
+
+// TLSConfig returns the tls configuration of outbound connections. Hosts
+// with their own settings are verified against their own certificate
+// authorities, or not at all when they are configured as insecure.
+func TLSConfig(allowInsecure bool) *tls.Config {
+	outboundMu.RLock()
+	state := outbound
+	outboundMu.RUnlock()
+
+	//nolint:gosec // allowInsecure is a user input and something we want to allow
+	config := &tls.Config{InsecureSkipVerify: allowInsecure, RootCAs: state.roots}
+	if allowInsecure || len(state.hosts) == 0 {
+		return config
+	}
+
+	// the standard verification cannot vary by host, so it is done in
+	// VerifyConnection instead
+	config.InsecureSkipVerify = true
+	config.VerifyConnection = func(cs tls.ConnectionState) error {
+		return verifyConnection(state, cs)
+	}
+
+	return config
+}
+
+// Transport returns an http transport with the outbound configuration for sdk
+// clients that accept one
+func Transport() *http.Transport {
+	//nolint:forcetypeassert // we are cloning the default transport
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = Proxy
+	transport.TLSClientConfig = TLSConfig(false)
+
+	return transport
+}
+
+// TracedTransport returns Transport traced as child spans of the operation in
+// the request context, requests made outside of a traced operation are not
+// traced
+func TracedTransport() http.RoundTripper {
+	return otelhttp.NewTransport(Transport(), otelhttp.WithFilter(func(req *http.Request) bool {
+		return trace.SpanContextFromContext(req.Context()).IsValid()
+	}))
+}
+
+// verifyConnection verifies the certificate chain of a connection against the
+// settings of its host
+func verifyConnection(state *outboundState, cs tls.ConnectionState) error {
+	host := strings.ToLower(cs.ServerName)
+	roots := state.roots
+	if h, ok := state.hosts[host]; ok {
+		if h.insecure {
+			return nil
+		}
+		roots = h.roots
+	}
+
+	if len(cs.PeerCertificates) == 0 {
+		return fmt.Errorf("no certificates presented by %q", host)
+	}
+
+	opts := x509.VerifyOptions{
+		DNSName:       cs.ServerName,
+		Roots:         roots,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, cert := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+
+	if _, err := cs.PeerCertificates[0].Verify(opts); err != nil {
+		return fmt.Errorf("unable to verify certificate of %q: %w", host, err)
+	}
+
+	return nil
+}
+
+// certPool returns the system certificate authorities extended with bundles
+func certPool(bundles ...string) (*x509.CertPool, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+
+	for _, bundle := range bundles {
+		if bundle == "" {
+			continue
+		}
+
+		pem, err := os.ReadFile(bundle)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read ca bundle %q: %w", bundle, err)
+		}
+		if ok := pool.AppendCertsFromPEM(pem); !ok {
+			return nil, fmt.Errorf("ca bundle %q does not contain any valid PEM certificates", bundle)
+		}
+	}
+
+	return pool, nil
+}
+
+// exportOutbound sets the proxy and certificate authority environment
+// variables honoured by sdks and subprocesses
+func exportOutbound(o Outbound, proxyConfig *httpproxy.Config) error {
+	if o.ProxyURL != "" {
+		for _, key := range []string{"HTTP_PROXY", "HTTPS_PROXY", "http_proxy", "https_proxy"} {
+			os.Setenv(key, o.ProxyURL)
+		}
+	}
+	if proxyConfig.NoProxy != "" {
+		os.Setenv("NO_PROXY", proxyConfig.NoProxy)
+		os.Setenv("no_proxy", proxyConfig.NoProxy)
+	}
+
+	if o.CABundle == "" {
+		return nil
+	}
+
+	bundle, err := combinedCABundle(o.CABundle)
+	if err != nil {
+		return err
+	}
+
+	return os.Setenv("SSL_CERT_FILE", bundle)
+}
+
+// combinedCABundle writes the system certificate authorities followed by the
+// configured ones to a single file, as SSL_CERT_FILE replaces the system
+// bundle rather than extending it
+func combinedCABundle(caBundle string) (string, error) {
+	extra, err := os.ReadFile(caBundle)
+	if err != nil {
+		return "", fmt.Errorf("unable to read ca bundle %q: %w", caBundle, err)
+	}
+
+	candidates := caBundleCandidates
+	if current := os.Getenv("SSL_CERT_FILE"); current != "" {
+		candidates = []string{current}
+	}
+
+	var system []byte
+	for _, candidate := range candidates {
+		if system, err = os.ReadFile(candidate); err == nil {
+			break
+		}
+	}
+	if system == nil {
+		log.Warn().Msg("no system ca bundle found, only the configured certificate authorities are exported")
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to get user home directory: %w", err)
+	}
+
+	target := filepath.Join(homeDir, ".k1", "outbound-ca-bundle.pem")
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return "", fmt.Errorf("unable to create directory %q: %w", filepath.Dir(target), err)
+	}
+
+	content := append(append(system, '\n'), extra...)
+	if err := os.WriteFile(target, content, 0o644); err != nil {
+		return "", fmt.Errorf("unable to write ca bundle %q: %w", target, err)
+	}
+
+	return target, nil
+}
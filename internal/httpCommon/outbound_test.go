@@ -0,0 +1,69 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package httpCommon //nolint:revive,stylecheck // allowed during code reorg
+
+import (
+	"context"
+	"encoding/pem"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestOutboundHostTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(bundle, cert, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		outbound Outbound
+		wantErr  bool
+	}{
+		{name: "untrusted host", outbound: Outbound{}, wantErr: true},
+		{name: "insecure host", outbound: Outbound{InsecureHosts: []string{"example.com"}}},
+		{name: "host ca bundle", outbound: Outbound{HostCABundles: map[string]string{"example.com": bundle}}},
+		{name: "ca bundle of another host", outbound: Outbound{HostCABundles: map[string]string{"kubefirst.io": bundle}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ConfigureOutbound(tt.outbound); err != nil {
+				t.Fatalf("ConfigureOutbound() error = %v", err)
+			}
+			defer ConfigureOutbound(Outbound{})
+
+			// the test certificate is valid for example.com, which is dialed
+			// at the test server
+			transport := Transport()
+			transport.Proxy = nil
+			transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, network, srv.Listener.Addr().String())
+			}
+			client := &http.Client{Transport: transport, Timeout: 5 * time.Second}
+
+			resp, err := client.Get("https://example.com") //nolint:noctx // client enforces limits
+			if err == nil {
+				resp.Body.Close()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -69,7 +69,7 @@ type Config struct {
 }
 
 // GetConfig - load default values from kubefirst installer
-func GetConfig(clusterName, gitProvider, gitOwner, gitProtocol string) (*Config, error) {
+func GetConfig(clusterName, gitProvider, gitHost, gitOwner, gitProtocol string) (*Config, error) {
 	config := Config{}
 
 	if err := env.Parse(&config); err != nil {
@@ -84,12 +84,15 @@ func GetConfig(clusterName, gitProvider, gitOwner, gitProtocol string) (*Config,
 	}
 
 	// cGitHost describes which git host to use depending on gitProvider
-	var cGitHost string
-	switch gitProvider {
-	case "github":
-		cGitHost = GithubHost
-	case "gitlab":
-		cGitHost = GitlabHost
+	// when no self-hosted git server is configured
+	cGitHost := gitHost
+	if cGitHost == "" {
+		switch gitProvider {
+		case "github":
+			cGitHost = GithubHost
+		case "gitlab":
+			cGitHost = GitlabHost
+		}
 	}
 
 	config.DestinationGitopsRepoURL = fmt.Sprintf("https://%s/%s/gitops.git", cGitHost, gitOwner)
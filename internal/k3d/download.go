@@ -15,7 +15,7 @@ import (
 )
 
 func DownloadTools(clusterName string, gitProvider string, gitOwner string, toolsDir string, gitProtocol string) error {
-	config, err := GetConfig(clusterName, gitProvider, gitOwner, gitProtocol)
+	config, err := GetConfig(clusterName, gitProvider, "", gitOwner, gitProtocol)
 	if err != nil {
 		return fmt.Errorf("error while trying to get config: %w", err)
 	}
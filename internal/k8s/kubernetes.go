@@ -14,7 +14,6 @@ import (
 	"github.com/rs/zerolog/log"
 
 	pkg "github.com/konstructio/kubefirst-api/internal"
-	"github.com/spf13/viper"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	coreV1Types "k8s.io/client-go/kubernetes/typed/core/v1"
@@ -64,33 +63,27 @@ func GetClientConfig(kubeconfigPath string) (*rest.Config, error) {
 }
 
 func WaitForNamespaceandPods(kubeconfigPath, kubectlClientPath, namespace, podLabel string) {
-	if !viper.GetBool("create.softserve.ready") {
-		x := 50
-		for i := 0; i < x; i++ {
-			_, _, err := pkg.ExecShellReturnStrings(kubectlClientPath, "--kubeconfig", kubeconfigPath, "-n", namespace, "get", fmt.Sprintf("namespace/%s", namespace))
-			if err != nil {
-				log.Info().Msg(fmt.Sprintf("waiting for %s namespace to create ", namespace))
-				time.Sleep(10 * time.Second)
-			} else {
-				log.Info().Msg(fmt.Sprintf("namespace %s found, continuing", namespace))
-				time.Sleep(10 * time.Second)
-				i = 51
-			}
+	x := 50
+	for i := 0; i < x; i++ {
+		_, _, err := pkg.ExecShellReturnStrings(kubectlClientPath, "--kubeconfig", kubeconfigPath, "-n", namespace, "get", fmt.Sprintf("namespace/%s", namespace))
+		if err != nil {
+			log.Info().Msg(fmt.Sprintf("waiting for %s namespace to create ", namespace))
+			time.Sleep(10 * time.Second)
+		} else {
+			log.Info().Msg(fmt.Sprintf("namespace %s found, continuing", namespace))
+			time.Sleep(10 * time.Second)
+			i = 51
 		}
-		for i := 0; i < x; i++ {
-			_, _, err := pkg.ExecShellReturnStrings(kubectlClientPath, "--kubeconfig", kubeconfigPath, "-n", namespace, "get", "pods", "-l", podLabel)
-			if err != nil {
-				log.Info().Msg(fmt.Sprintf("waiting for %s pods to create ", namespace))
-				time.Sleep(10 * time.Second)
-			} else {
-				log.Info().Msg(fmt.Sprintf("%s pods found, continuing", namespace))
-				time.Sleep(10 * time.Second)
-				break
-			}
+	}
+	for i := 0; i < x; i++ {
+		_, _, err := pkg.ExecShellReturnStrings(kubectlClientPath, "--kubeconfig", kubeconfigPath, "-n", namespace, "get", "pods", "-l", podLabel)
+		if err != nil {
+			log.Info().Msg(fmt.Sprintf("waiting for %s pods to create ", namespace))
+			time.Sleep(10 * time.Second)
+		} else {
+			log.Info().Msg(fmt.Sprintf("%s pods found, continuing", namespace))
+			time.Sleep(10 * time.Second)
+			break
 		}
-		viper.Set("create.softserve.ready", true)
-		viper.WriteConfig()
-	} else {
-		log.Info().Msg("soft-serve is ready, skipping")
 	}
 }
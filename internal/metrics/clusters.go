@@ -0,0 +1,63 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package metrics
+
+import (
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	clusterStatusDesc = prometheus.NewDesc(
+		namespace+"_cluster_status",
+		"Status of a stored cluster record, 1 for its current status.",
+		[]string{"cluster_name", "cloud_provider", "status"}, nil,
+	)
+	clusterInProgressDesc = prometheus.NewDesc(
+		namespace+"_cluster_in_progress",
+		"Whether an operation is running on a stored cluster record.",
+		[]string{"cluster_name", "cloud_provider"}, nil,
+	)
+	clusterCertificatesExpiringDesc = prometheus.NewDesc(
+		namespace+"_cluster_certificates_expiring",
+		"Certificates of a cluster flagged as expiring soon.",
+		[]string{"cluster_name", "cloud_provider"}, nil,
+	)
+)
+
+// clusterCollector reads the cluster records on every scrape so the gauges
+// always reflect the stored state
+type clusterCollector struct {
+	list func() ([]pkgtypes.Cluster, error)
+}
+
+func (cc *clusterCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- clusterStatusDesc
+	ch <- clusterInProgressDesc
+	ch <- clusterCertificatesExpiringDesc
+}
+
+func (cc *clusterCollector) Collect(ch chan<- prometheus.Metric) {
+	clusters, err := cc.list()
+	if err != nil {
+		log.Warn().Msgf("unable to list clusters for metrics: %s", err)
+		return
+	}
+
+	for _, cl := range clusters {
+		ch <- prometheus.MustNewConstMetric(clusterStatusDesc, prometheus.GaugeValue, 1, cl.ClusterName, cl.CloudProvider, cl.Status)
+
+		inProgress := 0.0
+		if cl.InProgress {
+			inProgress = 1
+		}
+		ch <- prometheus.MustNewConstMetric(clusterInProgressDesc, prometheus.GaugeValue, inProgress, cl.ClusterName, cl.CloudProvider)
+
+		ch <- prometheus.MustNewConstMetric(clusterCertificatesExpiringDesc, prometheus.GaugeValue, float64(len(cl.ExpiringCertificates)), cl.ClusterName, cl.CloudProvider)
+	}
+}
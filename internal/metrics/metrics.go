@@ -0,0 +1,173 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package metrics
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rs/zerolog/log"
+)
+
+const namespace = "kubefirst_api"
+
+// Operations tracked by the active operations gauge
+const (
+	OperationCreate = "create"
+	OperationDelete = "delete"
+)
+
+// Registry holds every metric exposed on /metrics
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests served, by method, route and status code.",
+	}, []string{"method", "route", "code"})
+
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP requests, by method and route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	provisioningSteps = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "provisioning_steps_total",
+		Help:      "Provisioning steps finished, by provider, step and result.",
+	}, []string{"provider", "step", "result"})
+
+	provisioningStepDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "provisioning_step_duration_seconds",
+		Help:      "Duration of provisioning steps, by provider, step and result.",
+		Buckets:   []float64{1, 5, 15, 30, 60, 120, 300, 600, 1200, 1800, 3600},
+	}, []string{"provider", "step", "result"})
+
+	activeOperations = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_operations",
+		Help:      "Cluster operations running in this process, by operation and provider.",
+	}, []string{"operation", "provider"})
+
+	catalogRefreshes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "gitops_catalog_refresh_total",
+		Help:      "Gitops catalog refreshes, by result.",
+	}, []string{"result"})
+
+	catalogLastSuccess = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "gitops_catalog_last_success_timestamp_seconds",
+		Help:      "Time of the last successful gitops catalog refresh.",
+	})
+)
+
+// stepStarts holds the start time of running provisioning steps, keyed by
+// cluster and step
+var stepStarts sync.Map
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpRequestDuration,
+		provisioningSteps,
+		provisioningStepDuration,
+		activeOperations,
+		catalogRefreshes,
+		catalogLastSuccess,
+	)
+}
+
+// Handler serves the metrics of the registry
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// ObserveRequest records a served HTTP request
+func ObserveRequest(method, route, code string, duration time.Duration) {
+	httpRequests.WithLabelValues(method, route, code).Inc()
+	httpRequestDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+// ObserveStepEvent records the provisioning step marked by a telemetry event
+// such as kubefirst.init.domain_liveness.started. Started events open the step
+// of the cluster, completed and failed events close it and record its duration.
+func ObserveStepEvent(provider, clusterName, event string) {
+	step, result, ok := parseStepEvent(event)
+	if !ok {
+		return
+	}
+
+	key := clusterName + "/" + step
+	if result == "started" {
+		stepStarts.Store(key, time.Now())
+		return
+	}
+
+	provisioningSteps.WithLabelValues(provider, step, result).Inc()
+	if start, ok := stepStarts.LoadAndDelete(key); ok {
+		//nolint:forcetypeassert // only times are stored
+		provisioningStepDuration.WithLabelValues(provider, step, result).Observe(time.Since(start.(time.Time)).Seconds())
+	}
+}
+
+// TrackOperation counts a running cluster operation, the returned function
+// marks it finished
+func TrackOperation(operation, provider string) func() {
+	gauge := activeOperations.WithLabelValues(operation, provider)
+	gauge.Inc()
+	return gauge.Dec
+}
+
+// ObserveCatalogRefresh records the result of a gitops catalog refresh
+func ObserveCatalogRefresh(err error) {
+	if err != nil {
+		catalogRefreshes.WithLabelValues("failure").Inc()
+		return
+	}
+
+	catalogRefreshes.WithLabelValues("success").Inc()
+	catalogLastSuccess.SetToCurrentTime()
+}
+
+// RegisterClusterCollector exposes the status of the stored cluster records,
+// listed by list on every scrape
+func RegisterClusterCollector(list func() ([]pkgtypes.Cluster, error)) {
+	if err := Registry.Register(&clusterCollector{list: list}); err != nil {
+		log.Warn().Msgf("unable to register cluster metrics: %s", err)
+	}
+}
+
+// parseStepEvent splits a telemetry event name into its step and result
+func parseStepEvent(event string) (string, string, bool) {
+	idx := strings.LastIndex(event, ".")
+	if idx < 0 {
+		return "", "", false
+	}
+
+	result := event[idx+1:]
+	switch result {
+	case "started", "completed", "failed":
+	default:
+		return "", "", false
+	}
+
+	name := event[:idx]
+	return name[strings.LastIndex(name, ".")+1:], result, true
+}
@@ -0,0 +1,52 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestParseStepEvent(t *testing.T) {
+	tests := []struct {
+		event  string
+		step   string
+		result string
+		ok     bool
+	}{
+		{event: "kubefirst.init.domain_liveness.started", step: "domain_liveness", result: "started", ok: true},
+		{event: "kubefirst.mgmt.argocd_install.failed", step: "argocd_install", result: "failed", ok: true},
+		{event: "kubefirst.cluster_install.completed", step: "cluster_install", result: "completed", ok: true},
+		{event: "kubefirst.heartbeat", ok: false},
+		{event: "heartbeat", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.event, func(t *testing.T) {
+			step, result, ok := parseStepEvent(tt.event)
+			if ok != tt.ok || step != tt.step || result != tt.result {
+				t.Errorf("parseStepEvent(%q) = %q, %q, %v, want %q, %q, %v", tt.event, step, result, ok, tt.step, tt.result, tt.ok)
+			}
+		})
+	}
+}
+
+func TestObserveStepEvent(t *testing.T) {
+	ObserveStepEvent("civo", "dev", "kubefirst.init.state_store_create.started")
+	ObserveStepEvent("civo", "dev", "kubefirst.init.state_store_create.failed")
+
+	if got := testutil.ToFloat64(provisioningSteps.WithLabelValues("civo", "state_store_create", "failed")); got != 1 {
+		t.Errorf("failed steps = %v, want 1", got)
+	}
+	if got := testutil.CollectAndCount(provisioningStepDuration); got != 1 {
+		t.Errorf("step durations = %v, want 1", got)
+	}
+	if _, ok := stepStarts.Load("dev/state_store_create"); ok {
+		t.Error("finished step is still tracked as running")
+	}
+}
@@ -7,33 +7,30 @@ See the LICENSE file for more details.
 package middleware
 
 import (
-	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/konstructio/kubefirst-api/internal/constants"
+	"github.com/konstructio/kubefirst-api/internal/apierror"
 	"github.com/konstructio/kubefirst-api/internal/env"
 	"github.com/rs/zerolog/log"
 )
 
 // ValidateAPIKey determines whether or not a request is authenticated with a valid API key
-func ValidateAPIKey() gin.HandlerFunc {
+func ValidateAPIKey(cfg *env.Config) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		APIKey := strings.TrimPrefix(c.Request.Header.Get("Authorization"), "Bearer ")
 
 		if APIKey == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"status": 401, "message": "Authentication failed - no API key provided in request"})
-			c.Abort()
+			e := apierror.New(apierror.CodeUnauthorized, "Authentication failed - no API key provided in request")
+			c.AbortWithStatusJSON(e.Status(), e.Response())
 
 			log.Info().Msg(" Request Status: 401;  Authentication failed - no API key provided in request")
 			return
 		}
 
-		env, _ := env.GetEnv(constants.SilenceGetEnv)
-
-		if APIKey != env.K1AccessToken {
-			c.JSON(http.StatusUnauthorized, gin.H{"status": 401, "message": "Authentication failed - not a valid API key"})
-			c.Abort()
+		if APIKey != cfg.Get().K1AccessToken {
+			e := apierror.New(apierror.CodeUnauthorized, "Authentication failed - not a valid API key")
+			c.AbortWithStatusJSON(e.Status(), e.Response())
 
 			log.Info().Msg(" Request Status: 401;  Authentication failed - no API key provided in request")
 			return
@@ -0,0 +1,29 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/konstructio/kubefirst-api/internal/env"
+)
+
+// configKey is the request context key of the API configuration
+const configKey = "kubefirst.config"
+
+// Config hands the API configuration to the handlers of every request
+func Config(cfg *env.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(configKey, cfg)
+		c.Next()
+	}
+}
+
+// GetConfig returns the current API configuration from the context of a request
+func GetConfig(c *gin.Context) env.Env {
+	//nolint:forcetypeassert // only ever set by Config
+	return c.MustGet(configKey).(*env.Config).Get()
+}
@@ -0,0 +1,36 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/konstructio/kubefirst-api/internal/apierror"
+	"github.com/konstructio/kubefirst-api/internal/shutdown"
+)
+
+// RejectWhileDraining rejects mutating requests once the API is shutting down,
+// reads are still served until the server stops
+func RejectWhileDraining() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		if shutdown.Draining() {
+			e := apierror.New(apierror.CodeUnavailable, "the API is shutting down, retry the request")
+			c.Header("Retry-After", "30")
+			c.AbortWithStatusJSON(e.Status(), e.Response())
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -13,7 +13,10 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sort"
+	"strings"
 
+	"github.com/konstructio/kubefirst-api/internal/httpCommon"
 	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
@@ -26,8 +29,9 @@ func PutBucketObject(cr *pkgtypes.StateStoreCredentials, details *pkgtypes.State
 
 	// Initialize minio client object.
 	minioClient, err := minio.New(details.Hostname, &minio.Options{
-		Creds:  credentials.NewStaticV4(cr.AccessKeyID, cr.SecretAccessKey, ""),
-		Secure: true,
+		Creds:     credentials.NewStaticV4(cr.AccessKeyID, cr.SecretAccessKey, ""),
+		Secure:    true,
+		Transport: httpCommon.TracedTransport(),
 	})
 	if err != nil {
 		return fmt.Errorf("error initializing minio client: %w", err)
@@ -59,8 +63,9 @@ func PutClusterObject(cr *pkgtypes.StateStoreCredentials, d *pkgtypes.StateStore
 
 	// Initialize minio client
 	minioClient, err := minio.New(d.Hostname, &minio.Options{
-		Creds:  credentials.NewStaticV4(cr.AccessKeyID, cr.SecretAccessKey, cr.SessionToken),
-		Secure: true,
+		Creds:     credentials.NewStaticV4(cr.AccessKeyID, cr.SecretAccessKey, cr.SessionToken),
+		Secure:    true,
+		Transport: httpCommon.TracedTransport(),
 	})
 	if err != nil {
 		return fmt.Errorf("error initializing minio client: %w", err)
@@ -101,8 +106,9 @@ func GetClusterObject(cr *pkgtypes.StateStoreCredentials, d *pkgtypes.StateStore
 
 	// Initialize minio client
 	minioClient, err := minio.New(d.Hostname, &minio.Options{
-		Creds:  credentials.NewStaticV4(cr.AccessKeyID, cr.SecretAccessKey, cr.SessionToken),
-		Secure: secure,
+		Creds:     credentials.NewStaticV4(cr.AccessKeyID, cr.SecretAccessKey, cr.SessionToken),
+		Secure:    secure,
+		Transport: httpCommon.TracedTransport(),
 	})
 	if err != nil {
 		return fmt.Errorf("error initializing minio client: %w", err)
@@ -140,3 +146,101 @@ func GetClusterObject(cr *pkgtypes.StateStoreCredentials, d *pkgtypes.StateStore
 	log.Info().Msgf("downloaded cluster object %s from state store bucket %s successfully", localFilePath, d.Name)
 	return nil
 }
+
+// ListClusterObjects returns the objects under prefix in the cluster state store
+// bucket, newest first
+func ListClusterObjects(cr *pkgtypes.StateStoreCredentials, d *pkgtypes.StateStoreDetails, prefix string) ([]pkgtypes.BucketObject, error) {
+	ctx := context.Background()
+
+	minioClient, err := minio.New(stateStoreEndpoint(d), &minio.Options{
+		Creds:     credentials.NewStaticV4(cr.AccessKeyID, cr.SecretAccessKey, cr.SessionToken),
+		Secure:    true,
+		Transport: httpCommon.TracedTransport(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error initializing minio client: %w", err)
+	}
+
+	objects := []pkgtypes.BucketObject{}
+	for obj := range minioClient.ListObjects(ctx, d.Name, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if obj.Err != nil {
+			return nil, fmt.Errorf("error listing objects in bucket %q: %w", d.Name, obj.Err)
+		}
+		objects = append(objects, pkgtypes.BucketObject{
+			Key:          obj.Key,
+			Size:         obj.Size,
+			LastModified: obj.LastModified,
+		})
+	}
+
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].LastModified.After(objects[j].LastModified)
+	})
+
+	return objects, nil
+}
+
+// DeleteClusterObject removes an object from the cluster state store bucket
+func DeleteClusterObject(cr *pkgtypes.StateStoreCredentials, d *pkgtypes.StateStoreDetails, remoteFilePath string) error {
+	ctx := context.Background()
+
+	minioClient, err := minio.New(stateStoreEndpoint(d), &minio.Options{
+		Creds:     credentials.NewStaticV4(cr.AccessKeyID, cr.SecretAccessKey, cr.SessionToken),
+		Secure:    true,
+		Transport: httpCommon.TracedTransport(),
+	})
+	if err != nil {
+		return fmt.Errorf("error initializing minio client: %w", err)
+	}
+
+	err = minioClient.RemoveObject(ctx, d.Name, remoteFilePath, minio.RemoveObjectOptions{})
+	if err != nil {
+		return fmt.Errorf("error removing object %q from bucket %q: %w", remoteFilePath, d.Name, err)
+	}
+
+	return nil
+}
+
+// StateStoreBucketPrefix is the part of a state store bucket name that stays
+// the same when a cluster is recreated, the random cluster id follows it
+func StateStoreBucketPrefix(clusterName string) string {
+	return fmt.Sprintf("k1-state-store-%s-", clusterName)
+}
+
+// ListClusterBuckets returns the names of the buckets the state store
+// credentials can see on the state store endpoint
+func ListClusterBuckets(cr *pkgtypes.StateStoreCredentials, d *pkgtypes.StateStoreDetails) ([]string, error) {
+	minioClient, err := minio.New(stateStoreEndpoint(d), &minio.Options{
+		Creds:     credentials.NewStaticV4(cr.AccessKeyID, cr.SecretAccessKey, cr.SessionToken),
+		Secure:    true,
+		Transport: httpCommon.TracedTransport(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error initializing minio client: %w", err)
+	}
+
+	buckets, err := minioClient.ListBuckets(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("error listing buckets: %w", err)
+	}
+
+	names := make([]string, 0, len(buckets))
+	for _, b := range buckets {
+		names = append(names, b.Name)
+	}
+	return names, nil
+}
+
+// SupportsClusterObjects reports whether the state store is s3 compatible and
+// can be reached with the functions in this package
+func SupportsClusterObjects(cr *pkgtypes.StateStoreCredentials, d *pkgtypes.StateStoreDetails) bool {
+	return (d.Backend == "" || d.Backend == BackendS3) && d.Hostname != "" && d.Name != "" && cr.AccessKeyID != "" && cr.SecretAccessKey != ""
+}
+
+// stateStoreEndpoint strips any scheme some cloud providers include in the
+// bucket hostname, since minio expects a bare endpoint
+func stateStoreEndpoint(d *pkgtypes.StateStoreDetails) string {
+	endpoint := strings.TrimPrefix(d.Hostname, "https://")
+	endpoint = strings.TrimPrefix(endpoint, "http://")
+	return strings.TrimSuffix(endpoint, "/")
+}
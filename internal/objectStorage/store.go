@@ -0,0 +1,372 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+
+//nolint:revive,stylecheck // allowing package name objectStorage
+package objectStorage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	"cloud.google.com/go/storage"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/konstructio/kubefirst-api/internal/httpCommon"
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+// Terraform backends a state store can use
+const (
+	BackendS3    = "s3"
+	BackendGCS   = "gcs"
+	BackendAzure = "azurerm"
+)
+
+// AzureTerraformContainer is the blob container of the azure state stores
+// created with a cluster
+const AzureTerraformContainer = "terraform"
+
+// Store is a state store bucket, objects can be copied between the stores of
+// different backends
+type Store interface {
+	// Bucket returns the name of the bucket, the blob container on azure
+	Bucket() string
+	// Ensure creates the bucket when it does not exist
+	Ensure(ctx context.Context) error
+	List(ctx context.Context) ([]string, error)
+	Read(ctx context.Context, key string) ([]byte, error)
+	Write(ctx context.Context, key string, data []byte) error
+}
+
+// NewStore returns the store of a bucket. Azure stores use the storage
+// account in cr.Name and its key in cr.SecretAccessKey, gcs stores the
+// service account key in cr.KeyFile.
+func NewStore(backend string, cr *pkgtypes.StateStoreCredentials, d *pkgtypes.StateStoreDetails, region string) (Store, error) {
+	switch backend {
+	case "", BackendS3:
+		client, err := minio.New(stateStoreEndpoint(d), &minio.Options{
+			Creds:     credentials.NewStaticV4(cr.AccessKeyID, cr.SecretAccessKey, cr.SessionToken),
+			Secure:    true,
+			Transport: httpCommon.TracedTransport(),
+			Region:    region,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error initializing minio client: %w", err)
+		}
+		return &s3Store{client: client, bucket: d.Name, region: region}, nil
+	case BackendGCS:
+		ctx := context.Background()
+		creds, err := google.CredentialsFromJSON(ctx, []byte(cr.KeyFile), storage.ScopeFullControl)
+		if err != nil {
+			return nil, fmt.Errorf("could not create google storage client credentials: %w", err)
+		}
+		client, err := storage.NewClient(ctx, option.WithCredentials(creds))
+		if err != nil {
+			return nil, fmt.Errorf("could not create google storage client: %w", err)
+		}
+		return &gcsStore{client: client, bucket: d.Name, project: creds.ProjectID}, nil
+	case BackendAzure:
+		cred, err := azblob.NewSharedKeyCredential(cr.Name, cr.SecretAccessKey)
+		if err != nil {
+			return nil, fmt.Errorf("error creating azure storage credentials: %w", err)
+		}
+		client, err := azblob.NewClientWithSharedKeyCredential(fmt.Sprintf("https://%s.blob.core.windows.net/", cr.Name), cred, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create azblob client: %w", err)
+		}
+		return &azureStore{client: client, container: d.Name}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported state store backend %q", backend)
+}
+
+// CopyStoreObjects copies every object of a store to another, under the key
+// renames maps it to when there is one. Each copy is read back from the
+// destination and its sha256 checksum compared with the source object.
+func CopyStoreObjects(src, dst Store, renames map[string]string) ([]pkgtypes.MigratedObject, error) {
+	ctx := context.Background()
+
+	if err := dst.Ensure(ctx); err != nil {
+		return nil, err
+	}
+
+	keys, err := src.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	copied := []pkgtypes.MigratedObject{}
+	for _, key := range keys {
+		data, err := src.Read(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		checksum := sha256Hex(data)
+
+		dstKey := key
+		if renamed, ok := renames[key]; ok {
+			dstKey = renamed
+		}
+
+		if err := dst.Write(ctx, dstKey, data); err != nil {
+			return nil, err
+		}
+
+		written, err := dst.Read(ctx, dstKey)
+		if err != nil {
+			return nil, err
+		}
+		if dstChecksum := sha256Hex(written); dstChecksum != checksum {
+			return nil, fmt.Errorf("checksum mismatch for object %q: source %s, destination %s", key, checksum, dstChecksum)
+		}
+
+		copied = append(copied, pkgtypes.MigratedObject{
+			Bucket: dst.Bucket(),
+			Key:    dstKey,
+			Size:   int64(len(data)),
+			SHA256: checksum,
+		})
+	}
+
+	log.Info().Msgf("copied %d objects from bucket %s to bucket %s", len(copied), src.Bucket(), dst.Bucket())
+	return copied, nil
+}
+
+// BackendTerraformEnvs returns the variables terraform reads the credentials
+// of a state store backend from. The s3 credentials of aws clusters are their
+// cloud credentials, other s3 stores get theirs as backend configuration.
+func BackendTerraformEnvs(cloudProvider string, cr *pkgtypes.StateStoreCredentials, d *pkgtypes.StateStoreDetails) map[string]string {
+	switch d.Backend {
+	case BackendGCS:
+		return map[string]string{"GOOGLE_BACKEND_CREDENTIALS": cr.KeyFile}
+	case BackendAzure:
+		return map[string]string{"ARM_ACCESS_KEY": cr.SecretAccessKey}
+	}
+
+	if cloudProvider == "aws" {
+		return map[string]string{
+			"TF_CLI_ARGS_init": fmt.Sprintf("-backend-config=access_key=%s -backend-config=secret_key=%s", cr.AccessKeyID, cr.SecretAccessKey),
+		}
+	}
+
+	return map[string]string{
+		"AWS_ACCESS_KEY_ID":            cr.AccessKeyID,
+		"AWS_SECRET_ACCESS_KEY":        cr.SecretAccessKey,
+		"TF_VAR_aws_access_key_id":     cr.AccessKeyID,
+		"TF_VAR_aws_secret_access_key": cr.SecretAccessKey,
+	}
+}
+
+// TerraformEnvs adds the credentials of a migrated state store to envs, state
+// stores created with the cluster are covered by the cloud provider envs
+func TerraformEnvs(envs map[string]string, cl *pkgtypes.Cluster) map[string]string {
+	if cl.StateStoreDetails.Backend == "" {
+		return envs
+	}
+
+	for k, v := range BackendTerraformEnvs(cl.CloudProvider, &cl.StateStoreCredentials, &cl.StateStoreDetails) {
+		envs[k] = v
+	}
+	return envs
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+type s3Store struct {
+	client *minio.Client
+	bucket string
+	region string
+}
+
+func (s *s3Store) Bucket() string {
+	return s.bucket
+}
+
+func (s *s3Store) Ensure(ctx context.Context) error {
+	exists, err := s.client.BucketExists(ctx, s.bucket)
+	if err != nil {
+		return fmt.Errorf("error checking bucket %q existence: %w", s.bucket, err)
+	}
+	if exists {
+		return nil
+	}
+
+	if err := s.client.MakeBucket(ctx, s.bucket, minio.MakeBucketOptions{Region: s.region}); err != nil {
+		return fmt.Errorf("error creating bucket %q: %w", s.bucket, err)
+	}
+	log.Info().Msgf("created bucket %s", s.bucket)
+	return nil
+}
+
+func (s *s3Store) List(ctx context.Context) ([]string, error) {
+	keys := []string{}
+	for obj := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Recursive: true}) {
+		if obj.Err != nil {
+			return nil, fmt.Errorf("error listing objects in bucket %q: %w", s.bucket, obj.Err)
+		}
+		keys = append(keys, obj.Key)
+	}
+	return keys, nil
+}
+
+func (s *s3Store) Read(ctx context.Context, key string) ([]byte, error) {
+	reader, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving object %q: %w", key, err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("error reading object %q: %w", key, err)
+	}
+	return data, nil
+}
+
+func (s *s3Store) Write(ctx context.Context, key string, data []byte) error {
+	_, err := s.client.PutObject(ctx, s.bucket, key, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{})
+	if err != nil {
+		return fmt.Errorf("error writing object %q: %w", key, err)
+	}
+	return nil
+}
+
+type gcsStore struct {
+	client  *storage.Client
+	bucket  string
+	project string
+}
+
+func (s *gcsStore) Bucket() string {
+	return s.bucket
+}
+
+func (s *gcsStore) Ensure(ctx context.Context) error {
+	_, err := s.client.Bucket(s.bucket).Attrs(ctx)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, storage.ErrBucketNotExist) {
+		return fmt.Errorf("error checking gcs bucket %q existence: %w", s.bucket, err)
+	}
+
+	if err := s.client.Bucket(s.bucket).Create(ctx, s.project, &storage.BucketAttrs{}); err != nil {
+		return fmt.Errorf("error creating gcs bucket %s: %w", s.bucket, err)
+	}
+	log.Info().Msgf("created gcs bucket %s", s.bucket)
+	return nil
+}
+
+func (s *gcsStore) List(ctx context.Context) ([]string, error) {
+	keys := []string{}
+	it := s.client.Bucket(s.bucket).Objects(ctx, nil)
+	for {
+		attrs, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			return keys, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error listing objects in gcs bucket %q: %w", s.bucket, err)
+		}
+		keys = append(keys, attrs.Name)
+	}
+}
+
+func (s *gcsStore) Read(ctx context.Context, key string) ([]byte, error) {
+	reader, err := s.client.Bucket(s.bucket).Object(key).NewReader(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving object %q: %w", key, err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("error reading object %q: %w", key, err)
+	}
+	return data, nil
+}
+
+func (s *gcsStore) Write(ctx context.Context, key string, data []byte) error {
+	writer := s.client.Bucket(s.bucket).Object(key).NewWriter(ctx)
+	if _, err := writer.Write(data); err != nil {
+		writer.Close()
+		return fmt.Errorf("error writing object %q: %w", key, err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("error writing object %q: %w", key, err)
+	}
+	return nil
+}
+
+type azureStore struct {
+	client    *azblob.Client
+	container string
+}
+
+func (s *azureStore) Bucket() string {
+	return s.container
+}
+
+func (s *azureStore) Ensure(ctx context.Context) error {
+	_, err := s.client.CreateContainer(ctx, s.container, nil)
+	if err != nil && !bloberror.HasCode(err, bloberror.ContainerAlreadyExists) {
+		return fmt.Errorf("error creating blob storage container %s: %w", s.container, err)
+	}
+	return nil
+}
+
+func (s *azureStore) List(ctx context.Context) ([]string, error) {
+	keys := []string{}
+	pager := s.client.NewListBlobsFlatPager(s.container, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing blobs in container %q: %w", s.container, err)
+		}
+		for _, blob := range page.Segment.BlobItems {
+			if blob.Name != nil {
+				keys = append(keys, *blob.Name)
+			}
+		}
+	}
+	return keys, nil
+}
+
+func (s *azureStore) Read(ctx context.Context, key string) ([]byte, error) {
+	resp, err := s.client.DownloadStream(ctx, s.container, key, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving blob %q: %w", key, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading blob %q: %w", key, err)
+	}
+	return data, nil
+}
+
+func (s *azureStore) Write(ctx context.Context, key string, data []byte) error {
+	if _, err := s.client.UploadBuffer(ctx, s.container, key, data, nil); err != nil {
+		return fmt.Errorf("error writing blob %q: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Content types of the responses
+const (
+	ContentTypeJSON = "application/json"
+	ContentTypeSSE  = "text/event-stream"
+)
+
+// securityScheme is the name of the API key security scheme
+const securityScheme = "bearerAuth"
+
+// Endpoint is the typed definition of an API operation the document is built
+// from
+type Endpoint struct {
+	Method string
+	// Path in gin syntax, path parameters are taken from it
+	Path        string
+	ID          string
+	Summary     string
+	Description string
+	Tag         string
+	// Auth is set when the operation requires the API key
+	Auth  bool
+	Query []Param
+	// Body is a value of the type the request body is bound to
+	Body interface{}
+	// Responses are values of the types returned by status, OneOf when a
+	// status is returned with several types
+	Responses map[int]interface{}
+	// ContentType of the responses, JSON when empty
+	ContentType string
+}
+
+// Param is a query parameter
+type Param struct {
+	Name string
+	// Type is string, integer or boolean
+	Type        string
+	Description string
+	Required    bool
+}
+
+// OneOf holds values of the types a response may have
+type OneOf []interface{}
+
+// Build returns the document of the endpoints, failures of every operation
+// are described by the type of failure
+func Build(info Info, failure interface{}, endpoints []Endpoint) (*Document, error) {
+	reg := newRegistry()
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   map[string]PathItem{},
+		Components: Components{
+			Schemas: reg.schemas,
+			SecuritySchemes: map[string]SecurityScheme{
+				securityScheme: {Type: "http", Scheme: "bearer", Description: "kubefirst API key"},
+			},
+		},
+	}
+	failureSchema := reg.schema(reflect.TypeOf(failure))
+
+	ids := map[string]bool{}
+	tags := map[string]bool{}
+	for _, e := range endpoints {
+		if e.ID == "" {
+			return nil, fmt.Errorf("%s %s has no operation id", e.Method, e.Path)
+		}
+		if ids[e.ID] {
+			return nil, fmt.Errorf("operation id %s is used more than once", e.ID)
+		}
+		ids[e.ID] = true
+
+		path := Path(e.Path)
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = PathItem{}
+			doc.Paths[path] = item
+		}
+		method := lower(e.Method)
+		if _, ok := item[method]; ok {
+			return nil, fmt.Errorf("%s %s is defined more than once", e.Method, e.Path)
+		}
+
+		op := &Operation{
+			OperationID: e.ID,
+			Summary:     e.Summary,
+			Description: e.Description,
+			Responses:   map[string]Response{},
+			Security:    []map[string][]string{},
+		}
+		if e.Tag != "" {
+			op.Tags = []string{e.Tag}
+			if !tags[e.Tag] {
+				tags[e.Tag] = true
+				doc.Tags = append(doc.Tags, Tag{Name: e.Tag})
+			}
+		}
+		if e.Auth {
+			op.Security = append(op.Security, map[string][]string{securityScheme: {}})
+		}
+
+		for _, name := range PathParams(e.Path) {
+			op.Parameters = append(op.Parameters, Parameter{
+				Name:     name,
+				In:       "path",
+				Required: true,
+				Schema:   &Schema{Type: "string"},
+			})
+		}
+		for _, q := range e.Query {
+			op.Parameters = append(op.Parameters, Parameter{
+				Name:        q.Name,
+				In:          "query",
+				Description: q.Description,
+				Required:    q.Required,
+				Schema:      &Schema{Type: q.Type},
+			})
+		}
+
+		if e.Body != nil {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]MediaType{ContentTypeJSON: {Schema: reg.value(e.Body)}},
+			}
+		}
+
+		contentType := e.ContentType
+		if contentType == "" {
+			contentType = ContentTypeJSON
+		}
+		for status, body := range e.Responses {
+			schema := &Schema{Type: "string"}
+			if contentType == ContentTypeJSON {
+				schema = reg.value(body)
+			}
+			op.Responses[strconv.Itoa(status)] = Response{
+				Description: http.StatusText(status),
+				Content:     map[string]MediaType{contentType: {Schema: schema}},
+			}
+		}
+		op.Responses["default"] = Response{
+			Description: "Error",
+			Content:     map[string]MediaType{ContentTypeJSON: {Schema: failureSchema}},
+		}
+
+		item[method] = op
+	}
+
+	sort.Slice(doc.Tags, func(i, j int) bool { return doc.Tags[i].Name < doc.Tags[j].Name })
+
+	return doc, nil
+}
+
+// Path converts a gin path to an OpenAPI path, :name and *name become {name}
+func Path(ginPath string) string {
+	segments := strings.Split(ginPath, "/")
+	for i, s := range segments {
+		if strings.HasPrefix(s, ":") || strings.HasPrefix(s, "*") {
+			segments[i] = "{" + s[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// PathParams returns the names of the parameters of a gin path
+func PathParams(ginPath string) []string {
+	var names []string
+	for _, s := range strings.Split(ginPath, "/") {
+		if strings.HasPrefix(s, ":") || strings.HasPrefix(s, "*") {
+			names = append(names, s[1:])
+		}
+	}
+	return names
+}
+
+func lower(method string) string {
+	return strings.ToLower(method)
+}
@@ -0,0 +1,129 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testNode struct {
+	Name     string            `json:"name" binding:"required"`
+	Kind     string            `json:"kind,omitempty" binding:"oneof=a b"`
+	Size     int64             `json:"size"`
+	Created  time.Time         `json:"created"`
+	Parent   *testNode         `json:"parent,omitempty"`
+	Children []testNode        `json:"children"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Skipped  string            `json:"-"`
+	internal string
+}
+
+type testFailure struct {
+	Message string `json:"error"`
+}
+
+func testDocument(t *testing.T) *Document {
+	t.Helper()
+
+	doc, err := Build(Info{Title: "test", Version: "1.0"}, testFailure{}, []Endpoint{
+		{
+			Method: http.MethodPost, Path: "/nodes/:name/*path", ID: "createNode", Tag: "nodes", Auth: true,
+			Query:     []Param{{Name: "dry_run", Type: "boolean"}},
+			Body:      testNode{},
+			Responses: map[int]interface{}{http.StatusCreated: testNode{}},
+		},
+		{
+			Method: http.MethodGet, Path: "/nodes", ID: "listNodes",
+			Responses: map[int]interface{}{http.StatusOK: OneOf{[]testNode{}, true}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return doc
+}
+
+func TestBuild(t *testing.T) {
+	doc := testDocument(t)
+
+	op, ok := doc.Operation(http.MethodPost, "/nodes/:name/*path")
+	if !ok {
+		t.Fatal("expected the createNode operation under /nodes/{name}/{path}")
+	}
+	if len(op.Parameters) != 3 || op.Parameters[0].In != "path" || op.Parameters[2].In != "query" {
+		t.Errorf("expected the name and path parameters followed by dry_run, but got %+v", op.Parameters)
+	}
+	if len(op.Security) != 1 {
+		t.Errorf("expected the operation to require the API key, but got %v", op.Security)
+	}
+	if _, ok := op.Responses["default"]; !ok {
+		t.Error("expected the failure response to be the default response")
+	}
+
+	node := doc.Components.Schemas["testNode"]
+	if node == nil {
+		t.Fatalf("expected a testNode component, but got %v", reflect.ValueOf(doc.Components.Schemas).MapKeys())
+	}
+	if !reflect.DeepEqual(node.Required, []string{"name"}) {
+		t.Errorf("expected only the bound required field to be required, but got %v", node.Required)
+	}
+	if !reflect.DeepEqual(node.Properties["kind"].Enum, []string{"a", "b"}) {
+		t.Errorf("expected kind to be an enum, but got %v", node.Properties["kind"].Enum)
+	}
+	if node.Properties["created"].Format != "date-time" {
+		t.Errorf("expected created to be a date-time, but got %+v", node.Properties["created"])
+	}
+	if parent := node.Properties["parent"]; !parent.Nullable || len(parent.AllOf) != 1 {
+		t.Errorf("expected parent to be a nullable reference, but got %+v", parent)
+	}
+	for _, name := range []string{"-", "Skipped", "internal"} {
+		if _, ok := node.Properties[name]; ok {
+			t.Errorf("expected %s not to be a property", name)
+		}
+	}
+
+	if _, err := Build(Info{}, testFailure{}, []Endpoint{{Method: http.MethodGet, Path: "/a", ID: "a"}, {Method: http.MethodGet, Path: "/b", ID: "a"}}); err == nil {
+		t.Error("expected an error for a duplicated operation id")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	doc := testDocument(t)
+	create, _ := doc.Operation(http.MethodPost, "/nodes/:name/*path")
+	node := create.Responses["201"].Content[ContentTypeJSON].Schema
+	list, _ := doc.Operation(http.MethodGet, "/nodes")
+	nodes := list.Responses["200"].Content[ContentTypeJSON].Schema
+
+	tests := []struct {
+		name    string
+		schema  *Schema
+		body    string
+		wantErr bool
+	}{
+		{name: "valid", schema: node, body: `{"name":"a","size":1,"created":"2024-01-01T00:00:00Z","children":null,"parent":{"name":"b"}}`},
+		{name: "missing required", schema: node, body: `{"size":1}`, wantErr: true},
+		{name: "unknown property", schema: node, body: `{"name":"a","color":"red"}`, wantErr: true},
+		{name: "wrong type", schema: node, body: `{"name":"a","size":"1"}`, wantErr: true},
+		{name: "fraction for integer", schema: node, body: `{"name":"a","size":1.5}`, wantErr: true},
+		{name: "not in enum", schema: node, body: `{"name":"a","kind":"c"}`, wantErr: true},
+		{name: "nested", schema: node, body: `{"name":"a","children":[{"name":1}]}`, wantErr: true},
+		{name: "one of array", schema: nodes, body: `[{"name":"a"}]`},
+		{name: "one of boolean", schema: nodes, body: `true`},
+		{name: "none of", schema: nodes, body: `"a"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := doc.ValidateJSON(tt.schema, []byte(tt.body)); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPath(t *testing.T) {
+	if got := Path("/cluster/:cluster_name/vault/secrets/*path"); got != "/cluster/{cluster_name}/vault/secrets/{path}" {
+		t.Errorf("unexpected path %s", got)
+	}
+}
@@ -0,0 +1,202 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const refPrefix = "#/components/schemas/"
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// registry builds the schemas of Go types the way encoding/json marshals them,
+// named structs become components
+type registry struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newRegistry() *registry {
+	return &registry{schemas: map[string]*Schema{}, names: map[reflect.Type]string{}}
+}
+
+// value returns the schema of the type of v
+func (r *registry) value(v interface{}) *Schema {
+	if alternatives, ok := v.(OneOf); ok {
+		s := &Schema{}
+		for _, alternative := range alternatives {
+			s.OneOf = append(s.OneOf, r.value(alternative))
+		}
+		return s
+	}
+	return r.schema(reflect.TypeOf(v))
+}
+
+// schema returns the schema of t
+func (r *registry) schema(t reflect.Type) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Kind() == reflect.Pointer:
+		return nullable(r.schema(t.Elem()))
+	case t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType):
+		// types marshalling themselves to text, such as object ids, are
+		// strings, nothing is known of the others
+		if t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) {
+			return &Schema{Type: "string"}
+		}
+		return &Schema{}
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte", Nullable: true}
+		}
+		return &Schema{Type: "array", Items: r.schema(t.Elem()), Nullable: true}
+	case reflect.Array:
+		return &Schema{Type: "array", Items: r.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.schema(t.Elem()), Nullable: true}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return r.object(t)
+		}
+		return r.ref(t)
+	}
+
+	// interfaces and anything else hold any value
+	return &Schema{}
+}
+
+// ref registers the component of a named struct and returns a reference to it
+func (r *registry) ref(t reflect.Type) *Schema {
+	name, ok := r.names[t]
+	if !ok {
+		name = r.name(t)
+		r.names[t] = name
+
+		// registered before its fields so recursive types terminate
+		s := &Schema{}
+		r.schemas[name] = s
+		*s = *r.object(t)
+	}
+	return &Schema{Ref: refPrefix + name}
+}
+
+// name returns the component name of t, qualified by its package when another
+// type already took the short name
+func (r *registry) name(t reflect.Type) string {
+	name := t.Name()
+	if _, taken := r.schemas[name]; taken {
+		name = path.Base(t.PkgPath()) + "." + name
+	}
+	return name
+}
+
+// object returns the schema of the fields of a struct
+func (r *registry) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	r.fields(t, s)
+	return s
+}
+
+func (r *registry) fields(t reflect.Type, s *Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() && !f.Anonymous {
+			continue
+		}
+
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		// fields of embedded structs are promoted
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				r.fields(ft, s)
+				continue
+			}
+			if !f.IsExported() {
+				continue
+			}
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		prop := r.schema(f.Type)
+		if hasOption(opts, "string") {
+			prop = &Schema{Type: "string"}
+		}
+
+		for _, rule := range strings.Split(f.Tag.Get("binding"), ",") {
+			switch {
+			case rule == "required":
+				s.Required = append(s.Required, name)
+			case strings.HasPrefix(rule, "oneof=") && prop.Type == "string":
+				prop.Enum = strings.Fields(strings.TrimPrefix(rule, "oneof="))
+			}
+		}
+
+		s.Properties[name] = prop
+	}
+}
+
+// nullable returns s accepting null, references are wrapped as their siblings
+// are ignored
+func nullable(s *Schema) *Schema {
+	if s.Ref != "" {
+		return &Schema{AllOf: []*Schema{s}, Nullable: true}
+	}
+	copied := *s
+	copied.Nullable = true
+	return &copied
+}
+
+func hasOption(opts, option string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+func refName(ref string) string {
+	return strings.TrimPrefix(ref, refPrefix)
+}
@@ -0,0 +1,160 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// ValidateJSON checks a JSON body against a schema of the document
+func (d *Document) ValidateJSON(s *Schema, data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("invalid json: %w", err)
+	}
+	return d.Validate(s, value)
+}
+
+// Validate checks a value decoded by encoding/json against a schema of the
+// document. Objects built from structs must not hold properties the schema
+// does not know about, so fields missing from the document are caught.
+func (d *Document) Validate(s *Schema, value interface{}) error {
+	return d.validate(s, value, "$")
+}
+
+func (d *Document) validate(s *Schema, value interface{}, at string) error {
+	if s == nil {
+		return nil
+	}
+	if s.Ref != "" {
+		resolved := d.Resolve(s)
+		if resolved == nil {
+			return fmt.Errorf("%s: unknown schema %s", at, s.Ref)
+		}
+		return d.validate(resolved, value, at)
+	}
+
+	if len(s.OneOf) > 0 {
+		matches := 0
+		for _, sub := range s.OneOf {
+			if d.validate(sub, value, at) == nil {
+				matches++
+			}
+		}
+		if matches != 1 {
+			return fmt.Errorf("%s: matches %d of the oneOf schemas instead of one", at, matches)
+		}
+		return nil
+	}
+
+	if value == nil {
+		if s.Nullable || (s.Type == "" && len(s.AllOf) == 0) {
+			return nil
+		}
+		return fmt.Errorf("%s: null is not allowed", at)
+	}
+
+	for _, sub := range s.AllOf {
+		if err := d.validate(sub, value, at); err != nil {
+			return err
+		}
+	}
+
+	switch s.Type {
+	case "":
+		return nil
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return mismatch(at, s.Type, value)
+		}
+		return d.validateObject(s, object, at)
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			return mismatch(at, s.Type, value)
+		}
+		for i, item := range array {
+			if err := d.validate(s.Items, item, fmt.Sprintf("%s[%d]", at, i)); err != nil {
+				return err
+			}
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return mismatch(at, s.Type, value)
+		}
+		if len(s.Enum) > 0 && !contains(s.Enum, str) {
+			return fmt.Errorf("%s: %q is not one of %v", at, str, s.Enum)
+		}
+	case "integer":
+		number, ok := value.(float64)
+		if !ok || number != math.Trunc(number) {
+			return mismatch(at, s.Type, value)
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return mismatch(at, s.Type, value)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return mismatch(at, s.Type, value)
+		}
+	default:
+		return fmt.Errorf("%s: unknown type %s", at, s.Type)
+	}
+
+	return nil
+}
+
+func (d *Document) validateObject(s *Schema, object map[string]interface{}, at string) error {
+	for _, name := range s.Required {
+		if _, ok := object[name]; !ok {
+			return fmt.Errorf("%s: missing required property %s", at, name)
+		}
+	}
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		prop, ok := s.Properties[name]
+		switch {
+		case ok:
+		case s.AdditionalProperties != nil:
+			prop = s.AdditionalProperties
+		case s.Properties != nil:
+			return fmt.Errorf("%s: unknown property %s", at, name)
+		default:
+			continue
+		}
+		if err := d.validate(prop, object[name], at+"."+name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func mismatch(at, want string, value interface{}) error {
+	return fmt.Errorf("%s: expected %s, got %T", at, want, value)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
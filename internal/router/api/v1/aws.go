@@ -7,11 +7,14 @@ See the LICENSE file for more details.
 package api
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/konstructio/kubefirst-api/internal/apierror"
 	"github.com/konstructio/kubefirst-api/internal/aws"
+	"github.com/konstructio/kubefirst-api/internal/middleware"
 	"github.com/konstructio/kubefirst-api/internal/types"
 )
 
@@ -32,27 +35,22 @@ import (
 func GetValidateAWSDomain(c *gin.Context) {
 	domainName, exists := c.Params.Get("domain")
 	if !exists {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: ":domain parameter not provided in request",
-		})
+		respondError(c, http.StatusBadRequest, errors.New(":domain parameter not provided in request"))
 		return
 	}
 
 	// Run validate func
-	client, err := aws.New()
+	env := middleware.GetConfig(c)
+	client, err := aws.New(env.AWSRegion, env.AWSProfile)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusInternalServerError, apierror.Upstream("aws", err))
 		return
 	}
 
 	// Requires a trailing dot for Route53
 	validated, err := client.TestHostedZoneLivenessWithTxtRecords(fmt.Sprintf("%s.", domainName))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, apierror.Upstream("aws", err))
 		return
 	}
 
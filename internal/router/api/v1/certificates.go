@@ -0,0 +1,122 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/konstructio/kubefirst-api/internal/middleware"
+	"github.com/konstructio/kubefirst-api/internal/objectStorage"
+	"github.com/konstructio/kubefirst-api/internal/secrets"
+	"github.com/konstructio/kubefirst-api/internal/ssl"
+	"github.com/konstructio/kubefirst-api/internal/utils"
+	"k8s.io/client-go/dynamic"
+)
+
+// GetClusterTLSBackups godoc
+//
+//	@Summary		List the tls certificate backups of a cluster
+//	@Description	List the tls certificate backups stored in the state store of a cluster, newest first
+//	@Tags			cluster
+//	@Accept			json
+//	@Produce		json
+//	@Param			cluster_name	path		string	true	"Cluster name"
+//	@Success		200				{object}	[]types.BucketObject
+//	@Failure		400				{object}	types.JSONFailureResponse
+//	@Failure		404				{object}	types.JSONFailureResponse
+//	@Router			/cluster/:cluster_name/tls-backups [get]
+//	@Param			Authorization	header	string	true	"API key"	default(Bearer <API key>)
+//
+// GetClusterTLSBackups returns the tls certificate backups of a cluster
+func GetClusterTLSBackups(c *gin.Context) {
+	clusterName, param := c.Params.Get("cluster_name")
+	if !param {
+		respondError(c, http.StatusBadRequest, errors.New(":cluster_name not provided"))
+		return
+	}
+
+	kcfg := utils.GetKubernetesClient(clusterName)
+
+	cluster, err := secrets.GetCluster(kcfg.Clientset, clusterName)
+	if err != nil {
+		if errors.Is(err, &secrets.ClusterNotFoundError{}) {
+			respondError(c, http.StatusNotFound, err)
+			return
+		}
+
+		respondError(c, http.StatusInternalServerError, fmt.Errorf("unable to find cluster: %w", err))
+		return
+	}
+
+	if !objectStorage.SupportsClusterObjects(&cluster.StateStoreCredentials, &cluster.StateStoreDetails) {
+		respondError(c, http.StatusBadRequest, fmt.Errorf("tls backups are not supported for the state store of cluster %s", clusterName))
+		return
+	}
+
+	backups, err := ssl.ListStateStoreBackups(cluster)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, backups)
+}
+
+// GetClusterCertificates godoc
+//
+//	@Summary		List the tls certificates of a cluster
+//	@Description	List the tls certificates of a cluster with their issuer, names, expiry and renewal status
+//	@Tags			cluster
+//	@Accept			json
+//	@Produce		json
+//	@Param			cluster_name	path		string	true	"Cluster name"
+//	@Success		200				{object}	[]types.ClusterCertificate
+//	@Failure		400				{object}	types.JSONFailureResponse
+//	@Failure		404				{object}	types.JSONFailureResponse
+//	@Router			/cluster/:cluster_name/certificates [get]
+//	@Param			Authorization	header	string	true	"API key"	default(Bearer <API key>)
+//
+// GetClusterCertificates returns the tls certificates of a cluster
+func GetClusterCertificates(c *gin.Context) {
+	clusterName, param := c.Params.Get("cluster_name")
+	if !param {
+		respondError(c, http.StatusBadRequest, errors.New(":cluster_name not provided"))
+		return
+	}
+
+	kcfg := utils.GetKubernetesClient(clusterName)
+
+	_, err := secrets.GetCluster(kcfg.Clientset, clusterName)
+	if err != nil {
+		if errors.Is(err, &secrets.ClusterNotFoundError{}) {
+			respondError(c, http.StatusNotFound, err)
+			return
+		}
+
+		respondError(c, http.StatusInternalServerError, fmt.Errorf("unable to find cluster: %w", err))
+		return
+	}
+
+	env := middleware.GetConfig(c)
+
+	dyn, err := dynamic.NewForConfig(kcfg.RestConfig)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	certificates, err := ssl.ListCertificates(kcfg.Clientset, dyn, env.CertificateExpiryWindow)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, certificates)
+}
@@ -8,10 +8,12 @@ package api
 
 import (
 	"context"
+	"errors"
 	"net/http"
 
 	"github.com/civo/civogo"
 	"github.com/gin-gonic/gin"
+	"github.com/konstructio/kubefirst-api/internal/apierror"
 	"github.com/konstructio/kubefirst-api/internal/civo"
 	"github.com/konstructio/kubefirst-api/internal/types"
 )
@@ -34,18 +36,14 @@ import (
 func GetValidateCivoDomain(c *gin.Context) {
 	domainName, exists := c.Params.Get("domain")
 	if !exists {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: ":domain parameter not provided in request",
-		})
+		respondError(c, http.StatusBadRequest, errors.New(":domain parameter not provided in request"))
 		return
 	}
 
 	var settings types.CivoDomainValidationRequest
 	err := c.Bind(&settings)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -57,17 +55,13 @@ func GetValidateCivoDomain(c *gin.Context) {
 
 	domainID, err := civoConf.GetDNSInfo(domainName)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, apierror.Upstream("civo", err))
 		return
 	}
 
 	validated := civoConf.TestDomainLiveness(domainName, domainID)
 	if !validated {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: "domain validation failed",
-		})
+		respondError(c, http.StatusBadRequest, apierror.DNSNotDelegated(domainName, "domain validation failed"))
 		return
 	}
 	c.JSON(http.StatusOK, types.CivoDomainValidationResponse{
@@ -8,11 +8,13 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 
 	cloudflare_api "github.com/cloudflare/cloudflare-go"
 	"github.com/gin-gonic/gin"
+	"github.com/konstructio/kubefirst-api/internal/apierror"
 	"github.com/konstructio/kubefirst-api/internal/cloudflare"
 	"github.com/konstructio/kubefirst-api/internal/types"
 )
@@ -20,26 +22,20 @@ import (
 func PostValidateCloudflareDomain(c *gin.Context) {
 	domainName, exists := c.Params.Get("domain")
 	if !exists {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: ":domain parameter not provided in request",
-		})
+		respondError(c, http.StatusBadRequest, errors.New(":domain parameter not provided in request"))
 		return
 	}
 
 	var request types.CloudflareDomainValidationRequest
 	err := c.Bind(&request)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
 	client, err := cloudflare_api.NewWithAPIToken(request.Token)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: fmt.Sprintf("Could not create cloudflare client, %v", err),
-		})
+		respondError(c, http.StatusBadRequest, apierror.Upstream("cloudflare", fmt.Errorf("Could not create cloudflare client, %w", err)))
 		return
 	}
 
@@ -51,9 +47,7 @@ func PostValidateCloudflareDomain(c *gin.Context) {
 	validated := cloudflareConf.TestDomainLiveness(domainName)
 
 	if !validated {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: "domain validation failed",
-		})
+		respondError(c, http.StatusBadRequest, apierror.DNSNotDelegated(domainName, "domain validation failed"))
 		return
 	}
 	c.JSON(http.StatusOK, types.CloudflareDomainValidationResponse{
@@ -14,18 +14,22 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/konstructio/kubefirst-api/internal/apierror"
 	civoruntime "github.com/konstructio/kubefirst-api/internal/civo"
 	"github.com/konstructio/kubefirst-api/internal/constants"
 	digioceanruntime "github.com/konstructio/kubefirst-api/internal/digitalocean"
-	"github.com/konstructio/kubefirst-api/internal/env"
 	environments "github.com/konstructio/kubefirst-api/internal/environments"
 	"github.com/konstructio/kubefirst-api/internal/gitShim"
 	"github.com/konstructio/kubefirst-api/internal/k8s"
+	"github.com/konstructio/kubefirst-api/internal/metrics"
+	"github.com/konstructio/kubefirst-api/internal/middleware"
 	"github.com/konstructio/kubefirst-api/internal/secrets"
 	"github.com/konstructio/kubefirst-api/internal/services"
+	"github.com/konstructio/kubefirst-api/internal/shutdown"
 	"github.com/konstructio/kubefirst-api/internal/types"
 	"github.com/konstructio/kubefirst-api/internal/utils"
 	vultrruntime "github.com/konstructio/kubefirst-api/internal/vultr"
+	"github.com/konstructio/kubefirst-api/pkg/certificates"
 	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
 	"github.com/konstructio/kubefirst-api/providers/akamai"
 	"github.com/konstructio/kubefirst-api/providers/aws"
@@ -57,9 +61,7 @@ import (
 func DeleteCluster(c *gin.Context) {
 	clusterName, param := c.Params.Get("cluster_name")
 	if !param {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: ":cluster_name not provided",
-		})
+		respondError(c, http.StatusBadRequest, errors.New(":cluster_name not provided"))
 		return
 	}
 
@@ -68,13 +70,16 @@ func DeleteCluster(c *gin.Context) {
 	// Delete cluster
 	rec, err := secrets.GetCluster(kcfg.Clientset, clusterName)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
-	env, _ := env.GetEnv(constants.SilenceGetEnv)
+	if err := checkInterruptedOperation(rec, metrics.OperationDelete); err != nil {
+		respondError(c, http.StatusConflict, err)
+		return
+	}
+
+	env := middleware.GetConfig(c)
 
 	telemetryEvent := telemetry.TelemetryEvent{
 		CliVersion:        env.KubefirstVersion,
@@ -95,13 +100,15 @@ func DeleteCluster(c *gin.Context) {
 
 	if rec.LastCondition != "" {
 		rec.LastCondition = ""
+		rec.LastErrorCode = ""
 		err = secrets.UpdateCluster(kcfg.Clientset, *rec)
 		if err != nil {
 			log.Warn().Msgf("error updating cluster last_condition field: %s", err)
 		}
 	}
-	if rec.Status == constants.ClusterStatusError {
+	if rec.Status == constants.ClusterStatusError || rec.Status == constants.ClusterStatusInterrupted {
 		rec.Status = constants.ClusterStatusDeleting
+		rec.InterruptedOperation = ""
 		err = secrets.UpdateCluster(kcfg.Clientset, *rec)
 		if err != nil {
 			log.Warn().Msgf("error updating cluster status field: %s", err)
@@ -110,56 +117,51 @@ func DeleteCluster(c *gin.Context) {
 
 	switch rec.CloudProvider {
 	case "aws":
-		go func() {
-			err := aws.DeleteAWSCluster(rec, telemetryEvent)
-			if err != nil {
-				log.Error().Msg(err.Error())
-			}
-		}()
+		runOperation(c, metrics.OperationDelete, rec.ClusterName, func(ctx context.Context) error {
+			defer metrics.TrackOperation(metrics.OperationDelete, rec.CloudProvider)()
+
+			return aws.DeleteAWSCluster(ctx, env, rec, telemetryEvent)
+		})
 
 		c.JSON(http.StatusAccepted, types.JSONSuccessResponse{
 			Message: "cluster delete enqueued",
 		})
 	case "civo":
-		go func() {
-			err := civo.DeleteCivoCluster(rec, telemetryEvent)
-			if err != nil {
-				log.Error().Msg(err.Error())
-			}
-		}()
+		runOperation(c, metrics.OperationDelete, rec.ClusterName, func(ctx context.Context) error {
+			defer metrics.TrackOperation(metrics.OperationDelete, rec.CloudProvider)()
+
+			return civo.DeleteCivoCluster(ctx, env, rec, telemetryEvent)
+		})
 
 		c.JSON(http.StatusAccepted, types.JSONSuccessResponse{
 			Message: "cluster delete enqueued",
 		})
 	case "digitalocean":
-		go func() {
-			err := digitalocean.DeleteDigitaloceanCluster(rec, telemetryEvent)
-			if err != nil {
-				log.Error().Msg(err.Error())
-			}
-		}()
+		runOperation(c, metrics.OperationDelete, rec.ClusterName, func(ctx context.Context) error {
+			defer metrics.TrackOperation(metrics.OperationDelete, rec.CloudProvider)()
+
+			return digitalocean.DeleteDigitaloceanCluster(ctx, env, rec, telemetryEvent)
+		})
 
 		c.JSON(http.StatusAccepted, types.JSONSuccessResponse{
 			Message: "cluster delete enqueued",
 		})
 	case "vultr":
-		go func() {
-			err := vultr.DeleteVultrCluster(rec, telemetryEvent)
-			if err != nil {
-				log.Error().Msg(err.Error())
-			}
-		}()
+		runOperation(c, metrics.OperationDelete, rec.ClusterName, func(ctx context.Context) error {
+			defer metrics.TrackOperation(metrics.OperationDelete, rec.CloudProvider)()
+
+			return vultr.DeleteVultrCluster(ctx, env, rec, telemetryEvent)
+		})
 
 		c.JSON(http.StatusAccepted, types.JSONSuccessResponse{
 			Message: "cluster delete enqueued",
 		})
 	case "google":
-		go func() {
-			err := google.DeleteGoogleCluster(rec, telemetryEvent)
-			if err != nil {
-				log.Error().Msg(err.Error())
-			}
-		}()
+		runOperation(c, metrics.OperationDelete, rec.ClusterName, func(ctx context.Context) error {
+			defer metrics.TrackOperation(metrics.OperationDelete, rec.CloudProvider)()
+
+			return google.DeleteGoogleCluster(ctx, env, rec, telemetryEvent)
+		})
 
 		c.JSON(http.StatusAccepted, types.JSONSuccessResponse{
 			Message: "cluster delete enqueued",
@@ -175,7 +177,7 @@ func DeleteCluster(c *gin.Context) {
 //	@Accept			json
 //	@Produce		json
 //	@Param			cluster_name	path		string	true	"Cluster name"
-//	@Success		200				{object}	pkgtypes.Cluster
+//	@Success		200				{object}	types.Cluster
 //	@Failure		400				{object}	types.JSONFailureResponse
 //	@Router			/cluster/:cluster_name [get]
 //	@Param			Authorization	header	string	true	"API key"	default(Bearer <API key>)
@@ -184,9 +186,7 @@ func DeleteCluster(c *gin.Context) {
 func GetCluster(c *gin.Context) {
 	clusterName, param := c.Params.Get("cluster_name")
 	if !param {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: ":cluster_name not provided",
-		})
+		respondError(c, http.StatusBadRequest, errors.New(":cluster_name not provided"))
 		return
 	}
 
@@ -196,15 +196,11 @@ func GetCluster(c *gin.Context) {
 	cluster, err := secrets.GetCluster(kcfg.Clientset, clusterName)
 	if err != nil {
 		if errors.Is(err, &secrets.ClusterNotFoundError{}) {
-			c.JSON(http.StatusNotFound, types.JSONFailureResponse{
-				Message: err.Error(),
-			})
+			respondError(c, http.StatusNotFound, err)
 			return
 		}
 
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: "unable to find cluster: " + err.Error(),
-		})
+		respondError(c, http.StatusInternalServerError, fmt.Errorf("unable to find cluster: %w", err))
 		return
 	}
 
@@ -218,7 +214,7 @@ func GetCluster(c *gin.Context) {
 //	@Tags			cluster
 //	@Accept			json
 //	@Produce		json
-//	@Success		200	{object}	[]pkgtypes.Cluster
+//	@Success		200	{object}	[]types.Cluster
 //	@Failure		400	{object}	types.JSONFailureResponse
 //	@Router			/cluster [get]
 //	@Param			Authorization	header	string	true	"API key"	default(Bearer <API key>)
@@ -230,9 +226,7 @@ func GetClusters(c *gin.Context) {
 	// Retrieve all clusters info
 	allClusters, err := secrets.GetClusters(kcfg.Clientset)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -257,9 +251,7 @@ func GetClusters(c *gin.Context) {
 func PostCreateCluster(c *gin.Context) {
 	clusterName, param := c.Params.Get("cluster_name")
 	if !param || clusterName == ":cluster_name" {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: ":cluster_name not provided",
-		})
+		respondError(c, http.StatusBadRequest, errors.New(":cluster_name not provided"))
 		return
 	}
 
@@ -267,9 +259,7 @@ func PostCreateCluster(c *gin.Context) {
 	var clusterDefinition pkgtypes.ClusterDefinition
 	err := c.Bind(&clusterDefinition)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 	clusterDefinition.ClusterName = clusterName
@@ -282,9 +272,7 @@ func PostCreateCluster(c *gin.Context) {
 	cluster, err := secrets.GetCluster(kcfg.Clientset, clusterName)
 	if err != nil {
 		if !errors.Is(err, &secrets.ClusterNotFoundError{}) {
-			c.JSON(http.StatusInternalServerError, types.JSONFailureResponse{
-				Message: err.Error(),
-			})
+			respondError(c, http.StatusInternalServerError, err)
 			return
 		}
 
@@ -293,22 +281,27 @@ func PostCreateCluster(c *gin.Context) {
 
 	if cluster != nil {
 		if cluster.InProgress {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: fmt.Sprintf("%s has an active process running and another create cannot be enqeued", clusterName),
-			})
+			respondError(c, http.StatusConflict, apierror.OperationInProgress(clusterName, "%s has an active process running and another create cannot be enqeued", clusterName))
+			return
+		}
+
+		if err := checkInterruptedOperation(cluster, metrics.OperationCreate); err != nil {
+			respondError(c, http.StatusConflict, err)
 			return
 		}
 
 		if cluster.LastCondition != "" {
 			cluster.LastCondition = ""
+			cluster.LastErrorCode = ""
 			err = secrets.UpdateCluster(kcfg.Clientset, *cluster)
 			if err != nil {
 				log.Warn().Msgf("error updating cluster last_condition field: %s", err)
 			}
 		}
 
-		if cluster.Status == constants.ClusterStatusError {
+		if cluster.Status == constants.ClusterStatusError || cluster.Status == constants.ClusterStatusInterrupted {
 			cluster.Status = constants.ClusterStatusProvisioning
+			cluster.InterruptedOperation = ""
 			err = secrets.UpdateCluster(kcfg.Clientset, *cluster)
 			if err != nil {
 				log.Warn().Msgf("error updating cluster status field: %s", err)
@@ -335,11 +328,11 @@ func PostCreateCluster(c *gin.Context) {
 	useSecretForAuth := false
 	k1AuthSecret := map[string]string{}
 
-	env, err := env.GetEnv(constants.SilenceGetEnv)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: fmt.Sprintf("error getting environment variables: %s", err),
-		})
+	env := middleware.GetConfig(c)
+
+	// Pre-flight check against the LetsEncrypt rate limits
+	if err := checkCertificateUsage(&clusterDefinition, env.CertificateUsageCheck); err != nil {
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -349,9 +342,7 @@ func PostCreateCluster(c *gin.Context) {
 
 		k1AuthSecret, err := k8s.ReadSecretV2(kcfg.Clientset, constants.KubefirstNamespace, constants.KubefirstAuthSecretName)
 		if err != nil && !apierrors.IsNotFound(err) {
-			c.JSON(http.StatusInternalServerError, types.JSONFailureResponse{
-				Message: err.Error(),
-			})
+			respondError(c, http.StatusInternalServerError, err)
 			return
 		}
 
@@ -369,27 +360,22 @@ func PostCreateCluster(c *gin.Context) {
 		if useSecretForAuth {
 			err := utils.ValidateAuthenticationFields(k1AuthSecret)
 			if err != nil {
-				c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-					Message: fmt.Sprintf("error checking akamai auth: %s", err),
-				})
+				respondError(c, http.StatusBadRequest, apierror.InvalidCredentials("akamai", err))
 				return
 			}
 			clusterDefinition.AkamaiAuth = pkgtypes.AkamaiAuth{
 				Token: k1AuthSecret["akamai-token"],
 			}
 		} else if clusterDefinition.AkamaiAuth.Token == "" {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: "missing authentication credentials in request, please check and try again",
-			})
+			respondError(c, http.StatusBadRequest, apierror.InvalidCredentials("akamai", errors.New("missing authentication credentials in request, please check and try again")))
 			return
 		}
 
-		go func() {
-			err = akamai.CreateAkamaiCluster(&clusterDefinition)
-			if err != nil {
-				log.Error().Msg(err.Error())
-			}
-		}()
+		runOperation(c, metrics.OperationCreate, clusterDefinition.ClusterName, func(ctx context.Context) error {
+			defer metrics.TrackOperation(metrics.OperationCreate, clusterDefinition.CloudProvider)()
+
+			return akamai.CreateAkamaiCluster(ctx, env, &clusterDefinition)
+		})
 
 		c.JSON(http.StatusAccepted, types.JSONSuccessResponse{
 			Message: "cluster create enqueued",
@@ -398,9 +384,7 @@ func PostCreateCluster(c *gin.Context) {
 		if useSecretForAuth {
 			err := utils.ValidateAuthenticationFields(k1AuthSecret)
 			if err != nil {
-				c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-					Message: fmt.Sprintf("error checking aws auth: %s", err),
-				})
+				respondError(c, http.StatusBadRequest, apierror.InvalidCredentials("aws", err))
 				return
 			}
 			clusterDefinition.AWSAuth = pkgtypes.AWSAuth{
@@ -411,17 +395,14 @@ func PostCreateCluster(c *gin.Context) {
 		} else if clusterDefinition.AWSAuth.AccessKeyID == "" ||
 			clusterDefinition.AWSAuth.SecretAccessKey == "" ||
 			clusterDefinition.AWSAuth.SessionToken == "" {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: "missing authentication credentials in request, please check and try again",
-			})
+			respondError(c, http.StatusBadRequest, apierror.InvalidCredentials("aws", errors.New("missing authentication credentials in request, please check and try again")))
 			return
 		}
-		go func() {
-			err = aws.CreateAWSCluster(&clusterDefinition)
-			if err != nil {
-				log.Error().Msg(err.Error())
-			}
-		}()
+		runOperation(c, metrics.OperationCreate, clusterDefinition.ClusterName, func(ctx context.Context) error {
+			defer metrics.TrackOperation(metrics.OperationCreate, clusterDefinition.CloudProvider)()
+
+			return aws.CreateAWSCluster(ctx, env, &clusterDefinition)
+		})
 
 		c.JSON(http.StatusAccepted, types.JSONSuccessResponse{
 			Message: "cluster create enqueued",
@@ -430,9 +411,7 @@ func PostCreateCluster(c *gin.Context) {
 		if useSecretForAuth {
 			err := utils.ValidateAuthenticationFields(k1AuthSecret)
 			if err != nil {
-				c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-					Message: fmt.Sprintf("error checking azure auth: %s", err),
-				})
+				respondError(c, http.StatusBadRequest, apierror.InvalidCredentials("azure", err))
 				return
 			}
 			clusterDefinition.CivoAuth = pkgtypes.CivoAuth{
@@ -442,17 +421,14 @@ func PostCreateCluster(c *gin.Context) {
 			clusterDefinition.AzureAuth.ClientSecret == "" ||
 			clusterDefinition.AzureAuth.SubscriptionID == "" ||
 			clusterDefinition.AzureAuth.TenantID == "" {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: "missing authentication credentials in request, please check and try again",
-			})
+			respondError(c, http.StatusBadRequest, apierror.InvalidCredentials("azure", errors.New("missing authentication credentials in request, please check and try again")))
 			return
 		}
-		go func() {
-			err = azure.CreateAzureCluster(&clusterDefinition)
-			if err != nil {
-				log.Error().Msg(err.Error())
-			}
-		}()
+		runOperation(c, metrics.OperationCreate, clusterDefinition.ClusterName, func(ctx context.Context) error {
+			defer metrics.TrackOperation(metrics.OperationCreate, clusterDefinition.CloudProvider)()
+
+			return azure.CreateAzureCluster(ctx, env, &clusterDefinition)
+		})
 
 		c.JSON(http.StatusAccepted, types.JSONSuccessResponse{
 			Message: "cluster create enqueued",
@@ -461,27 +437,22 @@ func PostCreateCluster(c *gin.Context) {
 		if useSecretForAuth {
 			err := utils.ValidateAuthenticationFields(k1AuthSecret)
 			if err != nil {
-				c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-					Message: fmt.Sprintf("error checking civo auth: %s", err),
-				})
+				respondError(c, http.StatusBadRequest, apierror.InvalidCredentials("civo", err))
 				return
 			}
 			clusterDefinition.CivoAuth = pkgtypes.CivoAuth{
 				Token: k1AuthSecret["civo-token"],
 			}
 		} else if clusterDefinition.CivoAuth.Token == "" {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: "missing authentication credentials in request, please check and try again",
-			})
+			respondError(c, http.StatusBadRequest, apierror.InvalidCredentials("civo", errors.New("missing authentication credentials in request, please check and try again")))
 			return
 		}
 
-		go func() {
-			err = civo.CreateCivoCluster(&clusterDefinition)
-			if err != nil {
-				log.Error().Msg(err.Error())
-			}
-		}()
+		runOperation(c, metrics.OperationCreate, clusterDefinition.ClusterName, func(ctx context.Context) error {
+			defer metrics.TrackOperation(metrics.OperationCreate, clusterDefinition.CloudProvider)()
+
+			return civo.CreateCivoCluster(ctx, env, &clusterDefinition)
+		})
 
 		c.JSON(http.StatusAccepted, types.JSONSuccessResponse{
 			Message: "cluster create enqueued",
@@ -490,9 +461,7 @@ func PostCreateCluster(c *gin.Context) {
 		if useSecretForAuth {
 			err := utils.ValidateAuthenticationFields(k1AuthSecret)
 			if err != nil {
-				c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-					Message: fmt.Sprintf("error checking digitalocean auth: %s", err),
-				})
+				respondError(c, http.StatusBadRequest, apierror.InvalidCredentials("digitalocean", err))
 				return
 			}
 			clusterDefinition.DigitaloceanAuth = pkgtypes.DigitaloceanAuth{
@@ -503,18 +472,15 @@ func PostCreateCluster(c *gin.Context) {
 		} else if clusterDefinition.DigitaloceanAuth.Token == "" ||
 			clusterDefinition.DigitaloceanAuth.SpacesKey == "" ||
 			clusterDefinition.DigitaloceanAuth.SpacesSecret == "" {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: "missing authentication credentials in request, please check and try again",
-			})
+			respondError(c, http.StatusBadRequest, apierror.InvalidCredentials("digitalocean", errors.New("missing authentication credentials in request, please check and try again")))
 			return
 		}
 
-		go func() {
-			err = digitalocean.CreateDigitaloceanCluster(&clusterDefinition)
-			if err != nil {
-				log.Error().Msg(err.Error())
-			}
-		}()
+		runOperation(c, metrics.OperationCreate, clusterDefinition.ClusterName, func(ctx context.Context) error {
+			defer metrics.TrackOperation(metrics.OperationCreate, clusterDefinition.CloudProvider)()
+
+			return digitalocean.CreateDigitaloceanCluster(ctx, env, &clusterDefinition)
+		})
 
 		c.JSON(http.StatusAccepted, types.JSONSuccessResponse{
 			Message: "cluster create enqueued",
@@ -523,27 +489,22 @@ func PostCreateCluster(c *gin.Context) {
 		if useSecretForAuth {
 			err := utils.ValidateAuthenticationFields(k1AuthSecret)
 			if err != nil {
-				c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-					Message: fmt.Sprintf("error checking vultr auth: %s", err),
-				})
+				respondError(c, http.StatusBadRequest, apierror.InvalidCredentials("vultr", err))
 				return
 			}
 			clusterDefinition.VultrAuth = pkgtypes.VultrAuth{
 				Token: k1AuthSecret["vultr-api-key"],
 			}
 		} else if clusterDefinition.VultrAuth.Token == "" {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: "missing authentication credentials in request, please check and try again",
-			})
+			respondError(c, http.StatusBadRequest, apierror.InvalidCredentials("vultr", errors.New("missing authentication credentials in request, please check and try again")))
 			return
 		}
 
-		go func() {
-			err = vultr.CreateVultrCluster(&clusterDefinition)
-			if err != nil {
-				log.Error().Msg(err.Error())
-			}
-		}()
+		runOperation(c, metrics.OperationCreate, clusterDefinition.ClusterName, func(ctx context.Context) error {
+			defer metrics.TrackOperation(metrics.OperationCreate, clusterDefinition.CloudProvider)()
+
+			return vultr.CreateVultrCluster(ctx, env, &clusterDefinition)
+		})
 
 		c.JSON(http.StatusAccepted, types.JSONSuccessResponse{
 			Message: "cluster create enqueued",
@@ -552,9 +513,7 @@ func PostCreateCluster(c *gin.Context) {
 		if useSecretForAuth {
 			err := utils.ValidateAuthenticationFields(k1AuthSecret)
 			if err != nil {
-				c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-					Message: fmt.Sprintf("error checking google auth: %s", err),
-				})
+				respondError(c, http.StatusBadRequest, apierror.InvalidCredentials("google", err))
 				return
 			}
 			clusterDefinition.GoogleAuth = pkgtypes.GoogleAuth{
@@ -562,18 +521,15 @@ func PostCreateCluster(c *gin.Context) {
 				ProjectID: k1AuthSecret["ProjectId"],
 			}
 		} else if clusterDefinition.GoogleAuth.KeyFile == "" {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: "missing authentication credentials in request, please check and try again",
-			})
+			respondError(c, http.StatusBadRequest, apierror.InvalidCredentials("google", errors.New("missing authentication credentials in request, please check and try again")))
 			return
 		}
 
-		go func() {
-			err = google.CreateGoogleCluster(&clusterDefinition)
-			if err != nil {
-				log.Error().Msg(err.Error())
-			}
-		}()
+		runOperation(c, metrics.OperationCreate, clusterDefinition.ClusterName, func(ctx context.Context) error {
+			defer metrics.TrackOperation(metrics.OperationCreate, clusterDefinition.CloudProvider)()
+
+			return google.CreateGoogleCluster(ctx, env, &clusterDefinition)
+		})
 
 		c.JSON(http.StatusAccepted, types.JSONSuccessResponse{
 			Message: "cluster create enqueued",
@@ -583,9 +539,7 @@ func PostCreateCluster(c *gin.Context) {
 		if useSecretForAuth {
 			err := utils.ValidateAuthenticationFields(k1AuthSecret)
 			if err != nil {
-				c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-					Message: fmt.Sprintf("error checking k3s auth: %s", err),
-				})
+				respondError(c, http.StatusBadRequest, apierror.InvalidCredentials("k3s", err))
 				return
 			}
 			// force empty array if not server spubilc ips provided, to avoid errror of terraform tokenisation
@@ -604,19 +558,15 @@ func PostCreateCluster(c *gin.Context) {
 		} else if len(clusterDefinition.K3sAuth.K3sServersPrivateIps) == 0 ||
 			clusterDefinition.K3sAuth.K3sSSHUser == "" ||
 			clusterDefinition.K3sAuth.K3sSSHPrivateKey == "" {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				// Message: "missing authentication credentials in request, please check and try again",
-				Message: fmt.Sprintf("missing authentication credentials in request, please check and try again: %v", clusterDefinition.K3sAuth),
-			})
+			respondError(c, http.StatusBadRequest, apierror.InvalidCredentials("k3s", errors.New("missing authentication credentials in request, please check and try again")))
 			return
 		}
 
-		go func() {
-			err = k3s.CreateK3sCluster(&clusterDefinition)
-			if err != nil {
-				log.Error().Msg(err.Error())
-			}
-		}()
+		runOperation(c, metrics.OperationCreate, clusterDefinition.ClusterName, func(ctx context.Context) error {
+			defer metrics.TrackOperation(metrics.OperationCreate, clusterDefinition.CloudProvider)()
+
+			return k3s.CreateK3sCluster(ctx, env, &clusterDefinition)
+		})
 
 		c.JSON(http.StatusAccepted, types.JSONSuccessResponse{
 			Message: "cluster create enqueued",
@@ -624,7 +574,7 @@ func PostCreateCluster(c *gin.Context) {
 	}
 }
 
-// PostExportCluster godoc
+// GetExportCluster godoc
 //
 //	@Summary		Export a Kubefirst cluster database entry
 //	@Description	Export a Kubefirst cluster database entry
@@ -634,16 +584,14 @@ func PostCreateCluster(c *gin.Context) {
 //	@Param			cluster_name	path		string	true	"Cluster name"
 //	@Success		202				{object}	types.JSONSuccessResponse
 //	@Failure		400				{object}	types.JSONFailureResponse
-//	@Router			/cluster/:cluster_name/export [post]
+//	@Router			/cluster/:cluster_name/export [get]
 //	@Param			Authorization	header	string	true	"API key"	default(Bearer <API key>)
 //
-// PostExportCluster handles a request to export a cluster
+// GetExportCluster handles a request to export a cluster
 func GetExportCluster(c *gin.Context) {
 	clusterName, param := c.Params.Get("cluster_name")
 	if !param {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: ":cluster_name not provided",
-		})
+		respondError(c, http.StatusBadRequest, errors.New(":cluster_name not provided"))
 		return
 	}
 
@@ -652,9 +600,7 @@ func GetExportCluster(c *gin.Context) {
 	// get cluster object
 	cluster, err := secrets.GetCluster(kcfg.Clientset, clusterName)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -665,18 +611,14 @@ func GetExportCluster(c *gin.Context) {
 func GetClusterKubeConfig(c *gin.Context) {
 	cloudProvider, param := c.Params.Get("cloud_provider")
 	if !param {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: ":cloud_provider not provided",
-		})
+		respondError(c, http.StatusBadRequest, errors.New(":cloud_provider not provided"))
 		return
 	}
 
 	var kubeConfigRequest types.KubeconfigRequest
 	err := c.Bind(&kubeConfigRequest)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -688,27 +630,21 @@ func GetClusterKubeConfig(c *gin.Context) {
 	// Handle virtual cluster kubeconfig
 	if VCluster {
 		if kubeConfigRequest.ManagClusterName == "" {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: "missing man_cluster_name",
-			})
+			respondError(c, http.StatusBadRequest, errors.New("missing man_cluster_name"))
 			return
 		}
 
 		kcfg := utils.GetKubernetesClient(kubeConfigRequest.ClusterName)
 		internalSecret, err := k8s.ReadSecretV2(kcfg.Clientset, kubeConfigRequest.ClusterName, fmt.Sprintf("vc-%v", kubeConfigRequest.ClusterName))
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: err.Error(),
-			})
+			respondError(c, http.StatusBadRequest, err)
 			return
 		}
 
 		config, exists := internalSecret["config"]
 
 		if !exists {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: "Unable to locate kubeconfig",
-			})
+			respondError(c, http.StatusBadRequest, errors.New("Unable to locate kubeconfig"))
 			return
 		}
 
@@ -723,16 +659,12 @@ func GetClusterKubeConfig(c *gin.Context) {
 	case "civo":
 
 		if kubeConfigRequest.CivoAuth.Token == "" {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: "missing civo auth token",
-			})
+			respondError(c, http.StatusBadRequest, apierror.InvalidCredentials("civo", errors.New("missing civo auth token")))
 			return
 		}
 
 		if kubeConfigRequest.CloudRegion == "" {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: "missing cloud region",
-			})
+			respondError(c, http.StatusBadRequest, errors.New("missing cloud region"))
 			return
 		}
 
@@ -743,9 +675,7 @@ func GetClusterKubeConfig(c *gin.Context) {
 
 		config, err := civoConfig.GetKubeconfig(kubeConfigRequest.ClusterName)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: err.Error(),
-			})
+			respondError(c, http.StatusBadRequest, err)
 			return
 		}
 
@@ -761,9 +691,7 @@ func GetClusterKubeConfig(c *gin.Context) {
 
 		config, err := digitaloceanConf.GetKubeconfig(kubeConfigRequest.ClusterName)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: err.Error(),
-			})
+			respondError(c, http.StatusBadRequest, err)
 			return
 		}
 
@@ -780,9 +708,7 @@ func GetClusterKubeConfig(c *gin.Context) {
 
 		config, err := vultrConf.GetKubeconfig(kubeConfigRequest.ClusterName)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: err.Error(),
-			})
+			respondError(c, http.StatusBadRequest, err)
 			return
 		}
 
@@ -791,9 +717,7 @@ func GetClusterKubeConfig(c *gin.Context) {
 		})
 
 	default:
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: fmt.Sprintf("provided cloud provider: %v not implemented", cloudProvider),
-		})
+		respondError(c, http.StatusBadRequest, fmt.Errorf("provided cloud provider: %v not implemented", cloudProvider))
 		return
 	}
 }
@@ -817,9 +741,7 @@ func PostImportCluster(c *gin.Context) {
 	var cluster pkgtypes.Cluster
 	err := c.Bind(&cluster)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -828,9 +750,7 @@ func PostImportCluster(c *gin.Context) {
 	// Insert the cluster into the target database
 	err = secrets.InsertCluster(kcfg.Clientset, cluster)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -844,9 +764,7 @@ func PostImportCluster(c *gin.Context) {
 	err = gitShim.PrepareMgmtCluster(cluster)
 	if err != nil {
 		log.Error().Msgf("error cloning repository: %s", err)
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: fmt.Sprintf("error importing cluster %s: %s", cluster.ClusterName, err),
-		})
+		respondError(c, http.StatusBadRequest, fmt.Errorf("error importing cluster %s: %w", cluster.ClusterName, err))
 		return
 	}
 
@@ -854,9 +772,7 @@ func PostImportCluster(c *gin.Context) {
 	cluster.InProgress = false
 	err = secrets.UpdateCluster(kcfg.Clientset, cluster)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -882,9 +798,7 @@ func PostImportCluster(c *gin.Context) {
 func PostResetClusterProgress(c *gin.Context) {
 	clusterName, param := c.Params.Get("cluster_name")
 	if !param {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: ":cluster_name not provided",
-		})
+		respondError(c, http.StatusBadRequest, errors.New(":cluster_name not provided"))
 		return
 	}
 
@@ -896,9 +810,7 @@ func PostResetClusterProgress(c *gin.Context) {
 	cluster.InProgress = false
 	err := secrets.UpdateCluster(kcfg.Clientset, *cluster)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: fmt.Sprintf("error updating cluster %s: %s", clusterName, err),
-		})
+		respondError(c, http.StatusBadRequest, fmt.Errorf("error updating cluster %s: %w", clusterName, err))
 		return
 	}
 
@@ -924,9 +836,7 @@ func PostResetClusterProgress(c *gin.Context) {
 func PostCreateVcluster(c *gin.Context) {
 	clusterName, param := c.Params.Get("cluster_name")
 	if !param {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: ":cluster_name not provided",
-		})
+		respondError(c, http.StatusBadRequest, errors.New(":cluster_name not provided"))
 		return
 	}
 
@@ -938,14 +848,86 @@ func PostCreateVcluster(c *gin.Context) {
 		return
 	}
 
-	go func() {
-		err = environments.CreateDefaultClusters(*cluster)
-		if err != nil {
-			log.Error().Msgf("Error creating default environments %s", err.Error())
+	env := middleware.GetConfig(c)
+	runOperation(c, shutdown.OperationVclusterCreate, clusterName, func(context.Context) error {
+		if err := environments.CreateDefaultClusters(env, *cluster); err != nil {
+			return fmt.Errorf("error creating default environments: %w", err)
 		}
-	}()
+		return nil
+	})
 
 	c.JSON(http.StatusOK, types.JSONSuccessResponse{
 		Message: "created default cluster environments enqueued",
 	})
 }
+
+// checkCertificateUsage verifies the LetsEncrypt budget of the cluster domain
+// covers the platform ingress hosts. Depending on mode a shortfall is logged
+// (warn), returned (block) or the check is skipped (off).
+func checkCertificateUsage(clusterDefinition *pkgtypes.ClusterDefinition, mode string) error {
+	if mode == "off" || clusterDefinition.CloudflareAuth.OriginCaIssuerKey != "" {
+		return nil
+	}
+
+	fullDomainName := clusterDefinition.DomainName
+	if clusterDefinition.SubdomainName != "" {
+		fullDomainName = fmt.Sprintf("%s.%s", clusterDefinition.SubdomainName, clusterDefinition.DomainName)
+	}
+
+	usage, err := certificates.GetCertificateUsage(clusterDefinition.DomainName)
+	if err != nil {
+		log.Warn().Msgf("unable to check LetsEncrypt certificate usage, continuing: %s", err)
+		return nil
+	}
+
+	err = usage.CheckBudget(certificates.PlatformIngressHosts(fullDomainName))
+	if err == nil {
+		log.Info().Msgf("domain %s has %d of %d weekly LetsEncrypt certificates left", usage.Domain, usage.Remaining, usage.Limit)
+		return nil
+	}
+	if mode == "block" {
+		return fmt.Errorf("cluster create blocked by LetsEncrypt rate limits: %w", err)
+	}
+
+	log.Warn().Msgf("cluster create may hit LetsEncrypt rate limits: %s", err)
+	return nil
+}
+
+// checkInterruptedOperation refuses to start operation on a cluster whose
+// interrupted operation differs, a create must not resume on a half deleted
+// cluster. Records interrupted before the operation was recorded can only be
+// deleted.
+func checkInterruptedOperation(cl *pkgtypes.Cluster, operation string) error {
+	if cl.Status != constants.ClusterStatusInterrupted {
+		return nil
+	}
+
+	interrupted := cl.InterruptedOperation
+	if interrupted == "" {
+		interrupted = metrics.OperationDelete
+	}
+	if interrupted == operation {
+		return nil
+	}
+
+	return apierror.New(apierror.CodeConflict, "cluster %s was interrupted during %s, retry the %s to resume it", cl.ClusterName, interrupted, interrupted).
+		WithDetail("cluster_name", cl.ClusterName).
+		WithDetail("interrupted_operation", interrupted)
+}
+
+// runOperation runs fn in the background with a context that continues the
+// trace of the request and is canceled when a shutdown stops waiting for it.
+// The operation is tracked before it starts so a shutdown cannot miss it.
+func runOperation(c *gin.Context, kind, clusterName string, fn func(ctx context.Context) error) {
+	release := shutdown.Track(kind, clusterName)
+	ctx, cancel := shutdown.Context(c.Request.Context())
+
+	go func() {
+		defer release()
+		defer cancel()
+
+		if err := fn(ctx); err != nil {
+			log.Error().Msg(err.Error())
+		}
+	}()
+}
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/konstructio/kubefirst-api/internal/constants"
+	"github.com/konstructio/kubefirst-api/internal/metrics"
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
+)
+
+func TestCheckInterruptedOperation(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      string
+		interrupted string
+		operation   string
+		wantErr     bool
+	}{
+		{name: "not interrupted", status: constants.ClusterStatusError, operation: metrics.OperationCreate},
+		{name: "create resumed", status: constants.ClusterStatusInterrupted, interrupted: metrics.OperationCreate, operation: metrics.OperationCreate},
+		{name: "delete resumed", status: constants.ClusterStatusInterrupted, interrupted: metrics.OperationDelete, operation: metrics.OperationDelete},
+		{name: "create of interrupted delete", status: constants.ClusterStatusInterrupted, interrupted: metrics.OperationDelete, operation: metrics.OperationCreate, wantErr: true},
+		{name: "delete of interrupted create", status: constants.ClusterStatusInterrupted, interrupted: metrics.OperationCreate, operation: metrics.OperationDelete, wantErr: true},
+		{name: "unknown kind deleted", status: constants.ClusterStatusInterrupted, operation: metrics.OperationDelete},
+		{name: "unknown kind created", status: constants.ClusterStatusInterrupted, operation: metrics.OperationCreate, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cl := &pkgtypes.Cluster{ClusterName: "dev", Status: tt.status, InterruptedOperation: tt.interrupted}
+			if err := checkInterruptedOperation(cl, tt.operation); (err != nil) != tt.wantErr {
+				t.Errorf("checkInterruptedOperation() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
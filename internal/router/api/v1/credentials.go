@@ -0,0 +1,213 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/konstructio/kubefirst-api/internal/apierror"
+	"github.com/konstructio/kubefirst-api/internal/argocd"
+	"github.com/konstructio/kubefirst-api/internal/secrets"
+	"github.com/konstructio/kubefirst-api/internal/shutdown"
+	"github.com/konstructio/kubefirst-api/internal/utils"
+	"github.com/konstructio/kubefirst-api/internal/vault"
+	"github.com/konstructio/kubefirst-api/pkg/credentials"
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
+	log "github.com/rs/zerolog/log"
+	"k8s.io/client-go/kubernetes"
+)
+
+// GetClusterCredentials godoc
+//
+//	@Summary		Return the platform credentials of a cluster
+//	@Description	Return the Vault, Argo CD and kbot credentials of a cluster. Every access is recorded in the cluster credentials audit log.
+//	@Tags			cluster
+//	@Accept			json
+//	@Produce		json
+//	@Param			cluster_name	path		string	true	"Cluster name"
+//	@Success		200				{object}	credentials.PlatformCredentials
+//	@Failure		400				{object}	types.JSONFailureResponse
+//	@Failure		404				{object}	types.JSONFailureResponse
+//	@Failure		500				{object}	types.JSONFailureResponse
+//	@Router			/cluster/:cluster_name/credentials [get]
+//	@Param			Authorization	header	string	true	"API key"	default(Bearer <API key>)
+//
+// GetClusterCredentials returns the platform credentials of a cluster
+func GetClusterCredentials(c *gin.Context) {
+	clusterName, param := c.Params.Get("cluster_name")
+	if !param {
+		respondError(c, http.StatusBadRequest, errors.New(":cluster_name not provided"))
+		return
+	}
+
+	kcfg := utils.GetKubernetesClient(clusterName)
+
+	cluster, ok := getCredentialsCluster(c, kcfg.Clientset, clusterName)
+	if !ok {
+		return
+	}
+
+	// credentials are only returned once the access is recorded
+	if err := recordCredentialsAccess(c, kcfg.Clientset, clusterName, "read"); err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	creds := credentials.GetPlatformCredentials(kcfg.Clientset, clusterDomainName(cluster))
+
+	// fall back to the values captured on the cluster record during provisioning
+	if creds.ArgoCD.Password == "" && cluster.ArgoCDPassword != "" {
+		creds.ArgoCD.Password = cluster.ArgoCDPassword
+		creds.ArgoCD.Message = ""
+	}
+	if creds.Kbot.Password == "" && cluster.VaultAuth.KbotPassword != "" {
+		creds.Kbot.Password = cluster.VaultAuth.KbotPassword
+		creds.Kbot.Message = ""
+	}
+
+	c.JSON(http.StatusOK, creds)
+}
+
+// PostRotateClusterCredentials godoc
+//
+//	@Summary		Rotate the platform credentials of a cluster
+//	@Description	Rotate the Argo CD admin password and the kbot password stored in Vault, and return the new credentials. Every rotation is recorded in the cluster credentials audit log.
+//	@Tags			cluster
+//	@Accept			json
+//	@Produce		json
+//	@Param			cluster_name	path		string	true	"Cluster name"
+//	@Success		200				{object}	credentials.PlatformCredentials
+//	@Failure		400				{object}	types.JSONFailureResponse
+//	@Failure		404				{object}	types.JSONFailureResponse
+//	@Failure		500				{object}	types.JSONFailureResponse
+//	@Router			/cluster/:cluster_name/credentials/rotate [post]
+//	@Param			Authorization	header	string	true	"API key"	default(Bearer <API key>)
+//
+// PostRotateClusterCredentials rotates the platform credentials of a cluster
+func PostRotateClusterCredentials(c *gin.Context) {
+	clusterName, param := c.Params.Get("cluster_name")
+	if !param {
+		respondError(c, http.StatusBadRequest, errors.New(":cluster_name not provided"))
+		return
+	}
+
+	kcfg := utils.GetKubernetesClient(clusterName)
+
+	cluster, ok := getCredentialsCluster(c, kcfg.Clientset, clusterName)
+	if !ok {
+		return
+	}
+
+	if cluster.InProgress {
+		respondError(c, http.StatusConflict, apierror.OperationInProgress(clusterName, "%s has an active process running, credentials cannot be rotated", clusterName))
+		return
+	}
+
+	defer shutdown.Track(shutdown.OperationCredentialRotation, clusterName)()
+
+	if err := recordCredentialsAccess(c, kcfg.Clientset, clusterName, "rotate"); err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	creds := credentials.GetPlatformCredentials(kcfg.Clientset, clusterDomainName(cluster))
+
+	// kbot is rotated with the scoped kubefirst-api token
+	vaultToken, err := vault.Conf.APIToken(creds.Vault.URL, kcfg.Clientset)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, fmt.Errorf("unable to rotate credentials: %w", err))
+		return
+	}
+
+	argoCDPassword, err := credentials.RotateArgoCDAdminPassword(kcfg.Clientset)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	creds.ArgoCD.Password = argoCDPassword
+	creds.ArgoCD.Message = ""
+
+	// the stored argocd token was issued to the previous password
+	argoCDToken, err := argocd.GetArgocdTokenV2(creds.ArgoCD.URL, "admin", argoCDPassword)
+	if err != nil {
+		log.Warn().Msgf("unable to refresh argocd auth token of cluster %s: %s", clusterName, err)
+	}
+
+	kbotPassword, kbotErr := credentials.RotateKbotPassword(creds.Vault.URL, vaultToken)
+	if kbotErr == nil {
+		creds.Kbot.Password = kbotPassword
+		creds.Kbot.Message = ""
+	}
+
+	// re-read the record right before updating to avoid overwriting concurrent changes
+	cluster, err = secrets.GetCluster(kcfg.Clientset, clusterName)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, fmt.Errorf("credentials were rotated but the cluster record could not be read: %w", err))
+		return
+	}
+	cluster.ArgoCDPassword = argoCDPassword
+	if argoCDToken != "" {
+		cluster.ArgoCDAuthToken = argoCDToken
+	}
+	if kbotErr == nil {
+		cluster.VaultAuth.KbotPassword = kbotPassword
+	}
+	if err := secrets.UpdateCluster(kcfg.Clientset, *cluster); err != nil {
+		respondError(c, http.StatusInternalServerError, fmt.Errorf("credentials were rotated but the cluster record could not be updated: %w", err))
+		return
+	}
+
+	if kbotErr != nil {
+		respondError(c, http.StatusInternalServerError, fmt.Errorf("the Argo CD admin password was rotated but the kbot password was not: %w", kbotErr))
+		return
+	}
+
+	c.JSON(http.StatusOK, creds)
+}
+
+// getCredentialsCluster retrieves a cluster record, writing the error response
+// when it cannot be found
+func getCredentialsCluster(c *gin.Context, clientset kubernetes.Interface, clusterName string) (*pkgtypes.Cluster, bool) {
+	cluster, err := secrets.GetCluster(clientset, clusterName)
+	if err != nil {
+		if errors.Is(err, &secrets.ClusterNotFoundError{}) {
+			respondError(c, http.StatusNotFound, err)
+			return nil, false
+		}
+
+		respondError(c, http.StatusInternalServerError, fmt.Errorf("unable to find cluster: %w", err))
+		return nil, false
+	}
+
+	return cluster, true
+}
+
+// recordCredentialsAccess adds the request to the credentials audit log
+func recordCredentialsAccess(c *gin.Context, clientset kubernetes.Interface, clusterName, action string) error {
+	err := secrets.RecordCredentialsAccess(clientset, clusterName, pkgtypes.CredentialsAuditEntry{
+		Timestamp: time.Now().UTC(),
+		Action:    action,
+		ClientIP:  c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+	if err != nil {
+		return fmt.Errorf("unable to record credentials access: %w", err)
+	}
+	return nil
+}
+
+// clusterDomainName returns the domain the platform of a cluster is served under
+func clusterDomainName(cluster *pkgtypes.Cluster) string {
+	if cluster.SubdomainName != "" {
+		return fmt.Sprintf("%s.%s", cluster.SubdomainName, cluster.DomainName)
+	}
+	return cluster.DomainName
+}
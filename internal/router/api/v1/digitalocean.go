@@ -8,9 +8,11 @@ package api
 
 import (
 	"context"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/konstructio/kubefirst-api/internal/apierror"
 	"github.com/konstructio/kubefirst-api/internal/digitalocean"
 	"github.com/konstructio/kubefirst-api/internal/types"
 )
@@ -18,18 +20,14 @@ import (
 func PostValidateDigitalOceanDomain(c *gin.Context) {
 	domainName, exists := c.Params.Get("domain")
 	if !exists {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: ":domain parameter not provided in request",
-		})
+		respondError(c, http.StatusBadRequest, errors.New(":domain parameter not provided in request"))
 		return
 	}
 
 	var request types.DigitalOceanDomainValidationRequest
 	err := c.Bind(&request)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -40,9 +38,7 @@ func PostValidateDigitalOceanDomain(c *gin.Context) {
 
 	validated := digitaloceanConf.TestDomainLiveness(domainName)
 	if !validated {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: "domain validation failed",
-		})
+		respondError(c, http.StatusBadRequest, apierror.DNSNotDelegated(domainName, "domain validation failed"))
 		return
 	}
 
@@ -8,18 +8,22 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 
 	cloudflare_api "github.com/cloudflare/cloudflare-go"
 	"github.com/gin-gonic/gin"
+	"github.com/konstructio/kubefirst-api/internal/apierror"
 	awsinternal "github.com/konstructio/kubefirst-api/internal/aws"
 	"github.com/konstructio/kubefirst-api/internal/azure"
 	"github.com/konstructio/kubefirst-api/internal/civo"
 	cloudflare "github.com/konstructio/kubefirst-api/internal/cloudflare"
 	"github.com/konstructio/kubefirst-api/internal/digitalocean"
+	"github.com/konstructio/kubefirst-api/internal/httpCommon"
 	"github.com/konstructio/kubefirst-api/internal/types"
 	"github.com/konstructio/kubefirst-api/internal/vultr"
+	"github.com/konstructio/kubefirst-api/pkg/certificates"
 	"github.com/konstructio/kubefirst-api/pkg/google"
 	"github.com/linode/linodego"
 	"golang.org/x/oauth2"
@@ -42,9 +46,7 @@ import (
 func PostDomains(c *gin.Context) {
 	dnsProvider, param := c.Params.Get("dns_provider")
 	if !param {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: ":dns_provider not provided",
-		})
+		respondError(c, http.StatusBadRequest, errors.New(":dns_provider not provided"))
 		return
 	}
 
@@ -52,9 +54,7 @@ func PostDomains(c *gin.Context) {
 	var domainListRequest types.DomainListRequest
 	err := c.Bind(&domainListRequest)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -67,6 +67,7 @@ func PostDomains(c *gin.Context) {
 		oauth2Client := &http.Client{
 			Transport: &oauth2.Transport{
 				Source: tokenSource,
+				Base:   httpCommon.TracedTransport(),
 			},
 		}
 
@@ -74,9 +75,7 @@ func PostDomains(c *gin.Context) {
 
 		domains, err := client.ListDomains(context.Background(), &linodego.ListOptions{})
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.JSONFailureResponse{
-				Message: err.Error(),
-			})
+			respondError(c, http.StatusInternalServerError, apierror.Upstream("akamai", err))
 			return
 		}
 
@@ -91,9 +90,7 @@ func PostDomains(c *gin.Context) {
 		if domainListRequest.AWSAuth.AccessKeyID == "" ||
 			domainListRequest.AWSAuth.SecretAccessKey == "" ||
 			domainListRequest.AWSAuth.SessionToken == "" {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: "missing authentication credentials in request, please check and try again",
-			})
+			respondError(c, http.StatusBadRequest, apierror.InvalidCredentials("aws", errors.New("missing authentication credentials in request, please check and try again")))
 			return
 		}
 
@@ -104,9 +101,7 @@ func PostDomains(c *gin.Context) {
 			domainListRequest.AWSAuth.SessionToken,
 		)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.JSONFailureResponse{
-				Message: fmt.Sprintf("error creating aws client: %v", err),
-			})
+			respondError(c, http.StatusInternalServerError, apierror.Upstream("aws", fmt.Errorf("error creating aws client: %w", err)))
 			return
 		}
 
@@ -114,9 +109,7 @@ func PostDomains(c *gin.Context) {
 
 		domains, err := awsConf.GetHostedZones()
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: err.Error(),
-			})
+			respondError(c, http.StatusBadRequest, apierror.Upstream("aws", err))
 			return
 		}
 		domainListResponse.Domains = domains
@@ -124,9 +117,7 @@ func PostDomains(c *gin.Context) {
 	case "azure":
 		err = domainListRequest.AzureAuth.ValidateAuthCredentials()
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: err.Error(),
-			})
+			respondError(c, http.StatusBadRequest, apierror.InvalidCredentials("azure", err))
 			return
 		}
 
@@ -137,17 +128,13 @@ func PostDomains(c *gin.Context) {
 			domainListRequest.AzureAuth.TenantID,
 		)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.JSONFailureResponse{
-				Message: err.Error(),
-			})
+			respondError(c, http.StatusInternalServerError, apierror.Upstream("azure", err))
 			return
 		}
 
 		domains, err := azureClient.ListDomains(context.Background())
 		if err != nil {
-			c.JSON(http.StatusServiceUnavailable, types.JSONFailureResponse{
-				Message: err.Error(),
-			})
+			respondError(c, http.StatusServiceUnavailable, apierror.Upstream("azure", err))
 			return
 		}
 
@@ -160,17 +147,13 @@ func PostDomains(c *gin.Context) {
 	case "cloudflare":
 		// check for token, make sure it aint blank
 		if domainListRequest.CloudflareAuth.APIToken == "" {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: "missing authentication credentials in request, please check and try again",
-			})
+			respondError(c, http.StatusBadRequest, apierror.InvalidCredentials("cloudflare", errors.New("missing authentication credentials in request, please check and try again")))
 			return
 		}
 
 		client, err := cloudflare_api.NewWithAPIToken(domainListRequest.CloudflareAuth.APIToken)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.JSONFailureResponse{
-				Message: fmt.Sprintf("Could not create cloudflare client, %v", err),
-			})
+			respondError(c, http.StatusInternalServerError, apierror.Upstream("cloudflare", fmt.Errorf("Could not create cloudflare client, %w", err)))
 			return
 		}
 
@@ -181,9 +164,7 @@ func PostDomains(c *gin.Context) {
 
 		domains, err := cloudflareConf.GetDNSDomains()
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.JSONFailureResponse{
-				Message: err.Error(),
-			})
+			respondError(c, http.StatusInternalServerError, apierror.Upstream("cloudflare", err))
 			return
 		}
 
@@ -191,9 +172,7 @@ func PostDomains(c *gin.Context) {
 
 	case "civo":
 		if domainListRequest.CivoAuth.Token == "" {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: "missing authentication credentials in request, please check and try again",
-			})
+			respondError(c, http.StatusBadRequest, apierror.InvalidCredentials("civo", errors.New("missing authentication credentials in request, please check and try again")))
 			return
 		}
 		civoConf := civo.Configuration{
@@ -203,17 +182,13 @@ func PostDomains(c *gin.Context) {
 
 		domains, err := civoConf.GetDNSDomains()
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.JSONFailureResponse{
-				Message: err.Error(),
-			})
+			respondError(c, http.StatusInternalServerError, apierror.Upstream("civo", err))
 			return
 		}
 		domainListResponse.Domains = domains
 	case "digitalocean":
 		if domainListRequest.DigitaloceanAuth.Token == "" {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: "missing authentication credentials in request, please check and try again",
-			})
+			respondError(c, http.StatusBadRequest, apierror.InvalidCredentials("digitalocean", errors.New("missing authentication credentials in request, please check and try again")))
 			return
 		}
 		digitaloceanConf := digitalocean.Configuration{
@@ -223,17 +198,13 @@ func PostDomains(c *gin.Context) {
 
 		domains, err := digitaloceanConf.GetDNSDomains()
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.JSONFailureResponse{
-				Message: err.Error(),
-			})
+			respondError(c, http.StatusInternalServerError, apierror.Upstream("digitalocean", err))
 			return
 		}
 		domainListResponse.Domains = domains
 	case "vultr":
 		if domainListRequest.VultrAuth.Token == "" {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: "missing authentication credentials in request, please check and try again",
-			})
+			respondError(c, http.StatusBadRequest, apierror.InvalidCredentials("vultr", errors.New("missing authentication credentials in request, please check and try again")))
 			return
 		}
 		vultrConf := vultr.Configuration{
@@ -243,17 +214,13 @@ func PostDomains(c *gin.Context) {
 
 		domains, err := vultrConf.GetDNSDomains()
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.JSONFailureResponse{
-				Message: err.Error(),
-			})
+			respondError(c, http.StatusInternalServerError, apierror.Upstream("vultr", err))
 			return
 		}
 		domainListResponse.Domains = domains
 	case "google":
 		if domainListRequest.GoogleAuth.ProjectID == "" {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: "missing authentication credentials in request, please check and try again",
-			})
+			respondError(c, http.StatusBadRequest, apierror.InvalidCredentials("google", errors.New("missing authentication credentials in request, please check and try again")))
 			return
 		}
 
@@ -266,20 +233,47 @@ func PostDomains(c *gin.Context) {
 
 		domains, err := googleConf.GetDNSDomains()
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.JSONFailureResponse{
-				Message: err.Error(),
-			})
+			respondError(c, http.StatusInternalServerError, apierror.Upstream("google", err))
 			return
 		}
 
 		domainListResponse.Domains = domains
 
 	default:
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: fmt.Sprintf("unsupported provider: %s", dnsProvider),
-		})
+		respondError(c, http.StatusBadRequest, fmt.Errorf("unsupported provider: %s", dnsProvider))
 		return
 	}
 
 	c.JSON(http.StatusOK, domainListResponse)
 }
+
+// GetCertificateUsage godoc
+//
+//	@Summary		Return the LetsEncrypt certificate usage of a domain
+//	@Description	Return the LetsEncrypt certificates issued for a domain during the current weekly rate limit window
+//	@Tags			domain
+//	@Accept			json
+//	@Produce		json
+//	@Param			domain	path		string	true	"Domain name"
+//	@Success		200		{object}	certificates.CertificateUsage
+//	@Failure		400		{object}	types.JSONFailureResponse
+//	@Failure		502		{object}	types.JSONFailureResponse
+//	@Router			/domain/:domain/certificate-usage [get]
+//	@Param			Authorization	header	string	true	"API key"	default(Bearer <API key>)
+//
+// GetCertificateUsage returns the LetsEncrypt certificate usage of a domain
+func GetCertificateUsage(c *gin.Context) {
+	domain, param := c.Params.Get("domain")
+	if !param || domain == "" {
+		respondError(c, http.StatusBadRequest, errors.New(":domain not provided"))
+		return
+	}
+
+	usage, err := certificates.GetCertificateUsage(domain)
+	if err != nil {
+		respondError(c, http.StatusBadGateway, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, usage)
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -16,9 +17,7 @@ func GetEnvironments(c *gin.Context) {
 	kcfg := utils.GetKubernetesClient("TODO: SECRETS")
 	environments, err := secrets.GetEnvironments(kcfg.Clientset)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -30,17 +29,13 @@ func CreateEnvironment(c *gin.Context) {
 	var environmentDefinition pkgtypes.Environment
 	err := c.Bind(&environmentDefinition)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
 	newEnv, err := environments.NewEnvironment(environmentDefinition)
 	if err != nil {
-		c.JSON(http.StatusConflict, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusConflict, err)
 		return
 	}
 
@@ -51,18 +46,14 @@ func DeleteEnvironment(c *gin.Context) {
 	envID, param := c.Params.Get("environment_id")
 
 	if !param {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: ":environment_id not provided",
-		})
+		respondError(c, http.StatusBadRequest, errors.New(":environment_id not provided"))
 		return
 	}
 
 	kcfg := utils.GetKubernetesClient("TODO: SECRETS")
 	err := secrets.DeleteEnvironment(kcfg.Clientset, envID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -75,25 +66,19 @@ func UpdateEnvironment(c *gin.Context) {
 	envID, param := c.Params.Get("environment_id")
 
 	if !param {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: ":environment_id not provided",
-		})
+		respondError(c, http.StatusBadRequest, errors.New(":environment_id not provided"))
 		return
 	}
 
 	var environmentUpdate types.EnvironmentUpdateRequest
 	err := c.Bind(&environmentUpdate)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
 	if environmentUpdate.Color == "" && environmentUpdate.Description == "" {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: "please provide a description and or color to update",
-		})
+		respondError(c, http.StatusBadRequest, errors.New("please provide a description and or color to update"))
 		return
 	}
 
@@ -101,9 +86,7 @@ func UpdateEnvironment(c *gin.Context) {
 	updateErr := secrets.UpdateEnvironment(kcfg.Clientset, envID, environmentUpdate)
 
 	if updateErr != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: updateErr.Error(),
-		})
+		respondError(c, http.StatusBadRequest, updateErr)
 		return
 	}
 
@@ -0,0 +1,19 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/konstructio/kubefirst-api/internal/apierror"
+)
+
+// respondError writes the error envelope of err with the status of its code,
+// errors without a code are given the code of status
+func respondError(c *gin.Context, status int, err error) {
+	e := apierror.FromStatus(status, err)
+	c.JSON(e.Status(), e.Response())
+}
@@ -7,24 +7,40 @@ See the LICENSE file for more details.
 package api
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/konstructio/kubefirst-api/internal/gitopsCatalog"
+	"github.com/konstructio/kubefirst-api/internal/metrics"
 	"github.com/konstructio/kubefirst-api/internal/secrets"
 	"github.com/konstructio/kubefirst-api/internal/types"
 	"github.com/konstructio/kubefirst-api/internal/utils"
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes"
 )
 
 // GetGitopsCatalogApps godoc
 //
 //	@Summary		Returns a list of available Kubefirst gitops catalog applications
-//	@Description	Returns a list of available Kubefirst gitops catalog applications
+//	@Description	Returns a filtered, paginated list of available Kubefirst gitops catalog applications
 //	@Tags			gitops-catalog
 //	@Accept			json
 //	@Produce		json
-//	@Success		200	{object}	types.GitopsCatalogApps
-//	@Failure		400	{object}	types.JSONFailureResponse
+//	@Param			cluster_name	path		string	true	"Cluster name"
+//	@Param			cloud_provider	path		string	true	"Cloud provider"
+//	@Param			category		query		string	false	"Only return apps in this category"
+//	@Param			q				query		string	false	"Search the app display name and description"
+//	@Param			installed		query		bool	false	"Only return apps that are (true) or are not (false) installed on the cluster"
+//	@Param			page			query		int		false	"Page number, starting at 1"
+//	@Param			page_size		query		int		false	"Apps per page, all apps when omitted"
+//	@Success		200				{object}	types.GitopsCatalogAppsPage
+//	@Failure		400				{object}	types.JSONFailureResponse
+//	@Failure		404				{object}	types.JSONFailureResponse
 //	@Router			/gitops-catalog/:cluster_name/:cloud_provider/apps [get]
 //	@Param			Authorization	header	string	true	"API key"	default(Bearer <API key>)
 //
@@ -32,38 +48,169 @@ import (
 func GetGitopsCatalogApps(c *gin.Context) {
 	cloudProvider, param := c.Params.Get("cloud_provider")
 	if !param {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: ":cloud_provider not provided",
-		})
+		respondError(c, http.StatusBadRequest, errors.New(":cloud_provider not provided"))
 		return
 	}
 
 	clusterName, param := c.Params.Get("cluster_name")
 	if !param {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: ":cluster_name not provided",
-		})
+		respondError(c, http.StatusBadRequest, errors.New(":cluster_name not provided"))
+		return
+	}
+
+	filter := gitopsCatalog.AppFilter{
+		Category: c.Query("category"),
+		Search:   c.Query("q"),
+	}
+
+	page, err := queryInt(c, "page", 1, 1)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	pageSize, err := queryInt(c, "page_size", 0, 0)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
 	kcfg := utils.GetKubernetesClient(clusterName)
-	cluster, err := secrets.GetCluster(kcfg.Clientset, clusterName)
+	cluster, err := catalogCluster(kcfg.Clientset, clusterName)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: "cluster not found",
-		})
+		if errors.Is(err, &secrets.ClusterNotFoundError{}) {
+			respondError(c, http.StatusNotFound, err)
+			return
+		}
+
+		respondError(c, http.StatusInternalServerError, err)
 		return
 	}
 
+	if installed, ok := c.GetQuery("installed"); ok {
+		wantInstalled, err := strconv.ParseBool(installed)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, errors.New("installed must be true or false"))
+			return
+		}
+		filter.Installed = &wantInstalled
+
+		filter.InstalledApps, err = installedServices(kcfg.Clientset, cluster, clusterName)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				respondError(c, http.StatusNotFound, err)
+				return
+			}
+
+			respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
 	apps, err := secrets.GetGitopsCatalogAppsByCloudProvider(kcfg.Clientset, cloudProvider, cluster.GitProvider)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, apps)
+	filtered := gitopsCatalog.FilterApps(apps.Apps, filter)
+	if pageSize == 0 {
+		pageSize = len(filtered)
+	}
+
+	c.JSON(http.StatusOK, pkgtypes.GitopsCatalogAppsPage{
+		Name:     apps.Name,
+		Apps:     gitopsCatalog.Paginate(filtered, page, pageSize),
+		Total:    len(filtered),
+		Page:     page,
+		PageSize: pageSize,
+	})
+}
+
+// catalogCluster returns the record of a cluster. Workload clusters have no
+// record of their own, the record of their management cluster is returned.
+func catalogCluster(clientset kubernetes.Interface, clusterName string) (*pkgtypes.Cluster, error) {
+	cluster, err := secrets.GetCluster(clientset, clusterName)
+	if !errors.Is(err, &secrets.ClusterNotFoundError{}) {
+		return cluster, err
+	}
+
+	clusters, listErr := secrets.GetClusters(clientset)
+	if listErr != nil {
+		return nil, fmt.Errorf("unable to list clusters: %w", listErr)
+	}
+
+	for i := range clusters {
+		for _, wc := range clusters[i].WorkloadClusters {
+			if wc.ClusterName == clusterName {
+				return &clusters[i], nil
+			}
+		}
+	}
+
+	return nil, err
+}
+
+// installedServices returns the names of the services installed on a cluster.
+// The service lists of workload clusters are kept by the management cluster
+// and only created with their first service, so a workload cluster without one
+// has nothing installed.
+func installedServices(clientset kubernetes.Interface, cluster *pkgtypes.Cluster, clusterName string) ([]string, error) {
+	clusterServices, err := secrets.GetServices(clientset, clusterName)
+	if err != nil {
+		if apierrors.IsNotFound(err) && cluster.ClusterName != clusterName {
+			return []string{}, nil
+		}
+		return nil, fmt.Errorf("unable to list services of cluster %s: %w", clusterName, err)
+	}
+
+	names := []string{}
+	for _, svc := range clusterServices.Services {
+		names = append(names, svc.Name)
+	}
+	return names, nil
+}
+
+// GetGitopsCatalogCategories godoc
+//
+//	@Summary		Returns the gitops catalog categories
+//	@Description	Returns each gitops catalog category with the number of applications it holds
+//	@Tags			gitops-catalog
+//	@Accept			json
+//	@Produce		json
+//	@Param			cloud_provider	query		string	true	"Cloud provider of the cluster, its denylisted apps are not counted"
+//	@Param			git_provider	query		string	true	"Git provider of the cluster, its denylisted apps are not counted"
+//	@Success		200				{object}	types.GitopsCatalogCategories
+//	@Failure		400				{object}	types.JSONFailureResponse
+//	@Router			/gitops-catalog/categories [get]
+//	@Param			Authorization	header	string	true	"API key"	default(Bearer <API key>)
+//
+// GetGitopsCatalogCategories returns the gitops catalog categories with the count of apps
+// available to the cloud and git provider
+func GetGitopsCatalogCategories(c *gin.Context) {
+	cloudProvider := c.Query("cloud_provider")
+	if cloudProvider == "" {
+		respondError(c, http.StatusBadRequest, errors.New("cloud_provider not provided"))
+		return
+	}
+
+	gitProvider := c.Query("git_provider")
+	if gitProvider == "" {
+		respondError(c, http.StatusBadRequest, errors.New("git_provider not provided"))
+		return
+	}
+
+	kcfg := utils.GetKubernetesClient("")
+
+	apps, err := secrets.GetGitopsCatalogAppsByCloudProvider(kcfg.Clientset, cloudProvider, gitProvider)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pkgtypes.GitopsCatalogCategories{
+		Categories: gitopsCatalog.Categories(apps.Apps),
+	})
 }
 
 // UpdateGitopsCatalogApps godoc
@@ -82,10 +229,9 @@ func GetGitopsCatalogApps(c *gin.Context) {
 func UpdateGitopsCatalogApps(c *gin.Context) {
 	kcfg := utils.GetKubernetesClient("TODO: Secrets")
 	err := secrets.UpdateGitopsCatalogApps(kcfg.Clientset)
+	metrics.ObserveCatalogRefresh(err)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -93,3 +239,19 @@ func UpdateGitopsCatalogApps(c *gin.Context) {
 		Message: "gitops catalog application directory updated",
 	})
 }
+
+// queryInt reads an integer query parameter of at least minimum, falling back to def
+// when it is absent
+func queryInt(c *gin.Context, key string, def, minimum int) (int, error) {
+	raw, ok := c.GetQuery(key)
+	if !ok || raw == "" {
+		return def, nil
+	}
+
+	v, err := strconv.Atoi(raw)
+	if err != nil || v < minimum {
+		return 0, fmt.Errorf("%s must be an integer of at least %d", key, minimum)
+	}
+
+	return v, nil
+}
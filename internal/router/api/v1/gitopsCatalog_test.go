@@ -0,0 +1,68 @@
+package api
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/konstructio/kubefirst-api/internal/secrets"
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestInstalledServices(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	mgmt := pkgtypes.Cluster{
+		ClusterName:      "mgmt",
+		WorkloadClusters: []pkgtypes.WorkloadCluster{{ClusterName: "dev"}, {ClusterName: "staging"}},
+	}
+	if err := secrets.InsertCluster(clientset, mgmt); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := secrets.CreateClusterServiceList(clientset, "dev"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := secrets.InsertClusterServiceListEntry(clientset, "dev", &pkgtypes.Service{Name: "metaphor"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	t.Run("workload cluster", func(t *testing.T) {
+		cluster, err := catalogCluster(clientset, "dev")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if cluster.ClusterName != "mgmt" {
+			t.Errorf("expected the management cluster record, got %q", cluster.ClusterName)
+		}
+
+		names, err := installedServices(clientset, cluster, "dev")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if !reflect.DeepEqual(names, []string{"metaphor"}) {
+			t.Errorf("expected metaphor to be installed, got %v", names)
+		}
+	})
+
+	t.Run("workload cluster without services", func(t *testing.T) {
+		names, err := installedServices(clientset, &mgmt, "staging")
+		if err != nil || len(names) != 0 {
+			t.Errorf("expected no services, got %v, %v", names, err)
+		}
+	})
+
+	t.Run("management cluster without service list", func(t *testing.T) {
+		_, err := installedServices(clientset, &mgmt, "mgmt")
+		if !apierrors.IsNotFound(err) {
+			t.Errorf("expected a not found error, got %v", err)
+		}
+	})
+
+	t.Run("unknown cluster", func(t *testing.T) {
+		_, err := catalogCluster(clientset, "prod")
+		if !errors.Is(err, &secrets.ClusterNotFoundError{}) {
+			t.Errorf("expected a cluster not found error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,70 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package api
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/konstructio/kubefirst-api/internal/health"
+	"github.com/konstructio/kubefirst-api/internal/secrets"
+	"github.com/konstructio/kubefirst-api/internal/utils"
+)
+
+// GetReadyz godoc
+//
+//	@Summary		Return the readiness of the API and its dependencies
+//	@Description	Check the API is not shutting down, the kubernetes api, the permissions of the API in the kubefirst namespace, the gitops catalog secret, and vault and argo cd of the provisioned clusters. Catalog, vault and argo cd failures degrade the report without failing it, and vault and argo cd are checked at most once a minute.
+//	@Tags			health
+//	@Produce		json
+//	@Success		200	{object}	health.Report
+//	@Failure		503	{object}	health.Report
+//	@Router			/readyz [get]
+//
+// GetReadyz reports whether the API can serve requests
+func GetReadyz(c *gin.Context) {
+	checks := []health.Check{health.ShutdownCheck()}
+
+	kcfg := utils.GetKubernetesClient("")
+	if kcfg == nil {
+		checks = append(checks, health.Check{
+			Name:     "kubernetes",
+			Critical: true,
+			Run: func(context.Context) error {
+				return errors.New("unable to create kubernetes client")
+			},
+		})
+	} else {
+		checks = append(checks, health.KubernetesChecks(kcfg.Clientset)...)
+		checks = append(checks, health.CatalogCheck(kcfg.Clientset))
+
+		// failures to list the clusters are reported by the kubernetes checks
+		if clusters, err := secrets.GetClusters(kcfg.Clientset); err == nil {
+			checks = append(checks, health.ClusterChecks(clusters)...)
+		}
+	}
+
+	report := health.Run(c.Request.Context(), checks)
+	c.JSON(report.HTTPStatus(), report)
+}
+
+// GetLivez godoc
+//
+//	@Summary		Return the liveness of the API
+//	@Description	Check the background workers of the API, such as the gitops catalog updater and the telemetry heartbeat, are still running.
+//	@Tags			health
+//	@Produce		json
+//	@Success		200	{object}	health.Report
+//	@Failure		503	{object}	health.Report
+//	@Router			/livez [get]
+//
+// GetLivez reports whether the API process is working
+func GetLivez(c *gin.Context) {
+	report := health.Run(c.Request.Context(), health.WorkerChecks())
+	c.JSON(report.HTTPStatus(), report)
+}
@@ -2,15 +2,18 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
-	"os"
 
 	"github.com/gin-gonic/gin"
+	"github.com/konstructio/kubefirst-api/internal/apierror"
 	awsinternal "github.com/konstructio/kubefirst-api/internal/aws"
 	"github.com/konstructio/kubefirst-api/internal/azure"
 	"github.com/konstructio/kubefirst-api/internal/civo"
 	"github.com/konstructio/kubefirst-api/internal/digitalocean"
+	"github.com/konstructio/kubefirst-api/internal/httpCommon"
+	"github.com/konstructio/kubefirst-api/internal/middleware"
 	"github.com/konstructio/kubefirst-api/internal/types"
 	"github.com/konstructio/kubefirst-api/internal/vultr"
 	"github.com/konstructio/kubefirst-api/pkg/aws"
@@ -23,18 +26,14 @@ func ListInstanceSizesForRegion(c *gin.Context) {
 	dnsProvider, param := c.Params.Get("cloud_provider")
 
 	if !param {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: ":cloud_provider not provided",
-		})
+		respondError(c, http.StatusBadRequest, errors.New(":cloud_provider not provided"))
 		return
 	}
 
 	var instanceSizesRequest types.InstanceSizesRequest
 	err := c.Bind(&instanceSizesRequest)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -43,9 +42,7 @@ func ListInstanceSizesForRegion(c *gin.Context) {
 	switch dnsProvider {
 	case "civo":
 		if instanceSizesRequest.CivoAuth.Token == "" {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: "missing civo auth token, try again",
-			})
+			respondError(c, http.StatusBadRequest, apierror.InvalidCredentials("civo", errors.New("missing civo auth token, try again")))
 			return
 		}
 
@@ -56,9 +53,7 @@ func ListInstanceSizesForRegion(c *gin.Context) {
 
 		instanceSizes, err := civoConfig.ListInstanceSizes()
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: err.Error(),
-			})
+			respondError(c, http.StatusBadRequest, apierror.Upstream("civo", err))
 			return
 		}
 
@@ -70,14 +65,12 @@ func ListInstanceSizesForRegion(c *gin.Context) {
 		if instanceSizesRequest.AWSAuth.AccessKeyID == "" ||
 			instanceSizesRequest.AWSAuth.SecretAccessKey == "" ||
 			instanceSizesRequest.AWSAuth.SessionToken == "" {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: "missing authentication credentials in request, please check and try again",
-			})
+			respondError(c, http.StatusBadRequest, apierror.InvalidCredentials("aws", errors.New("missing authentication credentials in request, please check and try again")))
 			return
 		}
 
 		var awsConf *awsinternal.Configuration
-		if os.Getenv("IS_CLUSTER_ZERO") == "false" {
+		if env := middleware.GetConfig(c); !env.IsClusterZero {
 			awsConf = &awsinternal.Configuration{
 				Config: aws.NewEKSServiceAccountClientV1(),
 			}
@@ -89,9 +82,7 @@ func ListInstanceSizesForRegion(c *gin.Context) {
 				instanceSizesRequest.AWSAuth.SessionToken,
 			)
 			if err != nil {
-				c.JSON(http.StatusInternalServerError, types.JSONFailureResponse{
-					Message: fmt.Sprintf("error creating aws client: %v", err),
-				})
+				respondError(c, http.StatusInternalServerError, apierror.Upstream("aws", fmt.Errorf("error creating aws client: %w", err)))
 				return
 			}
 
@@ -100,9 +91,7 @@ func ListInstanceSizesForRegion(c *gin.Context) {
 
 		instanceSizes, err := awsConf.ListInstanceSizesForRegion(context.Background(), instanceSizesRequest.AMIType)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: err.Error(),
-			})
+			respondError(c, http.StatusBadRequest, apierror.Upstream("aws", err))
 			return
 		}
 
@@ -112,9 +101,7 @@ func ListInstanceSizesForRegion(c *gin.Context) {
 	case "azure":
 		err = instanceSizesRequest.AzureAuth.ValidateAuthCredentials()
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: err.Error(),
-			})
+			respondError(c, http.StatusBadRequest, apierror.InvalidCredentials("azure", err))
 			return
 		}
 
@@ -125,25 +112,19 @@ func ListInstanceSizesForRegion(c *gin.Context) {
 			instanceSizesRequest.AzureAuth.TenantID,
 		)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.JSONFailureResponse{
-				Message: err.Error(),
-			})
+			respondError(c, http.StatusInternalServerError, apierror.Upstream("azure", err))
 			return
 		}
 
 		instances, err := azureClient.GetInstanceSizes(context.Background(), instanceSizesRequest.CloudRegion)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.JSONFailureResponse{
-				Message: err.Error(),
-			})
+			respondError(c, http.StatusInternalServerError, apierror.Upstream("azure", err))
 			return
 		}
 		instanceSizesResponse.InstanceSizes = instances
 	case "digitalocean":
 		if instanceSizesRequest.DigitaloceanAuth.Token == "" {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: "missing authentication credentials in request, please check and try again",
-			})
+			respondError(c, http.StatusBadRequest, apierror.InvalidCredentials("digitalocean", errors.New("missing authentication credentials in request, please check and try again")))
 			return
 		}
 
@@ -154,9 +135,7 @@ func ListInstanceSizesForRegion(c *gin.Context) {
 
 		instances, err := digitaloceanConf.ListInstances()
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: err.Error(),
-			})
+			respondError(c, http.StatusBadRequest, apierror.Upstream("digitalocean", err))
 			return
 		}
 
@@ -166,9 +145,7 @@ func ListInstanceSizesForRegion(c *gin.Context) {
 
 	case "vultr":
 		if instanceSizesRequest.VultrAuth.Token == "" {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: "missing authentication credentials in request, please check and try again",
-			})
+			respondError(c, http.StatusBadRequest, apierror.InvalidCredentials("vultr", errors.New("missing authentication credentials in request, please check and try again")))
 			return
 		}
 
@@ -179,9 +156,7 @@ func ListInstanceSizesForRegion(c *gin.Context) {
 
 		instances, err := vultrConf.ListInstances()
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: err.Error(),
-			})
+			respondError(c, http.StatusBadRequest, apierror.Upstream("vultr", err))
 			return
 		}
 
@@ -192,17 +167,13 @@ func ListInstanceSizesForRegion(c *gin.Context) {
 	case "google":
 
 		if instanceSizesRequest.CloudZone == "" {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: "missing cloud_zone arg, please check and try again",
-			})
+			respondError(c, http.StatusBadRequest, errors.New("missing cloud_zone arg, please check and try again"))
 			return
 		}
 
 		if instanceSizesRequest.GoogleAuth.ProjectID == "" ||
 			instanceSizesRequest.GoogleAuth.KeyFile == "" {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: "missing authentication credentials in request, please check and try again",
-			})
+			respondError(c, http.StatusBadRequest, apierror.InvalidCredentials("google", errors.New("missing authentication credentials in request, please check and try again")))
 			return
 		}
 
@@ -215,9 +186,7 @@ func ListInstanceSizesForRegion(c *gin.Context) {
 
 		instances, err := googleConf.ListInstances(instanceSizesRequest.CloudZone)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: err.Error(),
-			})
+			respondError(c, http.StatusBadRequest, apierror.Upstream("google", err))
 			return
 		}
 
@@ -229,6 +198,7 @@ func ListInstanceSizesForRegion(c *gin.Context) {
 		oauth2Client := &http.Client{
 			Transport: &oauth2.Transport{
 				Source: tokenSource,
+				Base:   httpCommon.TracedTransport(),
 			},
 		}
 
@@ -236,9 +206,7 @@ func ListInstanceSizesForRegion(c *gin.Context) {
 
 		instances, err := client.ListTypes(context.Background(), &linodego.ListOptions{})
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: err.Error(),
-			})
+			respondError(c, http.StatusBadRequest, apierror.Upstream("akamai", err))
 			return
 		}
 
@@ -250,9 +218,7 @@ func ListInstanceSizesForRegion(c *gin.Context) {
 		instanceSizesResponse.InstanceSizes = linodeInstances
 
 	default:
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: fmt.Sprintf("unsupported dns provider: %s", dnsProvider),
-		})
+		respondError(c, http.StatusBadRequest, fmt.Errorf("unsupported dns provider: %s", dnsProvider))
 		return
 	}
 
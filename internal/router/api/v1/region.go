@@ -8,15 +8,18 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
-	"os"
 
 	"github.com/gin-gonic/gin"
+	"github.com/konstructio/kubefirst-api/internal/apierror"
 	awsinternal "github.com/konstructio/kubefirst-api/internal/aws"
 	"github.com/konstructio/kubefirst-api/internal/azure"
 	"github.com/konstructio/kubefirst-api/internal/civo"
 	"github.com/konstructio/kubefirst-api/internal/digitalocean"
+	"github.com/konstructio/kubefirst-api/internal/httpCommon"
+	"github.com/konstructio/kubefirst-api/internal/middleware"
 	"github.com/konstructio/kubefirst-api/internal/types"
 	"github.com/konstructio/kubefirst-api/internal/vultr"
 	"github.com/konstructio/kubefirst-api/pkg/aws"
@@ -42,9 +45,7 @@ import (
 func PostRegions(c *gin.Context) {
 	cloudProvider, param := c.Params.Get("cloud_provider")
 	if !param {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: ":cloud_provider not provided",
-		})
+		respondError(c, http.StatusBadRequest, errors.New(":cloud_provider not provided"))
 		return
 	}
 
@@ -52,9 +53,7 @@ func PostRegions(c *gin.Context) {
 	var regionListRequest types.RegionListRequest
 	err := c.Bind(&regionListRequest)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -65,13 +64,11 @@ func PostRegions(c *gin.Context) {
 		if regionListRequest.AWSAuth.AccessKeyID == "" ||
 			regionListRequest.AWSAuth.SecretAccessKey == "" ||
 			regionListRequest.AWSAuth.SessionToken == "" {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: "missing authentication credentials in request, please check and try again",
-			})
+			respondError(c, http.StatusBadRequest, apierror.InvalidCredentials("aws", errors.New("missing authentication credentials in request, please check and try again")))
 			return
 		}
 		var awsConf *awsinternal.Configuration
-		if os.Getenv("IS_CLUSTER_ZERO") == "false" {
+		if env := middleware.GetConfig(c); !env.IsClusterZero {
 			awsConf = &awsinternal.Configuration{
 				Config: aws.NewEKSServiceAccountClientV1(),
 			}
@@ -83,9 +80,7 @@ func PostRegions(c *gin.Context) {
 				regionListRequest.AWSAuth.SessionToken,
 			)
 			if err != nil {
-				c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-					Message: fmt.Sprintf("error creating aws client: %s", err),
-				})
+				respondError(c, http.StatusBadRequest, apierror.Upstream("aws", fmt.Errorf("error creating aws client: %w", err)))
 				return
 			}
 
@@ -94,18 +89,14 @@ func PostRegions(c *gin.Context) {
 
 		regions, err := awsConf.GetRegions()
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: err.Error(),
-			})
+			respondError(c, http.StatusBadRequest, apierror.Upstream("aws", err))
 			return
 		}
 		regionListResponse.Regions = regions
 	case "azure":
 		err = regionListRequest.AzureAuth.ValidateAuthCredentials()
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: err.Error(),
-			})
+			respondError(c, http.StatusBadRequest, apierror.InvalidCredentials("azure", err))
 			return
 		}
 
@@ -116,26 +107,20 @@ func PostRegions(c *gin.Context) {
 			regionListRequest.AzureAuth.TenantID,
 		)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.JSONFailureResponse{
-				Message: err.Error(),
-			})
+			respondError(c, http.StatusInternalServerError, apierror.Upstream("azure", err))
 			return
 		}
 
 		regions, err := azureClient.GetRegions(context.Background())
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.JSONFailureResponse{
-				Message: err.Error(),
-			})
+			respondError(c, http.StatusInternalServerError, apierror.Upstream("azure", err))
 			return
 		}
 
 		regionListResponse.Regions = regions
 	case "civo":
 		if regionListRequest.CivoAuth.Token == "" {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: "missing authentication credentials in request, please check and try again",
-			})
+			respondError(c, http.StatusBadRequest, apierror.InvalidCredentials("civo", errors.New("missing authentication credentials in request, please check and try again")))
 			return
 		}
 		civoConf := civo.Configuration{
@@ -145,17 +130,13 @@ func PostRegions(c *gin.Context) {
 
 		regions, err := civoConf.GetRegions()
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: err.Error(),
-			})
+			respondError(c, http.StatusBadRequest, apierror.Upstream("civo", err))
 			return
 		}
 		regionListResponse.Regions = regions
 	case "digitalocean":
 		if regionListRequest.DigitaloceanAuth.Token == "" {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: "missing authentication credentials in request, please check and try again",
-			})
+			respondError(c, http.StatusBadRequest, apierror.InvalidCredentials("digitalocean", errors.New("missing authentication credentials in request, please check and try again")))
 			return
 		}
 		digitaloceanConf := digitalocean.Configuration{
@@ -165,17 +146,13 @@ func PostRegions(c *gin.Context) {
 
 		regions, err := digitaloceanConf.GetRegions()
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: err.Error(),
-			})
+			respondError(c, http.StatusBadRequest, apierror.Upstream("digitalocean", err))
 			return
 		}
 		regionListResponse.Regions = regions
 	case "vultr":
 		if regionListRequest.VultrAuth.Token == "" {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: "missing authentication credentials in request, please check and try again",
-			})
+			respondError(c, http.StatusBadRequest, apierror.InvalidCredentials("vultr", errors.New("missing authentication credentials in request, please check and try again")))
 			return
 		}
 		vultrConf := vultr.Configuration{
@@ -185,17 +162,13 @@ func PostRegions(c *gin.Context) {
 
 		regions, err := vultrConf.GetRegions()
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: err.Error(),
-			})
+			respondError(c, http.StatusBadRequest, apierror.Upstream("vultr", err))
 			return
 		}
 		regionListResponse.Regions = regions
 	case "google":
 		if regionListRequest.GoogleAuth.KeyFile == "" {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: "missing authentication credentials in request, please check and try again",
-			})
+			respondError(c, http.StatusBadRequest, apierror.InvalidCredentials("google", errors.New("missing authentication credentials in request, please check and try again")))
 			return
 		}
 		googleConf := google.Configuration{
@@ -207,9 +180,7 @@ func PostRegions(c *gin.Context) {
 
 		regions, err := googleConf.GetRegions()
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: err.Error(),
-			})
+			respondError(c, http.StatusBadRequest, apierror.Upstream("google", err))
 			return
 		}
 		regionListResponse.Regions = regions
@@ -223,6 +194,7 @@ func PostRegions(c *gin.Context) {
 		oauth2Client := &http.Client{
 			Transport: &oauth2.Transport{
 				Source: tokenSource,
+				Base:   httpCommon.TracedTransport(),
 			},
 		}
 
@@ -230,9 +202,7 @@ func PostRegions(c *gin.Context) {
 
 		regions, err := client.ListRegions(context.Background(), &linodego.ListOptions{})
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: err.Error(),
-			})
+			respondError(c, http.StatusBadRequest, apierror.Upstream("akamai", err))
 			return
 		}
 
@@ -244,9 +214,7 @@ func PostRegions(c *gin.Context) {
 		regionListResponse.Regions = linodeRegions
 
 	default:
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: fmt.Sprintf("unsupported provider: %s", cloudProvider),
-		})
+		respondError(c, http.StatusBadRequest, fmt.Errorf("unsupported provider: %s", cloudProvider))
 		return
 	}
 
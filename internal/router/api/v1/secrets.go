@@ -8,6 +8,7 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -22,17 +23,13 @@ import (
 func GetClusterSecret(c *gin.Context) {
 	clusterName, param := c.Params.Get("cluster_name")
 	if !param {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: ":cluster_name not provided",
-		})
+		respondError(c, http.StatusBadRequest, errors.New(":cluster_name not provided"))
 		return
 	}
 
 	secret, param := c.Params.Get("secret")
 	if !param {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: ":secret not provided",
-		})
+		respondError(c, http.StatusBadRequest, errors.New(":secret not provided"))
 		return
 	}
 
@@ -41,9 +38,7 @@ func GetClusterSecret(c *gin.Context) {
 
 	jsonString, err := secrets.MapToStructuredJSON(kubefirstSecrets)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -53,35 +48,27 @@ func GetClusterSecret(c *gin.Context) {
 func CreateClusterSecret(c *gin.Context) {
 	clusterName, param := c.Params.Get("cluster_name")
 	if !param {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: ":cluster_name not provided",
-		})
+		respondError(c, http.StatusBadRequest, errors.New(":cluster_name not provided"))
 		return
 	}
 
 	secretName, param := c.Params.Get("secret")
 	if !param {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: ":secret not provided",
-		})
+		respondError(c, http.StatusBadRequest, errors.New(":secret not provided"))
 		return
 	}
 
 	var secretValues map[string]interface{}
 	err := c.Bind(&secretValues)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
 	kcfg := utils.GetKubernetesClient(clusterName)
 	bytes, err := json.Marshal(secretValues)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: "error stringifying object",
-		})
+		respondError(c, http.StatusBadRequest, errors.New("error stringifying object"))
 		return
 	}
 
@@ -97,9 +84,7 @@ func CreateClusterSecret(c *gin.Context) {
 
 	err = k8s.CreateSecretV2(kcfg.Clientset, secretToCreate)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -111,26 +96,20 @@ func CreateClusterSecret(c *gin.Context) {
 func UpdateClusterSecret(c *gin.Context) {
 	clusterName, param := c.Params.Get("cluster_name")
 	if !param {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: ":cluster_name not provided",
-		})
+		respondError(c, http.StatusBadRequest, errors.New(":cluster_name not provided"))
 		return
 	}
 
 	secret, param := c.Params.Get("secret")
 	if !param {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: ":secret not provided",
-		})
+		respondError(c, http.StatusBadRequest, errors.New(":secret not provided"))
 		return
 	}
 
 	var secretValues map[string]interface{}
 	err := c.Bind(&secretValues)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -138,18 +117,14 @@ func UpdateClusterSecret(c *gin.Context) {
 
 	bytes, err := json.Marshal(secretValues)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: "error stringifying object",
-		})
+		respondError(c, http.StatusBadRequest, errors.New("error stringifying object"))
 		return
 	}
 
 	secretValuesMap, _ := secrets.ParseJSONToMap(string(bytes))
 	err = k8s.UpdateSecretV2(kcfg.Clientset, "kubefirst", secret, secretValuesMap)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -7,12 +7,16 @@ See the LICENSE file for more details.
 package api
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/konstructio/kubefirst-api/internal/middleware"
 	"github.com/konstructio/kubefirst-api/internal/secrets"
 	"github.com/konstructio/kubefirst-api/internal/services"
+	"github.com/konstructio/kubefirst-api/internal/shutdown"
 	"github.com/konstructio/kubefirst-api/internal/types"
 	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
 
@@ -36,9 +40,7 @@ import (
 func GetServices(c *gin.Context) {
 	clusterName, param := c.Params.Get("cluster_name")
 	if !param {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: ":cluster_name not provided",
-		})
+		respondError(c, http.StatusBadRequest, errors.New(":cluster_name not provided"))
 		return
 	}
 
@@ -47,9 +49,7 @@ func GetServices(c *gin.Context) {
 	// Retrieve all services info
 	allServices, err := secrets.GetServices(kcfg.Clientset, clusterName)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -75,17 +75,13 @@ func GetServices(c *gin.Context) {
 func PostAddServiceToCluster(c *gin.Context) {
 	clusterName, param := c.Params.Get("cluster_name")
 	if !param {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: ":cluster_name not provided",
-		})
+		respondError(c, http.StatusBadRequest, errors.New(":cluster_name not provided"))
 		return
 	}
 
 	serviceName, param := c.Params.Get("service_name")
 	if !param {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: ":service_name not provided",
-		})
+		respondError(c, http.StatusBadRequest, errors.New(":service_name not provided"))
 		return
 	}
 
@@ -94,18 +90,14 @@ func PostAddServiceToCluster(c *gin.Context) {
 	// Verify cluster exists
 	_, err := secrets.GetCluster(kcfg.Clientset, clusterName)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: "cluster not found",
-		})
+		respondError(c, http.StatusInternalServerError, err)
 		return
 	}
 
 	// Verify service is a valid option and determine if it requires secrets
 	apps, err := secrets.GetGitopsCatalogApps(kcfg.Clientset)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 	valid, hasKeys := false, false
@@ -120,9 +112,7 @@ func PostAddServiceToCluster(c *gin.Context) {
 		}
 	}
 	if !valid {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: fmt.Sprintf("service %s is not valid", serviceName),
-		})
+		respondError(c, http.StatusBadRequest, fmt.Errorf("service %s is not valid", serviceName))
 		return
 	}
 
@@ -130,66 +120,355 @@ func PostAddServiceToCluster(c *gin.Context) {
 	var serviceDefinition pkgtypes.GitopsCatalogAppCreateRequest
 	err = c.Bind(&serviceDefinition)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
 	// Verify any required secrets are present and not empty
 	if hasKeys {
-		if serviceDefinition.SecretKeys == nil {
-			c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-				Message: fmt.Sprintf("service %s has required secret keys that cannot be empty, check your request and try again", serviceName),
-			})
+		if err := validateServiceSecretKeys(serviceName, &appDef, serviceDefinition.SecretKeys); err != nil {
+			respondError(c, http.StatusBadRequest, err)
 			return
 		}
+	}
+
+	// Generate and apply
+	cl, err := secrets.GetCluster(kcfg.Clientset, clusterName)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	err = services.CreateService(middleware.GetConfig(c), cl, serviceName, &appDef, &serviceDefinition, false)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.JSONSuccessResponse{
+		Message: fmt.Sprintf("service %s has been created", serviceName),
+	})
+}
+
+// PostBulkAddServicesToCluster godoc
+//
+//	@Summary		Add several gitops catalog applications to a cluster in dependency order
+//	@Description	Start installing a set of gitops catalog applications, one commit per dependency wave. The progress is polled with GET /services/:cluster_name/bulk
+//	@Tags			services
+//	@Accept			json
+//	@Produce		json
+//	@Param			cluster_name	path		string										true	"Cluster name"
+//	@Param			definition		body		types.GitopsCatalogAppBulkCreateRequest		true	"Bulk service create request in JSON format"
+//	@Success		202				{object}	types.GitopsCatalogAppBulkCreateResponse
+//	@Failure		400				{object}	types.JSONFailureResponse
+//	@Failure		409				{object}	types.JSONFailureResponse
+//	@Router			/services/:cluster_name/bulk [post]
+//	@Param			Authorization	header	string	true	"API key"	default(Bearer <API key>)
+//
+// PostBulkAddServicesToCluster handles a request to add several gitops catalog apps to a cluster
+func PostBulkAddServicesToCluster(c *gin.Context) {
+	clusterName, param := c.Params.Get("cluster_name")
+	if !param {
+		respondError(c, http.StatusBadRequest, errors.New(":cluster_name not provided"))
+		return
+	}
+
+	kcfg := utils.GetKubernetesClient(clusterName)
 
-		var providedKeys []string
-		for _, key := range serviceDefinition.SecretKeys {
-			providedKeys = append(providedKeys, key.Name)
+	// Verify cluster exists
+	cl, err := secrets.GetCluster(kcfg.Clientset, clusterName)
+	if err != nil {
+		if errors.Is(err, &secrets.ClusterNotFoundError{}) {
+			respondError(c, http.StatusNotFound, err)
+			return
 		}
 
-		for _, key := range appDef.SecretKeys {
-			found := utils.FindStringInSlice(providedKeys, key.Name)
-			if !found {
-				c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-					Message: fmt.Sprintf("%s is a required secret key", key.Name),
-				})
-				return
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Bind to variable as application/json, handle error
+	var bulkDefinition pkgtypes.GitopsCatalogAppBulkCreateRequest
+	err = c.Bind(&bulkDefinition)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	requested := map[string]bool{}
+	for _, entry := range bulkDefinition.Apps {
+		if requested[entry.Name] {
+			respondError(c, http.StatusBadRequest, fmt.Errorf("service %s is requested more than once", entry.Name))
+			return
+		}
+		requested[entry.Name] = true
+	}
+
+	catalogApps, err := secrets.GetGitopsCatalogApps(kcfg.Clientset)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	// Verify every requested service is a valid option with its required secrets
+	appDefs := []pkgtypes.GitopsCatalogApp{}
+	for _, entry := range bulkDefinition.Apps {
+		valid := false
+		for _, app := range catalogApps.Apps {
+			if app.Name != entry.Name {
+				continue
 			}
-			for _, subkey := range serviceDefinition.SecretKeys {
-				if key.Name == subkey.Name {
-					if subkey.Value == "" {
-						c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-							Message: fmt.Sprintf("%s is a required secret key and its value cannot be empty", subkey.Name),
-						})
-						return
-					}
+			valid = true
+			if app.SecretKeys != nil {
+				if err := validateServiceSecretKeys(entry.Name, &app, entry.SecretKeys); err != nil {
+					respondError(c, http.StatusBadRequest, err)
+					return
 				}
 			}
+			appDefs = append(appDefs, app)
+		}
+		if !valid {
+			respondError(c, http.StatusBadRequest, fmt.Errorf("service %s is not valid", entry.Name))
+			return
 		}
 	}
 
-	// Generate and apply
+	if err := services.ValidateServices(cl, appDefs, &bulkDefinition); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := services.StartBulkInstall(clusterName); err != nil {
+		respondError(c, http.StatusConflict, err)
+		return
+	}
+
+	cfg := middleware.GetConfig(c)
+	runOperation(c, shutdown.OperationServiceInstall, clusterName, func(ctx context.Context) error {
+		_, err := services.CreateServices(ctx, cfg, cl, appDefs, &bulkDefinition, false)
+		return err
+	})
+
+	progress, _ := services.GetBulkInstall(clusterName)
+	c.JSON(http.StatusAccepted, progress)
+}
+
+// GetBulkAddServices godoc
+//
+//	@Summary		Return the progress of the last bulk install of a cluster
+//	@Description	Return the status of the last bulk install of a cluster with the results of the waves installed so far
+//	@Tags			services
+//	@Accept			json
+//	@Produce		json
+//	@Param			cluster_name	path		string	true	"Cluster name"
+//	@Success		200				{object}	types.GitopsCatalogAppBulkCreateResponse
+//	@Failure		404				{object}	types.JSONFailureResponse
+//	@Router			/services/:cluster_name/bulk [get]
+//	@Param			Authorization	header	string	true	"API key"	default(Bearer <API key>)
+//
+// GetBulkAddServices returns the progress of the last bulk install of a cluster
+func GetBulkAddServices(c *gin.Context) {
+	clusterName, param := c.Params.Get("cluster_name")
+	if !param {
+		respondError(c, http.StatusBadRequest, errors.New(":cluster_name not provided"))
+		return
+	}
+
+	progress, ok := services.GetBulkInstall(clusterName)
+	if !ok {
+		respondError(c, http.StatusNotFound, fmt.Errorf("no bulk install was started on cluster %s", clusterName))
+		return
+	}
+
+	c.JSON(http.StatusOK, progress)
+}
+
+// PostAddServiceToTargets godoc
+//
+//	@Summary		Add a gitops catalog application to several clusters at once
+//	@Description	Install a gitops catalog application on a list of clusters with per-target config overrides in a single commit
+//	@Tags			services
+//	@Accept			json
+//	@Produce		json
+//	@Param			cluster_name	path		string											true	"Management cluster name"
+//	@Param			service_name	path		string											true	"Service name to be added"
+//	@Param			definition		body		types.GitopsCatalogAppMultiTargetCreateRequest	true	"Multi-target service create request in JSON format"
+//	@Success		200				{object}	types.GitopsCatalogAppMultiTargetCreateResponse
+//	@Failure		400				{object}	types.JSONFailureResponse
+//	@Router			/services/:cluster_name/:service_name/targets [post]
+//	@Param			Authorization	header	string	true	"API key"	default(Bearer <API key>)
+//
+// PostAddServiceToTargets handles a request to add a gitops catalog app to several clusters
+func PostAddServiceToTargets(c *gin.Context) {
+	clusterName, param := c.Params.Get("cluster_name")
+	if !param {
+		respondError(c, http.StatusBadRequest, errors.New(":cluster_name not provided"))
+		return
+	}
+
+	serviceName, param := c.Params.Get("service_name")
+	if !param {
+		respondError(c, http.StatusBadRequest, errors.New(":service_name not provided"))
+		return
+	}
+
+	kcfg := utils.GetKubernetesClient(clusterName)
+
+	// Verify cluster exists
 	cl, err := secrets.GetCluster(kcfg.Clientset, clusterName)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		if errors.Is(err, &secrets.ClusterNotFoundError{}) {
+			respondError(c, http.StatusNotFound, err)
+			return
+		}
+
+		respondError(c, http.StatusInternalServerError, err)
 		return
 	}
 
-	err = services.CreateService(cl, serviceName, &appDef, &serviceDefinition, false)
+	// Verify service is a valid option and determine if it requires secrets
+	apps, err := secrets.GetGitopsCatalogApps(kcfg.Clientset)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	valid := false
+	var appDef pkgtypes.GitopsCatalogApp
+	for _, app := range apps.Apps {
+		if app.Name == serviceName {
+			valid = true
+			appDef = app
+		}
+	}
+	if !valid {
+		respondError(c, http.StatusBadRequest, fmt.Errorf("service %s is not valid", serviceName))
+		return
+	}
+
+	// Bind to variable as application/json, handle error
+	var serviceDefinition pkgtypes.GitopsCatalogAppMultiTargetCreateRequest
+	err = c.Bind(&serviceDefinition)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if appDef.SecretKeys != nil {
+		if err := validateServiceSecretKeys(serviceName, &appDef, serviceDefinition.SecretKeys); err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	defer shutdown.Track(shutdown.OperationServiceInstall, clusterName)()
+
+	results, err := services.CreateServiceForTargets(middleware.GetConfig(c), cl, serviceName, &appDef, &serviceDefinition)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pkgtypes.GitopsCatalogAppMultiTargetCreateResponse{
+		Results: results,
+	})
+}
+
+// PutUpdateService godoc
+//
+//	@Summary		Update the configuration of a service installed on a cluster
+//	@Description	Rewrite the config and secret keys of an installed gitops catalog application
+//	@Tags			services
+//	@Accept			json
+//	@Produce		json
+//	@Param			cluster_name	path		string								true	"Cluster name"
+//	@Param			service_name	path		string								true	"Service name to be updated"
+//	@Param			definition		body		types.GitopsCatalogAppUpdateRequest	true	"Service update request in JSON format"
+//	@Success		200				{object}	types.JSONSuccessResponse
+//	@Failure		400				{object}	types.JSONFailureResponse
+//	@Router			/services/:cluster_name/:service_name [put]
+//	@Param			Authorization	header	string	true	"API key"	default(Bearer <API key>)
+//
+// PutUpdateService handles a request to change the config and secret keys of an installed service
+func PutUpdateService(c *gin.Context) {
+	clusterName, param := c.Params.Get("cluster_name")
+	if !param {
+		respondError(c, http.StatusBadRequest, errors.New(":cluster_name not provided"))
+		return
+	}
+
+	serviceName, param := c.Params.Get("service_name")
+	if !param {
+		respondError(c, http.StatusBadRequest, errors.New(":service_name not provided"))
+		return
+	}
+
+	kcfg := utils.GetKubernetesClient(clusterName)
+
+	// Verify cluster exists
+	cl, err := secrets.GetCluster(kcfg.Clientset, clusterName)
+	if err != nil {
+		if errors.Is(err, &secrets.ClusterNotFoundError{}) {
+			respondError(c, http.StatusNotFound, err)
+			return
+		}
+
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Verify service is a valid option and determine if it requires secrets
+	apps, err := secrets.GetGitopsCatalogApps(kcfg.Clientset)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	valid := false
+	var appDef pkgtypes.GitopsCatalogApp
+	for _, app := range apps.Apps {
+		if app.Name == serviceName {
+			valid = true
+			appDef = app
+		}
+	}
+	if !valid {
+		respondError(c, http.StatusBadRequest, fmt.Errorf("service %s is not valid", serviceName))
+		return
+	}
+
+	// Bind to variable as application/json, handle error
+	var serviceDefinition pkgtypes.GitopsCatalogAppUpdateRequest
+	err = c.Bind(&serviceDefinition)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := validateServiceConfigKeys(serviceName, &appDef, serviceDefinition.ConfigKeys); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	// Secrets are optional on update, but if provided they must be declared by
+	// the app and complete
+	if err := validateDeclaredSecretKeys(serviceName, &appDef, serviceDefinition.SecretKeys); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	if appDef.SecretKeys != nil && serviceDefinition.SecretKeys != nil {
+		if err := validateServiceSecretKeys(serviceName, &appDef, serviceDefinition.SecretKeys); err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	err = services.UpdateService(middleware.GetConfig(c), cl, serviceName, &appDef, &serviceDefinition)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, types.JSONSuccessResponse{
-		Message: fmt.Sprintf("service %s has been created", serviceName),
+		Message: fmt.Sprintf("service %s has been updated", serviceName),
 	})
 }
 
@@ -212,17 +491,13 @@ func PostAddServiceToCluster(c *gin.Context) {
 func PostValidateService(c *gin.Context) {
 	clusterName, param := c.Params.Get("cluster_name")
 	if !param {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: ":cluster_name not provided",
-		})
+		respondError(c, http.StatusBadRequest, errors.New(":cluster_name not provided"))
 		return
 	}
 
 	serviceName, param := c.Params.Get("service_name")
 	if !param {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: ":service_name not provided",
-		})
+		respondError(c, http.StatusBadRequest, errors.New(":service_name not provided"))
 		return
 	}
 
@@ -231,18 +506,14 @@ func PostValidateService(c *gin.Context) {
 	// Verify cluster exists
 	_, err := secrets.GetCluster(kcfg.Clientset, clusterName)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: "cluster not found",
-		})
+		respondError(c, http.StatusInternalServerError, err)
 		return
 	}
 
 	// Verify service is a valid option and determine if it requires secrets
 	apps, err := secrets.GetGitopsCatalogApps(kcfg.Clientset)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 	valid := false
@@ -253,9 +524,7 @@ func PostValidateService(c *gin.Context) {
 	}
 
 	if !valid {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: fmt.Sprintf("service %s is not valid", serviceName),
-		})
+		respondError(c, http.StatusBadRequest, fmt.Errorf("service %s is not valid", serviceName))
 		return
 	}
 
@@ -263,26 +532,20 @@ func PostValidateService(c *gin.Context) {
 	var serviceDefinition pkgtypes.GitopsCatalogAppCreateRequest
 	err = c.Bind(&serviceDefinition)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
 	// Generate and apply
 	cl, err := secrets.GetCluster(kcfg.Clientset, clusterName)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
 	canDeleteService, err := services.ValidateService(cl, serviceName, &serviceDefinition)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -309,17 +572,13 @@ func PostValidateService(c *gin.Context) {
 func DeleteServiceFromCluster(c *gin.Context) {
 	clusterName, param := c.Params.Get("cluster_name")
 	if !param {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: ":cluster_name not provided",
-		})
+		respondError(c, http.StatusBadRequest, errors.New(":cluster_name not provided"))
 		return
 	}
 
 	serviceName, param := c.Params.Get("service_name")
 	if !param {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: ":service_name not provided",
-		})
+		respondError(c, http.StatusBadRequest, errors.New(":service_name not provided"))
 		return
 	}
 
@@ -328,18 +587,19 @@ func DeleteServiceFromCluster(c *gin.Context) {
 	// Verify cluster exists
 	cl, err := secrets.GetCluster(kcfg.Clientset, clusterName)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: "cluster not found",
-		})
+		if errors.Is(err, &secrets.ClusterNotFoundError{}) {
+			respondError(c, http.StatusNotFound, err)
+			return
+		}
+
+		respondError(c, http.StatusInternalServerError, err)
 		return
 	}
 
 	// Verify service is a valid option and determine if it requires secrets
 	apps, err := secrets.GetGitopsCatalogApps(kcfg.Clientset)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 	valid := false
@@ -349,9 +609,7 @@ func DeleteServiceFromCluster(c *gin.Context) {
 		}
 	}
 	if !valid {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: fmt.Sprintf("service %s is not valid", serviceName),
-		})
+		respondError(c, http.StatusBadRequest, fmt.Errorf("service %s is not valid", serviceName))
 		return
 	}
 
@@ -359,17 +617,13 @@ func DeleteServiceFromCluster(c *gin.Context) {
 	var serviceDefinition pkgtypes.GitopsCatalogAppDeleteRequest
 	err = c.Bind(&serviceDefinition)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
 	err = services.DeleteService(cl, serviceName, serviceDefinition)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -377,3 +631,66 @@ func DeleteServiceFromCluster(c *gin.Context) {
 		Message: fmt.Sprintf("service %s has been deleted", serviceName),
 	})
 }
+
+// validateServiceSecretKeys verifies all secret keys required by a gitops catalog app
+// are provided and not empty
+func validateServiceSecretKeys(serviceName string, appDef *pkgtypes.GitopsCatalogApp, secretKeys []pkgtypes.GitopsCatalogAppKeys) error {
+	if secretKeys == nil {
+		return fmt.Errorf("service %s has required secret keys that cannot be empty, check your request and try again", serviceName)
+	}
+
+	var providedKeys []string
+	for _, key := range secretKeys {
+		providedKeys = append(providedKeys, key.Name)
+	}
+
+	for _, key := range appDef.SecretKeys {
+		if !utils.FindStringInSlice(providedKeys, key.Name) {
+			return fmt.Errorf("%s is a required secret key", key.Name)
+		}
+		for _, subkey := range secretKeys {
+			if key.Name == subkey.Name && subkey.Value == "" {
+				return fmt.Errorf("%s is a required secret key and its value cannot be empty", subkey.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateDeclaredSecretKeys verifies the secret keys of an update are declared
+// by the gitops catalog app
+func validateDeclaredSecretKeys(serviceName string, appDef *pkgtypes.GitopsCatalogApp, secretKeys []pkgtypes.GitopsCatalogAppKeys) error {
+	var knownKeys []string
+	for _, key := range appDef.SecretKeys {
+		knownKeys = append(knownKeys, key.Name)
+	}
+
+	for _, key := range secretKeys {
+		if !utils.FindStringInSlice(knownKeys, key.Name) {
+			return fmt.Errorf("%s is not a secret key of service %s", key.Name, serviceName)
+		}
+	}
+
+	return nil
+}
+
+// validateServiceConfigKeys verifies the config keys of an update belong to the
+// gitops catalog app and are not empty
+func validateServiceConfigKeys(serviceName string, appDef *pkgtypes.GitopsCatalogApp, configKeys []pkgtypes.GitopsCatalogAppKeys) error {
+	var knownKeys []string
+	for _, key := range appDef.ConfigKeys {
+		knownKeys = append(knownKeys, key.Name)
+	}
+
+	for _, key := range configKeys {
+		if !utils.FindStringInSlice(knownKeys, key.Name) {
+			return fmt.Errorf("%s is not a config key of service %s", key.Name, serviceName)
+		}
+		if key.Value == "" {
+			return fmt.Errorf("%s is a config key and its value cannot be empty", key.Name)
+		}
+	}
+
+	return nil
+}
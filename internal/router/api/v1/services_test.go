@@ -0,0 +1,78 @@
+package api
+
+import (
+	"testing"
+
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
+)
+
+var testAppDef = &pkgtypes.GitopsCatalogApp{
+	Name:       "metaphor",
+	ConfigKeys: []pkgtypes.GitopsCatalogAppKeys{{Name: "REPLICAS"}},
+	SecretKeys: []pkgtypes.GitopsCatalogAppKeys{{Name: "API_KEY"}, {Name: "DB_PASSWORD"}},
+}
+
+func TestValidateServiceSecretKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		keys    []pkgtypes.GitopsCatalogAppKeys
+		wantErr bool
+	}{
+		{name: "complete", keys: []pkgtypes.GitopsCatalogAppKeys{{Name: "API_KEY", Value: "a"}, {Name: "DB_PASSWORD", Value: "b"}}},
+		{name: "no keys", wantErr: true},
+		{name: "missing key", keys: []pkgtypes.GitopsCatalogAppKeys{{Name: "API_KEY", Value: "a"}}, wantErr: true},
+		{name: "empty value", keys: []pkgtypes.GitopsCatalogAppKeys{{Name: "API_KEY", Value: "a"}, {Name: "DB_PASSWORD"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateServiceSecretKeys("metaphor", testAppDef, tt.keys); (err != nil) != tt.wantErr {
+				t.Errorf("validateServiceSecretKeys() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateDeclaredSecretKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		appDef  *pkgtypes.GitopsCatalogApp
+		keys    []pkgtypes.GitopsCatalogAppKeys
+		wantErr bool
+	}{
+		{name: "no keys", appDef: testAppDef},
+		{name: "declared key", appDef: testAppDef, keys: []pkgtypes.GitopsCatalogAppKeys{{Name: "API_KEY", Value: "a"}}},
+		{name: "undeclared key", appDef: testAppDef, keys: []pkgtypes.GitopsCatalogAppKeys{{Name: "TOKEN", Value: "a"}}, wantErr: true},
+		{name: "app without secrets", appDef: &pkgtypes.GitopsCatalogApp{Name: "metaphor"}, keys: []pkgtypes.GitopsCatalogAppKeys{{Name: "API_KEY", Value: "a"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateDeclaredSecretKeys("metaphor", tt.appDef, tt.keys); (err != nil) != tt.wantErr {
+				t.Errorf("validateDeclaredSecretKeys() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateServiceConfigKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		keys    []pkgtypes.GitopsCatalogAppKeys
+		wantErr bool
+	}{
+		{name: "no keys"},
+		{name: "known key", keys: []pkgtypes.GitopsCatalogAppKeys{{Name: "REPLICAS", Value: "3"}}},
+		{name: "unknown key", keys: []pkgtypes.GitopsCatalogAppKeys{{Name: "COLOR", Value: "red"}}, wantErr: true},
+		{name: "secret key", keys: []pkgtypes.GitopsCatalogAppKeys{{Name: "API_KEY", Value: "a"}}, wantErr: true},
+		{name: "empty value", keys: []pkgtypes.GitopsCatalogAppKeys{{Name: "REPLICAS"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateServiceConfigKeys("metaphor", testAppDef, tt.keys); (err != nil) != tt.wantErr {
+				t.Errorf("validateServiceConfigKeys() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
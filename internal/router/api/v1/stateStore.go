@@ -0,0 +1,124 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/konstructio/kubefirst-api/internal/apierror"
+	"github.com/konstructio/kubefirst-api/internal/constants"
+	"github.com/konstructio/kubefirst-api/internal/objectStorage"
+	"github.com/konstructio/kubefirst-api/internal/secrets"
+	"github.com/konstructio/kubefirst-api/internal/shutdown"
+	"github.com/konstructio/kubefirst-api/internal/stateStore"
+	"github.com/konstructio/kubefirst-api/internal/utils"
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
+)
+
+// PostMigrateClusterStateStore godoc
+//
+//	@Summary		Migrate the state store of a cluster to another bucket
+//	@Description	Copy the terraform state and artifacts of a cluster to another s3 compatible, gcs or azure blob bucket, verify the copies by checksum, point the terraform backends of the gitops repository and the atlantis credentials at it. The cluster is marked in progress while migrating, its state store details are only updated once every step succeeded and the source bucket is left untouched.
+//	@Tags			cluster
+//	@Accept			json
+//	@Produce		json
+//	@Param			cluster_name	path		string								true	"Cluster name"
+//	@Param			definition		body		types.StateStoreMigrationRequest	true	"Destination bucket"
+//	@Success		200				{object}	types.StateStoreMigrationResult
+//	@Failure		400				{object}	types.JSONFailureResponse
+//	@Failure		404				{object}	types.JSONFailureResponse
+//	@Failure		409				{object}	types.JSONFailureResponse
+//	@Failure		500				{object}	types.JSONFailureResponse
+//	@Router			/cluster/:cluster_name/state-store/migrate [post]
+//	@Param			Authorization	header	string	true	"API key"	default(Bearer <API key>)
+//
+// PostMigrateClusterStateStore migrates the state store of a cluster to another bucket
+func PostMigrateClusterStateStore(c *gin.Context) {
+	clusterName, param := c.Params.Get("cluster_name")
+	if !param {
+		respondError(c, http.StatusBadRequest, errors.New(":cluster_name not provided"))
+		return
+	}
+
+	var req pkgtypes.StateStoreMigrationRequest
+	if err := c.Bind(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	kcfg := utils.GetKubernetesClient(clusterName)
+
+	cluster, ok := getCredentialsCluster(c, kcfg.Clientset, clusterName)
+	if !ok {
+		return
+	}
+
+	// the record is not updated atomically, the lock keeps two migrations of
+	// the same cluster from both passing the checks below
+	if _, running := stateStoreMigrations.LoadOrStore(clusterName, struct{}{}); running {
+		respondError(c, http.StatusConflict, apierror.OperationInProgress(clusterName, "the state store of %s is already being migrated", clusterName))
+		return
+	}
+	defer stateStoreMigrations.Delete(clusterName)
+
+	if cluster.InProgress || cluster.Status != constants.ClusterStatusProvisioned {
+		respondError(c, http.StatusConflict, apierror.New(apierror.CodeConflict, "the state store of %s can only be migrated once the cluster is provisioned and idle", clusterName))
+		return
+	}
+
+	if err := stateStore.Validate(cluster, &req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	defer shutdown.Track(shutdown.OperationStateStoreMigration, clusterName)()
+
+	// other operations and the tls backups leave the state store alone while
+	// it is copied
+	cluster.InProgress = true
+	if err := secrets.UpdateCluster(kcfg.Clientset, *cluster); err != nil {
+		respondError(c, http.StatusInternalServerError, fmt.Errorf("unable to mark cluster %s in progress: %w", clusterName, err))
+		return
+	}
+
+	res, migrateErr := stateStore.Migrate(cluster, &req, kcfg, "https://vault."+clusterDomainName(cluster))
+
+	// re-read the record right before updating to avoid overwriting concurrent changes
+	cluster, err := secrets.GetCluster(kcfg.Clientset, clusterName)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, fmt.Errorf("state store migration ended but the cluster record could not be read: %w", err))
+		return
+	}
+	cluster.InProgress = false
+	if migrateErr == nil {
+		cluster.StateStoreDetails = res.StateStoreDetails
+		cluster.StateStoreCredentials = req.Credentials
+		// azure credentials are named after the storage account
+		if res.StateStoreDetails.Backend != objectStorage.BackendAzure {
+			cluster.StateStoreCredentials.Name = req.BucketName
+		}
+	}
+	if err := secrets.UpdateCluster(kcfg.Clientset, *cluster); err != nil {
+		respondError(c, http.StatusInternalServerError, fmt.Errorf("state store migration ended but the cluster record could not be updated: %w", err))
+		return
+	}
+
+	if migrateErr != nil {
+		respondError(c, http.StatusInternalServerError, migrateErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// stateStoreMigrations holds the names of the clusters whose state store is
+// being migrated
+var stateStoreMigrations sync.Map
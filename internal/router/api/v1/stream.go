@@ -7,6 +7,7 @@ See the LICENSE file for more details.
 package api
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -14,7 +15,6 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/konstructio/kubefirst-api/internal/types"
 	"github.com/nxadm/tail"
 )
 
@@ -32,7 +32,7 @@ func setHeaders(c *gin.Context) {
 //	@Summary		Stream API server logs
 //	@Description	Stream API server logs
 //	@Tags			logs
-//	@Router			/stream/file_name [get]
+//	@Router			/stream/:file_name [get]
 //	@Param			Authorization	header	string	true	"API key"	default(Bearer <API key>)
 //
 // GetLogs
@@ -42,9 +42,7 @@ func GetLogs(c *gin.Context) {
 	fileName, param := c.Params.Get("file_name")
 
 	if !param {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: ":file_name not provided",
-		})
+		respondError(c, http.StatusBadRequest, errors.New(":file_name not provided"))
 		return
 	}
 
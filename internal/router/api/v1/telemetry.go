@@ -7,12 +7,13 @@ See the LICENSE file for more details.
 package api
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/konstructio/kubefirst-api/internal/constants"
-	"github.com/konstructio/kubefirst-api/internal/env"
+	"github.com/konstructio/kubefirst-api/internal/middleware"
 	"github.com/konstructio/kubefirst-api/internal/secrets"
+	apitelemetry "github.com/konstructio/kubefirst-api/internal/telemetry"
 	"github.com/konstructio/kubefirst-api/internal/types"
 	"github.com/konstructio/kubefirst-api/internal/utils"
 	"github.com/kubefirst/metrics-client/pkg/telemetry"
@@ -34,9 +35,7 @@ import (
 func PostTelemetry(c *gin.Context) {
 	clusterName, param := c.Params.Get("cluster_name")
 	if !param {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: ":cluster_name not provided",
-		})
+		respondError(c, http.StatusBadRequest, errors.New(":cluster_name not provided"))
 		return
 	}
 	kcfg := utils.GetKubernetesClient(clusterName)
@@ -44,13 +43,11 @@ func PostTelemetry(c *gin.Context) {
 	// Retrieve cluster info
 	cl, err := secrets.GetCluster(kcfg.Clientset, clusterName)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: "cluster not found",
-		})
+		respondError(c, http.StatusInternalServerError, err)
 		return
 	}
 
-	env, _ := env.GetEnv(constants.SilenceGetEnv)
+	env := middleware.GetConfig(c)
 
 	telEvent := telemetry.TelemetryEvent{
 		CliVersion:        env.KubefirstVersion,
@@ -72,13 +69,11 @@ func PostTelemetry(c *gin.Context) {
 	var req types.TelemetryRequest
 	err = c.Bind(&req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
-	telemetry.SendEvent(telEvent, req.Event, "")
+	apitelemetry.SendEvent(cl.TelemetryEnabled(), telEvent, req.Event, "")
 
 	c.JSON(http.StatusOK, true)
 }
@@ -0,0 +1,283 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	vaultapi "github.com/hashicorp/vault/api"
+	"github.com/konstructio/kubefirst-api/internal/secrets"
+	"github.com/konstructio/kubefirst-api/internal/types"
+	"github.com/konstructio/kubefirst-api/internal/utils"
+	"github.com/konstructio/kubefirst-api/internal/vault"
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes"
+)
+
+// GetClusterVaultSecrets godoc
+//
+//	@Summary		List or read secrets in the vault kv store of a cluster
+//	@Description	List the secrets below a path ending with a slash, or return a secret with its version history. Only the secrets of catalog apps installed on the cluster are accessible.
+//	@Tags			vault
+//	@Accept			json
+//	@Produce		json
+//	@Param			cluster_name	path		string	true	"Cluster name"
+//	@Param			path			path		string	true	"Secret path in the secret kv mount"
+//	@Param			version			query		int		false	"Secret version, defaults to the current version"
+//	@Success		200				{object}	types.VaultSecret
+//	@Success		200				{object}	types.VaultSecretList
+//	@Failure		400				{object}	types.JSONFailureResponse
+//	@Failure		403				{object}	types.JSONFailureResponse
+//	@Failure		404				{object}	types.JSONFailureResponse
+//	@Failure		500				{object}	types.JSONFailureResponse
+//	@Router			/cluster/:cluster_name/vault/secrets/{path} [get]
+//	@Param			Authorization	header	string	true	"API key"	default(Bearer <API key>)
+//
+// GetClusterVaultSecrets lists or reads secrets in the vault kv store of a cluster
+func GetClusterVaultSecrets(c *gin.Context) {
+	vaultURL, token, path, apps, ok := vaultSecretsRequest(c)
+	if !ok {
+		return
+	}
+
+	if path == "" || strings.HasSuffix(path, "/") {
+		list, err := vault.Conf.ListSecrets(vaultURL, token, path)
+		if err != nil {
+			respondError(c, vaultErrorStatus(err), err)
+			return
+		}
+		if path == "" {
+			list.Keys = vault.FilterAppSecrets(list.Keys, apps)
+		}
+		c.JSON(http.StatusOK, list)
+		return
+	}
+
+	version := 0
+	if v := c.Query("version"); v != "" {
+		var err error
+		version, err = strconv.Atoi(v)
+		if err != nil || version < 1 {
+			respondError(c, http.StatusBadRequest, fmt.Errorf("invalid version %q", v))
+			return
+		}
+	}
+
+	secret, err := vault.Conf.GetSecret(vaultURL, token, path, version)
+	if err != nil {
+		respondError(c, vaultErrorStatus(err), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, secret)
+}
+
+// PutClusterVaultSecret godoc
+//
+//	@Summary		Write a secret in the vault kv store of a cluster
+//	@Description	Write a new version of a secret. Only the secrets of catalog apps installed on the cluster are accessible.
+//	@Tags			vault
+//	@Accept			json
+//	@Produce		json
+//	@Param			cluster_name	path		string							true	"Cluster name"
+//	@Param			path			path		string							true	"Secret path in the secret kv mount"
+//	@Param			definition		body		types.VaultSecretWriteRequest	true	"Secret data"
+//	@Success		200				{object}	types.VaultSecret
+//	@Failure		400				{object}	types.JSONFailureResponse
+//	@Failure		403				{object}	types.JSONFailureResponse
+//	@Failure		404				{object}	types.JSONFailureResponse
+//	@Failure		500				{object}	types.JSONFailureResponse
+//	@Router			/cluster/:cluster_name/vault/secrets/{path} [put]
+//	@Param			Authorization	header	string	true	"API key"	default(Bearer <API key>)
+//
+// PutClusterVaultSecret writes a secret in the vault kv store of a cluster
+func PutClusterVaultSecret(c *gin.Context) {
+	var req pkgtypes.VaultSecretWriteRequest
+	if err := c.Bind(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	vaultURL, token, path, _, ok := vaultSecretsRequest(c)
+	if !ok {
+		return
+	}
+
+	if path == "" || strings.HasSuffix(path, "/") {
+		respondError(c, http.StatusBadRequest, errors.New("a secret path is required"))
+		return
+	}
+
+	version, err := vault.Conf.PutSecret(vaultURL, token, path, req.Data, req.CheckAndSet)
+	if err != nil {
+		respondError(c, vaultErrorStatus(err), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pkgtypes.VaultSecret{Path: path, Version: version})
+}
+
+// DeleteClusterVaultSecret godoc
+//
+//	@Summary		Delete a secret in the vault kv store of a cluster
+//	@Description	Delete versions of a secret, the current version by default. Deleted versions can be recovered unless destroy is set; destroying without versions removes the secret with its whole history. Only the secrets of catalog apps installed on the cluster are accessible.
+//	@Tags			vault
+//	@Accept			json
+//	@Produce		json
+//	@Param			cluster_name	path		string	true	"Cluster name"
+//	@Param			path			path		string	true	"Secret path in the secret kv mount"
+//	@Param			versions		query		string	false	"Comma separated versions to delete"
+//	@Param			destroy			query		bool	false	"Permanently destroy the versions"
+//	@Success		200				{object}	types.JSONSuccessResponse
+//	@Failure		400				{object}	types.JSONFailureResponse
+//	@Failure		403				{object}	types.JSONFailureResponse
+//	@Failure		404				{object}	types.JSONFailureResponse
+//	@Failure		500				{object}	types.JSONFailureResponse
+//	@Router			/cluster/:cluster_name/vault/secrets/{path} [delete]
+//	@Param			Authorization	header	string	true	"API key"	default(Bearer <API key>)
+//
+// DeleteClusterVaultSecret deletes a secret in the vault kv store of a cluster
+func DeleteClusterVaultSecret(c *gin.Context) {
+	var versions []int
+	if v := c.Query("versions"); v != "" {
+		for _, s := range strings.Split(v, ",") {
+			version, err := strconv.Atoi(strings.TrimSpace(s))
+			if err != nil || version < 1 {
+				respondError(c, http.StatusBadRequest, fmt.Errorf("invalid version %q", s))
+				return
+			}
+			versions = append(versions, version)
+		}
+	}
+
+	destroy := false
+	if d := c.Query("destroy"); d != "" {
+		var err error
+		destroy, err = strconv.ParseBool(d)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, fmt.Errorf("invalid destroy value %q", d))
+			return
+		}
+	}
+
+	vaultURL, token, path, _, ok := vaultSecretsRequest(c)
+	if !ok {
+		return
+	}
+
+	if path == "" || strings.HasSuffix(path, "/") {
+		respondError(c, http.StatusBadRequest, errors.New("a secret path is required"))
+		return
+	}
+
+	if err := vault.Conf.DeleteSecret(vaultURL, token, path, versions, destroy); err != nil {
+		respondError(c, vaultErrorStatus(err), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.JSONSuccessResponse{
+		Message: fmt.Sprintf("secret %s deleted", path),
+	})
+}
+
+// vaultSecretsRequest resolves the vault address of the requested cluster and
+// logs in with the app secrets token, writing the error response when the
+// request cannot be served. It returns the catalog apps installed on the
+// cluster, paths outside of their secrets are refused here and by the vault
+// policy of the token.
+func vaultSecretsRequest(c *gin.Context) (string, string, string, []string, bool) {
+	clusterName, param := c.Params.Get("cluster_name")
+	if !param {
+		respondError(c, http.StatusBadRequest, errors.New(":cluster_name not provided"))
+		return "", "", "", nil, false
+	}
+
+	path, valid := vault.CleanSecretPath(c.Param("path"))
+	if !valid {
+		respondError(c, http.StatusBadRequest, fmt.Errorf("invalid secret path %q", c.Param("path")))
+		return "", "", "", nil, false
+	}
+
+	kcfg := utils.GetKubernetesClient(clusterName)
+
+	cluster, ok := getCredentialsCluster(c, kcfg.Clientset, clusterName)
+	if !ok {
+		return "", "", "", nil, false
+	}
+
+	apps, err := installedCatalogApps(kcfg.Clientset, cluster)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return "", "", "", nil, false
+	}
+
+	if path != "" && !vault.IsAppSecretPath(path, apps) {
+		respondError(c, http.StatusForbidden, fmt.Errorf("secret path %q does not belong to a catalog app installed on cluster %s, only app secrets can be managed", path, clusterName))
+		return "", "", "", nil, false
+	}
+
+	vaultURL := "https://vault." + clusterDomainName(cluster)
+	token, err := vault.Conf.AppSecretsToken(vaultURL, apps, kcfg.Clientset)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, fmt.Errorf("unable to authenticate to vault: %w", err))
+		return "", "", "", nil, false
+	}
+
+	return vaultURL, token, path, apps, true
+}
+
+// installedCatalogApps returns the names of the catalog apps installed on a
+// cluster and its workload clusters, whose secrets live in the cluster vault
+func installedCatalogApps(clientset kubernetes.Interface, cluster *pkgtypes.Cluster) ([]string, error) {
+	clusterNames := []string{cluster.ClusterName}
+	for _, wc := range cluster.WorkloadClusters {
+		clusterNames = append(clusterNames, wc.ClusterName)
+	}
+
+	apps := []string{}
+	for _, name := range clusterNames {
+		services, err := secrets.GetServices(clientset, name)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("unable to list services of cluster %s: %w", name, err)
+		}
+
+		for _, svc := range services.Services {
+			if !svc.Default && !slices.Contains(apps, svc.Name) {
+				apps = append(apps, svc.Name)
+			}
+		}
+	}
+
+	return apps, nil
+}
+
+// vaultErrorStatus maps a vault error to the status of the response
+func vaultErrorStatus(err error) int {
+	if errors.Is(err, vaultapi.ErrSecretNotFound) {
+		return http.StatusNotFound
+	}
+
+	var respErr *vaultapi.ResponseError
+	if errors.As(err, &respErr) {
+		switch respErr.StatusCode {
+		case http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound:
+			return respErr.StatusCode
+		}
+	}
+
+	return http.StatusInternalServerError
+}
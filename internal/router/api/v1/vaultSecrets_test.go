@@ -0,0 +1,87 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	vaultapi "github.com/hashicorp/vault/api"
+	"github.com/konstructio/kubefirst-api/internal/types"
+)
+
+func vaultSecretsRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/cluster/:cluster_name/vault/secrets/*path", GetClusterVaultSecrets)
+	r.PUT("/cluster/:cluster_name/vault/secrets/*path", PutClusterVaultSecret)
+	r.DELETE("/cluster/:cluster_name/vault/secrets/*path", DeleteClusterVaultSecret)
+	return r
+}
+
+// The requests below are refused before the cluster is looked up
+func TestVaultSecretsRequestValidation(t *testing.T) {
+	r := vaultSecretsRouter()
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "escape the app folder", method: http.MethodGet, target: "/cluster/dev/vault/secrets/metaphor/../ci-secrets", wantStatus: http.StatusBadRequest, wantCode: "invalid_request"},
+		{name: "empty segment", method: http.MethodGet, target: "/cluster/dev/vault/secrets/metaphor//database", wantStatus: http.StatusBadRequest, wantCode: "invalid_request"},
+		{name: "write without data", method: http.MethodPut, target: "/cluster/dev/vault/secrets/metaphor", body: `{}`, wantStatus: http.StatusBadRequest, wantCode: "invalid_request"},
+		{name: "delete an invalid version", method: http.MethodDelete, target: "/cluster/dev/vault/secrets/metaphor?versions=1,x", wantStatus: http.StatusBadRequest, wantCode: "invalid_request"},
+		{name: "delete with an invalid destroy", method: http.MethodDelete, target: "/cluster/dev/vault/secrets/metaphor?destroy=maybe", wantStatus: http.StatusBadRequest, wantCode: "invalid_request"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, but got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+
+			var res types.JSONFailureResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+				t.Fatalf("unable to decode response: %s", err)
+			}
+			if res.Code != tt.wantCode {
+				t.Errorf("expected code %q, but got %q", tt.wantCode, res.Code)
+			}
+		})
+	}
+}
+
+func TestVaultErrorStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "missing secret", err: fmt.Errorf("error getting secret: %w", vaultapi.ErrSecretNotFound), want: http.StatusNotFound},
+		{name: "denied by policy", err: &vaultapi.ResponseError{StatusCode: http.StatusForbidden}, want: http.StatusForbidden},
+		{name: "check and set mismatch", err: &vaultapi.ResponseError{StatusCode: http.StatusBadRequest}, want: http.StatusBadRequest},
+		{name: "sealed vault", err: &vaultapi.ResponseError{StatusCode: http.StatusServiceUnavailable}, want: http.StatusInternalServerError},
+		{name: "other", err: errors.New("connection refused"), want: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := vaultErrorStatus(tt.err); got != tt.want {
+				t.Errorf("vaultErrorStatus() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -14,9 +14,7 @@ func ListZonesForRegion(c *gin.Context) {
 	var zonesListRequest types.ZonesListRequest
 	err := c.Bind(&zonesListRequest)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -31,9 +29,7 @@ func ListZonesForRegion(c *gin.Context) {
 
 	zones, err := googleConf.GetZones()
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.JSONFailureResponse{
-			Message: err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/konstructio/kubefirst-api/internal/env"
+	"github.com/konstructio/kubefirst-api/internal/openapi"
+)
+
+var update = flag.Bool("update", false, "update docs/openapi.json")
+
+// specFile is the OpenAPI document checked in for readers without a running API
+var specFile = filepath.Join("..", "..", "docs", "openapi.json")
+
+// unspecified are the routes served outside of the API operations
+var unspecified = map[string]bool{
+	"GET /metrics":      true,
+	"GET /swagger/*any": true,
+}
+
+func TestRoutesMatchSpec(t *testing.T) {
+	doc, err := Spec()
+	if err != nil {
+		t.Fatalf("unable to build the spec: %v", err)
+	}
+
+	var served []string
+	for _, r := range SetupRouter(env.Static(env.Env{})).Routes() {
+		if key := r.Method + " " + r.Path; !unspecified[key] {
+			served = append(served, r.Method+" "+openapi.Path(r.Path))
+		}
+	}
+
+	var specified []string
+	for path, item := range doc.Paths {
+		for method := range item {
+			specified = append(specified, strings.ToUpper(method)+" "+path)
+		}
+	}
+
+	sort.Strings(served)
+	sort.Strings(specified)
+	if strings.Join(served, "\n") != strings.Join(specified, "\n") {
+		t.Errorf("served routes and spec operations differ\nserved:\n%s\nspecified:\n%s", strings.Join(served, "\n"), strings.Join(specified, "\n"))
+	}
+}
+
+func TestResponsesMatchSpec(t *testing.T) {
+	doc, err := Spec()
+	if err != nil {
+		t.Fatalf("unable to build the spec: %v", err)
+	}
+	r := SetupRouter(env.Static(env.Env{K1AccessToken: "test-token"}))
+
+	tests := []struct {
+		name       string
+		method     string
+		route      string
+		target     string
+		auth       bool
+		body       string
+		wantStatus int
+	}{
+		{name: "health", method: http.MethodGet, route: "/api/v1/health", target: "/api/v1/health", wantStatus: http.StatusOK},
+		{name: "livez", method: http.MethodGet, route: "/livez", target: "/livez", wantStatus: http.StatusOK},
+		{name: "spec", method: http.MethodGet, route: "/openapi.json", target: "/openapi.json", wantStatus: http.StatusOK},
+		{name: "cloud defaults", method: http.MethodGet, route: "/api/v1/cloud-defaults", target: "/api/v1/cloud-defaults", auth: true, wantStatus: http.StatusOK},
+		{name: "missing api key", method: http.MethodGet, route: "/api/v1/cluster", target: "/api/v1/cluster", wantStatus: http.StatusUnauthorized},
+		{name: "malformed body", method: http.MethodPost, route: "/api/v1/environment", target: "/api/v1/environment", auth: true, body: "{", wantStatus: http.StatusBadRequest},
+		{name: "unknown route", method: http.MethodGet, target: "/api/v1/unknown", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.auth {
+				req.Header.Set("Authorization", "Bearer test-token")
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, but got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+
+			schema := doc.Components.Schemas["JSONFailureResponse"]
+			if tt.route != "" {
+				op, ok := doc.Operation(tt.method, tt.route)
+				if !ok {
+					t.Fatalf("no operation %s %s in the spec", tt.method, tt.route)
+				}
+				res, ok := op.Responses[strconv.Itoa(w.Code)]
+				if !ok {
+					res = op.Responses["default"]
+				}
+				schema = res.Content[openapi.ContentTypeJSON].Schema
+			}
+
+			if err := doc.ValidateJSON(schema, w.Body.Bytes()); err != nil {
+				t.Errorf("response does not match the spec: %v\n%s", err, w.Body.String())
+			}
+		})
+	}
+}
+
+// TestSpecFile keeps docs/openapi.json in sync with the route table, run it
+// with -update after changing the routes
+func TestSpecFile(t *testing.T) {
+	doc, err := Spec()
+	if err != nil {
+		t.Fatalf("unable to build the spec: %v", err)
+	}
+	want, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		t.Fatalf("unable to encode the spec: %v", err)
+	}
+	want = append(want, '\n')
+
+	if *update {
+		if err := os.WriteFile(specFile, want, 0o644); err != nil {
+			t.Fatalf("unable to write %s: %v", specFile, err)
+		}
+	}
+
+	got, err := os.ReadFile(specFile)
+	if err != nil {
+		t.Fatalf("unable to read %s: %v", specFile, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s is out of date, run: go test ./internal/router -run TestSpecFile -update", specFile)
+	}
+}
@@ -0,0 +1,360 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/konstructio/kubefirst-api/internal/health"
+	"github.com/konstructio/kubefirst-api/internal/openapi"
+	router "github.com/konstructio/kubefirst-api/internal/router/api/v1"
+	"github.com/konstructio/kubefirst-api/internal/types"
+	"github.com/konstructio/kubefirst-api/pkg/certificates"
+	"github.com/konstructio/kubefirst-api/pkg/credentials"
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
+)
+
+// route is an API operation with its handler, the router and the OpenAPI
+// document are both built from the route table
+type route struct {
+	openapi.Endpoint
+	handler gin.HandlerFunc
+}
+
+// success is the body of the operations answering with a message
+var success = types.JSONSuccessResponse{}
+
+// routes returns the route table of the API
+func routes() []route {
+	return []route{
+		// Probes
+		{openapi.Endpoint{
+			Method: http.MethodGet, Path: "/livez", ID: "getLivez", Tag: "health",
+			Summary:   "Report whether the API process is alive",
+			Responses: map[int]interface{}{http.StatusOK: health.Report{}, http.StatusServiceUnavailable: health.Report{}},
+		}, router.GetLivez},
+		{openapi.Endpoint{
+			Method: http.MethodGet, Path: "/readyz", ID: "getReadyz", Tag: "health",
+			Summary:   "Report whether the API is ready to serve requests",
+			Responses: map[int]interface{}{http.StatusOK: health.Report{}, http.StatusServiceUnavailable: health.Report{}},
+		}, router.GetReadyz},
+
+		// OpenAPI document
+		{openapi.Endpoint{
+			Method: http.MethodGet, Path: "/openapi.json", ID: "getOpenAPISpec", Tag: "docs",
+			Summary:   "Return the OpenAPI 3 document of the API",
+			Responses: map[int]interface{}{http.StatusOK: map[string]interface{}{}},
+		}, getSpec},
+
+		// Cluster
+		{openapi.Endpoint{
+			Method: http.MethodGet, Path: "/api/v1/cluster", ID: "listClusters", Tag: "cluster", Auth: true,
+			Summary:   "Return all known Kubefirst clusters",
+			Responses: map[int]interface{}{http.StatusOK: []pkgtypes.Cluster{}},
+		}, router.GetClusters},
+		{openapi.Endpoint{
+			Method: http.MethodPost, Path: "/api/v1/cluster/import", ID: "importCluster", Tag: "cluster", Auth: true,
+			Summary:   "Import a Kubefirst cluster database entry",
+			Body:      pkgtypes.Cluster{},
+			Responses: map[int]interface{}{http.StatusOK: success},
+		}, router.PostImportCluster},
+		{openapi.Endpoint{
+			Method: http.MethodGet, Path: "/api/v1/cluster/:cluster_name", ID: "getCluster", Tag: "cluster", Auth: true,
+			Summary:   "Return a Kubefirst cluster",
+			Responses: map[int]interface{}{http.StatusOK: pkgtypes.Cluster{}},
+		}, router.GetCluster},
+		{openapi.Endpoint{
+			Method: http.MethodDelete, Path: "/api/v1/cluster/:cluster_name", ID: "deleteCluster", Tag: "cluster", Auth: true,
+			Summary:   "Delete a Kubefirst cluster",
+			Responses: map[int]interface{}{http.StatusAccepted: success},
+		}, router.DeleteCluster},
+		{openapi.Endpoint{
+			Method: http.MethodPost, Path: "/api/v1/cluster/:cluster_name", ID: "createCluster", Tag: "cluster", Auth: true,
+			Summary:   "Create a Kubefirst cluster",
+			Body:      pkgtypes.ClusterDefinition{},
+			Responses: map[int]interface{}{http.StatusAccepted: success},
+		}, router.PostCreateCluster},
+		{openapi.Endpoint{
+			Method: http.MethodGet, Path: "/api/v1/cluster/:cluster_name/export", ID: "exportCluster", Tag: "cluster", Auth: true,
+			Summary:   "Export a Kubefirst cluster database entry",
+			Responses: map[int]interface{}{http.StatusOK: pkgtypes.Cluster{}},
+		}, router.GetExportCluster},
+		{openapi.Endpoint{
+			Method: http.MethodGet, Path: "/api/v1/cluster/:cluster_name/tls-backups", ID: "listClusterTLSBackups", Tag: "cluster", Auth: true,
+			Summary:   "List the TLS secret backups in the state store of a cluster",
+			Responses: map[int]interface{}{http.StatusOK: []pkgtypes.BucketObject{}},
+		}, router.GetClusterTLSBackups},
+		{openapi.Endpoint{
+			Method: http.MethodGet, Path: "/api/v1/cluster/:cluster_name/certificates", ID: "listClusterCertificates", Tag: "cluster", Auth: true,
+			Summary:   "List the certificates of a cluster with their expiry",
+			Responses: map[int]interface{}{http.StatusOK: []pkgtypes.ClusterCertificate{}},
+		}, router.GetClusterCertificates},
+		{openapi.Endpoint{
+			Method: http.MethodGet, Path: "/api/v1/cluster/:cluster_name/credentials", ID: "getClusterCredentials", Tag: "cluster", Auth: true,
+			Summary:   "Return the platform credentials of a cluster",
+			Responses: map[int]interface{}{http.StatusOK: credentials.PlatformCredentials{}},
+		}, router.GetClusterCredentials},
+		{openapi.Endpoint{
+			Method: http.MethodPost, Path: "/api/v1/cluster/:cluster_name/credentials/rotate", ID: "rotateClusterCredentials", Tag: "cluster", Auth: true,
+			Summary:   "Rotate the platform credentials of a cluster",
+			Responses: map[int]interface{}{http.StatusOK: credentials.PlatformCredentials{}},
+		}, router.PostRotateClusterCredentials},
+		{openapi.Endpoint{
+			Method: http.MethodPost, Path: "/api/v1/cluster/:cluster_name/state-store/migrate", ID: "migrateClusterStateStore", Tag: "cluster", Auth: true,
+			Summary:   "Migrate the state store of a cluster to another bucket",
+			Body:      pkgtypes.StateStoreMigrationRequest{},
+			Responses: map[int]interface{}{http.StatusOK: pkgtypes.StateStoreMigrationResult{}},
+		}, router.PostMigrateClusterStateStore},
+		{openapi.Endpoint{
+			Method: http.MethodGet, Path: "/api/v1/cluster/:cluster_name/vault/secrets/*path", ID: "getClusterVaultSecrets", Tag: "vault", Auth: true,
+			Summary:     "List or read secrets in the vault kv store of a cluster",
+			Description: "Paths ending with a slash are listed, the mount root lists the installed apps with secrets, other paths return the secret with its version history. Only the secrets of catalog apps installed on the cluster are accessible.",
+			Query:       []openapi.Param{{Name: "version", Type: "integer", Description: "Secret version, defaults to the current version"}},
+			Responses:   map[int]interface{}{http.StatusOK: openapi.OneOf{pkgtypes.VaultSecret{}, pkgtypes.VaultSecretList{}}},
+		}, router.GetClusterVaultSecrets},
+		{openapi.Endpoint{
+			Method: http.MethodPut, Path: "/api/v1/cluster/:cluster_name/vault/secrets/*path", ID: "putClusterVaultSecret", Tag: "vault", Auth: true,
+			Summary:     "Write a secret in the vault kv store of a cluster",
+			Description: "Only the secrets of catalog apps installed on the cluster are accessible.",
+			Body:        pkgtypes.VaultSecretWriteRequest{},
+			Responses:   map[int]interface{}{http.StatusOK: pkgtypes.VaultSecret{}},
+		}, router.PutClusterVaultSecret},
+		{openapi.Endpoint{
+			Method: http.MethodDelete, Path: "/api/v1/cluster/:cluster_name/vault/secrets/*path", ID: "deleteClusterVaultSecret", Tag: "vault", Auth: true,
+			Summary:     "Delete versions of a secret in the vault kv store of a cluster",
+			Description: "Only the secrets of catalog apps installed on the cluster are accessible.",
+			Query: []openapi.Param{
+				{Name: "versions", Type: "string", Description: "Comma separated versions to delete, the current version by default"},
+				{Name: "destroy", Type: "boolean", Description: "Permanently destroy the versions"},
+			},
+			Responses: map[int]interface{}{http.StatusOK: success},
+		}, router.DeleteClusterVaultSecret},
+		{openapi.Endpoint{
+			Method: http.MethodPost, Path: "/api/v1/cluster/:cluster_name/reset_progress", ID: "resetClusterProgress", Tag: "cluster", Auth: true,
+			Summary:   "Clear the progress markers of a cluster",
+			Responses: map[int]interface{}{http.StatusOK: success},
+		}, router.PostResetClusterProgress},
+		{openapi.Endpoint{
+			Method: http.MethodPost, Path: "/api/v1/cluster/:cluster_name/vclusters", ID: "createVclusters", Tag: "cluster", Auth: true,
+			Summary:   "Create the default virtual clusters of a management cluster",
+			Responses: map[int]interface{}{http.StatusOK: success},
+		}, router.PostCreateVcluster},
+
+		// KubeConfig
+		{openapi.Endpoint{
+			Method: http.MethodPost, Path: "/api/v1/kubeconfig/:cloud_provider", ID: "getClusterKubeconfig", Tag: "cluster", Auth: true,
+			Summary:   "Return the kubeconfig of a cluster",
+			Body:      types.KubeconfigRequest{},
+			Responses: map[int]interface{}{http.StatusOK: types.KubeconfigResponse{}},
+		}, router.GetClusterKubeConfig},
+
+		// Cluster Secret
+		{openapi.Endpoint{
+			Method: http.MethodGet, Path: "/api/v1/secret/:cluster_name/:secret", ID: "getClusterSecret", Tag: "secret",
+			Summary:   "Return a kubefirst secret of a cluster",
+			Responses: map[int]interface{}{http.StatusOK: map[string]interface{}{}},
+		}, router.GetClusterSecret},
+		{openapi.Endpoint{
+			Method: http.MethodPost, Path: "/api/v1/secret/:cluster_name/:secret", ID: "createClusterSecret", Tag: "secret",
+			Summary:   "Create a kubefirst secret of a cluster",
+			Body:      map[string]interface{}{},
+			Responses: map[int]interface{}{http.StatusOK: success},
+		}, router.CreateClusterSecret},
+		{openapi.Endpoint{
+			Method: http.MethodPut, Path: "/api/v1/secret/:cluster_name/:secret", ID: "updateClusterSecret", Tag: "secret",
+			Summary:   "Update a kubefirst secret of a cluster",
+			Body:      map[string]interface{}{},
+			Responses: map[int]interface{}{http.StatusOK: success},
+		}, router.UpdateClusterSecret},
+
+		// Gitops Catalog
+		{openapi.Endpoint{
+			Method: http.MethodGet, Path: "/api/v1/gitops-catalog/:cluster_name/:cloud_provider/apps", ID: "listGitopsCatalogApps", Tag: "gitops-catalog", Auth: true,
+			Summary: "Return the gitops catalog applications available to a cluster",
+			Query: []openapi.Param{
+				{Name: "category", Type: "string", Description: "Only return apps in this category"},
+				{Name: "q", Type: "string", Description: "Search the app display name and description"},
+				{Name: "installed", Type: "boolean", Description: "Only return apps that are (true) or are not (false) installed on the cluster"},
+				{Name: "page", Type: "integer", Description: "Page number, starting at 1"},
+				{Name: "page_size", Type: "integer", Description: "Apps per page, all apps when omitted"},
+			},
+			Responses: map[int]interface{}{http.StatusOK: pkgtypes.GitopsCatalogAppsPage{}},
+		}, router.GetGitopsCatalogApps},
+		{openapi.Endpoint{
+			Method: http.MethodGet, Path: "/api/v1/gitops-catalog/apps/update", ID: "updateGitopsCatalogApps", Tag: "gitops-catalog", Auth: true,
+			Summary:   "Refresh the gitops catalog applications",
+			Responses: map[int]interface{}{http.StatusOK: success},
+		}, router.UpdateGitopsCatalogApps},
+		{openapi.Endpoint{
+			Method: http.MethodGet, Path: "/api/v1/gitops-catalog/categories", ID: "listGitopsCatalogCategories", Tag: "gitops-catalog", Auth: true,
+			Summary: "Return the gitops catalog categories with their app count",
+			Query: []openapi.Param{
+				{Name: "cloud_provider", Type: "string", Description: "Cloud provider of the cluster, its denylisted apps are not counted", Required: true},
+				{Name: "git_provider", Type: "string", Description: "Git provider of the cluster, its denylisted apps are not counted", Required: true},
+			},
+			Responses: map[int]interface{}{http.StatusOK: pkgtypes.GitopsCatalogCategories{}},
+		}, router.GetGitopsCatalogCategories},
+
+		// Services
+		{openapi.Endpoint{
+			Method: http.MethodGet, Path: "/api/v1/services/:cluster_name", ID: "listServices", Tag: "services", Auth: true,
+			Summary:   "Return the services of a cluster",
+			Responses: map[int]interface{}{http.StatusOK: pkgtypes.ClusterServiceList{}},
+		}, router.GetServices},
+		{openapi.Endpoint{
+			Method: http.MethodPost, Path: "/api/v1/services/:cluster_name/bulk", ID: "bulkAddServices", Tag: "services", Auth: true,
+			Summary:   "Start adding several gitops catalog services to a cluster",
+			Body:      pkgtypes.GitopsCatalogAppBulkCreateRequest{},
+			Responses: map[int]interface{}{http.StatusAccepted: pkgtypes.GitopsCatalogAppBulkCreateResponse{}},
+		}, router.PostBulkAddServicesToCluster},
+		{openapi.Endpoint{
+			Method: http.MethodGet, Path: "/api/v1/services/:cluster_name/bulk", ID: "getBulkAddServices", Tag: "services", Auth: true,
+			Summary:   "Return the progress of the last bulk install of a cluster",
+			Responses: map[int]interface{}{http.StatusOK: pkgtypes.GitopsCatalogAppBulkCreateResponse{}},
+		}, router.GetBulkAddServices},
+		{openapi.Endpoint{
+			Method: http.MethodPost, Path: "/api/v1/services/:cluster_name/:service_name", ID: "addService", Tag: "services", Auth: true,
+			Summary:   "Add a gitops catalog service to a cluster",
+			Body:      pkgtypes.GitopsCatalogAppCreateRequest{},
+			Responses: map[int]interface{}{http.StatusOK: success},
+		}, router.PostAddServiceToCluster},
+		{openapi.Endpoint{
+			Method: http.MethodPut, Path: "/api/v1/services/:cluster_name/:service_name", ID: "updateService", Tag: "services", Auth: true,
+			Summary:   "Update the values of a service of a cluster",
+			Body:      pkgtypes.GitopsCatalogAppUpdateRequest{},
+			Responses: map[int]interface{}{http.StatusOK: success},
+		}, router.PutUpdateService},
+		{openapi.Endpoint{
+			Method: http.MethodPost, Path: "/api/v1/services/:cluster_name/:service_name/validate", ID: "validateService", Tag: "services", Auth: true,
+			Summary:   "Check whether a service can be removed from a cluster",
+			Body:      pkgtypes.GitopsCatalogAppCreateRequest{},
+			Responses: map[int]interface{}{http.StatusOK: pkgtypes.GitopsCatalogAppValidateRequest{}},
+		}, router.PostValidateService},
+		{openapi.Endpoint{
+			Method: http.MethodPost, Path: "/api/v1/services/:cluster_name/:service_name/targets", ID: "addServiceToTargets", Tag: "services", Auth: true,
+			Summary:   "Add a gitops catalog service to several clusters",
+			Body:      pkgtypes.GitopsCatalogAppMultiTargetCreateRequest{},
+			Responses: map[int]interface{}{http.StatusOK: pkgtypes.GitopsCatalogAppMultiTargetCreateResponse{}},
+		}, router.PostAddServiceToTargets},
+		{openapi.Endpoint{
+			Method: http.MethodDelete, Path: "/api/v1/services/:cluster_name/:service_name", ID: "deleteService", Tag: "services", Auth: true,
+			Summary:   "Remove a service from a cluster",
+			Body:      pkgtypes.GitopsCatalogAppDeleteRequest{},
+			Responses: map[int]interface{}{http.StatusOK: success},
+		}, router.DeleteServiceFromCluster},
+
+		// Domains
+		{openapi.Endpoint{
+			Method: http.MethodPost, Path: "/api/v1/domain/:dns_provider", ID: "listDomains", Tag: "domain", Auth: true,
+			Summary:   "Return the domains of a dns provider account",
+			Body:      types.DomainListRequest{},
+			Responses: map[int]interface{}{http.StatusOK: types.DomainListResponse{}},
+		}, router.PostDomains},
+		{openapi.Endpoint{
+			Method: http.MethodGet, Path: "/api/v1/domain/:domain/certificate-usage", ID: "getCertificateUsage", Tag: "domain", Auth: true,
+			Summary:   "Return the LetsEncrypt certificate budget used by a domain",
+			Responses: map[int]interface{}{http.StatusOK: certificates.CertificateUsage{}},
+		}, router.GetCertificateUsage},
+		{openapi.Endpoint{
+			Method: http.MethodGet, Path: "/api/v1/domain/validate/aws/:domain", ID: "validateAWSDomain", Tag: "domain", Auth: true,
+			Summary:   "Check a Route53 hosted zone is usable for a cluster",
+			Responses: map[int]interface{}{http.StatusOK: types.AWSDomainValidateResponse{}},
+		}, router.GetValidateAWSDomain},
+		{openapi.Endpoint{
+			Method: http.MethodGet, Path: "/api/v1/domain/validate/civo/:domain", ID: "validateCivoDomain", Tag: "domain", Auth: true,
+			Summary:   "Check a Civo domain is usable for a cluster",
+			Body:      types.CivoDomainValidationRequest{},
+			Responses: map[int]interface{}{http.StatusOK: types.CivoDomainValidationResponse{}},
+		}, router.GetValidateCivoDomain},
+		{openapi.Endpoint{
+			Method: http.MethodPost, Path: "/api/v1/domain/validate/cloudflare/:domain", ID: "validateCloudflareDomain", Tag: "domain", Auth: true,
+			Summary:   "Check a Cloudflare domain is usable for a cluster",
+			Body:      types.CloudflareDomainValidationRequest{},
+			Responses: map[int]interface{}{http.StatusOK: types.CloudflareDomainValidationResponse{}},
+		}, router.PostValidateCloudflareDomain},
+		{openapi.Endpoint{
+			Method: http.MethodPost, Path: "/api/v1/domain/validate/digitalocean/:domain", ID: "validateDigitalOceanDomain", Tag: "domain", Auth: true,
+			Summary:   "Check a DigitalOcean domain is usable for a cluster",
+			Body:      types.DigitalOceanDomainValidationRequest{},
+			Responses: map[int]interface{}{http.StatusOK: types.DigitalOceanDomainValidationResponse{}},
+		}, router.PostValidateDigitalOceanDomain},
+
+		// Regions, zones and instance sizes
+		{openapi.Endpoint{
+			Method: http.MethodPost, Path: "/api/v1/region/:cloud_provider", ID: "listRegions", Tag: "region", Auth: true,
+			Summary:   "Return the regions of a cloud provider",
+			Body:      types.RegionListRequest{},
+			Responses: map[int]interface{}{http.StatusOK: types.RegionListResponse{}},
+		}, router.PostRegions},
+		{openapi.Endpoint{
+			Method: http.MethodPost, Path: "/api/v1/zones", ID: "listZones", Tag: "region", Auth: true,
+			Summary:   "Return the zones of a Google Cloud region",
+			Body:      types.ZonesListRequest{},
+			Responses: map[int]interface{}{http.StatusOK: types.ZonesListResponse{}},
+		}, router.ListZonesForRegion},
+		{openapi.Endpoint{
+			Method: http.MethodPost, Path: "/api/v1/instance-sizes/:cloud_provider", ID: "listInstanceSizes", Tag: "region", Auth: true,
+			Summary:   "Return the instance sizes of a cloud provider region",
+			Body:      types.InstanceSizesRequest{},
+			Responses: map[int]interface{}{http.StatusOK: types.InstanceSizesResponse{}},
+		}, router.ListInstanceSizesForRegion},
+		{openapi.Endpoint{
+			Method: http.MethodGet, Path: "/api/v1/cloud-defaults", ID: "getCloudDefaults", Tag: "region", Auth: true,
+			Summary:   "Return the default instance size and node count of the cloud providers",
+			Responses: map[int]interface{}{http.StatusOK: pkgtypes.CloudProviderDefaults{}},
+		}, router.GetCloudProviderDefaults},
+
+		// Environments
+		{openapi.Endpoint{
+			Method: http.MethodGet, Path: "/api/v1/environment", ID: "listEnvironments", Tag: "environment", Auth: true,
+			Summary:   "Return all environments",
+			Responses: map[int]interface{}{http.StatusOK: []pkgtypes.Environment{}},
+		}, router.GetEnvironments},
+		{openapi.Endpoint{
+			Method: http.MethodPost, Path: "/api/v1/environment", ID: "createEnvironment", Tag: "environment", Auth: true,
+			Summary:   "Create an environment",
+			Body:      pkgtypes.Environment{},
+			Responses: map[int]interface{}{http.StatusCreated: pkgtypes.Environment{}},
+		}, router.CreateEnvironment},
+		{openapi.Endpoint{
+			Method: http.MethodDelete, Path: "/api/v1/environment/:environment_id", ID: "deleteEnvironment", Tag: "environment", Auth: true,
+			Summary:   "Delete an environment",
+			Responses: map[int]interface{}{http.StatusOK: success},
+		}, router.DeleteEnvironment},
+		{openapi.Endpoint{
+			Method: http.MethodPut, Path: "/api/v1/environment/:environment_id", ID: "updateEnvironment", Tag: "environment", Auth: true,
+			Summary:   "Update the color and description of an environment",
+			Body:      types.EnvironmentUpdateRequest{},
+			Responses: map[int]interface{}{http.StatusOK: success},
+		}, router.UpdateEnvironment},
+
+		// Utilities
+		{openapi.Endpoint{
+			Method: http.MethodGet, Path: "/api/v1/health", ID: "getHealth", Tag: "health",
+			Summary:   "Return health status of the application",
+			Responses: map[int]interface{}{http.StatusOK: types.JSONHealthResponse{}},
+		}, router.GetHealth},
+
+		// Event streaming
+		{openapi.Endpoint{
+			Method: http.MethodGet, Path: "/api/v1/stream/:file_name", ID: "streamLogs", Tag: "logs",
+			Summary:     "Stream a log file of the API",
+			Description: "Every line of the file is sent as a server-sent event, an error event ends the stream when the file cannot be read.",
+			Responses:   map[int]interface{}{http.StatusOK: ""},
+			ContentType: openapi.ContentTypeSSE,
+		}, router.GetLogs},
+
+		// Telemetry
+		{openapi.Endpoint{
+			Method: http.MethodPost, Path: "/api/v1/telemetry/:cluster_name", ID: "sendTelemetry", Tag: "telemetry", Auth: true,
+			Summary:   "Send a telemetry event of a cluster",
+			Body:      types.TelemetryRequest{},
+			Responses: map[int]interface{}{http.StatusOK: true},
+		}, router.PostTelemetry},
+	}
+}
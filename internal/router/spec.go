@@ -0,0 +1,55 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package api
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/konstructio/kubefirst-api/internal/apierror"
+	"github.com/konstructio/kubefirst-api/internal/openapi"
+	"github.com/konstructio/kubefirst-api/internal/types"
+)
+
+// specInfo describes the API in its OpenAPI document
+var specInfo = openapi.Info{
+	Title:       "Kubefirst API",
+	Description: "Kubefirst API",
+	Version:     "1.0",
+}
+
+var (
+	specOnce sync.Once
+	spec     *openapi.Document
+	specErr  error
+)
+
+// Spec returns the OpenAPI 3 document of the API built from the route table
+func Spec() (*openapi.Document, error) {
+	specOnce.Do(func() {
+		table := routes()
+		endpoints := make([]openapi.Endpoint, 0, len(table))
+		for _, rt := range table {
+			endpoints = append(endpoints, rt.Endpoint)
+		}
+		spec, specErr = openapi.Build(specInfo, types.JSONFailureResponse{}, endpoints)
+	})
+	return spec, specErr
+}
+
+// getSpec serves the OpenAPI document
+func getSpec(c *gin.Context) {
+	doc, err := Spec()
+	if err != nil {
+		e := apierror.From(err)
+		c.JSON(e.Status(), e.Response())
+		return
+	}
+
+	c.JSON(http.StatusOK, doc)
+}
@@ -0,0 +1,95 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package secrets
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/konstructio/kubefirst-api/internal/k8s"
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
+	log "github.com/rs/zerolog/log"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	kubefirstCredentialsAuditPrefix = "kubefirst-credentials-audit"
+	// credentialsAuditMaxEntries caps the size of the audit secret
+	credentialsAuditMaxEntries = 500
+)
+
+// GetCredentialsAuditLog returns the credentials audit log of a cluster
+func GetCredentialsAuditLog(clientSet kubernetes.Interface, clusterName string) (*pkgtypes.CredentialsAuditLog, error) {
+	auditSecret, err := k8s.ReadSecretV2Old(clientSet, "kubefirst", fmt.Sprintf("%s-%s", kubefirstCredentialsAuditPrefix, clusterName))
+	if err != nil {
+		return nil, fmt.Errorf("error reading credentials audit secret for cluster %s: %w", clusterName, err)
+	}
+
+	jsonString, err := MapToStructuredJSON(auditSecret)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing json: %w", err)
+	}
+
+	jsonData, err := json.Marshal(jsonString)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling json: %w", err)
+	}
+
+	auditLog := pkgtypes.CredentialsAuditLog{}
+	if err := json.Unmarshal(jsonData, &auditLog); err != nil {
+		return nil, fmt.Errorf("unable to cast credentials audit log: %w", err)
+	}
+
+	return &auditLog, nil
+}
+
+// RecordCredentialsAccess appends an entry to the credentials audit log of a
+// cluster, creating the log on first access
+func RecordCredentialsAccess(clientSet kubernetes.Interface, clusterName string, entry pkgtypes.CredentialsAuditEntry) error {
+	auditLog, err := GetCredentialsAuditLog(clientSet, clusterName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+	if !exists {
+		auditLog = &pkgtypes.CredentialsAuditLog{ClusterName: clusterName}
+	}
+
+	auditLog.Entries = append(auditLog.Entries, entry)
+	if len(auditLog.Entries) > credentialsAuditMaxEntries {
+		auditLog.Entries = auditLog.Entries[len(auditLog.Entries)-credentialsAuditMaxEntries:]
+	}
+
+	bytes, err := json.Marshal(auditLog)
+	if err != nil {
+		return fmt.Errorf("error marshalling json: %w", err)
+	}
+
+	secretValuesMap, err := ParseJSONToMap(string(bytes))
+	if err != nil {
+		return fmt.Errorf("error parsing json: %w", err)
+	}
+
+	name := fmt.Sprintf("%s-%s", kubefirstCredentialsAuditPrefix, clusterName)
+	if exists {
+		err = k8s.UpdateSecretV2(clientSet, "kubefirst", name, secretValuesMap)
+	} else {
+		err = k8s.CreateSecretV2(clientSet, &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "kubefirst"},
+			Data:       secretValuesMap,
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("error recording credentials access for cluster %s: %w", clusterName, err)
+	}
+
+	log.Info().Msgf("credentials of cluster %s accessed: action=%s client_ip=%s user_agent=%q", clusterName, entry.Action, entry.ClientIP, entry.UserAgent)
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/konstructio/kubefirst-api/internal/apierror"
 	"github.com/konstructio/kubefirst-api/internal/k8s"
 	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
 	log "github.com/rs/zerolog/log"
@@ -53,6 +54,11 @@ func (e *ClusterNotFoundError) Error() string {
 	return fmt.Sprintf("cluster %q not found", e.ClusterName)
 }
 
+// APIError returns the error code of a missing cluster
+func (e *ClusterNotFoundError) APIError() *apierror.Error {
+	return apierror.ClusterNotFound(e.ClusterName)
+}
+
 func (e *ClusterNotFoundError) Is(target error) bool {
 	_, ok := target.(*ClusterNotFoundError)
 	return ok
@@ -101,7 +101,8 @@ func GetGitopsCatalogAppsByCloudProvider(clientSet kubernetes.Interface, cloudPr
 func UpdateGitopsCatalogApps(clientSet kubernetes.Interface) error {
 	mpapps, err := gitopsCatalog.ReadActiveApplications()
 	if err != nil {
-		log.Error().Msgf("error reading gitops catalog apps at startup: %s", err)
+		log.Error().Msgf("error reading gitops catalog apps: %s", err)
+		return fmt.Errorf("error reading gitops catalog apps: %w", err)
 	}
 
 	catalogApps, err := GetGitopsCatalogApps(clientSet)
@@ -107,7 +107,10 @@ func DeleteClusterServiceListEntry(clientSet kubernetes.Interface, clusterName s
 // GetService returns a single service associated with a given cluster
 func GetService(clientSet kubernetes.Interface, clusterName string, serviceName string) (types.Service, error) {
 	// Find
-	clusterServices, _ := GetServices(clientSet, clusterName)
+	clusterServices, err := GetServices(clientSet, clusterName)
+	if err != nil {
+		return types.Service{}, fmt.Errorf("error getting services for cluster %s: %w", clusterName, err)
+	}
 
 	for _, service := range clusterServices.Services {
 		if service.Name == serviceName {
@@ -172,3 +175,42 @@ func InsertClusterServiceListEntry(clientSet kubernetes.Interface, clusterName s
 	log.Info().Msgf("service added: %v", def.Name)
 	return nil
 }
+
+// UpdateClusterServiceListEntry replaces an existing service entry in a cluster's service list
+func UpdateClusterServiceListEntry(clientSet kubernetes.Interface, clusterName string, def *types.Service) error {
+	// Find
+	clusterServices, err := GetServices(clientSet, clusterName)
+	if err != nil {
+		return fmt.Errorf("error updating service list entry %s: %w", def.Name, err)
+	}
+
+	found := false
+	for i, service := range clusterServices.Services {
+		if service.Name == def.Name {
+			clusterServices.Services[i] = *def
+			found = true
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("error updating service list entry %s: service not found for cluster %s", def.Name, clusterName)
+	}
+
+	bytes, err := json.Marshal(clusterServices)
+	if err != nil {
+		return fmt.Errorf("error marshalling json: %w", err)
+	}
+
+	secretValuesMap, err := ParseJSONToMap(string(bytes))
+	if err != nil {
+		return fmt.Errorf("error parsing json: %w", err)
+	}
+
+	err = k8s.UpdateSecretV2(clientSet, "kubefirst", fmt.Sprintf("%s-%s", kubefirstServicesPrefix, clusterName), secretValuesMap)
+	if err != nil {
+		return fmt.Errorf("error updating service list entry %s: %w", def.Name, err)
+	}
+
+	log.Info().Msgf("service updated: %v", def.Name)
+	return nil
+}
@@ -0,0 +1,86 @@
+package secrets
+
+import (
+	"testing"
+
+	"github.com/konstructio/kubefirst-api/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetService(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+
+	if _, err := GetService(clientset, "test", "metaphor"); err == nil {
+		t.Fatal("expected an error when the service list does not exist")
+	}
+
+	if err := CreateClusterServiceList(clientset, "test"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := InsertClusterServiceListEntry(clientset, "test", &types.Service{Name: "metaphor"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	svc, err := GetService(clientset, "test", "metaphor")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if svc.Name != "metaphor" {
+		t.Errorf("expected service metaphor, got %q", svc.Name)
+	}
+
+	if _, err := GetService(clientset, "test", "unknown"); err == nil {
+		t.Error("expected an error for a service that is not installed")
+	}
+}
+
+func TestUpdateClusterServiceListEntry(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+
+	if err := UpdateClusterServiceListEntry(clientset, "test", &types.Service{Name: "metaphor"}); err == nil {
+		t.Fatal("expected an error when the service list does not exist")
+	}
+
+	if err := CreateClusterServiceList(clientset, "test"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for _, name := range []string{"metaphor", "kyverno"} {
+		if err := InsertClusterServiceListEntry(clientset, "test", &types.Service{Name: name, CatalogVersion: "abc"}); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	updated := &types.Service{
+		Name:           "metaphor",
+		CatalogVersion: "abc",
+		ConfigKeys:     []types.GitopsCatalogAppKeys{{Name: "REPLICAS", Value: "3"}},
+		UpdatedBy:      []types.ServiceUpdate{{User: "kbot", Timestamp: "2024-01-01T00:00:00Z"}},
+	}
+	if err := UpdateClusterServiceListEntry(clientset, "test", updated); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	list, err := GetServices(clientset, "test")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(list.Services) != 2 {
+		t.Fatalf("expected 2 services, got %d", len(list.Services))
+	}
+	for _, svc := range list.Services {
+		switch svc.Name {
+		case "metaphor":
+			if len(svc.ConfigKeys) != 1 || svc.ConfigKeys[0].Value != "3" || len(svc.UpdatedBy) != 1 {
+				t.Errorf("expected metaphor to be updated, got %+v", svc)
+			}
+		case "kyverno":
+			if len(svc.ConfigKeys) != 0 || len(svc.UpdatedBy) != 0 {
+				t.Errorf("expected kyverno to be left untouched, got %+v", svc)
+			}
+		}
+	}
+
+	if err := UpdateClusterServiceListEntry(clientset, "test", &types.Service{Name: "unknown"}); err == nil {
+		t.Error("expected an error for a service that is not installed")
+	}
+}
@@ -0,0 +1,499 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package services
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-git/go-git/v5"
+	githttps "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/konstructio/kubefirst-api/internal/apierror"
+	"github.com/konstructio/kubefirst-api/internal/constants"
+	"github.com/konstructio/kubefirst-api/internal/env"
+	"github.com/konstructio/kubefirst-api/internal/gitClient"
+	"github.com/konstructio/kubefirst-api/internal/gitHost"
+	"github.com/konstructio/kubefirst-api/internal/gitShim"
+	"github.com/konstructio/kubefirst-api/internal/secrets"
+	internalutils "github.com/konstructio/kubefirst-api/internal/utils"
+	"github.com/konstructio/kubefirst-api/pkg/common"
+	"github.com/konstructio/kubefirst-api/pkg/providerConfigs"
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
+	utils "github.com/konstructio/kubefirst-api/pkg/utils"
+	cp "github.com/otiai10/copy"
+	log "github.com/rs/zerolog/log"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Bulk install result statuses
+const (
+	BulkStatusInstalled = "installed"
+	BulkStatusFailed    = "failed"
+	BulkStatusSkipped   = "skipped"
+)
+
+// SortByDependencies groups catalog apps into install waves so that every app is
+// installed after the apps it depends on. Dependencies already installed on the
+// cluster are treated as satisfied. Apps keep their request order within a wave.
+func SortByDependencies(apps []pkgtypes.GitopsCatalogApp, installed []string) ([][]pkgtypes.GitopsCatalogApp, error) {
+	requested := make(map[string]bool, len(apps))
+	for _, app := range apps {
+		requested[app.Name] = true
+	}
+
+	pending := make(map[string][]string, len(apps))
+	for _, app := range apps {
+		deps := []string{}
+		for _, dep := range app.DependsOn {
+			switch {
+			case requested[dep]:
+				deps = append(deps, dep)
+			case internalutils.FindStringInSlice(installed, dep):
+				continue
+			default:
+				return nil, fmt.Errorf("app %q depends on %q, which is neither installed nor part of the request", app.Name, dep)
+			}
+		}
+		pending[app.Name] = deps
+	}
+
+	waves := [][]pkgtypes.GitopsCatalogApp{}
+	done := make(map[string]bool, len(apps))
+
+	for len(done) < len(apps) {
+		wave := []pkgtypes.GitopsCatalogApp{}
+		for _, app := range apps {
+			if done[app.Name] {
+				continue
+			}
+
+			ready := true
+			for _, dep := range pending[app.Name] {
+				if !done[dep] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				wave = append(wave, app)
+			}
+		}
+
+		if len(wave) == 0 {
+			remaining := []string{}
+			for _, app := range apps {
+				if !done[app.Name] {
+					remaining = append(remaining, app.Name)
+				}
+			}
+			sort.Strings(remaining)
+			return nil, fmt.Errorf("dependency cycle between apps: %s", strings.Join(remaining, ", "))
+		}
+
+		for _, app := range wave {
+			done[app.Name] = true
+		}
+		waves = append(waves, wave)
+	}
+
+	return waves, nil
+}
+
+// Bulk install progress statuses
+const (
+	BulkInstallRunning   = "running"
+	BulkInstallCompleted = "completed"
+	BulkInstallFailed    = "failed"
+)
+
+// bulkInstalls holds the progress of the last bulk install of every cluster.
+// It is kept in memory, so it does not survive a restart of the api.
+var bulkInstalls = struct {
+	sync.Mutex
+	progress map[string]*pkgtypes.GitopsCatalogAppBulkCreateResponse
+}{progress: map[string]*pkgtypes.GitopsCatalogAppBulkCreateResponse{}}
+
+// StartBulkInstall registers a running bulk install for a cluster, only one
+// bulk install can run on a cluster at a time
+func StartBulkInstall(clusterName string) error {
+	bulkInstalls.Lock()
+	defer bulkInstalls.Unlock()
+
+	if p, ok := bulkInstalls.progress[clusterName]; ok && p.Status == BulkInstallRunning {
+		return apierror.OperationInProgress(clusterName, "cluster %q - a bulk install is already running", clusterName)
+	}
+	bulkInstalls.progress[clusterName] = &pkgtypes.GitopsCatalogAppBulkCreateResponse{
+		Status:  BulkInstallRunning,
+		Results: []pkgtypes.GitopsCatalogAppBulkCreateResult{},
+	}
+	return nil
+}
+
+// GetBulkInstall returns the progress of the last bulk install of a cluster
+func GetBulkInstall(clusterName string) (pkgtypes.GitopsCatalogAppBulkCreateResponse, bool) {
+	bulkInstalls.Lock()
+	defer bulkInstalls.Unlock()
+
+	p, ok := bulkInstalls.progress[clusterName]
+	if !ok {
+		return pkgtypes.GitopsCatalogAppBulkCreateResponse{}, false
+	}
+	copied := *p
+	copied.Results = append([]pkgtypes.GitopsCatalogAppBulkCreateResult{}, p.Results...)
+	return copied, true
+}
+
+// recordBulkInstall stores the results of a running bulk install. Installs that
+// were not started with StartBulkInstall are not recorded.
+func recordBulkInstall(clusterName string, results []pkgtypes.GitopsCatalogAppBulkCreateResult, status string, err error) {
+	bulkInstalls.Lock()
+	defer bulkInstalls.Unlock()
+
+	p, ok := bulkInstalls.progress[clusterName]
+	if !ok || p.Status != BulkInstallRunning {
+		return
+	}
+	p.Status = status
+	p.Results = append([]pkgtypes.GitopsCatalogAppBulkCreateResult{}, results...)
+	if err != nil {
+		p.Error = err.Error()
+	}
+}
+
+// ValidateServices checks that a set of gitops catalog apps can be installed on
+// a cluster: the cluster accepts services, none of the apps is installed yet
+// and their dependencies can be ordered
+func ValidateServices(cl *pkgtypes.Cluster, apps []pkgtypes.GitopsCatalogApp, req *pkgtypes.GitopsCatalogAppBulkCreateRequest) error {
+	target := newServiceTarget(cl, req.WorkloadClusterName, req.Environment)
+	kcfg := internalutils.GetKubernetesClient(cl.ClusterName)
+
+	_, _, err := planServices(kcfg.Clientset, cl, target.clusterName, apps)
+	return err
+}
+
+// planServices returns the install waves of apps and whether the cluster
+// already has a service list
+func planServices(clientset kubernetes.Interface, cl *pkgtypes.Cluster, clusterName string, apps []pkgtypes.GitopsCatalogApp) ([][]pkgtypes.GitopsCatalogApp, bool, error) {
+	switch cl.Status {
+	case constants.ClusterStatusDeleted, constants.ClusterStatusDeleting, constants.ClusterStatusError, constants.ClusterStatusInterrupted, constants.ClusterStatusProvisioning:
+		return nil, false, apierror.New(apierror.CodeConflict, "cluster %q - unable to deploy services to cluster: cannot deploy services to a cluster in %q state", cl.ClusterName, cl.Status)
+	}
+
+	installed := []string{}
+	existingServices, err := secrets.GetServices(clientset, clusterName)
+	hasServiceList := err == nil && existingServices.ClusterName != ""
+	if hasServiceList {
+		for _, svc := range existingServices.Services {
+			installed = append(installed, svc.Name)
+		}
+	}
+
+	if err := rejectInstalled(apps, installed); err != nil {
+		return nil, false, fmt.Errorf("cluster %q - %w", clusterName, err)
+	}
+
+	waves, err := SortByDependencies(apps, installed)
+	if err != nil {
+		return nil, false, fmt.Errorf("cluster %q - %w", clusterName, err)
+	}
+
+	return waves, hasServiceList, nil
+}
+
+// rejectInstalled returns a conflict for the apps that are already installed
+func rejectInstalled(apps []pkgtypes.GitopsCatalogApp, installed []string) error {
+	names := []string{}
+	for _, app := range apps {
+		if internalutils.FindStringInSlice(installed, app.Name) {
+			names = append(names, app.Name)
+		}
+	}
+	if len(names) > 0 {
+		return apierror.New(apierror.CodeConflict, "services already installed: %s", strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// CreateServices installs a set of gitops catalog apps in dependency order. Each
+// wave of independent apps is rendered into the gitops repository and pushed as
+// a single commit, and the outcome of every app is returned rather than aborting
+// on the first failure. Apps whose dependencies failed are skipped, as are the
+// waves left when ctx is canceled. The progress of installs started with
+// StartBulkInstall is recorded after every wave.
+func CreateServices(ctx context.Context, e env.Env, cl *pkgtypes.Cluster, apps []pkgtypes.GitopsCatalogApp, req *pkgtypes.GitopsCatalogAppBulkCreateRequest, excludeArgoSync bool) (results []pkgtypes.GitopsCatalogAppBulkCreateResult, err error) {
+	defer func() {
+		if err != nil {
+			recordBulkInstall(cl.ClusterName, results, BulkInstallFailed, err)
+			return
+		}
+		recordBulkInstall(cl.ClusterName, results, BulkInstallCompleted, nil)
+	}()
+
+	target := newServiceTarget(cl, req.WorkloadClusterName, req.Environment)
+	clusterName := target.clusterName
+	kcfg := internalutils.GetKubernetesClient(cl.ClusterName)
+
+	waves, hasServiceList, err := planServices(kcfg.Clientset, cl, clusterName, apps)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[string]pkgtypes.GitopsCatalogAppBulkCreateEntry, len(req.Apps))
+	for _, entry := range req.Apps {
+		entries[entry.Name] = entry
+	}
+
+	homeDir, _ := os.UserHomeDir()
+	tmpGitopsDir := fmt.Sprintf("%s/.k1/%s/bulk/gitops", homeDir, cl.ClusterName)
+	tmpGitopsCatalogDir := fmt.Sprintf("%s/.k1/%s/bulk/gitops-catalog", homeDir, cl.ClusterName)
+
+	for _, dir := range []string{tmpGitopsDir, tmpGitopsCatalogDir} {
+		if err := os.RemoveAll(dir); err != nil {
+			log.Error().Msgf("error removing gitops dir %s: %s", dir, err)
+			return nil, fmt.Errorf("cluster %q - error removing gitops dir %q: %w", cl.ClusterName, dir, err)
+		}
+	}
+
+	err = gitShim.PrepareGitEnvironment(cl, tmpGitopsDir)
+	if err != nil {
+		log.Error().Msgf("an error occurred preparing git environment %s %s", tmpGitopsDir, err)
+		return nil, fmt.Errorf("cluster %q - error preparing git environment %q: %w", cl.ClusterName, tmpGitopsDir, err)
+	}
+
+	err = gitShim.PrepareGitOpsCatalog(tmpGitopsCatalogDir)
+	if err != nil {
+		log.Error().Msgf("an error occurred preparing gitops catalog environment %s %s", tmpGitopsCatalogDir, err)
+		return nil, fmt.Errorf("cluster %q - error preparing gitops catalog environment %q: %w", cl.ClusterName, tmpGitopsCatalogDir, err)
+	}
+
+	catalogVersion, err := gitShim.GitOpsCatalogRevision(tmpGitopsCatalogDir)
+	if err != nil {
+		return nil, fmt.Errorf("cluster %q - error reading gitops catalog revision: %w", cl.ClusterName, err)
+	}
+
+	gitopsRepo, err := git.PlainOpen(tmpGitopsDir)
+	if err != nil {
+		log.Error().Msgf("error opening gitops repo: %s", err)
+		return nil, fmt.Errorf("cluster %q - error opening gitops repo: %w", cl.ClusterName, err)
+	}
+
+	if !hasServiceList {
+		if err := secrets.CreateClusterServiceList(kcfg.Clientset, clusterName); err != nil {
+			return nil, fmt.Errorf("cluster %q - error creating service list: %w", clusterName, err)
+		}
+	}
+
+	fullDomainName := getFullDomainName(cl)
+	vaultURL := getVaultURL(cl, fullDomainName)
+	registryPath := getRegistryPath(clusterName, cl.CloudProvider, false)
+	clusterRegistryPath := fmt.Sprintf("%s/%s", tmpGitopsDir, registryPath)
+
+	results = []pkgtypes.GitopsCatalogAppBulkCreateResult{}
+	failed := map[string]bool{}
+	aborted := ""
+
+	for i, wave := range waves {
+		waveNumber := i + 1
+		waveResults := []pkgtypes.GitopsCatalogAppBulkCreateResult{}
+		rendered := []pkgtypes.Service{}
+
+		if aborted == "" && ctx.Err() != nil {
+			aborted = "bulk install canceled before the wave started"
+		}
+
+		for _, app := range wave {
+			result := pkgtypes.GitopsCatalogAppBulkCreateResult{Name: app.Name, Wave: waveNumber}
+
+			if aborted != "" {
+				result.Status = BulkStatusSkipped
+				result.Error = aborted
+				failed[app.Name] = true
+				waveResults = append(waveResults, result)
+				continue
+			}
+
+			if dep := firstFailedDependency(app, failed); dep != "" {
+				result.Status = BulkStatusSkipped
+				result.Error = fmt.Sprintf("dependency %q was not installed", dep)
+				failed[app.Name] = true
+				waveResults = append(waveResults, result)
+				continue
+			}
+
+			entry := entries[app.Name]
+			catalogServiceFolder := fmt.Sprintf("%s/%s", tmpGitopsCatalogDir, app.Name)
+
+			if len(entry.SecretKeys) > 0 {
+				log.Info().Msgf("cluster %q - application %q has secrets, creating vault values", clusterName, app.Name)
+				if err := putServiceSecrets(kcfg, vaultURL, clusterName, app.Name, entry.SecretKeys); err != nil {
+					result.Status, result.Error = BulkStatusFailed, err.Error()
+					failed[app.Name] = true
+					waveResults = append(waveResults, result)
+					continue
+				}
+			}
+
+			gitopsKubefirstTokens := utils.CreateTokensFromDatabaseRecord(cl, registryPath, target.secretStoreRef, target.project, target.clusterDestination, target.environment, clusterName, e.KubefirstVersion)
+
+			err := providerConfigs.DetokenizeGitGitops(catalogServiceFolder, gitopsKubefirstTokens, cl.GitProtocol, cl.CloudflareAuth.OriginCaIssuerKey != "")
+			if err == nil {
+				err = DetokenizeConfigKeys(catalogServiceFolder, entry.ConfigKeys)
+			}
+			if err == nil {
+				err = cp.Copy(catalogServiceFolder, clusterRegistryPath, cp.Options{})
+				if err != nil {
+					// a partial copy must not end up in the commit of the wave
+					if cleanupErr := removeCopiedFiles(catalogServiceFolder, clusterRegistryPath); cleanupErr != nil {
+						log.Error().Msgf("cluster %q - error removing the files of %q: %s", clusterName, app.Name, cleanupErr)
+					}
+				}
+			}
+			if err != nil {
+				result.Status = BulkStatusFailed
+				result.Error = fmt.Sprintf("error rendering catalog components: %s", err)
+				failed[app.Name] = true
+				waveResults = append(waveResults, result)
+				continue
+			}
+
+			rendered = append(rendered, pkgtypes.Service{
+				Name:           app.Name,
+				Default:        false,
+				Description:    app.Description,
+				Image:          app.ImageURL,
+				Links:          common.GetIngressLinks(catalogServiceFolder, fullDomainName),
+				Status:         "",
+				CreatedBy:      req.User,
+				CatalogVersion: catalogVersion,
+				ConfigKeys:     entry.ConfigKeys,
+			})
+			result.Status = BulkStatusInstalled
+			waveResults = append(waveResults, result)
+		}
+
+		if len(rendered) > 0 {
+			if err := pushWave(cl, gitopsRepo, rendered, clusterName, req.User); err != nil {
+				log.Error().Msgf("cluster %q - error pushing wave %d: %s", clusterName, waveNumber, err)
+				aborted = "bulk install aborted after an earlier wave could not be pushed"
+				markWaveFailed(waveResults, failed, err)
+				results = append(results, waveResults...)
+				continue
+			}
+		}
+
+		for idx := range rendered {
+			svc := rendered[idx]
+			err := secrets.InsertClusterServiceListEntry(kcfg.Clientset, clusterName, &svc)
+			if err == nil && !excludeArgoSync && ctx.Err() == nil {
+				err = waitForApplicationSync(cl, kcfg, fullDomainName, clusterName, svc.Name)
+			}
+			if err != nil {
+				for r := range waveResults {
+					if waveResults[r].Name == svc.Name {
+						waveResults[r].Status = BulkStatusFailed
+						waveResults[r].Error = err.Error()
+					}
+				}
+				failed[svc.Name] = true
+			}
+		}
+
+		results = append(results, waveResults...)
+		recordBulkInstall(cl.ClusterName, results, BulkInstallRunning, nil)
+	}
+
+	return results, nil
+}
+
+// removeCopiedFiles removes from dst the files copied from src, along with the
+// directories the copy left empty
+func removeCopiedFiles(src, dst string) error {
+	dirs := []string{}
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if rel != "." {
+				dirs = append(dirs, rel)
+			}
+			return nil
+		}
+		if err := os.Remove(filepath.Join(dst, rel)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error removing %q: %w", rel, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// deepest directories first, directories holding other files are kept
+	for i := len(dirs) - 1; i >= 0; i-- {
+		path := filepath.Join(dst, dirs[i])
+		if entries, err := os.ReadDir(path); err == nil && len(entries) == 0 {
+			os.Remove(path)
+		}
+	}
+	return nil
+}
+
+// pushWave commits every service rendered in a wave as a single commit
+func pushWave(cl *pkgtypes.Cluster, gitopsRepo *git.Repository, rendered []pkgtypes.Service, clusterName, user string) error {
+	names := make([]string, 0, len(rendered))
+	for _, svc := range rendered {
+		names = append(names, svc.Name)
+	}
+
+	err := gitClient.Commit(gitopsRepo, fmt.Sprintf("adding %s to the cluster %s on behalf of %s", strings.Join(names, ", "), clusterName, user))
+	if err != nil {
+		return fmt.Errorf("error committing service files: %w", err)
+	}
+
+	err = gitopsRepo.Push(&git.PushOptions{
+		RemoteName: "origin",
+		Auth: &githttps.BasicAuth{
+			Username: cl.GitAuth.User,
+			Password: cl.GitAuth.Token,
+		},
+		CABundle: gitHost.CABundle(cl.GitServer),
+	})
+	if err != nil {
+		return fmt.Errorf("error pushing commit for service files: %w", err)
+	}
+
+	return nil
+}
+
+func firstFailedDependency(app pkgtypes.GitopsCatalogApp, failed map[string]bool) string {
+	for _, dep := range app.DependsOn {
+		if failed[dep] {
+			return dep
+		}
+	}
+	return ""
+}
+
+func markWaveFailed(results []pkgtypes.GitopsCatalogAppBulkCreateResult, failed map[string]bool, err error) {
+	for i := range results {
+		if results[i].Status == BulkStatusInstalled {
+			results[i].Status = BulkStatusFailed
+			results[i].Error = err.Error()
+			failed[results[i].Name] = true
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/konstructio/kubefirst-api/internal/apierror"
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
+)
+
+func TestSortByDependencies(t *testing.T) {
+	tests := []struct {
+		name      string
+		apps      []pkgtypes.GitopsCatalogApp
+		installed []string
+		want      [][]string
+		wantErr   bool
+	}{
+		{
+			name: "independent apps share a wave",
+			apps: []pkgtypes.GitopsCatalogApp{{Name: "a"}, {Name: "b"}},
+			want: [][]string{{"a", "b"}},
+		},
+		{
+			name: "dependencies install first",
+			apps: []pkgtypes.GitopsCatalogApp{
+				{Name: "app", DependsOn: []string{"postgres-operator"}},
+				{Name: "postgres-operator", DependsOn: []string{"cert-manager"}},
+				{Name: "cert-manager"},
+			},
+			want: [][]string{{"cert-manager"}, {"postgres-operator"}, {"app"}},
+		},
+		{
+			name:      "installed dependencies are satisfied",
+			apps:      []pkgtypes.GitopsCatalogApp{{Name: "app", DependsOn: []string{"cert-manager"}}},
+			installed: []string{"cert-manager"},
+			want:      [][]string{{"app"}},
+		},
+		{
+			name:    "missing dependency",
+			apps:    []pkgtypes.GitopsCatalogApp{{Name: "app", DependsOn: []string{"cert-manager"}}},
+			wantErr: true,
+		},
+		{
+			name: "dependency cycle",
+			apps: []pkgtypes.GitopsCatalogApp{
+				{Name: "a", DependsOn: []string{"b"}},
+				{Name: "b", DependsOn: []string{"a"}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			waves, err := SortByDependencies(tt.apps, tt.installed)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got waves %v", waves)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			got := [][]string{}
+			for _, wave := range waves {
+				names := []string{}
+				for _, app := range wave {
+					names = append(names, app.Name)
+				}
+				got = append(got, names)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected waves %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRejectInstalled(t *testing.T) {
+	apps := []pkgtypes.GitopsCatalogApp{{Name: "cert-manager"}, {Name: "app"}}
+
+	if err := rejectInstalled(apps, []string{"argocd"}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	err := rejectInstalled(apps, []string{"cert-manager"})
+	var apiErr *apierror.Error
+	if !errors.As(err, &apiErr) || apiErr.Code != apierror.CodeConflict {
+		t.Fatalf("expected a conflict, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "cert-manager") {
+		t.Errorf("expected the installed app in the error, got %q", err)
+	}
+}
+
+func TestRemoveCopiedFiles(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+
+	for _, path := range []string{"app.yaml", "components/app/values.yaml"} {
+		writeFile(t, filepath.Join(src, path))
+		writeFile(t, filepath.Join(dst, path))
+	}
+	writeFile(t, filepath.Join(dst, "argocd.yaml"))
+	writeFile(t, filepath.Join(dst, "components/argocd/values.yaml"))
+
+	if err := removeCopiedFiles(src, dst); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, path := range []string{"app.yaml", "components/app"} {
+		if _, err := os.Stat(filepath.Join(dst, path)); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed", path)
+		}
+	}
+	for _, path := range []string{"argocd.yaml", "components/argocd/values.yaml"} {
+		if _, err := os.Stat(filepath.Join(dst, path)); err != nil {
+			t.Errorf("expected %s to be kept: %s", path, err)
+		}
+	}
+}
+
+func TestBulkInstallProgress(t *testing.T) {
+	if err := StartBulkInstall("progress"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := StartBulkInstall("progress"); err == nil {
+		t.Fatal("expected an error for a second bulk install while one is running")
+	}
+
+	results := []pkgtypes.GitopsCatalogAppBulkCreateResult{{Name: "app", Wave: 1, Status: BulkStatusInstalled}}
+	recordBulkInstall("progress", results, BulkInstallRunning, nil)
+	recordBulkInstall("progress", results, BulkInstallCompleted, nil)
+	recordBulkInstall("progress", nil, BulkInstallFailed, errors.New("ignored once completed"))
+
+	got, ok := GetBulkInstall("progress")
+	if !ok {
+		t.Fatal("expected the progress of the bulk install")
+	}
+	if got.Status != BulkInstallCompleted || got.Error != "" || !reflect.DeepEqual(got.Results, results) {
+		t.Errorf("unexpected progress %+v", got)
+	}
+
+	if err := StartBulkInstall("progress"); err != nil {
+		t.Errorf("expected a new bulk install once the last one completed, got %s", err)
+	}
+}
+
+func writeFile(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("kind: Application\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -20,7 +20,10 @@ import (
 	"github.com/go-git/go-git/v5"
 	githttps "github.com/go-git/go-git/v5/plumbing/transport/http"
 	vaultapi "github.com/hashicorp/vault/api"
+	"github.com/konstructio/kubefirst-api/internal/apierror"
 	"github.com/konstructio/kubefirst-api/internal/constants"
+	"github.com/konstructio/kubefirst-api/internal/env"
+	"github.com/konstructio/kubefirst-api/internal/gitHost"
 	"github.com/konstructio/kubefirst-api/internal/gitShim"
 	"github.com/konstructio/kubefirst-api/internal/secrets"
 	internalutils "github.com/konstructio/kubefirst-api/internal/utils"
@@ -39,10 +42,10 @@ import (
 )
 
 // CreateService
-func CreateService(cl *pkgtypes.Cluster, serviceName string, appDef *pkgtypes.GitopsCatalogApp, req *pkgtypes.GitopsCatalogAppCreateRequest, excludeArgoSync bool) error {
+func CreateService(e env.Env, cl *pkgtypes.Cluster, serviceName string, appDef *pkgtypes.GitopsCatalogApp, req *pkgtypes.GitopsCatalogAppCreateRequest, excludeArgoSync bool) error {
 	switch cl.Status {
-	case constants.ClusterStatusDeleted, constants.ClusterStatusDeleting, constants.ClusterStatusError, constants.ClusterStatusProvisioning:
-		return fmt.Errorf("cluster %q - unable to deploy service %q to cluster: cannot deploy services to a cluster in %q state", cl.ClusterName, serviceName, cl.Status)
+	case constants.ClusterStatusDeleted, constants.ClusterStatusDeleting, constants.ClusterStatusError, constants.ClusterStatusInterrupted, constants.ClusterStatusProvisioning:
+		return apierror.New(apierror.CodeConflict, "cluster %q - unable to deploy service %q to cluster: cannot deploy services to a cluster in %q state", cl.ClusterName, serviceName, cl.Status)
 	}
 
 	homeDir, _ := os.UserHomeDir()
@@ -75,25 +78,19 @@ func CreateService(cl *pkgtypes.Cluster, serviceName string, appDef *pkgtypes.Gi
 		return fmt.Errorf("cluster %q - error preparing gitops catalog environment %q: %w", cl.ClusterName, tmpGitopsCatalogDir, err)
 	}
 
+	catalogVersion, err := gitShim.GitOpsCatalogRevision(tmpGitopsCatalogDir)
+	if err != nil {
+		return fmt.Errorf("cluster %q - error reading gitops catalog revision: %w", cl.ClusterName, err)
+	}
+
 	gitopsRepo, err := git.PlainOpen(tmpGitopsDir)
 	if err != nil {
 		log.Error().Msgf("error opening gitops repo: %s", err)
 		return fmt.Errorf("cluster %q - error opening gitops repo: %w", cl.ClusterName, err)
 	}
 
-	clusterName := cl.ClusterName
-	secretStoreRef := "vault-kv-secret"
-	project := "default"
-	clusterDestination := "in-cluster"
-	environment := "mgmt"
-
-	if req.WorkloadClusterName != "" {
-		clusterName = req.WorkloadClusterName
-		secretStoreRef = fmt.Sprintf("%s-vault-kv-secret", req.WorkloadClusterName)
-		project = clusterName
-		clusterDestination = clusterName
-		environment = req.Environment
-	}
+	target := newServiceTarget(cl, req.WorkloadClusterName, req.Environment)
+	clusterName := target.clusterName
 
 	registryPath := getRegistryPath(clusterName, cl.CloudProvider, req.IsTemplate)
 
@@ -102,50 +99,17 @@ func CreateService(cl *pkgtypes.Cluster, serviceName string, appDef *pkgtypes.Gi
 
 	kcfg := internalutils.GetKubernetesClient(cl.ClusterName)
 
-	var fullDomainName string
-	if cl.SubdomainName != "" {
-		fullDomainName = fmt.Sprintf("%s.%s", cl.SubdomainName, cl.DomainName)
-	} else {
-		fullDomainName = cl.DomainName
-	}
-
-	vaultURL := fmt.Sprintf("https://vault.%s", fullDomainName)
-
-	if cl.CloudProvider == "k3d" {
-		vaultURL = "http://vault.vault.svc:8200"
-	}
+	fullDomainName := getFullDomainName(cl)
+	vaultURL := getVaultURL(cl, fullDomainName)
 
 	// If there are secret values, create a vault secret
 	if len(req.SecretKeys) > 0 {
 		log.Info().Msgf("cluster %q - application %q has secrets, creating vault values", clusterName, appDef.Name)
 
-		s := make(map[string]interface{}, 0)
-
-		for _, secret := range req.SecretKeys {
-			s[secret.Name] = secret.Value
-		}
-
-		// Get token
-		existingKubernetesSecret, err := k8s.ReadSecretV2(kcfg.Clientset, vault.VaultNamespace, vault.VaultSecretName)
-		if err != nil {
-			return fmt.Errorf("cluster %q - error getting vault token: %w", clusterName, err)
-		}
-
-		vaultClient, err := vaultapi.NewClient(&vaultapi.Config{
-			Address: vaultURL,
-		})
+		err = putServiceSecrets(kcfg, vaultURL, clusterName, appDef.Name, req.SecretKeys)
 		if err != nil {
-			return fmt.Errorf("cluster %q - error initializing vault client: %w", clusterName, err)
+			return err
 		}
-
-		vaultClient.SetToken(existingKubernetesSecret["root-token"])
-
-		resp, err := vaultClient.KVv2("secret").Put(context.Background(), appDef.Name, s)
-		if err != nil {
-			return fmt.Errorf("cluster %q - error putting vault secret: %w", clusterName, err)
-		}
-
-		log.Info().Msgf("cluster %q - created vault secret data for application %q %s", clusterName, appDef.Name, resp.VersionMetadata.CreatedTime)
 	}
 
 	// Create service files in gitops dir
@@ -165,7 +129,7 @@ func CreateService(cl *pkgtypes.Cluster, serviceName string, appDef *pkgtypes.Gi
 
 	if !req.IsTemplate {
 		// Create Tokens
-		gitopsKubefirstTokens := utils.CreateTokensFromDatabaseRecord(cl, registryPath, secretStoreRef, project, clusterDestination, environment, clusterName)
+		gitopsKubefirstTokens := utils.CreateTokensFromDatabaseRecord(cl, registryPath, target.secretStoreRef, target.project, target.clusterDestination, target.environment, clusterName, e.KubefirstVersion)
 
 		// Detokenize App Template
 		err = providerConfigs.DetokenizeGitGitops(catalogServiceFolder, gitopsKubefirstTokens, cl.GitProtocol, cl.CloudflareAuth.OriginCaIssuerKey != "")
@@ -200,7 +164,8 @@ func CreateService(cl *pkgtypes.Cluster, serviceName string, appDef *pkgtypes.Gi
 			Username: cl.GitAuth.User,
 			Password: cl.GitAuth.Token,
 		},
-		Force: true,
+		CABundle: gitHost.CABundle(cl.GitServer),
+		Force:    true,
 	})
 	if err != nil {
 		return fmt.Errorf("cluster %q - error pushing commit for service file: %w", clusterName, err)
@@ -221,13 +186,15 @@ func CreateService(cl *pkgtypes.Cluster, serviceName string, appDef *pkgtypes.Gi
 
 	// Update list
 	err = secrets.InsertClusterServiceListEntry(kcfg.Clientset, clusterName, &pkgtypes.Service{
-		Name:        serviceName,
-		Default:     false,
-		Description: appDef.Description,
-		Image:       appDef.ImageURL,
-		Links:       links,
-		Status:      "",
-		CreatedBy:   req.User,
+		Name:           serviceName,
+		Default:        false,
+		Description:    appDef.Description,
+		Image:          appDef.ImageURL,
+		Links:          links,
+		Status:         "",
+		CreatedBy:      req.User,
+		CatalogVersion: catalogVersion,
+		ConfigKeys:     req.ConfigKeys,
 	})
 	if err != nil {
 		return fmt.Errorf("cluster %q - error inserting service list entry: %w", clusterName, err)
@@ -237,6 +204,180 @@ func CreateService(cl *pkgtypes.Cluster, serviceName string, appDef *pkgtypes.Gi
 		return nil
 	}
 
+	return waitForApplicationSync(cl, kcfg, fullDomainName, clusterName, serviceName)
+}
+
+// UpdateService rewrites the config and secret values of a service that has
+// already been installed, re-rendering its manifests from the gitops catalog
+// revision recorded when the service was created
+func UpdateService(e env.Env, cl *pkgtypes.Cluster, serviceName string, appDef *pkgtypes.GitopsCatalogApp, req *pkgtypes.GitopsCatalogAppUpdateRequest) error {
+	switch cl.Status {
+	case constants.ClusterStatusDeleted, constants.ClusterStatusDeleting, constants.ClusterStatusError, constants.ClusterStatusInterrupted, constants.ClusterStatusProvisioning:
+		return apierror.New(apierror.CodeConflict, "cluster %q - unable to update service %q: cannot update services on a cluster in %q state", cl.ClusterName, serviceName, cl.Status)
+	}
+
+	target := newServiceTarget(cl, req.WorkloadClusterName, req.Environment)
+	clusterName := target.clusterName
+
+	kcfg := internalutils.GetKubernetesClient(cl.ClusterName)
+
+	svc, err := secrets.GetService(kcfg.Clientset, clusterName, serviceName)
+	if err != nil {
+		return fmt.Errorf("cluster %q - error finding service: %w", clusterName, err)
+	}
+
+	// Config keys left out of the request keep the values the service was rendered with
+	configKeys := mergeConfigKeys(svc.ConfigKeys, req.ConfigKeys)
+	if !req.IsTemplate {
+		if err := requireConfigKeys(appDef, configKeys); err != nil {
+			return apierror.New(apierror.CodeInvalidRequest, "cluster %q - unable to update service %q: %s", clusterName, serviceName, err)
+		}
+	}
+
+	homeDir, _ := os.UserHomeDir()
+	tmpGitopsDir := fmt.Sprintf("%s/.k1/%s/%s/gitops", homeDir, cl.ClusterName, serviceName)
+	tmpGitopsCatalogDir := fmt.Sprintf("%s/.k1/%s/%s/gitops-catalog", homeDir, cl.ClusterName, serviceName)
+
+	if err := os.RemoveAll(tmpGitopsDir); err != nil {
+		log.Error().Msgf("error removing gitops dir %s: %s", tmpGitopsDir, err)
+		return fmt.Errorf("cluster %q - error removing gitops dir %q: %w", cl.ClusterName, tmpGitopsDir, err)
+	}
+
+	if err := os.RemoveAll(tmpGitopsCatalogDir); err != nil {
+		log.Error().Msgf("error removing gitops dir %s: %s", tmpGitopsCatalogDir, err)
+		return fmt.Errorf("cluster %q - error removing gitops dir %q: %w", cl.ClusterName, tmpGitopsCatalogDir, err)
+	}
+
+	err = gitShim.PrepareGitEnvironment(cl, tmpGitopsDir)
+	if err != nil {
+		log.Error().Msgf("an error occurred preparing git environment %s %s", tmpGitopsDir, err)
+		return fmt.Errorf("cluster %q - error preparing git environment %q: %w", cl.ClusterName, tmpGitopsDir, err)
+	}
+
+	err = gitShim.PrepareGitOpsCatalogAtRevision(tmpGitopsCatalogDir, svc.CatalogVersion)
+	if err != nil {
+		log.Error().Msgf("an error occurred preparing gitops catalog environment %s %s", tmpGitopsCatalogDir, err)
+		return fmt.Errorf("cluster %q - error preparing gitops catalog environment %q: %w", cl.ClusterName, tmpGitopsCatalogDir, err)
+	}
+
+	gitopsRepo, err := git.PlainOpen(tmpGitopsDir)
+	if err != nil {
+		log.Error().Msgf("error opening gitops repo: %s", err)
+		return fmt.Errorf("cluster %q - error opening gitops repo: %w", cl.ClusterName, err)
+	}
+
+	fullDomainName := getFullDomainName(cl)
+	vaultURL := getVaultURL(cl, fullDomainName)
+
+	// Rewrite the secret at the path created by CreateService
+	if len(req.SecretKeys) > 0 {
+		log.Info().Msgf("cluster %q - updating vault values for application %q", clusterName, appDef.Name)
+
+		err = putServiceSecrets(kcfg, vaultURL, clusterName, appDef.Name, req.SecretKeys)
+		if err != nil {
+			return err
+		}
+	}
+
+	registryPath := getRegistryPath(clusterName, cl.CloudProvider, req.IsTemplate)
+	clusterRegistryPath := fmt.Sprintf("%s/%s", tmpGitopsDir, registryPath)
+	catalogServiceFolder := fmt.Sprintf("%s/%s", tmpGitopsCatalogDir, serviceName)
+	componentsServiceFolder := fmt.Sprintf("%s/components/%s", clusterRegistryPath, serviceName)
+
+	if !req.IsTemplate {
+		gitopsKubefirstTokens := utils.CreateTokensFromDatabaseRecord(cl, registryPath, target.secretStoreRef, target.project, target.clusterDestination, target.environment, clusterName, e.KubefirstVersion)
+
+		err = providerConfigs.DetokenizeGitGitops(catalogServiceFolder, gitopsKubefirstTokens, cl.GitProtocol, cl.CloudflareAuth.OriginCaIssuerKey != "")
+		if err != nil {
+			return fmt.Errorf("cluster %q - error opening file: %w", clusterName, err)
+		}
+
+		err = DetokenizeConfigKeys(catalogServiceFolder, configKeys)
+		if err != nil {
+			return fmt.Errorf("cluster %q - error opening file: %w", clusterName, err)
+		}
+	}
+
+	// Replace the rendered components so files dropped by the new values do not linger
+	if err := os.RemoveAll(componentsServiceFolder); err != nil {
+		return fmt.Errorf("cluster %q - error removing components folder: %w", clusterName, err)
+	}
+
+	err = cp.Copy(catalogServiceFolder, clusterRegistryPath, cp.Options{})
+	if err != nil {
+		log.Error().Msgf("Error populating gitops repository with catalog components content: %q. error: %s", serviceName, err.Error())
+		return fmt.Errorf("cluster %q - error copying catalog components content: %w", clusterName, err)
+	}
+
+	w, err := gitopsRepo.Worktree()
+	if err != nil {
+		return fmt.Errorf("cluster %q - error getting gitops worktree: %w", clusterName, err)
+	}
+
+	status, err := w.Status()
+	if err != nil {
+		return fmt.Errorf("cluster %q - error getting gitops worktree status: %w", clusterName, err)
+	}
+
+	// Secret-only changes render the recorded config keys again, so the
+	// manifests are unchanged and there is nothing to push
+	if !status.IsClean() {
+		err = gitClient.Commit(gitopsRepo, fmt.Sprintf("updating %s on the cluster %s on behalf of %s", serviceName, clusterName, req.User))
+		if err != nil {
+			return fmt.Errorf("cluster %q - error committing service file: %w", clusterName, err)
+		}
+
+		err = gitopsRepo.Push(&git.PushOptions{
+			RemoteName: "origin",
+			Auth: &githttps.BasicAuth{
+				Username: cl.GitAuth.User,
+				Password: cl.GitAuth.Token,
+			},
+			CABundle: gitHost.CABundle(cl.GitServer),
+		})
+		if err != nil {
+			return fmt.Errorf("cluster %q - error pushing commit for service file: %w", clusterName, err)
+		}
+	} else {
+		log.Info().Msgf("cluster %q - no manifest changes for service %q", clusterName, serviceName)
+	}
+
+	svc.Links = common.GetIngressLinks(catalogServiceFolder, fullDomainName)
+	svc.ConfigKeys = configKeys
+	svc.UpdatedBy = append(svc.UpdatedBy, pkgtypes.ServiceUpdate{
+		User:      req.User,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	})
+
+	err = secrets.UpdateClusterServiceListEntry(kcfg.Clientset, clusterName, &svc)
+	if err != nil {
+		return fmt.Errorf("cluster %q - error updating service list entry: %w", clusterName, err)
+	}
+
+	return nil
+}
+
+// requireConfigKeys verifies every config key of a gitops catalog app has a
+// value, services installed before their config keys were recorded have to be
+// updated with the full set
+func requireConfigKeys(appDef *pkgtypes.GitopsCatalogApp, configKeys []pkgtypes.GitopsCatalogAppKeys) error {
+	values := map[string]string{}
+	for _, key := range configKeys {
+		values[key.Name] = key.Value
+	}
+
+	for _, key := range appDef.ConfigKeys {
+		if values[key.Name] == "" {
+			return fmt.Errorf("config key %s has no recorded value and must be provided", key.Name)
+		}
+	}
+
+	return nil
+}
+
+// waitForApplicationSync refreshes the argocd registry application and blocks until
+// the service's application exists and is synchronized and healthy
+func waitForApplicationSync(cl *pkgtypes.Cluster, kcfg *k8s.KubernetesClient, fullDomainName, clusterName, serviceName string) error {
 	// Wait for ArgoCD application sync
 	argocdClient, err := argocdapi.NewForConfig(kcfg.RestConfig)
 	if err != nil {
@@ -294,6 +435,39 @@ func CreateService(cl *pkgtypes.Cluster, serviceName string, appDef *pkgtypes.Gi
 	return nil
 }
 
+// putServiceSecrets writes an application's secret keys to the vault kv store
+func putServiceSecrets(kcfg *k8s.KubernetesClient, vaultURL, clusterName, appName string, secretKeys []pkgtypes.GitopsCatalogAppKeys) error {
+	s := make(map[string]interface{}, 0)
+
+	for _, secret := range secretKeys {
+		s[secret.Name] = secret.Value
+	}
+
+	// Get token
+	vaultToken, err := vault.Conf.APIToken(vaultURL, kcfg.Clientset)
+	if err != nil {
+		return fmt.Errorf("cluster %q - error getting vault token: %w", clusterName, err)
+	}
+
+	vaultClient, err := vaultapi.NewClient(&vaultapi.Config{
+		Address: vaultURL,
+	})
+	if err != nil {
+		return fmt.Errorf("cluster %q - error initializing vault client: %w", clusterName, err)
+	}
+
+	vaultClient.SetToken(vaultToken)
+
+	resp, err := vaultClient.KVv2("secret").Put(context.Background(), appName, s)
+	if err != nil {
+		return fmt.Errorf("cluster %q - error putting vault secret: %w", clusterName, err)
+	}
+
+	log.Info().Msgf("cluster %q - wrote vault secret data for application %q %s", clusterName, appName, resp.VersionMetadata.CreatedTime)
+
+	return nil
+}
+
 // DeleteService
 func DeleteService(cl *pkgtypes.Cluster, serviceName string, def pkgtypes.GitopsCatalogAppDeleteRequest) error {
 	var gitopsRepo *git.Repository
@@ -383,6 +557,7 @@ func DeleteService(cl *pkgtypes.Cluster, serviceName string, def pkgtypes.Gitops
 				Username: cl.GitAuth.User,
 				Password: cl.GitAuth.Token,
 			},
+			CABundle: gitHost.CABundle(cl.GitServer),
 		})
 		if err != nil {
 			return fmt.Errorf("cluster %q - error pushing commit for service file: %w", clusterName, err)
@@ -589,3 +764,50 @@ func getRegistryPath(clusterName, cloudProvider string, isTemplate bool) string
 
 	return filepath.Join("registry", "clusters", clusterName)
 }
+
+// serviceTarget describes the cluster and argocd destination a service is rendered for
+type serviceTarget struct {
+	clusterName        string
+	secretStoreRef     string
+	project            string
+	clusterDestination string
+	environment        string
+}
+
+// newServiceTarget returns the render target for the management cluster, or for
+// the named workload cluster and environment when one is provided
+func newServiceTarget(cl *pkgtypes.Cluster, workloadClusterName, environment string) serviceTarget {
+	if workloadClusterName == "" {
+		return serviceTarget{
+			clusterName:        cl.ClusterName,
+			secretStoreRef:     "vault-kv-secret",
+			project:            "default",
+			clusterDestination: "in-cluster",
+			environment:        "mgmt",
+		}
+	}
+
+	return serviceTarget{
+		clusterName:        workloadClusterName,
+		secretStoreRef:     fmt.Sprintf("%s-vault-kv-secret", workloadClusterName),
+		project:            workloadClusterName,
+		clusterDestination: workloadClusterName,
+		environment:        environment,
+	}
+}
+
+func getFullDomainName(cl *pkgtypes.Cluster) string {
+	if cl.SubdomainName != "" {
+		return fmt.Sprintf("%s.%s", cl.SubdomainName, cl.DomainName)
+	}
+
+	return cl.DomainName
+}
+
+func getVaultURL(cl *pkgtypes.Cluster, fullDomainName string) string {
+	if cl.CloudProvider == "k3d" {
+		return "http://vault.vault.svc:8200"
+	}
+
+	return fmt.Sprintf("https://vault.%s", fullDomainName)
+}
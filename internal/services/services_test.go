@@ -0,0 +1,58 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/konstructio/kubefirst-api/internal/apierror"
+	"github.com/konstructio/kubefirst-api/internal/constants"
+	"github.com/konstructio/kubefirst-api/internal/env"
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
+)
+
+func TestRequireConfigKeys(t *testing.T) {
+	appDef := &pkgtypes.GitopsCatalogApp{
+		Name:       "metaphor",
+		ConfigKeys: []pkgtypes.GitopsCatalogAppKeys{{Name: "REPLICAS"}, {Name: "LOG_LEVEL"}},
+	}
+	recorded := []pkgtypes.GitopsCatalogAppKeys{
+		{Name: "REPLICAS", Value: "1"},
+		{Name: "LOG_LEVEL", Value: "info"},
+	}
+
+	tests := []struct {
+		name      string
+		recorded  []pkgtypes.GitopsCatalogAppKeys
+		requested []pkgtypes.GitopsCatalogAppKeys
+		wantErr   bool
+	}{
+		{name: "secret only update keeps the recorded values", recorded: recorded},
+		{name: "partial update", recorded: recorded, requested: []pkgtypes.GitopsCatalogAppKeys{{Name: "REPLICAS", Value: "3"}}},
+		{name: "service without recorded values", requested: []pkgtypes.GitopsCatalogAppKeys{{Name: "REPLICAS", Value: "3"}}, wantErr: true},
+		{name: "full set without recorded values", requested: recorded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := requireConfigKeys(appDef, mergeConfigKeys(tt.recorded, tt.requested))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("requireConfigKeys() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUpdateServiceClusterState(t *testing.T) {
+	for _, status := range []string{constants.ClusterStatusDeleting, constants.ClusterStatusError, constants.ClusterStatusProvisioning} {
+		t.Run(status, func(t *testing.T) {
+			cl := &pkgtypes.Cluster{ClusterName: "test", Status: status}
+
+			err := UpdateService(env.Env{}, cl, "metaphor", &pkgtypes.GitopsCatalogApp{Name: "metaphor"}, &pkgtypes.GitopsCatalogAppUpdateRequest{})
+
+			var apiErr *apierror.Error
+			if !errors.As(err, &apiErr) || apiErr.Code != apierror.CodeConflict {
+				t.Errorf("expected a conflict error, got %v", err)
+			}
+		})
+	}
+}
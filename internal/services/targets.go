@@ -0,0 +1,266 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package services
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/go-git/go-git/v5"
+	githttps "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/konstructio/kubefirst-api/internal/apierror"
+	"github.com/konstructio/kubefirst-api/internal/constants"
+	"github.com/konstructio/kubefirst-api/internal/env"
+	"github.com/konstructio/kubefirst-api/internal/gitClient"
+	"github.com/konstructio/kubefirst-api/internal/gitHost"
+	"github.com/konstructio/kubefirst-api/internal/gitShim"
+	"github.com/konstructio/kubefirst-api/internal/secrets"
+	internalutils "github.com/konstructio/kubefirst-api/internal/utils"
+	"github.com/konstructio/kubefirst-api/pkg/common"
+	"github.com/konstructio/kubefirst-api/pkg/providerConfigs"
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
+	utils "github.com/konstructio/kubefirst-api/pkg/utils"
+	cp "github.com/otiai10/copy"
+	log "github.com/rs/zerolog/log"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes"
+)
+
+// CreateServiceForTargets installs a gitops catalog app on several clusters at once.
+// Every target gets its own registry path and config overrides, and all targets
+// that render successfully are pushed to the gitops repository in a single commit.
+// Secret keys are shared by all targets since they are stored once in vault.
+func CreateServiceForTargets(e env.Env, cl *pkgtypes.Cluster, serviceName string, appDef *pkgtypes.GitopsCatalogApp, req *pkgtypes.GitopsCatalogAppMultiTargetCreateRequest) ([]pkgtypes.GitopsCatalogAppTargetResult, error) {
+	switch cl.Status {
+	case constants.ClusterStatusDeleted, constants.ClusterStatusDeleting, constants.ClusterStatusError, constants.ClusterStatusInterrupted, constants.ClusterStatusProvisioning:
+		return nil, apierror.New(apierror.CodeConflict, "cluster %q - unable to deploy service %q to cluster: cannot deploy services to a cluster in %q state", cl.ClusterName, serviceName, cl.Status)
+	}
+
+	seen := map[string]bool{}
+	for _, t := range req.Targets {
+		if seen[t.WorkloadClusterName] {
+			return nil, fmt.Errorf("cluster %q - target %q is listed more than once", cl.ClusterName, t.WorkloadClusterName)
+		}
+		seen[t.WorkloadClusterName] = true
+	}
+
+	homeDir, _ := os.UserHomeDir()
+	tmpDir := fmt.Sprintf("%s/.k1/%s/%s/targets", homeDir, cl.ClusterName, serviceName)
+	tmpGitopsDir := fmt.Sprintf("%s/gitops", tmpDir)
+	tmpGitopsCatalogDir := fmt.Sprintf("%s/gitops-catalog", tmpDir)
+
+	if err := os.RemoveAll(tmpDir); err != nil {
+		log.Error().Msgf("error removing gitops dir %s: %s", tmpDir, err)
+		return nil, fmt.Errorf("cluster %q - error removing gitops dir %q: %w", cl.ClusterName, tmpDir, err)
+	}
+
+	err := gitShim.PrepareGitEnvironment(cl, tmpGitopsDir)
+	if err != nil {
+		log.Error().Msgf("an error occurred preparing git environment %s %s", tmpGitopsDir, err)
+		return nil, fmt.Errorf("cluster %q - error preparing git environment %q: %w", cl.ClusterName, tmpGitopsDir, err)
+	}
+
+	err = gitShim.PrepareGitOpsCatalog(tmpGitopsCatalogDir)
+	if err != nil {
+		log.Error().Msgf("an error occurred preparing gitops catalog environment %s %s", tmpGitopsCatalogDir, err)
+		return nil, fmt.Errorf("cluster %q - error preparing gitops catalog environment %q: %w", cl.ClusterName, tmpGitopsCatalogDir, err)
+	}
+
+	catalogVersion, err := gitShim.GitOpsCatalogRevision(tmpGitopsCatalogDir)
+	if err != nil {
+		return nil, fmt.Errorf("cluster %q - error reading gitops catalog revision: %w", cl.ClusterName, err)
+	}
+
+	gitopsRepo, err := git.PlainOpen(tmpGitopsDir)
+	if err != nil {
+		log.Error().Msgf("error opening gitops repo: %s", err)
+		return nil, fmt.Errorf("cluster %q - error opening gitops repo: %w", cl.ClusterName, err)
+	}
+
+	kcfg := internalutils.GetKubernetesClient(cl.ClusterName)
+	fullDomainName := getFullDomainName(cl)
+
+	if len(req.SecretKeys) > 0 {
+		log.Info().Msgf("cluster %q - application %q has secrets, creating vault values", cl.ClusterName, appDef.Name)
+
+		err = putServiceSecrets(kcfg, getVaultURL(cl, fullDomainName), cl.ClusterName, appDef.Name, req.SecretKeys)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	catalogServiceFolder := fmt.Sprintf("%s/%s", tmpGitopsCatalogDir, serviceName)
+	results := make([]pkgtypes.GitopsCatalogAppTargetResult, len(req.Targets))
+	links := make([][]string, len(req.Targets))
+	configKeys := make([][]pkgtypes.GitopsCatalogAppKeys, len(req.Targets))
+	rendered := 0
+
+	for i, t := range req.Targets {
+		environment := t.Environment
+		if t.WorkloadClusterName != "" && environment == "" {
+			environment = workloadClusterEnvironment(cl, t.WorkloadClusterName)
+		}
+
+		target := newServiceTarget(cl, t.WorkloadClusterName, environment)
+		registryPath := getRegistryPath(target.clusterName, cl.CloudProvider, false)
+
+		results[i] = pkgtypes.GitopsCatalogAppTargetResult{
+			ClusterName:  target.clusterName,
+			Environment:  target.environment,
+			RegistryPath: registryPath,
+		}
+
+		if t.WorkloadClusterName != "" && !hasWorkloadCluster(cl, t.WorkloadClusterName) {
+			results[i].Status = BulkStatusFailed
+			results[i].Error = fmt.Sprintf("workload cluster %q does not belong to cluster %q", t.WorkloadClusterName, cl.ClusterName)
+			continue
+		}
+
+		installed, err := isServiceInstalled(kcfg.Clientset, target.clusterName, serviceName)
+		if err == nil && installed {
+			err = fmt.Errorf("service %q is already installed", serviceName)
+		}
+		if err != nil {
+			results[i].Status = BulkStatusFailed
+			results[i].Error = err.Error()
+			continue
+		}
+
+		// Render each target from a pristine copy of the catalog app
+		targetServiceFolder := fmt.Sprintf("%s/render/%s/%s", tmpDir, target.clusterName, serviceName)
+		gitopsKubefirstTokens := utils.CreateTokensFromDatabaseRecord(cl, registryPath, target.secretStoreRef, target.project, target.clusterDestination, target.environment, target.clusterName, e.KubefirstVersion)
+
+		configKeys[i] = mergeConfigKeys(req.ConfigKeys, t.ConfigKeys)
+
+		err = cp.Copy(catalogServiceFolder, targetServiceFolder, cp.Options{})
+		if err == nil {
+			err = providerConfigs.DetokenizeGitGitops(targetServiceFolder, gitopsKubefirstTokens, cl.GitProtocol, cl.CloudflareAuth.OriginCaIssuerKey != "")
+		}
+		if err == nil {
+			err = DetokenizeConfigKeys(targetServiceFolder, configKeys[i])
+		}
+		if err == nil {
+			err = cp.Copy(targetServiceFolder, fmt.Sprintf("%s/%s", tmpGitopsDir, registryPath), cp.Options{})
+		}
+		if err != nil {
+			results[i].Status = BulkStatusFailed
+			results[i].Error = fmt.Sprintf("error rendering catalog components: %s", err)
+			continue
+		}
+
+		links[i] = common.GetIngressLinks(targetServiceFolder, fullDomainName)
+		results[i].Status = BulkStatusInstalled
+		rendered++
+	}
+
+	if rendered == 0 {
+		return results, nil
+	}
+
+	err = gitClient.Commit(gitopsRepo, fmt.Sprintf("adding %s to %d clusters on behalf of %s", serviceName, rendered, req.User))
+	if err != nil {
+		return nil, fmt.Errorf("cluster %q - error committing service files: %w", cl.ClusterName, err)
+	}
+
+	err = gitopsRepo.Push(&git.PushOptions{
+		RemoteName: "origin",
+		Auth: &githttps.BasicAuth{
+			Username: cl.GitAuth.User,
+			Password: cl.GitAuth.Token,
+		},
+		CABundle: gitHost.CABundle(cl.GitServer),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cluster %q - error pushing commit for service files: %w", cl.ClusterName, err)
+	}
+
+	for i := range results {
+		if results[i].Status != BulkStatusInstalled {
+			continue
+		}
+
+		err := secrets.CreateClusterServiceList(kcfg.Clientset, results[i].ClusterName)
+		if err == nil {
+			err = secrets.InsertClusterServiceListEntry(kcfg.Clientset, results[i].ClusterName, &pkgtypes.Service{
+				Name:           serviceName,
+				Default:        false,
+				Description:    appDef.Description,
+				Image:          appDef.ImageURL,
+				Links:          links[i],
+				Status:         "",
+				CreatedBy:      req.User,
+				CatalogVersion: catalogVersion,
+				ConfigKeys:     configKeys[i],
+			})
+		}
+		if err != nil {
+			results[i].Status = BulkStatusFailed
+			results[i].Error = fmt.Sprintf("manifests were committed but the service list could not be updated: %s", err)
+		}
+	}
+
+	return results, nil
+}
+
+// mergeConfigKeys returns the base config keys with any override of the same name applied
+func mergeConfigKeys(base, overrides []pkgtypes.GitopsCatalogAppKeys) []pkgtypes.GitopsCatalogAppKeys {
+	merged := make([]pkgtypes.GitopsCatalogAppKeys, 0, len(base)+len(overrides))
+	index := map[string]int{}
+
+	for _, key := range base {
+		index[key.Name] = len(merged)
+		merged = append(merged, key)
+	}
+
+	for _, key := range overrides {
+		if i, ok := index[key.Name]; ok {
+			merged[i] = key
+			continue
+		}
+		index[key.Name] = len(merged)
+		merged = append(merged, key)
+	}
+
+	return merged
+}
+
+// isServiceInstalled reports whether a service is in the service list of a
+// cluster, clusters without a service list have no services
+func isServiceInstalled(clientset kubernetes.Interface, clusterName, serviceName string) (bool, error) {
+	list, err := secrets.GetServices(clientset, clusterName)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("error reading the services of cluster %q: %w", clusterName, err)
+	}
+
+	for _, svc := range list.Services {
+		if svc.Name == serviceName {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func hasWorkloadCluster(cl *pkgtypes.Cluster, workloadClusterName string) bool {
+	for _, wc := range cl.WorkloadClusters {
+		if wc.ClusterName == workloadClusterName {
+			return true
+		}
+	}
+	return false
+}
+
+func workloadClusterEnvironment(cl *pkgtypes.Cluster, workloadClusterName string) string {
+	for _, wc := range cl.WorkloadClusters {
+		if wc.ClusterName == workloadClusterName {
+			return wc.Environment.Name
+		}
+	}
+	return ""
+}
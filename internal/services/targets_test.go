@@ -0,0 +1,62 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/konstructio/kubefirst-api/internal/secrets"
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestMergeConfigKeys(t *testing.T) {
+	base := []pkgtypes.GitopsCatalogAppKeys{
+		{Name: "REPLICAS", Value: "1"},
+		{Name: "LOG_LEVEL", Value: "info"},
+	}
+	overrides := []pkgtypes.GitopsCatalogAppKeys{
+		{Name: "REPLICAS", Value: "3"},
+		{Name: "INGRESS_CLASS", Value: "nginx"},
+	}
+
+	want := []pkgtypes.GitopsCatalogAppKeys{
+		{Name: "REPLICAS", Value: "3"},
+		{Name: "LOG_LEVEL", Value: "info"},
+		{Name: "INGRESS_CLASS", Value: "nginx"},
+	}
+
+	got := mergeConfigKeys(base, overrides)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	if base[0].Value != "1" {
+		t.Errorf("expected base keys to be left untouched, got %v", base)
+	}
+}
+
+func TestIsServiceInstalled(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+
+	installed, err := isServiceInstalled(clientset, "dev", "metaphor")
+	if err != nil || installed {
+		t.Fatalf("expected a cluster without a service list to have no services, got %v, %v", installed, err)
+	}
+
+	if err := secrets.CreateClusterServiceList(clientset, "dev"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := secrets.InsertClusterServiceListEntry(clientset, "dev", &pkgtypes.Service{Name: "metaphor"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	installed, err = isServiceInstalled(clientset, "dev", "metaphor")
+	if err != nil || !installed {
+		t.Errorf("expected metaphor to be installed, got %v, %v", installed, err)
+	}
+
+	installed, err = isServiceInstalled(clientset, "dev", "cert-manager")
+	if err != nil || installed {
+		t.Errorf("expected cert-manager not to be installed, got %v, %v", installed, err)
+	}
+}
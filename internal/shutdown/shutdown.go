@@ -0,0 +1,144 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+)
+
+// ErrInterrupted is returned by the steps of an operation that were not started
+// because the API is shutting down
+var ErrInterrupted = errors.New("interrupted by API shutdown")
+
+// Operations besides cluster create and delete, which are tracked under the
+// metrics operation names. Retrying them does not resume where they stopped.
+const (
+	OperationStateStoreMigration = "state store migration"
+	OperationServiceInstall      = "service install"
+	OperationCredentialRotation  = "credential rotation"
+	OperationVclusterCreate      = "virtual cluster create"
+)
+
+// Operation is a cluster operation running in the background
+type Operation struct {
+	Kind        string
+	ClusterName string
+}
+
+var (
+	mu       sync.Mutex
+	draining bool
+	nextID   int
+	running  = map[int]Operation{}
+	idle     = make(chan struct{})
+
+	// base is canceled once the API stops waiting for running operations
+	base, cancelBase = context.WithCancel(context.Background())
+)
+
+func init() {
+	close(idle)
+}
+
+// Begin stops the API from accepting new operations, running operations stop
+// at their next step boundary
+func Begin() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	draining = true
+}
+
+// Draining reports whether the API is shutting down
+func Draining() bool {
+	mu.Lock()
+	defer mu.Unlock()
+
+	return draining
+}
+
+// Cancel cancels the contexts returned by Context, operations still running
+// after the drain timeout are expected to return promptly
+func Cancel() {
+	cancelBase()
+}
+
+// Context returns a context carrying the values of parent, such as the trace
+// of the request starting an operation, that outlives the request and is
+// canceled by Cancel
+func Context(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(parent))
+	stop := context.AfterFunc(base, cancel)
+
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// Track registers a running operation, the returned function marks it finished
+func Track(kind, clusterName string) func() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(running) == 0 {
+		idle = make(chan struct{})
+	}
+	id := nextID
+	nextID++
+	running[id] = Operation{Kind: kind, ClusterName: clusterName}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			mu.Lock()
+			defer mu.Unlock()
+
+			delete(running, id)
+			if len(running) == 0 {
+				close(idle)
+			}
+		})
+	}
+}
+
+// Wait waits for the running operations to finish or for ctx to be done, it
+// returns the operations still running
+func Wait(ctx context.Context) []Operation {
+	mu.Lock()
+	done := idle
+	mu.Unlock()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+
+	return Running()
+}
+
+// Running returns the running operations
+func Running() []Operation {
+	mu.Lock()
+	defer mu.Unlock()
+
+	ops := make([]Operation, 0, len(running))
+	for _, op := range running {
+		ops = append(ops, op)
+	}
+	sort.Slice(ops, func(i, j int) bool {
+		if ops[i].ClusterName != ops[j].ClusterName {
+			return ops[i].ClusterName < ops[j].ClusterName
+		}
+		return ops[i].Kind < ops[j].Kind
+	})
+
+	return ops
+}
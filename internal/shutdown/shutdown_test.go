@@ -0,0 +1,79 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package shutdown
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWait(t *testing.T) {
+	create := Track("create", "mgmt")
+	deleted := Track("delete", "dev")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	remaining := Wait(ctx)
+	if len(remaining) != 2 || remaining[0].ClusterName != "dev" || remaining[1].ClusterName != "mgmt" {
+		t.Fatalf("expected the dev and mgmt operations to be running, got %v", remaining)
+	}
+
+	deleted()
+	deleted()
+	go create()
+
+	remaining = Wait(context.Background())
+	if len(remaining) != 0 {
+		t.Errorf("expected no running operations, got %v", remaining)
+	}
+}
+
+func TestBegin(t *testing.T) {
+	if Draining() {
+		t.Fatal("expected the API not to be draining before Begin")
+	}
+
+	Begin()
+	t.Cleanup(func() {
+		mu.Lock()
+		draining = false
+		mu.Unlock()
+	})
+
+	if !Draining() {
+		t.Error("expected the API to be draining after Begin")
+	}
+}
+
+func TestContext(t *testing.T) {
+	type key struct{}
+	parent, cancelParent := context.WithCancel(context.WithValue(context.Background(), key{}, "trace"))
+
+	ctx, cancel := Context(parent)
+	defer cancel()
+
+	cancelParent()
+	if ctx.Err() != nil {
+		t.Fatal("expected the operation context to outlive its request")
+	}
+	if ctx.Value(key{}) != "trace" {
+		t.Error("expected the operation context to keep the request values")
+	}
+
+	Cancel()
+	t.Cleanup(func() {
+		base, cancelBase = context.WithCancel(context.Background())
+	})
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected Cancel to cancel the operation context")
+	}
+}
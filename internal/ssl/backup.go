@@ -0,0 +1,324 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package ssl
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/konstructio/kubefirst-api/internal/constants"
+	"github.com/konstructio/kubefirst-api/internal/downloadManager"
+	"github.com/konstructio/kubefirst-api/internal/k8s"
+	"github.com/konstructio/kubefirst-api/internal/objectStorage"
+	"github.com/konstructio/kubefirst-api/internal/secrets"
+	"github.com/konstructio/kubefirst-api/internal/utils"
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
+	"github.com/rs/zerolog/log"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+// tlsBackupRetention is the number of backups kept per cluster
+const tlsBackupRetention = 14
+
+var (
+	certificateGVR   = schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"}
+	clusterIssuerGVR = schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "clusterissuers"}
+)
+
+// TLSBackupPrefix returns the state store prefix holding the tls backups of a cluster
+func TLSBackupPrefix(clusterName string) string {
+	return fmt.Sprintf("tls-backups/%s/", clusterName)
+}
+
+// WriteBackupArchive writes a gzipped tarball of the tls secrets, cert-manager
+// Certificates and ClusterIssuers of a cluster to w and returns the number of
+// resources written. Secrets are laid out as expected by Restore.
+func WriteBackupArchive(clientset kubernetes.Interface, dyn dynamic.Interface, w io.Writer) (int, error) {
+	ctx := context.Background()
+
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	count := 0
+
+	add := func(name string, obj interface{}) error {
+		content, err := yaml.Marshal(obj)
+		if err != nil {
+			return fmt.Errorf("unable to marshal yaml for %q: %w", name, err)
+		}
+		err = tw.WriteHeader(&tar.Header{
+			Name:    name,
+			Mode:    0o600,
+			Size:    int64(len(content)),
+			ModTime: time.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf("error writing header for %q: %w", name, err)
+		}
+		if _, err := tw.Write(content); err != nil {
+			return fmt.Errorf("error writing %q: %w", name, err)
+		}
+		count++
+		return nil
+	}
+
+	tlsSecrets, err := listTLSSecrets(clientset)
+	if err != nil {
+		return 0, err
+	}
+	for _, secret := range tlsSecrets {
+		prepareSecretForRestore(&secret)
+		if err := add(fmt.Sprintf("secrets/%s-%s.yaml", secret.Namespace, secret.Name), secret); err != nil {
+			return 0, err
+		}
+	}
+
+	// cert-manager resources are archived for reference, they are recreated by
+	// argocd from the gitops repository on restore
+	for _, res := range []struct {
+		dir string
+		gvr schema.GroupVersionResource
+	}{
+		{dir: "certificates", gvr: certificateGVR},
+		{dir: "clusterissuers", gvr: clusterIssuerGVR},
+	} {
+		list, err := dyn.Resource(res.gvr).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				log.Info().Msgf("%s are not available in the cluster, skipping", res.gvr.Resource)
+				continue
+			}
+			return 0, fmt.Errorf("error listing %s: %w", res.gvr.Resource, err)
+		}
+		for _, item := range list.Items {
+			prepareObjectForBackup(&item)
+			name := item.GetName()
+			if item.GetNamespace() != "" {
+				name = item.GetNamespace() + "-" + name
+			}
+			if err := add(fmt.Sprintf("%s/%s.yaml", res.dir, name), item.Object); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return 0, fmt.Errorf("error closing tar writer: %w", err)
+	}
+	if err := gw.Close(); err != nil {
+		return 0, fmt.Errorf("error closing gzip writer: %w", err)
+	}
+
+	return count, nil
+}
+
+// prepareObjectForBackup strips the fields of an object that are assigned by
+// the cluster it was read from
+func prepareObjectForBackup(obj *unstructured.Unstructured) {
+	obj.SetManagedFields(nil)
+	obj.SetOwnerReferences(nil)
+	obj.SetCreationTimestamp(metav1.Time{})
+	obj.SetResourceVersion("")
+	obj.SetUID("")
+	unstructured.RemoveNestedField(obj.Object, "status")
+}
+
+// BackupToStateStore uploads a tls backup of the cluster to its state store
+// bucket and prunes backups past the retention limit
+func BackupToStateStore(cl *pkgtypes.Cluster, kcfg *k8s.KubernetesClient) (string, error) {
+	if !objectStorage.SupportsClusterObjects(&cl.StateStoreCredentials, &cl.StateStoreDetails) {
+		return "", fmt.Errorf("state store of cluster %q does not support tls backups", cl.ClusterName)
+	}
+
+	dyn, err := dynamic.NewForConfig(kcfg.RestConfig)
+	if err != nil {
+		return "", fmt.Errorf("error creating dynamic client: %w", err)
+	}
+
+	f, err := os.CreateTemp("", "tls-backup-*.tar.gz")
+	if err != nil {
+		return "", fmt.Errorf("error creating temporary file: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	count, err := WriteBackupArchive(kcfg.Clientset, dyn, f)
+	if err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("error closing temporary file: %w", err)
+	}
+
+	key := TLSBackupPrefix(cl.ClusterName) + time.Now().UTC().Format("20060102T150405Z") + ".tar.gz"
+	err = objectStorage.PutClusterObject(&cl.StateStoreCredentials, &cl.StateStoreDetails, &pkgtypes.PushBucketObject{
+		LocalFilePath:  f.Name(),
+		RemoteFilePath: key,
+		ContentType:    "application/gzip",
+	})
+	if err != nil {
+		return "", fmt.Errorf("error uploading tls backup: %w", err)
+	}
+	log.Info().Msgf("backed up %d tls resources of cluster %s to %s", count, cl.ClusterName, key)
+
+	backups, err := ListStateStoreBackups(cl)
+	if err != nil {
+		log.Warn().Msgf("unable to prune tls backups of cluster %s: %s", cl.ClusterName, err)
+		return key, nil
+	}
+	for i := tlsBackupRetention; i < len(backups); i++ {
+		if err := objectStorage.DeleteClusterObject(&cl.StateStoreCredentials, &cl.StateStoreDetails, backups[i].Key); err != nil {
+			log.Warn().Msgf("unable to prune tls backup %s: %s", backups[i].Key, err)
+		}
+	}
+
+	return key, nil
+}
+
+// ListStateStoreBackups returns the tls backups of a cluster, newest first
+func ListStateStoreBackups(cl *pkgtypes.Cluster) ([]pkgtypes.BucketObject, error) {
+	backups, err := objectStorage.ListClusterObjects(&cl.StateStoreCredentials, &cl.StateStoreDetails, TLSBackupPrefix(cl.ClusterName))
+	if err != nil {
+		return nil, fmt.Errorf("error listing tls backups of cluster %q: %w", cl.ClusterName, err)
+	}
+	return backups, nil
+}
+
+// FetchLatestBackup downloads the newest tls backup of a cluster and extracts it
+// into backupDir so it can be applied with Restore. The state stores of earlier
+// clusters with the same name are searched too, so a recreated cluster gets the
+// certificates of the cluster it replaces. It reports false when there is no
+// backup.
+func FetchLatestBackup(cl *pkgtypes.Cluster, backupDir string) (bool, error) {
+	if !objectStorage.SupportsClusterObjects(&cl.StateStoreCredentials, &cl.StateStoreDetails) {
+		return false, nil
+	}
+
+	var latest *pkgtypes.BucketObject
+	var store pkgtypes.StateStoreDetails
+	for _, d := range clusterStateStores(cl) {
+		backups, err := objectStorage.ListClusterObjects(&cl.StateStoreCredentials, &d, TLSBackupPrefix(cl.ClusterName))
+		if err != nil {
+			if d.Name == cl.StateStoreDetails.Name {
+				return false, fmt.Errorf("error listing tls backups of cluster %q: %w", cl.ClusterName, err)
+			}
+			log.Warn().Msgf("unable to list tls backups in state store %s: %s", d.Name, err)
+			continue
+		}
+		if len(backups) > 0 && (latest == nil || backups[0].LastModified.After(latest.LastModified)) {
+			latest, store = &backups[0], d
+		}
+	}
+	if latest == nil {
+		return false, nil
+	}
+
+	localFilePath := filepath.Join(os.TempDir(), filepath.Base(latest.Key))
+	defer os.Remove(localFilePath)
+
+	err := objectStorage.GetClusterObject(&cl.StateStoreCredentials, &store, localFilePath, latest.Key, true)
+	if err != nil {
+		return false, fmt.Errorf("error downloading tls backup %q from %s: %w", latest.Key, store.Name, err)
+	}
+
+	f, err := os.Open(localFilePath)
+	if err != nil {
+		return false, fmt.Errorf("error opening tls backup %q: %w", localFilePath, err)
+	}
+	defer f.Close()
+
+	if err := downloadManager.ExtractTarGz(f, backupDir); err != nil {
+		return false, fmt.Errorf("error extracting tls backup %q: %w", latest.Key, err)
+	}
+
+	log.Info().Msgf("fetched tls backup %s of cluster %s from %s", latest.Key, cl.ClusterName, store.Name)
+	return true, nil
+}
+
+// clusterStateStores returns the state store of a cluster followed by the state
+// stores left behind by earlier clusters with the same name. Akamai buckets are
+// named after the cluster and azure storage accounts after the cluster id, so
+// only the current state store is searched on those.
+func clusterStateStores(cl *pkgtypes.Cluster) []pkgtypes.StateStoreDetails {
+	stores := []pkgtypes.StateStoreDetails{cl.StateStoreDetails}
+	switch cl.CloudProvider {
+	case "akamai", "azure":
+		return stores
+	}
+
+	buckets, err := objectStorage.ListClusterBuckets(&cl.StateStoreCredentials, &cl.StateStoreDetails)
+	if err != nil {
+		log.Warn().Msgf("unable to list state stores of earlier %s clusters: %s", cl.ClusterName, err)
+		return stores
+	}
+
+	prefix := objectStorage.StateStoreBucketPrefix(cl.ClusterName)
+	for _, bucket := range buckets {
+		clusterID, found := strings.CutPrefix(bucket, prefix)
+		if !found || clusterID == "" || strings.Contains(clusterID, "-") || bucket == cl.StateStoreDetails.Name {
+			continue
+		}
+		d := cl.StateStoreDetails
+		d.Name = bucket
+		stores = append(stores, d)
+	}
+
+	return stores
+}
+
+// ScheduledBackup backs up every interval the tls certificates of every
+// provisioned cluster to its state store
+func ScheduledBackup(interval time.Duration) {
+	if interval <= 0 {
+		log.Info().Msg("scheduled tls backups are disabled")
+		return
+	}
+
+	backupClusters()
+	for range time.Tick(interval) {
+		backupClusters()
+	}
+}
+
+// backupClusters backs up the tls certificates of every provisioned cluster,
+// clusters running an operation such as a state store migration are skipped
+func backupClusters() {
+	kcfg := utils.GetKubernetesClient("")
+
+	clusters, err := secrets.GetClusters(kcfg.Clientset)
+	if err != nil {
+		log.Warn().Msgf("unable to list clusters for tls backup: %s", err)
+		return
+	}
+
+	for _, cl := range clusters {
+		if cl.Status != constants.ClusterStatusProvisioned || cl.InProgress || !objectStorage.SupportsClusterObjects(&cl.StateStoreCredentials, &cl.StateStoreDetails) {
+			continue
+		}
+
+		clusterKcfg := utils.GetKubernetesClient(cl.ClusterName)
+		if clusterKcfg == nil {
+			continue
+		}
+
+		if _, err := BackupToStateStore(&cl, clusterKcfg); err != nil {
+			log.Warn().Msgf("unable to back up tls certificates of cluster %s: %s", cl.ClusterName, err)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package ssl
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/konstructio/kubefirst-api/internal/downloadManager"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/yaml"
+)
+
+func TestWriteBackupArchive(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Name:            "argocd-tls",
+			Namespace:       "argocd",
+			ResourceVersion: "42",
+			Annotations: map[string]string{
+				"cert-manager.io/certificate-name":  "argocd",
+				"kubectl.kubernetes.io/restartedAt": "now",
+			},
+		}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "vault-token", Namespace: "vault"}},
+	)
+
+	certificate := &unstructured.Unstructured{}
+	certificate.SetAPIVersion("cert-manager.io/v1")
+	certificate.SetKind("Certificate")
+	certificate.SetName("argocd")
+	certificate.SetNamespace("argocd")
+	certificate.Object["status"] = map[string]interface{}{"revision": int64(1)}
+
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		certificateGVR:   "CertificateList",
+		clusterIssuerGVR: "ClusterIssuerList",
+	}, certificate)
+
+	var buf bytes.Buffer
+	count, err := WriteBackupArchive(clientset, dyn, &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 resources in the archive, got %d", count)
+	}
+
+	dir := t.TempDir()
+	if err := downloadManager.ExtractTarGz(&buf, dir); err != nil {
+		t.Fatalf("unexpected error extracting archive: %s", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, "secrets", "argocd-argocd-tls.yaml"))
+	if err != nil {
+		t.Fatalf("expected secret in archive: %s", err)
+	}
+	var secret corev1.Secret
+	if err := yaml.Unmarshal(content, &secret); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if secret.ResourceVersion != "" {
+		t.Errorf("expected resource version to be stripped, got %q", secret.ResourceVersion)
+	}
+	if len(secret.Annotations) != 1 || secret.Annotations["cert-manager.io/certificate-name"] != "argocd" {
+		t.Errorf("expected only cert-manager annotations to be kept, got %v", secret.Annotations)
+	}
+
+	content, err = os.ReadFile(filepath.Join(dir, "certificates", "argocd-argocd.yaml"))
+	if err != nil {
+		t.Fatalf("expected certificate in archive: %s", err)
+	}
+	if bytes.Contains(content, []byte("status")) {
+		t.Errorf("expected certificate status to be stripped, got %s", content)
+	}
+}
@@ -0,0 +1,227 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package ssl
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/konstructio/kubefirst-api/internal/constants"
+	"github.com/konstructio/kubefirst-api/internal/secrets"
+	"github.com/konstructio/kubefirst-api/internal/utils"
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
+	"github.com/rs/zerolog/log"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	CertificateStatusValid    = "valid"
+	CertificateStatusExpiring = "expiring"
+	CertificateStatusExpired  = "expired"
+	CertificateStatusPending  = "pending"
+)
+
+// ListCertificates returns the tls certificates of a cluster, combining the tls
+// secrets found by Backup with the cert-manager Certificates that manage them.
+// Certificates expiring within window are reported as expiring.
+func ListCertificates(clientset kubernetes.Interface, dyn dynamic.Interface, window time.Duration) ([]pkgtypes.ClusterCertificate, error) {
+	now := time.Now()
+
+	tlsSecrets, err := listTLSSecrets(clientset)
+	if err != nil {
+		return nil, err
+	}
+
+	// index cert-manager Certificates by the secret they write to
+	managed := map[string]unstructured.Unstructured{}
+	list, err := dyn.Resource(certificateGVR).List(context.Background(), metav1.ListOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("error listing certificates: %w", err)
+	}
+	if list != nil {
+		for _, item := range list.Items {
+			secretName, _, _ := unstructured.NestedString(item.Object, "spec", "secretName")
+			managed[item.GetNamespace()+"/"+secretName] = item
+		}
+	}
+
+	res := make([]pkgtypes.ClusterCertificate, 0, len(tlsSecrets))
+	for _, secret := range tlsSecrets {
+		cert := pkgtypes.ClusterCertificate{
+			Name:       secret.Name,
+			Namespace:  secret.Namespace,
+			SecretName: secret.Name,
+		}
+
+		if item, ok := managed[secret.Namespace+"/"+secret.Name]; ok {
+			applyCertificateStatus(&cert, item)
+			delete(managed, secret.Namespace+"/"+secret.Name)
+		}
+
+		if err := parseSecretCertificate(&cert, secret); err != nil {
+			log.Warn().Msgf("unable to parse certificate in secret %s/%s: %s", secret.Namespace, secret.Name, err)
+			cert.Status = CertificateStatusPending
+		} else {
+			cert.Status = expiryStatus(cert.NotAfter, now, window)
+		}
+
+		res = append(res, cert)
+	}
+
+	// Certificates whose secret has not been issued yet
+	for _, item := range managed {
+		cert := pkgtypes.ClusterCertificate{Namespace: item.GetNamespace(), Status: CertificateStatusPending}
+		cert.SecretName, _, _ = unstructured.NestedString(item.Object, "spec", "secretName")
+		applyCertificateStatus(&cert, item)
+		res = append(res, cert)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Namespace != res[j].Namespace {
+			return res[i].Namespace < res[j].Namespace
+		}
+		return res[i].Name < res[j].Name
+	})
+
+	return res, nil
+}
+
+// applyCertificateStatus copies the cert-manager view of a certificate
+func applyCertificateStatus(cert *pkgtypes.ClusterCertificate, item unstructured.Unstructured) {
+	cert.Name = item.GetName()
+	cert.Managed = true
+
+	if issuer, found, _ := unstructured.NestedString(item.Object, "spec", "issuerRef", "name"); found {
+		cert.Issuer = issuer
+	}
+
+	if renewal, found, _ := unstructured.NestedString(item.Object, "status", "renewalTime"); found {
+		if t, err := time.Parse(time.RFC3339, renewal); err == nil {
+			cert.RenewalTime = &t
+		}
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(item.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if ok && condition["type"] == "Ready" {
+			cert.Ready = condition["status"] == "True"
+		}
+	}
+}
+
+// parseSecretCertificate reads the leaf certificate of a tls secret
+func parseSecretCertificate(cert *pkgtypes.ClusterCertificate, secret corev1.Secret) error {
+	block, _ := pem.Decode(secret.Data[corev1.TLSCertKey])
+	if block == nil {
+		return errors.New("no pem encoded certificate found")
+	}
+
+	x509Cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("error parsing certificate: %w", err)
+	}
+
+	// cert-manager's issuer name is more useful than the ca's common name
+	if cert.Issuer == "" {
+		cert.Issuer = x509Cert.Issuer.CommonName
+	}
+	cert.DNSNames = x509Cert.DNSNames
+	cert.NotBefore = x509Cert.NotBefore
+	cert.NotAfter = x509Cert.NotAfter
+	return nil
+}
+
+// expiryStatus classifies a certificate by its expiry
+func expiryStatus(notAfter, now time.Time, window time.Duration) string {
+	switch {
+	case !now.Before(notAfter):
+		return CertificateStatusExpired
+	case notAfter.Sub(now) <= window:
+		return CertificateStatusExpiring
+	default:
+		return CertificateStatusValid
+	}
+}
+
+// MonitorCertificateExpiry records every interval on each provisioned cluster
+// the certificates expiring within window
+func MonitorCertificateExpiry(interval, window time.Duration) {
+	if interval <= 0 {
+		log.Info().Msg("certificate expiry monitoring is disabled")
+		return
+	}
+
+	for range time.Tick(interval) {
+		kcfg := utils.GetKubernetesClient("")
+
+		clusters, err := secrets.GetClusters(kcfg.Clientset)
+		if err != nil {
+			log.Warn().Msgf("unable to list clusters for certificate expiry check: %s", err)
+			continue
+		}
+
+		for _, cl := range clusters {
+			if cl.Status != constants.ClusterStatusProvisioned {
+				continue
+			}
+
+			if err := checkClusterCertificates(kcfg.Clientset, cl.ClusterName, window); err != nil {
+				log.Warn().Msgf("unable to check certificate expiry of cluster %s: %s", cl.ClusterName, err)
+			}
+		}
+	}
+}
+
+// checkClusterCertificates updates the expiring certificates of a cluster record
+func checkClusterCertificates(clientset kubernetes.Interface, clusterName string, window time.Duration) error {
+	clusterKcfg := utils.GetKubernetesClient(clusterName)
+	if clusterKcfg == nil {
+		return fmt.Errorf("unable to create kubernetes client for cluster %q", clusterName)
+	}
+
+	dyn, err := dynamic.NewForConfig(clusterKcfg.RestConfig)
+	if err != nil {
+		return fmt.Errorf("error creating dynamic client: %w", err)
+	}
+
+	certs, err := ListCertificates(clusterKcfg.Clientset, dyn, window)
+	if err != nil {
+		return err
+	}
+
+	expiring := make([]pkgtypes.ClusterCertificate, 0)
+	for _, cert := range certs {
+		if cert.Status == CertificateStatusExpiring || cert.Status == CertificateStatusExpired {
+			log.Warn().Msgf("certificate %s/%s of cluster %s expires at %s", cert.Namespace, cert.Name, clusterName, cert.NotAfter.Format(time.RFC3339))
+			expiring = append(expiring, cert)
+		}
+	}
+
+	// re-read the record right before updating to avoid overwriting concurrent changes
+	cl, err := secrets.GetCluster(clientset, clusterName)
+	if err != nil {
+		return fmt.Errorf("error retrieving cluster record: %w", err)
+	}
+	cl.ExpiringCertificates = expiring
+	cl.CertificatesLastCheckedAt = time.Now().UTC().Format(time.RFC3339)
+
+	if err := secrets.UpdateCluster(clientset, *cl); err != nil {
+		return fmt.Errorf("error updating cluster record: %w", err)
+	}
+	return nil
+}
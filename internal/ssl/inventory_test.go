@@ -0,0 +1,106 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package ssl
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testCertificatePEM(t *testing.T, dnsName string, notAfter time.Time) []byte {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: dnsName},
+		DNSNames:     []string{dnsName},
+		NotBefore:    notAfter.Add(-90 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestListCertificates(t *testing.T) {
+	now := time.Now()
+
+	clientset := fake.NewSimpleClientset(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "argocd-tls", Namespace: "argocd"},
+			Data:       map[string][]byte{corev1.TLSCertKey: testCertificatePEM(t, "argocd.kubefirst.dev", now.Add(60*24*time.Hour))},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "vault-tls", Namespace: "vault"},
+			Data:       map[string][]byte{corev1.TLSCertKey: testCertificatePEM(t, "vault.kubefirst.dev", now.Add(2*24*time.Hour))},
+		},
+	)
+
+	argocd := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "cert-manager.io/v1",
+		"kind":       "Certificate",
+		"metadata":   map[string]interface{}{"name": "argocd", "namespace": "argocd"},
+		"spec": map[string]interface{}{
+			"secretName": "argocd-tls",
+			"issuerRef":  map[string]interface{}{"name": "letsencrypt-prod", "kind": "ClusterIssuer"},
+		},
+		"status": map[string]interface{}{
+			"renewalTime": now.Add(30 * 24 * time.Hour).UTC().Format(time.RFC3339),
+			"conditions":  []interface{}{map[string]interface{}{"type": "Ready", "status": "True"}},
+		},
+	}}
+	pending := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "cert-manager.io/v1",
+		"kind":       "Certificate",
+		"metadata":   map[string]interface{}{"name": "atlantis", "namespace": "atlantis"},
+		"spec":       map[string]interface{}{"secretName": "atlantis-tls"},
+	}}
+
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		certificateGVR: "CertificateList",
+	}, argocd, pending)
+
+	certs, err := ListCertificates(clientset, dyn, 14*24*time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(certs) != 3 {
+		t.Fatalf("expected 3 certificates, got %d: %+v", len(certs), certs)
+	}
+
+	// sorted by namespace
+	if certs[0].Name != "argocd" || !certs[0].Managed || !certs[0].Ready || certs[0].Issuer != "letsencrypt-prod" || certs[0].RenewalTime == nil || certs[0].Status != CertificateStatusValid {
+		t.Errorf("unexpected managed certificate %+v", certs[0])
+	}
+	if certs[1].SecretName != "atlantis-tls" || certs[1].Status != CertificateStatusPending {
+		t.Errorf("unexpected pending certificate %+v", certs[1])
+	}
+	if certs[2].Name != "vault-tls" || certs[2].Managed || certs[2].Issuer != "vault.kubefirst.dev" || certs[2].Status != CertificateStatusExpiring {
+		t.Errorf("unexpected unmanaged certificate %+v", certs[2])
+	}
+}
@@ -18,26 +18,21 @@ import (
 
 	pkg "github.com/konstructio/kubefirst-api/internal"
 	"github.com/konstructio/kubefirst-api/internal/k8s"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	v1 "k8s.io/client-go/applyconfigurations/core/v1"
+	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/yaml"
 )
 
-func Restore(backupDir, kubeconfigPath string) error {
+func Restore(backupDir string, clientset kubernetes.Interface) error {
 	sslSecretFiles, err := os.ReadDir(backupDir + "/secrets")
 	if err != nil {
 		return fmt.Errorf("error reading directory: %w", err)
 	}
 
-	clientset, err := k8s.GetClientSet(kubeconfigPath)
-	if err != nil {
-		return fmt.Errorf("error getting clientset: %w", err)
-	}
-
 	for _, secret := range sslSecretFiles {
-		// file is named with convention $namespace-$secretName.yaml
-		//  todo link to backup source code
-		namespace := strings.Split(secret.Name(), "-")[0]
 		log.Info().Msg("creating secret: " + secret.Name())
 
 		f, err := os.ReadFile(backupDir + "/secrets/" + secret.Name())
@@ -52,6 +47,21 @@ func Restore(backupDir, kubeconfigPath string) error {
 			return fmt.Errorf("error unmarshalling yaml: %w", err)
 		}
 
+		// file is named with convention $namespace-$secretName.yaml, the
+		// namespace of the secret itself is preferred as namespaces may hold dashes
+		namespace := strings.Split(secret.Name(), "-")[0]
+		if secretData.Namespace != nil && *secretData.Namespace != "" {
+			namespace = *secretData.Namespace
+		}
+
+		// secrets can be restored before the namespaces are bootstrapped
+		_, err = clientset.CoreV1().Namespaces().Create(context.Background(), &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: namespace},
+		}, metav1.CreateOptions{})
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("error creating namespace %q: %w", namespace, err)
+		}
+
 		sec, err := clientset.CoreV1().Secrets(namespace).Apply(context.Background(), secretData, metav1.ApplyOptions{FieldManager: "application/apply-patch"})
 		if err != nil {
 			return fmt.Errorf("error applying secret: %w", err)
@@ -68,37 +78,66 @@ func Backup(backupDir, kubeconfigPath string) error {
 	}
 
 	// * corev1 secret resources
+	secrets, err := listTLSSecrets(clientset)
+	if err != nil {
+		return err
+	}
+
+	for _, secret := range secrets {
+		log.Info().Msg("backing up secret (ns/resource): " + secret.Namespace + "/" + secret.Name)
+
+		// modify fields of secret for restore
+		prepareSecretForRestore(&secret)
+
+		fileName := fmt.Sprintf("%s/%s-%s.yaml", backupDir+"/secrets", secret.Namespace, secret.Name)
+		log.Info().Msgf("writing file: %q", fileName)
+		yamlContent, err := yaml.Marshal(secret)
+		if err != nil {
+			return fmt.Errorf("unable to marshal yaml: %w", err)
+		}
+		if err := pkg.CreateFile(fileName, yamlContent); err != nil {
+			return fmt.Errorf("error creating file: %w", err)
+		}
+	}
+	return nil
+}
+
+// listTLSSecrets returns the secrets of every namespace holding a tls certificate
+func listTLSSecrets(clientset kubernetes.Interface) ([]corev1.Secret, error) {
 	secrets, err := clientset.CoreV1().Secrets("").List(context.Background(), metav1.ListOptions{})
 	if err != nil {
-		return fmt.Errorf("error listing secrets: %w", err)
+		return nil, fmt.Errorf("error listing secrets: %w", err)
 	}
 
+	tlsSecrets := make([]corev1.Secret, 0)
 	for _, secret := range secrets.Items {
 		if strings.Contains(secret.Name, "-tls") {
-			log.Info().Msg("backing up secret (ns/resource): " + secret.Namespace + "/" + secret.Name)
-
-			// modify fields of secret for restore
-			secret.APIVersion = "v1"
-			secret.Kind = "Secret"
-			secret.SetManagedFields(nil)
-			secret.SetOwnerReferences(nil)
-			secret.SetAnnotations(nil)
-			secret.SetCreationTimestamp(metav1.Time{})
-			secret.SetResourceVersion("")
-			secret.SetUID("")
-
-			fileName := fmt.Sprintf("%s/%s-%s.yaml", backupDir+"/secrets", secret.Namespace, secret.Name)
-			log.Info().Msgf("writing file: %q", fileName)
-			yamlContent, err := yaml.Marshal(secret)
-			if err != nil {
-				return fmt.Errorf("unable to marshal yaml: %w", err)
-			}
-			if err := pkg.CreateFile(fileName, yamlContent); err != nil {
-				return fmt.Errorf("error creating file: %w", err)
+			tlsSecrets = append(tlsSecrets, secret)
+		}
+	}
+	return tlsSecrets, nil
+}
+
+// prepareSecretForRestore strips the fields of a secret that are assigned by the
+// cluster it was read from. cert-manager annotations are kept so the restored
+// certificate is matched to its Certificate instead of being reissued.
+func prepareSecretForRestore(secret *corev1.Secret) {
+	var annotations map[string]string
+	for k, v := range secret.GetAnnotations() {
+		if strings.HasPrefix(k, "cert-manager.io/") {
+			if annotations == nil {
+				annotations = map[string]string{}
 			}
-		} else {
-			log.Info().Msgf("skipping secret: %s", secret.Name)
+			annotations[k] = v
 		}
 	}
-	return nil
+
+	secret.APIVersion = "v1"
+	secret.Kind = "Secret"
+	secret.SetManagedFields(nil)
+	secret.SetOwnerReferences(nil)
+	secret.SetAnnotations(annotations)
+	secret.SetCreationTimestamp(metav1.Time{})
+	secret.SetResourceVersion("")
+	secret.SetUID("")
 }
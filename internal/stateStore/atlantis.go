@@ -0,0 +1,145 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+
+//nolint:revive,stylecheck // allowing package name stateStore
+package stateStore
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strconv"
+	"time"
+
+	"github.com/konstructio/kubefirst-api/internal/k8s"
+	"github.com/konstructio/kubefirst-api/internal/vault"
+	"github.com/rs/zerolog/log"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+)
+
+// atlantisName is the namespace and statefulset of the in-cluster terraform
+// runner, and the vault secret holding its environment
+const atlantisName = "atlantis"
+
+// externalSecretGVR is the resource syncing the atlantis environment from vault
+var externalSecretGVR = schema.GroupVersionResource{Group: "external-secrets.io", Version: "v1beta1", Resource: "externalsecrets"}
+
+// updateAtlantisCredentials writes the state store credentials of envs to the
+// atlantis secret in vault and returns a function restoring the previous ones
+func updateAtlantisCredentials(vaultURL string, kcfg *k8s.KubernetesClient, envs map[string]string) (func() error, error) {
+	token, err := vault.Conf.APIToken(vaultURL, kcfg.Clientset)
+	if err != nil {
+		return nil, fmt.Errorf("unable to authenticate to vault: %w", err)
+	}
+
+	current, err := vault.Conf.GetSecret(vaultURL, token, atlantisName, 0)
+	if err != nil {
+		return nil, fmt.Errorf("error reading atlantis credentials: %w", err)
+	}
+
+	data := maps.Clone(current.Data)
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	for k, v := range envs {
+		data[k] = v
+	}
+
+	version, err := vault.Conf.PutSecret(vaultURL, token, atlantisName, data, &current.Version)
+	if err != nil {
+		return nil, fmt.Errorf("error updating atlantis credentials: %w", err)
+	}
+
+	restore := func() error {
+		if _, err := vault.Conf.PutSecret(vaultURL, token, atlantisName, current.Data, &version); err != nil {
+			return fmt.Errorf("error restoring atlantis credentials: %w", err)
+		}
+		return nil
+	}
+	return restore, nil
+}
+
+// reloadAtlantis syncs the atlantis environment from vault and restarts the
+// atlantis pods so the next runs use it
+func reloadAtlantis(kcfg *k8s.KubernetesClient) error {
+	ctx := context.Background()
+
+	dyn, err := dynamic.NewForConfig(kcfg.RestConfig)
+	if err != nil {
+		return fmt.Errorf("error creating dynamic client: %w", err)
+	}
+
+	externalSecrets, err := dyn.Resource(externalSecretGVR).Namespace(atlantisName).List(ctx, metav1.ListOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("error listing atlantis external secrets: %w", err)
+	}
+
+	if externalSecrets != nil {
+		forcedAt := time.Now().Truncate(time.Second)
+		for i := range externalSecrets.Items {
+			es := &externalSecrets.Items[i]
+			annotations := es.GetAnnotations()
+			if annotations == nil {
+				annotations = map[string]string{}
+			}
+			annotations["force-sync"] = strconv.FormatInt(forcedAt.Unix(), 10)
+			es.SetAnnotations(annotations)
+
+			if _, err := dyn.Resource(externalSecretGVR).Namespace(atlantisName).Update(ctx, es, metav1.UpdateOptions{}); err != nil {
+				return fmt.Errorf("error syncing external secret %q: %w", es.GetName(), err)
+			}
+		}
+
+		err = wait.PollUntilContextTimeout(ctx, 5*time.Second, 2*time.Minute, true, func(ctx context.Context) (bool, error) {
+			for _, es := range externalSecrets.Items {
+				obj, err := dyn.Resource(externalSecretGVR).Namespace(atlantisName).Get(ctx, es.GetName(), metav1.GetOptions{})
+				if err != nil {
+					return false, fmt.Errorf("error getting external secret %q: %w", es.GetName(), err)
+				}
+				if !refreshedSince(obj, forcedAt) {
+					return false, nil
+				}
+			}
+			return true, nil
+		})
+		if err != nil {
+			return fmt.Errorf("error waiting for the atlantis external secrets to sync: %w", err)
+		}
+	}
+
+	statefulSet, err := kcfg.Clientset.AppsV1().StatefulSets(atlantisName).Get(ctx, atlantisName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info().Msg("atlantis is not installed, nothing to restart")
+			return nil
+		}
+		return fmt.Errorf("error getting atlantis statefulset: %w", err)
+	}
+
+	// deleting the pods keeps the statefulset as argocd deployed it
+	err = kcfg.Clientset.CoreV1().Pods(atlantisName).DeleteCollection(ctx, metav1.DeleteOptions{}, metav1.ListOptions{
+		LabelSelector: metav1.FormatLabelSelector(statefulSet.Spec.Selector),
+	})
+	if err != nil {
+		return fmt.Errorf("error restarting atlantis: %w", err)
+	}
+
+	log.Info().Msg("atlantis restarted with the new state store credentials")
+	return nil
+}
+
+// refreshedSince reports whether an external secret was synced at or after t
+func refreshedSince(es *unstructured.Unstructured, t time.Time) bool {
+	refreshTime, _, _ := unstructured.NestedString(es.Object, "status", "refreshTime")
+	refreshed, err := time.Parse(time.RFC3339, refreshTime)
+	return err == nil && !refreshed.Before(t)
+}
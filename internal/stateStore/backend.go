@@ -0,0 +1,243 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+
+//nolint:revive,stylecheck // allowing package name stateStore
+package stateStore
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/konstructio/kubefirst-api/internal/objectStorage"
+)
+
+// awsS3Hostname is the endpoint of aws s3, whose backend blocks carry no endpoint
+const awsS3Hostname = "s3.amazonaws.com"
+
+var (
+	s3BackendPattern = regexp.MustCompile(`backend\s+"s3"\s*\{`)
+	endpointPattern  = regexp.MustCompile(`(?m)^[ \t]*endpoint[ \t]*=`)
+	regionPattern    = regexp.MustCompile(`(?m)^([ \t]*region[ \t]*=[ \t]*)"[^"]*"`)
+	indentPattern    = regexp.MustCompile(`\n([ \t]+)\S`)
+	containerPattern = regexp.MustCompile(`(?m)^([ \t]*container_name[ \t]*=[ \t]*)"[^"]*"`)
+	attributePattern = regexp.MustCompile(`(?m)^[ \t]*(\w+)[ \t]*=[ \t]*"([^"]*)"`)
+)
+
+// gcsStateObject is the object the gcs backend stores the default workspace in,
+// under its prefix
+const gcsStateObject = "default.tfstate"
+
+// backendTarget is the bucket a terraform backend points at
+type backendTarget struct {
+	// Backend is s3, gcs or azurerm
+	Backend  string
+	Bucket   string
+	Hostname string
+	Region   string
+	// Account is the storage account of azurerm backends
+	Account string
+}
+
+// rewriteBackends points the backend blocks and remote state references of a
+// terraform file from one state store to another, possibly of another backend.
+// It reports whether the content changed, and maps the state objects whose key
+// differs on the new backend to their new key.
+func rewriteBackends(content string, from, to backendTarget) (string, map[string]string, bool) {
+	if from.Backend == objectStorage.BackendS3 && to.Backend == objectStorage.BackendS3 {
+		res, changed := rewriteS3Backends(content, from, to)
+		return res, nil, changed
+	}
+
+	// the container of azure state stores is named terraform, so it is only
+	// replaced in the backend blocks
+	res := content
+	switch {
+	case from.Backend != objectStorage.BackendAzure:
+		res = strings.ReplaceAll(res, quote(from.Bucket), quote(to.Bucket))
+	case to.Backend == objectStorage.BackendAzure:
+		res = strings.ReplaceAll(res, quote(from.Account), quote(to.Account))
+		res = containerPattern.ReplaceAllString(res, "${1}"+quote(to.Bucket))
+	}
+	if from.Backend == to.Backend {
+		return res, nil, res != content
+	}
+
+	// backend "s3" { and backend = "s3" followed by a remote state config
+	pattern := regexp.MustCompile(`backend\s*=?\s*"` + regexp.QuoteMeta(from.Backend) + `"\s*(?:config\s*=\s*)?\{`)
+
+	renames := map[string]string{}
+	var b strings.Builder
+	rest := res
+	for {
+		loc := pattern.FindStringIndex(rest)
+		if loc == nil {
+			b.WriteString(rest)
+			break
+		}
+
+		end := matchingBrace(rest, loc[1]-1)
+		if end < 0 {
+			b.WriteString(rest)
+			break
+		}
+
+		body, oldKey, newKey := convertBackendBody(rest[loc[1]:end], from, to)
+		if oldKey != newKey {
+			renames[oldKey] = newKey
+		}
+
+		b.WriteString(rest[:loc[0]])
+		b.WriteString(strings.Replace(rest[loc[0]:loc[1]], quote(from.Backend), quote(to.Backend), 1))
+		b.WriteString(body)
+		rest = rest[end:]
+	}
+	res = b.String()
+
+	return res, renames, res != content
+}
+
+// convertBackendBody renders the body of a backend block, or of a remote state
+// config, for another backend. It returns the key of the state object under
+// the old and the new backend.
+func convertBackendBody(body string, from, to backendTarget) (string, string, string) {
+	attrs := map[string]string{}
+	for _, m := range attributePattern.FindAllStringSubmatch(body, -1) {
+		attrs[m[1]] = m[2]
+	}
+
+	key := attrs["key"]
+	if from.Backend == objectStorage.BackendGCS {
+		key = gcsStateObject
+		if prefix := strings.Trim(attrs["prefix"], "/"); prefix != "" {
+			key = prefix + "/" + gcsStateObject
+		}
+	}
+	newKey := key
+
+	var lines [][2]string
+	switch to.Backend {
+	case objectStorage.BackendGCS:
+		prefix, found := strings.CutSuffix(key, "/"+gcsStateObject)
+		if !found {
+			prefix = strings.TrimSuffix(key, ".tfstate")
+			newKey = prefix + "/" + gcsStateObject
+		}
+		lines = [][2]string{{"bucket", quote(to.Bucket)}, {"prefix", quote(prefix)}}
+	case objectStorage.BackendAzure:
+		lines = [][2]string{{"storage_account_name", quote(to.Account)}, {"container_name", quote(to.Bucket)}, {"key", quote(key)}}
+	default:
+		region := to.Region
+		if region == "" {
+			region = "us-east-1"
+		}
+		lines = [][2]string{{"bucket", quote(to.Bucket)}, {"key", quote(key)}, {"region", quote(region)}}
+		if to.Hostname != awsS3Hostname {
+			lines = append(lines,
+				[2]string{"endpoint", quote("https://" + to.Hostname)},
+				[2]string{"skip_credentials_validation", "true"},
+				[2]string{"skip_region_validation", "true"},
+			)
+		}
+	}
+
+	indent := "    "
+	if m := indentPattern.FindStringSubmatch(body); m != nil {
+		indent = m[1]
+	}
+	closing := "\n"
+	if i := strings.LastIndex(body, "\n"); i >= 0 {
+		closing = body[i:]
+	}
+
+	var b strings.Builder
+	for _, l := range lines {
+		fmt.Fprintf(&b, "\n%s%s = %s", indent, l[0], l[1])
+	}
+	b.WriteString(closing)
+
+	return b.String(), key, newKey
+}
+
+// rewriteS3Backends points the s3 backend blocks and remote state references
+// of a terraform file from one bucket to another, and reports whether the
+// content changed
+func rewriteS3Backends(content string, from, to backendTarget) (string, bool) {
+	res := strings.ReplaceAll(content, quote(from.Bucket), quote(to.Bucket))
+
+	if from.Hostname != "" && from.Hostname != to.Hostname {
+		res = strings.ReplaceAll(res, quote("https://"+from.Hostname), quote("https://"+to.Hostname))
+		res = strings.ReplaceAll(res, quote(from.Hostname), quote(to.Hostname))
+	}
+
+	var b strings.Builder
+	rest := res
+	for {
+		loc := s3BackendPattern.FindStringIndex(rest)
+		if loc == nil {
+			b.WriteString(rest)
+			break
+		}
+
+		end := matchingBrace(rest, loc[1]-1)
+		if end < 0 {
+			b.WriteString(rest)
+			break
+		}
+
+		b.WriteString(rest[:loc[1]])
+		b.WriteString(rewriteS3Block(rest[loc[1]:end], to))
+		rest = rest[end:]
+	}
+	res = b.String()
+
+	return res, res != content
+}
+
+// rewriteS3Block rewrites the body of an s3 backend block
+func rewriteS3Block(body string, to backendTarget) string {
+	if to.Region != "" {
+		body = regionPattern.ReplaceAllString(body, fmt.Sprintf(`${1}%s`, quote(to.Region)))
+	}
+
+	// backends of other s3 compatible stores need an endpoint
+	if to.Hostname != awsS3Hostname && !endpointPattern.MatchString(body) {
+		indent := "    "
+		if m := indentPattern.FindStringSubmatch(body); m != nil {
+			indent = m[1]
+		}
+		body = fmt.Sprintf("\n%sendpoint = %s\n%sskip_credentials_validation = true\n%sskip_region_validation = true%s",
+			indent, quote("https://"+to.Hostname), indent, indent, body)
+	}
+
+	return body
+}
+
+// matchingBrace returns the index of the brace closing the one at open
+func matchingBrace(s string, open int) int {
+	depth := 0
+	inString := false
+	for i := open; i < len(s); i++ {
+		switch {
+		case s[i] == '"' && (i == 0 || s[i-1] != '\\'):
+			inString = !inString
+		case inString:
+		case s[i] == '{':
+			depth++
+		case s[i] == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func quote(s string) string {
+	return `"` + s + `"`
+}
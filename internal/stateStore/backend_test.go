@@ -0,0 +1,235 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+
+//nolint:revive,stylecheck // allowing package name stateStore
+package stateStore
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRewriteS3Backends(t *testing.T) {
+	civoBackend := `terraform {
+  backend "s3" {
+    bucket   = "k1-state-store-dev-abc123"
+    key      = "terraform/civo/terraform.tfstate"
+    endpoint = "https://objectstore.nyc1.civo.com"
+    region   = "nyc1"
+    skip_credentials_validation = true
+  }
+}
+
+data "terraform_remote_state" "civo" {
+  backend = "s3"
+  config = {
+    bucket   = "k1-state-store-dev-abc123"
+    endpoint = "https://objectstore.nyc1.civo.com"
+  }
+}
+`
+
+	awsBackend := `terraform {
+  backend "s3" {
+    bucket  = "k1-state-store-dev-abc123"
+    key     = "terraform/aws/terraform.tfstate"
+    region  = "us-east-1"
+    encrypt = true
+  }
+}
+`
+
+	tests := []struct {
+		name     string
+		content  string
+		from, to backendTarget
+		contains []string
+		excludes []string
+		changed  bool
+	}{
+		{
+			name:    "civo to digitalocean",
+			content: civoBackend,
+			from:    backendTarget{Bucket: "k1-state-store-dev-abc123", Hostname: "objectstore.nyc1.civo.com"},
+			to:      backendTarget{Bucket: "k1-dev-state", Hostname: "nyc3.digitaloceanspaces.com", Region: "us-east-1"},
+			contains: []string{
+				`bucket   = "k1-dev-state"`,
+				`endpoint = "https://nyc3.digitaloceanspaces.com"`,
+				`region   = "us-east-1"`,
+			},
+			excludes: []string{"k1-state-store-dev-abc123", "civo.com"},
+			changed:  true,
+		},
+		{
+			name:    "aws to s3 compatible store",
+			content: awsBackend,
+			from:    backendTarget{Bucket: "k1-state-store-dev-abc123", Hostname: "s3.amazonaws.com"},
+			to:      backendTarget{Bucket: "k1-dev-state", Hostname: "objectstore.lon1.civo.com"},
+			contains: []string{
+				`bucket  = "k1-dev-state"`,
+				`    endpoint = "https://objectstore.lon1.civo.com"`,
+				`    skip_credentials_validation = true`,
+				`region  = "us-east-1"`,
+			},
+			changed: true,
+		},
+		{
+			name:     "unrelated file",
+			content:  `resource "vault_mount" "secret" {}`,
+			from:     backendTarget{Bucket: "k1-state-store-dev-abc123", Hostname: "s3.amazonaws.com"},
+			to:       backendTarget{Bucket: "k1-dev-state", Hostname: "objectstore.lon1.civo.com"},
+			contains: []string{`resource "vault_mount" "secret" {}`},
+			changed:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changed := rewriteS3Backends(tt.content, tt.from, tt.to)
+			if changed != tt.changed {
+				t.Errorf("changed = %v, want %v", changed, tt.changed)
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(got, s) {
+					t.Errorf("rewritten content does not contain %q:\n%s", s, got)
+				}
+			}
+			for _, s := range tt.excludes {
+				if strings.Contains(got, s) {
+					t.Errorf("rewritten content still contains %q:\n%s", s, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRewriteBackends(t *testing.T) {
+	civoBackend := `terraform {
+  backend "s3" {
+    bucket   = "k1-state-store-dev-abc123"
+    key      = "terraform/civo/terraform.tfstate"
+    endpoint = "https://objectstore.nyc1.civo.com"
+    region   = "nyc1"
+  }
+}
+`
+
+	googleBackend := `terraform {
+  backend "gcs" {
+    bucket = "k1-state-store-dev-abc123"
+    prefix = "terraform/google"
+  }
+}
+
+data "terraform_remote_state" "google" {
+  backend = "gcs"
+  config = {
+    bucket = "k1-state-store-dev-abc123"
+    prefix = "terraform/google"
+  }
+}
+`
+
+	azureBackend := `terraform {
+  backend "azurerm" {
+    storage_account_name = "k1abc123dev"
+    container_name       = "terraform"
+    key                  = "terraform/azure/terraform.tfstate"
+  }
+}
+
+module "kubefirst" {
+  source = "terraform"
+}
+`
+
+	tests := []struct {
+		name     string
+		content  string
+		from, to backendTarget
+		contains []string
+		excludes []string
+		renames  map[string]string
+	}{
+		{
+			name:    "s3 to gcs",
+			content: civoBackend,
+			from:    backendTarget{Backend: "s3", Bucket: "k1-state-store-dev-abc123", Hostname: "objectstore.nyc1.civo.com"},
+			to:      backendTarget{Backend: "gcs", Bucket: "k1-dev-state"},
+			contains: []string{
+				`backend "gcs" {`,
+				`    bucket = "k1-dev-state"`,
+				`    prefix = "terraform/civo/terraform"`,
+			},
+			excludes: []string{"civo.com", `"s3"`, "region"},
+			renames:  map[string]string{"terraform/civo/terraform.tfstate": "terraform/civo/terraform/default.tfstate"},
+		},
+		{
+			name:    "gcs to azurerm",
+			content: googleBackend,
+			from:    backendTarget{Backend: "gcs", Bucket: "k1-state-store-dev-abc123"},
+			to:      backendTarget{Backend: "azurerm", Bucket: "terraform", Account: "k1state"},
+			contains: []string{
+				`backend "azurerm" {`,
+				`backend = "azurerm"`,
+				`storage_account_name = "k1state"`,
+				`key = "terraform/google/default.tfstate"`,
+			},
+			excludes: []string{"k1-state-store-dev-abc123", `"gcs"`, "prefix"},
+			renames:  map[string]string{},
+		},
+		{
+			name:    "azurerm to s3",
+			content: azureBackend,
+			from:    backendTarget{Backend: "azurerm", Bucket: "terraform", Account: "k1abc123dev"},
+			to:      backendTarget{Backend: "s3", Bucket: "k1-dev-state", Hostname: "nyc3.digitaloceanspaces.com"},
+			contains: []string{
+				`backend "s3" {`,
+				`bucket = "k1-dev-state"`,
+				`key = "terraform/azure/terraform.tfstate"`,
+				`endpoint = "https://nyc3.digitaloceanspaces.com"`,
+				`source = "terraform"`,
+			},
+			excludes: []string{"k1abc123dev", "container_name"},
+			renames:  map[string]string{},
+		},
+		{
+			name:     "azurerm to another account",
+			content:  azureBackend,
+			from:     backendTarget{Backend: "azurerm", Bucket: "terraform", Account: "k1abc123dev"},
+			to:       backendTarget{Backend: "azurerm", Bucket: "state", Account: "k1state"},
+			contains: []string{`storage_account_name = "k1state"`, `container_name       = "state"`, `source = "terraform"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, renames, changed := rewriteBackends(tt.content, tt.from, tt.to)
+			if !changed {
+				t.Errorf("content did not change")
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(got, s) {
+					t.Errorf("rewritten content does not contain %q:\n%s", s, got)
+				}
+			}
+			for _, s := range tt.excludes {
+				if strings.Contains(got, s) {
+					t.Errorf("rewritten content still contains %q:\n%s", s, got)
+				}
+			}
+			if len(renames) != len(tt.renames) {
+				t.Fatalf("renames = %v, want %v", renames, tt.renames)
+			}
+			for k, v := range tt.renames {
+				if renames[k] != v {
+					t.Errorf("renames[%q] = %q, want %q", k, renames[k], v)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,310 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+
+//nolint:revive,stylecheck // allowing package name stateStore
+package stateStore
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	githttps "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/konstructio/kubefirst-api/internal/gitClient"
+	"github.com/konstructio/kubefirst-api/internal/gitHost"
+	"github.com/konstructio/kubefirst-api/internal/gitShim"
+	"github.com/konstructio/kubefirst-api/internal/k8s"
+	"github.com/konstructio/kubefirst-api/internal/objectStorage"
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
+	"github.com/rs/zerolog/log"
+)
+
+// Validate checks that the state store of a cluster can be migrated to the
+// requested bucket
+func Validate(cl *pkgtypes.Cluster, req *pkgtypes.StateStoreMigrationRequest) error {
+	src := sourceDetails(cl)
+	if !configured(sourceCredentials(cl, &src), &src) {
+		return fmt.Errorf("the %s state store of cluster %q is not recorded and cannot be migrated", cl.CloudProvider, cl.ClusterName)
+	}
+
+	dst := destinationDetails(req)
+	if !configured(&req.Credentials, &dst) {
+		switch dst.Backend {
+		case objectStorage.BackendGCS:
+			return errors.New("the destination bucket name and service account key file are required")
+		case objectStorage.BackendAzure:
+			return errors.New("the destination container name, storage account name and access key are required")
+		}
+		return errors.New("the destination hostname, bucket name and credentials are required")
+	}
+
+	if dst.Backend == src.Backend && dst.Hostname == src.Hostname && dst.Name == src.Name &&
+		(dst.Backend != objectStorage.BackendAzure || req.Credentials.Name == cl.StateStoreCredentials.Name) {
+		return errors.New("the destination is the current state store")
+	}
+
+	if cl.StateStoreDetails.AWSArtifactsBucket != "" && req.ArtifactsBucketName == "" {
+		return fmt.Errorf("cluster %q has a separate artifacts bucket, artifacts_bucket_name is required", cl.ClusterName)
+	}
+
+	return nil
+}
+
+// Migrate copies the terraform state and artifacts of a cluster to another
+// bucket of any backend, points the terraform backends of the gitops
+// repository and the credentials atlantis reads from vault at it, and returns
+// the details to record on the cluster. The source buckets are left untouched
+// so a failed migration can be retried.
+func Migrate(cl *pkgtypes.Cluster, req *pkgtypes.StateStoreMigrationRequest, kcfg *k8s.KubernetesClient, vaultURL string) (*pkgtypes.StateStoreMigrationResult, error) {
+	if err := Validate(cl, req); err != nil {
+		return nil, err
+	}
+
+	src := sourceDetails(cl)
+	srcCr := sourceCredentials(cl, &src)
+	dst := destinationDetails(req)
+	res := &pkgtypes.StateStoreMigrationResult{StateStoreDetails: dst}
+
+	homeDir, _ := os.UserHomeDir()
+	gitopsDir := fmt.Sprintf("%s/.k1/%s/state-store-migration/gitops", homeDir, cl.ClusterName)
+	defer os.RemoveAll(gitopsDir)
+
+	files, renames, err := rewriteGitopsBackends(cl, gitopsDir, backendTarget{
+		Backend:  src.Backend,
+		Bucket:   src.Name,
+		Hostname: strings.TrimPrefix(src.Hostname, "https://"),
+		Account:  accountOf(srcCr, &src),
+	}, backendTarget{
+		Backend:  dst.Backend,
+		Bucket:   dst.Name,
+		Hostname: strings.TrimPrefix(dst.Hostname, "https://"),
+		Region:   req.Region,
+		Account:  accountOf(&req.Credentials, &dst),
+	})
+	if err != nil {
+		return nil, err
+	}
+	res.GitopsFiles = files
+
+	objects, err := copyStore(srcCr, &src, &req.Credentials, &dst, req.Region, renames)
+	if err != nil {
+		return nil, fmt.Errorf("error copying state store of cluster %q: %w", cl.ClusterName, err)
+	}
+	res.Objects = objects
+
+	if cl.StateStoreDetails.AWSArtifactsBucket != "" {
+		srcArtifacts := src
+		srcArtifacts.Name = cl.StateStoreDetails.AWSArtifactsBucket
+		dstArtifacts := dst
+		dstArtifacts.Name = req.ArtifactsBucketName
+
+		artifacts, err := copyStore(srcCr, &srcArtifacts, &req.Credentials, &dstArtifacts, req.Region, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error copying artifacts of cluster %q: %w", cl.ClusterName, err)
+		}
+		res.Objects = append(res.Objects, artifacts...)
+
+		res.StateStoreDetails.AWSStateStoreBucket = req.BucketName
+		res.StateStoreDetails.AWSArtifactsBucket = req.ArtifactsBucketName
+	}
+
+	restore, err := updateAtlantisCredentials(vaultURL, kcfg, objectStorage.BackendTerraformEnvs(cl.CloudProvider, &req.Credentials, &dst))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := pushGitopsBackends(cl, gitopsDir, files, req.BucketName); err != nil {
+		if restoreErr := restore(); restoreErr != nil {
+			log.Error().Msgf("state store migration of cluster %s failed and %s", cl.ClusterName, restoreErr)
+		}
+		return nil, err
+	}
+
+	// vault holds the new credentials, atlantis picks them up on its next sync
+	// if it cannot be reloaded now
+	if err := reloadAtlantis(kcfg); err != nil {
+		log.Warn().Msgf("unable to reload atlantis of cluster %s: %s", cl.ClusterName, err)
+	}
+
+	log.Info().Msgf("migrated state store of cluster %s to %s bucket %s", cl.ClusterName, dst.Backend, dst.Name)
+	return res, nil
+}
+
+// copyStore copies the objects of a bucket to another, possibly of another
+// backend
+func copyStore(srcCr *pkgtypes.StateStoreCredentials, src *pkgtypes.StateStoreDetails, dstCr *pkgtypes.StateStoreCredentials, dst *pkgtypes.StateStoreDetails, region string, renames map[string]string) ([]pkgtypes.MigratedObject, error) {
+	srcStore, err := objectStorage.NewStore(src.Backend, srcCr, src, "")
+	if err != nil {
+		return nil, err
+	}
+
+	dstStore, err := objectStorage.NewStore(dst.Backend, dstCr, dst, region)
+	if err != nil {
+		return nil, err
+	}
+
+	return objectStorage.CopyStoreObjects(srcStore, dstStore, renames)
+}
+
+// rewriteGitopsBackends clones the gitops repository and points its terraform
+// at the new state store, returning the files that changed and the state
+// objects whose key differs on the new backend
+func rewriteGitopsBackends(cl *pkgtypes.Cluster, gitopsDir string, from, to backendTarget) ([]string, map[string]string, error) {
+	if err := os.RemoveAll(gitopsDir); err != nil {
+		return nil, nil, fmt.Errorf("error removing gitops dir %q: %w", gitopsDir, err)
+	}
+
+	if err := gitShim.PrepareGitEnvironment(cl, gitopsDir); err != nil {
+		return nil, nil, fmt.Errorf("error preparing gitops repository: %w", err)
+	}
+
+	changed := []string{}
+	renames := map[string]string{}
+	err := filepath.WalkDir(gitopsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".tf" {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("error reading %q: %w", path, err)
+		}
+
+		rewritten, fileRenames, ok := rewriteBackends(string(content), from, to)
+		if !ok {
+			return nil
+		}
+		if err := os.WriteFile(path, []byte(rewritten), 0o644); err != nil {
+			return fmt.Errorf("error writing %q: %w", path, err)
+		}
+		maps.Copy(renames, fileRenames)
+
+		rel, _ := filepath.Rel(gitopsDir, path)
+		changed = append(changed, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("error rewriting terraform backends: %w", err)
+	}
+
+	return changed, renames, nil
+}
+
+// pushGitopsBackends commits and pushes the rewritten terraform backends
+func pushGitopsBackends(cl *pkgtypes.Cluster, gitopsDir string, files []string, bucketName string) error {
+	if len(files) == 0 {
+		log.Info().Msgf("no terraform backends of cluster %s reference the state store", cl.ClusterName)
+		return nil
+	}
+
+	gitopsRepo, err := git.PlainOpen(gitopsDir)
+	if err != nil {
+		return fmt.Errorf("error opening gitops repo: %w", err)
+	}
+
+	err = gitClient.Commit(gitopsRepo, fmt.Sprintf("migrating terraform state store of cluster %s to bucket %s", cl.ClusterName, bucketName))
+	if err != nil {
+		return fmt.Errorf("error committing terraform backends: %w", err)
+	}
+
+	err = gitopsRepo.Push(&git.PushOptions{
+		RemoteName: "origin",
+		Auth: &githttps.BasicAuth{
+			Username: cl.GitAuth.User,
+			Password: cl.GitAuth.Token,
+		},
+		CABundle: gitHost.CABundle(cl.GitServer),
+	})
+	if err != nil {
+		return fmt.Errorf("error pushing terraform backends: %w", err)
+	}
+
+	return nil
+}
+
+// sourceDetails returns the details of the current state store of a cluster.
+// Google and azure clusters do not record the state store created with them.
+func sourceDetails(cl *pkgtypes.Cluster) pkgtypes.StateStoreDetails {
+	d := cl.StateStoreDetails
+	if d.Backend != "" {
+		return d
+	}
+
+	switch cl.CloudProvider {
+	case "google":
+		d.Backend = objectStorage.BackendGCS
+		if d.Name == "" {
+			d.Name = objectStorage.StateStoreBucketPrefix(cl.ClusterName) + cl.ClusterID
+		}
+	case "azure":
+		d.Backend = objectStorage.BackendAzure
+		if d.Name == "" {
+			d.Name = objectStorage.AzureTerraformContainer
+		}
+	default:
+		d.Backend = objectStorage.BackendS3
+	}
+	return d
+}
+
+// sourceCredentials returns the credentials of the current state store of a
+// cluster, google clusters reach theirs with the cloud credentials
+func sourceCredentials(cl *pkgtypes.Cluster, src *pkgtypes.StateStoreDetails) *pkgtypes.StateStoreCredentials {
+	cr := cl.StateStoreCredentials
+	if src.Backend == objectStorage.BackendGCS && cr.KeyFile == "" {
+		cr.KeyFile = cl.GoogleAuth.KeyFile
+	}
+	return &cr
+}
+
+// configured reports whether the bucket and the credentials of a state store
+// are known
+func configured(cr *pkgtypes.StateStoreCredentials, d *pkgtypes.StateStoreDetails) bool {
+	switch d.Backend {
+	case objectStorage.BackendGCS:
+		return d.Name != "" && cr.KeyFile != ""
+	case objectStorage.BackendAzure:
+		return d.Name != "" && cr.Name != "" && cr.SecretAccessKey != ""
+	}
+	return objectStorage.SupportsClusterObjects(cr, d)
+}
+
+// accountOf returns the storage account of azure state stores
+func accountOf(cr *pkgtypes.StateStoreCredentials, d *pkgtypes.StateStoreDetails) string {
+	if d.Backend == objectStorage.BackendAzure {
+		return cr.Name
+	}
+	return ""
+}
+
+// destinationDetails returns the state store details of the requested bucket
+func destinationDetails(req *pkgtypes.StateStoreMigrationRequest) pkgtypes.StateStoreDetails {
+	backend := req.Backend
+	if backend == "" {
+		backend = objectStorage.BackendS3
+	}
+
+	return pkgtypes.StateStoreDetails{
+		Name:     req.BucketName,
+		Hostname: req.Hostname,
+		Backend:  backend,
+	}
+}
@@ -1,38 +1,84 @@
 package telemetry
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/konstructio/kubefirst-api/internal/constants"
 	"github.com/konstructio/kubefirst-api/internal/env"
+	"github.com/konstructio/kubefirst-api/internal/health"
 	"github.com/konstructio/kubefirst-api/internal/secrets"
 	"github.com/konstructio/kubefirst-api/internal/utils"
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
 	"github.com/kubefirst/metrics-client/pkg/telemetry"
+	"github.com/rs/zerolog/log"
 )
 
-func Heartbeat(event telemetry.TelemetryEvent) {
-	telemetry.SendEvent(event, telemetry.KubefirstHeartbeat, "")
-	HeartbeatWorkloadClusters(event)
+// heartbeatInterval is the delay between heartbeats
+const heartbeatInterval = 5 * time.Minute
 
-	for range time.Tick(time.Second * 300) {
-		telemetry.SendEvent(event, telemetry.KubefirstHeartbeat, "")
-		HeartbeatWorkloadClusters(event)
+// Heartbeat periodically sends the heartbeat of the API and, outside of
+// cluster zero, of the workload clusters
+func Heartbeat(cfg *env.Config, event telemetry.TelemetryEvent, isClusterZero bool) {
+	beat := func() {
+		health.Beat(health.WorkerHeartbeat, heartbeatInterval)
+		SendEvent(clusterUsesTelemetry(event.ClusterID, cfg.Get().UseTelemetry), event, telemetry.KubefirstHeartbeat, "")
+		if !isClusterZero {
+			HeartbeatWorkloadClusters(event)
+		}
+	}
+
+	beat()
+	for range time.Tick(heartbeatInterval) {
+		beat()
 	}
 }
 
-func HeartbeatWorkloadClusters(event telemetry.TelemetryEvent) error {
-	env, _ := env.GetEnv(constants.SilenceGetEnv)
+// clusterUsesTelemetry reports whether telemetry is enabled on the record of a
+// cluster, or configured when no record matches, such as on cluster zero.
+// Nothing is sent when the records cannot be read, so a cluster that opted out
+// never leaks events.
+func clusterUsesTelemetry(clusterID string, configured bool) bool {
+	kcfg := utils.GetKubernetesClient("")
+	if kcfg == nil {
+		return false
+	}
 
-	if env.IsClusterZero {
-		return nil
+	clusters, err := secrets.GetClusters(kcfg.Clientset)
+	if err != nil {
+		log.Warn().Msgf("unable to read cluster records, skipping heartbeat: %s", err)
+		return false
 	}
 
+	return telemetryEnabled(clusters, clusterID, configured)
+}
+
+// telemetryEnabled returns the telemetry setting of the record of a cluster,
+// or configured when no record matches
+func telemetryEnabled(clusters []pkgtypes.Cluster, clusterID string, configured bool) bool {
+	for _, cluster := range clusters {
+		if cluster.ClusterID == clusterID {
+			return cluster.TelemetryEnabled()
+		}
+	}
+
+	return configured
+}
+
+func HeartbeatWorkloadClusters(event telemetry.TelemetryEvent) error {
 	kcfg := utils.GetKubernetesClient("")
+	if kcfg == nil {
+		return nil
+	}
 
-	clusters, _ := secrets.GetClusters(kcfg.Clientset)
+	clusters, err := secrets.GetClusters(kcfg.Clientset)
+	if err != nil {
+		return fmt.Errorf("unable to read cluster records: %w", err)
+	}
 
 	for _, cluster := range clusters {
-		if cluster.Status == constants.ClusterStatusProvisioned {
+		// workload clusters follow the telemetry setting of their management cluster
+		if cluster.Status == constants.ClusterStatusProvisioned && cluster.TelemetryEnabled() {
 			for _, workloadCluster := range cluster.WorkloadClusters {
 				if workloadCluster.Status == constants.ClusterStatusProvisioned {
 					telemetryEvent := telemetry.TelemetryEvent{
@@ -52,7 +98,7 @@ func HeartbeatWorkloadClusters(event telemetry.TelemetryEvent) error {
 						MetricName:        telemetry.KubefirstHeartbeat,
 					}
 
-					telemetry.SendEvent(telemetryEvent, telemetry.KubefirstHeartbeat, "")
+					SendEvent(cluster.TelemetryEnabled(), telemetryEvent, telemetry.KubefirstHeartbeat, "")
 				}
 			}
 		}
@@ -0,0 +1,41 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package telemetry
+
+import (
+	"testing"
+
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
+)
+
+func TestTelemetryEnabled(t *testing.T) {
+	optedOut := false
+	clusters := []pkgtypes.Cluster{
+		{ClusterID: "opted-in"},
+		{ClusterID: "opted-out", UseTelemetry: &optedOut},
+	}
+
+	tests := []struct {
+		name       string
+		clusterID  string
+		configured bool
+		want       bool
+	}{
+		{name: "record opted in", clusterID: "opted-in", configured: false, want: true},
+		{name: "record opted out", clusterID: "opted-out", configured: true, want: false},
+		{name: "no record, telemetry configured", clusterID: "cluster-zero", configured: true, want: true},
+		{name: "no record, telemetry disabled", clusterID: "cluster-zero", configured: false, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := telemetryEnabled(clusters, tt.clusterID, tt.configured); got != tt.want {
+				t.Errorf("telemetryEnabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
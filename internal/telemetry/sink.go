@@ -0,0 +1,228 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package telemetry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/konstructio/kubefirst-api/internal/env"
+	"github.com/konstructio/kubefirst-api/internal/httpCommon"
+	"github.com/kubefirst/metrics-client/pkg/telemetry"
+	"github.com/rs/zerolog/log"
+)
+
+// Sink names accepted by TELEMETRY_SINK
+const (
+	SinkUpstream = "upstream"
+	SinkFile     = "file"
+	SinkWebhook  = "webhook"
+	SinkNone     = "none"
+)
+
+// Sink delivers telemetry events
+type Sink interface {
+	Send(event telemetry.TelemetryEvent, metricName, errMsg string) error
+}
+
+// Record is an event as written by the file and webhook sinks, with the
+// properties sent upstream
+type Record struct {
+	Timestamp         time.Time `json:"timestamp"`
+	Event             string    `json:"event"`
+	UserID            string    `json:"user_id"`
+	CliVersion        string    `json:"cli_version"`
+	CloudProvider     string    `json:"cloud_provider"`
+	ClusterID         string    `json:"cluster_id"`
+	ClusterType       string    `json:"cluster_type"`
+	Domain            string    `json:"domain"`
+	GitProvider       string    `json:"git_provider"`
+	InstallMethod     string    `json:"install_method"`
+	Client            string    `json:"client"`
+	KubefirstTeam     string    `json:"kubefirst_team"`
+	KubefirstTeamInfo string    `json:"kubefirst_team_info"`
+	MachineID         string    `json:"machine_id"`
+	ParentClusterID   string    `json:"parent_cluster_id"`
+	Error             string    `json:"error,omitempty"`
+}
+
+var (
+	sinkMu  sync.RWMutex
+	current Sink = noopSink{}
+)
+
+// Configure installs the sink selected by the environment. No event is sent
+// anywhere when USE_TELEMETRY is false, whatever the sink.
+func Configure(e env.Env) error {
+	sink, err := NewSink(e)
+	if err != nil {
+		return err
+	}
+
+	sinkMu.Lock()
+	current = sink
+	sinkMu.Unlock()
+
+	return nil
+}
+
+// NewSink returns the sink selected by the environment
+func NewSink(e env.Env) (Sink, error) {
+	if !e.UseTelemetry {
+		log.Info().Msg("telemetry collection is disabled")
+		return noopSink{}, nil
+	}
+
+	switch e.TelemetrySink {
+	case SinkUpstream, "":
+		return upstreamSink{}, nil
+	case SinkFile:
+		path := e.TelemetryFile
+		if path == "" {
+			homeDir, err := os.UserHomeDir()
+			if err != nil {
+				return nil, fmt.Errorf("unable to get user home directory: %w", err)
+			}
+			path = filepath.Join(homeDir, ".k1", "telemetry.jsonl")
+		}
+		return &fileSink{path: path}, nil
+	case SinkWebhook:
+		if e.TelemetryWebhookURL == "" {
+			return nil, fmt.Errorf("TELEMETRY_WEBHOOK_URL is required by the %s telemetry sink", SinkWebhook)
+		}
+		return &webhookSink{url: e.TelemetryWebhookURL, client: httpCommon.CustomHTTPClient(false, 10*time.Second)}, nil
+	case SinkNone:
+		return noopSink{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported TELEMETRY_SINK %q, must be one of %s, %s, %s or %s", e.TelemetrySink, SinkUpstream, SinkFile, SinkWebhook, SinkNone)
+	}
+}
+
+// SendEvent delivers an event to the configured sink unless telemetry is
+// disabled for the cluster it describes. Delivery failures are logged only,
+// telemetry never fails an operation.
+func SendEvent(useTelemetry bool, event telemetry.TelemetryEvent, metricName, errMsg string) {
+	if !useTelemetry {
+		return
+	}
+
+	sinkMu.RLock()
+	sink := current
+	sinkMu.RUnlock()
+
+	if err := sink.Send(event, metricName, errMsg); err != nil {
+		log.Warn().Msgf("unable to send telemetry event %s: %s", metricName, err)
+	}
+}
+
+// NewRecord returns the record of an event
+func NewRecord(event telemetry.TelemetryEvent, metricName, errMsg string) Record {
+	return Record{
+		Timestamp:         time.Now().UTC(),
+		Event:             metricName,
+		UserID:            event.UserId,
+		CliVersion:        event.CliVersion,
+		CloudProvider:     event.CloudProvider,
+		ClusterID:         event.ClusterID,
+		ClusterType:       event.ClusterType,
+		Domain:            event.DomainName,
+		GitProvider:       event.GitProvider,
+		InstallMethod:     event.InstallMethod,
+		Client:            event.KubefirstClient,
+		KubefirstTeam:     event.KubefirstTeam,
+		KubefirstTeamInfo: event.KubefirstTeamInfo,
+		MachineID:         event.MachineID,
+		ParentClusterID:   event.ParentClusterId,
+		Error:             errMsg,
+	}
+}
+
+// upstreamSink sends events to the kubefirst telemetry endpoint
+type upstreamSink struct{}
+
+func (upstreamSink) Send(event telemetry.TelemetryEvent, metricName, errMsg string) error {
+	if err := telemetry.SendEvent(event, metricName, errMsg); err != nil {
+		return fmt.Errorf("error sending event upstream: %w", err)
+	}
+	return nil
+}
+
+// fileSink appends events to a local JSONL file
+type fileSink struct {
+	mu   sync.Mutex
+	path string
+}
+
+func (s *fileSink) Send(event telemetry.TelemetryEvent, metricName, errMsg string) error {
+	line, err := json.Marshal(NewRecord(event, metricName, errMsg))
+	if err != nil {
+		return fmt.Errorf("error marshalling event: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("unable to create directory %q: %w", filepath.Dir(s.path), err)
+	}
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("unable to open telemetry file %q: %w", s.path, err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("unable to write telemetry file %q: %w", s.path, err)
+	}
+
+	return nil
+}
+
+// webhookSink posts events as JSON to a webhook
+type webhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func (s *webhookSink) Send(event telemetry.TelemetryEvent, metricName, errMsg string) error {
+	body, err := json.Marshal(NewRecord(event, metricName, errMsg))
+	if err != nil {
+		return fmt.Errorf("error marshalling event: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body)) //nolint:noctx // client enforces limits
+	if err != nil {
+		return fmt.Errorf("error creating webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error posting event to webhook: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("webhook responded with status %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+// noopSink drops every event
+type noopSink struct{}
+
+func (noopSink) Send(telemetry.TelemetryEvent, string, string) error {
+	return nil
+}
@@ -0,0 +1,86 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package telemetry
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/konstructio/kubefirst-api/internal/env"
+	"github.com/kubefirst/metrics-client/pkg/telemetry"
+)
+
+func TestSendEvent(t *testing.T) {
+	var received atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var record Record
+		if err := json.NewDecoder(r.Body).Decode(&record); err != nil || record.Event != telemetry.KubefirstHeartbeat {
+			t.Errorf("unexpected webhook record %+v: %v", record, err)
+		}
+		received.Add(1)
+	}))
+	defer srv.Close()
+
+	file := filepath.Join(t.TempDir(), "telemetry.jsonl")
+	event := telemetry.TelemetryEvent{ClusterID: "abc123", MetricName: telemetry.KubefirstHeartbeat}
+
+	tests := []struct {
+		name             string
+		env              env.Env
+		useTelemetry     bool
+		wantWebhookCalls int32
+		wantFileLines    int
+		wantErr          bool
+	}{
+		{name: "webhook", env: env.Env{UseTelemetry: true, TelemetrySink: SinkWebhook, TelemetryWebhookURL: srv.URL}, useTelemetry: true, wantWebhookCalls: 1},
+		{name: "file", env: env.Env{UseTelemetry: true, TelemetrySink: SinkFile, TelemetryFile: file}, useTelemetry: true, wantFileLines: 1},
+		{name: "disabled for the cluster", env: env.Env{UseTelemetry: true, TelemetrySink: SinkWebhook, TelemetryWebhookURL: srv.URL}},
+		{name: "disabled globally", env: env.Env{TelemetrySink: SinkWebhook, TelemetryWebhookURL: srv.URL}, useTelemetry: true},
+		{name: "none", env: env.Env{UseTelemetry: true, TelemetrySink: SinkNone}, useTelemetry: true},
+		{name: "webhook without url", env: env.Env{UseTelemetry: true, TelemetrySink: SinkWebhook}, wantErr: true},
+		{name: "unknown sink", env: env.Env{UseTelemetry: true, TelemetrySink: "segment"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received.Store(0)
+			os.Remove(file)
+
+			err := Configure(tt.env)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Configure() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			defer Configure(env.Env{})
+
+			SendEvent(tt.useTelemetry, event, telemetry.KubefirstHeartbeat, "")
+
+			if got := received.Load(); got != tt.wantWebhookCalls {
+				t.Errorf("webhook calls = %d, want %d", got, tt.wantWebhookCalls)
+			}
+
+			lines := 0
+			if content, err := os.ReadFile(file); err == nil {
+				var record Record
+				if err := json.Unmarshal(content, &record); err != nil || record.ClusterID != event.ClusterID {
+					t.Errorf("unexpected file record %q: %v", content, err)
+				}
+				lines = 1
+			}
+			if lines != tt.wantFileLines {
+				t.Errorf("file lines = %d, want %d", lines, tt.wantFileLines)
+			}
+		})
+	}
+}
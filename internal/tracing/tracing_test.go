@@ -0,0 +1,67 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestRun(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	if got := TraceID(context.Background()); got != "" {
+		t.Errorf("TraceID() of an untraced context = %q, want empty", got)
+	}
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus codes.Code
+	}{
+		{name: "step succeeded", wantStatus: codes.Unset},
+		{name: "step failed", err: errors.New("terraform apply failed"), wantStatus: codes.Error},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, root := Start(context.Background(), "cluster.create")
+			defer root.End()
+
+			var stepTraceID string
+			err := Run(ctx, tt.name, func(ctx context.Context) error {
+				stepTraceID = TraceID(ctx)
+				return tt.err
+			})
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Run() error = %v, want %v", err, tt.err)
+			}
+			if stepTraceID == "" || stepTraceID != TraceID(ctx) {
+				t.Errorf("step trace id = %q, want %q", stepTraceID, TraceID(ctx))
+			}
+
+			spans := recorder.Ended()
+			span := spans[len(spans)-1]
+			if span.Name() != tt.name {
+				t.Errorf("span name = %q, want %q", span.Name(), tt.name)
+			}
+			if span.Parent().SpanID() != root.SpanContext().SpanID() {
+				t.Errorf("span parent = %s, want %s", span.Parent().SpanID(), root.SpanContext().SpanID())
+			}
+			if span.Status().Code != tt.wantStatus {
+				t.Errorf("span status = %v, want %v", span.Status().Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -6,9 +6,14 @@ See the LICENSE file for more details.
 */
 package types
 
-// JSONFailureResponse describes a failure message returned by the API
+// JSONFailureResponse describes a failure returned by the API, its status is
+// set by its code
 type JSONFailureResponse struct {
-	Message string `json:"error" example:"err"`
+	Message   string            `json:"error" example:"cluster \"dev\" not found"`
+	Code      string            `json:"code" example:"cluster_not_found"`
+	Details   map[string]string `json:"details,omitempty"`
+	Retryable bool              `json:"retryable"`
+	Provider  string            `json:"provider,omitempty" example:"aws"`
 }
 
 // JSONHealthResponse describes a message returned by the API health endpoint
@@ -3,6 +3,7 @@ package utils
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
 	pkg "github.com/konstructio/kubefirst-api/internal"
 	"github.com/konstructio/kubefirst-api/internal/downloadManager"
@@ -10,7 +11,7 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
-func DownloadTools(kubectlClientPath, kubectlClientVersion, localOs, localArchitecture, terraformClientVersion, toolsDirPath string) error {
+func DownloadTools(cache *downloadManager.ToolCache, kubectlClientPath, kubectlClientVersion, localOs, localArchitecture, terraformClientVersion, toolsDirPath string) error {
 	log.Info().Msg("starting downloads...")
 
 	// create folder if it doesn't exist
@@ -22,20 +23,8 @@ func DownloadTools(kubectlClientPath, kubectlClientVersion, localOs, localArchit
 	group := errgroup.Group{}
 
 	group.Go(func() error {
-		kubectlDownloadURL := fmt.Sprintf(
-			"https://dl.k8s.io/release/%s/bin/%s/%s/kubectl",
-			kubectlClientVersion,
-			localOs,
-			localArchitecture,
-		)
-		log.Info().Msgf("Downloading kubectl from: %s", kubectlDownloadURL)
-		err = downloadManager.DownloadFile(kubectlClientPath, kubectlDownloadURL)
-		if err != nil {
-			return fmt.Errorf("error downloading kubectl file: %w", err)
-		}
-
-		if err := os.Chmod(kubectlClientPath, 0o755); err != nil {
-			return fmt.Errorf("failed to chmod kubectl: %w", err)
+		if err := cache.Kubectl(kubectlClientVersion, localOs, localArchitecture, kubectlClientPath); err != nil {
+			return fmt.Errorf("error installing kubectl: %w", err)
 		}
 
 		kubectlStdOut, kubectlStdErr, err := pkg.ExecShellReturnStrings(kubectlClientPath, "version", "--client=true", "-oyaml")
@@ -49,36 +38,14 @@ func DownloadTools(kubectlClientPath, kubectlClientVersion, localOs, localArchit
 	})
 
 	group.Go(func() error {
-		terraformDownloadURL := fmt.Sprintf(
-			"https://releases.hashicorp.com/terraform/%s/terraform_%s_%s_%s.zip",
-			terraformClientVersion,
-			terraformClientVersion,
-			localOs,
-			localArchitecture,
-		)
-		log.Info().Msgf("Downloading terraform from %s", terraformDownloadURL)
-		terraformDownloadZipPath := fmt.Sprintf("%s/terraform.zip", toolsDirPath)
-		err = downloadManager.DownloadFile(terraformDownloadZipPath, terraformDownloadURL)
-		if err != nil {
-			return fmt.Errorf("error downloading terraform file: %w", err)
-		}
-
-		if err := downloadManager.Unzip(terraformDownloadZipPath, toolsDirPath); err != nil {
-			return fmt.Errorf("error unzipping terraform file: %w", err)
+		if err := cache.Terraform(terraformClientVersion, localOs, localArchitecture, filepath.Join(toolsDirPath, "terraform")); err != nil {
+			return fmt.Errorf("error installing terraform: %w", err)
 		}
 
 		if err := os.Chmod(toolsDirPath, 0o777); err != nil {
 			return fmt.Errorf("failed to chmod %q: %w", toolsDirPath, err)
 		}
 
-		if err := os.Chmod(fmt.Sprintf("%s/terraform", toolsDirPath), 0o755); err != nil {
-			return fmt.Errorf("failed to chmod %q: %w", fmt.Sprintf("%s/terraform", toolsDirPath), err)
-		}
-
-		if err := os.RemoveAll(fmt.Sprintf("%s/terraform.zip", toolsDirPath)); err != nil {
-			return fmt.Errorf("failed to remove terraform.zip: %w", err)
-		}
-
 		// todo output terraform client version to be consistent with others
 		log.Info().Msg("Terraform download finished")
 		return nil
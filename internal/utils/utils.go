@@ -12,11 +12,14 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"sync"
 	"time"
 
-	"github.com/konstructio/kubefirst-api/internal/constants"
+	"github.com/konstructio/kubefirst-api/internal/catalogMirror"
 	"github.com/konstructio/kubefirst-api/internal/env"
+	"github.com/konstructio/kubefirst-api/internal/health"
 	"github.com/konstructio/kubefirst-api/internal/k8s"
+	"github.com/konstructio/kubefirst-api/internal/metrics"
 	"github.com/konstructio/kubefirst-api/internal/secrets"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -105,16 +108,30 @@ var BackupResolver = &net.Resolver{
 	},
 }
 
+// gitopsCatalogInterval is the delay between gitops catalog refreshes
+const gitopsCatalogInterval = 30 * time.Minute
+
 // ScheduledGitopsCatalogUpdate
 func ScheduledGitopsCatalogUpdate() {
 	kcfg := GetKubernetesClient("")
 
+	health.Beat(health.WorkerGitopsCatalog, gitopsCatalogInterval)
 	err := secrets.UpdateGitopsCatalogApps(kcfg.Clientset)
+	metrics.ObserveCatalogRefresh(err)
 	if err != nil {
 		log.Warn().Msg(err.Error())
 	}
-	for range time.Tick(time.Minute * 30) {
+
+	// A mirror is immutable for the lifetime of the process, there is nothing to poll
+	if catalogMirror.Enabled() {
+		health.Done(health.WorkerGitopsCatalog)
+		return
+	}
+
+	for range time.Tick(gitopsCatalogInterval) {
+		health.Beat(health.WorkerGitopsCatalog, gitopsCatalogInterval)
 		err := secrets.UpdateGitopsCatalogApps(kcfg.Clientset)
+		metrics.ObserveCatalogRefresh(err)
 		if err != nil {
 			log.Warn().Msg(err.Error())
 		}
@@ -132,22 +149,44 @@ func ValidateAuthenticationFields(s map[string]string) error {
 	return nil
 }
 
+// kubernetesClientSettings locate the kubernetes api of cluster zero and of
+// the clusters created by the API
+type kubernetesClientSettings struct {
+	inCluster      bool
+	kubeconfigPath string
+}
+
+var (
+	kubernetesClientMu sync.RWMutex
+	kubernetesClient   kubernetesClientSettings
+)
+
+// ConfigureKubernetesClient sets how GetKubernetesClient reaches the
+// kubernetes api, it must run before any client is created
+func ConfigureKubernetesClient(e env.Env) {
+	kubernetesClientMu.Lock()
+	defer kubernetesClientMu.Unlock()
+
+	kubernetesClient = kubernetesClientSettings{inCluster: e.InCluster}
+	if e.K1LocalDebug {
+		kubernetesClient.kubeconfigPath = e.K1LocalKubeconfigPath
+	}
+}
+
 // GetKubernetesClient for cluster zero and existing cluster
 func GetKubernetesClient(clusterName string) *k8s.KubernetesClient {
-	// Get Environment variables
-	env, _ := env.GetEnv(constants.SilenceGetEnv)
+	kubernetesClientMu.RLock()
+	settings := kubernetesClient
+	kubernetesClientMu.RUnlock()
 
 	// Create Kubernetes Client Context
-	inCluster := env.InCluster
-	homeDir, _ := os.UserHomeDir()
-	clusterDir := fmt.Sprintf("%s/.k1/%s", homeDir, clusterName)
-	kubeconfigPath := fmt.Sprintf("%s/kubeconfig", clusterDir)
-
-	if env.K1LocalDebug {
-		kubeconfigPath = env.K1LocalKubeconfigPath
+	kubeconfigPath := settings.kubeconfigPath
+	if kubeconfigPath == "" {
+		homeDir, _ := os.UserHomeDir()
+		kubeconfigPath = fmt.Sprintf("%s/.k1/%s/kubeconfig", homeDir, clusterName)
 	}
 
-	kcfg, err := k8s.CreateKubeConfig(inCluster, kubeconfigPath)
+	kcfg, err := k8s.CreateKubeConfig(settings.inCluster, kubeconfigPath)
 	if err != nil {
 		log.Error().Msg(fmt.Errorf("error creating kubeconfig: %w", err).Error())
 	}
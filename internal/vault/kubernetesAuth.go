@@ -0,0 +1,300 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	vaultapi "github.com/hashicorp/vault/api"
+	"github.com/rs/zerolog/log"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// Kubernetes auth mount used by kubefirst-api for its own writes
+	APIAuthPath = "kubernetes-kubefirst-api"
+	// Policy and role granted to kubefirst-api
+	APIPolicyName = "kubefirst-api"
+	APIRoleName   = "kubefirst-api"
+	// Policy and role used for the secrets managed through the API key
+	AppSecretsPolicyName = "kubefirst-api-app-secrets"
+	AppSecretsRoleName   = "kubefirst-api-app-secrets"
+	// Service account kubefirst-api authenticates as, in the vault namespace
+	APIServiceAccountName = "kubefirst-api-vault"
+)
+
+// apiPolicy grants kubefirst-api the paths it writes after bootstrap:
+// platform and catalog app secrets, the kbot user credentials and the app
+// secrets policy, which follows the apps installed on the cluster
+const apiPolicy = `
+path "secret/data/*" {
+  capabilities = ["create", "read", "update"]
+}
+
+path "sys/policies/acl/` + AppSecretsPolicyName + `" {
+  capabilities = ["create", "update"]
+}
+
+path "users/data/*" {
+  capabilities = ["create", "read", "update", "patch"]
+}
+
+path "auth/userpass/users/+/password" {
+  capabilities = ["update"]
+}
+`
+
+// AppSecretsPolicy limits the secrets endpoints of the API to the secrets of
+// the catalog apps installed on a cluster, platform secrets stay out of reach
+// of the API key. Listing the mount root only returns key names.
+func AppSecretsPolicy(apps []string) string {
+	var b strings.Builder
+	b.WriteString(`
+path "secret/metadata/" {
+  capabilities = ["list"]
+}
+`)
+
+	for _, app := range apps {
+		for _, p := range []string{app, app + "/*"} {
+			fmt.Fprintf(&b, `
+path "secret/data/%[1]s" {
+  capabilities = ["create", "read", "update", "delete"]
+}
+
+path "secret/metadata/%[1]s" {
+  capabilities = ["list", "read", "delete"]
+}
+
+path "secret/delete/%[1]s" {
+  capabilities = ["update"]
+}
+
+path "secret/destroy/%[1]s" {
+  capabilities = ["update"]
+}
+`, p)
+		}
+	}
+
+	return b.String()
+}
+
+// apiTokenRequestSeconds is the lifetime of the service account token
+// exchanged for a vault token
+const apiTokenRequestSeconds int64 = 600
+
+// ConfigureAPIAuth enables a kubernetes auth method that issues tokens scoped
+// to the kubefirst-api policy to the kubefirst-api service account
+func (conf *Configuration) ConfigureAPIAuth(endpoint, rootToken string, clientset kubernetes.Interface) error {
+	ctx := context.Background()
+
+	_, err := clientset.CoreV1().ServiceAccounts(VaultNamespace).Create(ctx, &v1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: APIServiceAccountName, Namespace: VaultNamespace},
+	}, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("error creating service account %q: %w", APIServiceAccountName, err)
+	}
+
+	vaultClient, err := conf.newClient(endpoint, rootToken)
+	if err != nil {
+		return err
+	}
+
+	mounts, err := vaultClient.Sys().ListAuth()
+	if err != nil {
+		return fmt.Errorf("error listing vault auth methods: %w", err)
+	}
+	if _, ok := mounts[APIAuthPath+"/"]; !ok {
+		if err := vaultClient.Sys().EnableAuthWithOptions(APIAuthPath, &vaultapi.EnableAuthOptions{Type: "kubernetes"}); err != nil {
+			return fmt.Errorf("error enabling vault auth method %q: %w", APIAuthPath, err)
+		}
+	}
+
+	// vault runs in the cluster, it reviews tokens with its own service account
+	_, err = vaultClient.Logical().Write(fmt.Sprintf("auth/%s/config", APIAuthPath), map[string]interface{}{
+		"kubernetes_host": "https://kubernetes.default.svc",
+	})
+	if err != nil {
+		return fmt.Errorf("error configuring vault auth method %q: %w", APIAuthPath, err)
+	}
+
+	roles := []struct{ role, policy, rules string }{
+		{APIRoleName, APIPolicyName, apiPolicy},
+		{AppSecretsRoleName, AppSecretsPolicyName, AppSecretsPolicy(nil)},
+	}
+
+	for _, r := range roles {
+		if err := vaultClient.Sys().PutPolicy(r.policy, r.rules); err != nil {
+			return fmt.Errorf("error writing vault policy %q: %w", r.policy, err)
+		}
+
+		_, err = vaultClient.Logical().Write(fmt.Sprintf("auth/%s/role/%s", APIAuthPath, r.role), map[string]interface{}{
+			"bound_service_account_names":      []string{APIServiceAccountName},
+			"bound_service_account_namespaces": []string{VaultNamespace},
+			"token_policies":                   []string{r.policy},
+			"token_ttl":                        "15m",
+			"token_max_ttl":                    "1h",
+		})
+		if err != nil {
+			return fmt.Errorf("error writing vault role %q: %w", r.role, err)
+		}
+	}
+
+	log.Info().Msgf("configured vault auth method %s for kubefirst-api", APIAuthPath)
+	return nil
+}
+
+// APILogin exchanges a kubefirst-api service account token for a scoped vault token
+func (conf *Configuration) APILogin(endpoint string, clientset kubernetes.Interface) (string, error) {
+	return conf.login(endpoint, APIRoleName, clientset)
+}
+
+// login exchanges a kubefirst-api service account token for a token of role
+func (conf *Configuration) login(endpoint, role string, clientset kubernetes.Interface) (string, error) {
+	expiration := apiTokenRequestSeconds
+	tokenRequest, err := clientset.CoreV1().ServiceAccounts(VaultNamespace).CreateToken(context.Background(), APIServiceAccountName, &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{ExpirationSeconds: &expiration},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return "", fmt.Errorf("error requesting token for service account %q: %w", APIServiceAccountName, err)
+	}
+
+	vaultClient, err := conf.newClient(endpoint, "")
+	if err != nil {
+		return "", err
+	}
+
+	secret, err := vaultClient.Logical().Write(fmt.Sprintf("auth/%s/login", APIAuthPath), map[string]interface{}{
+		"role": role,
+		"jwt":  tokenRequest.Status.Token,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error logging in to vault with role %q: %w", role, err)
+	}
+	if secret == nil || secret.Auth == nil || secret.Auth.ClientToken == "" {
+		return "", errors.New("vault login returned no token")
+	}
+
+	return secret.Auth.ClientToken, nil
+}
+
+// APIToken returns a scoped vault token for the writes of kubefirst-api. Scoped
+// auth is configured with the root token on clusters bootstrapped before it
+// existed, the root token itself is never returned.
+func (conf *Configuration) APIToken(endpoint string, clientset kubernetes.Interface) (string, error) {
+	return conf.scopedToken(endpoint, APIRoleName, clientset)
+}
+
+// AppSecretsToken returns a vault token limited to the secrets of apps. The app
+// secrets policy is rewritten with apps first, the policies of a token are
+// resolved on every request so the token follows the installed apps.
+func (conf *Configuration) AppSecretsToken(endpoint string, apps []string, clientset kubernetes.Interface) (string, error) {
+	apiToken, err := conf.APIToken(endpoint, clientset)
+	if err != nil {
+		return "", err
+	}
+
+	vaultClient, err := conf.newClient(endpoint, apiToken)
+	if err != nil {
+		return "", err
+	}
+
+	policyErr := vaultClient.Sys().PutPolicy(AppSecretsPolicyName, AppSecretsPolicy(apps))
+	if policyErr != nil {
+		// the kubefirst-api policy of older clusters cannot write the app
+		// secrets policy, it is upgraded while the root token is still around
+		rootToken, err := readRootToken(clientset)
+		if err != nil || rootToken == "" {
+			return "", fmt.Errorf("error writing vault policy %q: %w", AppSecretsPolicyName, policyErr)
+		}
+		if err := conf.ConfigureAPIAuth(endpoint, rootToken, clientset); err != nil {
+			return "", err
+		}
+
+		rootClient, err := conf.newClient(endpoint, rootToken)
+		if err != nil {
+			return "", err
+		}
+		if err := rootClient.Sys().PutPolicy(AppSecretsPolicyName, AppSecretsPolicy(apps)); err != nil {
+			return "", fmt.Errorf("error writing vault policy %q: %w", AppSecretsPolicyName, err)
+		}
+	}
+
+	return conf.scopedToken(endpoint, AppSecretsRoleName, clientset)
+}
+
+// scopedToken logs in with role, configuring scoped auth first when the role
+// does not exist yet
+func (conf *Configuration) scopedToken(endpoint, role string, clientset kubernetes.Interface) (string, error) {
+	token, loginErr := conf.login(endpoint, role, clientset)
+	if loginErr == nil {
+		return token, nil
+	}
+
+	rootToken, err := readRootToken(clientset)
+	if err != nil || rootToken == "" {
+		return "", fmt.Errorf("unable to get a vault token: %w", loginErr)
+	}
+
+	log.Info().Msgf("scoped vault login failed, configuring vault auth method %s: %s", APIAuthPath, loginErr)
+	if err := conf.ConfigureAPIAuth(endpoint, rootToken, clientset); err != nil {
+		return "", fmt.Errorf("unable to get a vault token: %w", err)
+	}
+
+	return conf.login(endpoint, role, clientset)
+}
+
+// RevokeRootToken revokes the vault root token and removes it from the vault
+// unseal secret. It is a no-op when the secret no longer holds a root token.
+func (conf *Configuration) RevokeRootToken(endpoint string, clientset kubernetes.Interface) error {
+	ctx := context.Background()
+
+	secret, err := clientset.CoreV1().Secrets(VaultNamespace).Get(ctx, VaultSecretName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error getting secret %q: %w", VaultSecretName, err)
+	}
+
+	rootToken := string(secret.Data["root-token"])
+	if rootToken == "" {
+		log.Info().Msg("vault root token already revoked")
+		return nil
+	}
+
+	vaultClient, err := conf.newClient(endpoint, rootToken)
+	if err != nil {
+		return err
+	}
+
+	if err := vaultClient.Auth().Token().RevokeSelf(""); err != nil {
+		return fmt.Errorf("error revoking vault root token: %w", err)
+	}
+
+	delete(secret.Data, "root-token")
+	if _, err := clientset.CoreV1().Secrets(VaultNamespace).Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("vault root token was revoked but could not be removed from secret %q: %w", VaultSecretName, err)
+	}
+
+	log.Info().Msg("revoked vault root token")
+	return nil
+}
+
+// readRootToken returns the root token from the vault unseal secret, if any
+func readRootToken(clientset kubernetes.Interface) (string, error) {
+	secret, err := clientset.CoreV1().Secrets(VaultNamespace).Get(context.Background(), VaultSecretName, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("error getting secret %q: %w", VaultSecretName, err)
+	}
+	return string(secret.Data["root-token"]), nil
+}
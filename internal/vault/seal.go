@@ -0,0 +1,23 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package vault
+
+import (
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
+)
+
+// UsesAutoUnseal reports whether vault of a cluster is initialized with
+// recovery keys rather than shamir unseal keys. The aws and google gitops
+// templates configure a kms seal with the key created by the cloud terraform.
+func UsesAutoUnseal(cl *pkgtypes.Cluster) bool {
+	switch cl.CloudProvider {
+	case "aws", "google":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,203 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package vault
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	vaultapi "github.com/hashicorp/vault/api"
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
+)
+
+// SecretsMount is the kv v2 mount holding platform and catalog app secrets
+const SecretsMount = "secret"
+
+// ListSecrets returns the secrets and folders below path in the secrets mount
+func (conf *Configuration) ListSecrets(endpoint, token, path string) (*pkgtypes.VaultSecretList, error) {
+	vaultClient, err := conf.newClient(endpoint, token)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &pkgtypes.VaultSecretList{Path: path, Keys: []string{}}
+
+	secret, err := vaultClient.Logical().List(fmt.Sprintf("%s/metadata/%s", SecretsMount, path))
+	if err != nil {
+		return nil, fmt.Errorf("error listing secrets at %q: %w", path, err)
+	}
+	if secret == nil || secret.Data == nil {
+		return res, nil
+	}
+
+	keys, _ := secret.Data["keys"].([]interface{})
+	for _, key := range keys {
+		if k, ok := key.(string); ok {
+			res.Keys = append(res.Keys, k)
+		}
+	}
+	sort.Strings(res.Keys)
+
+	return res, nil
+}
+
+// GetSecret returns a version of a secret with its version history, the
+// current version is returned when version is 0
+func (conf *Configuration) GetSecret(endpoint, token, path string, version int) (*pkgtypes.VaultSecret, error) {
+	vaultClient, err := conf.newClient(endpoint, token)
+	if err != nil {
+		return nil, err
+	}
+	kv := vaultClient.KVv2(SecretsMount)
+	ctx := context.Background()
+
+	var secret *vaultapi.KVSecret
+	if version > 0 {
+		secret, err = kv.GetVersion(ctx, path, version)
+	} else {
+		secret, err = kv.Get(ctx, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting secret %q: %w", path, err)
+	}
+
+	metadata, err := kv.GetMetadata(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("error getting metadata of secret %q: %w", path, err)
+	}
+
+	res := &pkgtypes.VaultSecret{
+		Path:     path,
+		Data:     secret.Data,
+		Metadata: convertMetadata(metadata),
+	}
+	if secret.VersionMetadata != nil {
+		res.Version = secret.VersionMetadata.Version
+	}
+
+	return res, nil
+}
+
+// PutSecret writes a new version of a secret and returns its version. When
+// checkAndSet is set, the write only succeeds if it matches the current version.
+func (conf *Configuration) PutSecret(endpoint, token, path string, data map[string]interface{}, checkAndSet *int) (int, error) {
+	vaultClient, err := conf.newClient(endpoint, token)
+	if err != nil {
+		return 0, err
+	}
+
+	var opts []vaultapi.KVOption
+	if checkAndSet != nil {
+		opts = append(opts, vaultapi.WithCheckAndSet(*checkAndSet))
+	}
+
+	secret, err := vaultClient.KVv2(SecretsMount).Put(context.Background(), path, data, opts...)
+	if err != nil {
+		return 0, fmt.Errorf("error writing secret %q: %w", path, err)
+	}
+	if secret.VersionMetadata == nil {
+		return 0, nil
+	}
+
+	return secret.VersionMetadata.Version, nil
+}
+
+// DeleteSecret deletes versions of a secret, the current version when versions
+// is empty. Deleted versions can be recovered unless destroy is set; destroying
+// without versions removes the secret with its whole history.
+func (conf *Configuration) DeleteSecret(endpoint, token, path string, versions []int, destroy bool) error {
+	vaultClient, err := conf.newClient(endpoint, token)
+	if err != nil {
+		return err
+	}
+	kv := vaultClient.KVv2(SecretsMount)
+	ctx := context.Background()
+
+	switch {
+	case destroy && len(versions) == 0:
+		err = kv.DeleteMetadata(ctx, path)
+	case destroy:
+		err = kv.Destroy(ctx, path, versions)
+	case len(versions) > 0:
+		err = kv.DeleteVersions(ctx, path, versions)
+	default:
+		err = kv.Delete(ctx, path)
+	}
+	if err != nil {
+		return fmt.Errorf("error deleting secret %q: %w", path, err)
+	}
+
+	return nil
+}
+
+// CleanSecretPath normalizes a secret path relative to the secrets mount and
+// reports whether it is valid
+func CleanSecretPath(path string) (string, bool) {
+	path = strings.TrimPrefix(path, "/")
+	for _, segment := range strings.Split(strings.TrimSuffix(path, "/"), "/") {
+		if segment == "." || segment == ".." || (segment == "" && path != "") {
+			return "", false
+		}
+	}
+	return path, true
+}
+
+// IsAppSecretPath reports whether a clean secret path is the secret of one of
+// apps or below it. Catalog apps keep their secrets at the app name.
+func IsAppSecretPath(path string, apps []string) bool {
+	app, _, _ := strings.Cut(path, "/")
+	return app != "" && slices.Contains(apps, app)
+}
+
+// FilterAppSecrets returns the keys of a listing of the mount root that belong
+// to one of apps
+func FilterAppSecrets(keys, apps []string) []string {
+	filtered := []string{}
+	for _, key := range keys {
+		if slices.Contains(apps, strings.TrimSuffix(key, "/")) {
+			filtered = append(filtered, key)
+		}
+	}
+	return filtered
+}
+
+// convertMetadata returns the version history of a secret, oldest first
+func convertMetadata(metadata *vaultapi.KVMetadata) *pkgtypes.VaultSecretMetadata {
+	if metadata == nil {
+		return nil
+	}
+
+	res := &pkgtypes.VaultSecretMetadata{
+		CurrentVersion: metadata.CurrentVersion,
+		OldestVersion:  metadata.OldestVersion,
+		MaxVersions:    metadata.MaxVersions,
+		CreatedTime:    metadata.CreatedTime,
+		UpdatedTime:    metadata.UpdatedTime,
+		Versions:       make([]pkgtypes.VaultSecretVersion, 0, len(metadata.Versions)),
+	}
+
+	for _, v := range metadata.Versions {
+		version := pkgtypes.VaultSecretVersion{
+			Version:     v.Version,
+			CreatedTime: v.CreatedTime,
+			Destroyed:   v.Destroyed,
+		}
+		if !v.DeletionTime.IsZero() {
+			deletionTime := v.DeletionTime
+			version.DeletionTime = &deletionTime
+		}
+		res.Versions = append(res.Versions, version)
+	}
+	sort.Slice(res.Versions, func(i, j int) bool {
+		return res.Versions[i].Version < res.Versions[j].Version
+	})
+
+	return res
+}
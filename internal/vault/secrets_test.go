@@ -0,0 +1,93 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package vault
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCleanSecretPath(t *testing.T) {
+	tests := []struct {
+		path  string
+		want  string
+		valid bool
+	}{
+		{path: "/", want: "", valid: true},
+		{path: "", want: "", valid: true},
+		{path: "/metaphor", want: "metaphor", valid: true},
+		{path: "/metaphor/database", want: "metaphor/database", valid: true},
+		{path: "/metaphor/", want: "metaphor/", valid: true},
+		{path: "/metaphor//database", valid: false},
+		{path: "/../sys/policy", valid: false},
+		{path: "/metaphor/./database", valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, valid := CleanSecretPath(tt.path)
+			if valid != tt.valid {
+				t.Fatalf("CleanSecretPath(%q) valid = %v, want %v", tt.path, valid, tt.valid)
+			}
+			if got != tt.want {
+				t.Errorf("CleanSecretPath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsAppSecretPath(t *testing.T) {
+	apps := []string{"metaphor", "cert-manager-webhook"}
+
+	tests := map[string]bool{
+		"":                          false,
+		"metaphor":                  true,
+		"metaphor/":                 true,
+		"metaphor/database":         true,
+		"cert-manager-webhook":      true,
+		"metaphor-development":      false,
+		"atlantis":                  false,
+		"apps/metaphor":             false,
+		"cert-manager-webhook-keys": false,
+	}
+
+	for path, want := range tests {
+		if got := IsAppSecretPath(path, apps); got != want {
+			t.Errorf("IsAppSecretPath(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestFilterAppSecrets(t *testing.T) {
+	keys := []string{"atlantis", "ci-secrets", "metaphor", "metaphor/", "users/"}
+
+	got := FilterAppSecrets(keys, []string{"metaphor", "users-app"})
+	want := []string{"metaphor", "metaphor/"}
+	if !slices.Equal(got, want) {
+		t.Errorf("FilterAppSecrets() = %v, want %v", got, want)
+	}
+}
+
+func TestAppSecretsPolicy(t *testing.T) {
+	policy := AppSecretsPolicy([]string{"metaphor"})
+
+	for _, path := range []string{
+		`path "secret/metadata/" {`,
+		`path "secret/data/metaphor" {`,
+		`path "secret/data/metaphor/*" {`,
+		`path "secret/destroy/metaphor/*" {`,
+	} {
+		if !strings.Contains(policy, path) {
+			t.Errorf("policy is missing %s", path)
+		}
+	}
+
+	if strings.Contains(AppSecretsPolicy(nil), "secret/data/") {
+		t.Error("policy without apps grants access to secret data")
+	}
+}
@@ -17,11 +17,51 @@ import (
 
 // GetUserPassword retrieves the password for a Vault user at the users mount path
 func (conf *Configuration) GetUserPassword(endpoint string, token string, username string, key string) (string, error) {
+	vaultClient, err := conf.newClient(endpoint, token)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := vaultClient.KVv2("users").Get(context.Background(), username)
+	if err != nil {
+		return "", fmt.Errorf("error getting user %q: %w", username, err)
+	}
+
+	return resp.Data[key].(string), nil
+}
+
+// SetUserPassword changes the password of a Vault userpass user and records the
+// new password at key in the users mount path
+func (conf *Configuration) SetUserPassword(endpoint string, token string, username string, key string, password string) error {
+	vaultClient, err := conf.newClient(endpoint, token)
+	if err != nil {
+		return err
+	}
+
+	_, err = vaultClient.Logical().Write(fmt.Sprintf("auth/userpass/users/%s/password", username), map[string]interface{}{
+		"password": password,
+	})
+	if err != nil {
+		return fmt.Errorf("error setting password of user %q: %w", username, err)
+	}
+
+	_, err = vaultClient.KVv2("users").Patch(context.Background(), username, map[string]interface{}{
+		key: password,
+	})
+	if err != nil {
+		return fmt.Errorf("error storing password of user %q: %w", username, err)
+	}
+
+	log.Info().Msgf("updated password of vault user %s", username)
+	return nil
+}
+
+func (conf *Configuration) newClient(endpoint string, token string) (*vaultapi.Client, error) {
 	conf.Config.Address = endpoint
 
 	vaultClient, err := vaultapi.NewClient(conf.Config)
 	if err != nil {
-		return "", fmt.Errorf("error creating vault client: %w", err)
+		return nil, fmt.Errorf("error creating vault client: %w", err)
 	}
 
 	vaultClient.SetToken(token)
@@ -32,11 +72,5 @@ func (conf *Configuration) GetUserPassword(endpoint string, token string, userna
 	}
 
 	log.Info().Msg("created vault client")
-
-	resp, err := vaultClient.KVv2("users").Get(context.Background(), username)
-	if err != nil {
-		return "", fmt.Errorf("error getting user %q: %w", username, err)
-	}
-
-	return resp.Data[key].(string), nil
+	return vaultClient, nil
 }
@@ -8,14 +8,16 @@ package vultr
 
 import (
 	"context"
+	"net/http"
 
+	"github.com/konstructio/kubefirst-api/internal/httpCommon"
 	"github.com/vultr/govultr/v3"
 	"golang.org/x/oauth2"
 )
 
 func NewVultr(vultrAPIKey string) *govultr.Client {
 	config := &oauth2.Config{}
-	ctx := context.Background()
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{Transport: httpCommon.TracedTransport()})
 	ts := config.TokenSource(ctx, &oauth2.Token{AccessToken: vultrAPIKey})
 	vultrClient := govultr.NewClient(oauth2.NewClient(ctx, ts))
 
@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/konstructio/kubefirst-api/internal/httpCommon"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
 	"github.com/rs/zerolog/log"
@@ -113,8 +114,9 @@ func (c *Configuration) CreateObjectStorageBucket(cr BucketCredentials, bucketNa
 
 	// Initialize minio client object.
 	minioClient, err := minio.New(cr.Endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(cr.AccessKey, cr.SecretAccessKey, ""),
-		Secure: useSSL,
+		Creds:     credentials.NewStaticV4(cr.AccessKey, cr.SecretAccessKey, ""),
+		Secure:    useSSL,
+		Transport: httpCommon.TracedTransport(),
 	})
 	if err != nil {
 		return fmt.Errorf("error initializing minio client for vultr with endpoint %q: %w", cr.Endpoint, err)
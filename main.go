@@ -7,14 +7,22 @@ See the LICENSE file for more details.
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/konstructio/kubefirst-api/docs"
+	"github.com/konstructio/kubefirst-api/internal/catalogMirror"
 	"github.com/konstructio/kubefirst-api/internal/env"
+	"github.com/konstructio/kubefirst-api/internal/httpCommon"
+	"github.com/konstructio/kubefirst-api/internal/metrics"
 	api "github.com/konstructio/kubefirst-api/internal/router"
 	"github.com/konstructio/kubefirst-api/internal/secrets"
 	"github.com/konstructio/kubefirst-api/internal/services"
+	"github.com/konstructio/kubefirst-api/internal/ssl"
 	apitelemetry "github.com/konstructio/kubefirst-api/internal/telemetry"
+	"github.com/konstructio/kubefirst-api/internal/tracing"
 	"github.com/konstructio/kubefirst-api/internal/utils"
 	"github.com/konstructio/kubefirst-api/pkg/types"
 	"github.com/kubefirst/metrics-client/pkg/telemetry"
@@ -30,10 +38,37 @@ import (
 //	@BasePath		/api/v1
 
 func main() {
-	env, err := env.GetEnv(false)
+	cfg, err := loadConfig()
 	if err != nil {
 		log.Fatal().Msg(err.Error())
 	}
+	go reloadOnSignal(cfg)
+
+	env := cfg.Get()
+	utils.ConfigureKubernetesClient(env)
+	catalogMirror.Configure(env)
+
+	err = httpCommon.ConfigureOutbound(httpCommon.Outbound{
+		ProxyURL:      env.OutboundProxyURL,
+		NoProxy:       env.OutboundNoProxy,
+		CABundle:      env.OutboundCABundle,
+		InsecureHosts: env.OutboundInsecureHosts,
+		HostCABundles: env.OutboundHostCABundles,
+	})
+	if err != nil {
+		log.Fatal().Msgf("error configuring outbound connections: %s", err)
+	}
+
+	err = apitelemetry.Configure(env)
+	if err != nil {
+		log.Fatal().Msgf("error configuring telemetry: %s", err)
+	}
+
+	shutdownTracing, err := tracing.Setup(env)
+	if err != nil {
+		log.Fatal().Msgf("error configuring tracing: %s", err)
+	}
+	defer shutdownTracing(context.Background())
 
 	if env.IsClusterZero {
 		log.Info().Msg("IS_CLUSTER_ZERO is set to true, skipping import cluster logic")
@@ -50,7 +85,7 @@ func main() {
 			}
 
 			if importedCluster.PostInstallCatalogApps != nil {
-				go importResourcesInCluster(importedCluster)
+				go importResourcesInCluster(env, importedCluster)
 			}
 		}
 	}
@@ -84,31 +119,86 @@ func main() {
 	if !env.IsClusterZero {
 		// Subroutine to automatically update gitops catalog
 		go utils.ScheduledGitopsCatalogUpdate()
+		// Subroutine to back up tls certificates to the cluster state stores
+		go ssl.ScheduledBackup(env.TLSBackupInterval)
+		// Subroutine to flag expiring tls certificates on the cluster records
+		go ssl.MonitorCertificateExpiry(env.CertificateExpiryCheckInterval, env.CertificateExpiryWindow)
 	}
-	go apitelemetry.Heartbeat(telemetryEvent)
+	go apitelemetry.Heartbeat(cfg, telemetryEvent, env.IsClusterZero)
+
+	// Cluster status gauges read from the stored records on every scrape
+	metrics.RegisterClusterCollector(func() ([]types.Cluster, error) {
+		kcfg := utils.GetKubernetesClient("")
+		if kcfg == nil {
+			return nil, errors.New("unable to create kubernetes client")
+		}
+		return secrets.GetClusters(kcfg.Clientset)
+	})
 
 	// API
-	r := api.SetupRouter()
+	r := api.SetupRouter(cfg)
 
-	err = r.Run(fmt.Sprintf(":%v", env.ServerPort))
-	if err != nil {
-		log.Fatal().Msgf("Error starting API: %s", err)
+	if err := serve(r, env); err != nil {
+		log.Fatal().Msg(err.Error())
 	}
 }
 
-func importResourcesInCluster(importedCluster *types.Cluster) {
+func importResourcesInCluster(e env.Env, importedCluster *types.Cluster) {
+	request := &types.GitopsCatalogAppBulkCreateRequest{
+		User: "kbot",
+	}
+
+	kcfg := utils.GetKubernetesClient(importedCluster.ClusterName)
+
+	// the catalog declares the dependencies between the apps, the import
+	// payload only carries the keys. The scheduled refresh may not have
+	// stored the catalog yet.
+	catalogApps, err := secrets.GetGitopsCatalogApps(kcfg.Clientset)
+	if err != nil {
+		if err := secrets.UpdateGitopsCatalogApps(kcfg.Clientset); err != nil {
+			log.Error().Msgf("error loading the gitops catalog for post install catalog applications: %s", err)
+			return
+		}
+		if catalogApps, err = secrets.GetGitopsCatalogApps(kcfg.Clientset); err != nil {
+			log.Error().Msgf("error reading the gitops catalog for post install catalog applications: %s", err)
+			return
+		}
+	}
+
+	appDefs := []types.GitopsCatalogApp{}
 	for _, catalogApp := range importedCluster.PostInstallCatalogApps {
-		log.Info().Msgf("installing catalog application %s", catalogApp.Name)
+		if slices.ContainsFunc(request.Apps, func(e types.GitopsCatalogAppBulkCreateEntry) bool { return e.Name == catalogApp.Name }) {
+			log.Warn().Msgf("post install catalog application %s is listed more than once, installing it once", catalogApp.Name)
+			continue
+		}
+
+		i := slices.IndexFunc(catalogApps.Apps, func(app types.GitopsCatalogApp) bool { return app.Name == catalogApp.Name })
+		if i < 0 {
+			log.Error().Msgf("post install catalog application %s is not in the gitops catalog, skipping it", catalogApp.Name)
+			continue
+		}
 
-		request := &types.GitopsCatalogAppCreateRequest{
-			User:       "kbot",
+		appDefs = append(appDefs, catalogApps.Apps[i])
+		request.Apps = append(request.Apps, types.GitopsCatalogAppBulkCreateEntry{
+			Name:       catalogApp.Name,
 			SecretKeys: catalogApp.SecretKeys,
 			ConfigKeys: catalogApp.ConfigKeys,
-		}
+		})
+	}
 
-		err := services.CreateService(importedCluster, catalogApp.Name, &catalogApp, request, true)
-		if err != nil {
-			log.Info().Msgf("Error creating default environments %s", err.Error())
+	log.Info().Msgf("installing %d post install catalog applications", len(request.Apps))
+
+	results, err := services.CreateServices(context.Background(), e, importedCluster, appDefs, request, true)
+	if err != nil {
+		log.Error().Msgf("error installing post install catalog applications: %s", err)
+		return
+	}
+
+	for _, result := range results {
+		if result.Error != "" {
+			log.Error().Msgf("catalog application %s (wave %d) %s: %s", result.Name, result.Wave, result.Status, result.Error)
+			continue
 		}
+		log.Info().Msgf("catalog application %s (wave %d) %s", result.Name, result.Wave, result.Status)
 	}
 }
@@ -9,6 +9,7 @@ package aws
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
 	pkg "github.com/konstructio/kubefirst-api/internal"
 	"github.com/konstructio/kubefirst-api/internal/downloadManager"
@@ -17,7 +18,7 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
-func DownloadTools(awsConfig *providerConfigs.ProviderConfig, kubectlClientVersion string, terraformClientVersion string) error {
+func DownloadTools(cache *downloadManager.ToolCache, awsConfig *providerConfigs.ProviderConfig, kubectlClientVersion string, terraformClientVersion string) error {
 	log.Info().Msg("starting downloads...")
 
 	// create folder if it doesn't exist
@@ -29,20 +30,8 @@ func DownloadTools(awsConfig *providerConfigs.ProviderConfig, kubectlClientVersi
 	eg := errgroup.Group{}
 
 	eg.Go(func() error {
-		kubectlDownloadURL := fmt.Sprintf(
-			"https://dl.k8s.io/release/%s/bin/%s/%s/kubectl",
-			kubectlClientVersion,
-			pkg.LocalhostOS,
-			pkg.LocalhostARCH,
-		)
-		log.Info().Msgf("Downloading kubectl from: %s", kubectlDownloadURL)
-		err = downloadManager.DownloadFile(awsConfig.KubectlClient, kubectlDownloadURL)
-		if err != nil {
-			return fmt.Errorf("error downloading kubectl file: %w", err)
-		}
-
-		if err := os.Chmod(awsConfig.KubectlClient, 0o755); err != nil {
-			return fmt.Errorf("failed to chmod kubectl: %w", err)
+		if err := cache.Kubectl(kubectlClientVersion, pkg.LocalhostOS, pkg.LocalhostARCH, awsConfig.KubectlClient); err != nil {
+			return fmt.Errorf("error installing kubectl: %w", err)
 		}
 
 		kubectlStdOut, kubectlStdErr, err := pkg.ExecShellReturnStrings(awsConfig.KubectlClient, "version", "--client=true", "-oyaml")
@@ -56,36 +45,15 @@ func DownloadTools(awsConfig *providerConfigs.ProviderConfig, kubectlClientVersi
 	})
 
 	eg.Go(func() error {
-		terraformDownloadURL := fmt.Sprintf(
-			"https://releases.hashicorp.com/terraform/%s/terraform_%s_%s_%s.zip",
-			terraformClientVersion,
-			terraformClientVersion,
-			pkg.LocalhostOS,
-			pkg.LocalhostARCH,
-		)
-		log.Info().Msgf("Downloading terraform from %s", terraformDownloadURL)
-		terraformDownloadZipPath := fmt.Sprintf("%s/terraform.zip", awsConfig.ToolsDir)
-		err = downloadManager.DownloadFile(terraformDownloadZipPath, terraformDownloadURL)
-		if err != nil {
-			return fmt.Errorf("error downloading terraform file, %w", err)
+		if err := cache.Terraform(terraformClientVersion, pkg.LocalhostOS, pkg.LocalhostARCH, filepath.Join(awsConfig.ToolsDir, "terraform")); err != nil {
+			return fmt.Errorf("error installing terraform: %w", err)
 		}
 
-		downloadManager.Unzip(terraformDownloadZipPath, awsConfig.ToolsDir)
-
-		err = os.Chmod(awsConfig.ToolsDir, 0o777)
+		err := os.Chmod(awsConfig.ToolsDir, 0o777)
 		if err != nil {
 			return fmt.Errorf("failed to chmod %q: %w", awsConfig.ToolsDir, err)
 		}
 
-		err = os.Chmod(fmt.Sprintf("%s/terraform", awsConfig.ToolsDir), 0o755)
-		if err != nil {
-			return fmt.Errorf("failed to chmod %q: %w", awsConfig.ToolsDir, err)
-		}
-		err = os.RemoveAll(fmt.Sprintf("%s/terraform.zip", awsConfig.ToolsDir))
-		if err != nil {
-			return fmt.Errorf("failed to remove terraform.zip: %w", err)
-		}
-
 		log.Info().Msg("Terraform download finished")
 		return nil
 	})
@@ -13,7 +13,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/konstructio/kubefirst-api/internal/httpCommon"
 	"github.com/konstructio/kubefirst-api/pkg/reports"
@@ -22,12 +25,72 @@ import (
 
 const letsDebugHost = "https://letsdebug.net/certwatch-query"
 
+const (
+	// WeeklyCertificateLimit is the number of certificates LetsEncrypt issues
+	// per registered domain per week
+	WeeklyCertificateLimit = 50
+	// DuplicateCertificateLimit is the number of certificates LetsEncrypt
+	// issues for the same names per week
+	DuplicateCertificateLimit = 5
+
+	rateLimitWindow = 7 * 24 * time.Hour
+)
+
+// platformHosts are the subdomains that receive a LetsEncrypt certificate
+// when a cluster is provisioned
+var platformHosts = []string{
+	"argocd",
+	"argo",
+	"atlantis",
+	"chartmuseum",
+	"kubefirst",
+	"vault",
+	"metaphor-development",
+	"metaphor-staging",
+	"metaphor-production",
+}
+
+// PlatformIngressHosts returns the ingress hosts of a cluster served under domain
+func PlatformIngressHosts(domain string) []string {
+	hosts := make([]string, 0, len(platformHosts))
+	for _, host := range platformHosts {
+		hosts = append(hosts, fmt.Sprintf("%s.%s", host, domain))
+	}
+	return hosts
+}
+
 // CheckCertificateUsage polls letsdebug to get information about used certificates
 func CheckCertificateUsage(domain string) error {
+	params, err := getCertificates(domain)
+	if err != nil {
+		return err
+	}
+
+	// Print
+	messageHeader := fmt.Sprintf("LetsEncrypt Certificate Usage\n\nWeekly usage summary for domain %s", domain)
+	message := printLetsEncryptCertData(messageHeader, params, false)
+	fmt.Println(reports.StyleMessage(message))
+
+	return nil
+}
+
+// GetCertificateUsage polls letsdebug and summarizes the certificates issued
+// for domain in the current rate limit window
+func GetCertificateUsage(domain string) (*CertificateUsage, error) {
+	params, err := getCertificates(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	return summarizeUsage(domain, params), nil
+}
+
+// getCertificates retrieves the certificates issued for domain in the last week
+func getCertificates(domain string) ([]CertificateDetail, error) {
 	// Retrieve response from letsdebug regarding used certificates
 	req, err := http.NewRequest(http.MethodGet, letsDebugHost, nil)
 	if err != nil {
-		return fmt.Errorf("failed to create HTTP request for certificate usage: %w", err)
+		return nil, fmt.Errorf("failed to create HTTP request for certificate usage: %w", err)
 	}
 	query := fmt.Sprintf(`WITH ci AS ( SELECT min(sub.CERTIFICATE_ID) ID, min(sub.ISSUER_CA_ID) ISSUER_CA_ID, sub.CERTIFICATE DER FROM (SELECT * FROM certificate_and_identities cai WHERE plainto_tsquery('%s') @@ identities(cai.CERTIFICATE) AND cai.NAME_VALUE ILIKE ('%%' || '%s' || '%%') LIMIT 10000 ) sub GROUP BY sub.CERTIFICATE ) SELECT ci.ID crtsh_id, ci.DER der FROM ci LEFT JOIN LATERAL ( SELECT min(ctle.ENTRY_TIMESTAMP) ENTRY_TIMESTAMP FROM ct_log_entry ctle WHERE ctle.CERTIFICATE_ID = ci.ID ) le ON TRUE, ca WHERE ci.ISSUER_CA_ID = ca.ID AND x509_notBefore(ci.DER) >= NOW() - INTERVAL '169 hours' AND ci.ISSUER_CA_ID IN (16418, 183267, 183283) ORDER BY le.ENTRY_TIMESTAMP DESC;`,
 		domain,
@@ -39,14 +102,18 @@ func CheckCertificateUsage(domain string) error {
 
 	resp, err := httpCommon.CustomHTTPClient(false).Do(req)
 	if err != nil {
-		return fmt.Errorf("unable to check certificate usage for domain %q: %w", domain, err)
+		return nil, fmt.Errorf("unable to check certificate usage for domain %q: %w", domain, err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to check certificate usage for domain %q: unexpected status %s", domain, resp.Status)
+	}
+
 	// Decode response into struct
 	var output Response
 	if err := json.NewDecoder(resp.Body).Decode(&output); err != nil {
-		return fmt.Errorf("unable to decode response for domain %q: %w", domain, err)
+		return nil, fmt.Errorf("unable to decode response for domain %q: %w", domain, err)
 	}
 
 	// Iterate over returned certificates
@@ -55,12 +122,15 @@ func CheckCertificateUsage(domain string) error {
 		sDec, err := base64.StdEncoding.DecodeString(result.Der)
 		if err != nil {
 			log.Error().Msgf("unable to decode certificate: %s", err)
-			return fmt.Errorf("unable to decode certificate for domain %q: %w", domain, err)
+			return nil, fmt.Errorf("unable to decode certificate for domain %q: %w", domain, err)
 		}
 		cert, err := x509.ParseCertificate(sDec)
 		if err != nil {
 			log.Error().Msgf("unable to parse certificate: %s", err)
-			return fmt.Errorf("unable to parse certificate for domain %q: %w", domain, err)
+			return nil, fmt.Errorf("unable to parse certificate for domain %q: %w", domain, err)
+		}
+		if len(cert.DNSNames) == 0 {
+			continue
 		}
 		detail := CertificateDetail{
 			Issued:         cert.NotBefore,
@@ -71,12 +141,64 @@ func CheckCertificateUsage(domain string) error {
 	}
 
 	// Remove duplicates
-	params = removeDuplicates(params)
+	return removeDuplicates(params), nil
+}
 
-	// Print
-	messageHeader := fmt.Sprintf("LetsEncrypt Certificate Usage\n\nWeekly usage summary for domain %s", domain)
-	message := printLetsEncryptCertData(messageHeader, params, false)
-	fmt.Println(reports.StyleMessage(message))
+// summarizeUsage groups certificates by their first name and computes the
+// remaining budget against the LetsEncrypt rate limits
+func summarizeUsage(domain string, params []CertificateDetail) *CertificateUsage {
+	usage := &CertificateUsage{
+		Domain:    domain,
+		Issued:    len(params),
+		Limit:     WeeklyCertificateLimit,
+		Remaining: max(WeeklyCertificateLimit-len(params), 0),
+		Names:     []NameUsage{},
+	}
+
+	names := map[string]*NameUsage{}
+	for _, cert := range params {
+		if usage.ResetTime == nil || cert.Issued.Add(rateLimitWindow).Before(*usage.ResetTime) {
+			reset := cert.Issued.Add(rateLimitWindow)
+			usage.ResetTime = &reset
+		}
+
+		name, ok := names[cert.DNSNames[0]]
+		if !ok {
+			name = &NameUsage{Name: cert.DNSNames[0], Limit: DuplicateCertificateLimit}
+			names[cert.DNSNames[0]] = name
+		}
+		name.Issued++
+		name.Details = append(name.Details, cert)
+		if name.ResetTime.IsZero() || cert.Issued.Add(rateLimitWindow).Before(name.ResetTime) {
+			name.ResetTime = cert.Issued.Add(rateLimitWindow)
+		}
+	}
+
+	for _, name := range names {
+		name.Remaining = max(name.Limit-name.Issued, 0)
+		usage.Names = append(usage.Names, *name)
+	}
+	sort.Slice(usage.Names, func(i, j int) bool {
+		return usage.Names[i].Name < usage.Names[j].Name
+	})
+
+	return usage
+}
+
+// CheckBudget returns an error when the remaining budget cannot cover a new
+// certificate for each of hosts
+func (u *CertificateUsage) CheckBudget(hosts []string) error {
+	if u.Remaining < len(hosts) {
+		return fmt.Errorf("domain %s has %d of %d weekly LetsEncrypt certificates left but %d are required, the next one frees up at %s",
+			u.Domain, u.Remaining, u.Limit, len(hosts), u.ResetTime.Format(time.RFC3339))
+	}
+
+	for _, name := range u.Names {
+		if name.Remaining == 0 && slices.Contains(hosts, name.Name) {
+			return fmt.Errorf("name %s has reached the limit of %d duplicate LetsEncrypt certificates per week, the next one frees up at %s",
+				name.Name, name.Limit, name.ResetTime.Format(time.RFC3339))
+		}
+	}
 
 	return nil
 }
@@ -86,7 +208,7 @@ func printLetsEncryptCertData(messageHeader string, params []CertificateDetail,
 	var certificateData bytes.Buffer
 	certificateData.WriteString(strings.Repeat("-", 70))
 	certificateData.WriteString(fmt.Sprintf("\n%s\n\n", messageHeader))
-	certificateData.WriteString(fmt.Sprintf("%v of 50 weekly certificates issued\n", len(params)))
+	certificateData.WriteString(fmt.Sprintf("%v of %d weekly certificates issued\n", len(params), WeeklyCertificateLimit))
 	certificateData.WriteString(strings.Repeat("-", 70))
 	certificateData.WriteString("\n\n")
 
@@ -107,7 +229,7 @@ func printLetsEncryptCertData(messageHeader string, params []CertificateDetail,
 
 		for domain, usedCertificatesCount := range occurrences {
 			certificateData.WriteString(fmt.Sprintf("%s\n", domain))
-			certificateData.WriteString(fmt.Sprintf("	%v of 5 of weekly certificates\n", usedCertificatesCount))
+			certificateData.WriteString(fmt.Sprintf("	%v of %d of weekly certificates\n", usedCertificatesCount, DuplicateCertificateLimit))
 			certificateData.WriteString("")
 		}
 	} else {
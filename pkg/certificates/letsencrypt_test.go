@@ -0,0 +1,78 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package certificates
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestCheckBudget(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	hosts := PlatformIngressHosts("kubefirst.dev")
+
+	certs := func(n int, name string) []CertificateDetail {
+		res := make([]CertificateDetail, 0, n)
+		for i := 0; i < n; i++ {
+			res = append(res, CertificateDetail{
+				Issued:   now.Add(-time.Duration(i) * time.Hour),
+				DNSNames: []string{fmt.Sprintf("%s-%d", name, i)},
+			})
+		}
+		return res
+	}
+
+	tests := []struct {
+		name    string
+		params  []CertificateDetail
+		wantErr bool
+	}{
+		{name: "no certificates"},
+		{name: "enough budget", params: certs(WeeklyCertificateLimit-len(hosts), "app")},
+		{name: "weekly limit", params: certs(WeeklyCertificateLimit-len(hosts)+1, "app"), wantErr: true},
+		{name: "duplicate limit", params: func() []CertificateDetail {
+			res := make([]CertificateDetail, 0, DuplicateCertificateLimit)
+			for i := 0; i < DuplicateCertificateLimit; i++ {
+				res = append(res, CertificateDetail{Issued: now.Add(-time.Duration(i) * time.Hour), DNSNames: []string{"vault.kubefirst.dev"}})
+			}
+			return res
+		}(), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usage := summarizeUsage("kubefirst.dev", tt.params)
+			if usage.Issued != len(tt.params) {
+				t.Errorf("expected %d issued certificates, got %d", len(tt.params), usage.Issued)
+			}
+
+			err := usage.CheckBudget(hosts)
+			if tt.wantErr && err == nil {
+				t.Fatal("expected error")
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestSummarizeUsageResetTime(t *testing.T) {
+	oldest := time.Date(2024, 5, 8, 0, 0, 0, 0, time.UTC)
+	usage := summarizeUsage("kubefirst.dev", []CertificateDetail{
+		{Issued: oldest.Add(24 * time.Hour), DNSNames: []string{"argocd.kubefirst.dev"}},
+		{Issued: oldest, DNSNames: []string{"argocd.kubefirst.dev"}},
+	})
+
+	if usage.ResetTime == nil || !usage.ResetTime.Equal(oldest.Add(rateLimitWindow)) {
+		t.Errorf("expected reset time %s, got %v", oldest.Add(rateLimitWindow), usage.ResetTime)
+	}
+	if len(usage.Names) != 1 || usage.Names[0].Issued != 2 || usage.Names[0].Remaining != DuplicateCertificateLimit-2 {
+		t.Errorf("unexpected name usage %+v", usage.Names)
+	}
+}
@@ -23,7 +23,30 @@ type CertificateQueryResult struct {
 
 // CertificateDetail captures information on each individual certificate
 type CertificateDetail struct {
-	Issued         time.Time
-	ExpirationDays float64
-	DNSNames       []string
+	Issued         time.Time `json:"issued"`
+	ExpirationDays float64   `json:"expiration_days"`
+	DNSNames       []string  `json:"dns_names"`
+}
+
+// CertificateUsage summarizes the LetsEncrypt certificates issued for a
+// registered domain during the current rate limit window
+type CertificateUsage struct {
+	Domain    string `json:"domain"`
+	Issued    int    `json:"issued"`
+	Limit     int    `json:"limit"`
+	Remaining int    `json:"remaining"`
+	// ResetTime is when the oldest certificate leaves the window, freeing a slot
+	ResetTime *time.Time  `json:"reset_time,omitempty"`
+	Names     []NameUsage `json:"names"`
+}
+
+// NameUsage summarizes the certificates issued for a single name, which are
+// subject to the duplicate certificate limit
+type NameUsage struct {
+	Name      string              `json:"name"`
+	Issued    int                 `json:"issued"`
+	Limit     int                 `json:"limit"`
+	Remaining int                 `json:"remaining"`
+	ResetTime time.Time           `json:"reset_time"`
+	Details   []CertificateDetail `json:"details"`
 }
@@ -0,0 +1,77 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
+)
+
+// CatalogAppsOptions filters and paginates the gitops catalog applications,
+// zero values are not sent
+type CatalogAppsOptions struct {
+	Category string
+	Search   string
+	// Installed only returns apps that are (true) or are not (false) installed
+	// on the cluster
+	Installed *bool
+	Page      int
+	PageSize  int
+}
+
+func (o CatalogAppsOptions) query() url.Values {
+	query := url.Values{}
+	if o.Category != "" {
+		query.Set("category", o.Category)
+	}
+	if o.Search != "" {
+		query.Set("q", o.Search)
+	}
+	if o.Installed != nil {
+		query.Set("installed", strconv.FormatBool(*o.Installed))
+	}
+	if o.Page > 0 {
+		query.Set("page", strconv.Itoa(o.Page))
+	}
+	if o.PageSize > 0 {
+		query.Set("page_size", strconv.Itoa(o.PageSize))
+	}
+	return query
+}
+
+// ListCatalogApps returns the gitops catalog applications available to a
+// cluster
+func (c *Client) ListCatalogApps(ctx context.Context, clusterName, cloudProvider string, opts CatalogAppsOptions) (*pkgtypes.GitopsCatalogAppsPage, error) {
+	var page pkgtypes.GitopsCatalogAppsPage
+	if err := c.do(ctx, http.MethodGet, apiPath("gitops-catalog", clusterName, cloudProvider, "apps"), opts.query(), nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// ListCatalogCategories returns the gitops catalog categories with their app
+// count
+func (c *Client) ListCatalogCategories(ctx context.Context) (*pkgtypes.GitopsCatalogCategories, error) {
+	var categories pkgtypes.GitopsCatalogCategories
+	if err := c.do(ctx, http.MethodGet, apiPath("gitops-catalog", "categories"), nil, nil, &categories); err != nil {
+		return nil, err
+	}
+	return &categories, nil
+}
+
+// UpdateCatalogApps refreshes the gitops catalog applications
+func (c *Client) UpdateCatalogApps(ctx context.Context) (*SuccessResponse, error) {
+	var res SuccessResponse
+	if err := c.do(ctx, http.MethodGet, apiPath("gitops-catalog", "apps", "update"), nil, nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
@@ -0,0 +1,180 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+
+// Package client is a Go client of the kubefirst API. Its requests and
+// responses follow the OpenAPI document served by the API at /openapi.json.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// APIVersion is the version of the API the client talks to
+const APIVersion = "v1"
+
+// Version is the version of the client, sent in the User-Agent header
+const Version = "1.0.0"
+
+// Client calls the kubefirst API
+type Client struct {
+	baseURL    string
+	token      string
+	userAgent  string
+	httpClient *http.Client
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client requests are sent with
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithUserAgent sets the User-Agent header of the requests
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// New returns a client of the API at baseURL, such as http://localhost:8081,
+// authenticating with the API key token
+func New(baseURL, token string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		token:      token,
+		userAgent:  "kubefirst-api-client/" + Version,
+		httpClient: http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Error is a failure returned by the API
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+	Details    map[string]string
+	// Retryable reports whether the request may succeed as is later on
+	Retryable bool
+	Provider  string
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("kubefirst api: %d: %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("kubefirst api: %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// apiPath returns the path of an API operation, segments are escaped
+func apiPath(segments ...string) string {
+	escaped := make([]string, len(segments))
+	for i, s := range segments {
+		escaped[i] = url.PathEscape(s)
+	}
+	return "/api/" + APIVersion + "/" + strings.Join(escaped, "/")
+}
+
+// newRequest returns a request of the API, body is sent as JSON when set
+func (c *Client) newRequest(ctx context.Context, method, path string, query url.Values, body interface{}) (*http.Request, error) {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode request body: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	return req, nil
+}
+
+// do sends a request and decodes the response into out when set
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	req, err := c.newRequest(ctx, method, path, query, body)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return decodeError(resp)
+	}
+
+	if out == nil {
+		_, err = io.Copy(io.Discard, resp.Body)
+		return err
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("unable to decode response of %s %s: %w", method, path, err)
+	}
+
+	return nil
+}
+
+// decodeError returns the error of a failed response, bodies that are not the
+// error envelope are kept as the message
+func decodeError(resp *http.Response) error {
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("unable to read error response: %w", err)
+	}
+
+	e := &Error{StatusCode: resp.StatusCode}
+	var envelope FailureResponse
+	if err := json.Unmarshal(data, &envelope); err == nil && envelope.Message != "" {
+		e.Code = envelope.Code
+		e.Message = envelope.Message
+		e.Details = envelope.Details
+		e.Retryable = envelope.Retryable
+		e.Provider = envelope.Provider
+		return e
+	}
+
+	e.Message = strings.TrimSpace(string(data))
+	if e.Message == "" {
+		e.Message = http.StatusText(resp.StatusCode)
+	}
+	return e
+}
@@ -0,0 +1,311 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/konstructio/kubefirst-api/internal/openapi"
+	router "github.com/konstructio/kubefirst-api/internal/router"
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
+)
+
+// specServer answers the requests matching an operation of the API spec with
+// the response of the test, requests that do not follow the spec fail the test
+func specServer(t *testing.T, doc *openapi.Document, response interface{}, operationID *string) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, op := findOperation(doc, r.Method, r.URL.EscapedPath())
+		if op == nil {
+			t.Errorf("%s %s is not an operation of the spec", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		*operationID = op.OperationID
+
+		if len(op.Security) > 0 && r.Header.Get("Authorization") != "Bearer test-token" {
+			t.Errorf("%s requires the API key, but got %q", op.OperationID, r.Header.Get("Authorization"))
+		}
+
+		for name := range r.URL.Query() {
+			if !hasQueryParam(op, name) {
+				t.Errorf("%s has no query parameter %s", op.OperationID, name)
+			}
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		switch {
+		case op.RequestBody == nil && len(body) > 0:
+			t.Errorf("%s takes no body, but got %s", op.OperationID, body)
+		case op.RequestBody != nil:
+			if err := doc.ValidateJSON(op.RequestBody.Content[openapi.ContentTypeJSON].Schema, body); err != nil {
+				t.Errorf("%s request body does not match the spec: %v", op.OperationID, err)
+			}
+		}
+
+		for status, res := range op.Responses {
+			if status == "default" {
+				continue
+			}
+			data, err := json.Marshal(response)
+			if err != nil {
+				t.Fatalf("unable to encode the response: %v", err)
+			}
+			if err := doc.ValidateJSON(res.Content[openapi.ContentTypeJSON].Schema, data); err != nil {
+				t.Errorf("%s test response does not match the spec: %v", op.OperationID, err)
+			}
+
+			code, _ := strconv.Atoi(status)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(code)
+			_, _ = w.Write(data)
+			return
+		}
+		t.Errorf("%s %s has no success response", r.Method, path)
+	}))
+}
+
+// findOperation returns the operation of a request, path parameters match a
+// single escaped segment and literal segments take precedence as in gin
+func findOperation(doc *openapi.Document, method, escapedPath string) (string, *openapi.Operation) {
+	segments := strings.Split(escapedPath, "/")
+
+	var found string
+	var foundOp *openapi.Operation
+	best := -1
+	for path, item := range doc.Paths {
+		op, ok := item[strings.ToLower(method)]
+		template := strings.Split(path, "/")
+		if !ok || len(template) != len(segments) {
+			continue
+		}
+
+		literals := 0
+		for i, s := range template {
+			if strings.HasPrefix(s, "{") {
+				continue
+			}
+			if s != segments[i] {
+				literals = -1
+				break
+			}
+			literals++
+		}
+		if literals > best {
+			found, foundOp, best = path, op, literals
+		}
+	}
+	return found, foundOp
+}
+
+func hasQueryParam(op *openapi.Operation, name string) bool {
+	for _, p := range op.Parameters {
+		if p.In == "query" && p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestClientMatchesSpec(t *testing.T) {
+	doc, err := router.Spec()
+	if err != nil {
+		t.Fatalf("unable to build the spec: %v", err)
+	}
+
+	installed := true
+	success := SuccessResponse{Message: "ok"}
+	tests := []struct {
+		operationID string
+		response    interface{}
+		call        func(ctx context.Context, c *Client) error
+	}{
+		{"listClusters", []pkgtypes.Cluster{{ClusterName: "dev"}}, func(ctx context.Context, c *Client) error {
+			_, err := c.ListClusters(ctx)
+			return err
+		}},
+		{"getCluster", pkgtypes.Cluster{ClusterName: "dev"}, func(ctx context.Context, c *Client) error {
+			_, err := c.GetCluster(ctx, "dev")
+			return err
+		}},
+		{"createCluster", success, func(ctx context.Context, c *Client) error {
+			_, err := c.CreateCluster(ctx, "dev", pkgtypes.ClusterDefinition{
+				AdminEmail: "admin@example.com", CloudProvider: "aws", CloudRegion: "us-east-1", DomainName: "example.com",
+				DNSProvider: "aws", Type: "mgmt", NodeType: "t3.large", NodeCount: 3, GitProvider: "github", GitProtocol: "https",
+			})
+			return err
+		}},
+		{"deleteCluster", success, func(ctx context.Context, c *Client) error {
+			_, err := c.DeleteCluster(ctx, "dev")
+			return err
+		}},
+		{"importCluster", success, func(ctx context.Context, c *Client) error {
+			_, err := c.ImportCluster(ctx, pkgtypes.Cluster{ClusterName: "dev"})
+			return err
+		}},
+		{"exportCluster", pkgtypes.Cluster{ClusterName: "dev"}, func(ctx context.Context, c *Client) error {
+			_, err := c.ExportCluster(ctx, "dev")
+			return err
+		}},
+		{"resetClusterProgress", success, func(ctx context.Context, c *Client) error {
+			_, err := c.ResetClusterProgress(ctx, "dev")
+			return err
+		}},
+		{"listServices", pkgtypes.ClusterServiceList{ClusterName: "dev"}, func(ctx context.Context, c *Client) error {
+			_, err := c.ListServices(ctx, "dev")
+			return err
+		}},
+		{"addService", success, func(ctx context.Context, c *Client) error {
+			_, err := c.AddService(ctx, "dev", "metaphor", pkgtypes.GitopsCatalogAppCreateRequest{})
+			return err
+		}},
+		{"bulkAddServices", pkgtypes.GitopsCatalogAppBulkCreateResponse{}, func(ctx context.Context, c *Client) error {
+			_, err := c.BulkAddServices(ctx, "dev", pkgtypes.GitopsCatalogAppBulkCreateRequest{})
+			return err
+		}},
+		{"getBulkAddServices", pkgtypes.GitopsCatalogAppBulkCreateResponse{Status: "running"}, func(ctx context.Context, c *Client) error {
+			_, err := c.GetBulkAddServices(ctx, "dev")
+			return err
+		}},
+		{"addServiceToTargets", pkgtypes.GitopsCatalogAppMultiTargetCreateResponse{}, func(ctx context.Context, c *Client) error {
+			_, err := c.AddServiceToTargets(ctx, "dev", "metaphor", pkgtypes.GitopsCatalogAppMultiTargetCreateRequest{})
+			return err
+		}},
+		{"updateService", success, func(ctx context.Context, c *Client) error {
+			_, err := c.UpdateService(ctx, "dev", "metaphor", pkgtypes.GitopsCatalogAppUpdateRequest{})
+			return err
+		}},
+		{"validateService", pkgtypes.GitopsCatalogAppValidateRequest{CanDeleteService: true}, func(ctx context.Context, c *Client) error {
+			_, err := c.ValidateService(ctx, "dev", "metaphor", pkgtypes.GitopsCatalogAppCreateRequest{})
+			return err
+		}},
+		{"deleteService", success, func(ctx context.Context, c *Client) error {
+			_, err := c.DeleteService(ctx, "dev", "metaphor", pkgtypes.GitopsCatalogAppDeleteRequest{})
+			return err
+		}},
+		{"listEnvironments", []pkgtypes.Environment{{Name: "staging"}}, func(ctx context.Context, c *Client) error {
+			_, err := c.ListEnvironments(ctx)
+			return err
+		}},
+		{"createEnvironment", pkgtypes.Environment{Name: "staging"}, func(ctx context.Context, c *Client) error {
+			_, err := c.CreateEnvironment(ctx, pkgtypes.Environment{Name: "staging"})
+			return err
+		}},
+		{"updateEnvironment", success, func(ctx context.Context, c *Client) error {
+			_, err := c.UpdateEnvironment(ctx, "65f0", EnvironmentUpdateRequest{Color: "blue"})
+			return err
+		}},
+		{"deleteEnvironment", success, func(ctx context.Context, c *Client) error {
+			_, err := c.DeleteEnvironment(ctx, "65f0")
+			return err
+		}},
+		{"listGitopsCatalogApps", pkgtypes.GitopsCatalogAppsPage{Page: 2}, func(ctx context.Context, c *Client) error {
+			_, err := c.ListCatalogApps(ctx, "dev", "aws", CatalogAppsOptions{Category: "security", Search: "vault", Installed: &installed, Page: 2, PageSize: 10})
+			return err
+		}},
+		{"listGitopsCatalogCategories", pkgtypes.GitopsCatalogCategories{}, func(ctx context.Context, c *Client) error {
+			_, err := c.ListCatalogCategories(ctx)
+			return err
+		}},
+		{"updateGitopsCatalogApps", success, func(ctx context.Context, c *Client) error {
+			_, err := c.UpdateCatalogApps(ctx)
+			return err
+		}},
+		{"listDomains", DomainListResponse{Domains: []string{"example.com"}}, func(ctx context.Context, c *Client) error {
+			_, err := c.ListDomains(ctx, "cloudflare", DomainListRequest{})
+			return err
+		}},
+		{"getCertificateUsage", CertificateUsage{}, func(ctx context.Context, c *Client) error {
+			_, err := c.GetCertificateUsage(ctx, "example.com")
+			return err
+		}},
+		{"validateAWSDomain", AWSDomainValidateResponse{Validated: true}, func(ctx context.Context, c *Client) error {
+			_, err := c.ValidateAWSDomain(ctx, "example.com")
+			return err
+		}},
+		{"validateCivoDomain", CivoDomainValidationResponse{Validated: true}, func(ctx context.Context, c *Client) error {
+			_, err := c.ValidateCivoDomain(ctx, "example.com", CivoDomainValidationRequest{CloudRegion: "nyc1"})
+			return err
+		}},
+		{"validateCloudflareDomain", CloudflareDomainValidationResponse{Validated: true}, func(ctx context.Context, c *Client) error {
+			_, err := c.ValidateCloudflareDomain(ctx, "example.com", CloudflareDomainValidationRequest{})
+			return err
+		}},
+		{"validateDigitalOceanDomain", DigitalOceanDomainValidationResponse{Validated: true}, func(ctx context.Context, c *Client) error {
+			_, err := c.ValidateDigitalOceanDomain(ctx, "example.com", DigitalOceanDomainValidationRequest{})
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.operationID, func(t *testing.T) {
+			var operationID string
+			srv := specServer(t, doc, tt.response, &operationID)
+			defer srv.Close()
+
+			if err := tt.call(context.Background(), New(srv.URL, "test-token")); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if operationID != tt.operationID {
+				t.Errorf("expected the %s operation, but got %q", tt.operationID, operationID)
+			}
+		})
+	}
+}
+
+func TestErrorEnvelope(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(FailureResponse{Message: "dev has an active process running", Code: "operation_in_progress", Retryable: true})
+	}))
+	defer srv.Close()
+
+	_, err := New(srv.URL, "test-token").DeleteCluster(context.Background(), "dev")
+
+	var e *Error
+	if !errors.As(err, &e) {
+		t.Fatalf("expected a *client.Error, but got %v", err)
+	}
+	if e.StatusCode != http.StatusConflict || e.Code != "operation_in_progress" || !e.Retryable {
+		t.Errorf("unexpected error %+v", e)
+	}
+}
+
+func TestStreamLogs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/api/v1/stream/:file_name", func(c *gin.Context) {
+		c.SSEvent("", "first line")
+		c.SSEvent("", "second line")
+		c.SSEvent("error", "log file removed")
+	})
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	stream, err := New(srv.URL, "").StreamLogs(context.Background(), "log_1.log")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer stream.Close()
+
+	for _, want := range []string{"first line", "second line"} {
+		got, err := stream.Next()
+		if err != nil || got != want {
+			t.Fatalf("expected %q, but got %q, %v", want, got, err)
+		}
+	}
+	if _, err := stream.Next(); err == nil || err.Error() != "log file removed" {
+		t.Errorf("expected the error event, but got %v", err)
+	}
+	if _, err := stream.Next(); !errors.Is(err, io.EOF) {
+		t.Errorf("expected io.EOF at the end of the stream, but got %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package client
+
+import (
+	"context"
+	"net/http"
+
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
+)
+
+// ListClusters returns all known clusters
+func (c *Client) ListClusters(ctx context.Context) ([]pkgtypes.Cluster, error) {
+	var clusters []pkgtypes.Cluster
+	if err := c.do(ctx, http.MethodGet, apiPath("cluster"), nil, nil, &clusters); err != nil {
+		return nil, err
+	}
+	return clusters, nil
+}
+
+// GetCluster returns a cluster
+func (c *Client) GetCluster(ctx context.Context, clusterName string) (*pkgtypes.Cluster, error) {
+	var cluster pkgtypes.Cluster
+	if err := c.do(ctx, http.MethodGet, apiPath("cluster", clusterName), nil, nil, &cluster); err != nil {
+		return nil, err
+	}
+	return &cluster, nil
+}
+
+// CreateCluster starts the creation of a cluster, its progress is reported by
+// GetCluster
+func (c *Client) CreateCluster(ctx context.Context, clusterName string, definition pkgtypes.ClusterDefinition) (*SuccessResponse, error) {
+	var res SuccessResponse
+	if err := c.do(ctx, http.MethodPost, apiPath("cluster", clusterName), nil, definition, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// DeleteCluster starts the deletion of a cluster
+func (c *Client) DeleteCluster(ctx context.Context, clusterName string) (*SuccessResponse, error) {
+	var res SuccessResponse
+	if err := c.do(ctx, http.MethodDelete, apiPath("cluster", clusterName), nil, nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ImportCluster imports a cluster record exported by ExportCluster
+func (c *Client) ImportCluster(ctx context.Context, cluster pkgtypes.Cluster) (*SuccessResponse, error) {
+	var res SuccessResponse
+	if err := c.do(ctx, http.MethodPost, apiPath("cluster", "import"), nil, cluster, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ExportCluster returns the record of a cluster for ImportCluster
+func (c *Client) ExportCluster(ctx context.Context, clusterName string) (*pkgtypes.Cluster, error) {
+	var cluster pkgtypes.Cluster
+	if err := c.do(ctx, http.MethodGet, apiPath("cluster", clusterName, "export"), nil, nil, &cluster); err != nil {
+		return nil, err
+	}
+	return &cluster, nil
+}
+
+// ResetClusterProgress clears the progress markers of a cluster so a failed
+// operation can be retried
+func (c *Client) ResetClusterProgress(ctx context.Context, clusterName string) (*SuccessResponse, error) {
+	var res SuccessResponse
+	if err := c.do(ctx, http.MethodPost, apiPath("cluster", clusterName, "reset_progress"), nil, nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
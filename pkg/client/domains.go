@@ -0,0 +1,68 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// ListDomains returns the domains of a dns provider account
+func (c *Client) ListDomains(ctx context.Context, dnsProvider string, req DomainListRequest) (*DomainListResponse, error) {
+	var res DomainListResponse
+	if err := c.do(ctx, http.MethodPost, apiPath("domain", dnsProvider), nil, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// GetCertificateUsage returns the LetsEncrypt certificate budget used by a
+// domain
+func (c *Client) GetCertificateUsage(ctx context.Context, domain string) (*CertificateUsage, error) {
+	var usage CertificateUsage
+	if err := c.do(ctx, http.MethodGet, apiPath("domain", domain, "certificate-usage"), nil, nil, &usage); err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
+
+// ValidateAWSDomain checks a Route53 hosted zone is usable for a cluster
+func (c *Client) ValidateAWSDomain(ctx context.Context, domain string) (*AWSDomainValidateResponse, error) {
+	var res AWSDomainValidateResponse
+	if err := c.do(ctx, http.MethodGet, apiPath("domain", "validate", "aws", domain), nil, nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ValidateCivoDomain checks a Civo domain is usable for a cluster
+func (c *Client) ValidateCivoDomain(ctx context.Context, domain string, req CivoDomainValidationRequest) (*CivoDomainValidationResponse, error) {
+	var res CivoDomainValidationResponse
+	if err := c.do(ctx, http.MethodGet, apiPath("domain", "validate", "civo", domain), nil, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ValidateCloudflareDomain checks a Cloudflare domain is usable for a cluster
+func (c *Client) ValidateCloudflareDomain(ctx context.Context, domain string, req CloudflareDomainValidationRequest) (*CloudflareDomainValidationResponse, error) {
+	var res CloudflareDomainValidationResponse
+	if err := c.do(ctx, http.MethodPost, apiPath("domain", "validate", "cloudflare", domain), nil, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ValidateDigitalOceanDomain checks a DigitalOcean domain is usable for a
+// cluster
+func (c *Client) ValidateDigitalOceanDomain(ctx context.Context, domain string, req DigitalOceanDomainValidationRequest) (*DigitalOceanDomainValidationResponse, error) {
+	var res DigitalOceanDomainValidationResponse
+	if err := c.do(ctx, http.MethodPost, apiPath("domain", "validate", "digitalocean", domain), nil, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
@@ -0,0 +1,50 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package client
+
+import (
+	"context"
+	"net/http"
+
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
+)
+
+// ListEnvironments returns all environments
+func (c *Client) ListEnvironments(ctx context.Context) ([]pkgtypes.Environment, error) {
+	var environments []pkgtypes.Environment
+	if err := c.do(ctx, http.MethodGet, apiPath("environment"), nil, nil, &environments); err != nil {
+		return nil, err
+	}
+	return environments, nil
+}
+
+// CreateEnvironment creates an environment and returns it with its id
+func (c *Client) CreateEnvironment(ctx context.Context, environment pkgtypes.Environment) (*pkgtypes.Environment, error) {
+	var created pkgtypes.Environment
+	if err := c.do(ctx, http.MethodPost, apiPath("environment"), nil, environment, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// UpdateEnvironment updates the color and description of an environment
+func (c *Client) UpdateEnvironment(ctx context.Context, environmentID string, update EnvironmentUpdateRequest) (*SuccessResponse, error) {
+	var res SuccessResponse
+	if err := c.do(ctx, http.MethodPut, apiPath("environment", environmentID), nil, update, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// DeleteEnvironment deletes an environment
+func (c *Client) DeleteEnvironment(ctx context.Context, environmentID string) (*SuccessResponse, error) {
+	var res SuccessResponse
+	if err := c.do(ctx, http.MethodDelete, apiPath("environment", environmentID), nil, nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
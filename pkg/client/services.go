@@ -0,0 +1,87 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package client
+
+import (
+	"context"
+	"net/http"
+
+	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
+)
+
+// ListServices returns the services of a cluster
+func (c *Client) ListServices(ctx context.Context, clusterName string) (*pkgtypes.ClusterServiceList, error) {
+	var services pkgtypes.ClusterServiceList
+	if err := c.do(ctx, http.MethodGet, apiPath("services", clusterName), nil, nil, &services); err != nil {
+		return nil, err
+	}
+	return &services, nil
+}
+
+// AddService adds a gitops catalog service to a cluster
+func (c *Client) AddService(ctx context.Context, clusterName, serviceName string, req pkgtypes.GitopsCatalogAppCreateRequest) (*SuccessResponse, error) {
+	var res SuccessResponse
+	if err := c.do(ctx, http.MethodPost, apiPath("services", clusterName, serviceName), nil, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// BulkAddServices starts adding several gitops catalog services to a cluster,
+// its progress is returned by GetBulkAddServices
+func (c *Client) BulkAddServices(ctx context.Context, clusterName string, req pkgtypes.GitopsCatalogAppBulkCreateRequest) (*pkgtypes.GitopsCatalogAppBulkCreateResponse, error) {
+	var res pkgtypes.GitopsCatalogAppBulkCreateResponse
+	if err := c.do(ctx, http.MethodPost, apiPath("services", clusterName, "bulk"), nil, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// GetBulkAddServices returns the progress of the last bulk install of a cluster
+func (c *Client) GetBulkAddServices(ctx context.Context, clusterName string) (*pkgtypes.GitopsCatalogAppBulkCreateResponse, error) {
+	var res pkgtypes.GitopsCatalogAppBulkCreateResponse
+	if err := c.do(ctx, http.MethodGet, apiPath("services", clusterName, "bulk"), nil, nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// AddServiceToTargets adds a gitops catalog service to several clusters
+func (c *Client) AddServiceToTargets(ctx context.Context, clusterName, serviceName string, req pkgtypes.GitopsCatalogAppMultiTargetCreateRequest) (*pkgtypes.GitopsCatalogAppMultiTargetCreateResponse, error) {
+	var res pkgtypes.GitopsCatalogAppMultiTargetCreateResponse
+	if err := c.do(ctx, http.MethodPost, apiPath("services", clusterName, serviceName, "targets"), nil, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// UpdateService updates the values of a service of a cluster
+func (c *Client) UpdateService(ctx context.Context, clusterName, serviceName string, req pkgtypes.GitopsCatalogAppUpdateRequest) (*SuccessResponse, error) {
+	var res SuccessResponse
+	if err := c.do(ctx, http.MethodPut, apiPath("services", clusterName, serviceName), nil, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ValidateService reports whether a service can be removed from a cluster
+func (c *Client) ValidateService(ctx context.Context, clusterName, serviceName string, req pkgtypes.GitopsCatalogAppCreateRequest) (*pkgtypes.GitopsCatalogAppValidateRequest, error) {
+	var res pkgtypes.GitopsCatalogAppValidateRequest
+	if err := c.do(ctx, http.MethodPost, apiPath("services", clusterName, serviceName, "validate"), nil, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// DeleteService removes a service from a cluster
+func (c *Client) DeleteService(ctx context.Context, clusterName, serviceName string, req pkgtypes.GitopsCatalogAppDeleteRequest) (*SuccessResponse, error) {
+	var res SuccessResponse
+	if err := c.do(ctx, http.MethodDelete, apiPath("services", clusterName, serviceName), nil, req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
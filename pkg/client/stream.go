@@ -0,0 +1,84 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package client
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// LogStream reads the lines of a log file streamed by the API
+type LogStream struct {
+	body    io.ReadCloser
+	scanner *bufio.Scanner
+}
+
+// StreamLogs follows a log file of the API, such as the log of a cluster
+// creation. The stream ends when ctx is done or Close is called.
+func (c *Client) StreamLogs(ctx context.Context, fileName string) (*LogStream, error) {
+	path := apiPath("stream", fileName)
+	req, err := c.newRequest(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", http.MethodGet, path, err)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		defer resp.Body.Close()
+		return nil, decodeError(resp)
+	}
+
+	return &LogStream{body: resp.Body, scanner: bufio.NewScanner(resp.Body)}, nil
+}
+
+// Next returns the next line of the log file, io.EOF when the stream ended
+func (s *LogStream) Next() (string, error) {
+	var event string
+	var data []string
+	for s.scanner.Scan() {
+		line := s.scanner.Text()
+
+		// a blank line ends an event
+		if line == "" {
+			if data == nil {
+				continue
+			}
+			if event == "error" {
+				return "", errors.New(strings.Join(data, "\n"))
+			}
+			return strings.Join(data, "\n"), nil
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+
+	if err := s.scanner.Err(); err != nil {
+		return "", fmt.Errorf("unable to read log stream: %w", err)
+	}
+	return "", io.EOF
+}
+
+// Close ends the stream
+func (s *LogStream) Close() error {
+	return s.body.Close()
+}
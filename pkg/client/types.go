@@ -0,0 +1,32 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package client
+
+import (
+	"github.com/konstructio/kubefirst-api/internal/types"
+	"github.com/konstructio/kubefirst-api/pkg/certificates"
+)
+
+// Bodies of the API that are not part of the pkg/types package
+type (
+	FailureResponse = types.JSONFailureResponse
+	SuccessResponse = types.JSONSuccessResponse
+
+	DomainListRequest  = types.DomainListRequest
+	DomainListResponse = types.DomainListResponse
+	CertificateUsage   = certificates.CertificateUsage
+
+	AWSDomainValidateResponse            = types.AWSDomainValidateResponse
+	CivoDomainValidationRequest          = types.CivoDomainValidationRequest
+	CivoDomainValidationResponse         = types.CivoDomainValidationResponse
+	CloudflareDomainValidationRequest    = types.CloudflareDomainValidationRequest
+	CloudflareDomainValidationResponse   = types.CloudflareDomainValidationResponse
+	DigitalOceanDomainValidationRequest  = types.DigitalOceanDomainValidationRequest
+	DigitalOceanDomainValidationResponse = types.DigitalOceanDomainValidationResponse
+
+	EnvironmentUpdateRequest = types.EnvironmentUpdateRequest
+)
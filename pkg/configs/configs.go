@@ -5,8 +5,4 @@ import "github.com/konstructio/kubefirst-api/configs"
 const DefaultK1Version = configs.DefaultK1Version
 
 //nolint:gochecknoglobals
-var (
-	K1Version             = configs.K1Version
-	ReadConfig            = configs.ReadConfig
-	InitializeViperConfig = configs.InitializeViperConfig
-)
+var K1Version = configs.K1Version
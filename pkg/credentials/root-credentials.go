@@ -14,7 +14,7 @@ import (
 	"strings"
 
 	"github.com/atotto/clipboard"
-	"github.com/konstructio/kubefirst-api/internal/helpers"
+	"github.com/konstructio/kubefirst-api/internal/env"
 	"github.com/konstructio/kubefirst-api/internal/httpCommon"
 	"github.com/konstructio/kubefirst-api/internal/k8s"
 	"github.com/konstructio/kubefirst-api/internal/vault"
@@ -25,14 +25,12 @@ import (
 
 // EvalAuth determines whether or not there are active kubefirst platforms
 // If there are not, an error is returned
-func EvalAuth(expectedCloudProvider string, expectedGitProvider string) (bool, error) {
-	flags := helpers.GetClusterStatusFlags()
-
+func EvalAuth(e env.Env, expectedCloudProvider string, expectedGitProvider string) (bool, error) {
 	switch {
-	case flags.CloudProvider == "" || flags.GitProvider == "":
+	case e.CloudProvider == "" || e.GitProvider == "":
 		return false, errors.New("could not parse cloud and git provider information from config")
-	case flags.CloudProvider != expectedCloudProvider:
-		return false, fmt.Errorf("it looks like the current deployed platform is %s - try running this command for that provider", flags.CloudProvider)
+	case e.CloudProvider != expectedCloudProvider:
+		return false, fmt.Errorf("it looks like the current deployed platform is %s - try running this command for that provider", e.CloudProvider)
 	}
 
 	log.Info().Msgf("Verified %s platform using %s - parsing credentials...", expectedCloudProvider, expectedGitProvider)
@@ -40,52 +38,73 @@ func EvalAuth(expectedCloudProvider string, expectedGitProvider string) (bool, e
 	return true, nil
 }
 
-// ParseAuthData gets base root credentials for platform components
-func ParseAuthData(clientset kubernetes.Interface, cloudProvider string, domainName string, opts *CredentialOptions) error {
-	// Retrieve vault root token
-	var vaultRootToken string
-	vaultUnsealSecretData, err := k8s.ReadSecretV2(clientset, "vault", "vault-unseal-secret")
+// GetPlatformCredentials gets base root credentials for platform components.
+// Credentials that cannot be retrieved are left empty with an explanation.
+func GetPlatformCredentials(clientset kubernetes.Interface, domainName string) *PlatformCredentials {
+	creds := &PlatformCredentials{
+		ArgoCD: ComponentCredentials{URL: fmt.Sprintf("https://argocd.%s", domainName), Username: "admin"},
+		Vault:  ComponentCredentials{URL: fmt.Sprintf("https://vault.%s", domainName)},
+		Kbot:   ComponentCredentials{Username: "kbot"},
+	}
+
+	// Retrieve vault root token, which is revoked once bootstrap completes
+	vaultUnsealSecretData, err := k8s.ReadSecretV2(clientset, vault.VaultNamespace, vault.VaultSecretName)
 	if err != nil {
 		log.Warn().Msgf("vault secret may not exist: %s", err)
+		creds.Vault.Message = "the vault root token secret could not be found"
 	}
 	if len(vaultUnsealSecretData) != 0 {
-		vaultRootToken = vaultUnsealSecretData["root-token"]
+		creds.Vault.Token = vaultUnsealSecretData["root-token"]
+	}
+	if err == nil && creds.Vault.Token == "" {
+		creds.Vault.Message = "the vault root token was revoked after bootstrap, generate a new one from the unseal or recovery keys with vault operator generate-root"
 	}
 
 	// Retrieve argocd password
-	var argoCDPassword string
 	argoCDSecretData, err := k8s.ReadSecretV2(clientset, "argocd", "argocd-initial-admin-secret")
 	if err != nil {
 		log.Warn().Msgf("Argo CD secret may not exist: %s", err)
+		creds.ArgoCD.Message = "the Argo CD initial admin secret could not be found"
 	}
 	if len(argoCDSecretData) != 0 {
-		argoCDPassword = argoCDSecretData["password"]
+		creds.ArgoCD.Password = argoCDSecretData["password"]
 	}
 
 	// Retrieve kbot password
-	var kbotPassword string
-	if vaultRootToken != "" {
-		vaultURL := fmt.Sprintf("https://vault.%s", domainName)
-		vaultResolves := httpCommon.ResolveAddress(vaultURL)
+	if vaultResolves := httpCommon.ResolveAddress(creds.Vault.URL); vaultResolves != nil {
+		creds.Kbot.Message = fmt.Sprintf("Cannot resolve Vault yet: %s - wait a few minutes and try again.", vaultResolves)
+		return creds
+	}
 
-		if vaultResolves == nil {
-			if vaultRootToken == "" {
-				fmt.Println("Cannot retrieve Vault token automatically. Please provide one here:")
-				fmt.Scanln(&vaultRootToken)
-			}
-			vault := vault.Conf
-			kbotPassword, err = vault.GetUserPassword(
-				vaultURL,
-				vaultRootToken,
-				"kbot",
-				"initial-password",
-			)
-			if err != nil {
-				log.Warn().Msgf("problem retrieving kbot password: %s", err)
-			}
-		} else {
-			kbotPassword = fmt.Sprintf("Cannot resolve Vault yet: %s - wait a few minutes and try again.", vaultResolves)
-		}
+	vaultToken, err := vault.Conf.APIToken(creds.Vault.URL, clientset)
+	if err != nil {
+		log.Warn().Msgf("unable to authenticate to vault: %s", err)
+		creds.Kbot.Message = fmt.Sprintf("the kbot password is stored in vault, which could not be authenticated to: %s", err)
+		return creds
+	}
+
+	creds.Kbot.Password, err = vault.Conf.GetUserPassword(
+		creds.Vault.URL,
+		vaultToken,
+		"kbot",
+		"initial-password",
+	)
+	if err != nil {
+		log.Warn().Msgf("problem retrieving kbot password: %s", err)
+		creds.Kbot.Message = fmt.Sprintf("problem retrieving kbot password: %s", err)
+	}
+
+	return creds
+}
+
+// ParseAuthData prints base root credentials for platform components
+func ParseAuthData(clientset kubernetes.Interface, cloudProvider string, domainName string, opts *CredentialOptions) error {
+	creds := GetPlatformCredentials(clientset, domainName)
+	vaultRootToken := creds.Vault.Token
+	argoCDPassword := creds.ArgoCD.Password
+	kbotPassword := creds.Kbot.Password
+	if kbotPassword == "" && vaultRootToken != "" {
+		kbotPassword = creds.Kbot.Message
 	}
 
 	// If copying to clipboard, no need to return all output
@@ -0,0 +1,110 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package credentials
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/konstructio/kubefirst-api/internal/vault"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/crypto/bcrypt"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	passwordCharset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	passwordLength  = 24
+)
+
+// RotateArgoCDAdminPassword sets a new Argo CD admin password, which also
+// invalidates existing admin sessions, and returns it. The initial admin secret
+// is updated so the new password can be retrieved like the original one.
+func RotateArgoCDAdminPassword(clientset kubernetes.Interface) (string, error) {
+	ctx := context.Background()
+
+	password, err := generatePassword()
+	if err != nil {
+		return "", err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("error hashing password: %w", err)
+	}
+
+	argoCDSecret, err := clientset.CoreV1().Secrets("argocd").Get(ctx, "argocd-secret", metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("error getting Argo CD secret: %w", err)
+	}
+	if argoCDSecret.Data == nil {
+		argoCDSecret.Data = map[string][]byte{}
+	}
+	argoCDSecret.Data["admin.password"] = hash
+	argoCDSecret.Data["admin.passwordMtime"] = []byte(time.Now().UTC().Format(time.RFC3339))
+
+	if _, err := clientset.CoreV1().Secrets("argocd").Update(ctx, argoCDSecret, metav1.UpdateOptions{}); err != nil {
+		return "", fmt.Errorf("error updating Argo CD secret: %w", err)
+	}
+
+	initialSecret, err := clientset.CoreV1().Secrets("argocd").Get(ctx, "argocd-initial-admin-secret", metav1.GetOptions{})
+	switch {
+	case errors.IsNotFound(err):
+		_, err = clientset.CoreV1().Secrets("argocd").Create(ctx, &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "argocd-initial-admin-secret", Namespace: "argocd"},
+			Data:       map[string][]byte{"password": []byte(password)},
+		}, metav1.CreateOptions{})
+	case err == nil:
+		if initialSecret.Data == nil {
+			initialSecret.Data = map[string][]byte{}
+		}
+		initialSecret.Data["password"] = []byte(password)
+		_, err = clientset.CoreV1().Secrets("argocd").Update(ctx, initialSecret, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return "", fmt.Errorf("error storing Argo CD admin password: %w", err)
+	}
+
+	log.Info().Msg("rotated Argo CD admin password")
+	return password, nil
+}
+
+// RotateKbotPassword sets a new password for the kbot vault user and returns it.
+// The users terraform in the gitops repository reverts the password if it is
+// applied again with its original state.
+func RotateKbotPassword(vaultURL, vaultToken string) (string, error) {
+	password, err := generatePassword()
+	if err != nil {
+		return "", err
+	}
+
+	if err := vault.Conf.SetUserPassword(vaultURL, vaultToken, "kbot", "initial-password", password); err != nil {
+		return "", fmt.Errorf("error rotating kbot password: %w", err)
+	}
+
+	log.Info().Msg("rotated kbot password")
+	return password, nil
+}
+
+// generatePassword returns a random alphanumeric password
+func generatePassword() (string, error) {
+	b := make([]byte, passwordLength)
+	for i := range b {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(passwordCharset))))
+		if err != nil {
+			return "", fmt.Errorf("error generating password: %w", err)
+		}
+		b[i] = passwordCharset[n.Int64()]
+	}
+	return string(b), nil
+}
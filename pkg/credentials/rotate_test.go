@@ -0,0 +1,69 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package credentials
+
+import (
+	"context"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRotateArgoCDAdminPassword(t *testing.T) {
+	tests := []struct {
+		name          string
+		initialSecret bool
+	}{
+		{name: "initial admin secret present", initialSecret: true},
+		{name: "initial admin secret deleted"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset(&v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "argocd-secret", Namespace: "argocd"},
+				Data:       map[string][]byte{"admin.password": []byte("old")},
+			})
+			if tt.initialSecret {
+				clientset.CoreV1().Secrets("argocd").Create(context.Background(), &v1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "argocd-initial-admin-secret", Namespace: "argocd"},
+					Data:       map[string][]byte{"password": []byte("old")},
+				}, metav1.CreateOptions{})
+			}
+
+			password, err := RotateArgoCDAdminPassword(clientset)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if len(password) != passwordLength {
+				t.Errorf("expected a %d character password, got %q", passwordLength, password)
+			}
+
+			argoCDSecret, err := clientset.CoreV1().Secrets("argocd").Get(context.Background(), "argocd-secret", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if err := bcrypt.CompareHashAndPassword(argoCDSecret.Data["admin.password"], []byte(password)); err != nil {
+				t.Errorf("admin password hash does not match the new password: %s", err)
+			}
+			if len(argoCDSecret.Data["admin.passwordMtime"]) == 0 {
+				t.Error("expected admin password mtime to be set")
+			}
+
+			initialSecret, err := clientset.CoreV1().Secrets("argocd").Get(context.Background(), "argocd-initial-admin-secret", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if string(initialSecret.Data["password"]) != password {
+				t.Errorf("expected initial admin secret to hold the new password, got %q", initialSecret.Data["password"])
+			}
+		})
+	}
+}
@@ -11,3 +11,20 @@ type CredentialOptions struct {
 	CopyKbotPasswordToClipboard   bool
 	CopyVaultPasswordToClipboard  bool
 }
+
+// PlatformCredentials holds the credentials of the platform components of a cluster
+type PlatformCredentials struct {
+	ArgoCD ComponentCredentials `json:"argocd"`
+	Vault  ComponentCredentials `json:"vault"`
+	Kbot   ComponentCredentials `json:"kbot"`
+}
+
+// ComponentCredentials holds the credentials of a single platform component.
+// Message explains why a credential could not be retrieved.
+type ComponentCredentials struct {
+	URL      string `json:"url,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Token    string `json:"token,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
@@ -26,20 +26,28 @@ var gpuSupport = []string{"civo"}
 
 const (
 	AkamaiGitHub       = "akamai-github"
+	AkamaiGitea        = "akamai-gitea"
 	AwsGitHub          = "aws-github"
 	AwsGitLab          = "aws-gitlab"
+	AwsGitea           = "aws-gitea"
 	AzureGitHub        = "azure-github"
 	AzureGitLab        = "azure-gitlab"
+	AzureGitea         = "azure-gitea"
 	CivoGitHub         = "civo-github"
 	CivoGitLab         = "civo-gitlab"
+	CivoGitea          = "civo-gitea"
 	GoogleGitHub       = "google-github"
 	GoogleGitLab       = "google-gitlab"
+	GoogleGitea        = "google-gitea"
 	DigitalOceanGitHub = "digitalocean-github"
 	DigitalOceanGitLab = "digitalocean-gitlab"
+	DigitalOceanGitea  = "digitalocean-gitea"
 	VultrGitHub        = "vultr-github"
 	VultrGitLab        = "vultr-gitlab"
+	VultrGitea         = "vultr-gitea"
 	K3sGitHub          = "k3s-github"
 	K3sGitLab          = "k3s-gitlab"
+	K3sGitea           = "k3s-gitea"
 )
 
 func removeAllWithLogger(path string) {
@@ -119,9 +127,15 @@ func AdjustGitopsRepo(
 	apexContentExists bool,
 	useCloudflareOriginIssuer bool,
 ) error {
+	driver := fmt.Sprintf("%s-%s", cloudProvider, gitProvider)
+	if _, err := os.Stat(filepath.Join(gitopsRepoDir, driver)); err != nil {
+		log.Error().Msgf("the gitops template has no %s driver: %s", driver, err)
+		return fmt.Errorf("the gitops template has no %s driver: %w", driver, err)
+	}
+
 	// * clean up all other platforms
 	for _, platform := range pkg.SupportedPlatforms {
-		if platform != fmt.Sprintf("%s-%s", cloudProvider, gitProvider) {
+		if platform != driver {
 			removeAllWithLogger(filepath.Join(gitopsRepoDir, platform))
 		}
 	}
@@ -169,20 +183,28 @@ func AdjustGitopsRepo(
 
 	switch cloudAndGitProvider {
 	case AkamaiGitHub,
+		AkamaiGitea,
 		AwsGitHub,
 		AwsGitLab,
+		AwsGitea,
 		AzureGitHub,
 		AzureGitLab,
+		AzureGitea,
 		CivoGitHub,
 		CivoGitLab,
+		CivoGitea,
 		GoogleGitHub,
 		GoogleGitLab,
+		GoogleGitea,
 		DigitalOceanGitHub,
 		DigitalOceanGitLab,
+		DigitalOceanGitea,
 		VultrGitHub,
 		VultrGitLab,
+		VultrGitea,
 		K3sGitHub,
-		K3sGitLab:
+		K3sGitLab,
+		K3sGitea:
 		return adjustGitOpsRepoForProvider(cloudProvider, gitProvider, gitopsRepoDir, clusterType, clusterName, apexContentExists, false)
 
 	default:
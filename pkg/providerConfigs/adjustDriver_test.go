@@ -0,0 +1,23 @@
+package providerConfigs //nolint:revive,stylecheck // allowing temporarily for better code organization
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAdjustGitopsRepoMissingDriver(t *testing.T) {
+	gitopsRepoDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(gitopsRepoDir, "aws-github"), 0o700); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	err := AdjustGitopsRepo("aws", "kubefirst", "mgmt", gitopsRepoDir, "gitea", false, false)
+	if err == nil {
+		t.Fatal("expected an error for a template without an aws-gitea driver")
+	}
+
+	if _, err := os.Stat(filepath.Join(gitopsRepoDir, "aws-github")); err != nil {
+		t.Fatalf("expected the template to be left untouched: %s", err)
+	}
+}
@@ -63,6 +63,7 @@ func GetConfig(
 	clusterName,
 	domainName,
 	gitProvider,
+	gitHost,
 	gitOwner,
 	gitProtocol,
 	cloudflareAPIToken,
@@ -75,12 +76,15 @@ func GetConfig(
 	}
 
 	// cGitHost describes which git host to use depending on gitProvider
-	var cGitHost string
-	switch gitProvider {
-	case "github":
-		cGitHost = GithubHost
-	case "gitlab":
-		cGitHost = GitlabHost
+	// when no self-hosted git server is configured
+	cGitHost := gitHost
+	if cGitHost == "" {
+		switch gitProvider {
+		case "github":
+			cGitHost = GithubHost
+		case "gitlab":
+			cGitHost = GitlabHost
+		}
 	}
 
 	return &ProviderConfig{
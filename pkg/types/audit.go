@@ -0,0 +1,24 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package types
+
+import "time"
+
+// CredentialsAuditLog records the accesses to the platform credentials of a cluster
+type CredentialsAuditLog struct {
+	ClusterName string                  `json:"cluster_name"`
+	Entries     []CredentialsAuditEntry `json:"entries"`
+}
+
+// CredentialsAuditEntry describes a single access to the platform credentials
+type CredentialsAuditEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	// Action is read or rotate
+	Action    string `json:"action"`
+	ClientIP  string `json:"client_ip"`
+	UserAgent string `json:"user_agent"`
+}
@@ -42,13 +42,16 @@ type VultrAuth struct {
 	Token string `bson:"token" json:"token"`
 }
 
-// StateStoreCredentials
+// StateStoreCredentials, azure stores the storage account in Name and its
+// access key in SecretAccessKey
 type StateStoreCredentials struct {
 	AccessKeyID     string `bson:"access_key_id,omitempty" json:"access_key_id,omitempty"`
 	SecretAccessKey string `bson:"secret_access_key,omitempty" json:"secret_access_key,omitempty"`
 	SessionToken    string `bson:"session_token,omitempty" json:"session_token,omitempty"`
 	Name            string `bson:"name,omitempty" json:"name,omitempty"`
 	ID              string `bson:"id,omitempty" json:"id,omitempty"`
+	// KeyFile is the service account key of gcs state stores
+	KeyFile string `bson:"key_file,omitempty" json:"key_file,omitempty"`
 }
 
 // Auth for Git Provider
@@ -61,6 +64,14 @@ type GitAuth struct {
 	PublicKeys string `bson:"public_keys,omitempty" json:"public_keys,omitempty"`
 }
 
+// GitServer describes a self-hosted git provider such as GitHub Enterprise or
+// GitLab self-managed. Empty fields fall back to github.com / gitlab.com.
+type GitServer struct {
+	BaseURL  string `bson:"base_url,omitempty" json:"base_url,omitempty"`
+	APIURL   string `bson:"api_url,omitempty" json:"api_url,omitempty"`
+	CABundle string `bson:"ca_bundle,omitempty" json:"ca_bundle,omitempty"`
+}
+
 type VaultAuth struct {
 	RootToken    string `bson:"root_token,omitempty" json:"root_token,omitempty"`
 	KbotPassword string `bson:"kbot_password,omitempty" json:"kbot_password,omitempty"`
@@ -7,6 +7,8 @@ See the LICENSE file for more details.
 package types
 
 import (
+	"time"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -30,10 +32,11 @@ type ClusterDefinition struct {
 	// Git
 
 	// Git
-	GitopsTemplateURL    string `json:"gitops_template_url"`
-	GitopsTemplateBranch string `json:"gitops_template_branch"`
-	GitProvider          string `json:"git_provider" binding:"required,oneof=github gitlab"`
-	GitProtocol          string `json:"git_protocol" binding:"required,oneof=ssh https"`
+	GitopsTemplateURL    string    `json:"gitops_template_url"`
+	GitopsTemplateBranch string    `json:"gitops_template_branch"`
+	GitProvider          string    `json:"git_provider" binding:"required,oneof=github gitlab gitea"`
+	GitProtocol          string    `json:"git_protocol" binding:"required,oneof=ssh https"`
+	GitServer            GitServer `json:"git_server,omitempty"`
 
 	// AWS
 	ECR     bool   `json:"ecr,omitempty"`
@@ -54,6 +57,9 @@ type ClusterDefinition struct {
 	K3sAuth          K3sAuth          `json:"k3s_auth,omitempty"`
 	GitAuth          GitAuth          `json:"git_auth,omitempty"`
 	LogFileName      string           `bson:"log_file,omitempty" json:"log_file,omitempty"`
+
+	// Telemetry, enabled when omitted. No event about the cluster is sent when disabled.
+	UseTelemetry *bool `json:"use_telemetry,omitempty"`
 }
 
 // Cluster describes the configuration storage for a Kubefirst cluster object
@@ -65,6 +71,13 @@ type Cluster struct {
 	Status        string `bson:"status" json:"status"`
 	LastCondition string `bson:"last_condition" json:"last_condition"`
 	InProgress    bool   `bson:"in_progress" json:"in_progress"`
+	// LastErrorCode is the error code of the last failed operation
+	LastErrorCode string `bson:"last_error_code,omitempty" json:"last_error_code,omitempty"`
+	// InterruptedOperation is the operation an API shutdown stopped, create or
+	// delete, only that operation may be retried
+	InterruptedOperation string `bson:"interrupted_operation,omitempty" json:"interrupted_operation,omitempty"`
+	// TraceID is the trace of the last create or delete operation
+	TraceID string `bson:"trace_id,omitempty" json:"trace_id,omitempty"`
 
 	// Identifiers
 	AlertsEmail            string             `bson:"alerts_email" json:"alerts_email"`
@@ -91,12 +104,13 @@ type Cluster struct {
 	GoogleAuth       GoogleAuth       `bson:"google_auth,omitempty" json:"google_auth,omitempty"`
 	K3sAuth          K3sAuth          `bson:"k3s_auth,omitempty" json:"k3s_auth,omitempty"`
 
-	GitopsTemplateURL    string `bson:"gitops_template_url" json:"gitops_template_url"`
-	GitopsTemplateBranch string `bson:"gitops_template_branch" json:"gitops_template_branch"`
-	GitProvider          string `bson:"git_provider" json:"git_provider"`
-	GitProtocol          string `bson:"git_protocol" json:"git_protocol"`
-	GitHost              string `bson:"git_host" json:"git_host"`
-	GitlabOwnerGroupID   int    `bson:"gitlab_owner_group_id" json:"gitlab_owner_group_id"`
+	GitopsTemplateURL    string    `bson:"gitops_template_url" json:"gitops_template_url"`
+	GitopsTemplateBranch string    `bson:"gitops_template_branch" json:"gitops_template_branch"`
+	GitProvider          string    `bson:"git_provider" json:"git_provider"`
+	GitProtocol          string    `bson:"git_protocol" json:"git_protocol"`
+	GitHost              string    `bson:"git_host" json:"git_host"`
+	GitServer            GitServer `bson:"git_server,omitempty" json:"git_server,omitempty"`
+	GitlabOwnerGroupID   int       `bson:"gitlab_owner_group_id" json:"gitlab_owner_group_id"`
 
 	AtlantisWebhookSecret string `bson:"atlantis_webhook_secret" json:"atlantis_webhook_secret"`
 	AtlantisWebhookURL    string `bson:"atlantis_webhook_url" json:"atlantis_webhook_url"`
@@ -124,8 +138,14 @@ type Cluster struct {
 	// Azure
 	AzureDNSZoneResourceGroup string `bson:"azure_dns_zone_resource_group,omitempty" json:"azure_dns_zone_resource_group,omitempty"`
 
-	// Telemetry
-	UseTelemetry bool `bson:"use_telemetry"`
+	// Certificates expiring within the monitoring window, as of the last check
+	ExpiringCertificates      []ClusterCertificate `bson:"expiring_certificates,omitempty" json:"expiring_certificates,omitempty"`
+	CertificatesLastCheckedAt string               `bson:"certificates_last_checked_at,omitempty" json:"certificates_last_checked_at,omitempty"`
+
+	// Telemetry, no event about the cluster or its workload clusters is sent when
+	// false. Records written before the setting existed leave it unset, see
+	// TelemetryEnabled.
+	UseTelemetry *bool `bson:"use_telemetry,omitempty"`
 
 	// Checks
 	InstallKubefirstPro            bool              `bson:"install_kubefirst_pro,omitempty" json:"install_kubefirst_pro,omitempty"`
@@ -159,6 +179,39 @@ type StateStoreDetails struct {
 	Hostname            string `bson:"hostname,omitempty" json:"hostname,omitempty"`
 	AWSStateStoreBucket string `bson:"aws_state_store_bucket,omitempty" json:"aws_state_store_bucket,omitempty"`
 	AWSArtifactsBucket  string `bson:"aws_artifacts_bucket,omitempty" json:"aws_artifacts_bucket,omitempty"`
+	// Backend is the terraform backend of a migrated state store, empty when
+	// the state store is the one created with the cluster
+	Backend string `bson:"backend,omitempty" json:"backend,omitempty"`
+}
+
+// StateStoreMigrationRequest describes the bucket the state store of a
+// cluster is migrated to
+type StateStoreMigrationRequest struct {
+	// Backend is s3 for s3 compatible stores, gcs or azurerm, defaults to s3
+	Backend string `json:"backend,omitempty" binding:"omitempty,oneof=s3 gcs azurerm"`
+	// Required for s3 compatible stores
+	Hostname string `json:"hostname,omitempty"`
+	// The blob container for azurerm
+	BucketName string `json:"bucket_name" binding:"required"`
+	// Required for clusters with a separate artifacts bucket
+	ArtifactsBucketName string                `json:"artifacts_bucket_name,omitempty"`
+	Region              string                `json:"region,omitempty"`
+	Credentials         StateStoreCredentials `json:"credentials" binding:"required"`
+}
+
+// StateStoreMigrationResult describes a completed state store migration
+type StateStoreMigrationResult struct {
+	StateStoreDetails StateStoreDetails `json:"state_store_details"`
+	Objects           []MigratedObject  `json:"objects"`
+	GitopsFiles       []string          `json:"gitops_files"`
+}
+
+// MigratedObject is an object copied to the new state store, verified by checksum
+type MigratedObject struct {
+	Bucket string `json:"bucket"`
+	Key    string `json:"key"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
 }
 
 // PushBucketObject
@@ -168,6 +221,30 @@ type PushBucketObject struct {
 	ContentType    string `json:"content_type"`
 }
 
+// ClusterCertificate describes a tls certificate served by a cluster
+type ClusterCertificate struct {
+	Name       string    `json:"name"`
+	Namespace  string    `json:"namespace"`
+	SecretName string    `json:"secret_name"`
+	Issuer     string    `json:"issuer,omitempty"`
+	DNSNames   []string  `json:"dns_names,omitempty"`
+	NotBefore  time.Time `json:"not_before,omitempty"`
+	NotAfter   time.Time `json:"not_after,omitempty"`
+	// Ready and RenewalTime are reported by cert-manager for managed certificates
+	Managed     bool       `json:"managed"`
+	Ready       bool       `json:"ready"`
+	RenewalTime *time.Time `json:"renewal_time,omitempty"`
+	// Status is one of valid, expiring, expired or pending
+	Status string `json:"status"`
+}
+
+// BucketObject describes an object stored in a cluster state store bucket
+type BucketObject struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"last_modified"`
+}
+
 type ProxyImportRequest struct {
 	Body Cluster `bson:"body" json:"body"`
 	URL  string  `bson:"url" json:"url"`
@@ -185,6 +262,12 @@ type Environment struct {
 	CreationTimestamp string             `bson:"creation_timestamp" json:"creation_timestamp"`
 }
 
+// TelemetryEnabled reports whether events about the cluster may be sent. An
+// unset setting is enabled, USE_TELEMETRY still applies to every cluster.
+func (cl *Cluster) TelemetryEnabled() bool {
+	return cl.UseTelemetry == nil || *cl.UseTelemetry
+}
+
 type WorkloadCluster struct {
 	AdminEmail        string      `bson:"admin_email,omitempty" json:"admin_email,omitempty"`
 	CloudProvider     string      `bson:"cloud_provider,omitempty" json:"cloud_provider,omitempty"`
@@ -12,6 +12,26 @@ type GitopsCatalogApps struct {
 	Apps []GitopsCatalogApp `bson:"apps" json:"apps" yaml:"apps"`
 }
 
+// GitopsCatalogAppsPage is a filtered, paginated view of the gitops catalog
+type GitopsCatalogAppsPage struct {
+	Name     string             `bson:"name" json:"name"`
+	Apps     []GitopsCatalogApp `bson:"apps" json:"apps"`
+	Total    int                `bson:"total" json:"total"`
+	Page     int                `bson:"page" json:"page"`
+	PageSize int                `bson:"page_size" json:"page_size"`
+}
+
+// GitopsCatalogCategory describes a gitops catalog category and how many apps it holds
+type GitopsCatalogCategory struct {
+	Name  string `bson:"name" json:"name"`
+	Count int    `bson:"count" json:"count"`
+}
+
+// GitopsCatalogCategories lists the categories in the gitops catalog
+type GitopsCatalogCategories struct {
+	Categories []GitopsCatalogCategory `bson:"categories" json:"categories"`
+}
+
 // GitopsCatalogApp describes a Kubefirst gitops catalog application
 type GitopsCatalogApp struct {
 	Category      string                 `bson:"category" json:"category" yaml:"category"`
@@ -24,6 +44,7 @@ type GitopsCatalogApp struct {
 	SecretKeys    []GitopsCatalogAppKeys `bson:"secret_keys" json:"secret_keys" yaml:"secretKeys"`
 	CloudDenylist []string               `bson:"cloudDenylist" json:"cloudDenylist" yaml:"cloudDenylist"`
 	GitDenylist   []string               `bson:"gitDenylist" json:"gitDenylist" yaml:"gitDenylist"`
+	DependsOn     []string               `bson:"dependsOn,omitempty" json:"dependsOn,omitempty" yaml:"dependsOn,omitempty"`
 }
 
 // GitopsCatalogAppSecretKey describes a required secret value when creating a
@@ -46,6 +67,83 @@ type GitopsCatalogAppCreateRequest struct {
 	Environment         string                 `bson:"environment" json:"environment"`
 }
 
+// GitopsCatalogAppUpdateRequest describes a request to change the config and secret
+// values of a service that has already been installed on a cluster
+type GitopsCatalogAppUpdateRequest struct {
+	IsTemplate          bool                   `bson:"is_template" json:"is_template"`
+	User                string                 `bson:"user" json:"user"`
+	SecretKeys          []GitopsCatalogAppKeys `bson:"secret_keys,omitempty" json:"secret_keys,omitempty"`
+	ConfigKeys          []GitopsCatalogAppKeys `bson:"config_keys,omitempty" json:"config_keys,omitempty"`
+	WorkloadClusterName string                 `bson:"workload_cluster_name" json:"workload_cluster_name"`
+	Environment         string                 `bson:"environment" json:"environment"`
+}
+
+// GitopsCatalogAppBulkCreateRequest describes a request to install several gitops
+// catalog apps on a cluster, ordered by their declared dependencies
+type GitopsCatalogAppBulkCreateRequest struct {
+	User                string                            `bson:"user" json:"user"`
+	WorkloadClusterName string                            `bson:"workload_cluster_name" json:"workload_cluster_name"`
+	Environment         string                            `bson:"environment" json:"environment"`
+	Apps                []GitopsCatalogAppBulkCreateEntry `bson:"apps" json:"apps" binding:"required,min=1,dive"`
+}
+
+// GitopsCatalogAppBulkCreateEntry describes a single app in a bulk install request
+type GitopsCatalogAppBulkCreateEntry struct {
+	Name       string                 `bson:"name" json:"name" binding:"required"`
+	SecretKeys []GitopsCatalogAppKeys `bson:"secret_keys,omitempty" json:"secret_keys,omitempty"`
+	ConfigKeys []GitopsCatalogAppKeys `bson:"config_keys,omitempty" json:"config_keys,omitempty"`
+}
+
+// GitopsCatalogAppBulkCreateResult reports the outcome of installing a single app
+// as part of a bulk install
+type GitopsCatalogAppBulkCreateResult struct {
+	Name   string `bson:"name" json:"name"`
+	Wave   int    `bson:"wave" json:"wave"`
+	Status string `bson:"status" json:"status"`
+	Error  string `bson:"error,omitempty" json:"error,omitempty"`
+}
+
+// GitopsCatalogAppBulkCreateResponse reports the progress of a bulk install with
+// the results of the waves installed so far
+type GitopsCatalogAppBulkCreateResponse struct {
+	Status  string                             `bson:"status" json:"status"`
+	Error   string                             `bson:"error,omitempty" json:"error,omitempty"`
+	Results []GitopsCatalogAppBulkCreateResult `bson:"results" json:"results"`
+}
+
+// GitopsCatalogAppMultiTargetCreateRequest describes a request to install the same
+// gitops catalog app on several clusters in a single commit
+type GitopsCatalogAppMultiTargetCreateRequest struct {
+	User       string                   `bson:"user" json:"user"`
+	SecretKeys []GitopsCatalogAppKeys   `bson:"secret_keys,omitempty" json:"secret_keys,omitempty"`
+	ConfigKeys []GitopsCatalogAppKeys   `bson:"config_keys,omitempty" json:"config_keys,omitempty"`
+	Targets    []GitopsCatalogAppTarget `bson:"targets" json:"targets" binding:"required,min=1,dive"`
+}
+
+// GitopsCatalogAppTarget describes a single cluster a gitops catalog app is installed on.
+// An empty workload cluster name targets the management cluster. Config keys override
+// the request-wide config keys with the same name.
+type GitopsCatalogAppTarget struct {
+	WorkloadClusterName string                 `bson:"workload_cluster_name" json:"workload_cluster_name"`
+	Environment         string                 `bson:"environment" json:"environment"`
+	ConfigKeys          []GitopsCatalogAppKeys `bson:"config_keys,omitempty" json:"config_keys,omitempty"`
+}
+
+// GitopsCatalogAppTargetResult reports the outcome of installing an app on a single target
+type GitopsCatalogAppTargetResult struct {
+	ClusterName  string `bson:"cluster_name" json:"cluster_name"`
+	Environment  string `bson:"environment" json:"environment"`
+	RegistryPath string `bson:"registry_path" json:"registry_path"`
+	Status       string `bson:"status" json:"status"`
+	Error        string `bson:"error,omitempty" json:"error,omitempty"`
+}
+
+// GitopsCatalogAppMultiTargetCreateResponse lists the per-target results of a
+// multi-target install
+type GitopsCatalogAppMultiTargetCreateResponse struct {
+	Results []GitopsCatalogAppTargetResult `bson:"results" json:"results"`
+}
+
 // GitopsCatalogAppValidateRequest
 type GitopsCatalogAppValidateRequest struct {
 	CanDeleteService bool `bson:"can_delete_service" json:"can_delete_service"`
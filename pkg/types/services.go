@@ -15,6 +15,19 @@ type Service struct {
 	Links       []string `bson:"links" json:"links"`
 	Status      string   `bson:"status" json:"status"`
 	CreatedBy   string   `bson:"created_by" json:"created_by"`
+
+	// CatalogVersion is the gitops catalog revision the service was rendered from
+	CatalogVersion string          `bson:"catalog_version,omitempty" json:"catalog_version,omitempty"`
+	UpdatedBy      []ServiceUpdate `bson:"updated_by,omitempty" json:"updated_by,omitempty"`
+	// ConfigKeys are the config values the service was rendered with, updates
+	// only need to provide the values that change
+	ConfigKeys []GitopsCatalogAppKeys `bson:"config_keys,omitempty" json:"config_keys,omitempty"`
+}
+
+// ServiceUpdate records a single change made to an installed service
+type ServiceUpdate struct {
+	User      string `bson:"user" json:"user"`
+	Timestamp string `bson:"timestamp" json:"timestamp"`
 }
 
 // ClusterServiceList tracks services per cluster
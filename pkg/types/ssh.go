@@ -10,4 +10,7 @@ type EvalSSHKeyRequest struct {
 	GitProvider     string
 	GitlabGroupFlag string
 	GitToken        string
+	GitServer       GitServer
+	// PublicKey is the kbot public key added to the gitlab user
+	PublicKey string
 }
@@ -0,0 +1,43 @@
+package types
+
+import "time"
+
+// VaultSecret is a secret of the vault kv store
+type VaultSecret struct {
+	Path     string                 `json:"path"`
+	Version  int                    `json:"version"`
+	Data     map[string]interface{} `json:"data,omitempty"`
+	Metadata *VaultSecretMetadata   `json:"metadata,omitempty"`
+}
+
+// VaultSecretMetadata describes the version history of a vault secret
+type VaultSecretMetadata struct {
+	CurrentVersion int                  `json:"current_version"`
+	OldestVersion  int                  `json:"oldest_version"`
+	MaxVersions    int                  `json:"max_versions"`
+	CreatedTime    time.Time            `json:"created_time"`
+	UpdatedTime    time.Time            `json:"updated_time"`
+	Versions       []VaultSecretVersion `json:"versions"`
+}
+
+// VaultSecretVersion describes a version of a vault secret
+type VaultSecretVersion struct {
+	Version      int        `json:"version"`
+	CreatedTime  time.Time  `json:"created_time"`
+	DeletionTime *time.Time `json:"deletion_time,omitempty"`
+	Destroyed    bool       `json:"destroyed"`
+}
+
+// VaultSecretList lists the secrets and folders below a vault kv path,
+// folders end with a slash
+type VaultSecretList struct {
+	Path string   `json:"path"`
+	Keys []string `json:"keys"`
+}
+
+// VaultSecretWriteRequest writes a new version of a vault secret
+type VaultSecretWriteRequest struct {
+	Data map[string]interface{} `json:"data" binding:"required"`
+	// CheckAndSet only writes when the current version matches, 0 only writes new secrets
+	CheckAndSet *int `json:"cas,omitempty"`
+}
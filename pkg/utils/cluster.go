@@ -24,8 +24,9 @@ func PutClusterObject(cr *types.StateStoreCredentials, d *types.StateStoreDetail
 
 	// Initialize minio client
 	minioClient, err := minio.New(d.Hostname, &minio.Options{
-		Creds:  credentials.NewStaticV4(cr.AccessKeyID, cr.SecretAccessKey, cr.SessionToken),
-		Secure: true,
+		Creds:     credentials.NewStaticV4(cr.AccessKeyID, cr.SecretAccessKey, cr.SessionToken),
+		Secure:    true,
+		Transport: httpCommon.TracedTransport(),
 	})
 	if err != nil {
 		return fmt.Errorf("error initializing minio client: %w", err)
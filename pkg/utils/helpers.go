@@ -30,14 +30,11 @@ var (
 	GetAvailableDiskSize = internal.GetAvailableDiskSize
 	OpenBrowser          = internal.OpenBrowser
 	ResetK1Dir           = internal.ResetK1Dir
-	SetupViper           = internal.SetupViper
 	OpenLogFile          = internal.OpenLogFile
 )
 
 // helper exports
 var (
-	DisplayLogHints       = helpers.DisplayLogHints
-	TestEndpointTLS       = helpers.TestEndpointTLS
-	SetClusterStatusFlags = helpers.SetClusterStatusFlags
-	GetClusterStatusFlags = helpers.GetClusterStatusFlags
+	DisplayLogHints = helpers.DisplayLogHints
+	TestEndpointTLS = helpers.TestEndpointTLS
 )
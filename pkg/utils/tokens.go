@@ -11,16 +11,13 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/konstructio/kubefirst-api/internal/constants"
-	"github.com/konstructio/kubefirst-api/internal/env"
+	"github.com/konstructio/kubefirst-api/internal/gitHost"
 	"github.com/konstructio/kubefirst-api/pkg/providerConfigs"
 	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
 	"github.com/thanhpk/randstr"
 )
 
-func CreateTokensFromDatabaseRecord(cl *pkgtypes.Cluster, registryPath string, secretStoreRef string, project string, clusterDestination string, environment string, clusterName string) *providerConfigs.GitopsDirectoryValues {
-	env, _ := env.GetEnv(constants.SilenceGetEnv)
-
+func CreateTokensFromDatabaseRecord(cl *pkgtypes.Cluster, registryPath string, secretStoreRef string, project string, clusterDestination string, environment string, clusterName string, kubefirstVersion string) *providerConfigs.GitopsDirectoryValues {
 	var fullDomainName string
 	if cl.SubdomainName != "" {
 		fullDomainName = fmt.Sprintf("%s.%s", cl.SubdomainName, cl.DomainName)
@@ -58,11 +55,7 @@ func CreateTokensFromDatabaseRecord(cl *pkgtypes.Cluster, registryPath string, s
 		externalDNSProviderSecretKey = fmt.Sprintf("%s-auth", cl.CloudProvider)
 	}
 
-	containerRegistryHost := "ghcr.io"
-
-	if cl.GitProvider == "gitlab" {
-		containerRegistryHost = "registry.gitlab.com"
-	}
+	containerRegistryHost := gitHost.ContainerRegistryHost(cl.GitProvider, cl.GitServer)
 
 	// Updating cluster name for workload clusters
 	clusterNameToken := cl.ClusterName
@@ -84,7 +77,8 @@ func CreateTokensFromDatabaseRecord(cl *pkgtypes.Cluster, registryPath string, s
 		KubefirstTeam:                  cl.KubefirstTeam,
 		NodeType:                       cl.NodeType,
 		NodeCount:                      cl.NodeCount,
-		KubefirstVersion:               env.KubefirstVersion,
+		KubefirstVersion:               kubefirstVersion,
+		UseTelemetry:                   strconv.FormatBool(cl.TelemetryEnabled()),
 		ArgoCDIngressURL:               fmt.Sprintf("https://argocd.%s", fullDomainName),
 		ArgoCDIngressNoHTTPSURL:        fmt.Sprintf("argocd.%s", fullDomainName),
 		ArgoWorkflowsIngressURL:        fmt.Sprintf("https://argo.%s", fullDomainName),
@@ -110,7 +104,7 @@ func CreateTokensFromDatabaseRecord(cl *pkgtypes.Cluster, registryPath string, s
 		GitURL:               cl.GitopsTemplateURL,
 		GitopsRepoURL:        destinationGitopsRepoURL,
 
-		GitHubHost:  fmt.Sprintf("https://github.com/%s/gitops.git", cl.GitAuth.Owner),
+		GitHubHost:  fmt.Sprintf("https://%s/%s/gitops.git", cl.GitHost, cl.GitAuth.Owner),
 		GitHubOwner: cl.GitAuth.Owner,
 		GitHubUser:  cl.GitAuth.User,
 
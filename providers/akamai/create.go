@@ -7,147 +7,135 @@ See the LICENSE file for more details.
 package akamai
 
 import (
+	"context"
 	"fmt"
-	"os"
 
 	"github.com/konstructio/kubefirst-api/internal/constants"
 	"github.com/konstructio/kubefirst-api/internal/controller"
+	"github.com/konstructio/kubefirst-api/internal/env"
 	"github.com/konstructio/kubefirst-api/internal/k8s"
 	"github.com/konstructio/kubefirst-api/internal/secrets"
-	"github.com/konstructio/kubefirst-api/internal/ssl"
+	"github.com/konstructio/kubefirst-api/internal/tracing"
 	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
 	log "github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
 )
 
-func CreateAkamaiCluster(definition *pkgtypes.ClusterDefinition) error {
-	ctrl := controller.ClusterController{}
+func CreateAkamaiCluster(ctx context.Context, e env.Env, definition *pkgtypes.ClusterDefinition) error {
+	ctx, span := tracing.Start(ctx, "cluster.create",
+		attribute.String("cluster.name", definition.ClusterName),
+		attribute.String("cloud.provider", definition.CloudProvider),
+	)
+	defer span.End()
+
+	ctrl := controller.ClusterController{Context: ctx, Env: e}
 	err := ctrl.InitController(definition)
 	if err != nil {
 		return fmt.Errorf("failed to initialize controller: %w", err)
 	}
 
 	ctrl.Cluster.InProgress = true
+	ctrl.Cluster.TraceID = tracing.TraceID(ctx)
 	err = secrets.UpdateCluster(ctrl.KubernetesClient, ctrl.Cluster)
 	if err != nil {
 		return fmt.Errorf("error updating cluster status: %w", err)
 	}
 
-	err = ctrl.DownloadTools(ctrl.ProviderConfig.ToolsDir)
+	err = ctrl.RunStep("DownloadTools", func() error { return ctrl.DownloadTools(ctrl.ProviderConfig.ToolsDir) })
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error downloading tools: %w", err)
 	}
 
-	err = ctrl.DomainLivenessTest()
+	err = ctrl.RunStep("DomainLivenessTest", ctrl.DomainLivenessTest)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error running domain liveness test: %w", err)
 	}
 
-	err = ctrl.StateStoreCredentials()
+	err = ctrl.RunStep("StateStoreCredentials", ctrl.StateStoreCredentials)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error storing state store credentials: %w", err)
 	}
 
-	err = ctrl.StateStoreCreate()
+	err = ctrl.RunStep("StateStoreCreate", ctrl.StateStoreCreate)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error creating state store: %w", err)
 	}
 
-	err = ctrl.GitInit()
+	err = ctrl.RunStep("GitInit", ctrl.GitInit)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error initializing git: %w", err)
 	}
 
-	err = ctrl.InitializeBot()
+	err = ctrl.RunStep("InitializeBot", ctrl.InitializeBot)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error initializing bot: %w", err)
 	}
 
-	err = ctrl.RepositoryPrep()
+	err = ctrl.RunStep("RepositoryPrep", ctrl.RepositoryPrep)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error preparing repository: %w", err)
 	}
 
-	err = ctrl.RunGitTerraform()
+	err = ctrl.RunStep("RunGitTerraform", ctrl.RunGitTerraform)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error running git terraform: %w", err)
 	}
 
-	err = ctrl.RepositoryPush()
+	err = ctrl.RunStep("RepositoryPush", ctrl.RepositoryPush)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error pushing repository: %w", err)
 	}
 
-	err = ctrl.CreateCluster()
+	err = ctrl.RunStep("CreateCluster", ctrl.CreateCluster)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error creating cluster: %w", err)
 	}
 
-	err = ctrl.ClusterSecretsBootstrap()
+	err = ctrl.RunStep("ClusterSecretsBootstrap", ctrl.ClusterSecretsBootstrap)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error bootstrapping cluster secrets: %w", err)
 	}
 
-	// * check for ssl restore
-	log.Info().Msg("checking for tls secrets to restore")
-	secretsFilesToRestore, err := os.ReadDir(ctrl.ProviderConfig.SSLBackupDir + "/secrets")
-	if err != nil {
-		if os.IsNotExist(err) {
-			log.Info().Msg("no files found in secrets directory, continuing")
-		} else {
-			log.Info().Msgf("unable to check for TLS secrets to restore: %s", err.Error())
-		}
-	}
+	ctrl.RestoreTLSBackup()
 
-	if len(secretsFilesToRestore) != 0 {
-		// todo would like these but requires CRD's and is not currently supported
-		// add crds ( use execShellReturnErrors? )
-		// https://raw.githubusercontent.com/cert-manager/cert-manager/v1.11.0/deploy/crds/crd-clusterissuers.yaml
-		// https://raw.githubusercontent.com/cert-manager/cert-manager/v1.11.0/deploy/crds/crd-certificates.yaml
-		// add certificates, and clusterissuers
-		log.Info().Msgf("found %d tls secrets to restore", len(secretsFilesToRestore))
-		ssl.Restore(ctrl.ProviderConfig.SSLBackupDir, ctrl.ProviderConfig.Kubeconfig)
-	} else {
-		log.Info().Msg("no files found in secrets directory, continuing")
-	}
-
-	err = ctrl.InstallArgoCD()
+	err = ctrl.RunStep("InstallArgoCD", ctrl.InstallArgoCD)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error installing argocd: %w", err)
 	}
 
-	err = ctrl.InitializeArgoCD()
+	err = ctrl.RunStep("InitializeArgoCD", ctrl.InitializeArgoCD)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error initializing argocd: %w", err)
 	}
 
-	err = ctrl.DeployRegistryApplication()
+	err = ctrl.RunStep("DeployRegistryApplication", ctrl.DeployRegistryApplication)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error deploying registry application: %w", err)
 	}
 
-	err = ctrl.WaitForVault()
+	err = ctrl.RunStep("WaitForVault", ctrl.WaitForVault)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error waiting for vault: %w", err)
 	}
 
-	err = ctrl.InitializeVault()
+	err = ctrl.RunStep("InitializeVault", ctrl.InitializeVault)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error initializing vault: %w", err)
 	}
 
@@ -175,24 +163,30 @@ func CreateAkamaiCluster(definition *pkgtypes.ClusterDefinition) error {
 		vaultStopChannel,
 	)
 
-	err = ctrl.RunVaultTerraform()
+	err = ctrl.RunStep("RunVaultTerraform", ctrl.RunVaultTerraform)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error running vault terraform: %w", err)
 	}
 
-	err = ctrl.WriteVaultSecrets()
+	err = ctrl.RunStep("WriteVaultSecrets", ctrl.WriteVaultSecrets)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error writing vault secrets: %w", err)
 	}
 
-	err = ctrl.RunUsersTerraform()
+	err = ctrl.RunStep("RunUsersTerraform", ctrl.RunUsersTerraform)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error running users terraform: %w", err)
 	}
 
+	err = ctrl.RunStep("RevokeVaultRootToken", ctrl.RevokeVaultRootToken)
+	if err != nil {
+		ctrl.UpdateClusterOnError(err)
+		return fmt.Errorf("error revoking vault root token: %w", err)
+	}
+
 	// Wait for last sync wave app transition to Running
 	log.Info().Msg("waiting for final sync wave Deployment to transition to Running")
 	crossplaneDeployment, err := k8s.ReturnDeploymentObject(
@@ -204,14 +198,14 @@ func CreateAkamaiCluster(definition *pkgtypes.ClusterDefinition) error {
 	)
 	if err != nil {
 		log.Error().Msgf("error finding crossplane Deployment: %s", err)
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error finding crossplane Deployment: %w", err)
 	}
 	log.Info().Msg("waiting on dns, tls certificates from letsencrypt and remaining sync waves.\n this may take up to 60 minutes but regularly completes in under 20 minutes")
 	_, err = k8s.WaitForDeploymentReady(kcfg.Clientset, crossplaneDeployment, 3600)
 	if err != nil {
 		log.Error().Msgf("error waiting for all Apps to sync ready state: %s", err)
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error waiting for all Apps to sync ready state: %w", err)
 	}
 	chStop := make(chan struct{}, 1)
@@ -220,10 +214,10 @@ func CreateAkamaiCluster(definition *pkgtypes.ClusterDefinition) error {
 	}()
 
 	// * export and import cluster
-	err = ctrl.ExportClusterRecord()
+	err = ctrl.RunStep("ExportClusterRecord", ctrl.ExportClusterRecord)
 	if err != nil {
 		log.Error().Msgf("error exporting cluster record: %s", err)
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error exporting cluster record: %w", err)
 	}
 
@@ -234,9 +228,9 @@ func CreateAkamaiCluster(definition *pkgtypes.ClusterDefinition) error {
 		return fmt.Errorf("error updating cluster status after provisioning: %w", err)
 	}
 
-	if err := ctrl.FinalCheck(); err != nil {
+	if err := ctrl.RunStep("FinalCheck", ctrl.FinalCheck); err != nil {
 		log.Error().Msgf("error doing final check: %s", err)
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error doing final check: %w", err)
 	}
 
@@ -7,19 +7,29 @@ See the LICENSE file for more details.
 package aws
 
 import (
+	"context"
 	"fmt"
 
 	awsext "github.com/konstructio/kubefirst-api/extensions/aws"
 	awsinternal "github.com/konstructio/kubefirst-api/internal/aws"
 	"github.com/konstructio/kubefirst-api/internal/controller"
+	"github.com/konstructio/kubefirst-api/internal/env"
 	"github.com/konstructio/kubefirst-api/internal/k8s"
 	"github.com/konstructio/kubefirst-api/internal/secrets"
+	"github.com/konstructio/kubefirst-api/internal/tracing"
 	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
 	log "github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
 )
 
-func CreateAWSCluster(definition *pkgtypes.ClusterDefinition) error {
-	ctrl := controller.ClusterController{}
+func CreateAWSCluster(ctx context.Context, e env.Env, definition *pkgtypes.ClusterDefinition) error {
+	ctx, span := tracing.Start(ctx, "cluster.create",
+		attribute.String("cluster.name", definition.ClusterName),
+		attribute.String("cloud.provider", definition.CloudProvider),
+	)
+	defer span.End()
+
+	ctrl := controller.ClusterController{Context: ctx, Env: e}
 
 	if err := ctrl.InitController(definition); err != nil {
 		return fmt.Errorf("error initializing controller: %w", err)
@@ -27,6 +37,7 @@ func CreateAWSCluster(definition *pkgtypes.ClusterDefinition) error {
 
 	// Update cluster status in database
 	ctrl.Cluster.InProgress = true
+	ctrl.Cluster.TraceID = tracing.TraceID(ctx)
 	if err := secrets.UpdateCluster(ctrl.KubernetesClient, ctrl.Cluster); err != nil {
 		return fmt.Errorf("error updating cluster status: %w", err)
 	}
@@ -39,65 +50,65 @@ func CreateAWSCluster(definition *pkgtypes.ClusterDefinition) error {
 		ctrl.AWSAuth.SessionToken,
 	)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error creating aws client: %w", err)
 	}
 
 	awsClient := &awsinternal.Configuration{Config: conf}
 
 	if _, err := awsClient.CheckAvailabilityZones(ctrl.CloudRegion); err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error checking availability zones: %w", err)
 	}
 
-	if err := ctrl.DownloadTools(ctrl.ProviderConfig.ToolsDir); err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+	if err := ctrl.RunStep("DownloadTools", func() error { return ctrl.DownloadTools(ctrl.ProviderConfig.ToolsDir) }); err != nil {
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error downloading tools: %w", err)
 	}
 
-	if err := ctrl.DomainLivenessTest(); err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+	if err := ctrl.RunStep("DomainLivenessTest", ctrl.DomainLivenessTest); err != nil {
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error running domain liveness test: %w", err)
 	}
 
-	if err := ctrl.StateStoreCredentials(); err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+	if err := ctrl.RunStep("StateStoreCredentials", ctrl.StateStoreCredentials); err != nil {
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error getting state store credentials: %w", err)
 	}
 
-	if err := ctrl.GitInit(); err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+	if err := ctrl.RunStep("GitInit", ctrl.GitInit); err != nil {
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error initializing git: %w", err)
 	}
 
-	if err := ctrl.InitializeBot(); err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+	if err := ctrl.RunStep("InitializeBot", ctrl.InitializeBot); err != nil {
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error initializing bot: %w", err)
 	}
 
 	// Where detokeinization happens
-	if err := ctrl.RepositoryPrep(); err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+	if err := ctrl.RunStep("RepositoryPrep", ctrl.RepositoryPrep); err != nil {
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error preparing repository: %w", err)
 	}
 
-	if err := ctrl.RunGitTerraform(); err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+	if err := ctrl.RunStep("RunGitTerraform", ctrl.RunGitTerraform); err != nil {
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error running git terraform: %w", err)
 	}
 
-	if err := ctrl.RepositoryPush(); err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+	if err := ctrl.RunStep("RepositoryPush", ctrl.RepositoryPush); err != nil {
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error pushing repository: %w", err)
 	}
 
-	if err := ctrl.CreateCluster(); err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+	if err := ctrl.RunStep("CreateCluster", ctrl.CreateCluster); err != nil {
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error creating cluster: %w", err)
 	}
 
-	if err := ctrl.DetokenizeKMSKeyID(); err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+	if err := ctrl.RunStep("DetokenizeKMSKeyID", ctrl.DetokenizeKMSKeyID); err != nil {
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error detokenizing KMS key ID: %w", err)
 	}
 
@@ -106,28 +117,30 @@ func CreateAWSCluster(definition *pkgtypes.ClusterDefinition) error {
 	// for all cloud providers
 	ctrl.Kcfg, err = awsext.CreateEKSKubeconfig(&ctrl.AwsClient.Config, ctrl.ClusterName)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("failed to create eks config: %w", err)
 	}
-	if err := ctrl.WaitForClusterReady(); err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+	if err := ctrl.RunStep("WaitForClusterReady", ctrl.WaitForClusterReady); err != nil {
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error waiting for cluster to be ready: %w", err)
 	}
 
 	// Cluster bootstrap (aws specific)
-	// rec, err := ctrl.GetCurrentClusterRecord()
+	// rec, err := ctrl.RunStep("GetCurrentClusterRecord", ctrl.GetCurrentClusterRecord)
 	// if err != nil {
 	// 	ctrl.HandleError(err.Error())
 	// 	return err
 	// }
 
-	if err := ctrl.InstallArgoCD(); err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+	ctrl.RestoreTLSBackup()
+
+	if err := ctrl.RunStep("InstallArgoCD", ctrl.InstallArgoCD); err != nil {
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error installing ArgoCD: %w", err)
 	}
 
-	if err := ctrl.InitializeArgoCD(); err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+	if err := ctrl.RunStep("InitializeArgoCD", ctrl.InitializeArgoCD); err != nil {
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error initializing ArgoCD: %w", err)
 	}
 
@@ -137,8 +150,8 @@ func CreateAWSCluster(definition *pkgtypes.ClusterDefinition) error {
 		return fmt.Errorf("error updating cluster status: %w", err)
 	}
 
-	if err := ctrl.ClusterSecretsBootstrap(); err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+	if err := ctrl.RunStep("ClusterSecretsBootstrap", ctrl.ClusterSecretsBootstrap); err != nil {
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error bootstrapping cluster secrets: %w", err)
 	}
 
@@ -153,13 +166,13 @@ func CreateAWSCluster(definition *pkgtypes.ClusterDefinition) error {
 		return fmt.Errorf("error updating cluster status after cluster secrets were created: %w", err)
 	}
 
-	if err := ctrl.DeployRegistryApplication(); err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+	if err := ctrl.RunStep("DeployRegistryApplication", ctrl.DeployRegistryApplication); err != nil {
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error deploying registry application: %w", err)
 	}
 
-	if err := ctrl.WaitForVault(); err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+	if err := ctrl.RunStep("WaitForVault", ctrl.WaitForVault); err != nil {
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error waiting for vault: %w", err)
 	}
 
@@ -179,29 +192,34 @@ func CreateAWSCluster(definition *pkgtypes.ClusterDefinition) error {
 		vaultStopChannel,
 	)
 
-	if err := ctrl.InitializeVault(); err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+	if err := ctrl.RunStep("InitializeVault", ctrl.InitializeVault); err != nil {
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error initializing vault: %w", err)
 	}
 
-	if err := ctrl.RunVaultTerraform(); err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+	if err := ctrl.RunStep("RunVaultTerraform", ctrl.RunVaultTerraform); err != nil {
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error running vault terraform: %w", err)
 	}
 
-	if err := ctrl.WriteVaultSecrets(); err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+	if err := ctrl.RunStep("WriteVaultSecrets", ctrl.WriteVaultSecrets); err != nil {
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error writing vault secrets: %w", err)
 	}
 
-	if err := ctrl.RunUsersTerraform(); err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+	if err := ctrl.RunStep("RunUsersTerraform", ctrl.RunUsersTerraform); err != nil {
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error running users terraform: %w", err)
 	}
 
-	if err := ctrl.FinalCheck(); err != nil {
+	if err := ctrl.RunStep("RevokeVaultRootToken", ctrl.RevokeVaultRootToken); err != nil {
+		ctrl.UpdateClusterOnError(err)
+		return fmt.Errorf("error revoking vault root token: %w", err)
+	}
+
+	if err := ctrl.RunStep("FinalCheck", ctrl.FinalCheck); err != nil {
 		log.Error().Msgf("error doing final check: %s", err)
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error doing final check: %w", err)
 	}
 
@@ -7,8 +7,8 @@ See the LICENSE file for more details.
 package aws
 
 import (
+	"context"
 	"fmt"
-	"strconv"
 	"time"
 
 	awsext "github.com/konstructio/kubefirst-api/extensions/aws"
@@ -17,27 +17,39 @@ import (
 	"github.com/konstructio/kubefirst-api/internal/argocd"
 	awsinternal "github.com/konstructio/kubefirst-api/internal/aws"
 	"github.com/konstructio/kubefirst-api/internal/constants"
+	"github.com/konstructio/kubefirst-api/internal/controller"
+	"github.com/konstructio/kubefirst-api/internal/env"
 	"github.com/konstructio/kubefirst-api/internal/errors"
-	gitlab "github.com/konstructio/kubefirst-api/internal/gitlab"
+	"github.com/konstructio/kubefirst-api/internal/gitProvider"
 	"github.com/konstructio/kubefirst-api/internal/httpCommon"
 	"github.com/konstructio/kubefirst-api/internal/k8s"
 	"github.com/konstructio/kubefirst-api/internal/secrets"
+	apitelemetry "github.com/konstructio/kubefirst-api/internal/telemetry"
+	"github.com/konstructio/kubefirst-api/internal/tracing"
 	"github.com/konstructio/kubefirst-api/internal/utils"
 	"github.com/konstructio/kubefirst-api/pkg/providerConfigs"
 	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
 	"github.com/kubefirst/metrics-client/pkg/telemetry"
 	log "github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // DeleteAWSCluster
-func DeleteAWSCluster(cl *pkgtypes.Cluster, telemetryEvent telemetry.TelemetryEvent) error {
-	telemetry.SendEvent(telemetryEvent, telemetry.ClusterDeleteStarted, "")
+func DeleteAWSCluster(ctx context.Context, e env.Env, cl *pkgtypes.Cluster, telemetryEvent telemetry.TelemetryEvent) error {
+	ctx, span := tracing.Start(ctx, "cluster.delete",
+		attribute.String("cluster.name", cl.ClusterName),
+		attribute.String("cloud.provider", cl.CloudProvider),
+	)
+	defer span.End()
+
+	apitelemetry.SendEvent(cl.TelemetryEnabled(), telemetryEvent, telemetry.ClusterDeleteStarted, "")
 
 	// Instantiate provider config
 	config, err := providerConfigs.GetConfig(
 		cl.ClusterName,
 		cl.DomainName,
 		cl.GitProvider,
+		cl.GitHost,
 		cl.GitAuth.Owner,
 		cl.GitProtocol,
 		cl.CloudflareAuth.APIToken,
@@ -50,92 +62,32 @@ func DeleteAWSCluster(cl *pkgtypes.Cluster, telemetryEvent telemetry.TelemetryEv
 	kcfg := utils.GetKubernetesClient(cl.ClusterName)
 
 	cl.Status = constants.ClusterStatusDeleting
+	cl.TraceID = tracing.TraceID(ctx)
 
 	if err := secrets.UpdateCluster(kcfg.Clientset, *cl); err != nil {
 		return fmt.Errorf("error updating cluster status for cluster %s: %w", cl.ClusterName, err)
 	}
 
-	switch cl.GitProvider {
-	case "github":
-		if cl.GitTerraformApplyCheck {
-			log.Info().Msg("destroying github resources with terraform")
-
-			tfEntrypoint := config.GitopsDir + "/terraform/github"
-			tfEnvs := map[string]string{}
-			tfEnvs = awsext.GetAwsTerraformEnvs(tfEnvs, cl)
-			tfEnvs = awsext.GetGithubTerraformEnvs(tfEnvs, cl)
-			err := terraformext.InitDestroyAutoApprove(config.TerraformClient, tfEntrypoint, tfEnvs)
-			if err != nil {
-				log.Error().Msgf("error executing terraform destroy %s", tfEntrypoint)
-				errors.HandleClusterError(cl, err.Error())
-				return fmt.Errorf("failed to execute terraform destroy for GitHub resources at %s: %w", tfEntrypoint, err)
-			}
-			log.Info().Msg("github resources terraform destroyed")
-
-			kcfg := utils.GetKubernetesClient(cl.ClusterName)
-
-			cl.GitTerraformApplyCheck = false
-			err = secrets.UpdateCluster(kcfg.Clientset, *cl)
-			if err != nil {
-				return fmt.Errorf("error updating cluster after destroying github resources for cluster %s: %w", cl.ClusterName, err)
-			}
-		}
-	case "gitlab":
-		if cl.GitTerraformApplyCheck {
-			log.Info().Msg("destroying gitlab resources with terraform")
-			gitlabClient, err := gitlab.NewGitLabClient(cl.GitAuth.Token, cl.GitAuth.Owner)
-			if err != nil {
-				return fmt.Errorf("error creating gitlab client for cluster %s: %w", cl.ClusterName, err)
-			}
-
-			// Before removing Terraform resources, remove any container registry repositories
-			// since failing to remove them beforehand will result in an apply failure
-			projectsForDeletion := []string{"gitops", "metaphor"}
-			for _, project := range projectsForDeletion {
-				projectExists, err := gitlabClient.CheckProjectExists(project)
-				if err != nil {
-					log.Error().Msgf("could not check for existence of project %s: %s", project, err)
-				}
-				if projectExists {
-					log.Info().Msgf("checking project %s for container registries...", project)
-					crr, err := gitlabClient.GetProjectContainerRegistryRepositories(project)
-					if err != nil {
-						log.Error().Msgf("could not retrieve container registry repositories: %s", err)
-					}
-					if len(crr) > 0 {
-						for _, cr := range crr {
-							err := gitlabClient.DeleteContainerRegistryRepository(project, cr.ID)
-							if err != nil {
-								log.Error().Msgf("error deleting container registry repository: %s", err)
-							}
-						}
-					} else {
-						log.Info().Msgf("project %s does not have any container registries, skipping", project)
-					}
-				} else {
-					log.Info().Msgf("project %s does not exist, skipping", project)
-				}
-			}
+	if err := errors.CheckpointDelete(cl, "DestroyGitResources"); err != nil {
+		return err
+	}
 
-			tfEntrypoint := config.GitopsDir + "/terraform/gitlab"
-			tfEnvs := map[string]string{}
-			tfEnvs = awsext.GetAwsTerraformEnvs(tfEnvs, cl)
-			tfEnvs = awsext.GetGitlabTerraformEnvs(tfEnvs, gitlabClient.ParentGroupID, cl)
-			err = terraformext.InitDestroyAutoApprove(config.TerraformClient, tfEntrypoint, tfEnvs)
-			if err != nil {
-				log.Error().Msgf("error executing terraform destroy %s", tfEntrypoint)
-				errors.HandleClusterError(cl, err.Error())
-				return fmt.Errorf("failed to execute terraform destroy for GitLab resources at %s: %w", tfEntrypoint, err)
-			}
+	provider, err := gitProvider.ForCluster(cl)
+	if err != nil {
+		return fmt.Errorf("error creating %s client for cluster %s: %w", cl.GitProvider, cl.ClusterName, err)
+	}
 
-			log.Info().Msg("gitlab resources terraform destroyed")
+	tfEnvs := map[string]string{}
+	tfEnvs = awsext.GetAwsTerraformEnvs(tfEnvs, cl)
+	tfEnvs = awsext.GetGitTerraformEnvs(tfEnvs, cl)
+	err = controller.DestroyGitResources(ctx, kcfg.Clientset, cl, provider, config.TerraformClient, config.GitopsDir, tfEnvs)
+	if err != nil {
+		errors.HandleClusterError(cl, err)
+		return err
+	}
 
-			cl.GitTerraformApplyCheck = false
-			err = secrets.UpdateCluster(kcfg.Clientset, *cl)
-			if err != nil {
-				return fmt.Errorf("error updating cluster after destroying gitlab resources for cluster %s: %w", cl.ClusterName, err)
-			}
-		}
+	if err := errors.CheckpointDelete(cl, "DeleteClusterResources"); err != nil {
+		return err
 	}
 
 	if cl.CloudTerraformApplyCheck || cl.CloudTerraformApplyFailedCheck {
@@ -147,7 +99,7 @@ func DeleteAWSCluster(cl *pkgtypes.Cluster, telemetryEvent telemetry.TelemetryEv
 				cl.AWSAuth.SessionToken,
 			)
 			if err != nil {
-				errors.HandleClusterError(cl, err.Error())
+				errors.HandleClusterError(cl, err)
 				return fmt.Errorf("error creating aws client for cluster %s: %w", cl.ClusterName, err)
 			}
 
@@ -201,9 +153,9 @@ func DeleteAWSCluster(cl *pkgtypes.Cluster, telemetryEvent telemetry.TelemetryEv
 
 				client := httpCommon.CustomHTTPClient(true)
 				log.Info().Msg("deleting the registry application")
-				httpCode, _, err := argocd.DeleteApplication(client, config.RegistryAppName, argocdAuthToken, "true")
+				httpCode, _, err := argocd.DeleteApplication(client, argocd.GetArgoEndpoint(e), config.RegistryAppName, argocdAuthToken, "true")
 				if err != nil {
-					errors.HandleClusterError(cl, err.Error())
+					errors.HandleClusterError(cl, err)
 					return fmt.Errorf("failed to delete ArgoCD application %s for cluster %s: %w", config.RegistryAppName, cl.ClusterName, err)
 				}
 				log.Info().Msgf("http status code %d", httpCode)
@@ -220,26 +172,22 @@ func DeleteAWSCluster(cl *pkgtypes.Cluster, telemetryEvent telemetry.TelemetryEv
 			}
 		}
 
+		if err := errors.CheckpointDelete(cl, "DestroyCloudResources"); err != nil {
+			return err
+		}
+
 		log.Info().Msg("destroying aws cloud resources")
 		tfEntrypoint := config.GitopsDir + fmt.Sprintf("/terraform/%s", cl.CloudProvider)
 		tfEnvs := map[string]string{}
 		tfEnvs = awsext.GetAwsTerraformEnvs(tfEnvs, cl)
 		tfEnvs["TF_VAR_aws_account_id"] = cl.AWSAccountID
+		tfEnvs = awsext.GetGitTerraformEnvs(tfEnvs, cl)
+		tfEnvs = provider.TerraformEnvs(tfEnvs)
 
-		switch cl.GitProvider {
-		case "github":
-			tfEnvs = awsext.GetGithubTerraformEnvs(tfEnvs, cl)
-		case "gitlab":
-			gid, err := strconv.Atoi(strconv.Itoa(cl.GitlabOwnerGroupID))
-			if err != nil {
-				return fmt.Errorf("couldn't convert gitlab group id to int for cluster %s: %w", cl.ClusterName, err)
-			}
-			tfEnvs = awsext.GetGitlabTerraformEnvs(tfEnvs, gid, cl)
-		}
-		err = terraformext.InitDestroyAutoApprove(config.TerraformClient, tfEntrypoint, tfEnvs)
+		err = terraformext.InitDestroyAutoApprove(ctx, config.TerraformClient, tfEntrypoint, tfEnvs)
 		if err != nil {
 			log.Error().Msgf("error executing terraform destroy %s", tfEntrypoint)
-			errors.HandleClusterError(cl, err.Error())
+			errors.HandleClusterError(cl, err)
 			return fmt.Errorf("failed to execute terraform destroy for AWS resources at %s: %w", tfEntrypoint, err)
 		}
 		log.Info().Msg("aws resources terraform destroyed")
@@ -258,19 +206,9 @@ func DeleteAWSCluster(cl *pkgtypes.Cluster, telemetryEvent telemetry.TelemetryEv
 	}
 
 	// remove ssh key provided one was created
-	if cl.GitProvider == "gitlab" {
-		gitlabClient, err := gitlab.NewGitLabClient(cl.GitAuth.Token, cl.GitAuth.Owner)
-		if err != nil {
-			return fmt.Errorf("error creating gitlab client for SSH key deletion for cluster %s: %w", cl.ClusterName, err)
-		}
-		log.Info().Msgf("attempting to delete managed ssh key...")
-		err = gitlabClient.DeleteUserSSHKey("kbot-ssh-key")
-		if err != nil {
-			log.Warn().Msgf("error deleting SSH key for cluster %s: %s", cl.ClusterName, err)
-		}
-	}
+	controller.DeleteKbotSSHKey(provider)
 
-	telemetry.SendEvent(telemetryEvent, telemetry.ClusterDeleteCompleted, "")
+	apitelemetry.SendEvent(cl.TelemetryEnabled(), telemetryEvent, telemetry.ClusterDeleteCompleted, "")
 
 	cl.Status = constants.ClusterStatusDeleted
 	err = secrets.UpdateCluster(kcfg.Clientset, *cl)
@@ -1,19 +1,27 @@
 package azure
 
 import (
+	"context"
 	"fmt"
-	"os"
 
 	"github.com/konstructio/kubefirst-api/internal/controller"
+	"github.com/konstructio/kubefirst-api/internal/env"
 	"github.com/konstructio/kubefirst-api/internal/secrets"
-	"github.com/konstructio/kubefirst-api/internal/ssl"
+	"github.com/konstructio/kubefirst-api/internal/tracing"
 	"github.com/konstructio/kubefirst-api/pkg/k8s"
 	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
 )
 
-func CreateAzureCluster(definition *pkgtypes.ClusterDefinition) error {
-	ctrl := controller.ClusterController{}
+func CreateAzureCluster(ctx context.Context, e env.Env, definition *pkgtypes.ClusterDefinition) error {
+	ctx, span := tracing.Start(ctx, "cluster.create",
+		attribute.String("cluster.name", definition.ClusterName),
+		attribute.String("cloud.provider", definition.CloudProvider),
+	)
+	defer span.End()
+
+	ctrl := controller.ClusterController{Context: ctx, Env: e}
 
 	log.Debug().Msg("initializing controller")
 	err := ctrl.InitController(definition)
@@ -22,6 +30,7 @@ func CreateAzureCluster(definition *pkgtypes.ClusterDefinition) error {
 	}
 
 	ctrl.Cluster.InProgress = true
+	ctrl.Cluster.TraceID = tracing.TraceID(ctx)
 	log.Debug().Msg("updating cluster secrets")
 	err = secrets.UpdateCluster(ctrl.KubernetesClient, ctrl.Cluster)
 	if err != nil {
@@ -29,137 +38,116 @@ func CreateAzureCluster(definition *pkgtypes.ClusterDefinition) error {
 	}
 
 	log.Debug().Msg("downling tools")
-	err = ctrl.DownloadTools(ctrl.ProviderConfig.ToolsDir)
+	err = ctrl.RunStep("DownloadTools", func() error { return ctrl.DownloadTools(ctrl.ProviderConfig.ToolsDir) })
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error downloading tools: %w", err)
 	}
 
 	log.Debug().Msg("checking domain liveness")
-	err = ctrl.DomainLivenessTest()
+	err = ctrl.RunStep("DomainLivenessTest", ctrl.DomainLivenessTest)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error running domain liveness test: %w", err)
 	}
 
 	log.Debug().Msg("creating state store")
-	err = ctrl.StateStoreCredentials()
+	err = ctrl.RunStep("StateStoreCredentials", ctrl.StateStoreCredentials)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error creating state store: %w", err)
 	}
 
 	log.Debug().Msg("initializing git")
-	err = ctrl.GitInit()
+	err = ctrl.RunStep("GitInit", ctrl.GitInit)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error initializing git: %w", err)
 	}
 
 	log.Debug().Msg("initializing bot")
-	err = ctrl.InitializeBot()
+	err = ctrl.RunStep("InitializeBot", ctrl.InitializeBot)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error initializing bot: %w", err)
 	}
 
 	log.Debug().Msg("repository prep")
-	err = ctrl.RepositoryPrep()
+	err = ctrl.RunStep("RepositoryPrep", ctrl.RepositoryPrep)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error preparing repository: %w", err)
 	}
 
 	log.Debug().Msg("running git terraform")
-	err = ctrl.RunGitTerraform()
+	err = ctrl.RunStep("RunGitTerraform", ctrl.RunGitTerraform)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error running git terraform: %w", err)
 	}
 
 	log.Debug().Msg("pushing repository")
-	err = ctrl.RepositoryPush()
+	err = ctrl.RunStep("RepositoryPush", ctrl.RepositoryPush)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error pushing repository: %w", err)
 	}
 
 	log.Debug().Msg("pushing repository")
-	err = ctrl.CreateCluster()
+	err = ctrl.RunStep("CreateCluster", ctrl.CreateCluster)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error creating cluster: %w", err)
 	}
 
 	log.Debug().Msg("creating cluster")
-	err = ctrl.CreateCluster()
+	err = ctrl.RunStep("CreateCluster", ctrl.CreateCluster)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error creating cluster: %w", err)
 	}
 
 	log.Debug().Msg("bootstrapping cluster secrets")
-	err = ctrl.ClusterSecretsBootstrap()
+	err = ctrl.RunStep("ClusterSecretsBootstrap", ctrl.ClusterSecretsBootstrap)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error bootstrapping cluster secrets: %w", err)
 	}
 
-	// * check for ssl restore
-	log.Info().Msg("checking for tls secrets to restore")
-	secretsFilesToRestore, err := os.ReadDir(ctrl.ProviderConfig.SSLBackupDir + "/secrets")
-	if err != nil {
-		if os.IsNotExist(err) {
-			log.Info().Msg("no files found in secrets directory, continuing")
-		} else {
-			log.Info().Msgf("unable to check for TLS secrets to restore: %s", err.Error())
-		}
-	}
-
-	if len(secretsFilesToRestore) != 0 {
-		// todo would like these but requires CRD's and is not currently supported
-		// add crds ( use execShellReturnErrors? )
-		// https://raw.githubusercontent.com/cert-manager/cert-manager/v1.11.0/deploy/crds/crd-clusterissuers.yaml
-		// https://raw.githubusercontent.com/cert-manager/cert-manager/v1.11.0/deploy/crds/crd-certificates.yaml
-		// add certificates, and clusterissuers
-		log.Info().Msgf("found %d tls secrets to restore", len(secretsFilesToRestore))
-		ssl.Restore(ctrl.ProviderConfig.SSLBackupDir, ctrl.ProviderConfig.Kubeconfig)
-	} else {
-		log.Info().Msg("no files found in secrets directory, continuing")
-	}
+	ctrl.RestoreTLSBackup()
 
 	log.Debug().Msg("installing argocd")
-	err = ctrl.InstallArgoCD()
+	err = ctrl.RunStep("InstallArgoCD", ctrl.InstallArgoCD)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error installing argocd: %w", err)
 	}
 
 	log.Debug().Msg("initializing argocd")
-	err = ctrl.InitializeArgoCD()
+	err = ctrl.RunStep("InitializeArgoCD", ctrl.InitializeArgoCD)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error initializing argocd: %w", err)
 	}
 
 	log.Debug().Msg("deploying registry application")
-	err = ctrl.DeployRegistryApplication()
+	err = ctrl.RunStep("DeployRegistryApplication", ctrl.DeployRegistryApplication)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error deploying registry application: %w", err)
 	}
 
 	log.Debug().Msg("waiting for vault readiness")
-	err = ctrl.WaitForVault()
+	err = ctrl.RunStep("WaitForVault", ctrl.WaitForVault)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error waiting for vault: %w", err)
 	}
 
 	log.Debug().Msg("initializing vault")
-	err = ctrl.InitializeVault()
+	err = ctrl.RunStep("InitializeVault", ctrl.InitializeVault)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error initializing vault: %w", err)
 	}
 
@@ -187,29 +175,35 @@ func CreateAzureCluster(definition *pkgtypes.ClusterDefinition) error {
 	)
 
 	log.Debug().Msg("running vault terraform")
-	err = ctrl.RunVaultTerraform()
+	err = ctrl.RunStep("RunVaultTerraform", ctrl.RunVaultTerraform)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error running vault terraform: %w", err)
 	}
 
 	log.Debug().Msg("write vault secrets")
-	err = ctrl.WriteVaultSecrets()
+	err = ctrl.RunStep("WriteVaultSecrets", ctrl.WriteVaultSecrets)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error writing vault secrets: %w", err)
 	}
 
 	log.Debug().Msg("running users terraform")
-	err = ctrl.RunUsersTerraform()
+	err = ctrl.RunStep("RunUsersTerraform", ctrl.RunUsersTerraform)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error running users terraform: %w", err)
 	}
 
-	if err := ctrl.FinalCheck(); err != nil {
+	err = ctrl.RunStep("RevokeVaultRootToken", ctrl.RevokeVaultRootToken)
+	if err != nil {
+		ctrl.UpdateClusterOnError(err)
+		return fmt.Errorf("error revoking vault root token: %w", err)
+	}
+
+	if err := ctrl.RunStep("FinalCheck", ctrl.FinalCheck); err != nil {
 		log.Error().Msgf("error doing final check: %s", err)
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error doing final check: %w", err)
 	}
 
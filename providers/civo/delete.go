@@ -7,6 +7,7 @@ See the LICENSE file for more details.
 package civo
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -16,27 +17,39 @@ import (
 	pkg "github.com/konstructio/kubefirst-api/internal"
 	"github.com/konstructio/kubefirst-api/internal/argocd"
 	"github.com/konstructio/kubefirst-api/internal/constants"
+	"github.com/konstructio/kubefirst-api/internal/controller"
+	"github.com/konstructio/kubefirst-api/internal/env"
 	"github.com/konstructio/kubefirst-api/internal/errors"
-	gitlab "github.com/konstructio/kubefirst-api/internal/gitlab"
+	"github.com/konstructio/kubefirst-api/internal/gitProvider"
 	"github.com/konstructio/kubefirst-api/internal/httpCommon"
 	"github.com/konstructio/kubefirst-api/internal/k8s"
 	"github.com/konstructio/kubefirst-api/internal/secrets"
+	apitelemetry "github.com/konstructio/kubefirst-api/internal/telemetry"
+	"github.com/konstructio/kubefirst-api/internal/tracing"
 	"github.com/konstructio/kubefirst-api/internal/utils"
 	"github.com/konstructio/kubefirst-api/pkg/providerConfigs"
 	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
 	"github.com/kubefirst/metrics-client/pkg/telemetry"
 	log "github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // DeleteCivoCluster
-func DeleteCivoCluster(cl *pkgtypes.Cluster, telemetryEvent telemetry.TelemetryEvent) error {
-	telemetry.SendEvent(telemetryEvent, telemetry.ClusterDeleteStarted, "")
+func DeleteCivoCluster(ctx context.Context, e env.Env, cl *pkgtypes.Cluster, telemetryEvent telemetry.TelemetryEvent) error {
+	ctx, span := tracing.Start(ctx, "cluster.delete",
+		attribute.String("cluster.name", cl.ClusterName),
+		attribute.String("cloud.provider", cl.CloudProvider),
+	)
+	defer span.End()
+
+	apitelemetry.SendEvent(cl.TelemetryEnabled(), telemetryEvent, telemetry.ClusterDeleteStarted, "")
 
 	// Instantiate provider config
 	config, err := providerConfigs.GetConfig(
 		cl.ClusterName,
 		cl.DomainName,
 		cl.GitProvider,
+		cl.GitHost,
 		cl.GitAuth.Owner,
 		cl.GitProtocol,
 		cl.CloudflareAuth.APIToken,
@@ -49,75 +62,32 @@ func DeleteCivoCluster(cl *pkgtypes.Cluster, telemetryEvent telemetry.TelemetryE
 	kcfg := utils.GetKubernetesClient(cl.ClusterName)
 
 	cl.Status = constants.ClusterStatusDeleting
+	cl.TraceID = tracing.TraceID(ctx)
 
 	if err := secrets.UpdateCluster(kcfg.Clientset, *cl); err != nil {
 		return fmt.Errorf("error updating cluster status for cluster %s: %w", cl.ClusterName, err)
 	}
 
-	tfEnvs := map[string]string{}
-	var tfEntrypoint string
-
-	if cl.GitTerraformApplyCheck {
-		log.Info().Msgf("destroying %s resources with terraform", cl.GitProvider)
-		switch cl.GitProvider {
-		case "github":
-			tfEntrypoint = config.GitopsDir + "/terraform/github"
-			tfEnvs = civoext.GetCivoTerraformEnvs(tfEnvs, cl)
-			tfEnvs = civoext.GetGithubTerraformEnvs(tfEnvs, cl)
-
-		case "gitlab":
-			gitlabClient, err := gitlab.NewGitLabClient(cl.GitAuth.Token, cl.GitAuth.Owner)
-			if err != nil {
-				return fmt.Errorf("error creating GitLab client for cluster %s: %w", cl.ClusterName, err)
-			}
-
-			// Before removing Terraform resources, remove any container registry repositories
-			// since failing to remove them beforehand will result in an apply failure
-			projectsForDeletion := []string{"gitops", "metaphor"}
-			for _, project := range projectsForDeletion {
-				projectExists, err := gitlabClient.CheckProjectExists(project)
-				if err != nil {
-					log.Error().Msgf("could not check for existence of project %s: %s", project, err)
-				}
-				if projectExists {
-					log.Info().Msgf("checking project %s for container registries...", project)
-					crr, err := gitlabClient.GetProjectContainerRegistryRepositories(project)
-					if err != nil {
-						log.Error().Msgf("could not retrieve container registry repositories: %s", err)
-					}
-					if len(crr) > 0 {
-						for _, cr := range crr {
-							err := gitlabClient.DeleteContainerRegistryRepository(project, cr.ID)
-							if err != nil {
-								log.Error().Msgf("error deleting container registry repository: %s", err)
-							}
-						}
-					} else {
-						log.Info().Msgf("project %s does not have any container registries, skipping", project)
-					}
-				} else {
-					log.Info().Msgf("project %s does not exist, skipping", project)
-				}
-			}
-			tfEntrypoint = config.GitopsDir + "/terraform/gitlab"
-			tfEnvs = civoext.GetCivoTerraformEnvs(tfEnvs, cl)
-			tfEnvs = civoext.GetGitlabTerraformEnvs(tfEnvs, gitlabClient.ParentGroupID, cl)
-		}
+	if err := errors.CheckpointDelete(cl, "DestroyGitResources"); err != nil {
+		return err
+	}
 
-		err = terraformext.InitDestroyAutoApprove(config.TerraformClient, tfEntrypoint, tfEnvs)
-		if err != nil {
-			log.Info().Msgf("error executing terraform destroy %s", tfEntrypoint)
-			errors.HandleClusterError(cl, err.Error())
-			return fmt.Errorf("error executing terraform destroy for %s: %w", tfEntrypoint, err)
-		}
+	provider, err := gitProvider.ForCluster(cl)
+	if err != nil {
+		return fmt.Errorf("error creating %s client for cluster %s: %w", cl.GitProvider, cl.ClusterName, err)
+	}
 
-		log.Info().Msgf("%s resources terraform destroyed", cl.GitProvider)
+	tfEnvs := map[string]string{}
+	tfEnvs = civoext.GetCivoTerraformEnvs(tfEnvs, cl)
+	tfEnvs = civoext.GetGitTerraformEnvs(tfEnvs, cl)
+	err = controller.DestroyGitResources(ctx, kcfg.Clientset, cl, provider, config.TerraformClient, config.GitopsDir, tfEnvs)
+	if err != nil {
+		errors.HandleClusterError(cl, err)
+		return err
+	}
 
-		cl.GitTerraformApplyCheck = false
-		err = secrets.UpdateCluster(kcfg.Clientset, *cl)
-		if err != nil {
-			return fmt.Errorf("error updating cluster status after terraform destroy for cluster %s: %w", cl.ClusterName, err)
-		}
+	if err := errors.CheckpointDelete(cl, "DeleteClusterResources"); err != nil {
+		return err
 	}
 
 	if cl.CloudTerraformApplyCheck || cl.CloudTerraformApplyFailedCheck {
@@ -180,9 +150,9 @@ func DeleteCivoCluster(cl *pkgtypes.Cluster, telemetryEvent telemetry.TelemetryE
 
 				client := httpCommon.CustomHTTPClient(true)
 				log.Info().Msg("deleting the registry application")
-				httpCode, _, err := argocd.DeleteApplication(client, config.RegistryAppName, argocdAuthToken, "true")
+				httpCode, _, err := argocd.DeleteApplication(client, argocd.GetArgoEndpoint(e), config.RegistryAppName, argocdAuthToken, "true")
 				if err != nil {
-					errors.HandleClusterError(cl, err.Error())
+					errors.HandleClusterError(cl, err)
 					return fmt.Errorf("error deleting ArgoCD application for cluster %s: %w", cl.ClusterName, err)
 				}
 				log.Info().Msgf("http status code %d", httpCode)
@@ -208,21 +178,21 @@ func DeleteCivoCluster(cl *pkgtypes.Cluster, telemetryEvent telemetry.TelemetryE
 			}
 		}
 
+		if err := errors.CheckpointDelete(cl, "DestroyCloudResources"); err != nil {
+			return err
+		}
+
 		log.Info().Msg("destroying civo cloud resources")
 		tfEntrypoint := config.GitopsDir + fmt.Sprintf("/terraform/%s", cl.CloudProvider)
 		tfEnvs := map[string]string{}
 		tfEnvs = civoext.GetCivoTerraformEnvs(tfEnvs, cl)
+		tfEnvs = civoext.GetGitTerraformEnvs(tfEnvs, cl)
+		tfEnvs = provider.TerraformEnvs(tfEnvs)
 
-		switch cl.GitProvider {
-		case "github":
-			tfEnvs = civoext.GetGithubTerraformEnvs(tfEnvs, cl)
-		case "gitlab":
-			tfEnvs = civoext.GetGitlabTerraformEnvs(tfEnvs, cl.GitlabOwnerGroupID, cl)
-		}
-		err = terraformext.InitDestroyAutoApprove(config.TerraformClient, tfEntrypoint, tfEnvs)
+		err = terraformext.InitDestroyAutoApprove(ctx, config.TerraformClient, tfEntrypoint, tfEnvs)
 		if err != nil {
 			log.Printf("error executing terraform destroy %s", tfEntrypoint)
-			errors.HandleClusterError(cl, err.Error())
+			errors.HandleClusterError(cl, err)
 			return fmt.Errorf("error executing terraform destroy for %s: %w", tfEntrypoint, err)
 		}
 		log.Info().Msg("civo resources terraform destroyed")
@@ -236,19 +206,9 @@ func DeleteCivoCluster(cl *pkgtypes.Cluster, telemetryEvent telemetry.TelemetryE
 	}
 
 	// remove ssh key provided one was created
-	if cl.GitProvider == "gitlab" {
-		gitlabClient, err := gitlab.NewGitLabClient(cl.GitAuth.Token, cl.GitAuth.Owner)
-		if err != nil {
-			return fmt.Errorf("error creating GitLab client for SSH key deletion for cluster %s: %w", cl.ClusterName, err)
-		}
-		log.Info().Msg("attempting to delete managed ssh key...")
-		err = gitlabClient.DeleteUserSSHKey("kbot-ssh-key")
-		if err != nil {
-			log.Warn().Msg(err.Error())
-		}
-	}
+	controller.DeleteKbotSSHKey(provider)
 
-	telemetry.SendEvent(telemetryEvent, telemetry.ClusterDeleteCompleted, "")
+	apitelemetry.SendEvent(cl.TelemetryEnabled(), telemetryEvent, telemetry.ClusterDeleteCompleted, "")
 
 	cl.Status = constants.ClusterStatusDeleted
 	err = secrets.UpdateCluster(kcfg.Clientset, *cl)
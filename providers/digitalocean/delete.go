@@ -9,7 +9,6 @@ package digitalocean
 import (
 	"context"
 	"fmt"
-	"strconv"
 	"time"
 
 	digitaloceanext "github.com/konstructio/kubefirst-api/extensions/digitalocean"
@@ -17,28 +16,40 @@ import (
 	pkg "github.com/konstructio/kubefirst-api/internal"
 	"github.com/konstructio/kubefirst-api/internal/argocd"
 	"github.com/konstructio/kubefirst-api/internal/constants"
+	"github.com/konstructio/kubefirst-api/internal/controller"
 	"github.com/konstructio/kubefirst-api/internal/digitalocean"
+	"github.com/konstructio/kubefirst-api/internal/env"
 	"github.com/konstructio/kubefirst-api/internal/errors"
-	gitlab "github.com/konstructio/kubefirst-api/internal/gitlab"
+	"github.com/konstructio/kubefirst-api/internal/gitProvider"
 	"github.com/konstructio/kubefirst-api/internal/httpCommon"
 	"github.com/konstructio/kubefirst-api/internal/k8s"
 	"github.com/konstructio/kubefirst-api/internal/secrets"
+	apitelemetry "github.com/konstructio/kubefirst-api/internal/telemetry"
+	"github.com/konstructio/kubefirst-api/internal/tracing"
 	"github.com/konstructio/kubefirst-api/internal/utils"
 	"github.com/konstructio/kubefirst-api/pkg/providerConfigs"
 	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
 	"github.com/kubefirst/metrics-client/pkg/telemetry"
 	log "github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // DeleteDigitaloceanCluster
-func DeleteDigitaloceanCluster(cl *pkgtypes.Cluster, telemetryEvent telemetry.TelemetryEvent) error {
-	telemetry.SendEvent(telemetryEvent, telemetry.ClusterDeleteStarted, "")
+func DeleteDigitaloceanCluster(ctx context.Context, e env.Env, cl *pkgtypes.Cluster, telemetryEvent telemetry.TelemetryEvent) error {
+	ctx, span := tracing.Start(ctx, "cluster.delete",
+		attribute.String("cluster.name", cl.ClusterName),
+		attribute.String("cloud.provider", cl.CloudProvider),
+	)
+	defer span.End()
+
+	apitelemetry.SendEvent(cl.TelemetryEnabled(), telemetryEvent, telemetry.ClusterDeleteStarted, "")
 
 	// Instantiate provider config
 	config, err := providerConfigs.GetConfig(
 		cl.ClusterName,
 		cl.DomainName,
 		cl.GitProvider,
+		cl.GitHost,
 		cl.GitAuth.Owner,
 		cl.GitProtocol,
 		cl.CloudflareAuth.APIToken,
@@ -51,90 +62,32 @@ func DeleteDigitaloceanCluster(cl *pkgtypes.Cluster, telemetryEvent telemetry.Te
 	kcfg := utils.GetKubernetesClient(cl.ClusterName)
 
 	cl.Status = constants.ClusterStatusDeleting
+	cl.TraceID = tracing.TraceID(ctx)
 
 	if err := secrets.UpdateCluster(kcfg.Clientset, *cl); err != nil {
 		return fmt.Errorf("error updating cluster: %w", err)
 	}
 
-	switch cl.GitProvider {
-	case "github":
-		if cl.GitTerraformApplyCheck {
-			log.Info().Msg("destroying github resources with terraform")
-
-			tfEntrypoint := config.GitopsDir + "/terraform/github"
-			tfEnvs := map[string]string{}
-			tfEnvs = digitaloceanext.GetDigitaloceanTerraformEnvs(tfEnvs, cl)
-			tfEnvs = digitaloceanext.GetGithubTerraformEnvs(tfEnvs, cl)
-			err := terraformext.InitDestroyAutoApprove(config.TerraformClient, tfEntrypoint, tfEnvs)
-			if err != nil {
-				log.Printf("error executing terraform destroy %s", tfEntrypoint)
-				errors.HandleClusterError(cl, err.Error())
-				return fmt.Errorf("error executing terraform destroy %s: %w", tfEntrypoint, err)
-			}
-			log.Info().Msg("github resources terraform destroyed")
-
-			cl.GitTerraformApplyCheck = false
-			err = secrets.UpdateCluster(kcfg.Clientset, *cl)
-			if err != nil {
-				return fmt.Errorf("error updating cluster: %w", err)
-			}
-		}
-	case "gitlab":
-		if cl.GitTerraformApplyCheck {
-			log.Info().Msg("destroying gitlab resources with terraform")
-			gitlabClient, err := gitlab.NewGitLabClient(cl.GitAuth.Token, cl.GitAuth.Owner)
-			if err != nil {
-				return fmt.Errorf("error creating new GitLab client: %w", err)
-			}
-
-			// Before removing Terraform resources, remove any container registry repositories
-			// since failing to remove them beforehand will result in an apply failure
-			projectsForDeletion := []string{"gitops", "metaphor"}
-			for _, project := range projectsForDeletion {
-				projectExists, err := gitlabClient.CheckProjectExists(project)
-				if err != nil {
-					log.Error().Msgf("could not check for existence of project %s: %s", project, err)
-				}
-				if projectExists {
-					log.Info().Msgf("checking project %s for container registries...", project)
-					crr, err := gitlabClient.GetProjectContainerRegistryRepositories(project)
-					if err != nil {
-						log.Error().Msgf("could not retrieve container registry repositories: %s", err)
-					}
-					if len(crr) > 0 {
-						for _, cr := range crr {
-							err := gitlabClient.DeleteContainerRegistryRepository(project, cr.ID)
-							if err != nil {
-								log.Error().Msgf("error deleting container registry repository: %s", err)
-							}
-						}
-					} else {
-						log.Info().Msgf("project %s does not have any container registries, skipping", project)
-					}
-				} else {
-					log.Info().Msgf("project %s does not exist, skipping", project)
-				}
-			}
+	if err := errors.CheckpointDelete(cl, "DestroyGitResources"); err != nil {
+		return err
+	}
 
-			tfEntrypoint := config.GitopsDir + "/terraform/gitlab"
-			tfEnvs := map[string]string{}
-			tfEnvs = digitaloceanext.GetDigitaloceanTerraformEnvs(tfEnvs, cl)
-			tfEnvs = digitaloceanext.GetGitlabTerraformEnvs(tfEnvs, gitlabClient.ParentGroupID, cl)
-			err = terraformext.InitDestroyAutoApprove(config.TerraformClient, tfEntrypoint, tfEnvs)
-			if err != nil {
-				log.Info().Msgf("error executing terraform destroy %s", tfEntrypoint)
-				errors.HandleClusterError(cl, err.Error())
-				return fmt.Errorf("error executing terraform destroy %s: %w", tfEntrypoint, err)
-			}
+	provider, err := gitProvider.ForCluster(cl)
+	if err != nil {
+		return fmt.Errorf("error creating %s client for cluster %s: %w", cl.GitProvider, cl.ClusterName, err)
+	}
 
-			log.Info().Msg("gitlab resources terraform destroyed")
+	tfEnvs := map[string]string{}
+	tfEnvs = digitaloceanext.GetDigitaloceanTerraformEnvs(tfEnvs, cl)
+	tfEnvs = digitaloceanext.GetGitTerraformEnvs(tfEnvs, cl)
+	err = controller.DestroyGitResources(ctx, kcfg.Clientset, cl, provider, config.TerraformClient, config.GitopsDir, tfEnvs)
+	if err != nil {
+		errors.HandleClusterError(cl, err)
+		return err
+	}
 
-			cl.GitTerraformApplyCheck = false
-			err = secrets.UpdateCluster(kcfg.Clientset, *cl)
-			if err != nil {
-				return fmt.Errorf("error updating cluster: %w", err)
-			}
-		}
+	if err := errors.CheckpointDelete(cl, "DeleteClusterResources"); err != nil {
+		return err
 	}
 
 	// Should be a "cluster was created" check
@@ -218,9 +171,9 @@ func DeleteDigitaloceanCluster(cl *pkgtypes.Cluster, telemetryEvent telemetry.Te
 
 				client := httpCommon.CustomHTTPClient(true)
 				log.Info().Msg("deleting the registry application")
-				httpCode, _, err := argocd.DeleteApplication(client, config.RegistryAppName, argocdAuthToken, "true")
+				httpCode, _, err := argocd.DeleteApplication(client, argocd.GetArgoEndpoint(e), config.RegistryAppName, argocdAuthToken, "true")
 				if err != nil {
-					errors.HandleClusterError(cl, err.Error())
+					errors.HandleClusterError(cl, err)
 					return fmt.Errorf("error deleting the registry application: %w", err)
 				}
 				log.Info().Msgf("http status code %d", httpCode)
@@ -237,25 +190,21 @@ func DeleteDigitaloceanCluster(cl *pkgtypes.Cluster, telemetryEvent telemetry.Te
 			}
 		}
 
+		if err := errors.CheckpointDelete(cl, "DestroyCloudResources"); err != nil {
+			return err
+		}
+
 		log.Info().Msg("destroying digitalocean cloud resources")
 		tfEntrypoint := config.GitopsDir + fmt.Sprintf("/terraform/%s", cl.CloudProvider)
 		tfEnvs := map[string]string{}
 		tfEnvs = digitaloceanext.GetDigitaloceanTerraformEnvs(tfEnvs, cl)
+		tfEnvs = digitaloceanext.GetGitTerraformEnvs(tfEnvs, cl)
+		tfEnvs = provider.TerraformEnvs(tfEnvs)
 
-		switch cl.GitProvider {
-		case "github":
-			tfEnvs = digitaloceanext.GetGithubTerraformEnvs(tfEnvs, cl)
-		case "gitlab":
-			gid, err := strconv.Atoi(strconv.Itoa(cl.GitlabOwnerGroupID))
-			if err != nil {
-				return fmt.Errorf("couldn't convert gitlab group id to int: %w", err)
-			}
-			tfEnvs = digitaloceanext.GetGitlabTerraformEnvs(tfEnvs, gid, cl)
-		}
-		err = terraformext.InitDestroyAutoApprove(config.TerraformClient, tfEntrypoint, tfEnvs)
+		err = terraformext.InitDestroyAutoApprove(ctx, config.TerraformClient, tfEntrypoint, tfEnvs)
 		if err != nil {
 			log.Printf("error executing terraform destroy %s", tfEntrypoint)
-			errors.HandleClusterError(cl, err.Error())
+			errors.HandleClusterError(cl, err)
 			return fmt.Errorf("error executing terraform destroy %s: %w", tfEntrypoint, err)
 		}
 		log.Info().Msg("digitalocean resources terraform destroyed")
@@ -275,19 +224,9 @@ func DeleteDigitaloceanCluster(cl *pkgtypes.Cluster, telemetryEvent telemetry.Te
 	}
 
 	// remove ssh key provided one was created
-	if cl.GitProvider == "gitlab" {
-		gitlabClient, err := gitlab.NewGitLabClient(cl.GitAuth.Token, cl.GitAuth.Owner)
-		if err != nil {
-			return fmt.Errorf("error creating new GitLab client: %w", err)
-		}
-		log.Info().Msgf("attempting to delete managed ssh key...")
-		err = gitlabClient.DeleteUserSSHKey("kbot-ssh-key")
-		if err != nil {
-			log.Warn().Msg(err.Error())
-		}
-	}
+	controller.DeleteKbotSSHKey(provider)
 
-	telemetry.SendEvent(telemetryEvent, telemetry.ClusterDeleteCompleted, "")
+	apitelemetry.SendEvent(cl.TelemetryEnabled(), telemetryEvent, telemetry.ClusterDeleteCompleted, "")
 
 	cl.Status = constants.ClusterStatusDeleted
 	err = secrets.UpdateCluster(kcfg.Clientset, *cl)
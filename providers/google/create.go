@@ -7,19 +7,29 @@ See the LICENSE file for more details.
 package google
 
 import (
+	"context"
 	"fmt"
 	"os"
 
 	"github.com/konstructio/kubefirst-api/internal/controller"
+	"github.com/konstructio/kubefirst-api/internal/env"
 	"github.com/konstructio/kubefirst-api/internal/k8s"
 	"github.com/konstructio/kubefirst-api/internal/secrets"
+	"github.com/konstructio/kubefirst-api/internal/tracing"
 	"github.com/konstructio/kubefirst-api/pkg/google"
 	pkgtypes "github.com/konstructio/kubefirst-api/pkg/types"
 	log "github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
 )
 
-func CreateGoogleCluster(definition *pkgtypes.ClusterDefinition) error {
-	ctrl := controller.ClusterController{}
+func CreateGoogleCluster(ctx context.Context, e env.Env, definition *pkgtypes.ClusterDefinition) error {
+	ctx, span := tracing.Start(ctx, "cluster.create",
+		attribute.String("cluster.name", definition.ClusterName),
+		attribute.String("cloud.provider", definition.CloudProvider),
+	)
+	defer span.End()
+
+	ctrl := controller.ClusterController{Context: ctx, Env: e}
 	err := ctrl.InitController(definition)
 	if err != nil {
 		return fmt.Errorf("error initializing controller: %w", err)
@@ -27,6 +37,7 @@ func CreateGoogleCluster(definition *pkgtypes.ClusterDefinition) error {
 
 	// Update cluster status in database
 	ctrl.Cluster.InProgress = true
+	ctrl.Cluster.TraceID = tracing.TraceID(ctx)
 	err = secrets.UpdateCluster(ctrl.KubernetesClient, ctrl.Cluster)
 	if err != nil {
 		return fmt.Errorf("error updating cluster in progress status: %w", err)
@@ -47,65 +58,65 @@ func CreateGoogleCluster(definition *pkgtypes.ClusterDefinition) error {
 
 	os.Setenv("GOOGLE_APPLICATION_CREDENTIALS", fmt.Sprintf("%s/.k1/application-default-credentials.json", homeDir))
 
-	err = ctrl.DownloadTools(ctrl.ProviderConfig.ToolsDir)
+	err = ctrl.RunStep("DownloadTools", func() error { return ctrl.DownloadTools(ctrl.ProviderConfig.ToolsDir) })
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error downloading tools: %w", err)
 	}
 
-	err = ctrl.DomainLivenessTest()
+	err = ctrl.RunStep("DomainLivenessTest", ctrl.DomainLivenessTest)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error during domain liveness test: %w", err)
 	}
 
-	err = ctrl.StateStoreCredentials()
+	err = ctrl.RunStep("StateStoreCredentials", ctrl.StateStoreCredentials)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error retrieving state store credentials: %w", err)
 	}
 
 	// Checks for existing repos
-	err = ctrl.GitInit()
+	err = ctrl.RunStep("GitInit", ctrl.GitInit)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error initializing git repository: %w", err)
 	}
 
-	err = ctrl.InitializeBot()
+	err = ctrl.RunStep("InitializeBot", ctrl.InitializeBot)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error initializing bot: %w", err)
 	}
 
 	// Where detokeinization happens
-	err = ctrl.RepositoryPrep()
+	err = ctrl.RunStep("RepositoryPrep", ctrl.RepositoryPrep)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error during repository preparation: %w", err)
 	}
 
-	err = ctrl.RunGitTerraform()
+	err = ctrl.RunStep("RunGitTerraform", ctrl.RunGitTerraform)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error running Git Terraform: %w", err)
 	}
 
-	err = ctrl.RepositoryPush()
+	err = ctrl.RunStep("RepositoryPush", ctrl.RepositoryPush)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error pushing repository: %w", err)
 	}
 
-	err = ctrl.CreateCluster()
+	err = ctrl.RunStep("CreateCluster", ctrl.CreateCluster)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error creating cluster: %w", err)
 	}
 
-	err = ctrl.DetokenizeKMSKeyID()
+	err = ctrl.RunStep("DetokenizeKMSKeyID", ctrl.DetokenizeKMSKeyID)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error detokenizing KMS Key ID: %w", err)
 	}
 
@@ -116,21 +127,23 @@ func CreateGoogleCluster(definition *pkgtypes.ClusterDefinition) error {
 	// Save config
 	ctrl.Kcfg = kcfg
 
-	err = ctrl.WaitForClusterReady()
+	err = ctrl.RunStep("WaitForClusterReady", ctrl.WaitForClusterReady)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error waiting for cluster readiness: %w", err)
 	}
 
-	err = ctrl.InstallArgoCD()
+	ctrl.RestoreTLSBackup()
+
+	err = ctrl.RunStep("InstallArgoCD", ctrl.InstallArgoCD)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error installing ArgoCD: %w", err)
 	}
 
-	err = ctrl.InitializeArgoCD()
+	err = ctrl.RunStep("InitializeArgoCD", ctrl.InitializeArgoCD)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error initializing ArgoCD: %w", err)
 	}
 
@@ -141,9 +154,9 @@ func CreateGoogleCluster(definition *pkgtypes.ClusterDefinition) error {
 		return fmt.Errorf("error updating cluster after installation: %w", err)
 	}
 
-	err = ctrl.ClusterSecretsBootstrap()
+	err = ctrl.RunStep("ClusterSecretsBootstrap", ctrl.ClusterSecretsBootstrap)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error bootstrapping cluster secrets: %w", err)
 	}
 
@@ -159,15 +172,15 @@ func CreateGoogleCluster(definition *pkgtypes.ClusterDefinition) error {
 		return fmt.Errorf("error updating cluster after secrets creation: %w", err)
 	}
 
-	err = ctrl.DeployRegistryApplication()
+	err = ctrl.RunStep("DeployRegistryApplication", ctrl.DeployRegistryApplication)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error deploying registry application: %w", err)
 	}
 
-	err = ctrl.WaitForVault()
+	err = ctrl.RunStep("WaitForVault", ctrl.WaitForVault)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error waiting for Vault: %w", err)
 	}
 
@@ -187,33 +200,39 @@ func CreateGoogleCluster(definition *pkgtypes.ClusterDefinition) error {
 		vaultStopChannel,
 	)
 
-	err = ctrl.InitializeVault()
+	err = ctrl.RunStep("InitializeVault", ctrl.InitializeVault)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error initializing Vault: %w", err)
 	}
 
-	err = ctrl.RunVaultTerraform()
+	err = ctrl.RunStep("RunVaultTerraform", ctrl.RunVaultTerraform)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error running Vault Terraform: %w", err)
 	}
 
-	err = ctrl.WriteVaultSecrets()
+	err = ctrl.RunStep("WriteVaultSecrets", ctrl.WriteVaultSecrets)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error writing Vault secrets: %w", err)
 	}
 
-	err = ctrl.RunUsersTerraform()
+	err = ctrl.RunStep("RunUsersTerraform", ctrl.RunUsersTerraform)
 	if err != nil {
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error running Users Terraform: %w", err)
 	}
 
-	if err := ctrl.FinalCheck(); err != nil {
+	err = ctrl.RunStep("RevokeVaultRootToken", ctrl.RevokeVaultRootToken)
+	if err != nil {
+		ctrl.UpdateClusterOnError(err)
+		return fmt.Errorf("error revoking vault root token: %w", err)
+	}
+
+	if err := ctrl.RunStep("FinalCheck", ctrl.FinalCheck); err != nil {
 		log.Error().Msgf("error doing final check: %s", err)
-		ctrl.UpdateClusterOnError(err.Error())
+		ctrl.UpdateClusterOnError(err)
 		return fmt.Errorf("error doing final check: %w", err)
 	}
 
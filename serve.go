@@ -0,0 +1,108 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/konstructio/kubefirst-api/internal/env"
+	clustererrors "github.com/konstructio/kubefirst-api/internal/errors"
+	"github.com/konstructio/kubefirst-api/internal/metrics"
+	"github.com/konstructio/kubefirst-api/internal/shutdown"
+	log "github.com/rs/zerolog/log"
+)
+
+// serverShutdownTimeout is the time given to in-flight requests once the
+// running operations are drained
+const serverShutdownTimeout = 10 * time.Second
+
+// cancelTimeout is the time given to operations still running after the drain
+// timeout to return once their context is canceled
+const cancelTimeout = 30 * time.Second
+
+// serve runs the API until SIGTERM or SIGINT, then drains it: mutating
+// requests are rejected, running operations are given the shutdown timeout to
+// reach a step boundary and those still running have their context canceled.
+// Once they returned, or the cancel timeout passed, they are marked interrupted
+// and the server stops
+func serve(handler http.Handler, e env.Env) error {
+	srv := &http.Server{
+		Addr:              fmt.Sprintf(":%v", e.ServerPort),
+		Handler:           handler,
+		ReadHeaderTimeout: 30 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return fmt.Errorf("error starting API: %w", err)
+	case <-ctx.Done():
+	}
+	// a second signal kills the process
+	stop()
+
+	log.Info().Msgf("shutdown signal received, draining running operations for up to %s", e.ShutdownTimeout)
+	shutdown.Begin()
+
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), e.ShutdownTimeout)
+	defer cancelDrain()
+
+	interrupted := shutdown.Wait(drainCtx)
+	if len(interrupted) > 0 {
+		// the operations write their own error before they are marked interrupted
+		log.Warn().Msgf("%d operations still running, canceling them", len(interrupted))
+		shutdown.Cancel()
+
+		cancelCtx, cancelWait := context.WithTimeout(context.Background(), cancelTimeout)
+		defer cancelWait()
+
+		for _, op := range shutdown.Wait(cancelCtx) {
+			log.Error().Msgf("cluster %s: %s did not return after its context was canceled", op.ClusterName, op.Kind)
+		}
+	}
+
+	for _, op := range interrupted {
+		switch op.Kind {
+		case metrics.OperationCreate, metrics.OperationDelete:
+			condition := fmt.Sprintf("%s during cluster %s, retry the operation to resume", shutdown.ErrInterrupted, op.Kind)
+			log.Warn().Msgf("cluster %s: %s", op.ClusterName, condition)
+			if err := clustererrors.HandleClusterInterrupted(op.ClusterName, op.Kind, condition); err != nil {
+				log.Error().Msgf("error marking cluster %s interrupted: %s", op.ClusterName, err)
+			}
+		default:
+			condition := fmt.Sprintf("%s during %s, it did not complete and must be started again", shutdown.ErrInterrupted, op.Kind)
+			log.Warn().Msgf("cluster %s: %s", op.ClusterName, condition)
+			if err := clustererrors.HandleOperationAbandoned(op.ClusterName, condition); err != nil {
+				log.Error().Msgf("error recording the interrupted %s of cluster %s: %s", op.Kind, op.ClusterName, err)
+			}
+		}
+	}
+
+	serverCtx, cancelServer := context.WithTimeout(context.Background(), serverShutdownTimeout)
+	defer cancelServer()
+
+	if err := srv.Shutdown(serverCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("error shutting down API: %w", err)
+	}
+
+	log.Info().Msg("API stopped")
+	return nil
+}